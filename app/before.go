@@ -15,7 +15,6 @@ import (
 	"github.com/p9c/pod/app/apputil"
 	chaincfg "github.com/p9c/pod/pkg/chain/config"
 	"github.com/p9c/pod/pkg/chain/config/netparams"
-	"github.com/p9c/pod/pkg/chain/fork"
 	"github.com/p9c/pod/pkg/conte"
 	"github.com/p9c/pod/pkg/pod"
 )
@@ -67,13 +66,9 @@ func beforeFunc(cx *conte.Xt) func(c *cli.Context) error {
 			switch *cx.Config.Network {
 			case "testnet", "testnet3", "t":
 				cx.ActiveNet = &netparams.TestNet3Params
-				fork.IsTestnet = true
-				// fork.HashReps = 3
 			case "regtestnet", "regressiontest", "r":
-				fork.IsTestnet = true
 				cx.ActiveNet = &netparams.RegressionTestParams
 			case "simnet", "s":
-				fork.IsTestnet = true
 				cx.ActiveNet = &netparams.SimNetParams
 			default:
 				if *cx.Config.Network != "mainnet" &&
@@ -191,6 +186,9 @@ func beforeFunc(cx *conte.Xt) func(c *cli.Context) error {
 		if c.IsSet("norpc") {
 			*cx.Config.DisableRPC = c.Bool("norpc")
 		}
+		if c.IsSet("rest") {
+			*cx.Config.REST = c.Bool("rest")
+		}
 		if c.IsSet("nodnsseed") {
 			*cx.Config.DisableDNSSeed = c.Bool("nodnsseed")
 		}
@@ -212,6 +210,9 @@ func beforeFunc(cx *conte.Xt) func(c *cli.Context) error {
 		if c.IsSet("cpuprofile") {
 			*cx.Config.CPUProfile = c.String("cpuprofile")
 		}
+		if c.IsSet("metricslisten") {
+			*cx.Config.MetricsListener = c.String("metricslisten")
+		}
 		if c.IsSet("upnp") {
 			*cx.Config.UPNP = c.Bool("upnp")
 		}
@@ -227,12 +228,18 @@ func beforeFunc(cx *conte.Xt) func(c *cli.Context) error {
 		if c.IsSet("trickleinterval") {
 			*cx.Config.TrickleInterval = c.Duration("trickleinterval")
 		}
+		if c.IsSet("maxmempool") {
+			*cx.Config.MaxMempoolSize = c.Int("maxmempool")
+		}
 		if c.IsSet("maxorphantx") {
 			*cx.Config.MaxOrphanTxs = c.Int("maxorphantx")
 		}
 		if c.IsSet("generate") {
 			*cx.Config.Generate = c.Bool("generate")
 		}
+		if c.IsSet("forkheight") {
+			*cx.Config.ForkHeight = c.Int("forkheight")
+		}
 		if c.IsSet("genthreads") {
 			*cx.Config.GenThreads = c.Int("genthreads")
 		}
@@ -258,6 +265,27 @@ func beforeFunc(cx *conte.Xt) func(c *cli.Context) error {
 		if c.IsSet("controller") {
 			*cx.Config.Controller = c.String("controller")
 		}
+		if c.IsSet("stratum") {
+			*cx.Config.StratumListener = c.String("stratum")
+		}
+		if c.IsSet("minerlistener") {
+			*cx.Config.MinerListener = c.String("minerlistener")
+		}
+		if c.IsSet("minercontroller") {
+			*cx.Config.MinerControllers = c.StringSlice("minercontroller")
+		}
+		if c.IsSet("minertcp") {
+			*cx.Config.MinerTCP = c.Bool("minertcp")
+		}
+		if c.IsSet("poolpayout") {
+			*cx.Config.PoolPayout = c.String("poolpayout")
+		}
+		if c.IsSet("poolwindow") {
+			*cx.Config.PoolWindow = c.Int("poolwindow")
+		}
+		if c.IsSet("pooladdress") {
+			*cx.Config.PoolAddress = c.String("pooladdress")
+		}
 		if c.IsSet("miningaddrs") {
 			*cx.Config.MiningAddrs = c.StringSlice("miningaddrs")
 		}
@@ -299,9 +327,27 @@ func beforeFunc(cx *conte.Xt) func(c *cli.Context) error {
 		if c.IsSet("sigcachemaxsize") {
 			*cx.Config.SigCacheMaxSize = c.Int("sigcachemaxsize")
 		}
+		if c.IsSet("prune") {
+			*cx.Config.Prune = c.Int("prune")
+		}
 		if c.IsSet("blocksonly") {
 			*cx.Config.BlocksOnly = c.Bool("blocksonly")
 		}
+		if c.IsSet("pubhashblock") {
+			*cx.Config.PubHashBlock = c.StringSlice("pubhashblock")
+		}
+		if c.IsSet("pubhashtx") {
+			*cx.Config.PubHashTx = c.StringSlice("pubhashtx")
+		}
+		if c.IsSet("pubrawblock") {
+			*cx.Config.PubRawBlock = c.StringSlice("pubrawblock")
+		}
+		if c.IsSet("pubrawtx") {
+			*cx.Config.PubRawTx = c.StringSlice("pubrawtx")
+		}
+		if c.IsSet("pubreorg") {
+			*cx.Config.PubReorg = c.StringSlice("pubreorg")
+		}
 		if c.IsSet("notxindex") {
 			*cx.Config.TxIndex = c.Bool("notxindex")
 		}
@@ -314,6 +360,9 @@ func beforeFunc(cx *conte.Xt) func(c *cli.Context) error {
 		if c.IsSet("rejectnonstd") {
 			*cx.Config.RejectNonStd = c.Bool("rejectnonstd")
 		}
+		if c.IsSet("rejectreplacement") {
+			*cx.Config.RejectReplacement = c.Bool("rejectreplacement")
+		}
 		if c.IsSet("noinitialload") {
 			*cx.Config.NoInitialLoad = c.Bool("noinitialload")
 		}
@@ -326,12 +375,27 @@ func beforeFunc(cx *conte.Xt) func(c *cli.Context) error {
 		if c.IsSet("walletpass") {
 			*cx.Config.WalletPass = c.String("walletpass")
 		}
+		if c.IsSet("usespv") {
+			*cx.Config.UseSPV = c.Bool("usespv")
+		}
+		if c.IsSet("spvconnect") {
+			*cx.Config.SPVConnect = c.StringSlice("spvconnect")
+		}
+		if c.IsSet("spvdatadir") {
+			*cx.Config.SPVDataDir = c.String("spvdatadir")
+		}
+		if c.IsSet("walletbirthday") {
+			*cx.Config.WalletBirthday = c.String("walletbirthday")
+		}
 		if c.IsSet("onetimetlskey") {
 			*cx.Config.OneTimeTLSKey = c.Bool("onetimetlskey")
 		}
 		if c.IsSet("walletrpclisten") {
 			*cx.Config.WalletRPCListeners = c.StringSlice("walletrpclisten")
 		}
+		if c.IsSet("walletmetricslisten") {
+			*cx.Config.WalletMetricsListener = c.String("walletmetricslisten")
+		}
 		if c.IsSet("walletrpcmaxclients") {
 			*cx.Config.WalletRPCMaxClients = c.Int("walletrpcmaxclients")
 		}
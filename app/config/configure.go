@@ -9,7 +9,7 @@ import (
 )
 
 func // Configure loads and sanitises the configuration from urfave/cli
-Configure(cx *conte.Xt, commandName string) {
+Configure(cx *conte.Xt, commandName string) (err error) {
 	Debug("running Configure", commandName)
 	Trace("configuring pod")
 	cx.WalletChan = make(chan *wallet.Wallet)
@@ -18,12 +18,15 @@ Configure(cx *conte.Xt, commandName string) {
 	cfg := cx.Config
 	initLogLevel(cfg)
 	initDictionary(cfg)
-	initParams(cx)
+	if err = initParams(cx); Check(err) {
+		return
+	}
 	initDataDir(cfg)
 	initTLSStuffs(cfg, cx.StateCfg)
 	initConfigFile(cfg)
 	initLogDir(cfg)
 	initWalletFile(cx)
+	initSPVDataDir(cx)
 	initListeners(cx, commandName)
 	// Don't add peers from the config file when in regression test mode.
 	if ((*cfg.Network)[0] == 'r') && len(*cfg.AddPeers) > 0 {
@@ -32,6 +35,7 @@ Configure(cx *conte.Xt, commandName string) {
 	normalizeAddresses(cfg)
 	setRelayReject(cfg)
 	validateDBtype(cfg)
+	validatePrune(cfg)
 	validateProfilePort(cfg)
 	validateBanDuration(cfg)
 	validateWhitelists(cfg, cx.StateCfg)
@@ -48,4 +52,5 @@ Configure(cx *conte.Xt, commandName string) {
 		Trace("saving configuration")
 		save.Pod(cx.Config)
 	}
+	return
 }
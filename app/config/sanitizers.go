@@ -30,7 +30,6 @@ import (
 	"github.com/p9c/pod/app/appdata"
 	"github.com/p9c/pod/cmd/node/state"
 	"github.com/p9c/pod/pkg/chain/config/netparams"
-	"github.com/p9c/pod/pkg/chain/fork"
 	"github.com/p9c/pod/pkg/conte"
 	"github.com/p9c/pod/pkg/logi"
 	"github.com/p9c/pod/pkg/pod"
@@ -69,6 +68,14 @@ func initWalletFile(cx *conte.Xt) {
 	Trace("walletfile set to", *cx.Config.WalletFile)
 }
 
+func initSPVDataDir(cx *conte.Xt) {
+	if cx.Config.SPVDataDir == nil || *cx.Config.SPVDataDir == "" {
+		*cx.Config.SPVDataDir = filepath.Join(*cx.Config.DataDir,
+			cx.ActiveNet.Name, "spv")
+	}
+	Trace("spvdatadir set to", *cx.Config.SPVDataDir)
+}
+
 func initConfigFile(cfg *pod.Config) {
 	if *cfg.ConfigFile == "" {
 		*cfg.ConfigFile =
@@ -87,7 +94,7 @@ func initLogDir(cfg *pod.Config) {
 	}
 }
 
-func initParams(cx *conte.Xt) {
+func initParams(cx *conte.Xt) error {
 	network := "mainnet"
 	if cx.Config.Network != nil {
 		network = *cx.Config.Network
@@ -96,7 +103,6 @@ func initParams(cx *conte.Xt) {
 	case "testnet", "testnet3", "t":
 		Trace("on testnet")
 		cx.ActiveNet = &netparams.TestNet3Params
-		fork.IsTestnet = true
 	case "regtestnet", "regressiontest", "r":
 		Trace("on regression testnet")
 		cx.ActiveNet = &netparams.RegressionTestParams
@@ -110,6 +116,29 @@ func initParams(cx *conte.Xt) {
 		Trace("on mainnet")
 		cx.ActiveNet = &netparams.MainNetParams
 	}
+	return initForkHeight(cx)
+}
+
+// initForkHeight moves the first hard fork of the regression test and
+// simulation networks to the configured height, in a copy of the parameters of
+// the network so the schedule of the network itself is left unchanged
+func initForkHeight(cx *conte.Xt) (err error) {
+	if cx.Config.ForkHeight == nil || *cx.Config.ForkHeight < 1 {
+		return
+	}
+	if cx.ActiveNet.Name != netparams.RegressionTestParams.Name &&
+		cx.ActiveNet.Name != netparams.SimNetParams.Name {
+		Warn("fork height can only be set on regtest and simnet, ignoring it")
+		return
+	}
+	height := int32(*cx.Config.ForkHeight)
+	var params *netparams.Params
+	if params, err = cx.ActiveNet.WithForkHeight(height); Check(err) {
+		return fmt.Errorf("invalid fork height %d: %v", height, err)
+	}
+	cx.ActiveNet = params
+	Info("first hard fork activates at height", height)
+	return
 }
 
 func validatePort(port string) bool {
@@ -418,6 +447,25 @@ func validateDBtype(cfg *pod.Config) {
 	}
 }
 
+func validatePrune(cfg *pod.Config) {
+	// Validate the prune target, which can't be so small that the recent
+	// blocks that are kept don't fit.
+	Trace("validating prune target")
+	switch {
+	case *cfg.Prune < 0:
+		err := fmt.Errorf("%s: The prune target may not be negative -- "+
+			"parsed [%v]", funcName, *cfg.Prune)
+		Error(funcName, err)
+		*cfg.Prune = 0
+	case *cfg.Prune > 0 && *cfg.Prune < blockchain.MinPruneTarget:
+		err := fmt.Errorf("%s: The prune target may not be less than "+
+			"%d MiB -- parsed [%v]", funcName, blockchain.MinPruneTarget,
+			*cfg.Prune)
+		Warn(funcName, err)
+		*cfg.Prune = blockchain.MinPruneTarget
+	}
+}
+
 func validateProfilePort(cfg *pod.Config) {
 	// Validate profile port number
 	Trace("validating profile port number")
@@ -610,6 +658,17 @@ func validatePolicies(cfg *pod.Config, stateConfig *state.Config) {
 		fmt.Fprintln(os.Stderr, err)
 		//os.Exit(1)
 	}
+	// Keep the mempool size limit large enough to hold the largest packages
+	// of transactions.
+	Trace("checking max mempool size")
+	if *cfg.MaxMempoolSize < node.MaxMempoolSizeMin {
+		str := "%s: The maxmempool option may not be less than %d MiB -- " +
+			"parsed [%d]"
+		err := fmt.Errorf(str, funcName, node.MaxMempoolSizeMin,
+			*cfg.MaxMempoolSize)
+		Warn(funcName, err)
+		*cfg.MaxMempoolSize = node.MaxMempoolSizeMin
+	}
 	// Limit the block priority and minimum block sizes to max block size.
 	Trace("validating block priority and minimum size/weight")
 	*cfg.BlockPrioritySize = int(apputil.MinUint32(
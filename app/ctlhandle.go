@@ -23,7 +23,9 @@ func ctlHandleList(c *cli.Context) error {
 
 func ctlHandle(cx *conte.Xt) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
-		config.Configure(cx, c.Command.Name)
+		if err := config.Configure(cx, c.Command.Name); Check(err) {
+			return err
+		}
 		args := c.Args()
 		if len(args) < 1 {
 			return cli.ShowSubcommandHelp(c)
@@ -41,7 +43,9 @@ func ctlHandle(cx *conte.Xt) func(c *cli.Context) error {
 
 func ctlGUIHandle(cx *conte.Xt) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
-		config.Configure(cx, c.Command.Name)
+		if err := config.Configure(cx, c.Command.Name); Check(err) {
+			return err
+		}
 
 		return nil
 	}
@@ -103,6 +103,18 @@ GetApp(cx *conte.Xt) (a *cli.App) {
 						apputil.SubCommands(),
 						nil,
 					),
+					apputil.NewCommand("dumputxo",
+						"write a snapshot of the utxo set to a file",
+						dumputxoHandle(cx),
+						apputil.SubCommands(),
+						dumputxoFlags,
+					),
+					apputil.NewCommand("loadutxo",
+						"start a new chain from a snapshot of the utxo set",
+						loadutxoHandle(cx),
+						apputil.SubCommands(),
+						loadutxoFlags,
+					),
 					apputil.NewCommand("resetchain",
 						"reset the chain",
 						func(c *cli.Context) (err error) {
@@ -128,7 +140,9 @@ GetApp(cx *conte.Xt) (a *cli.App) {
 							"for development and testing as well as clearing up"+
 							" transaction mess)",
 						func(c *cli.Context) (err error) {
-							config.Configure(cx, c.Command.Name)
+							if err = config.Configure(cx, c.Command.Name); Check(err) {
+								return
+							}
 							Info("dropping wallet history")
 							go func() {
 								Warn("starting wallet")
@@ -147,6 +161,13 @@ GetApp(cx *conte.Xt) (a *cli.App) {
 						apputil.SubCommands(),
 						nil,
 					),
+					apputil.NewCommand("restore",
+						"restore a wallet from its BIP39 mnemonic or seed, "+
+							"scanning the chain for its addresses on next start",
+						WalletRestoreHandle(cx),
+						apputil.SubCommands(),
+						nil,
+					),
 				), nil, "w"),
 			apputil.NewCommand("shell", "start combined wallet/node shell",
 				shellHandle(cx), apputil.SubCommands(), nil, "s"),
@@ -161,6 +182,18 @@ GetApp(cx *conte.Xt) (a *cli.App) {
 					"nothing before; communicates via net/rpc encoding/gob as"+
 					" default over stdio", kopach_worker.KopachWorkerHandle(cx),
 				apputil.SubCommands(), nil),
+			apputil.NewCommand("tools", "development and analysis tools", nil,
+				apputil.SubCommands(
+					apputil.NewCommand("diffsim",
+						"simulate the difficulty adjustment of the chain"+
+							" under a hashrate scenario, writing the targets"+
+							" and block intervals of each algorithm as CSV or"+
+							" JSON",
+						diffsimHandle,
+						apputil.SubCommands(),
+						diffsimFlags,
+					),
+				), nil, "t"),
 			apputil.NewCommand("init",
 				"steps through creation of new wallet and initialization for"+
 					" a network with these specified in the main",
@@ -207,6 +240,12 @@ GetApp(cx *conte.Xt) (a *cli.App) {
 				"connect to mainnet/testnet/regtest/simnet",
 				"mainnet",
 				cx.Config.Network),
+			apputil.Int(
+				"forkheight",
+				"height the first hard fork activates at on regtest and"+
+					" simnet, 0 for the network default",
+				0,
+				cx.Config.ForkHeight),
 			apputil.String(
 				"username",
 				"sets the username for services",
@@ -382,6 +421,11 @@ GetApp(cx *conte.Xt) (a *cli.App) {
 					" is disabled by default if no rpcuser/rpcpass or"+
 					" rpclimituser/rpclimitpass is specified",
 				cx.Config.DisableRPC),
+			apputil.Bool(
+				"rest",
+				"Serve the read-only REST interface at /rest/ on the RPC"+
+					" listeners, without authentication",
+				cx.Config.REST),
 			apputil.Bool(
 				"nodnsseed",
 				"Disable DNS seeding for peers",
@@ -416,6 +460,12 @@ GetApp(cx *conte.Xt) (a *cli.App) {
 				"Write CPU profile to the specified file",
 				"",
 				cx.Config.CPUProfile),
+			apputil.String(
+				"metricslisten",
+				"address to serve prometheus metrics of the node on at"+
+					" /metrics, empty disables it",
+				"",
+				cx.Config.MetricsListener),
 			apputil.Bool(
 				"upnp",
 				"Use UPnP to map our listening port outside of NAT",
@@ -443,6 +493,12 @@ GetApp(cx *conte.Xt) (a *cli.App) {
 					" inventory to a connected peer",
 				node.DefaultTrickleInterval,
 				cx.Config.TrickleInterval),
+			apputil.Int(
+				"maxmempool",
+				"Keep the mempool under this size in MiB by evicting the"+
+					" transactions paying the lowest fees",
+				node.DefaultMaxMempoolSize,
+				cx.Config.MaxMempoolSize),
 			apputil.Int(
 				"maxorphantx",
 				"Max number of orphan transactions to keep in memory",
@@ -472,6 +528,47 @@ GetApp(cx *conte.Xt) (a *cli.App) {
 					" and other node peers",
 				":0",
 				cx.Config.Controller),
+			apputil.String(
+				"stratum",
+				"address for the stratum v1 mining server to listen on,"+
+					" empty disables it",
+				"",
+				cx.Config.StratumListener),
+			apputil.String(
+				"minerlistener",
+				"address for the miner controller to accept unicast UDP"+
+					" and TCP kopach connections on, empty disables it",
+				"",
+				cx.Config.MinerListener),
+			apputil.StringSlice(
+				"minercontroller",
+				"address of a miner controller for kopach to connect to"+
+					" directly instead of finding one by multicast",
+				cx.Config.MinerControllers),
+			apputil.Bool(
+				"minertcp",
+				"connect kopach to the miner controllers with a TCP"+
+					" stream instead of UDP",
+				cx.Config.MinerTCP),
+			apputil.String(
+				"poolpayout",
+				"scheme to split block rewards between the shares of"+
+					" workers with, pplns or prop, empty disables share"+
+					" accounting",
+				"",
+				cx.Config.PoolPayout),
+			apputil.Int(
+				"poolwindow",
+				"number of blocks worth of shares that PPLNS splits each"+
+					" block reward over",
+				2,
+				cx.Config.PoolWindow),
+			apputil.String(
+				"pooladdress",
+				"address for kopach to have its shares credited to, empty"+
+					" disables sending shares",
+				"",
+				cx.Config.PoolAddress),
 			apputil.Bool(
 				"autoports",
 				"uses random automatic ports for p2p, rpc and controller",
@@ -536,10 +633,36 @@ GetApp(cx *conte.Xt) (a *cli.App) {
 					" signature verification cache",
 				node.DefaultSigCacheMaxSize,
 				cx.Config.SigCacheMaxSize),
+			apputil.Int(
+				"prune",
+				"Delete the oldest blocks to keep the block files under"+
+					" this size in MiB, at least 550, 0 keeps all blocks",
+				0,
+				cx.Config.Prune),
 			apputil.Bool(
 				"blocksonly",
 				"Do not accept transactions from remote peers.",
 				cx.Config.BlocksOnly),
+			apputil.StringSlice(
+				"pubhashblock",
+				"Publish the hashes of new blocks on the given tcp:// or unix:// endpoint",
+				cx.Config.PubHashBlock),
+			apputil.StringSlice(
+				"pubhashtx",
+				"Publish the hashes of new transactions on the given tcp:// or unix:// endpoint",
+				cx.Config.PubHashTx),
+			apputil.StringSlice(
+				"pubrawblock",
+				"Publish new blocks in full on the given tcp:// or unix:// endpoint",
+				cx.Config.PubRawBlock),
+			apputil.StringSlice(
+				"pubrawtx",
+				"Publish new transactions in full on the given tcp:// or unix:// endpoint",
+				cx.Config.PubRawTx),
+			apputil.StringSlice(
+				"pubreorg",
+				"Publish the blocks disconnected by chain reorganizations on the given tcp:// or unix:// endpoint",
+				cx.Config.PubReorg),
 			apputil.Bool(
 				"notxindex",
 				"Disable the transaction index which makes all transactions"+
@@ -559,6 +682,11 @@ GetApp(cx *conte.Xt) (a *cli.App) {
 				"Reject non-standard transactions regardless of"+
 					" the default settings for the active network.",
 				cx.Config.RejectNonStd),
+			apputil.Bool(
+				"rejectreplacement",
+				"Reject transactions that replace transactions in the"+
+					" mempool that signal opt-in replace-by-fee",
+				cx.Config.RejectReplacement),
 			apputil.Bool(
 				"noinitialload",
 				"Defer wallet creation/opening on startup and"+
@@ -579,6 +707,28 @@ GetApp(cx *conte.Xt) (a *cli.App) {
 					" the wallet was created with one",
 				"",
 				cx.Config.WalletPass),
+			apputil.Bool(
+				"usespv",
+				"synchronise the wallet from compact block filters"+
+					" served by peers instead of the RPC of a full node",
+				cx.Config.UseSPV),
+			apputil.StringSlice(
+				"spvconnect",
+				"connect the SPV wallet only to these nodes serving"+
+					" compact block filters",
+				cx.Config.SPVConnect),
+			apputil.String(
+				"spvdatadir",
+				"folder where the SPV wallet stores block headers and"+
+					" filters",
+				"",
+				cx.Config.SPVDataDir),
+			apputil.String(
+				"walletbirthday",
+				"date (YYYY-MM-DD) before which the wallet's keys were"+
+					" never used, rescans of an SPV wallet start after it",
+				"",
+				cx.Config.WalletBirthday),
 			apputil.Bool(
 				"onetimetlskey",
 				"Generate a new TLS certpair at startup, but"+
@@ -594,6 +744,12 @@ GetApp(cx *conte.Xt) (a *cli.App) {
 					" interface/port (default port: 11046, testnet: 21046,"+
 					" simnet: 41046)",
 				cx.Config.WalletRPCListeners),
+			apputil.String(
+				"walletmetricslisten",
+				"address to serve prometheus metrics of the wallet on at"+
+					" /metrics, empty disables it",
+				"",
+				cx.Config.WalletMetricsListener),
 			apputil.Int(
 				"walletrpcmaxclients",
 				"Max number of legacy RPC clients for"+
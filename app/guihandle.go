@@ -18,7 +18,9 @@ import (
 var guiHandle = func(cx *conte.Xt) func(c *cli.Context) (err error) {
 	return func(c *cli.Context) (err error) {
 		serve.Log(cx.KillAll)
-		config.Configure(cx, c.Command.Name)
+		if err = config.Configure(cx, c.Command.Name); Check(err) {
+			return
+		}
 		Warn("starting GUI")
 		rc := rcd.RcInit(cx)
 		if !apputil.FileExists(*cx.Config.WalletFile) {
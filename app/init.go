@@ -13,7 +13,9 @@ import (
 var initHandle = func(cx *conte.Xt) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
 		Info("running configuration and wallet initialiser")
-		config.Configure(cx, c.Command.Name)
+		if err := config.Configure(cx, c.Command.Name); Check(err) {
+			return err
+		}
 		command := os.Args[0]
 		args := append(os.Args[1:len(os.Args)-1], "wallet")
 		Debug(args)
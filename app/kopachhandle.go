@@ -7,8 +7,6 @@ import (
 	"github.com/urfave/cli"
 
 	"github.com/p9c/pod/cmd/kopach"
-	"github.com/p9c/pod/pkg/chain/config/netparams"
-	"github.com/p9c/pod/pkg/chain/fork"
 
 	"github.com/p9c/pod/pkg/conte"
 	"github.com/p9c/pod/pkg/util/interrupt"
@@ -17,9 +15,8 @@ import (
 func KopachHandle(cx *conte.Xt) func(c *cli.Context) (err error) {
 	return func(c *cli.Context) (err error) {
 		Info("starting up kopach standalone miner for parallelcoin")
-		config.Configure(cx, c.Command.Name)
-		if cx.ActiveNet.Name == netparams.TestNet3Params.Name {
-			fork.IsTestnet = true
+		if err = config.Configure(cx, c.Command.Name); Check(err) {
+			return
 		}
 		quit := make(chan struct{})
 		interrupt.AddHandler(func() {
@@ -14,7 +14,9 @@ import (
 
 var monitorHandle = func(cx *conte.Xt) func(c *cli.Context) (err error) {
 	return func(c *cli.Context) (err error) {
-		config.Configure(cx, c.Command.Name)
+		if err = config.Configure(cx, c.Command.Name); Check(err) {
+			return
+		}
 		rc := rcd.RcInit(cx)
 		Warn("starting monitor GUI")
 		return monitor.Run(cx, rc)
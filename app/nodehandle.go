@@ -13,7 +13,9 @@ func nodeHandle(cx *conte.Xt) func(c *cli.Context) error {
 	return func(c *cli.Context) (err error) {
 		Trace("running node handler")
 		serve.Log(cx.KillAll)
-		config.Configure(cx, c.Command.Name)
+		if err = config.Configure(cx, c.Command.Name); Check(err) {
+			return
+		}
 		cx.NodeReady = make(chan struct{})
 		cx.Node.Store(false)
 		// serviceOptions defines the configuration options for the daemon as a service on Windows.
@@ -17,7 +17,9 @@ import (
 
 func shellHandle(cx *conte.Xt) func(c *cli.Context) (err error) {
 	return func(c *cli.Context) (err error) {
-		config.Configure(cx, c.Command.Name)
+		if err = config.Configure(cx, c.Command.Name); Check(err) {
+			return
+		}
 		serve.Log(cx.KillAll)
 		Debug("starting shell")
 		if *cx.Config.TLS || *cx.Config.ServerTLS {
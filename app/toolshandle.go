@@ -0,0 +1,94 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli"
+
+	"github.com/p9c/pod/pkg/chain/diffsim"
+)
+
+// diffsimFlags are the options of the difficulty adjustment simulator, which
+// override those of the scenario file
+var diffsimFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "scenario, s",
+		Usage: "JSON file describing the hashrate scenario to simulate",
+	},
+	cli.StringFlag{
+		Name: "fork, f",
+		Usage: "retarget algorithm to simulate, " + diffsim.Halcyon + " or " +
+			diffsim.Plan9,
+		Value: diffsim.Plan9,
+	},
+	cli.IntFlag{
+		Name:  "blocks, b",
+		Usage: "number of blocks to mine",
+		Value: 1000,
+	},
+	cli.Int64Flag{
+		Name:  "seed",
+		Usage: "seed of the random source",
+		Value: 1,
+	},
+	cli.StringFlag{
+		Name:  "format",
+		Usage: "output format, csv or json",
+		Value: "csv",
+	},
+	cli.StringFlag{
+		Name:  "output, o",
+		Usage: "file to write the output to, standard output if not set",
+	},
+}
+
+func diffsimHandle(c *cli.Context) (err error) {
+	s := &diffsim.Scenario{
+		Fork:   c.String("fork"),
+		Blocks: c.Int("blocks"),
+		Seed:   c.Int64("seed"),
+	}
+	if path := c.String("scenario"); path != "" {
+		if s, err = diffsim.LoadScenario(path); Check(err) {
+			return
+		}
+		if c.IsSet("fork") {
+			s.Fork = c.String("fork")
+		}
+		if c.IsSet("blocks") || s.Blocks == 0 {
+			s.Blocks = c.Int("blocks")
+		}
+		if c.IsSet("seed") {
+			s.Seed = c.Int64("seed")
+		}
+	}
+	var write func(*diffsim.Result, io.Writer) error
+	switch format := c.String("format"); format {
+	case "csv":
+		write = (*diffsim.Result).WriteCSV
+	case "json":
+		write = (*diffsim.Result).WriteJSON
+	default:
+		return fmt.Errorf("unknown output format '%s', must be csv or json",
+			format)
+	}
+	var res *diffsim.Result
+	if res, err = diffsim.Run(s); Check(err) {
+		return
+	}
+	out := os.Stdout
+	if path := c.String("output"); path != "" {
+		if out, err = os.Create(path); Check(err) {
+			return
+		}
+		defer func() {
+			if err := out.Close(); Check(err) {
+			}
+		}()
+	}
+	if err = write(res, out); Check(err) {
+	}
+	return
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/urfave/cli"
+
+	"github.com/p9c/pod/app/config"
+	"github.com/p9c/pod/cmd/node"
+	"github.com/p9c/pod/pkg/conte"
+)
+
+// dumputxoFlags are the options of the utxo snapshot export
+var dumputxoFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "height",
+		Usage: "height of the block to take the snapshot at, the best block if negative",
+		Value: -1,
+	},
+	cli.StringFlag{
+		Name:  "file, f",
+		Usage: "file to write the snapshot to, utxo-<height>.dat in the network data directory if not set",
+	},
+}
+
+// loadutxoFlags are the options of the utxo snapshot import
+var loadutxoFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "file, f",
+		Usage: "file to load the snapshot from",
+	},
+	cli.StringFlag{
+		Name:  "utxohash",
+		Usage: "hash of the utxo set of the snapshot, when there is none for its height in the chain parameters",
+	},
+}
+
+func dumputxoHandle(cx *conte.Xt) func(c *cli.Context) error {
+	return func(c *cli.Context) (err error) {
+		if err = config.Configure(cx, c.Command.Name); Check(err) {
+			return
+		}
+		return node.DumpUtxoSnapshot(cx, int32(c.Int("height")),
+			c.String("file"))
+	}
+}
+
+func loadutxoHandle(cx *conte.Xt) func(c *cli.Context) error {
+	return func(c *cli.Context) (err error) {
+		cx.StateCfg.LoadUtxoSnapshot = c.String("file")
+		if cx.StateCfg.LoadUtxoSnapshot == "" {
+			return errors.New("the file of the utxo snapshot to load is " +
+				"required")
+		}
+		cx.StateCfg.UtxoSnapshotHash = c.String("utxohash")
+		return nodeHandle(cx)(c)
+	}
+}
@@ -19,7 +19,9 @@ func WalletHandle(cx *conte.Xt) func(c *cli.Context) (err error) {
 	return func(c *cli.Context) (err error) {
 		var wg sync.WaitGroup
 		serve.Log(cx.KillAll)
-		config.Configure(cx, c.Command.Name)
+		if err = config.Configure(cx, c.Command.Name); Check(err) {
+			return
+		}
 		dbFilename := *cx.Config.DataDir + slash + cx.ActiveNet.
 			Params.Name + slash + wallet.WalletDbName
 		if !apputil.FileExists(dbFilename) {
@@ -43,3 +45,28 @@ func WalletHandle(cx *conte.Xt) func(c *cli.Context) (err error) {
 		return
 	}
 }
+
+// WalletRestoreHandle creates the wallet from the mnemonic or seed of an
+// existing wallet, which then finds the addresses it has used by scanning the
+// chain when it is started.
+func WalletRestoreHandle(cx *conte.Xt) func(c *cli.Context) (err error) {
+	return func(c *cli.Context) (err error) {
+		if err = config.Configure(cx, c.Command.Name); Check(err) {
+			return
+		}
+		dbFilename := *cx.Config.DataDir + slash + cx.ActiveNet.
+			Params.Name + slash + wallet.WalletDbName
+		if apputil.FileExists(dbFilename) {
+			err = fmt.Errorf("wallet already exists at %s, move it away to "+
+				"restore another", dbFilename)
+			Error(err)
+			return
+		}
+		if err = walletmain.RestoreWallet(cx.ActiveNet, cx.Config); Check(err) {
+			return
+		}
+		fmt.Println("restart to complete initial setup and scan for the " +
+			"wallet's addresses")
+		return
+	}
+}
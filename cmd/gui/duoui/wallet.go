@@ -1,6 +1,8 @@
 package duoui
 
 import (
+	"strings"
+
 	"gioui.org/layout"
 	"gioui.org/text"
 	"gioui.org/unit"
@@ -21,15 +23,45 @@ var (
 		SingleLine: true,
 		// Submit:     true,
 	}
+	mnemonicEditor = &gel.Editor{
+		// Submit:     true,
+	}
+	mnemonicPassEditor = &gel.Editor{
+		SingleLine: true,
+		// Submit:     true,
+	}
 	listWallet = &layout.List{
 		Axis: layout.Vertical,
 	}
+	// newMnemonic is the mnemonic generated for a new wallet, shown to be
+	// written down before the wallet is created from it
+	newMnemonic        string
+	createWalletErr    string
 	encryption         = new(gel.CheckBox)
 	seed               = new(gel.CheckBox)
+	shortMnemonic      = new(gel.CheckBox)
+	mnemonicSaved      = new(gel.CheckBox)
 	testnet            = new(gel.CheckBox)
 	buttonCreateWallet = new(gel.Button)
 )
 
+// newWalletMnemonic returns the mnemonic for a new wallet, generating one of
+// the number of words chosen when there is none or the choice changed.
+func (ui *DuoUI) newWalletMnemonic() string {
+	words := 24
+	if shortMnemonic.Checked(ui.ly.Context) {
+		words = 12
+	}
+	if len(strings.Fields(newMnemonic)) != words {
+		var err error
+		if newMnemonic, err = ui.rc.NewMnemonic(words); Check(err) {
+			newMnemonic = ""
+		}
+		mnemonicSaved.SetChecked(false)
+	}
+	return newMnemonic
+}
+
 func (ui *DuoUI) DuoUIloaderCreateWallet() {
 	cs := ui.ly.Context.Constraints
 	gelook.DuoUIdrawRectangle(ui.ly.Context, cs.Width.Max,
@@ -81,12 +113,59 @@ func (ui *DuoUI) DuoUIloaderCreateWallet() {
 			},
 			func() {
 				seedCheckBox := ui.ly.Theme.DuoUIcheckBox(
-					"Do you have an existing wallet seed you want to use?",
+					"Do you have an existing wallet mnemonic you want to restore?",
 					ui.ly.Theme.Colors["Dark"], ui.ly.Theme.Colors["Dark"])
 				seedCheckBox.Font.Typeface = ui.ly.Theme.Fonts["Primary"]
 				seedCheckBox.Color = gelook.HexARGB(ui.ly.Theme.Colors["Dark"])
 				seedCheckBox.Layout(ui.ly.Context, seed)
 			},
+			func() {
+				if !seed.Checked(ui.ly.Context) {
+					shortCheckBox := ui.ly.Theme.DuoUIcheckBox(
+						"Use a shorter 12 word mnemonic?",
+						ui.ly.Theme.Colors["Dark"], ui.ly.Theme.Colors["Dark"])
+					shortCheckBox.Font.Typeface = ui.ly.Theme.Fonts["Primary"]
+					shortCheckBox.Color = gelook.HexARGB(ui.ly.Theme.Colors["Dark"])
+					shortCheckBox.Layout(ui.ly.Context, shortMnemonic)
+					return
+				}
+				layout.UniformInset(unit.Dp(8)).Layout(ui.ly.Context, func() {
+					e := ui.ly.Theme.DuoUIeditor("Enter the mnemonic words", "Dark", "Light", 32)
+					e.Font.Typeface = ui.ly.Theme.Fonts["Primary"]
+					e.Font.Style = text.Regular
+					e.Layout(ui.ly.Context, mnemonicEditor)
+				})
+			},
+			func() {
+				if seed.Checked(ui.ly.Context) {
+					return
+				}
+				mnemonic := ui.ly.Theme.H6(ui.newWalletMnemonic())
+				mnemonic.Font.Typeface = ui.ly.Theme.Fonts["Secondary"]
+				mnemonic.Color = ui.ly.Theme.Colors["Dark"]
+				mnemonic.Layout(ui.ly.Context)
+			},
+			func() {
+				if seed.Checked(ui.ly.Context) {
+					return
+				}
+				savedCheckBox := ui.ly.Theme.DuoUIcheckBox(
+					"I have written down the mnemonic words in order and "+
+						"keep them in a safe place, as the wallet can NOT be"+
+						" restored without them",
+					ui.ly.Theme.Colors["Dark"], ui.ly.Theme.Colors["Dark"])
+				savedCheckBox.Font.Typeface = ui.ly.Theme.Fonts["Primary"]
+				savedCheckBox.Color = gelook.HexARGB(ui.ly.Theme.Colors["Dark"])
+				savedCheckBox.Layout(ui.ly.Context, mnemonicSaved)
+			},
+			func() {
+				layout.UniformInset(unit.Dp(8)).Layout(ui.ly.Context, func() {
+					e := ui.ly.Theme.DuoUIeditor("Mnemonic passphrase (optional)", "Dark", "Light", 32)
+					e.Font.Typeface = ui.ly.Theme.Fonts["Primary"]
+					e.Font.Style = text.Regular
+					e.Layout(ui.ly.Context, mnemonicPassEditor)
+				})
+			},
 			func() {
 				testnetCheckBox := ui.ly.Theme.DuoUIcheckBox(
 					"Use testnet?", ui.ly.Theme.Colors["Dark"], ui.ly.Theme.Colors["Dark"])
@@ -98,11 +177,27 @@ func (ui *DuoUI) DuoUIloaderCreateWallet() {
 				var createWalletbuttonComp gelook.DuoUIbutton
 				createWalletbuttonComp = ui.ly.Theme.DuoUIbutton(ui.ly.Theme.Fonts["Secondary"], "CREATE WALLET", ui.ly.Theme.Colors["Dark"], ui.ly.Theme.Colors["Light"], ui.ly.Theme.Colors["Light"], ui.ly.Theme.Colors["Dark"], "", ui.ly.Theme.Colors["Dark"], 16, 0, 125, 32, 4, 4, 4, 4)
 				for buttonCreateWallet.Clicked(ui.ly.Context) {
-					if passPhrase != "" && passPhrase == confirmPassPhrase {
+					restore := seed.Checked(ui.ly.Context)
+					mnemonic := newMnemonic
+					if restore {
+						mnemonic = strings.Join(strings.Fields(
+							strings.ToLower(mnemonicEditor.Text())), " ")
+					}
+					switch {
+					case passPhrase == "" || passPhrase != confirmPassPhrase:
+						createWalletErr = "the passphrases must match"
+					case !restore && !mnemonicSaved.Checked(ui.ly.Context):
+						createWalletErr = "write down the mnemonic words first"
+					default:
 						if testnet.Checked(ui.ly.Context) {
 							ui.rc.UseTestnet()
 						}
-						ui.rc.CreateWallet(passPhrase, "", "", "")
+						if err := ui.rc.CreateWallet(passPhrase, mnemonic,
+							mnemonicPassEditor.Text(), "", "", restore); Check(err) {
+							createWalletErr = err.Error()
+							break
+						}
+						createWalletErr = ""
 						if testnet.Checked(ui.ly.Context) {
 							interrupt.RequestRestart()
 						}
@@ -110,6 +205,15 @@ func (ui *DuoUI) DuoUIloaderCreateWallet() {
 				}
 				createWalletbuttonComp.Layout(ui.ly.Context, buttonCreateWallet)
 			},
+			func() {
+				if createWalletErr == "" {
+					return
+				}
+				errLabel := ui.ly.Theme.Body1(createWalletErr)
+				errLabel.Font.Typeface = ui.ly.Theme.Fonts["Primary"]
+				errLabel.Color = ui.ly.Theme.Colors["Danger"]
+				errLabel.Layout(ui.ly.Context)
+			},
 		}
 		listWallet.Layout(ui.ly.Context, len(controllers), func(i int) {
 			layout.UniformInset(unit.Dp(10)).Layout(ui.ly.Context, controllers[i])
@@ -9,8 +9,9 @@ import (
 
 // GetHashrate returns the exponential weighted moving average of the total hashrate and
 // a simple moving average for each block version. To decode the caller needs to use
-// fork.GetAlgoName(height, version) which returns the string name of the algorithm/block version
-// fork.List[<current hard fork id>].Algos[<block version number>].VersionInterval tells the number of
+// the AlgoName(version, height) of the hard fork schedule in the network parameters, which returns
+// the string name of the algorithm/block version, and Fork(height).Algos[<algorithm name>].VersionInterval
+// of the schedule tells the number of
 // seconds for this block version interval and the coinbase payment is scaled according to this ratio,
 // which is computed by
 func GetHashrate(hrb *ring.Ring) (hr float64, hrp map[int32]float64) {
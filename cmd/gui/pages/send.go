@@ -30,13 +30,22 @@ var (
 	buttonPasteAddress = new(gel.Button)
 	buttonPasteAmount  = new(gel.Button)
 	buttonSend         = new(gel.Button)
-	sendStruct         = new(send)
+	sendStruct         = &send{coinSelection: "bnb"}
+	coinSelections     = []struct {
+		value, label string
+		button       *gel.Button
+	}{
+		{"bnb", "CHANGELESS", new(gel.Button)},
+		{"avoidreuse", "AVOID REUSE", new(gel.Button)},
+		{"consolidate", "CONSOLIDATE", new(gel.Button)},
+	}
 )
 
 type send struct {
-	address    string
-	amount     float64
-	passPharse string
+	address       string
+	amount        float64
+	passPharse    string
+	coinSelection string
 }
 
 func Send(rc *rcd.RcVar, gtx *layout.Context, th *gelook.DuoUItheme) *gelook.DuoUIpage {
@@ -93,6 +102,9 @@ func sendBody(rc *rcd.RcVar, gtx *layout.Context, th *gelook.DuoUItheme) func()
 									})))
 						})
 					},
+					func() {
+						layout.Flex{}.Layout(gtx, coinSelectionButtons(gtx, th)...)
+					},
 					func() {
 						layout.Flex{}.Layout(gtx,
 							layout.Rigid(component.Button(gtx, th, buttonSend, th.Fonts["Primary"], 14, 10, 10, 9, 10,
@@ -100,7 +112,7 @@ func sendBody(rc *rcd.RcVar, gtx *layout.Context, th *gelook.DuoUItheme) func()
 									rc.Dialog.Show = true
 									rc.Dialog = &model.DuoUIdialog{
 										Show:       true,
-										Green:      rc.DuoSend(sendStruct.passPharse, sendStruct.address, 11),
+										Green:      rc.DuoSend(sendStruct.passPharse, sendStruct.address, 11, sendStruct.coinSelection),
 										GreenLabel: "SEND",
 										CustomField: func() {
 											layout.Flex{}.Layout(gtx,
@@ -124,3 +136,21 @@ func sendBody(rc *rcd.RcVar, gtx *layout.Context, th *gelook.DuoUItheme) func()
 		//Info("passPharse:" + sendStruct.passPharse)
 	}
 }
+
+// coinSelectionButtons lays out a button for each coin selection strategy, with
+// the one the send will use highlighted.
+func coinSelectionButtons(gtx *layout.Context, th *gelook.DuoUItheme) []layout.FlexChild {
+	children := make([]layout.FlexChild, len(coinSelections))
+	for i := range coinSelections {
+		cs := coinSelections[i]
+		color, bgColor := th.Colors["ButtonTextDim"], th.Colors["ButtonBgDim"]
+		if sendStruct.coinSelection == cs.value {
+			color, bgColor = th.Colors["ButtonText"], th.Colors["ButtonBg"]
+		}
+		children[i] = layout.Rigid(component.Button(gtx, th, cs.button, th.Fonts["Primary"], 12, 8, 10, 8, 10,
+			color, bgColor, cs.label, func() {
+				sendStruct.coinSelection = cs.value
+			}))
+	}
+	return children
+}
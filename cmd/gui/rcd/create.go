@@ -1,40 +1,51 @@
 package rcd
 
 import (
-	"encoding/hex"
 	"time"
 
 	"github.com/p9c/pod/app/save"
-	"github.com/p9c/pod/pkg/util/hdkeychain"
+	"github.com/p9c/pod/pkg/util/bip39"
 	"github.com/p9c/pod/pkg/wallet"
 )
 
-func (r *RcVar) CreateWallet(privPassphrase, duoSeed, pubPassphrase, walletDir string) {
-	var err error
+// NewMnemonic returns a new BIP39 mnemonic of 12 or 24 words for the seed of
+// a new wallet.
+func (r *RcVar) NewMnemonic(words int) (mnemonic string, err error) {
+	var bits int
+	if bits, err = bip39.EntropyBits(words); Check(err) {
+		return
+	}
+	var entropy []byte
+	if entropy, err = bip39.NewEntropy(bits); Check(err) {
+		return
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// CreateWallet creates the wallet from the seed of a BIP39 mnemonic and its
+// optional passphrase. When restore is true the mnemonic is of an existing
+// wallet, whose used addresses are found by scanning the chain from the
+// genesis block when the wallet starts.
+func (r *RcVar) CreateWallet(privPassphrase, mnemonic, mnemonicPassphrase,
+	pubPassphrase, walletDir string, restore bool) (err error) {
 	var seed []byte
 	if walletDir == "" {
 		walletDir = *r.cx.Config.WalletFile
 	}
 	l := wallet.NewLoader(r.cx.ActiveNet, *r.cx.Config.WalletFile, 250)
 
-	if duoSeed == "" {
-		seed, err = hdkeychain.GenerateSeed(hdkeychain.RecommendedSeedLen)
-		if err != nil {
-			Error(err)
-			panic(err)
-		}
-	} else {
-		seed, err = hex.DecodeString(duoSeed)
-		if err != nil {
-			// Need to make JS invocation to embed
-			Error(err)
-		}
+	if seed, err = bip39.NewSeedChecked(mnemonic, mnemonicPassphrase); Check(err) {
+		return
+	}
+	bday := time.Now()
+	if restore {
+		bday = r.cx.ActiveNet.GenesisBlock.Header.Timestamp
 	}
 
-	_, err = l.CreateNewWallet([]byte(pubPassphrase), []byte(privPassphrase), seed, time.Now(), true, r.cx.Config)
+	_, err = l.CreateNewWallet([]byte(pubPassphrase), []byte(privPassphrase), seed, bday, true, r.cx.Config)
 	if err != nil {
 		Error(err)
-		panic(err)
+		return
 	}
 
 	r.Boot.IsFirstRun = false
@@ -43,6 +54,7 @@ func (r *RcVar) CreateWallet(privPassphrase, duoSeed, pubPassphrase, walletDir s
 
 	save.Pod(r.cx.Config)
 	// Info(rc)
+	return
 }
 
 func (r *RcVar) UseTestnet() {
@@ -59,7 +59,7 @@ type Boot struct {
 //	GetDuoUItransactions(sfrom, count int, cat string)
 //	GetDuoUIbalance()
 //	GetDuoUItransactionsExcerpts()
-//	DuoSend(wp string, ad string, am float64)
+//	DuoSend(wp string, ad string, am float64, coinSelection string)
 //	GetDuoUItatus()
 //	PushDuoUIalert(t string, m interface{}, at string)
 //	GetDuoUIblockHeight()
@@ -19,7 +19,9 @@ func (r *RcVar) SaveDaemonCfg() {
 	config, _ := pod.EmptyConfig()
 	if err := js.Unmarshal(marshalled, config); err != nil {
 	}
-	config2.Configure(r.cx, r.cx.AppContext.Command.Name)
+	if err := config2.Configure(r.cx, r.cx.AppContext.Command.Name); Check(err) {
+		return
+	}
 	save.Pod(config)
 }
 
@@ -39,7 +39,7 @@ func (r *RcVar) GetDuoUIunconfirmedBalance() {
 	return
 }
 
-func (r *RcVar) DuoSend(wp string, ad string, am float64) func() {
+func (r *RcVar) DuoSend(wp string, ad string, am float64, coinSelection string) func() {
 	return func() {
 		pass := legacy.RPCHandlers["walletpassphrase"].Result()
 		pass.WalletPassphrase(&btcjson.WalletPassphraseCmd{
@@ -49,10 +49,11 @@ func (r *RcVar) DuoSend(wp string, ad string, am float64) func() {
 		pass.WalletPassphraseWait(nil)
 		send := legacy.RPCHandlers["sendtoaddress"].Result()
 		send.SendToAddress(&btcjson.SendToAddressCmd{
-			Address:   ad,
-			Amount:    am,
-			Comment:   nil,
-			CommentTo: nil,
+			Address:       ad,
+			Amount:        am,
+			Comment:       nil,
+			CommentTo:     nil,
+			CoinSelection: &coinSelection,
 		})
 		send.SendToAddressWait(nil)
 	}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/rpc"
 
+	"github.com/p9c/pod/cmd/kopach/worker"
 	"github.com/p9c/pod/pkg/kopachctrl/job"
 )
 
@@ -83,3 +84,36 @@ func (c *Client) SendPass(pass string) (err error) {
 	}
 	return
 }
+
+// SendController tells the worker to dispatch its solutions directly to the
+// controller at the given address instead of by multicast
+func (c *Client) SendController(pass, address string, tcp bool) (err error) {
+	Debug("sending dispatch controller", address)
+	var reply bool
+	err = c.Call("Worker.SendController",
+		worker.Controller{Pass: pass, Address: address, TCP: tcp}, &reply)
+	if err != nil {
+		Error(err)
+		return
+	}
+	if reply != true {
+		err = errors.New("send controller command not acknowledged")
+	}
+	return
+}
+
+// SendPoolAddress tells the worker to send its shares to the controller to be
+// credited to the given address
+func (c *Client) SendPoolAddress(address string) (err error) {
+	Debug("sending pool address", address)
+	var reply bool
+	err = c.Call("Worker.SendPoolAddress", address, &reply)
+	if err != nil {
+		Error(err)
+		return
+	}
+	if reply != true {
+		err = errors.New("send pool address command not acknowledged")
+	}
+	return
+}
@@ -3,12 +3,12 @@ package kopach_worker
 import (
 	"net/rpc"
 	"os"
+	"strconv"
 
 	"github.com/urfave/cli"
 
 	"github.com/p9c/pod/cmd/kopach/worker"
 	"github.com/p9c/pod/pkg/chain/config/netparams"
-	"github.com/p9c/pod/pkg/chain/fork"
 	"github.com/p9c/pod/pkg/conte"
 	log "github.com/p9c/pod/pkg/logi"
 	"github.com/p9c/pod/pkg/util/interrupt"
@@ -27,16 +27,37 @@ func KopachWorkerHandle(cx *conte.Xt) func(c *cli.Context) error {
 		// it is only the other way around that there could be problems with
 		// testnet probably never as high as this and hard fork activates early
 		// for testing as pre-hardfork doesn't need testing or CPU mining.
+		// Blocks are hashed by the hard fork schedule of the network, and the
+		// regression test and simulation networks can move the first hard
+		// fork, which is passed as the third parameter.
+		params := &netparams.MainNetParams
 		if len(os.Args) > 2 {
-			if os.Args[2] == netparams.TestNet3Params.Name {
-				fork.IsTestnet = true
+			switch os.Args[2] {
+			case netparams.TestNet3Params.Name:
+				params = &netparams.TestNet3Params
+			case netparams.RegressionTestParams.Name:
+				params = &netparams.RegressionTestParams
+			case netparams.SimNetParams.Name:
+				params = &netparams.SimNetParams
 			}
 		}
 		if len(os.Args) > 3 {
 			log.L.SetLevel(os.Args[3], true, "pod")
 		}
+		if len(os.Args) > 4 && (params.Name == netparams.RegressionTestParams.Name ||
+			params.Name == netparams.SimNetParams.Name) {
+			height, err := strconv.Atoi(os.Args[4])
+			if Check(err) {
+				return err
+			}
+			if height > 0 {
+				if params, err = params.WithForkHeight(int32(height)); Check(err) {
+					return err
+				}
+			}
+		}
 		Debug("miner worker starting")
-		w, conn := worker.New(cx.KillAll)
+		w, conn := worker.New(cx.KillAll, params.Forks)
 		interrupt.AddHandler(func() {
 			Debug("KopachWorkerHandle interrupt")
 			if err := conn.Close(); Check(err) {
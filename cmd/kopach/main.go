@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/urfave/cli"
@@ -28,8 +29,13 @@ type HashCount struct {
 }
 
 type Worker struct {
-	active        atomic.Bool
+	active atomic.Bool
+	// mx guards the connection and the selected controller, which the
+	// controller watcher switches
+	mx            sync.Mutex
 	conn          *transport.Channel
+	controllers   []string
+	controller    int
 	ctx           context.Context
 	quit          chan struct{}
 	cx            *conte.Xt
@@ -46,6 +52,7 @@ func KopachHandle(cx *conte.Xt) func(c *cli.Context) error {
 	return func(c *cli.Context) (err error) {
 		Debug("miner controller starting")
 		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
 		w := &Worker{
 			ctx:           ctx,
 			cx:            cx,
@@ -54,14 +61,19 @@ func KopachHandle(cx *conte.Xt) func(c *cli.Context) error {
 		}
 		w.lastSent.Store(time.Now().UnixNano())
 		w.active.Store(false)
-		Debug("opening broadcast channel listener")
-		w.conn, err = transport.
-			NewBroadcastChannel("kopachmain", w, *cx.Config.MinerPass,
-				transport.DefaultPort, kopachctrl.MaxDatagramSize, handlers,
-				cx.KillAll)
+		w.controllers = *cx.Config.MinerControllers
+		if len(w.controllers) > 0 {
+			Debug("connecting to miner controllers", w.controllers)
+			err = w.connectController()
+		} else {
+			Debug("opening broadcast channel listener")
+			w.conn, err = transport.
+				NewBroadcastChannel("kopachmain", w, *cx.Config.MinerPass,
+					transport.DefaultPort, kopachctrl.MaxDatagramSize, handlers,
+					cx.KillAll)
+		}
 		if err != nil {
 			Error(err)
-			cancel()
 			return
 		}
 		var wks []*worker.Worker
@@ -70,7 +82,8 @@ func KopachHandle(cx *conte.Xt) func(c *cli.Context) error {
 		for i := 0; i < *cx.Config.GenThreads; i++ {
 			Debug("starting worker", i)
 			cmd := worker.Spawn(os.Args[0], "worker",
-				cx.ActiveNet.Name, *cx.Config.LogLevel)
+				cx.ActiveNet.Name, *cx.Config.LogLevel,
+				fmt.Sprint(*cx.Config.ForkHeight))
 			wks = append(wks, cmd)
 			w.workers = append(w.workers, client.New(cmd.StdConn))
 		}
@@ -83,11 +96,24 @@ func KopachHandle(cx *conte.Xt) func(c *cli.Context) error {
 				Debug("stopped worker", i)
 			}
 		})
-		for i := range w.workers {
-			Debug("sending pass to worker", i)
-			err := w.workers[i].SendPass(*cx.Config.MinerPass)
-			if err != nil {
-				Error(err)
+		if len(w.controllers) > 0 {
+			w.sendController()
+		} else {
+			for i := range w.workers {
+				Debug("sending pass to worker", i)
+				err := w.workers[i].SendPass(*cx.Config.MinerPass)
+				if err != nil {
+					Error(err)
+				}
+			}
+		}
+		if *cx.Config.PoolAddress != "" {
+			for i := range w.workers {
+				Debug("sending pool address to worker", i)
+				err := w.workers[i].SendPoolAddress(*cx.Config.PoolAddress)
+				if err != nil {
+					Error(err)
+				}
 			}
 		}
 		w.active.Store(true)
@@ -118,18 +144,67 @@ func KopachHandle(cx *conte.Xt) func(c *cli.Context) error {
 							}
 						}
 					}
+					// try the next configured controller if the current
+					// one is not sending work
+					if len(w.controllers) > 1 && since > time.Second*3 {
+						w.mx.Lock()
+						w.controller = (w.controller + 1) % len(w.controllers)
+						w.mx.Unlock()
+						if err := w.connectController(); !Check(err) {
+							w.sendController()
+						}
+						w.lastSent.Store(time.Now().UnixNano())
+					}
 				case <-cx.KillAll:
 					break out
 				}
 			}
 		}()
-		Debug("listening on", kopachctrl.UDP4MulticastAddress)
+		if len(w.controllers) > 0 {
+			Debug("receiving work from controllers", w.controllers)
+		} else {
+			Debug("listening on", kopachctrl.UDP4MulticastAddress)
+		}
 		<-cx.KillAll
 		Info("kopach shutting down")
 		return
 	}
 }
 
+// connectController replaces the connection of the kopach with one to the
+// currently selected configured controller
+func (w *Worker) connectController() (err error) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+	Debug("connecting to controller", w.controllers[w.controller])
+	if w.conn != nil {
+		if err = w.conn.Close(); Check(err) {
+		}
+	}
+	w.FirstSender.Store("")
+	w.conn, err = transport.NewClientChannel("kopachmain", w,
+		*w.cx.Config.MinerPass, w.controllers[w.controller],
+		*w.cx.Config.MinerTCP, kopachctrl.MaxDatagramSize, handlers,
+		w.quit)
+	return
+}
+
+// sendController tells the workers to dispatch their solutions to the
+// currently selected controller
+func (w *Worker) sendController() {
+	w.mx.Lock()
+	controller := w.controllers[w.controller]
+	w.mx.Unlock()
+	for i := range w.workers {
+		Debug("sending controller to worker", i)
+		err := w.workers[i].SendController(*w.cx.Config.MinerPass,
+			controller, *w.cx.Config.MinerTCP)
+		if err != nil {
+			Error(err)
+		}
+	}
+}
+
 // these are the handlers for specific message types.
 var handlers = transport.Handlers{
 	string(job.Magic): func(ctx interface{}, src net.Addr, dst string,
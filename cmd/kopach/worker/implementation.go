@@ -6,6 +6,7 @@ import (
 	blockchain "github.com/p9c/pod/pkg/chain"
 	"github.com/p9c/pod/pkg/chain/fork"
 	"github.com/p9c/pod/pkg/kopachctrl/hashrate"
+	"github.com/p9c/pod/pkg/kopachctrl/share"
 	"github.com/p9c/pod/pkg/kopachctrl/sol"
 	"math/rand"
 	"net"
@@ -51,6 +52,9 @@ type Worker struct {
 	running       atomic.Bool
 	hashCount     atomic.Uint64
 	hashSampleBuf *ring.BufferUint64
+	poolAddress   atomic.String
+	// forks is the hard fork schedule of the network blocks are hashed by
+	forks *fork.Schedule
 }
 
 type Counter struct {
@@ -118,12 +122,14 @@ func (w *Worker) hashReport() {
 // NewWithConnAndSemaphore is exposed to enable use an actual network
 // connection while retaining the same RPC API to allow a worker to be
 // configured to run on a bare metal system with a different launcher main
-func NewWithConnAndSemaphore(conn *stdconn.StdConn, quit chan struct{}) *Worker {
+func NewWithConnAndSemaphore(conn *stdconn.StdConn, quit chan struct{},
+	forks *fork.Schedule) *Worker {
 	Debug("creating new worker")
 	msgBlock := wire.MsgBlock{Header: wire.BlockHeader{}}
 	w := &Worker{
 		pipeConn:      conn,
 		Quit:          quit,
+		forks:         forks,
 		roller:        NewCounter(RoundsPerAlgo),
 		startChan:     make(chan struct{}),
 		stopChan:      make(chan struct{}),
@@ -234,17 +240,21 @@ out:
 						w.hashCount.Store(w.hashCount.Load() + uint64(w.roller.RoundsPerAlgo.Load()))
 						nextAlgo = w.roller.C.Load() + 1
 						hashReport := hashrate.Get(w.roller.RoundsPerAlgo.Load(), nextAlgo, nH)
-						err := w.dispatchConn.SendMany(hashrate.HashrateMagic,
+						err := w.dispatch().SendMany(hashrate.HashrateMagic,
 							transport.GetShards(hashReport.Data))
 						if err != nil {
 							Error(err)
 						}
 					}
-					hash := mb.Header.BlockHashWithAlgos(nH)
+					hash, err := mb.Header.BlockHashWithAlgos(w.forks, nH)
+					if err != nil {
+						Error(err)
+						continue
+					}
 					bigHash := blockchain.HashToBig(&hash)
 					if bigHash.Cmp(fork.CompactToBig(mb.Header.Bits)) <= 0 {
 						srs := sol.GetSolContainer(w.senderPort.Load(), mb)
-						err := w.dispatchConn.SendMany(sol.SolutionMagic,
+						err := w.dispatch().SendMany(sol.SolutionMagic,
 							transport.GetShards(srs.Data))
 						if err != nil {
 							Error(err)
@@ -253,6 +263,18 @@ out:
 
 						break running
 					}
+					// hashes that meet the share target are sent to the
+					// controller to be credited to the pool address
+					if addr := w.poolAddress.Load(); addr != "" &&
+						bigHash.Cmp(share.Target(mb.Header.Bits)) <= 0 {
+						shr := share.Get(w.senderPort.Load(), addr, &mb.Header)
+						err := w.dispatch().SendMany(share.Magic,
+							transport.GetShards(shr.Data))
+						if err != nil {
+							Error(err)
+						}
+						Trace("sent share")
+					}
 					mb.Header.Version = nextAlgo
 					mb.Header.Bits = w.bitses.Load().(blockchain.TargetBits)[mb.Header.Version]
 					mb.Header.Nonce++
@@ -266,11 +288,12 @@ out:
 
 // New initialises the state for a worker, loading the work
 // function handler that runs a round of processing between
-// checking quit signal and work semaphore
-func New(quit chan struct{}) (w *Worker, conn net.Conn) {
+// checking quit signal and work semaphore. Blocks are hashed by the hard fork
+// schedule of the network.
+func New(quit chan struct{}, forks *fork.Schedule) (w *Worker, conn net.Conn) {
 	// log.L.SetLevel("trace", true)
 	sc := stdconn.New(os.Stdin, os.Stdout, quit)
-	return NewWithConnAndSemaphore(&sc, quit), &sc
+	return NewWithConnAndSemaphore(&sc, quit, forks), &sc
 }
 
 // NewJob is a delivery of a new job for the worker, this
@@ -367,14 +390,65 @@ func (w *Worker) SendPass(pass string, reply *bool) (err error) {
 	// sp := fmt.Sprint(rand.Intn(32767) + 1025)
 	// rp := fmt.Sprint(rand.Intn(32767) + 1025)
 	var conn *transport.Channel
-	conn, err = transport.NewBroadcastChannel(
+	if conn, err = transport.NewBroadcastChannel(
 		"kopachworker", w, pass, transport.DefaultPort,
-		kopachctrl.MaxDatagramSize, transport.Handlers{}, w.Quit)
-	if err != nil {
-		Error(err)
+		kopachctrl.MaxDatagramSize, transport.Handlers{}, w.Quit); Check(err) {
+		return
 	}
-	w.dispatchConn = conn
-	w.dispatchReady.Store(true)
+	w.setDispatch(conn)
+	*reply = true
+	return
+}
+
+// SendPoolAddress gives the address the worker's shares are credited to by a
+// controller that is run as a pool, an empty address stops sending shares
+func (w *Worker) SendPoolAddress(address string, reply *bool) (err error) {
+	Debug("receiving pool address", address)
+	w.poolAddress.Store(address)
+	*reply = true
+	return
+}
+
+// Controller is a miner controller that a worker dispatches its solutions to
+// directly, for when multicast is not available
+type Controller struct {
+	Pass    string
+	Address string
+	TCP     bool
+}
+
+// SendController gives the address and encryption key of the controller that
+// the kopach is connected to by unicast, replacing the previous dispatch
+// connection of the worker
+func (w *Worker) SendController(ctl Controller, reply *bool) (err error) {
+	Debug("receiving dispatch controller", ctl.Address, "tcp", ctl.TCP)
+	var conn *transport.Channel
+	if conn, err = transport.NewClientChannel("kopachworker", w, ctl.Pass,
+		ctl.Address, ctl.TCP, kopachctrl.MaxDatagramSize,
+		transport.Handlers{}, w.Quit); Check(err) {
+		return
+	}
+	w.setDispatch(conn)
 	*reply = true
 	return
 }
+
+// setDispatch replaces the connection solutions and hashrate reports are sent
+// on, closing the previous one
+func (w *Worker) setDispatch(conn *transport.Channel) {
+	w.mx.Lock()
+	prev := w.dispatchConn
+	w.dispatchConn = conn
+	w.mx.Unlock()
+	if prev != nil {
+		if err := prev.Close(); Check(err) {
+		}
+	}
+	w.dispatchReady.Store(true)
+}
+
+func (w *Worker) dispatch() *transport.Channel {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+	return w.dispatchConn
+}
@@ -137,6 +137,10 @@ const (
 	// DefaultGenThreads            = 1
 	// DefaultMinerListener         = "127.0.0.1:11011"
 	DefaultMaxOrphanTransactions = 100
+	// DefaultMaxMempoolSize is the default limit of the mempool size in MiB
+	DefaultMaxMempoolSize = 300
+	// MaxMempoolSizeMin is the smallest mempool size limit in MiB
+	MaxMempoolSizeMin = 5
 	// DefaultMaxOrphanTxSize       = 100000
 	DefaultSigCacheMaxSize = 100000
 	// These are set to default on because more often one wants them than not
@@ -434,19 +438,16 @@ func loadConfig() (
 	if cfg.TestNet3 {
 		numNets++
 		ActiveNetParams = &TestNet3Params
-		fork.IsTestnet = true
 	}
 	if cfg.RegressionTest {
 		numNets++
 		ActiveNetParams = &RegressionTestParams
-		fork.IsTestnet = true
 	}
 	if cfg.SimNet {
 		numNets++
 		// Also disable dns seeding on the simulation test network.
 		ActiveNetParams = &SimNetParams
 		cfg.DisableDNSSeed = true
-		fork.IsTestnet = true
 	}
 	if numNets > 1 {
 		str := "%s: The testnet, regtest, segnet, and simnet netparams " +
@@ -24,6 +24,8 @@ COMMANDS:
      dropaddrindex  drop the address search index
      droptxindex    drop the address search index
      dropcfindex    drop the address search index
+     dumputxo       write a snapshot of the utxo set to a file
+     loadutxo       start a new chain from a snapshot of the utxo set
 
 GLOBAL OPTIONS:
    --help, -h  show help
@@ -11,6 +11,7 @@ import (
 
 	"github.com/p9c/pod/cmd/node/blockdb"
 	"github.com/p9c/pod/pkg/kopachctrl"
+	"github.com/p9c/pod/pkg/metrics"
 
 	"github.com/p9c/pod/app/apputil"
 	"github.com/p9c/pod/cmd/node/path"
@@ -110,6 +111,12 @@ func Main(cx *conte.Xt, shutdownChan chan struct{}) (err error) {
 	if interrupt.Requested() {
 		return nil
 	}
+	// start the chain from a utxo snapshot if requested
+	if cx.StateCfg.LoadUtxoSnapshot != "" {
+		if err = loadUtxoSnapshot(cx, db); Check(err) {
+			return
+		}
+	}
 	// drop indexes and exit if requested.
 	// NOTE: The order is important here because dropping the
 	// tx index also drops the address index since it relies on it
@@ -152,7 +159,18 @@ func Main(cx *conte.Xt, shutdownChan chan struct{}) (err error) {
 			*cx.Config.Listeners, err)
 		return err
 	}
-
+	// serve metrics if requested
+	var metricsServer *metrics.Server
+	if *cx.Config.MetricsListener != "" {
+		server.RegisterMetrics()
+		metricsServer, err = metrics.Listen(*cx.Config.MetricsListener,
+			metrics.Default)
+		if err != nil {
+			Errorf("unable to serve metrics on %v: %v",
+				*cx.Config.MetricsListener, err)
+			return err
+		}
+	}
 	server.Start()
 	cx.RealNode = server
 	if len(server.RPCServers) > 0 {
@@ -170,6 +188,9 @@ func Main(cx *conte.Xt, shutdownChan chan struct{}) (err error) {
 	gracefulShutdown := func() {
 		Info("gracefully shutting down the server...")
 		// server.CPUMiner.Stop()
+		if metricsServer != nil {
+			metricsServer.Stop()
+		}
 		Debug("stopping controller")
 		e := server.Stop()
 		if e != nil {
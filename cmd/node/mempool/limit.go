@@ -0,0 +1,263 @@
+package mempool
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+	"time"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/util"
+)
+
+const (
+	// rollingFeeHalfLife is the time it takes the rolling minimum fee to
+	// halve once a block has been connected since it was last raised, while
+	// the pool is at least half full. It decays faster in an emptier pool.
+	rollingFeeHalfLife = time.Hour * 12
+	// rollingFeeUpdateInterval is the minimum amount of time in between
+	// decays of the rolling minimum fee.
+	rollingFeeUpdateInterval = time.Second * 10
+)
+
+// Size returns the total virtual size of the transactions in the pool.
+// This function is safe for concurrent access.
+func (mp *TxPool) Size() int64 {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+	return mp.poolSize
+}
+
+// MinFee returns the fee rate in DUO/kB a transaction must pay to be accepted
+// into the pool, which is the minimum relay fee, or higher when transactions
+// have been evicted to keep the pool under its size limit.
+// This function is safe for concurrent access.
+func (mp *TxPool) MinFee() util.Amount {
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+	minFee := util.Amount(mp.minFee())
+	if minFee < mp.cfg.Policy.MinRelayTxFee {
+		return mp.cfg.Policy.MinRelayTxFee
+	}
+	return minFee
+}
+
+// BlockConnected lets the rolling minimum fee start decaying again after it
+// was raised by evicting transactions, as the transactions of the block have
+// made room in the pool. This function is safe for concurrent access.
+func (mp *TxPool) BlockConnected() {
+	mp.mtx.Lock()
+	mp.blockSinceFeeBump = true
+	mp.lastRollingFeeUpdate = time.Now()
+	mp.mtx.Unlock()
+}
+
+// minFee returns the rolling minimum fee rate in DUO/kB, or zero if no
+// transactions have been evicted since it last decayed away. It halves every
+// rollingFeeHalfLife once a block has been connected since it was raised, and
+// every quarter or half of that while the pool is less than a quarter or half
+// full.
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) minFee() int64 {
+	if !mp.blockSinceFeeBump || mp.rollingMinFee == 0 {
+		return int64(math.Round(mp.rollingMinFee))
+	}
+	now := time.Now()
+	elapsed := now.Sub(mp.lastRollingFeeUpdate)
+	if elapsed > rollingFeeUpdateInterval {
+		halfLife := rollingFeeHalfLife
+		switch limit := mp.cfg.Policy.MaxMempoolSize; {
+		case mp.poolSize < limit/4:
+			halfLife /= 4
+		case mp.poolSize < limit/2:
+			halfLife /= 2
+		}
+		mp.rollingMinFee /= math.Pow(2, float64(elapsed)/float64(halfLife))
+		mp.lastRollingFeeUpdate = now
+		if mp.rollingMinFee < float64(mp.cfg.Policy.MinRelayTxFee)/2 {
+			mp.rollingMinFee = 0
+			return 0
+		}
+	}
+	minFee := int64(math.Round(mp.rollingMinFee))
+	if minFee < int64(mp.cfg.Policy.MinRelayTxFee) {
+		return int64(mp.cfg.Policy.MinRelayTxFee)
+	}
+	return minFee
+}
+
+// evictionFeePerKB returns the fee rate a transaction is evicted by when the
+// pool is full, which is the higher of its own fee rate and that of the
+// package of it and its descendants, as evicting it also evicts them. Its
+// own fee includes any fee delta set with PrioritiseTransaction.
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) evictionFeePerKB(desc *TxDesc) int64 {
+	delta := mp.feeDeltas[*desc.Tx.Hash()]
+	own := (desc.Fee + delta) * 1000 / GetTxVirtualSize(desc.Tx)
+	pkg := (desc.descendantStats.Fees + delta) * 1000 /
+		desc.descendantStats.Size
+	if pkg > own {
+		return pkg
+	}
+	return own
+}
+
+// evictionQueue is a min-heap of the transactions in the pool by the fee rate
+// they are evicted by, so that the next transaction to evict is found without
+// looking at all of them. It implements heap.Interface.
+type evictionQueue []*TxDesc
+
+// Len returns the number of transactions in the queue. It is part of the
+// heap.Interface implementation.
+func (q evictionQueue) Len() int {
+	return len(q)
+}
+
+// Less returns whether the transaction at index i is evicted before the one
+// at index j. It is part of the heap.Interface implementation.
+func (q evictionQueue) Less(i, j int) bool {
+	return q[i].evictionFee < q[j].evictionFee
+}
+
+// Swap swaps the transactions at the passed indices in the queue. It is part
+// of the heap.Interface implementation.
+func (q evictionQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].evictionIndex = i
+	q[j].evictionIndex = j
+}
+
+// Push pushes the passed transaction onto the queue. It is part of the
+// heap.Interface implementation.
+func (q *evictionQueue) Push(x interface{}) {
+	desc := x.(*TxDesc)
+	desc.evictionIndex = len(*q)
+	*q = append(*q, desc)
+}
+
+// Pop removes the last transaction of the queue. It is part of the
+// heap.Interface implementation.
+func (q *evictionQueue) Pop() interface{} {
+	old := *q
+	desc := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	desc.evictionIndex = -1
+	return desc
+}
+
+// contains returns whether a transaction is in the queue
+func (q evictionQueue) contains(desc *TxDesc) bool {
+	i := desc.evictionIndex
+	return i >= 0 && i < len(q) && q[i] == desc
+}
+
+// addEviction adds a transaction that was just added to the pool to the
+// eviction queue.
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) addEviction(desc *TxDesc) {
+	desc.evictionFee = mp.evictionFeePerKB(desc)
+	heap.Push(&mp.evictionQueue, desc)
+}
+
+// fixEviction moves a transaction in the eviction queue after its fee delta
+// or its descendants have changed. Transactions that are not in the queue are
+// ignored.
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) fixEviction(desc *TxDesc) {
+	if !mp.evictionQueue.contains(desc) {
+		return
+	}
+	desc.evictionFee = mp.evictionFeePerKB(desc)
+	heap.Fix(&mp.evictionQueue, desc.evictionIndex)
+}
+
+// removeEviction removes a transaction that left the pool from the eviction
+// queue.
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) removeEviction(desc *TxDesc) {
+	if mp.evictionQueue.contains(desc) {
+		heap.Remove(&mp.evictionQueue, desc.evictionIndex)
+	}
+}
+
+// trimToSize evicts the transactions with the lowest fee rates, along with
+// their descendants, until the pool is no larger than MaxMempoolSize, and
+// raises the rolling minimum fee above the fee rate of the evicted
+// transactions, so that they are not replaced by others paying as little.
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) trimToSize() {
+	limit := mp.cfg.Policy.MaxMempoolSize
+	if limit <= 0 {
+		return
+	}
+	var evicted int
+	for mp.poolSize > limit && len(mp.evictionQueue) > 0 {
+		worst := mp.evictionQueue[0]
+		// The next transaction must pay more than the evicted one by at
+		// least the relay fee, which pays for relaying the evicted one.
+		feePerKB := float64(worst.evictionFee +
+			int64(mp.cfg.Policy.MinRelayTxFee))
+		if feePerKB > mp.rollingMinFee {
+			mp.rollingMinFee = feePerKB
+			mp.blockSinceFeeBump = false
+		}
+		evicted += 1 + len(mp.txDescendants(worst.Tx))
+		mp.removeTransaction(worst.Tx, true)
+	}
+	if evicted > 0 {
+		Debugf("evicted %d transactions to keep the pool under %d bytes, "+
+			"minimum fee is now %v/kB", evicted, limit,
+			util.Amount(int64(mp.rollingMinFee)))
+	}
+}
+
+// replacementEvicted returns whether a replacement of the passed virtual size
+// and fee rate would be evicted by trimToSize after the transactions it
+// conflicts with are removed and it is added, so that they are not removed
+// for a replacement that does not stay in the pool. The eviction is simulated
+// in the order of the eviction queue, without lowering the fee rates of the
+// packages that lose descendants, and a replacement paying the same fee rate
+// as the next transaction to evict is taken as evicted.
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) replacementEvicted(size, feePerKB int64,
+	conflicts map[chainhash.Hash]*TxDesc) bool {
+	limit := mp.cfg.Policy.MaxMempoolSize
+	if limit <= 0 {
+		return false
+	}
+	poolSize := mp.poolSize + size
+	removed := make(map[chainhash.Hash]struct{}, len(conflicts))
+	for hash, desc := range conflicts {
+		removed[hash] = struct{}{}
+		poolSize -= GetTxVirtualSize(desc.Tx)
+	}
+	if poolSize <= limit {
+		return false
+	}
+	queue := make([]*TxDesc, len(mp.evictionQueue))
+	copy(queue, mp.evictionQueue)
+	sort.Slice(queue, func(i, j int) bool {
+		return queue[i].evictionFee < queue[j].evictionFee
+	})
+	for _, desc := range queue {
+		if poolSize <= limit {
+			return false
+		}
+		if _, ok := removed[*desc.Tx.Hash()]; ok {
+			continue
+		}
+		if feePerKB <= desc.evictionFee {
+			return true
+		}
+		removed[*desc.Tx.Hash()] = struct{}{}
+		poolSize -= GetTxVirtualSize(desc.Tx)
+		for hash, descendant := range mp.txDescendants(desc.Tx) {
+			if _, ok := removed[hash]; !ok {
+				removed[hash] = struct{}{}
+				poolSize -= GetTxVirtualSize(descendant.Tx)
+			}
+		}
+	}
+	return poolSize > limit
+}
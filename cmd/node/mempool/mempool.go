@@ -12,7 +12,6 @@ import (
 	blockchain "github.com/p9c/pod/pkg/chain"
 	chaincfg "github.com/p9c/pod/pkg/chain/config"
 	"github.com/p9c/pod/pkg/chain/config/netparams"
-	"github.com/p9c/pod/pkg/chain/hardfork"
 	chainhash "github.com/p9c/pod/pkg/chain/hash"
 	indexers "github.com/p9c/pod/pkg/chain/index"
 	"github.com/p9c/pod/pkg/chain/mining"
@@ -96,6 +95,23 @@ type Policy struct {
 	// MinRelayTxFee defines the minimum transaction fee in DUO/kB to be
 	// considered a non-zero fee.
 	MinRelayTxFee util.Amount
+	// RejectReplacement defines whether to reject transactions that replace
+	// transactions in the pool, even if those signal replaceability.
+	RejectReplacement bool
+	// MaxMempoolSize is the limit in bytes of the total virtual size of the
+	// transactions in the pool, above which those paying the lowest fee
+	// rates are evicted. Zero means no limit.
+	MaxMempoolSize int64
+	// MaxAncestorCount and MaxAncestorSize limit the number and total
+	// virtual size of the transactions in the pool that a transaction spends
+	// from, counting itself. Zero means no limit.
+	MaxAncestorCount int64
+	MaxAncestorSize  int64
+	// MaxDescendantCount and MaxDescendantSize limit the number and total
+	// virtual size of the transactions in the pool that spend from any
+	// transaction in the pool, counting itself. Zero means no limit.
+	MaxDescendantCount int64
+	MaxDescendantSize  int64
 }
 
 type // Tag represents an identifier to use for tagging orphan transactions.
@@ -110,6 +126,17 @@ TxDesc struct {
 	// StartingPriority is the priority of the transaction when it was added
 	// to the pool.
 	StartingPriority float64
+	// ancestorStats and descendantStats are the statistics of the
+	// transaction with its ancestors and with its descendants in the pool.
+	// They are kept up to date as transactions enter and leave the pool and
+	// must only be accessed with the mempool lock held.
+	ancestorStats   packageStats
+	descendantStats packageStats
+	// evictionFee is the fee rate the transaction is evicted by when the pool
+	// is full, and evictionIndex is its index in the eviction queue of the
+	// pool.
+	evictionFee   int64
+	evictionIndex int
 }
 
 type // TxPool is used as a source of transactions that need to be mined into
@@ -126,6 +153,22 @@ TxPool struct {
 	outpoints     map[wire.OutPoint]*util.Tx
 	pennyTotal    float64 // exponentially decaying total for penny spends.
 	lastPennyUnix int64   // unix time of last ``penny spend''
+	// feeDeltas holds the amounts added to the fees of transactions when
+	// they are chosen for a block, set with PrioritiseTransaction.
+	feeDeltas map[chainhash.Hash]int64
+	// poolSize is the total virtual size of the transactions in the pool.
+	poolSize int64
+	// evictionQueue orders the transactions in the pool by the fee rate
+	// they are evicted by when the pool is full.
+	evictionQueue evictionQueue
+	// rollingMinFee is the fee rate in DUO/kB a transaction must pay to
+	// enter the pool after transactions were evicted to keep it under
+	// MaxMempoolSize. It decays from when a block is connected after it was
+	// last raised, which blockSinceFeeBump records, and
+	// lastRollingFeeUpdate is the time it last decayed.
+	rollingMinFee        float64
+	blockSinceFeeBump    bool
+	lastRollingFeeUpdate time.Time
 	// nextExpireScan is the time after which the orphan pool will be scanned
 	// in order to evict orphans.
 	// This is NOT a hard deadline as the scan will only run when an orphan
@@ -150,6 +193,16 @@ const (
 	// consequently affects their relay and inclusion when generating block
 	// templates.
 	DefaultBlockPrioritySize = 50000
+	// DefaultMaxAncestorCount and DefaultMaxDescendantCount are the default
+	// limits of the number of transactions in a package of unconfirmed
+	// transactions in the pool.
+	DefaultMaxAncestorCount   = 25
+	DefaultMaxDescendantCount = 25
+	// DefaultMaxAncestorSize and DefaultMaxDescendantSize are the default
+	// limits in bytes of the virtual size of a package of unconfirmed
+	// transactions in the pool.
+	DefaultMaxAncestorSize   = 101000
+	DefaultMaxDescendantSize = 101000
 	// orphanTTL is the maximum amount of time an orphan is allowed to stay
 	// in the orphan pool before it expires and is evicted during the next scan.
 	orphanTTL = time.Minute * 15
@@ -264,7 +317,7 @@ func // MiningDescs returns a slice of mining descriptors for all the
 	descs := make([]*mining.TxDesc, len(mp.pool))
 	i := 0
 	for _, desc := range mp.pool {
-		descs[i] = &desc.TxDesc
+		descs[i] = mp.miningDesc(desc)
 		i++
 	}
 	mp.mtx.RUnlock()
@@ -519,9 +572,12 @@ func // addTransaction adds the passed transaction to the memory pool.
 		StartingPriority: mining.CalcPriority(tx.MsgTx(), utxoView, height),
 	}
 	mp.pool[*tx.Hash()] = txD
+	mp.poolSize += GetTxVirtualSize(tx)
 	for _, txIn := range tx.MsgTx().TxIn {
 		mp.outpoints[txIn.PreviousOutPoint] = tx
 	}
+	mp.addToPackages(txD)
+	mp.addEviction(txD)
 	atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
 	// Add unconfirmed address index entries associated with the transaction
 	// if enabled.
@@ -537,19 +593,29 @@ func // addTransaction adds the passed transaction to the memory pool.
 
 func // checkPoolDoubleSpend checks whether or not the passed transaction is
 // attempting to spend coins already spent by other transactions in the pool.
+// Spending coins spent by transactions that signal replaceability is allowed
+// unless the policy rejects replacements, and makes the transaction a
+// replacement, which is reported so it can be checked against the rules for
+// replacements once its fee is known.
 // Note it does not check for double spends against transactions already in
 // the main chain.
 // This function MUST be called with the mempool lock held (for reads).
-(mp *TxPool) checkPoolDoubleSpend(tx *util.Tx) error {
+(mp *TxPool) checkPoolDoubleSpend(tx *util.Tx) (bool, error) {
+	var isReplacement bool
 	for _, txIn := range tx.MsgTx().TxIn {
-		if txR, exists := mp.outpoints[txIn.PreviousOutPoint]; exists {
+		txR, exists := mp.outpoints[txIn.PreviousOutPoint]
+		if !exists {
+			continue
+		}
+		if mp.cfg.Policy.RejectReplacement || !mp.signalsReplacement(txR) {
 			str := fmt.Sprintf("output %v already spent by "+
 				"transaction %v in the memory pool",
 				txIn.PreviousOutPoint, txR.Hash())
-			return txRuleError(wire.RejectDuplicate, str)
+			return false, txRuleError(wire.RejectDuplicate, str)
 		}
+		isReplacement = true
 	}
-	return nil
+	return isReplacement, nil
 }
 
 func // fetchInputUtxos loads utxo details about the input transactions
@@ -678,7 +744,7 @@ func // maybeAcceptTransaction is the internal function which implements the
 			return nil, nil, txRuleError(wire.RejectNonstandard, str)
 		}
 	}
-	if blockchain.ContainsBlacklisted(b, tx, hardfork.Blacklist) {
+	if blockchain.ContainsBlacklisted(b, tx) {
 		return nil, nil, errors.New("transaction contains blacklisted address")
 	}
 	// Don't accept the transaction if it already exists in the pool.
@@ -743,7 +809,7 @@ func // maybeAcceptTransaction is the internal function which implements the
 	// There is a more in-depth check that happens later after fetching the
 	// referenced transaction inputs from the main chain which examines the
 	// actual spend data and prevents double spends.
-	err = mp.checkPoolDoubleSpend(tx)
+	isReplacement, err := mp.checkPoolDoubleSpend(tx)
 	if err != nil {
 		Error(err)
 		return nil, nil, err
@@ -880,6 +946,20 @@ func // maybeAcceptTransaction is the internal function which implements the
 			minFee)
 		return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
 	}
+	// Don't allow transactions paying less than the rolling minimum fee of
+	// the pool, which is raised when transactions are evicted to keep it
+	// under its size limit.
+	// Transactions which are being added back to the memory pool from blocks
+	// that have been disconnected during a reorg are exempted.
+	if isNew {
+		poolMinFee := calcMinRequiredTxRelayFee(serializedSize,
+			util.Amount(mp.minFee()))
+		if poolMinFee > 0 && txFee+mp.feeDeltas[*txHash] < poolMinFee {
+			str := fmt.Sprintf("transaction %v has %d fees which is under "+
+				"the mempool minimum fee of %d", txHash, txFee, poolMinFee)
+			return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
+		}
+	}
 	// Require that free transactions have sufficient priority to be mined in
 	// the next block.
 	// Transactions which are being added back to the memory pool from blocks
@@ -918,6 +998,26 @@ func // maybeAcceptTransaction is the internal function which implements the
 			mp.cfg.Policy.FreeTxRelayLimit*10*1000,
 		)
 	}
+	// A transaction that spends outputs spent by transactions in the pool
+	// that signal replaceability must follow the rules for replacing them.
+	var conflicts map[chainhash.Hash]*TxDesc
+	if isReplacement {
+		conflicts, err = mp.validateReplacement(tx, txFee)
+		if err != nil {
+			Error(err)
+			return nil, nil, err
+		}
+	}
+	// Don't allow chains of unconfirmed transactions longer or larger than
+	// the package limits, as the statistics of every package a transaction
+	// is in are updated when it enters or leaves the pool. The limits are
+	// checked once the transactions it replaces are known, as they are not
+	// in the packages any more when it enters.
+	err = mp.checkPackageLimits(tx, serializedSize, conflicts)
+	if err != nil {
+		Error(err)
+		return nil, nil, err
+	}
 	// Verify crypto signatures for each input and reject the transaction if
 	// any don't verify.
 	err = blockchain.ValidateTransactionScripts(b, tx, utxoView,
@@ -930,8 +1030,33 @@ func // maybeAcceptTransaction is the internal function which implements the
 		}
 		return nil, nil, err
 	}
+	// A replacement that would be evicted again to keep the pool under its
+	// size limit is rejected before the transactions it replaces are removed,
+	// as they would be lost along with it.
+	if isNew && len(conflicts) > 0 && mp.replacementEvicted(serializedSize,
+		(txFee+mp.feeDeltas[*txHash])*1000/serializedSize, conflicts) {
+		str := fmt.Sprintf("replacement transaction %v pays too little to "+
+			"stay in the full mempool", txHash)
+		return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
+	}
+	// Evict the transactions being replaced, and those spending from them,
+	// before adding the replacement.
+	for hash, conflict := range conflicts {
+		Debugf("replacing transaction %v with %v", hash, txHash)
+		mp.removeTransaction(conflict.Tx, true)
+	}
 	// Add to transaction pool.
 	txD := mp.addTransaction(utxoView, tx, bestHeight, txFee)
+	// Keep the pool under its size limit, which may evict the transaction
+	// itself if it pays the lowest fee rate.
+	if isNew {
+		mp.trimToSize()
+		if !mp.isTransactionInPool(txHash) {
+			str := fmt.Sprintf("transaction %v was evicted as it pays too "+
+				"little to enter the full mempool", txHash)
+			return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
+		}
+	}
 	Debugf(
 		"accepted transaction %v (pool size: %v) %s",
 		txHash,
@@ -1117,6 +1242,10 @@ func // removeTransaction is the internal function which implements the public
 			delete(mp.outpoints, txIn.PreviousOutPoint)
 		}
 		delete(mp.pool, *txHash)
+		delete(mp.feeDeltas, *txHash)
+		mp.poolSize -= GetTxVirtualSize(txDesc.Tx)
+		mp.removeEviction(txDesc)
+		mp.removeFromPackages(txDesc)
 		atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
 	}
 }
@@ -1131,5 +1260,6 @@ New(cfg *Config) *TxPool {
 		orphansByPrev:  make(map[wire.OutPoint]map[chainhash.Hash]*util.Tx),
 		nextExpireScan: time.Now().Add(orphanExpireScanInterval),
 		outpoints:      make(map[wire.OutPoint]*util.Tx),
+		feeDeltas:      make(map[chainhash.Hash]int64),
 	}
 }
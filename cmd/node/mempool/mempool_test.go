@@ -1,6 +1,7 @@
 package mempool
 
 import (
+	"bytes"
 	"encoding/hex"
 	"reflect"
 	"runtime"
@@ -738,3 +739,900 @@ func TestCheckSpend(t *testing.T) {
 		t.Fatalf("Unexpeced spend found in pool: %v", spend)
 	}
 }
+
+// TestPackageStats ensures the ancestor and descendant package statistics are
+// kept up to date as transactions that spend from each other enter and leave
+// the pool, including when a transaction returns to the pool after its
+// spenders.
+func TestPackageStats(t *testing.T) {
+	t.Parallel()
+	harness, outputs, err := newPoolHarness(&netparams.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	// Create a parent with two outputs spent by two children, and a
+	// grandchild that spends both children.
+	parent, err := harness.CreateSignedTx(outputs[:1], 2)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	child1, err := harness.CreateSignedTx(
+		[]spendableOutput{txOutToSpendableOut(parent, 0)}, 1)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	child2, err := harness.CreateSignedTx(
+		[]spendableOutput{txOutToSpendableOut(parent, 1)}, 1)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	grandchild, err := harness.CreateSignedTx([]spendableOutput{
+		txOutToSpendableOut(child1, 0), txOutToSpendableOut(child2, 0)}, 1)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	for _, tx := range []*util.Tx{parent, child1, child2, grandchild} {
+		if _, err := harness.txPool.ProcessTransaction(nil, tx, true, false,
+			0); err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+		}
+	}
+	size := func(txs ...*util.Tx) (n int64) {
+		for _, tx := range txs {
+			n += GetTxVirtualSize(tx)
+		}
+		return
+	}
+	check := func(stage string, tx *util.Tx, ancestors, descendants []*util.Tx) {
+		entry, err := harness.txPool.MempoolEntry(tx.Hash())
+		if err != nil {
+			t.Fatalf("%s: MempoolEntry: %v", stage, err)
+		}
+		ancestors = append(ancestors, tx)
+		descendants = append(descendants, tx)
+		if entry.AncestorCount != int64(len(ancestors)) ||
+			entry.AncestorSize != size(ancestors...) {
+			t.Fatalf("%s: ancestor package of %v is %d transactions of %d"+
+				" bytes, want %d of %d", stage, tx.Hash(), entry.AncestorCount,
+				entry.AncestorSize, len(ancestors), size(ancestors...))
+		}
+		if entry.DescendantCount != int64(len(descendants)) ||
+			entry.DescendantSize != size(descendants...) {
+			t.Fatalf("%s: descendant package of %v is %d transactions of %d"+
+				" bytes, want %d of %d", stage, tx.Hash(),
+				entry.DescendantCount, entry.DescendantSize, len(descendants),
+				size(descendants...))
+		}
+	}
+	check("added", parent, nil, []*util.Tx{child1, child2, grandchild})
+	check("added", child1, []*util.Tx{parent}, []*util.Tx{grandchild})
+	check("added", grandchild, []*util.Tx{parent, child1, child2}, nil)
+	// Remove the parent as though it was mined.
+	harness.txPool.RemoveTransaction(parent, false)
+	check("mined", child1, nil, []*util.Tx{grandchild})
+	check("mined", grandchild, []*util.Tx{child1, child2}, nil)
+	// Return the parent to the pool as though its block was disconnected.
+	if _, err := harness.txPool.ProcessTransaction(nil, parent, true, false,
+		0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	check("returned", parent, nil, []*util.Tx{child1, child2, grandchild})
+	check("returned", grandchild, []*util.Tx{parent, child1, child2}, nil)
+	// Remove one child with its spenders.
+	harness.txPool.RemoveTransaction(child1, true)
+	check("removed", parent, nil, []*util.Tx{child2})
+	check("removed", child2, []*util.Tx{parent}, nil)
+	ancestors, err := harness.txPool.Ancestors(child2.Hash())
+	if err != nil || len(ancestors) != 1 || ancestors[0].Tx != parent {
+		t.Fatalf("Ancestors: got %v, %v", ancestors, err)
+	}
+	descendants, err := harness.txPool.Descendants(parent.Hash())
+	if err != nil || len(descendants) != 1 || descendants[0].Tx != child2 {
+		t.Fatalf("Descendants: got %v, %v", descendants, err)
+	}
+}
+
+// TestPackageLimits ensures transactions are rejected when they would make
+// the ancestor package of a transaction, or the descendant package of one of
+// its ancestors, larger than the limits of the policy.
+func TestPackageLimits(t *testing.T) {
+	t.Parallel()
+	// Each transaction of the chain and the split is accepted when the index
+	// in it is below accepted.
+	tests := []struct {
+		name     string
+		chain    uint32
+		split    uint32
+		policy   func(p *Policy, size int64)
+		accepted int
+	}{
+		{
+			name:  "ancestor count",
+			chain: 4,
+			policy: func(p *Policy, size int64) {
+				p.MaxAncestorCount = 3
+			},
+			accepted: 3,
+		},
+		{
+			name:  "ancestor size",
+			chain: 3,
+			policy: func(p *Policy, size int64) {
+				p.MaxAncestorSize = size * 2
+			},
+			accepted: 2,
+		},
+		{
+			name:  "descendant count",
+			split: 3,
+			policy: func(p *Policy, size int64) {
+				p.MaxDescendantCount = 3
+			},
+			accepted: 2,
+		},
+		{
+			name:  "descendant size",
+			chain: 3,
+			policy: func(p *Policy, size int64) {
+				p.MaxDescendantSize = size * 2
+			},
+			accepted: 2,
+		},
+		{
+			name:     "no limits",
+			chain:    30,
+			policy:   func(p *Policy, size int64) {},
+			accepted: 30,
+		},
+	}
+	for _, test := range tests {
+		harness, outputs, err := newPoolHarness(&netparams.MainNetParams)
+		if err != nil {
+			t.Fatalf("unable to create test pool: %v", err)
+		}
+		mp := harness.txPool
+		var txs []*util.Tx
+		if test.chain > 0 {
+			if txs, err = harness.CreateTxChain(outputs[0],
+				test.chain); err != nil {
+				t.Fatalf("%s: unable to create transaction chain: %v",
+					test.name, err)
+			}
+		} else {
+			// A parent is accepted before the limits are set, and each of
+			// its children spends one of its outputs.
+			parent, err := harness.CreateSignedTx(outputs, test.split)
+			if err != nil {
+				t.Fatalf("%s: unable to create transaction: %v", test.name,
+					err)
+			}
+			if _, err = mp.ProcessTransaction(nil, parent, false, false,
+				0); err != nil {
+				t.Fatalf("%s: ProcessTransaction: failed to accept tx: %v",
+					test.name, err)
+			}
+			for i := uint32(0); i < test.split; i++ {
+				child, err := harness.CreateSignedTx([]spendableOutput{
+					txOutToSpendableOut(parent, i)}, 1)
+				if err != nil {
+					t.Fatalf("%s: unable to create transaction: %v",
+						test.name, err)
+				}
+				txs = append(txs, child)
+			}
+		}
+		// The limits are set from the largest size of the transactions,
+		// which differ by a byte or so in their signatures.
+		var size int64
+		for _, tx := range txs {
+			if s := GetTxVirtualSize(tx); s > size {
+				size = s
+			}
+		}
+		test.policy(&mp.cfg.Policy, size)
+		for i, tx := range txs {
+			_, err := mp.ProcessTransaction(nil, tx, false, false, 0)
+			if i < test.accepted {
+				if err != nil {
+					t.Fatalf("%s: ProcessTransaction: failed to accept tx "+
+						"%d: %v", test.name, i, err)
+				}
+				continue
+			}
+			if _, ok := err.(RuleError); !ok {
+				t.Fatalf("%s: ProcessTransaction: tx %d got %v, want a "+
+					"rule error", test.name, i, err)
+			}
+			if mp.IsTransactionInPool(tx.Hash()) {
+				t.Fatalf("%s: rejected tx %d is in the pool", test.name, i)
+			}
+		}
+	}
+}
+
+// TestSaveLoad ensures the transactions saved from the pool are added back to
+// a new pool with their entry times and fee deltas, except for those that are
+// not valid any more.
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+	harness, outputs, err := newPoolHarness(&netparams.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	parent, err := harness.CreateSignedTx(outputs[:1], 2)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	child1, err := harness.CreateSignedTx(
+		[]spendableOutput{txOutToSpendableOut(parent, 0)}, 1)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	child2, err := harness.CreateSignedTx(
+		[]spendableOutput{txOutToSpendableOut(parent, 1)}, 1)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	// Add the children first as orphans, so that the pool holds them in a
+	// different order than they have to be loaded in.
+	for _, tx := range []*util.Tx{child2, child1, parent} {
+		if _, err := harness.txPool.ProcessTransaction(nil, tx, true, false,
+			0); err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+		}
+	}
+	added := time.Now().Add(-time.Hour)
+	harness.txPool.mtx.Lock()
+	harness.txPool.pool[*child1.Hash()].Added = added
+	harness.txPool.mtx.Unlock()
+	absent := chainhash.Hash{1}
+	harness.txPool.PrioritiseTransaction(child1.Hash(), 5000)
+	harness.txPool.PrioritiseTransaction(&absent, 42)
+	var buf bytes.Buffer
+	n, err := harness.txPool.Save(&buf)
+	if err != nil || n != 3 {
+		t.Fatalf("Save: saved %d transactions, %v", n, err)
+	}
+	// Load the pool as though the parent was mined while the node was down.
+	harness.chain.utxos.LookupEntry(outputs[0].outPoint).Spend()
+	harness.chain.utxos.AddTxOuts(parent, harness.chain.BestHeight()+1)
+	pool := New(&harness.txPool.cfg)
+	n, err = pool.Load(nil, &buf)
+	if err != nil || n != 2 {
+		t.Fatalf("Load: loaded %d transactions, %v", n, err)
+	}
+	if pool.HaveTransaction(parent.Hash()) {
+		t.Fatalf("Load: mined transaction %v was loaded", parent.Hash())
+	}
+	entry, err := pool.MempoolEntry(child1.Hash())
+	if err != nil {
+		t.Fatalf("MempoolEntry: %v", err)
+	}
+	if entry.Time != added.Unix() {
+		t.Fatalf("Load: entry time is %d, want %d", entry.Time, added.Unix())
+	}
+	delta, err := util.NewAmount(entry.ModifiedFee - entry.Fee)
+	if err != nil || delta != 5000 {
+		t.Fatalf("Load: fee delta is %v, want 5000", delta)
+	}
+	for _, desc := range pool.MiningDescs() {
+		want := desc.Fee * 1000 / GetTxVirtualSize(desc.Tx)
+		if desc.Tx.Hash().IsEqual(child1.Hash()) {
+			want = (desc.Fee + 5000) * 1000 / GetTxVirtualSize(desc.Tx)
+		}
+		if desc.FeePerKB != want {
+			t.Fatalf("MiningDescs: fee per kB of %v is %d, want %d",
+				desc.Tx.Hash(), desc.FeePerKB, want)
+		}
+	}
+	if pool.feeDeltas[absent] != 42 {
+		t.Fatalf("Load: fee delta of a transaction not in the pool is %d,"+
+			" want 42", pool.feeDeltas[absent])
+	}
+}
+
+// TestLoadFeeDelta ensures the fee deltas of saved transactions are restored
+// before they are validated again, that transactions are loaded even when
+// they pay less than the minimum fee of the pool, and that the pool is then
+// trimmed to its size limit with the deltas counted.
+func TestLoadFeeDelta(t *testing.T) {
+	t.Parallel()
+	harness, outputs, err := newPoolHarness(&netparams.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tests := []struct {
+		name    string
+		delta   int64
+		trimmed bool
+	}{
+		{"with a large delta", 100000, false},
+		{"without a delta", 0, true},
+		{"with a small delta", 100, false},
+	}
+	// The parent pays no fee, so it is only kept for its delta.
+	parent, err := harness.CreateSignedTx(outputs[:1], uint32(len(tests)))
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := harness.txPool.ProcessTransaction(nil, parent, false, false,
+		0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	harness.txPool.PrioritiseTransaction(parent.Hash(), 100000)
+	txs := make([]*util.Tx, len(tests))
+	for i, test := range tests {
+		tx, err := harness.createTxWithFee([]spendableOutput{
+			txOutToSpendableOut(parent, uint32(i))}, 1000,
+			wire.MaxTxInSequenceNum)
+		if err != nil {
+			t.Fatalf("unable to create transaction: %v", err)
+		}
+		if _, err := harness.txPool.ProcessTransaction(nil, tx, false, false,
+			0); err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+		}
+		harness.txPool.PrioritiseTransaction(tx.Hash(), test.delta)
+		txs[i] = tx
+	}
+	var buf bytes.Buffer
+	if _, err := harness.txPool.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	saved := buf.Bytes()
+	// Load into a pool whose minimum fee rose above the fee of the
+	// transactions after they were saved.
+	pool := New(&harness.txPool.cfg)
+	pool.rollingMinFee = 50000
+	n, err := pool.Load(nil, bytes.NewReader(saved))
+	if err != nil || n != len(tests)+1 {
+		t.Fatalf("Load: loaded %d transactions, %v, want %d", n, err,
+			len(tests)+1)
+	}
+	for i, test := range tests {
+		if got := pool.feeDeltas[*txs[i].Hash()]; got != test.delta {
+			t.Errorf("%s: got fee delta %d, want %d", test.name, got,
+				test.delta)
+		}
+	}
+	// Load into a pool with room for all but one of the transactions, which
+	// evicts the one with the lowest fee rate with its delta.
+	cfg := harness.txPool.cfg
+	cfg.Policy.MaxMempoolSize = pool.Size() - 1
+	pool = New(&cfg)
+	n, err = pool.Load(nil, bytes.NewReader(saved))
+	if err != nil || n != len(tests) {
+		t.Fatalf("Load: loaded %d transactions, %v, want %d", n, err,
+			len(tests))
+	}
+	if !pool.HaveTransaction(parent.Hash()) {
+		t.Fatalf("Load: parent paying its fee with its delta was evicted")
+	}
+	for i, test := range tests {
+		if pool.HaveTransaction(txs[i].Hash()) == test.trimmed {
+			t.Errorf("%s: got loaded %v, want %v", test.name, test.trimmed,
+				!test.trimmed)
+		}
+	}
+}
+
+// createTxWithFee creates a signed transaction spending the passed outputs to
+// a single output, leaving the passed fee and giving every input the passed
+// sequence number.
+func (p *poolHarness) createTxWithFee(inputs []spendableOutput,
+	fee util.Amount, sequence uint32) (*util.Tx, error) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	amount := -fee
+	for _, input := range inputs {
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: input.outPoint,
+			Sequence:         sequence,
+		})
+		amount += input.amount
+	}
+	tx.AddTxOut(&wire.TxOut{PkScript: p.payScript, Value: int64(amount)})
+	for i := range tx.TxIn {
+		sigScript, err := txscript.SignatureScript(tx, i, p.payScript,
+			txscript.SigHashAll, p.signKey, true)
+		if err != nil {
+			return nil, err
+		}
+		tx.TxIn[i].SignatureScript = sigScript
+	}
+	return util.NewTx(tx), nil
+}
+
+// TestPackageLimitsReplacement ensures the transactions a replacement evicts
+// are not counted in the descendant packages of their ancestors, so a
+// replacement is accepted when the package it enters is full only with the
+// transactions it replaces.
+func TestPackageLimitsReplacement(t *testing.T) {
+	t.Parallel()
+	harness, outputs, err := newPoolHarness(&netparams.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	mp := harness.txPool
+	parent, err := harness.CreateSignedTx(outputs[:1], 2)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := mp.ProcessTransaction(nil, parent, false, false,
+		0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	original, err := harness.createTxWithFee([]spendableOutput{
+		txOutToSpendableOut(parent, 0)}, 2000, MaxRBFSequence)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := mp.ProcessTransaction(nil, original, false, false,
+		0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	// The descendant package of the parent is full with the original.
+	mp.cfg.Policy.MaxDescendantCount = 2
+	sibling, err := harness.createTxWithFee([]spendableOutput{
+		txOutToSpendableOut(parent, 1)}, 2000, wire.MaxTxInSequenceNum)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := mp.ProcessTransaction(nil, sibling, false, false,
+		0); err == nil {
+		t.Fatalf("ProcessTransaction: accepted a transaction over the " +
+			"descendant limit")
+	}
+	replacement, err := harness.createTxWithFee([]spendableOutput{
+		txOutToSpendableOut(parent, 0)}, 5000, wire.MaxTxInSequenceNum)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := mp.ProcessTransaction(nil, replacement, false, false,
+		0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept replacement: %v", err)
+	}
+	if mp.HaveTransaction(original.Hash()) {
+		t.Fatalf("replaced transaction %v is still in the pool",
+			original.Hash())
+	}
+	entry, err := mp.MempoolEntry(parent.Hash())
+	if err != nil {
+		t.Fatalf("MempoolEntry: %v", err)
+	}
+	if entry.DescendantCount != 2 {
+		t.Fatalf("MempoolEntry: got %d descendants, want 2",
+			entry.DescendantCount)
+	}
+}
+
+// TestReplaceByFee ensures transactions that signal replaceability, directly
+// or through their ancestors in the pool, are replaced only by transactions
+// following the replacement rules, and others not at all.
+func TestReplaceByFee(t *testing.T) {
+	t.Parallel()
+	harness, outputs, err := newPoolHarness(&netparams.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	split, err := harness.CreateSignedTx(outputs[:1], 3)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := harness.txPool.ProcessTransaction(nil, split, true, false,
+		0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	out := func(tx *util.Tx, i uint32) []spendableOutput {
+		return []spendableOutput{txOutToSpendableOut(tx, i)}
+	}
+	tests := []struct {
+		name     string
+		inputs   []spendableOutput
+		fee      util.Amount
+		sequence uint32
+		accept   bool
+		replaced int
+	}{
+		{"signalling original", out(split, 0), 2000, MaxRBFSequence, true, -1},
+		{"insufficient absolute fee", out(split, 0), 2100,
+			wire.MaxTxInSequenceNum, false, -1},
+		{"replacement", out(split, 0), 5000, wire.MaxTxInSequenceNum, true, 0},
+		{"final original", out(split, 1), 2000, wire.MaxTxInSequenceNum, true,
+			-1},
+		{"replacing a final transaction", out(split, 1), 20000,
+			MaxRBFSequence, false, -1},
+		{"signalling original", out(split, 2), 2000, MaxRBFSequence, true, -1},
+	}
+	var txs []*util.Tx
+	for i, test := range tests {
+		tx, err := harness.createTxWithFee(test.inputs, test.fee,
+			test.sequence)
+		if err != nil {
+			t.Fatalf("unable to create transaction: %v", err)
+		}
+		txs = append(txs, tx)
+		_, err = harness.txPool.ProcessTransaction(nil, tx, false, false, 0)
+		if test.accept != (err == nil) {
+			t.Fatalf("test %d (%s): ProcessTransaction error %v, want "+
+				"accepted %v", i, test.name, err, test.accept)
+		}
+		if test.replaced >= 0 &&
+			harness.txPool.HaveTransaction(txs[test.replaced].Hash()) {
+			t.Fatalf("test %d (%s): replaced transaction %v is still in "+
+				"the pool", i, test.name, txs[test.replaced].Hash())
+		}
+	}
+	// A replacement may not spend unconfirmed outputs that the transaction
+	// it replaces did not spend.
+	newInput, err := harness.createTxWithFee([]spendableOutput{
+		txOutToSpendableOut(split, 2), txOutToSpendableOut(txs[3], 0)},
+		20000, MaxRBFSequence)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := harness.txPool.ProcessTransaction(nil, newInput, false,
+		false, 0); err == nil {
+		t.Fatalf("ProcessTransaction: accepted a replacement spending a " +
+			"new unconfirmed input")
+	}
+	// A transaction signals replaceability only through itself and its
+	// ancestors in the pool.
+	child, err := harness.createTxWithFee(out(txs[2], 0), 1000,
+		wire.MaxTxInSequenceNum)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := harness.txPool.ProcessTransaction(nil, child, false, false,
+		0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	if harness.txPool.SignalsReplacement(child) {
+		t.Fatalf("SignalsReplacement: %v signals replaceability",
+			child.Hash())
+	}
+	// Replacing a transaction also evicts the transactions spending from it,
+	// and the policy can reject replacements altogether.
+	grandchild, err := harness.createTxWithFee(out(txs[5], 0), 1000,
+		wire.MaxTxInSequenceNum)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := harness.txPool.ProcessTransaction(nil, grandchild, false,
+		false, 0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	if !harness.txPool.SignalsReplacement(grandchild) {
+		t.Fatalf("SignalsReplacement: %v does not inherit replaceability",
+			grandchild.Hash())
+	}
+	replacement, err := harness.createTxWithFee(out(split, 2), 10000,
+		wire.MaxTxInSequenceNum)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	harness.txPool.cfg.Policy.RejectReplacement = true
+	if _, err := harness.txPool.ProcessTransaction(nil, replacement, false,
+		false, 0); err == nil {
+		t.Fatalf("ProcessTransaction: accepted a replacement against policy")
+	}
+	harness.txPool.cfg.Policy.RejectReplacement = false
+	if _, err := harness.txPool.ProcessTransaction(nil, replacement, false,
+		false, 0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept replacement: %v", err)
+	}
+	for _, tx := range []*util.Tx{txs[5], grandchild} {
+		if harness.txPool.HaveTransaction(tx.Hash()) {
+			t.Fatalf("replaced transaction %v is still in the pool",
+				tx.Hash())
+		}
+	}
+}
+
+// TestMempoolSizeLimit ensures the pool evicts the transactions paying the
+// lowest fee rates when it grows beyond its size limit, and rejects
+// transactions paying less than the rolling minimum fee, which then decays.
+func TestMempoolSizeLimit(t *testing.T) {
+	t.Parallel()
+	harness, outputs, err := newPoolHarness(&netparams.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	mp := harness.txPool
+	split, err := harness.CreateSignedTx(outputs[:1], 4)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := mp.ProcessTransaction(nil, split, true, false,
+		0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	out := func(tx *util.Tx, i uint32) []spendableOutput {
+		return []spendableOutput{txOutToSpendableOut(tx, i)}
+	}
+	var txs []*util.Tx
+	for i, fee := range []util.Amount{1000, 3000, 5000, 4000} {
+		tx, err := harness.createTxWithFee(out(split, uint32(i)), fee,
+			wire.MaxTxInSequenceNum)
+		if err != nil {
+			t.Fatalf("unable to create transaction: %v", err)
+		}
+		txs = append(txs, tx)
+		// Fill the pool with all but the last transaction.
+		if i == 3 {
+			mp.cfg.Policy.MaxMempoolSize = mp.Size()
+		}
+		if _, err := mp.ProcessTransaction(nil, tx, false, false,
+			0); err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+		}
+	}
+	// The transaction paying the lowest fee rate made room for the last one,
+	// and the minimum fee rose above its fee rate by the relay fee.
+	if mp.HaveTransaction(txs[0].Hash()) {
+		t.Fatalf("transaction %v paying the lowest fee was not evicted",
+			txs[0].Hash())
+	}
+	if mp.Size() > mp.cfg.Policy.MaxMempoolSize {
+		t.Fatalf("Size: pool size %d is over its limit %d", mp.Size(),
+			mp.cfg.Policy.MaxMempoolSize)
+	}
+	minFee := util.Amount(1000*1000/GetTxVirtualSize(txs[0])) +
+		mp.cfg.Policy.MinRelayTxFee
+	if got := mp.MinFee(); got != minFee {
+		t.Fatalf("MinFee: got %v, want %v", got, minFee)
+	}
+	// A transaction paying less than the minimum fee is rejected.
+	lowFee, err := harness.createTxWithFee(out(split, 0), 1000,
+		wire.MaxTxInSequenceNum)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := mp.ProcessTransaction(nil, lowFee, false, false,
+		0); err == nil {
+		t.Fatalf("ProcessTransaction: accepted a transaction paying less " +
+			"than the mempool minimum fee")
+	}
+	// The minimum fee doesn't decay until a block is connected, then halves
+	// every half-life while the pool is at least half full, until it falls
+	// back to the relay fee.
+	mp.lastRollingFeeUpdate = time.Now().Add(-rollingFeeHalfLife)
+	if got := mp.MinFee(); got != minFee {
+		t.Fatalf("MinFee: got %v before a block, want %v", got, minFee)
+	}
+	mp.BlockConnected()
+	mp.lastRollingFeeUpdate = time.Now().Add(-rollingFeeHalfLife)
+	if got := mp.MinFee(); got < minFee/2-1 || got > minFee/2+1 {
+		t.Fatalf("MinFee: got %v after a half-life, want %v", got, minFee/2)
+	}
+	mp.lastRollingFeeUpdate = time.Now().Add(-rollingFeeHalfLife * 4)
+	if got := mp.MinFee(); got != mp.cfg.Policy.MinRelayTxFee {
+		t.Fatalf("MinFee: got %v after decaying, want %v", got,
+			mp.cfg.Policy.MinRelayTxFee)
+	}
+	// A transaction paying the lowest fee rate of a full pool is evicted
+	// itself, and rejected.
+	if _, err := mp.ProcessTransaction(nil, lowFee, false, false,
+		0); err == nil {
+		t.Fatalf("ProcessTransaction: accepted a transaction that was " +
+			"evicted from the full pool")
+	}
+	if mp.HaveTransaction(lowFee.Hash()) {
+		t.Fatalf("evicted transaction %v is in the pool", lowFee.Hash())
+	}
+	for _, tx := range txs[1:] {
+		if !mp.HaveTransaction(tx.Hash()) {
+			t.Fatalf("transaction %v is not in the pool", tx.Hash())
+		}
+	}
+}
+
+// TestReplacementFullPool ensures a replacement that would be evicted from a
+// full pool is rejected without evicting the transactions it replaces, and
+// that a replacement that fits replaces them.
+func TestReplacementFullPool(t *testing.T) {
+	t.Parallel()
+	harness, outputs, err := newPoolHarness(&netparams.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	mp := harness.txPool
+	split, err := harness.CreateSignedTx(outputs[:1], 3)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := mp.ProcessTransaction(nil, split, true, false,
+		0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	// The split pays no fee, so it is prioritised to keep it from being
+	// evicted along with everything spending from it.
+	mp.PrioritiseTransaction(split.Hash(), 1000000)
+	out := func(tx *util.Tx, i uint32) []spendableOutput {
+		return []spendableOutput{txOutToSpendableOut(tx, i)}
+	}
+	var pool []*util.Tx
+	for _, test := range []struct {
+		inputs   []spendableOutput
+		fee      util.Amount
+		sequence uint32
+	}{
+		{out(split, 0), 1000, MaxRBFSequence},
+		{nil, 1000, wire.MaxTxInSequenceNum},
+		{out(split, 1), 8000, wire.MaxTxInSequenceNum},
+		{out(split, 2), 9000, wire.MaxTxInSequenceNum},
+	} {
+		inputs := test.inputs
+		if inputs == nil {
+			// the child of the replaceable transaction
+			inputs = out(pool[0], 0)
+		}
+		tx, err := harness.createTxWithFee(inputs, test.fee, test.sequence)
+		if err != nil {
+			t.Fatalf("unable to create transaction: %v", err)
+		}
+		if _, err := mp.ProcessTransaction(nil, tx, false, false,
+			0); err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+		}
+		pool = append(pool, tx)
+	}
+	mp.cfg.Policy.MaxMempoolSize = mp.Size()
+	// The replacement pays enough to replace the original and its child, but
+	// is so large that its fee rate is the lowest of the pool, which has no
+	// room for it once they are removed.
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: txOutToSpendableOut(split, 0).outPoint,
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	const numOutputs = 30
+	value := (int64(txOutToSpendableOut(split, 0).amount) - 7000) /
+		numOutputs
+	for i := 0; i < numOutputs; i++ {
+		msgTx.AddTxOut(&wire.TxOut{PkScript: harness.payScript, Value: value})
+	}
+	sigScript, err := txscript.SignatureScript(msgTx, 0, harness.payScript,
+		txscript.SigHashAll, harness.signKey, true)
+	if err != nil {
+		t.Fatalf("unable to sign transaction: %v", err)
+	}
+	msgTx.TxIn[0].SignatureScript = sigScript
+	large := util.NewTx(msgTx)
+	if _, err := mp.ProcessTransaction(nil, large, false, false,
+		0); err == nil {
+		t.Fatalf("ProcessTransaction: accepted a replacement that does " +
+			"not fit in the full pool")
+	}
+	if mp.HaveTransaction(large.Hash()) {
+		t.Fatalf("rejected replacement %v is in the pool", large.Hash())
+	}
+	for _, tx := range pool {
+		if !mp.HaveTransaction(tx.Hash()) {
+			t.Fatalf("transaction %v is not in the pool", tx.Hash())
+		}
+	}
+	// A replacement smaller than the transactions it replaces fits.
+	small, err := harness.createTxWithFee(out(split, 0), 25000,
+		wire.MaxTxInSequenceNum)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := mp.ProcessTransaction(nil, small, false, false,
+		0); err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept replacement: %v", err)
+	}
+	for i, tx := range pool {
+		want := i >= 2
+		if got := mp.HaveTransaction(tx.Hash()); got != want {
+			t.Fatalf("transaction %v: got in the pool %v, want %v",
+				tx.Hash(), got, want)
+		}
+	}
+	if mp.Size() > mp.cfg.Policy.MaxMempoolSize {
+		t.Fatalf("Size: pool size %d is over its limit %d", mp.Size(),
+			mp.cfg.Policy.MaxMempoolSize)
+	}
+}
+
+// checkEvictionQueue ensures the eviction queue of a pool holds every
+// transaction of the pool with its current eviction fee rate, in heap order.
+func checkEvictionQueue(t *testing.T, mp *TxPool, when string) {
+	q := mp.evictionQueue
+	if len(q) != len(mp.pool) {
+		t.Fatalf("%s: eviction queue has %d transactions, pool has %d", when,
+			len(q), len(mp.pool))
+	}
+	for i, desc := range q {
+		if mp.pool[*desc.Tx.Hash()] != desc {
+			t.Fatalf("%s: transaction %v in the eviction queue is not in the "+
+				"pool", when, desc.Tx.Hash())
+		}
+		if desc.evictionIndex != i {
+			t.Fatalf("%s: transaction %v has index %d, want %d", when,
+				desc.Tx.Hash(), desc.evictionIndex, i)
+		}
+		if want := mp.evictionFeePerKB(desc); desc.evictionFee != want {
+			t.Fatalf("%s: transaction %v has eviction fee %d, want %d", when,
+				desc.Tx.Hash(), desc.evictionFee, want)
+		}
+		if parent := q[(i-1)/2]; i > 0 && parent.evictionFee > desc.evictionFee {
+			t.Fatalf("%s: eviction queue is out of order at %d", when, i)
+		}
+	}
+}
+
+// TestEvictionQueue ensures the eviction queue of the pool follows the fee
+// rates transactions are evicted by as transactions enter and leave the pool
+// and their fees are prioritised.
+func TestEvictionQueue(t *testing.T) {
+	t.Parallel()
+	harness, outputs, err := newPoolHarness(&netparams.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	mp := harness.txPool
+	split, err := harness.CreateSignedTx(outputs[:1], 3)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	accept := func(tx *util.Tx) {
+		if _, err := mp.ProcessTransaction(nil, tx, false, false,
+			0); err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+		}
+	}
+	accept(split)
+	checkEvictionQueue(t, mp, "one transaction")
+	var txs []*util.Tx
+	for i, fee := range []util.Amount{1000, 3000, 5000} {
+		tx, err := harness.createTxWithFee([]spendableOutput{
+			txOutToSpendableOut(split, uint32(i))}, fee,
+			wire.MaxTxInSequenceNum)
+		if err != nil {
+			t.Fatalf("unable to create transaction: %v", err)
+		}
+		accept(tx)
+		txs = append(txs, tx)
+		checkEvictionQueue(t, mp, "adding a transaction")
+	}
+	worst := func() *chainhash.Hash {
+		return mp.evictionQueue[0].Tx.Hash()
+	}
+	if !worst().IsEqual(txs[0].Hash()) {
+		t.Fatalf("transaction %v paying the lowest fee is not the first "+
+			"to evict", txs[0].Hash())
+	}
+	// A child paying a high fee raises the fee rate its parent is evicted
+	// by, as evicting the parent also evicts it.
+	child, err := harness.createTxWithFee([]spendableOutput{
+		txOutToSpendableOut(txs[0], 0)}, 20000, wire.MaxTxInSequenceNum)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	accept(child)
+	checkEvictionQueue(t, mp, "adding a child")
+	if !worst().IsEqual(txs[1].Hash()) {
+		t.Fatalf("transaction %v is not the first to evict after its "+
+			"sibling gained a child", txs[1].Hash())
+	}
+	// Prioritising a transaction moves it in the queue.
+	mp.PrioritiseTransaction(txs[1].Hash(), 100000)
+	checkEvictionQueue(t, mp, "prioritising a transaction")
+	if worst().IsEqual(txs[1].Hash()) {
+		t.Fatalf("prioritised transaction %v is still the first to evict",
+			txs[1].Hash())
+	}
+	mp.PrioritiseTransaction(txs[1].Hash(), -100000)
+	checkEvictionQueue(t, mp, "removing the priority of a transaction")
+	// Removing the child lowers the fee rate of its parent again.
+	mp.RemoveTransaction(child, false)
+	checkEvictionQueue(t, mp, "removing a child")
+	if !worst().IsEqual(txs[0].Hash()) {
+		t.Fatalf("transaction %v is not the first to evict after its "+
+			"child left", txs[0].Hash())
+	}
+	// Removing a transaction with its descendants removes them all.
+	accept(child)
+	mp.RemoveTransaction(split, true)
+	checkEvictionQueue(t, mp, "removing a transaction and its descendants")
+}
@@ -0,0 +1,320 @@
+package mempool
+
+import (
+	"fmt"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/chain/mining"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/rpc/btcjson"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// packageStats is the number, virtual size and fees of a set of transactions
+// in the pool
+type packageStats struct {
+	Count int64
+	Size  int64
+	Fees  int64
+}
+
+func (p *packageStats) add(o packageStats) {
+	p.Count += o.Count
+	p.Size += o.Size
+	p.Fees += o.Fees
+}
+
+func (p *packageStats) sub(o packageStats) {
+	p.Count -= o.Count
+	p.Size -= o.Size
+	p.Fees -= o.Fees
+}
+
+// ownStats returns the package statistics of a transaction on its own
+func ownStats(desc *TxDesc) packageStats {
+	return packageStats{Count: 1, Size: GetTxVirtualSize(desc.Tx), Fees: desc.Fee}
+}
+
+// Ancestors returns the transactions in the pool that a transaction spends
+// from, directly or through other transactions in the pool, or an error if it
+// is not in the pool. This function is safe for concurrent access.
+func (mp *TxPool) Ancestors(hash *chainhash.Hash) ([]*TxDesc, error) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+	desc, ok := mp.pool[*hash]
+	if !ok {
+		return nil, fmt.Errorf("transaction %v is not in the pool", hash)
+	}
+	return descSlice(mp.txAncestors(desc.Tx)), nil
+}
+
+// Descendants returns the transactions in the pool that spend from a
+// transaction, directly or through other transactions in the pool, or an
+// error if it is not in the pool. This function is safe for concurrent access.
+func (mp *TxPool) Descendants(hash *chainhash.Hash) ([]*TxDesc, error) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+	desc, ok := mp.pool[*hash]
+	if !ok {
+		return nil, fmt.Errorf("transaction %v is not in the pool", hash)
+	}
+	return descSlice(mp.txDescendants(desc.Tx)), nil
+}
+
+// MempoolEntry returns the details of a transaction in the pool with the
+// statistics of its ancestor and descendant packages, or an error if it is not
+// in the pool. This function is safe for concurrent access.
+func (mp *TxPool) MempoolEntry(hash *chainhash.Hash) (
+	*btcjson.GetMempoolEntryResult, error) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+	desc, ok := mp.pool[*hash]
+	if !ok {
+		return nil, fmt.Errorf("transaction %v is not in the pool", hash)
+	}
+	return mp.mempoolEntry(desc), nil
+}
+
+// mempoolEntry returns the details of a transaction in the pool.
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) mempoolEntry(desc *TxDesc) *btcjson.GetMempoolEntryResult {
+	tx := desc.Tx
+	var currentPriority float64
+	utxos, err := mp.fetchInputUtxos(tx)
+	if err == nil {
+		currentPriority = mining.CalcPriority(tx.MsgTx(), utxos,
+			mp.cfg.BestHeight()+1)
+	}
+	entry := &btcjson.GetMempoolEntryResult{
+		Size:             int32(GetTxVirtualSize(tx)),
+		Fee:              util.Amount(desc.Fee).ToDUO(),
+		ModifiedFee:      util.Amount(desc.Fee + mp.feeDeltas[*tx.Hash()]).ToDUO(),
+		Time:             desc.Added.Unix(),
+		Height:           int64(desc.Height),
+		StartingPriority: desc.StartingPriority,
+		CurrentPriority:  currentPriority,
+		DescendantCount:  desc.descendantStats.Count,
+		DescendantSize:   desc.descendantStats.Size,
+		DescendantFees:   util.Amount(desc.descendantStats.Fees).ToDUO(),
+		AncestorCount:    desc.ancestorStats.Count,
+		AncestorSize:     desc.ancestorStats.Size,
+		AncestorFees:     util.Amount(desc.ancestorStats.Fees).ToDUO(),
+		Depends:          make([]string, 0),
+	}
+	for _, txIn := range tx.MsgTx().TxIn {
+		hash := &txIn.PreviousOutPoint.Hash
+		if mp.haveTransaction(hash) {
+			entry.Depends = append(entry.Depends, hash.String())
+		}
+	}
+	return entry
+}
+
+// txAncestors returns the transactions in the pool a transaction spends from,
+// directly or through other transactions in the pool.
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) txAncestors(tx *util.Tx) map[chainhash.Hash]*TxDesc {
+	ancestors := make(map[chainhash.Hash]*TxDesc)
+	queue := []*util.Tx{tx}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, txIn := range next.MsgTx().TxIn {
+			hash := txIn.PreviousOutPoint.Hash
+			if _, seen := ancestors[hash]; seen {
+				continue
+			}
+			if parent, ok := mp.pool[hash]; ok {
+				ancestors[hash] = parent
+				queue = append(queue, parent.Tx)
+			}
+		}
+	}
+	return ancestors
+}
+
+// txDescendants returns the transactions in the pool that spend from a
+// transaction, directly or through other transactions in the pool.
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) txDescendants(tx *util.Tx) map[chainhash.Hash]*TxDesc {
+	descendants := make(map[chainhash.Hash]*TxDesc)
+	queue := []*util.Tx{tx}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		prevOut := wire.OutPoint{Hash: *next.Hash()}
+		for i := range next.MsgTx().TxOut {
+			prevOut.Index = uint32(i)
+			spender, ok := mp.outpoints[prevOut]
+			if !ok {
+				continue
+			}
+			hash := *spender.Hash()
+			if _, seen := descendants[hash]; seen {
+				continue
+			}
+			if child, ok := mp.pool[hash]; ok {
+				descendants[hash] = child
+				queue = append(queue, child.Tx)
+			}
+		}
+	}
+	return descendants
+}
+
+// checkPackageLimits returns an error if adding a transaction of a virtual
+// size to the pool would make its ancestor package, or the descendant package
+// of any of its ancestors, exceed the limits of the policy. The transactions
+// it replaces are not counted in the descendant packages of their ancestors,
+// as they leave the pool when it enters. The walk of the ancestors stops as
+// soon as a limit is exceeded, so it is bounded by the limits rather than the
+// length of the chain.
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) checkPackageLimits(tx *util.Tx, size int64,
+	conflicts map[chainhash.Hash]*TxDesc) error {
+	policy := &mp.cfg.Policy
+	exceeds := func(n, limit int64) bool {
+		return limit > 0 && n > limit
+	}
+	// replaced holds the statistics of the replaced transactions in the
+	// descendant package of each of their ancestors.
+	replaced := make(map[chainhash.Hash]packageStats)
+	for _, conflict := range conflicts {
+		for hash := range mp.txAncestors(conflict.Tx) {
+			stats := replaced[hash]
+			stats.add(ownStats(conflict))
+			replaced[hash] = stats
+		}
+	}
+	ancestors := packageStats{Count: 1, Size: size}
+	if exceeds(ancestors.Size, policy.MaxAncestorSize) ||
+		exceeds(ancestors.Size, policy.MaxDescendantSize) {
+		str := fmt.Sprintf("transaction %v is larger than the package "+
+			"size limit", tx.Hash())
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+	seen := make(map[chainhash.Hash]struct{})
+	queue := []*util.Tx{tx}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, txIn := range next.MsgTx().TxIn {
+			hash := txIn.PreviousOutPoint.Hash
+			if _, ok := seen[hash]; ok {
+				continue
+			}
+			parent, ok := mp.pool[hash]
+			if !ok {
+				continue
+			}
+			seen[hash] = struct{}{}
+			ancestors.add(ownStats(parent))
+			if exceeds(ancestors.Count, policy.MaxAncestorCount) ||
+				exceeds(ancestors.Size, policy.MaxAncestorSize) {
+				str := fmt.Sprintf("transaction %v has too many "+
+					"unconfirmed ancestors, more than %d transactions or "+
+					"%d bytes", tx.Hash(), policy.MaxAncestorCount,
+					policy.MaxAncestorSize)
+				return txRuleError(wire.RejectNonstandard, str)
+			}
+			descendants := parent.descendantStats
+			descendants.sub(replaced[hash])
+			if exceeds(descendants.Count+1, policy.MaxDescendantCount) ||
+				exceeds(descendants.Size+size, policy.MaxDescendantSize) {
+				str := fmt.Sprintf("transaction %v would give its "+
+					"ancestor %v too many unconfirmed descendants, more "+
+					"than %d transactions or %d bytes", tx.Hash(), hash,
+					policy.MaxDescendantCount, policy.MaxDescendantSize)
+				return txRuleError(wire.RejectNonstandard, str)
+			}
+			queue = append(queue, parent.Tx)
+		}
+	}
+	return nil
+}
+
+// calcPackages computes the ancestor and descendant package statistics of a
+// transaction in the pool from scratch.
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) calcPackages(desc *TxDesc) {
+	desc.ancestorStats = ownStats(desc)
+	for _, ancestor := range mp.txAncestors(desc.Tx) {
+		desc.ancestorStats.add(ownStats(ancestor))
+	}
+	desc.descendantStats = ownStats(desc)
+	for _, descendant := range mp.txDescendants(desc.Tx) {
+		desc.descendantStats.add(ownStats(descendant))
+	}
+	mp.fixEviction(desc)
+}
+
+// addToPackages updates the package statistics of a transaction that was just
+// added to the pool, and of its ancestors and descendants. Usually it has no
+// descendants, and the others only gain the transaction, but when transactions
+// of a disconnected block return to the pool their spenders may already be in
+// it, and the links through the transaction can only be found by computing
+// their packages again.
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) addToPackages(desc *TxDesc) {
+	ancestors := mp.txAncestors(desc.Tx)
+	descendants := mp.txDescendants(desc.Tx)
+	if len(ancestors) > 0 && len(descendants) > 0 {
+		mp.calcPackages(desc)
+		for _, n := range ancestors {
+			mp.calcPackages(n)
+		}
+		for _, n := range descendants {
+			mp.calcPackages(n)
+		}
+		return
+	}
+	own := ownStats(desc)
+	desc.ancestorStats = own
+	desc.descendantStats = own
+	for _, ancestor := range ancestors {
+		desc.ancestorStats.add(ownStats(ancestor))
+		ancestor.descendantStats.add(own)
+		mp.fixEviction(ancestor)
+	}
+	for _, descendant := range descendants {
+		desc.descendantStats.add(ownStats(descendant))
+		descendant.ancestorStats.add(own)
+	}
+}
+
+// removeFromPackages updates the package statistics of the ancestors and
+// descendants of a transaction that was just removed from the pool, which can
+// still be found from its inputs and from the spenders of its outputs. The
+// packages are computed again if it has both, because they may also be linked
+// by other transactions.
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) removeFromPackages(desc *TxDesc) {
+	ancestors := mp.txAncestors(desc.Tx)
+	descendants := mp.txDescendants(desc.Tx)
+	if len(ancestors) > 0 && len(descendants) > 0 {
+		for _, n := range ancestors {
+			mp.calcPackages(n)
+		}
+		for _, n := range descendants {
+			mp.calcPackages(n)
+		}
+		return
+	}
+	own := ownStats(desc)
+	for _, ancestor := range ancestors {
+		ancestor.descendantStats.sub(own)
+		mp.fixEviction(ancestor)
+	}
+	for _, descendant := range descendants {
+		descendant.ancestorStats.sub(own)
+	}
+}
+
+// descSlice returns the descriptors of a set of transactions
+func descSlice(descs map[chainhash.Hash]*TxDesc) []*TxDesc {
+	s := make([]*TxDesc, 0, len(descs))
+	for _, desc := range descs {
+		s = append(s, desc)
+	}
+	return s
+}
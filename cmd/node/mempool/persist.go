@@ -0,0 +1,252 @@
+package mempool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/chain/mining"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// The pool is saved as:
+//   <version><tx count><txs><delta count><deltas>
+//   Field          Type               Size
+//   version        uint64             8 bytes
+//   tx count       uint64             8 bytes
+//   txs            []tx               variable
+//   delta count    uint64             8 bytes
+//   deltas         []delta            (chainhash.HashSize + 8) * delta count
+// Each transaction is followed by the time it entered the pool and its fee
+// delta, and is written after the transactions in the pool it spends from:
+//   <transaction><time><fee delta>
+//   Field          Type               Size
+//   transaction    wire.MsgTx         variable
+//   time           int64              8 bytes
+//   fee delta      int64              8 bytes
+// The deltas are the fee deltas of the transactions that were not in the
+// pool:
+//   <hash><fee delta>
+//   Field          Type               Size
+//   hash           chainhash.Hash     chainhash.HashSize
+//   fee delta      int64              8 bytes
+// -----------------------------------------------------------------------------
+
+// mempoolSaveVersion is the version of the format the pool is saved in
+const mempoolSaveVersion = 1
+
+// PrioritiseTransaction adds delta, which may be negative, to the fee a
+// transaction is treated as paying when it is chosen for a block, without
+// changing the fee it actually pays. The delta is kept for a transaction that
+// is not in the pool until it enters it. This function is safe for concurrent
+// access.
+func (mp *TxPool) PrioritiseTransaction(hash *chainhash.Hash, delta int64) {
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+	mp.addFeeDelta(hash, delta)
+}
+
+// addFeeDelta adds delta to the fee delta of a transaction.
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) addFeeDelta(hash *chainhash.Hash, delta int64) {
+	if mp.feeDeltas[*hash] += delta; mp.feeDeltas[*hash] == 0 {
+		delete(mp.feeDeltas, *hash)
+	}
+	if desc, ok := mp.pool[*hash]; ok {
+		mp.fixEviction(desc)
+	}
+}
+
+// miningDesc returns the mining descriptor of a transaction in the pool, with
+// its fee delta and its fee per kilobyte changed by it, if it has one.
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) miningDesc(desc *TxDesc) *mining.TxDesc {
+	delta, ok := mp.feeDeltas[*desc.Tx.Hash()]
+	if !ok {
+		return &desc.TxDesc
+	}
+	miningDesc := desc.TxDesc
+	miningDesc.FeeDelta = delta
+	miningDesc.FeePerKB = (desc.Fee + delta) * 1000 /
+		GetTxVirtualSize(desc.Tx)
+	return &miningDesc
+}
+
+// Save writes the transactions in the pool, with the times they entered it
+// and the fee deltas, so that they can be added back with Load.
+// It returns the number of transactions written.
+// This function is safe for concurrent access.
+func (mp *TxPool) Save(w io.Writer) (int, error) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+	// A transaction has more ancestors in the pool than any of them, so in
+	// this order it comes after those it spends from.
+	descs := make([]*TxDesc, 0, len(mp.pool))
+	for _, desc := range mp.pool {
+		descs = append(descs, desc)
+	}
+	sort.Slice(descs, func(i, j int) bool {
+		return descs[i].ancestorStats.Count < descs[j].ancestorStats.Count
+	})
+	err := binary.Write(w, binary.LittleEndian, uint64(mempoolSaveVersion))
+	if err == nil {
+		err = binary.Write(w, binary.LittleEndian, uint64(len(descs)))
+	}
+	for _, desc := range descs {
+		if err != nil {
+			break
+		}
+		if err = desc.Tx.MsgTx().Serialize(w); err != nil {
+			break
+		}
+		record := [2]int64{desc.Added.Unix(), mp.feeDeltas[*desc.Tx.Hash()]}
+		err = binary.Write(w, binary.LittleEndian, record)
+	}
+	deltas := make(map[chainhash.Hash]int64, len(mp.feeDeltas))
+	for hash, delta := range mp.feeDeltas {
+		if _, ok := mp.pool[hash]; !ok {
+			deltas[hash] = delta
+		}
+	}
+	if err == nil {
+		err = binary.Write(w, binary.LittleEndian, uint64(len(deltas)))
+	}
+	for hash, delta := range deltas {
+		if err != nil {
+			break
+		}
+		if _, err = w.Write(hash[:]); err == nil {
+			err = binary.Write(w, binary.LittleEndian, delta)
+		}
+	}
+	if err != nil {
+		Error("failed to save the mempool:", err)
+		return 0, err
+	}
+	return len(descs), nil
+}
+
+// Load adds the transactions written by Save back to the pool, validating
+// each of them again as the transactions of disconnected blocks are, without
+// the fee and priority checks of new transactions, and restores the fee
+// deltas. The fee delta of each transaction is restored before it is
+// validated, so it counts when the pool is trimmed to its size limit once the
+// transactions are loaded. Transactions that are not valid any more, such as
+// those mined or double spent while the node was down, are skipped. It
+// returns the number of transactions added to the pool. This function is safe
+// for concurrent access.
+func (mp *TxPool) Load(b *blockchain.BlockChain, r io.Reader) (int, error) {
+	var version, count uint64
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return 0, err
+	}
+	if version != mempoolSaveVersion {
+		return 0, fmt.Errorf("unsupported saved mempool version %d", version)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return 0, err
+	}
+	var accepted, failed int
+	var loaded []*chainhash.Hash
+	for i := uint64(0); i < count; i++ {
+		var msgTx wire.MsgTx
+		if err := msgTx.Deserialize(r); err != nil {
+			return accepted, err
+		}
+		var record [2]int64
+		if err := binary.Read(r, binary.LittleEndian, &record); err != nil {
+			return accepted, err
+		}
+		tx := util.NewTx(&msgTx)
+		mp.PrioritiseTransaction(tx.Hash(), record[1])
+		missing, txD, err := mp.MaybeAcceptTransaction(b, tx, false, false)
+		if err != nil || len(missing) > 0 {
+			Trace("not loading saved transaction", tx.Hash(), err)
+			// The transaction may have been mined, so its delta is not
+			// kept for it.
+			mp.PrioritiseTransaction(tx.Hash(), -record[1])
+			failed++
+			continue
+		}
+		mp.mtx.Lock()
+		txD.Added = time.Unix(record[0], 0)
+		mp.mtx.Unlock()
+		loaded = append(loaded, tx.Hash())
+		accepted++
+	}
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return accepted, err
+	}
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+	for i := uint64(0); i < count; i++ {
+		var hash chainhash.Hash
+		var delta int64
+		if _, err := io.ReadFull(r, hash[:]); err != nil {
+			return accepted, err
+		}
+		if err := binary.Read(r, binary.LittleEndian, &delta); err != nil {
+			return accepted, err
+		}
+		mp.addFeeDelta(&hash, delta)
+	}
+	// The pool may have been saved with a larger size limit, and the
+	// transactions evicted to fit it are counted as failed.
+	mp.trimToSize()
+	for _, hash := range loaded {
+		if !mp.isTransactionInPool(hash) {
+			accepted--
+			failed++
+		}
+	}
+	Infof("loaded %d transactions into the mempool, %d failed", accepted,
+		failed)
+	return accepted, nil
+}
+
+// SaveFile saves the pool to a file, replacing it only once the pool has been
+// written completely.
+func (mp *TxPool) SaveFile(path string) (n int, err error) {
+	tmpPath := path + ".new"
+	var f *os.File
+	if f, err = os.Create(tmpPath); Check(err) {
+		return
+	}
+	w := bufio.NewWriter(f)
+	if n, err = mp.Save(w); err == nil {
+		err = w.Flush()
+	}
+	if e := f.Close(); Check(e) && err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		if e := os.Remove(tmpPath); Check(e) {
+		}
+		return 0, err
+	}
+	Infof("saved %d transactions of the mempool to '%s'", n, path)
+	return
+}
+
+// LoadFile loads the pool from a file written by SaveFile.
+func (mp *TxPool) LoadFile(b *blockchain.BlockChain, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := f.Close(); Check(err) {
+		}
+	}()
+	return mp.Load(b, bufio.NewReader(f))
+}
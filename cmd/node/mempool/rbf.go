@@ -0,0 +1,153 @@
+package mempool
+
+import (
+	"fmt"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/util"
+)
+
+const (
+	// MaxRBFSequence is the highest input sequence number that signals that a
+	// transaction can be replaced by one paying a higher fee, as defined by
+	// BIP125.
+	MaxRBFSequence = 0xfffffffd
+	// MaxReplacementEvictions is the largest number of transactions, counting
+	// the descendants of those it conflicts with, that a replacement may
+	// evict from the pool.
+	MaxReplacementEvictions = 100
+)
+
+// SignalsReplacement returns whether a transaction can be replaced by one
+// paying a higher fee, because it or one of the unconfirmed transactions it
+// spends from in the pool has an input with a sequence number no higher than
+// MaxRBFSequence. This function is safe for concurrent access.
+func (mp *TxPool) SignalsReplacement(tx *util.Tx) bool {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+	return mp.signalsReplacement(tx)
+}
+
+// signalsReplacement returns whether a transaction signals replaceability
+// explicitly or inherits it from an ancestor in the pool.
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) signalsReplacement(tx *util.Tx) bool {
+	if signalsReplacementExplicitly(tx) {
+		return true
+	}
+	for _, ancestor := range mp.txAncestors(tx) {
+		if signalsReplacementExplicitly(ancestor.Tx) {
+			return true
+		}
+	}
+	return false
+}
+
+// signalsReplacementExplicitly returns whether a transaction has an input with
+// a sequence number no higher than MaxRBFSequence.
+func signalsReplacementExplicitly(tx *util.Tx) bool {
+	for _, txIn := range tx.MsgTx().TxIn {
+		if txIn.Sequence <= MaxRBFSequence {
+			return true
+		}
+	}
+	return false
+}
+
+// txConflicts returns the transactions in the pool that spend the same
+// outputs as a transaction, along with all of their descendants, which would
+// all be evicted if the transaction replaced them.
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) txConflicts(tx *util.Tx) map[chainhash.Hash]*TxDesc {
+	conflicts := make(map[chainhash.Hash]*TxDesc)
+	for _, txIn := range tx.MsgTx().TxIn {
+		conflict, ok := mp.outpoints[txIn.PreviousOutPoint]
+		if !ok {
+			continue
+		}
+		if _, seen := conflicts[*conflict.Hash()]; seen {
+			continue
+		}
+		conflicts[*conflict.Hash()] = mp.pool[*conflict.Hash()]
+		for hash, descendant := range mp.txDescendants(conflict) {
+			conflicts[hash] = descendant
+		}
+	}
+	return conflicts
+}
+
+// validateReplacement checks that a transaction spending outputs already
+// spent in the pool by transactions that signal replaceability follows the
+// replacement rules of BIP125, and returns the transactions it replaces:
+//   - it evicts no more than MaxReplacementEvictions transactions
+//   - it does not spend an output of a transaction it replaces
+//   - its fee rate is higher than that of each transaction it replaces
+//   - its fee is at least the total fee of the transactions it replaces, plus
+//     the minimum relay fee for its own size
+//   - it spends no unconfirmed outputs that none of the transactions it
+//     directly replaces spent
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) validateReplacement(tx *util.Tx, txFee int64) (
+	map[chainhash.Hash]*TxDesc, error) {
+	txHash := tx.Hash()
+	conflicts := mp.txConflicts(tx)
+	if len(conflicts) > MaxReplacementEvictions {
+		str := fmt.Sprintf("replacement transaction %v evicts more "+
+			"transactions than permitted: max is %v, evicts %v", txHash,
+			MaxReplacementEvictions, len(conflicts))
+		return nil, txRuleError(wire.RejectNonstandard, str)
+	}
+	for _, txIn := range tx.MsgTx().TxIn {
+		if _, ok := conflicts[txIn.PreviousOutPoint.Hash]; ok {
+			str := fmt.Sprintf("replacement transaction %v spends "+
+				"transaction %v that it replaces", txHash,
+				txIn.PreviousOutPoint.Hash)
+			return nil, txRuleError(wire.RejectInvalid, str)
+		}
+	}
+	size := GetTxVirtualSize(tx)
+	txFeePerKB := txFee * 1000 / size
+	var conflictsFee int64
+	for _, conflict := range conflicts {
+		if txFeePerKB <= conflict.FeePerKB {
+			str := fmt.Sprintf("replacement transaction %v has an "+
+				"insufficient fee rate: needs more than %v, has %v", txHash,
+				conflict.FeePerKB, txFeePerKB)
+			return nil, txRuleError(wire.RejectInsufficientFee, str)
+		}
+		conflictsFee += conflict.Fee
+	}
+	minFee := conflictsFee + calcMinRequiredTxRelayFee(size,
+		mp.cfg.Policy.MinRelayTxFee)
+	if txFee < minFee {
+		str := fmt.Sprintf("replacement transaction %v has an "+
+			"insufficient absolute fee: needs %v, has %v", txHash, minFee,
+			txFee)
+		return nil, txRuleError(wire.RejectInsufficientFee, str)
+	}
+	// The inputs of the transactions replaced directly are the only
+	// unconfirmed ones the replacement may spend, as otherwise it could be
+	// less likely to be mined than those it replaces.
+	parents := make(map[chainhash.Hash]struct{})
+	for _, txIn := range tx.MsgTx().TxIn {
+		if conflict, ok := mp.outpoints[txIn.PreviousOutPoint]; ok {
+			for _, in := range conflict.MsgTx().TxIn {
+				parents[in.PreviousOutPoint.Hash] = struct{}{}
+			}
+		}
+	}
+	for _, txIn := range tx.MsgTx().TxIn {
+		hash := txIn.PreviousOutPoint.Hash
+		if _, ok := mp.pool[hash]; !ok {
+			continue
+		}
+		if _, ok := parents[hash]; !ok {
+			str := fmt.Sprintf("replacement transaction %v spends new "+
+				"unconfirmed input %v", txHash, txIn.PreviousOutPoint)
+			return nil, txRuleError(wire.RejectNonstandard, str)
+		}
+	}
+	return conflicts, nil
+}
@@ -0,0 +1,226 @@
+package pubsub
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxQueuedMessages is the number of notifications that are queued for a
+// subscriber, past which new ones are dropped until it catches up.
+const maxQueuedMessages = 1000
+
+// publisher sends the notifications of its topics to the subscribers
+// connected to one endpoint.
+type publisher struct {
+	endpoint    string
+	network     string
+	address     string
+	topics      map[string]struct{}
+	listener    net.Listener
+	wg          sync.WaitGroup
+	mtx         sync.Mutex // protects the fields below
+	sequence    map[string]uint32
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+// subscriber is a connection notifications are sent to, with the queue of
+// notifications waiting to be written to it.
+type subscriber struct {
+	conn  net.Conn
+	queue chan []byte
+}
+
+// newPublisher returns a publisher listening on an endpoint.
+func newPublisher(endpoint string) (*publisher, error) {
+	network, address, err := parseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if network == "unix" {
+		removeStaleSocket(address)
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &publisher{
+		endpoint:    endpoint,
+		network:     network,
+		address:     address,
+		topics:      make(map[string]struct{}),
+		listener:    listener,
+		sequence:    make(map[string]uint32),
+		subscribers: make(map[*subscriber]struct{}),
+	}, nil
+}
+
+// start begins accepting subscribers.
+func (p *publisher) start() {
+	topics := make([]string, 0, len(p.topics))
+	for topic := range p.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	Infof("publishing %s notifications on %s",
+		strings.Join(topics, ", "), p.endpoint)
+	p.wg.Add(1)
+	go p.acceptHandler()
+}
+
+// stop stops listening, disconnects the subscribers and waits for their
+// handlers to finish.
+func (p *publisher) stop() {
+	if err := p.listener.Close(); err != nil {
+		Debug(err)
+	}
+	p.mtx.Lock()
+	p.closed = true
+	for s := range p.subscribers {
+		delete(p.subscribers, s)
+		close(s.queue)
+		_ = s.conn.Close()
+	}
+	p.mtx.Unlock()
+	p.wg.Wait()
+	if p.network == "unix" {
+		removeStaleSocket(p.address)
+	}
+}
+
+// publish queues a notification for all the subscribers, dropping it for
+// those whose queue is full. The sequence number of the topic is advanced
+// whether or not there are any subscribers.
+func (p *publisher) publish(topic string, body []byte) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	seq := p.sequence[topic]
+	p.sequence[topic] = seq + 1
+	msg := encodeMessage(topic, body, seq)
+	for s := range p.subscribers {
+		select {
+		case s.queue <- msg:
+		default:
+			Debugf("dropping %s notification %d for slow subscriber %v on %s",
+				topic, seq, s.conn.RemoteAddr(), p.endpoint)
+		}
+	}
+}
+
+// acceptHandler accepts subscribers until the listener is closed. It must be
+// run as a goroutine.
+func (p *publisher) acceptHandler() {
+	defer p.wg.Done()
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			Debug("stopped accepting subscribers on", p.endpoint, err)
+			return
+		}
+		s := &subscriber{
+			conn:  conn,
+			queue: make(chan []byte, maxQueuedMessages),
+		}
+		// A subscriber accepted while the publisher is stopping would never
+		// be disconnected.
+		p.mtx.Lock()
+		if p.closed {
+			p.mtx.Unlock()
+			_ = conn.Close()
+			return
+		}
+		p.subscribers[s] = struct{}{}
+		p.mtx.Unlock()
+		Debug("new subscriber", conn.RemoteAddr(), "on", p.endpoint)
+		p.wg.Add(2)
+		go p.writeHandler(s)
+		go p.readHandler(s)
+	}
+}
+
+// writeHandler writes the notifications queued for a subscriber until it is
+// removed or a write fails. It must be run as a goroutine.
+func (p *publisher) writeHandler(s *subscriber) {
+	defer p.wg.Done()
+	for msg := range s.queue {
+		if _, err := s.conn.Write(msg); err != nil {
+			Debug("failed to write to subscriber", s.conn.RemoteAddr(),
+				"on", p.endpoint, err)
+			break
+		}
+	}
+	p.remove(s)
+}
+
+// readHandler discards anything a subscriber sends and removes it once it
+// disconnects. It must be run as a goroutine.
+func (p *publisher) readHandler(s *subscriber) {
+	defer p.wg.Done()
+	_, _ = io.Copy(ioutil.Discard, s.conn)
+	p.remove(s)
+}
+
+// remove disconnects a subscriber and stops queueing notifications for it.
+func (p *publisher) remove(s *subscriber) {
+	p.mtx.Lock()
+	if _, ok := p.subscribers[s]; ok {
+		delete(p.subscribers, s)
+		close(s.queue)
+		Debug("subscriber", s.conn.RemoteAddr(), "left", p.endpoint)
+	}
+	p.mtx.Unlock()
+	_ = s.conn.Close()
+}
+
+// encodeMessage returns the serialized notification message of a topic.
+func encodeMessage(topic string, body []byte, seq uint32) []byte {
+	msg := make([]byte, 0, 1+len(topic)+4+len(body)+4)
+	msg = append(msg, byte(len(topic)))
+	msg = append(msg, topic...)
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(body)))
+	msg = append(msg, n[:]...)
+	msg = append(msg, body...)
+	binary.LittleEndian.PutUint32(n[:], seq)
+	return append(msg, n[:]...)
+}
+
+// parseEndpoint splits an endpoint into the network and address to listen on.
+func parseEndpoint(endpoint string) (network, address string, err error) {
+	switch {
+	case strings.HasPrefix(endpoint, "tcp://"):
+		network, address = "tcp", strings.TrimPrefix(endpoint, "tcp://")
+	case strings.HasPrefix(endpoint, "unix://"):
+		network, address = "unix", strings.TrimPrefix(endpoint, "unix://")
+	case strings.Contains(endpoint, "://"):
+		return "", "", fmt.Errorf("unsupported notification endpoint %q, "+
+			"only tcp:// and unix:// endpoints are", endpoint)
+	default:
+		network, address = "tcp", endpoint
+	}
+	if address == "" {
+		return "", "", fmt.Errorf("notification endpoint %q has no address",
+			endpoint)
+	}
+	return network, address, nil
+}
+
+// removeStaleSocket removes a Unix socket left behind at a path, such as by a
+// node that did not shut down cleanly. Anything other than a socket is left
+// alone, so that listening on the path fails instead.
+func removeStaleSocket(path string) {
+	fi, err := os.Lstat(path)
+	if err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	if err = os.Remove(path); err != nil {
+		Debug(err)
+	}
+}
@@ -0,0 +1,230 @@
+package pubsub
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestEncodeMessage ensures notifications are serialized with the topic, body
+// and sequence number laid out as documented.
+func TestEncodeMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		topic string
+		body  []byte
+		seq   uint32
+		want  []byte
+	}{
+		{"empty body", TopicHashTx, nil, 0, []byte{
+			0x06, 'h', 'a', 's', 'h', 't', 'x',
+			0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00,
+		}},
+		{"body", TopicReorg, []byte{0xaa, 0xbb, 0xcc}, 0x01020304, []byte{
+			0x05, 'r', 'e', 'o', 'r', 'g',
+			0x03, 0x00, 0x00, 0x00,
+			0xaa, 0xbb, 0xcc,
+			0x04, 0x03, 0x02, 0x01,
+		}},
+		{"last sequence", "t", []byte{0x01}, 0xffffffff, []byte{
+			0x01, 't',
+			0x01, 0x00, 0x00, 0x00,
+			0x01,
+			0xff, 0xff, 0xff, 0xff,
+		}},
+	}
+	for _, test := range tests {
+		got := encodeMessage(test.topic, test.body, test.seq)
+		if !bytes.Equal(got, test.want) {
+			t.Errorf("%s: got %x, want %x", test.name, got, test.want)
+		}
+	}
+	// The length of the body is written in full past a single byte.
+	body := make([]byte, 0x0102)
+	got := encodeMessage("t", body, 0)
+	if want := []byte{0x02, 0x01, 0x00, 0x00}; !bytes.Equal(got[2:6], want) {
+		t.Errorf("long body: got length %x, want %x", got[2:6], want)
+	}
+	if len(got) != 2+4+len(body)+4 {
+		t.Errorf("long body: got %d bytes, want %d", len(got),
+			2+4+len(body)+4)
+	}
+}
+
+// TestParseEndpoint ensures endpoints are split into the network and address
+// to listen on, and that endpoints that can't be listened on are refused.
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		network  string
+		address  string
+		err      string
+	}{
+		{"tcp://127.0.0.1:28332", "tcp", "127.0.0.1:28332", ""},
+		{"127.0.0.1:28332", "tcp", "127.0.0.1:28332", ""},
+		{":28332", "tcp", ":28332", ""},
+		{"unix:///tmp/pod.sock", "unix", "/tmp/pod.sock", ""},
+		{"ipc:///tmp/pod.sock", "", "", "unsupported"},
+		{"tcp://", "", "", "no address"},
+		{"unix://", "", "", "no address"},
+		{"", "", "", "no address"},
+	}
+	for _, test := range tests {
+		network, address, err := parseEndpoint(test.endpoint)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%q: got error %v, want one containing %q",
+					test.endpoint, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.endpoint, err)
+			continue
+		}
+		if network != test.network || address != test.address {
+			t.Errorf("%q: got %s %s, want %s %s", test.endpoint, network,
+				address, test.network, test.address)
+		}
+	}
+}
+
+// testPublisher returns a publisher on a local TCP port that is not started.
+func testPublisher(t *testing.T) *publisher {
+	t.Helper()
+	p, err := newPublisher("tcp://127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("newPublisher: %v", err)
+	}
+	p.topics[TopicHashTx] = struct{}{}
+	return p
+}
+
+// TestPublish ensures a subscriber receives the notifications published after
+// it connects, with their sequence numbers counting up.
+func TestPublish(t *testing.T) {
+	p := testPublisher(t)
+	p.start()
+	defer p.stop()
+	conn, err := net.Dial("tcp", p.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to subscribe: %v", err)
+	}
+	defer conn.Close()
+	// Wait for the subscriber to be registered, so that it doesn't miss the
+	// notifications.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		p.mtx.Lock()
+		n := len(p.subscribers)
+		p.mtx.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d subscribers, want 1", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	var want []byte
+	for seq := uint32(0); seq < 3; seq++ {
+		body := []byte{byte(seq)}
+		p.publish(TopicHashTx, body)
+		want = append(want, encodeMessage(TopicHashTx, body, seq)...)
+	}
+	if err = conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, len(want))
+	if _, err = io.ReadFull(conn, got); err != nil {
+		t.Fatalf("unable to read notifications: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+}
+
+// TestSlowSubscriber ensures notifications are dropped for a subscriber whose
+// queue is full, without holding up the others or the sequence numbers.
+func TestSlowSubscriber(t *testing.T) {
+	p := testPublisher(t)
+	defer p.stop()
+	// The subscribers are registered without handlers, so that nothing
+	// drains their queues.
+	newSubscriber := func() *subscriber {
+		conn, _ := net.Pipe()
+		s := &subscriber{
+			conn:  conn,
+			queue: make(chan []byte, maxQueuedMessages),
+		}
+		p.subscribers[s] = struct{}{}
+		return s
+	}
+	slow, fast := newSubscriber(), newSubscriber()
+	for i := 0; i < maxQueuedMessages; i++ {
+		p.publish(TopicHashTx, nil)
+		<-fast.queue
+	}
+	p.publish(TopicHashTx, []byte{0x01})
+	if len(slow.queue) != maxQueuedMessages {
+		t.Errorf("slow subscriber: got %d queued, want %d", len(slow.queue),
+			maxQueuedMessages)
+	}
+	if got := <-slow.queue; !bytes.Equal(got,
+		encodeMessage(TopicHashTx, nil, 0)) {
+		t.Errorf("slow subscriber: got first message %x, want sequence 0",
+			got)
+	}
+	want := encodeMessage(TopicHashTx, []byte{0x01}, maxQueuedMessages)
+	select {
+	case got := <-fast.queue:
+		if !bytes.Equal(got, want) {
+			t.Errorf("fast subscriber: got %x, want %x", got, want)
+		}
+	default:
+		t.Errorf("fast subscriber: got no message, want %x", want)
+	}
+	if got := p.sequence[TopicHashTx]; got != maxQueuedMessages+1 {
+		t.Errorf("got sequence %d, want %d", got, maxQueuedMessages+1)
+	}
+}
+
+// oneConnListener is a listener that accepts a single connection and then
+// fails as if it was closed.
+type oneConnListener struct {
+	net.Listener
+	conn net.Conn
+}
+
+func (l *oneConnListener) Accept() (net.Conn, error) {
+	if l.conn == nil {
+		return nil, errors.New("listener closed")
+	}
+	conn := l.conn
+	l.conn = nil
+	return conn, nil
+}
+
+// TestAcceptAfterStop ensures a subscriber accepted after the publisher was
+// stopped is disconnected instead of being registered.
+func TestAcceptAfterStop(t *testing.T) {
+	p := testPublisher(t)
+	p.stop()
+	local, remote := net.Pipe()
+	defer remote.Close()
+	p.listener = &oneConnListener{Listener: p.listener, conn: local}
+	p.wg.Add(1)
+	p.acceptHandler()
+	if len(p.subscribers) != 0 {
+		t.Errorf("got %d subscribers, want 0", len(p.subscribers))
+	}
+	// The subscriber sees its connection closed.
+	if _, err := remote.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("got read error %v, want %v", err, io.EOF)
+	}
+}
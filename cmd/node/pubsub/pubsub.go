@@ -0,0 +1,208 @@
+// Package pubsub publishes notifications of new blocks, transactions and chain
+// reorganizations to subscribers connected to plain TCP or Unix sockets, in
+// the style of ZeroMQ PUB sockets, so that services can follow the node
+// without polling or keeping an RPC websocket session.
+//
+// Each notification is written as one message holding its topic, its body and
+// a sequence number, which counts up from zero for each topic on each endpoint
+// so that subscribers can tell when they have missed notifications:
+//
+//	topic length  1 byte
+//	topic         topic length bytes
+//	body length   4 bytes, little endian
+//	body          body length bytes
+//	sequence      4 bytes, little endian
+//
+// Notifications are dropped for subscribers that don't keep up, rather than
+// holding up the node.
+package pubsub
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/p9c/pod/cmd/node/mempool"
+	blockchain "github.com/p9c/pod/pkg/chain"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// The topics notifications are published on.
+const (
+	// TopicHashBlock carries the hash of each block connected to the main
+	// chain, in the byte order it is displayed in.
+	TopicHashBlock = "hashblock"
+	// TopicHashTx carries the hash of each transaction accepted into the
+	// mempool or connected to the main chain in a block, in the byte order it
+	// is displayed in.
+	TopicHashTx = "hashtx"
+	// TopicRawBlock carries each block connected to the main chain, serialized.
+	TopicRawBlock = "rawblock"
+	// TopicRawTx carries each transaction accepted into the mempool or
+	// connected to the main chain in a block, serialized with witness data.
+	TopicRawTx = "rawtx"
+	// TopicReorg carries the hash of each block disconnected from the main
+	// chain, in the byte order it is displayed in, followed by its height as 4
+	// bytes in little endian order.
+	TopicReorg = "reorg"
+)
+
+// Topics are all the topics notifications are published on.
+var Topics = []string{
+	TopicHashBlock, TopicHashTx, TopicRawBlock, TopicRawTx, TopicReorg,
+}
+
+// Notifier publishes notifications to the subscribers of the endpoints that
+// are configured for each topic.
+type Notifier struct {
+	publishers []*publisher
+}
+
+// New returns a Notifier that publishes each topic in the map on its
+// endpoints, which are addresses of the form tcp://host:port or
+// unix:///path/to/socket, where addresses without a scheme are TCP ones.
+// Several topics may share an endpoint.  The endpoints are listened on right
+// away, and subscribers are accepted once the Notifier is started.
+func New(endpoints map[string][]string) (*Notifier, error) {
+	for topic := range endpoints {
+		if !isTopic(topic) {
+			return nil, fmt.Errorf("unknown notification topic %q", topic)
+		}
+	}
+	n := &Notifier{}
+	byEndpoint := make(map[string]*publisher)
+	for _, topic := range Topics {
+		for _, endpoint := range endpoints[topic] {
+			p, ok := byEndpoint[endpoint]
+			if !ok {
+				var err error
+				if p, err = newPublisher(endpoint); err != nil {
+					Error(err)
+					n.Stop()
+					return nil, err
+				}
+				byEndpoint[endpoint] = p
+				n.publishers = append(n.publishers, p)
+			}
+			p.topics[topic] = struct{}{}
+		}
+	}
+	return n, nil
+}
+
+// Start begins accepting subscribers on all the endpoints.
+func (n *Notifier) Start() {
+	for _, p := range n.publishers {
+		p.start()
+	}
+}
+
+// Stop stops listening on all the endpoints and disconnects their
+// subscribers.
+func (n *Notifier) Stop() {
+	for _, p := range n.publishers {
+		p.stop()
+	}
+}
+
+// HandleBlockchainNotification publishes the blocks connected to and
+// disconnected from the main chain along with their transactions. It is to be
+// subscribed to the chain's notifications.
+func (n *Notifier) HandleBlockchainNotification(notification *blockchain.Notification) {
+	switch notification.Type {
+	case blockchain.NTBlockConnected:
+		block, ok := notification.Data.(*util.Block)
+		if !ok {
+			Warn("chain connected notification is not a block")
+			break
+		}
+		n.publish(TopicHashBlock, func() []byte {
+			return displayHash(block.Hash())
+		})
+		n.publish(TopicRawBlock, func() []byte {
+			blockBytes, err := block.Bytes()
+			if err != nil {
+				Error(err)
+				return nil
+			}
+			return blockBytes
+		})
+		for _, tx := range block.Transactions() {
+			n.publishTx(tx)
+		}
+	case blockchain.NTBlockDisconnected:
+		block, ok := notification.Data.(*util.Block)
+		if !ok {
+			Warn("chain disconnected notification is not a block")
+			break
+		}
+		n.publish(TopicReorg, func() []byte {
+			var height [4]byte
+			binary.LittleEndian.PutUint32(height[:], uint32(block.Height()))
+			return append(displayHash(block.Hash()), height[:]...)
+		})
+	}
+}
+
+// NotifyNewTransactions publishes the transactions newly accepted into the
+// mempool.
+func (n *Notifier) NotifyNewTransactions(txns []*mempool.TxDesc) {
+	for _, txD := range txns {
+		n.publishTx(txD.Tx)
+	}
+}
+
+// publishTx publishes a transaction on the transaction topics.
+func (n *Notifier) publishTx(tx *util.Tx) {
+	n.publish(TopicHashTx, func() []byte {
+		return displayHash(tx.Hash())
+	})
+	n.publish(TopicRawTx, func() []byte {
+		msgTx := tx.MsgTx()
+		buf := bytes.NewBuffer(make([]byte, 0, msgTx.SerializeSize()))
+		if err := msgTx.Serialize(buf); err != nil {
+			Error(err)
+			return nil
+		}
+		return buf.Bytes()
+	})
+}
+
+// publish sends a notification to the subscribers of the topic on all the
+// endpoints it is published on. The body is only made when the topic is
+// published on any endpoint, and nothing is sent if it can't be made.
+func (n *Notifier) publish(topic string, body func() []byte) {
+	var b []byte
+	for _, p := range n.publishers {
+		if _, ok := p.topics[topic]; !ok {
+			continue
+		}
+		if b == nil {
+			if b = body(); b == nil {
+				return
+			}
+		}
+		p.publish(topic, b)
+	}
+}
+
+// displayHash returns the bytes of a hash in the order it is displayed in,
+// which is the reverse of the order it is serialized in.
+func displayHash(hash *chainhash.Hash) []byte {
+	b := make([]byte, chainhash.HashSize)
+	for i := range b {
+		b[i] = hash[chainhash.HashSize-1-i]
+	}
+	return b
+}
+
+// isTopic returns whether notifications are published on a topic.
+func isTopic(topic string) bool {
+	for _, t := range Topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
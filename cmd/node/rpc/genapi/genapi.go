@@ -38,12 +38,24 @@ var handlers = handlersT{
 		Cmd:     "*btcjson.AddNodeCmd",
 		ResType: "None",
 	},
+	{
+		Method:  "combinepsbt",
+		Handler: "CombinePSBT",
+		Cmd:     "*btcjson.CombinePSBTCmd",
+		ResType: "string",
+	},
 	{
 		Method:  "createrawtransaction",
 		Handler: "CreateRawTransaction",
 		Cmd:     "*btcjson.CreateRawTransactionCmd",
 		ResType: "string",
 	},
+	{
+		Method:  "decodepsbt",
+		Handler: "DecodePSBT",
+		Cmd:     "*btcjson.DecodePSBTCmd",
+		ResType: "btcjson.DecodePSBTResult",
+	},
 	{
 		Method:  "decoderawtransaction",
 		Handler: "DecodeRawTransaction",
@@ -62,10 +74,22 @@ var handlers = handlersT{
 		Cmd:     "*btcjson.EstimateFeeCmd",
 		ResType: "float64",
 	},
+	{
+		Method:  "finalizepsbt",
+		Handler: "FinalizePSBT",
+		Cmd:     "*btcjson.FinalizePSBTCmd",
+		ResType: "btcjson.FinalizePSBTResult",
+	},
 	{
 		Method:  "generate",
 		Handler: "Generate",
-		Cmd:     "*None",
+		Cmd:     "*btcjson.GenerateCmd",
+		ResType: "[]string",
+	},
+	{
+		Method:  "generatealgo",
+		Handler: "GenerateAlgo",
+		Cmd:     "*btcjson.GenerateAlgoCmd",
 		ResType: "[]string",
 	},
 	{
@@ -122,6 +146,12 @@ var handlers = handlersT{
 		Cmd:     "*btcjson.GetBlockTemplateCmd",
 		ResType: "string",
 	},
+	{
+		Method:  "getchaintips",
+		Handler: "GetChainTips",
+		Cmd:     "*None",
+		ResType: "[]btcjson.GetChainTipsResult",
+	},
 	{
 		Method:  "getcfilter",
 		Handler: "GetCFilter",
@@ -176,6 +206,24 @@ var handlers = handlersT{
 		Cmd:     "*None",
 		ResType: "btcjson.InfoChainResult0",
 	},
+	{
+		Method:  "getmempoolancestors",
+		Handler: "GetMempoolAncestors",
+		Cmd:     "*btcjson.GetMempoolAncestorsCmd",
+		ResType: "[]string",
+	},
+	{
+		Method:  "getmempooldescendants",
+		Handler: "GetMempoolDescendants",
+		Cmd:     "*btcjson.GetMempoolDescendantsCmd",
+		ResType: "[]string",
+	},
+	{
+		Method:  "getmempoolentry",
+		Handler: "GetMempoolEntry",
+		Cmd:     "*btcjson.GetMempoolEntryCmd",
+		ResType: "btcjson.GetMempoolEntryResult",
+	},
 	{
 		Method:  "getmempoolinfo",
 		Handler: "GetMempoolInfo",
@@ -200,12 +248,24 @@ var handlers = handlersT{
 		Cmd:     "*btcjson.GetNetworkHashPSCmd",
 		ResType: "[]btcjson.GetPeerInfoResult",
 	},
+	{
+		Method:  "getnetworkinfo",
+		Handler: "GetNetworkInfo",
+		Cmd:     "*None",
+		ResType: "btcjson.GetNetworkInfoResult",
+	},
 	{
 		Method:  "getpeerinfo",
 		Handler: "GetPeerInfo",
 		Cmd:     "*None",
 		ResType: "[]btcjson.GetPeerInfoResult",
 	},
+	{
+		Method:  "getpoolbalances",
+		Handler: "GetPoolBalances",
+		Cmd:     "*None",
+		ResType: "[]btcjson.GetPoolBalancesResult",
+	},
 	{
 		Method:  "getrawmempool",
 		Handler: "GetRawMempool",
@@ -230,18 +290,60 @@ var handlers = handlersT{
 		Cmd:     "*btcjson.HelpCmd",
 		ResType: "string",
 	},
+	{
+		Method:  "invalidateblock",
+		Handler: "InvalidateBlock",
+		Cmd:     "*btcjson.InvalidateBlockCmd",
+		ResType: "None",
+	},
 	{
 		Method:  "node",
 		Handler: "Node",
 		Cmd:     "*btcjson.NodeCmd",
 		ResType: "None",
 	},
+	{
+		Method:  "loadmempool",
+		Handler: "LoadMempool",
+		Cmd:     "*None",
+		ResType: "None",
+	},
 	{
 		Method:  "ping",
 		Handler: "Ping",
 		Cmd:     "*None",
 		ResType: "None",
 	},
+	{
+		Method:  "poolpayout",
+		Handler: "PoolPayout",
+		Cmd:     "*btcjson.PoolPayoutCmd",
+		ResType: "btcjson.PoolPayoutResult",
+	},
+	{
+		Method:  "preciousblock",
+		Handler: "PreciousBlock",
+		Cmd:     "*btcjson.PreciousBlockCmd",
+		ResType: "None",
+	},
+	{
+		Method:  "prioritisetransaction",
+		Handler: "PrioritiseTransaction",
+		Cmd:     "*btcjson.PrioritiseTransactionCmd",
+		ResType: "bool",
+	},
+	{
+		Method:  "reconsiderblock",
+		Handler: "ReconsiderBlock",
+		Cmd:     "*btcjson.ReconsiderBlockCmd",
+		ResType: "None",
+	},
+	{
+		Method:  "savemempool",
+		Handler: "SaveMempool",
+		Cmd:     "*None",
+		ResType: "None",
+	},
 	{
 		Method:  "searchrawtransactions",
 		Handler: "SearchRawTransactions",
@@ -0,0 +1,139 @@
+package rpc
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	"github.com/p9c/pod/pkg/chain/fork"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/rpc/btcjson"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// GenerateBlocks mines blocks on the best chain with the CPU and returns their
+// hashes. If a block version is given, each block is mined with its algorithm,
+// which must exist at the height of the block, otherwise with the first
+// algorithm of the hard fork. This is for the regression test and simulation
+// networks, where the targets are easy enough to mine blocks on demand.
+func GenerateBlocks(s *Server, numBlocks uint32, version *int32,
+	closeChan <-chan struct{}) (interface{}, error) {
+	// Respond with an error if there are no addresses to pay the created blocks
+	// to.
+	if len(s.StateCfg.ActiveMiningAddrs) == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInternal.Code,
+			Message: "No payment addresses specified via --miningaddr",
+		}
+	}
+	// Respond with an error if there's virtually 0 chance of mining a block
+	// with the CPU.
+	if !s.Cfg.ChainParams.GenerateSupported {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCDifficulty,
+			Message: fmt.Sprintf("No support for `generate` on the current"+
+				" network, %s, as it's unlikely to be possible to mine a block"+
+				" with the CPU.", s.Cfg.ChainParams.Net),
+		}
+	}
+	// Respond with an error if the client is requesting 0 blocks to be
+	// generated.
+	if numBlocks == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInternal.Code,
+			Message: "Please request a nonzero number of blocks to generate.",
+		}
+	}
+	rand.Seed(time.Now().UnixNano())
+	reply := make([]string, 0, numBlocks)
+	for i := uint32(0); i < numBlocks; i++ {
+		height := s.Cfg.Chain.BestSnapshot().Height + 1
+		var algo string
+		if version != nil {
+			var ok bool
+			algo, ok = s.Cfg.ChainParams.Forks.Fork(height).AlgoVers[*version]
+			if !ok {
+				return nil, &btcjson.RPCError{
+					Code: btcjson.ErrRPCInvalidParameter,
+					Message: fmt.Sprintf("block version %d has no algorithm"+
+						" at height %d", *version, height),
+				}
+			}
+		}
+		// Choose a payment address at random.
+		payToAddr := s.StateCfg.ActiveMiningAddrs[rand.Intn(len(s.StateCfg.
+			ActiveMiningAddrs))]
+		template, err := s.Cfg.Generator.NewBlockTemplate(0, payToAddr, algo)
+		if err != nil {
+			Error(err)
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInternal.Code,
+				Message: "Failed to create new block template: " + err.Error(),
+			}
+		}
+		solved, err := SolveBlock(s, template.Block, template.Height, closeChan)
+		if err != nil {
+			Error(err)
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInternal.Code,
+				Message: "Failed to solve block: " + err.Error(),
+			}
+		}
+		if !solved {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInternal.Code,
+				Message: "Block generation was interrupted",
+			}
+		}
+		block := util.NewBlock(template.Block)
+		block.SetHeight(template.Height)
+		// Process this block using the same rules as blocks coming from other
+		// nodes.  This will in turn relay it to the network like normal.
+		if _, err = s.Cfg.SyncMgr.SubmitBlock(block, blockchain.BFNone); err != nil {
+			Error(err)
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCVerify,
+				Message: "Generated block rejected: " + err.Error(),
+			}
+		}
+		reply = append(reply, block.Hash().String())
+	}
+	return reply, nil
+}
+
+// SolveBlock searches the extra nonce and the nonce of a block until its hash,
+// with the algorithm of its version at the height, meets its target. It
+// returns false if the close channel is closed first.
+func SolveBlock(s *Server, msgBlock *wire.MsgBlock, height int32,
+	closeChan <-chan struct{}) (solved bool, err error) {
+	header := &msgBlock.Header
+	target := fork.CompactToBig(header.Bits)
+	for extraNonce := uint64(0); ; extraNonce++ {
+		if err = s.Cfg.Generator.UpdateExtraNonce(msgBlock, height,
+			extraNonce); err != nil {
+			return
+		}
+		for nonce := uint32(0); ; nonce++ {
+			select {
+			case <-closeChan:
+				return
+			default:
+			}
+			header.Nonce = nonce
+			var hash chainhash.Hash
+			if hash, err = header.BlockHashWithAlgos(s.Cfg.ChainParams.Forks,
+				height); err != nil {
+				return
+			}
+			if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+				return true, nil
+			}
+			if nonce == math.MaxUint32 {
+				break
+			}
+		}
+	}
+}
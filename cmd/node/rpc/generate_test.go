@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/p9c/pod/cmd/node/state"
+	blockchain "github.com/p9c/pod/pkg/chain"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/chain/mining"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/rpc/btcjson"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// testTxSource is an empty source of transactions for block templates.
+type testTxSource struct{}
+
+func (testTxSource) LastUpdated() time.Time                    { return time.Time{} }
+func (testTxSource) MiningDescs() []*mining.TxDesc             { return nil }
+func (testTxSource) HaveTransaction(hash *chainhash.Hash) bool { return false }
+
+// testSyncManager submits blocks straight to the chain of a server.
+type testSyncManager struct {
+	ServerSyncManager
+	chain *blockchain.BlockChain
+}
+
+func (m *testSyncManager) SubmitBlock(block *util.Block,
+	flags blockchain.BehaviorFlags) (bool, error) {
+	_, isOrphan, err := m.chain.ProcessBlock(0, block, flags, block.Height())
+	return isOrphan, err
+}
+
+// testGenerateServer returns a server for the generate commands with a
+// regression test chain in a temporary directory, and a function that removes
+// it.
+func testGenerateServer(t *testing.T) (*Server, func()) {
+	t.Helper()
+	s, teardown := testRESTServer(t)
+	params := s.Cfg.ChainParams
+	addr, err := util.NewAddressPubKeyHash(make([]byte, 20), params)
+	if err != nil {
+		teardown()
+		t.Fatal(err)
+	}
+	s.StateCfg = &state.Config{ActiveMiningAddrs: []util.Address{addr}}
+	s.Cfg.Generator = mining.NewBlkTmplGenerator(&mining.Policy{
+		BlockMaxWeight: blockchain.MaxBlockWeight - 4000,
+		BlockMaxSize:   blockchain.MaxBlockBaseSize - 1000,
+	}, params, testTxSource{}, s.Cfg.Chain, blockchain.NewMedianTime(),
+		txscript.NewSigCache(1000), txscript.NewHashCache(1000))
+	s.Cfg.SyncMgr = &testSyncManager{chain: s.Cfg.Chain}
+	return s, teardown
+}
+
+// TestHandleGenerateAlgo ensures generatealgo mines blocks with the requested
+// version onto the chain, and refuses versions without an algorithm at the
+// height of the block.
+func TestHandleGenerateAlgo(t *testing.T) {
+	s, teardown := testGenerateServer(t)
+	defer teardown()
+	forks := s.Cfg.ChainParams.Forks
+	// the first hard fork activates at block 1 on the regression test
+	// network, so the last of its algorithms is not the default one
+	algos := forks.AlgoSlices(forks.Current(1))
+	version := algos[len(algos)-1].Version
+	reply, err := HandleGenerateAlgo(s,
+		&btcjson.GenerateAlgoCmd{NumBlocks: 2, Version: version}, nil)
+	if err != nil {
+		t.Fatalf("generatealgo %d failed: %v", version, err)
+	}
+	hashes := reply.([]string)
+	if len(hashes) != 2 {
+		t.Fatalf("generatealgo returned %d blocks, want 2", len(hashes))
+	}
+	for i, h := range hashes {
+		hash, err := chainhash.NewHashFromStr(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		header, err := s.Cfg.Chain.HeaderByHash(hash)
+		if err != nil {
+			t.Fatalf("block %d not in the chain: %v", i, err)
+		}
+		if header.Version != version {
+			t.Errorf("block %d has version %d, want %d", i, header.Version,
+				version)
+		}
+	}
+	if best := s.Cfg.Chain.BestSnapshot(); best.Height != 2 ||
+		best.Hash.String() != hashes[1] {
+		t.Fatalf("best block is %v at height %d, want the last generated",
+			best.Hash, best.Height)
+	}
+	_, err = HandleGenerateAlgo(s,
+		&btcjson.GenerateAlgoCmd{NumBlocks: 1, Version: 1}, nil)
+	if rerr, ok := err.(*btcjson.RPCError); !ok ||
+		rerr.Code != btcjson.ErrRPCInvalidParameter {
+		t.Fatalf("generatealgo with an unknown version returned %v, want"+
+			" an invalid parameter error", err)
+	}
+}
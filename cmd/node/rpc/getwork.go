@@ -310,8 +310,9 @@ func HandleGetWorkSubmission(s *Server, hexData string) (interface{}, error) {
 	merkles := blockchain.BuildMerkleTreeStore(block.Transactions(), false)
 	msgBlock.Header.MerkleRoot = *merkles[len(merkles)-1]
 	// Ensure the submitted block hash is less than the target difficulty.
-	pl := fork.GetMinDiff(s.Cfg.Algo, s.Cfg.Chain.BestSnapshot().Height)
-	err = blockchain.CheckProofOfWork(block, pl, s.Cfg.Chain.BestSnapshot().Height)
+	forks := s.Cfg.ChainParams.Forks
+	pl := forks.MinDiff(s.Cfg.Algo, s.Cfg.Chain.BestSnapshot().Height)
+	err = blockchain.CheckProofOfWork(block, pl, forks, s.Cfg.Chain.BestSnapshot().Height)
 	if err != nil {
 		Error(err)
 		// Anything other than a rule violation is an unexpected error, so return
@@ -11,6 +11,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -369,54 +370,47 @@ func HandleGenerate(
 	cmd interface{},
 	closeChan <-chan struct{},
 ) (interface{}, error) {
-	// Respond with an error if there are no addresses to pay the created blocks
-	// to.
-	if len(s.StateCfg.ActiveMiningAddrs) == 0 {
+	var msg string
+	var err error
+	c, ok := cmd.(*btcjson.GenerateCmd)
+	if !ok {
+		var h string
+		h, err = s.HelpCacher.RPCMethodHelp("generate")
+		if err != nil {
+			msg = err.Error() + "\n\n"
+		}
+		msg += h
 		return nil, &btcjson.RPCError{
-			Code:    btcjson.ErrRPCInternal.Code,
-			Message: "No payment addresses specified via --miningaddr",
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: msg,
 		}
 	}
-	// Respond with an error if there's virtually 0 chance of mining a block
-	// with the CPU.
-	if !s.Cfg.ChainParams.GenerateSupported {
+	// with no algorithm given the first one of the hard fork is mined
+	return GenerateBlocks(s, c.NumBlocks, nil, closeChan)
+}
+
+// HandleGenerateAlgo handles generatealgo commands.
+func HandleGenerateAlgo(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	var msg string
+	var err error
+	c, ok := cmd.(*btcjson.GenerateAlgoCmd)
+	if !ok {
+		var h string
+		h, err = s.HelpCacher.RPCMethodHelp("generatealgo")
+		if err != nil {
+			msg = err.Error() + "\n\n"
+		}
+		msg += h
 		return nil, &btcjson.RPCError{
-			Code: btcjson.ErrRPCDifficulty,
-			Message: fmt.Sprintf("No support for `generate` on the current"+
-				" network, %s, as it's unlikely to be possible to mine a block"+
-				" with the CPU.", s.Cfg.ChainParams.Net),
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: msg,
 		}
 	}
-	Debug("cpu miner stuff is missing here")
-	// Set the algorithm according to the port we were called on
-	// s.Cfg.CPUMiner.SetAlgo(s.Cfg.Algo)
-	// c := cmd.(*btcjson.GenerateCmd)
-	// // Respond with an error if the client is requesting 0 blocks to be
-	// // generated.
-	// if c.NumBlocks == 0 {
-	// 	return nil, &btcjson.RPCError{
-	// 		Code:    btcjson.ErrRPCInternal.Code,
-	// 		Message: "Please request a nonzero number of blocks to generate.",
-	// 	}
-	// }
-	// // Create a reply
-	// reply := make([]string, c.NumBlocks)
-	// blockHashes, err := s.Cfg.CPUMiner.GenerateNBlocks(0, c.NumBlocks,
-	// 	s.Cfg.Algo)
-	// if err != nil {
-	// 	L.ScriptError(err)
-	// 	return nil, &btcjson.RPCError{
-	// 		Code:    btcjson.ErrRPCInternal.Code,
-	// 		Message: err.ScriptError(),
-	// 	}
-	// }
-	// // Mine the correct number of blocks, assigning the hex representation of
-	// // the hash of each one to its place in the reply.
-	// for i, hash := range blockHashes {
-	// 	reply[i] = hash.String()
-	// }
-	// return reply, nil
-	return nil, nil
+	return GenerateBlocks(s, c.NumBlocks, &c.Version, closeChan)
 }
 
 // HandleGetAddedNodeInfo handles getaddednodeinfo commands.
@@ -585,6 +579,17 @@ func HandleGetBlock(
 	})
 	if err != nil {
 		Error(err)
+		// blocks of the main chain below the prune height are known, but no
+		// longer stored
+		height, e := s.Cfg.Chain.BlockHeightByHash(hash)
+		if pruneHeight := s.Cfg.Chain.PruneHeight(); e == nil &&
+			height < pruneHeight {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCMisc,
+				Message: fmt.Sprintf("Block not available (pruned data), "+
+					"blocks are stored from height %d", pruneHeight),
+			}
+		}
 		return nil, &btcjson.RPCError{
 			Code:    btcjson.ErrRPCBlockNotFound,
 			Message: "Block not found",
@@ -625,16 +630,22 @@ func HandleGetBlock(
 	}
 	params := s.Cfg.ChainParams
 	blockHeader := &blk.MsgBlock().Header
-	algoname := fork.GetAlgoName(blockHeader.Version, blockHeight)
-	a := fork.GetAlgoVer(algoname, blockHeight)
-	algoid := fork.GetAlgoID(algoname, blockHeight)
+	powHash, err := blk.MsgBlock().BlockHashWithAlgos(params.Forks, blockHeight)
+	if err != nil {
+		Error(err)
+		context := "Failed to hash block"
+		return nil, InternalRPCError(err.Error(), context)
+	}
+	algoname := s.Cfg.ChainParams.Forks.AlgoName(blockHeader.Version, blockHeight)
+	a := s.Cfg.ChainParams.Forks.AlgoVer(algoname, blockHeight)
+	algoid := s.Cfg.ChainParams.Forks.AlgoID(algoname, blockHeight)
 	blockReply := btcjson.GetBlockVerboseResult{
 		Hash:          c.Hash,
 		Version:       blockHeader.Version,
 		VersionHex:    fmt.Sprintf("%08x", blockHeader.Version),
 		PowAlgoID:     algoid,
 		PowAlgo:       algoname,
-		PowHash:       blk.MsgBlock().BlockHashWithAlgos(blockHeight).String(),
+		PowHash:       powHash.String(),
 		MerkleRoot:    blockHeader.MerkleRoot.String(),
 		PreviousHash:  blockHeader.PrevBlock.String(),
 		Nonce:         blockHeader.Nonce,
@@ -685,6 +696,7 @@ func HandleGetBlockChainInfo(
 	params := s.Cfg.ChainParams
 	chain := s.Cfg.Chain
 	chainSnapshot := chain.BestSnapshot()
+	pruneHeight := chain.PruneHeight()
 	chainInfo := &btcjson.GetBlockChainInfoResult{
 		Chain:         params.Name,
 		Blocks:        chainSnapshot.Height,
@@ -692,7 +704,8 @@ func HandleGetBlockChainInfo(
 		BestBlockHash: chainSnapshot.Hash.String(),
 		Difficulty:    GetDifficultyRatio(chainSnapshot.Bits, params, 2),
 		MedianTime:    chainSnapshot.MedianTime.Unix(),
-		Pruned:        false,
+		Pruned:        *s.Config.Prune != 0 || pruneHeight > 0,
+		PruneHeight:   pruneHeight,
 		Bip9SoftForks: make(map[string]*btcjson.Bip9SoftForkDescription),
 	}
 	// Next, populate the response with information describing the current
@@ -890,17 +903,17 @@ func HandleGetBlockHeader(
 		}
 		nextHashString = nextHash.String()
 	}
-	var a int32 = 2
-	if blockHeader.Version == 514 {
-		a = 514
-	}
 	params := s.Cfg.ChainParams
+	algoname := params.Forks.AlgoName(blockHeader.Version, blockHeight)
+	a := params.Forks.AlgoVer(algoname, blockHeight)
 	blockHeaderReply := btcjson.GetBlockHeaderVerboseResult{
 		Hash:          c.Hash,
 		Confirmations: int64(1 + best.Height - blockHeight),
 		Height:        blockHeight,
 		Version:       blockHeader.Version,
 		VersionHex:    fmt.Sprintf("%08x", blockHeader.Version),
+		PowAlgoID:     params.Forks.AlgoID(algoname, blockHeight),
+		PowAlgo:       algoname,
 		MerkleRoot:    blockHeader.MerkleRoot.String(),
 		NextHash:      nextHashString,
 		PreviousHash:  blockHeader.PrevBlock.String(),
@@ -1294,6 +1307,25 @@ func HandleGetCFilterHeader(
 	return hash.String(), nil
 }
 
+// HandleGetChainTips implements the getchaintips command.
+func HandleGetChainTips(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	tips := s.Cfg.Chain.ChainTips()
+	result := make([]btcjson.GetChainTipsResult, len(tips))
+	for i := range tips {
+		result[i] = btcjson.GetChainTipsResult{
+			Height:    tips[i].Height,
+			Hash:      tips[i].Hash.String(),
+			BranchLen: tips[i].BranchLen,
+			Status:    string(tips[i].Status),
+		}
+	}
+	return result, nil
+}
+
 // HandleGetConnectionCount implements the getconnectioncount command.
 func HandleGetConnectionCount(
 	s *Server,
@@ -1485,10 +1517,10 @@ func HandleGetInfo(
 		BestSnapshot()
 	v := s.Cfg.Chain.Index.LookupNode(&best.Hash)
 	foundcount, height := 0, best.Height
-	switch fork.GetCurrent(height) {
+	switch s.Cfg.ChainParams.Forks.Current(height) {
 	case 0:
 		for foundcount < 9 && height > 0 {
-			switch fork.GetAlgoName(v.Header().Version, height) {
+			switch s.Cfg.ChainParams.Forks.AlgoName(v.Header().Version, height) {
 			case fork.SHA256d:
 				if lastbitsSHA256D == 0 {
 					foundcount++
@@ -1525,7 +1557,7 @@ func HandleGetInfo(
 			TimeOffset:        int64(s.Cfg.TimeSource.Offset().Seconds()),
 			Connections:       s.Cfg.ConnMgr.ConnectedCount(),
 			Proxy:             *s.Config.Proxy,
-			PowAlgoID:         fork.GetAlgoID(s.Cfg.Algo, height),
+			PowAlgoID:         s.Cfg.ChainParams.Forks.AlgoID(s.Cfg.Algo, height),
 			PowAlgo:           s.Cfg.Algo,
 			Difficulty:        Difficulty,
 			DifficultySHA256D: dSHA256D,
@@ -1536,8 +1568,8 @@ func HandleGetInfo(
 	case 1:
 		foundcount, height := 0, best.Height
 		for foundcount < 9 &&
-			height > fork.List[fork.GetCurrent(height)].ActivationHeight-512 {
-			switch fork.GetAlgoName(v.Header().Version, height) {
+			height > s.Cfg.ChainParams.Forks.Forks[s.Cfg.ChainParams.Forks.Current(height)].ActivationHeight-512 {
+			switch s.Cfg.ChainParams.Forks.AlgoName(v.Header().Version, height) {
 			case fork.Scrypt:
 				if lastbitsScrypt == 0 {
 					foundcount++
@@ -1574,7 +1606,7 @@ func HandleGetInfo(
 			TimeOffset:          int64(s.Cfg.TimeSource.Offset().Seconds()),
 			Connections:         s.Cfg.ConnMgr.ConnectedCount(),
 			Proxy:               *s.Config.Proxy,
-			PowAlgoID:           fork.GetAlgoID(s.Cfg.Algo, height),
+			PowAlgoID:           s.Cfg.ChainParams.Forks.AlgoID(s.Cfg.Algo, height),
 			PowAlgo:             s.Cfg.Algo,
 			Difficulty:          Difficulty,
 			DifficultyBlake2b:   dBlake2b,
@@ -1593,6 +1625,129 @@ func HandleGetInfo(
 	return ret, nil
 }
 
+// HandleGetMempoolAncestors implements the getmempoolancestors command.
+func HandleGetMempoolAncestors(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	var msg string
+	var err error
+	c, ok := cmd.(*btcjson.GetMempoolAncestorsCmd)
+	if !ok {
+		var h string
+		h, err = s.HelpCacher.RPCMethodHelp("getmempoolancestors")
+		if err != nil {
+			msg = err.Error() + "\n\n"
+		}
+		msg += h
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: msg,
+		}
+	}
+	hash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		Error(err)
+		return nil, DecodeHexError(c.TxID)
+	}
+	descs, err := s.Cfg.TxMemPool.Ancestors(hash)
+	if err != nil {
+		return nil, NoTxInfoError(hash)
+	}
+	return MempoolEntries(s, descs, c.Verbose != nil && *c.Verbose), nil
+}
+
+// HandleGetMempoolDescendants implements the getmempooldescendants command.
+func HandleGetMempoolDescendants(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	var msg string
+	var err error
+	c, ok := cmd.(*btcjson.GetMempoolDescendantsCmd)
+	if !ok {
+		var h string
+		h, err = s.HelpCacher.RPCMethodHelp("getmempooldescendants")
+		if err != nil {
+			msg = err.Error() + "\n\n"
+		}
+		msg += h
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: msg,
+		}
+	}
+	hash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		Error(err)
+		return nil, DecodeHexError(c.TxID)
+	}
+	descs, err := s.Cfg.TxMemPool.Descendants(hash)
+	if err != nil {
+		return nil, NoTxInfoError(hash)
+	}
+	return MempoolEntries(s, descs, c.Verbose != nil && *c.Verbose), nil
+}
+
+// HandleGetMempoolEntry implements the getmempoolentry command.
+func HandleGetMempoolEntry(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	var msg string
+	var err error
+	c, ok := cmd.(*btcjson.GetMempoolEntryCmd)
+	if !ok {
+		var h string
+		h, err = s.HelpCacher.RPCMethodHelp("getmempoolentry")
+		if err != nil {
+			msg = err.Error() + "\n\n"
+		}
+		msg += h
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: msg,
+		}
+	}
+	hash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		Error(err)
+		return nil, DecodeHexError(c.TxID)
+	}
+	entry, err := s.Cfg.TxMemPool.MempoolEntry(hash)
+	if err != nil {
+		return nil, NoTxInfoError(hash)
+	}
+	return entry, nil
+}
+
+// MempoolEntries returns the hashes of mempool transactions, or their
+// details keyed by hash if verbose is set. Transactions that have left the
+// pool since they were found are skipped.
+func MempoolEntries(s *Server, descs []*mempool.TxDesc,
+	verbose bool) interface{} {
+	if !verbose {
+		hashes := make([]string, len(descs))
+		for i := range descs {
+			hashes[i] = descs[i].Tx.Hash().String()
+		}
+		sort.Strings(hashes)
+		return hashes
+	}
+	entries := make(map[string]*btcjson.GetMempoolEntryResult, len(descs))
+	for _, desc := range descs {
+		entry, err := s.Cfg.TxMemPool.MempoolEntry(desc.Tx.Hash())
+		if err != nil {
+			continue
+		}
+		entries[desc.Tx.Hash().String()] = entry
+	}
+	return entries
+}
+
 // HandleGetMempoolInfo implements the getmempoolinfo command.
 func HandleGetMempoolInfo(
 	s *Server,
@@ -1605,8 +1760,12 @@ func HandleGetMempoolInfo(
 		numBytes += int64(txD.Tx.MsgTx().SerializeSize())
 	}
 	ret := &btcjson.GetMempoolInfoResult{
-		Size:  int64(len(mempoolTxns)),
-		Bytes: numBytes,
+		Size:          int64(len(mempoolTxns)),
+		Bytes:         numBytes,
+		Usage:         s.Cfg.TxMemPool.Size(),
+		MaxMempool:    int64(*s.Config.MaxMempoolSize) * 1024 * 1024,
+		MempoolMinFee: s.Cfg.TxMemPool.MinFee().ToDUO(),
+		MinRelayTxFee: s.StateCfg.ActiveMinRelayTxFee.ToDUO(),
 	}
 	return ret, nil
 }
@@ -1640,10 +1799,10 @@ func HandleGetMiningInfo(s *Server, cmd interface{},
 	best := s.Cfg.Chain.BestSnapshot()
 	v := s.Cfg.Chain.Index.LookupNode(&best.Hash)
 	foundCount, height := 0, best.Height
-	switch fork.GetCurrent(height) {
+	switch s.Cfg.ChainParams.Forks.Current(height) {
 	case 0:
 		for foundCount < 2 && height > 0 {
-			switch fork.GetAlgoName(v.Header().Version, height) {
+			switch s.Cfg.ChainParams.Forks.AlgoName(v.Header().Version, height) {
 			case fork.SHA256d:
 				if lastbitsSHA256D == 0 {
 					foundCount++
@@ -1676,7 +1835,7 @@ func HandleGetMiningInfo(s *Server, cmd interface{},
 			CurrentBlockSize:   best.BlockSize,
 			CurrentBlockWeight: best.BlockWeight,
 			CurrentBlockTx:     best.NumTxns,
-			PowAlgoID:          fork.GetAlgoID(s.Cfg.Algo, height),
+			PowAlgoID:          s.Cfg.ChainParams.Forks.AlgoID(s.Cfg.Algo, height),
 			PowAlgo:            s.Cfg.Algo,
 			Difficulty:         Difficulty,
 			DifficultySHA256D:  dSHA256D,
@@ -1690,8 +1849,8 @@ func HandleGetMiningInfo(s *Server, cmd interface{},
 		}
 	case 1:
 		foundcount, height := 0, best.Height
-		for foundcount < 9 && height > fork.List[fork.GetCurrent(height)].ActivationHeight-512 {
-			switch fork.GetAlgoName(v.Header().Version, height) {
+		for foundcount < 9 && height > s.Cfg.ChainParams.Forks.Forks[s.Cfg.ChainParams.Forks.Current(height)].ActivationHeight-512 {
+			switch s.Cfg.ChainParams.Forks.AlgoName(v.Header().Version, height) {
 			case fork.Scrypt:
 				if lastbitsScrypt == 0 {
 					foundcount++
@@ -1724,7 +1883,7 @@ func HandleGetMiningInfo(s *Server, cmd interface{},
 			CurrentBlockSize:   best.BlockSize,
 			CurrentBlockWeight: best.BlockWeight,
 			CurrentBlockTx:     best.NumTxns,
-			PowAlgoID:          fork.GetAlgoID(s.Cfg.Algo, height),
+			PowAlgoID:          s.Cfg.ChainParams.Forks.AlgoID(s.Cfg.Algo, height),
 			PowAlgo:            s.Cfg.Algo,
 			Difficulty:         Difficulty,
 			DifficultyScrypt:   dScrypt,
@@ -1862,6 +2021,137 @@ func HandleGetNetworkHashPS(
 	return hashesPerSec.Int64(), nil
 }
 
+// onionPeersOnly returns whether the node is restricted to connecting to a set
+// of peers that are all onion addresses
+func onionPeersOnly(connectPeers []string) bool {
+	for _, addr := range connectPeers {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		if !strings.HasSuffix(host, ".onion") {
+			return false
+		}
+	}
+	return len(connectPeers) > 0
+}
+
+// addressFamilies returns whether the node has an IPv4 and an IPv6 address,
+// from the local addresses known to the address manager, the addresses the
+// node listener is bound to and the addresses of the network interfaces.
+func (s *Server) addressFamilies() (ipv4, ipv6 bool) {
+	var ips []net.IP
+	if s.Cfg.AddrManager != nil {
+		for _, la := range s.Cfg.AddrManager.LocalAddresses() {
+			ips = append(ips, la.NA.IP)
+		}
+	}
+	if !*s.Config.DisableListen {
+		for _, addr := range *s.Config.Listeners {
+			if host, _, err := net.SplitHostPort(addr); err == nil {
+				ips = append(ips, net.ParseIP(host))
+			}
+		}
+	}
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				ips = append(ips, ipNet.IP)
+			}
+		}
+	}
+	for _, ip := range ips {
+		if ip == nil || ip.IsLoopback() || ip.IsUnspecified() ||
+			ip.IsLinkLocalUnicast() {
+			continue
+		}
+		if ip.To4() != nil {
+			ipv4 = true
+		} else {
+			ipv6 = true
+		}
+	}
+	return
+}
+
+// HandleGetNetworkInfo implements the getnetworkinfo command.
+func HandleGetNetworkInfo(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	userAgent := &wire.MsgVersion{UserAgent: wire.DefaultUserAgent}
+	if err := userAgent.AddUserAgent(UserAgentName, UserAgentVersion,
+		*s.Config.UserAgentComments...); err != nil {
+		Error(err)
+	}
+	// Onion addresses are reached through the onion proxy, or the proxy if
+	// Tor is enabled without one.
+	proxy := *s.Config.Proxy
+	onionProxy := *s.Config.OnionProxy
+	if onionProxy == "" && *s.Config.Onion {
+		onionProxy = proxy
+	}
+	// IPv4 and IPv6 peers are not reached when connections are restricted
+	// to onion peers or every connection goes through the Tor proxy, and
+	// otherwise only from a family the node has an address in.
+	clearnet := !onionPeersOnly(*s.Config.ConnectPeers) &&
+		!(*s.Config.Onion && proxy != "" && onionProxy == proxy)
+	ipv4, ipv6 := s.addressFamilies()
+	networks := []btcjson.NetworksResult{
+		{
+			Name:      "ipv4",
+			Limited:   !(clearnet && ipv4),
+			Reachable: clearnet && ipv4,
+			Proxy:     proxy,
+		},
+		{
+			Name:      "ipv6",
+			Limited:   !(clearnet && ipv6),
+			Reachable: clearnet && ipv6,
+			Proxy:     proxy,
+		},
+		{
+			Name:      "onion",
+			Limited:   onionProxy == "",
+			Reachable: onionProxy != "",
+			Proxy:     onionProxy,
+		},
+	}
+	for i := range networks {
+		networks[i].ProxyRandomizeCredentials = networks[i].Proxy != "" &&
+			*s.Config.TorIsolation
+	}
+	localAddrs := make([]btcjson.LocalAddressesResult, 0)
+	if s.Cfg.AddrManager != nil {
+		for _, la := range s.Cfg.AddrManager.LocalAddresses() {
+			localAddrs = append(localAddrs, btcjson.LocalAddressesResult{
+				Address: la.NA.IP.String(),
+				Port:    la.NA.Port,
+				Score:   int32(la.Score),
+			})
+		}
+	}
+	relayFee := s.StateCfg.ActiveMinRelayTxFee.ToDUO()
+	return &btcjson.GetNetworkInfoResult{
+		Version: int32(
+			1000000*version.AppMajor +
+				10000*version.AppMinor +
+				100*version.AppPatch),
+		SubVersion:      userAgent.UserAgent,
+		ProtocolVersion: int32(MaxProtocolVersion),
+		LocalServices:   fmt.Sprintf("%016x", uint64(s.Cfg.Services)),
+		LocalRelay:      !*s.Config.BlocksOnly,
+		TimeOffset:      int64(s.Cfg.TimeSource.Offset().Seconds()),
+		Connections:     s.Cfg.ConnMgr.ConnectedCount(),
+		NetworkActive:   true,
+		Networks:        networks,
+		RelayFee:        relayFee,
+		IncrementalFee:  relayFee,
+		LocalAddresses:  localAddrs,
+	}, nil
+}
+
 // HandleGetPeerInfo implements the getpeerinfo command.
 func HandleGetPeerInfo(
 	s *Server,
@@ -1905,6 +2195,24 @@ func HandleGetPeerInfo(
 	return infos, nil
 }
 
+// HandleGetPoolBalances implements the getpoolbalances command.
+func HandleGetPoolBalances(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	pool := s.PoolLedger()
+	if pool == nil {
+		return nil, ErrRPCNoPool
+	}
+	balances, err := pool.PoolBalances()
+	if err != nil {
+		Error(err)
+		return nil, InternalRPCError(err.Error(), "Failed to read pool ledger")
+	}
+	return balances, nil
+}
+
 // HandleGetRawMempool implements the getrawmempool command.
 func HandleGetRawMempool(
 	s *Server,
@@ -2149,30 +2457,12 @@ func HandleGetTxOut(
 		pkScript = entry.PkScript()
 		isCoinbase = entry.IsCoinBase()
 	}
-	// Disassemble script into single line printable format. The disassembled
-	// string will contain [error] inline if the script doesn't fully parse, so
-	// ignore the error here.
-	disbuf, _ := txscript.DisasmString(pkScript)
-	// Get further info about the script. Ignore the error here since an error
-	// means the script couldn't parse and there is no additional information
-	// about it anyways.
-	scriptClass, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(pkScript, s.Cfg.ChainParams)
-	addresses := make([]string, len(addrs))
-	for i, addr := range addrs {
-		addresses[i] = addr.EncodeAddress()
-	}
 	txOutReply := &btcjson.GetTxOutResult{
 		BestBlock:     bestBlockHash,
 		Confirmations: int64(confirmations),
 		Value:         util.Amount(value).ToDUO(),
-		ScriptPubKey: btcjson.ScriptPubKeyResult{
-			Asm:       disbuf,
-			Hex:       hex.EncodeToString(pkScript),
-			ReqSigs:   int32(reqSigs),
-			Type:      scriptClass.String(),
-			Addresses: addresses,
-		},
-		Coinbase: isCoinbase,
+		ScriptPubKey:  ScriptPubKeyResult(pkScript, s.Cfg.ChainParams),
+		Coinbase:      isCoinbase,
 	}
 	return txOutReply, nil
 }
@@ -2233,6 +2523,58 @@ func HandleHelp(
 	return help, nil
 }
 
+// HandleInvalidateBlock implements the invalidateblock command.
+func HandleInvalidateBlock(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	var msg string
+	var err error
+	c, ok := cmd.(*btcjson.InvalidateBlockCmd)
+	if !ok {
+		var h string
+		h, err = s.HelpCacher.RPCMethodHelp("invalidateblock")
+		if err != nil {
+			msg = err.Error() + "\n\n"
+		}
+		msg += h
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: msg,
+		}
+	}
+	hash, err := IndexedBlockHash(s, c.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.Cfg.Chain.InvalidateBlock(hash); err != nil {
+		Error(err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDatabase,
+			Message: err.Error(),
+		}
+	}
+	return nil, nil
+}
+
+// IndexedBlockHash decodes the hash of a block and returns an error for the
+// RPC client if it is not in the block index.
+func IndexedBlockHash(s *Server, hashStr string) (*chainhash.Hash, error) {
+	hash, err := chainhash.NewHashFromStr(hashStr)
+	if err != nil {
+		Error(err)
+		return nil, DecodeHexError(hashStr)
+	}
+	if s.Cfg.Chain.Index.LookupNode(hash) == nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found",
+		}
+	}
+	return hash, nil
+}
+
 // HandleNode handles node commands.
 func HandleNode(
 	s *Server,
@@ -2339,6 +2681,24 @@ func HandleNode(
 	return nil, nil
 }
 
+// HandleLoadMempool implements the loadmempool command.
+func HandleLoadMempool(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	_, err := s.Cfg.TxMemPool.LoadFile(s.Cfg.Chain,
+		MempoolFile(s.Config, s.Cfg.ChainParams))
+	if err != nil {
+		Error(err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Failed to load the mempool: " + err.Error(),
+		}
+	}
+	return nil, nil
+}
+
 // HandlePing implements the ping command.
 func HandlePing(
 	s *Server,
@@ -2355,6 +2715,161 @@ func HandlePing(
 	return nil, nil
 }
 
+// HandlePoolPayout implements the poolpayout command.
+func HandlePoolPayout(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	c := cmd.(*btcjson.PoolPayoutCmd)
+	pool := s.PoolLedger()
+	if pool == nil {
+		return nil, ErrRPCNoPool
+	}
+	var minAmount util.Amount
+	if c.MinAmount != nil {
+		var err error
+		if minAmount, err = util.NewAmount(*c.MinAmount); err != nil {
+			Error(err)
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Invalid minimum amount: " + err.Error(),
+			}
+		}
+	}
+	dryRun := c.DryRun != nil && *c.DryRun
+	result, err := pool.PoolPayout(minAmount, dryRun)
+	if err != nil {
+		Error(err)
+		return nil, InternalRPCError(err.Error(), "Failed to pay out pool")
+	}
+	return result, nil
+}
+
+// HandlePreciousBlock implements the preciousblock command.
+func HandlePreciousBlock(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	var msg string
+	var err error
+	c, ok := cmd.(*btcjson.PreciousBlockCmd)
+	if !ok {
+		var h string
+		h, err = s.HelpCacher.RPCMethodHelp("preciousblock")
+		if err != nil {
+			msg = err.Error() + "\n\n"
+		}
+		msg += h
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: msg,
+		}
+	}
+	hash, err := IndexedBlockHash(s, c.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.Cfg.Chain.PreciousBlock(hash); err != nil {
+		Error(err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDatabase,
+			Message: err.Error(),
+		}
+	}
+	return nil, nil
+}
+
+// HandlePrioritiseTransaction implements the prioritisetransaction command.
+func HandlePrioritiseTransaction(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	var msg string
+	var err error
+	c, ok := cmd.(*btcjson.PrioritiseTransactionCmd)
+	if !ok {
+		var h string
+		h, err = s.HelpCacher.RPCMethodHelp("prioritisetransaction")
+		if err != nil {
+			msg = err.Error() + "\n\n"
+		}
+		msg += h
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: msg,
+		}
+	}
+	hash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		Error(err)
+		return nil, DecodeHexError(c.TxID)
+	}
+	if c.PriorityDelta != 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Priority is not supported, set the priority delta to 0",
+		}
+	}
+	s.Cfg.TxMemPool.PrioritiseTransaction(hash, c.FeeDelta)
+	return true, nil
+}
+
+// HandleReconsiderBlock implements the reconsiderblock command.
+func HandleReconsiderBlock(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	var msg string
+	var err error
+	c, ok := cmd.(*btcjson.ReconsiderBlockCmd)
+	if !ok {
+		var h string
+		h, err = s.HelpCacher.RPCMethodHelp("reconsiderblock")
+		if err != nil {
+			msg = err.Error() + "\n\n"
+		}
+		msg += h
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: msg,
+		}
+	}
+	hash, err := IndexedBlockHash(s, c.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.Cfg.Chain.ReconsiderBlock(hash); err != nil {
+		Error(err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDatabase,
+			Message: err.Error(),
+		}
+	}
+	return nil, nil
+}
+
+// HandleSaveMempool implements the savemempool command.
+func HandleSaveMempool(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	_, err := s.Cfg.TxMemPool.SaveFile(MempoolFile(s.Config,
+		s.Cfg.ChainParams))
+	if err != nil {
+		Error(err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Failed to save the mempool: " + err.Error(),
+		}
+	}
+	return nil, nil
+}
+
 // HandleSearchRawTransactions implements the searchrawtransactions command.
 // TODO: simplify this, break it up
 func HandleSearchRawTransactions(
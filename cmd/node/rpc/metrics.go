@@ -0,0 +1,120 @@
+package rpc
+
+import (
+	"github.com/p9c/pod/pkg/metrics"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// maxAlgoSearchDepth is how many blocks back from the tip are searched for
+// the latest block of each algorithm.
+const maxAlgoSearchDepth = 1000
+
+// rpcRequestSeconds is the latency of the node RPC requests by method.
+var rpcRequestSeconds = metrics.NewHistogram(
+	"pod_rpc_request_duration_seconds",
+	"Time taken to handle node RPC requests, by method.",
+	metrics.LatencyBuckets, "method",
+)
+
+// RegisterMetrics adds the collectors of the node and its RPC servers to the
+// default metrics registry.
+func (n *Node) RegisterMetrics() {
+	metrics.Register("node", metrics.CollectorFunc(n.CollectMetrics))
+	metrics.Register("node_rpc", rpcRequestSeconds)
+}
+
+// CollectMetrics adds the current state of the chain, the mempool and the
+// peers to a scrape.
+func (n *Node) CollectMetrics(s *metrics.Scrape) {
+	n.collectChainMetrics(s)
+	n.collectMempoolMetrics(s)
+	n.collectPeerMetrics(s)
+}
+
+// collectChainMetrics adds the height of the chain and the height and
+// difficulty of the latest block of each algorithm of the current hard fork.
+func (n *Node) collectChainMetrics(s *metrics.Scrape) {
+	best := n.Chain.BestSnapshot()
+	s.Gauge("pod_chain_height", "Height of the best block.",
+		float64(best.Height))
+	forks := n.ChainParams.Forks
+	for _, b := range LatestAlgoBlocks(n.Chain, n.ChainParams,
+		maxAlgoSearchDepth) {
+		s.Gauge("pod_chain_algo_height",
+			"Height of the latest block of each mining algorithm.",
+			float64(b.Height), "algo", b.Name)
+		s.Gauge("pod_chain_difficulty",
+			"Difficulty of the latest block of each mining algorithm, from "+
+				"its target bits.",
+			GetDifficultyRatio(b.Bits, n.ChainParams,
+				forks.AlgoVer(b.Name, best.Height)), "algo", b.Name)
+	}
+}
+
+// collectMempoolMetrics adds the size and fees of the mempool.
+func (n *Node) collectMempoolMetrics(s *metrics.Scrape) {
+	var numBytes, fees int64
+	descs := n.TxMemPool.TxDescs()
+	for _, desc := range descs {
+		numBytes += int64(desc.Tx.MsgTx().SerializeSize())
+		fees += desc.Fee
+	}
+	s.Gauge("pod_mempool_transactions",
+		"Number of transactions in the mempool.", float64(len(descs)))
+	s.Gauge("pod_mempool_bytes",
+		"Serialized size of the transactions in the mempool.",
+		float64(numBytes))
+	s.Gauge("pod_mempool_usage_bytes",
+		"Virtual size of the transactions in the mempool, which is "+
+			"limited by the maximum mempool size.",
+		float64(n.TxMemPool.Size()))
+	s.Gauge("pod_mempool_fees_duo",
+		"Total fees paid by the transactions in the mempool, in DUO.",
+		util.Amount(fees).ToDUO())
+	s.Gauge("pod_mempool_min_fee_duo_per_kb",
+		"Fee rate a transaction must pay to be accepted into the mempool, "+
+			"in DUO/kB.",
+		n.TxMemPool.MinFee().ToDUO())
+}
+
+// collectPeerMetrics adds the connected peers with their ban scores and the
+// network traffic.
+func (n *Node) collectPeerMetrics(s *metrics.Scrape) {
+	received, sent := n.NetTotals()
+	s.Counter("pod_net_received_bytes_total",
+		"Bytes received from all peers.", float64(received))
+	s.Counter("pod_net_sent_bytes_total",
+		"Bytes sent to all peers.", float64(sent))
+	// The peer handler only answers queries while the node is running.
+	replyChan := make(chan []*NodePeer)
+	select {
+	case n.Query <- GetPeersMsg{Reply: replyChan}:
+	case <-n.Quit:
+		return
+	}
+	peers := <-replyChan
+	var inbound, outbound int
+	for _, sp := range peers {
+		direction := "outbound"
+		if sp.Inbound() {
+			direction = "inbound"
+			inbound++
+		} else {
+			outbound++
+		}
+		s.Gauge("pod_peer_ban_score",
+			"Ban score of each connected peer, which is banned once it "+
+				"reaches the ban threshold.",
+			float64(sp.BanScore.Int()), "addr", sp.Addr(),
+			"direction", direction)
+	}
+	s.Gauge("pod_peers", "Number of connected peers.", float64(inbound),
+		"direction", "inbound")
+	s.Gauge("pod_peers", "Number of connected peers.", float64(outbound),
+		"direction", "outbound")
+	if *n.Config.BanThreshold > 0 {
+		s.Gauge("pod_peer_ban_threshold",
+			"Ban score at which peers are banned.",
+			float64(*n.Config.BanThreshold))
+	}
+}
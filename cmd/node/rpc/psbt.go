@@ -0,0 +1,347 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/rpc/btcjson"
+	"github.com/p9c/pod/pkg/util"
+	"github.com/p9c/pod/pkg/util/hdkeychain"
+	"github.com/p9c/pod/pkg/util/psbt"
+)
+
+// SigHashNames are the names of the signature hash types as they are given
+// to signrawtransaction and walletprocesspsbt and shown by decodepsbt.
+var SigHashNames = map[txscript.SigHashType]string{
+	txscript.SigHashAll:                                   "ALL",
+	txscript.SigHashNone:                                  "NONE",
+	txscript.SigHashSingle:                                "SINGLE",
+	txscript.SigHashAll | txscript.SigHashAnyOneCanPay:    "ALL|ANYONECANPAY",
+	txscript.SigHashNone | txscript.SigHashAnyOneCanPay:   "NONE|ANYONECANPAY",
+	txscript.SigHashSingle | txscript.SigHashAnyOneCanPay: "SINGLE|ANYONECANPAY",
+}
+
+// HandleCombinePSBT handles combinepsbt commands.
+func HandleCombinePSBT(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	var msg string
+	c, ok := cmd.(*btcjson.CombinePSBTCmd)
+	if !ok {
+		h, err := s.HelpCacher.RPCMethodHelp("combinepsbt")
+		Debug(h, err)
+		if err != nil {
+			msg = err.Error() + "\n\n"
+		}
+		msg += h
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: msg,
+		}
+	}
+	if len(c.PSBTs) == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "no PSBTs to combine",
+		}
+	}
+	packets := make([]*psbt.Packet, len(c.PSBTs))
+	for i, b64 := range c.PSBTs {
+		p, err := DecodePSBT(b64)
+		if err != nil {
+			return nil, err
+		}
+		packets[i] = p
+	}
+	combined, err := psbt.Combine(packets...)
+	if err != nil {
+		Error(err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+	return EncodePSBT(combined)
+}
+
+// HandleDecodePSBT handles decodepsbt commands.
+func HandleDecodePSBT(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	var msg string
+	c, ok := cmd.(*btcjson.DecodePSBTCmd)
+	if !ok {
+		h, err := s.HelpCacher.RPCMethodHelp("decodepsbt")
+		Debug(h, err)
+		if err != nil {
+			msg = err.Error() + "\n\n"
+		}
+		msg += h
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: msg,
+		}
+	}
+	p, err := DecodePSBT(c.PSBT)
+	if err != nil {
+		return nil, err
+	}
+	params := s.Cfg.ChainParams
+	reply := btcjson.DecodePSBTResult{
+		Tx:      txRawDecodeResult(p.UnsignedTx, params),
+		Unknown: psbtUnknownsResult(p.Unknowns),
+		Inputs:  make([]btcjson.DecodePSBTInput, len(p.Inputs)),
+		Outputs: make([]btcjson.DecodePSBTOutput, len(p.Outputs)),
+	}
+	if reply.Unknown == nil {
+		reply.Unknown = map[string]string{}
+	}
+	for i := range p.Inputs {
+		in := &p.Inputs[i]
+		r := &reply.Inputs[i]
+		if in.NonWitnessUtxo != nil {
+			tx := txRawDecodeResult(in.NonWitnessUtxo, params)
+			r.NonWitnessUtxo = &tx
+		}
+		if in.WitnessUtxo != nil {
+			r.WitnessUtxo = &btcjson.PSBTWitnessUtxo{
+				Amount:       util.Amount(in.WitnessUtxo.Value).ToDUO(),
+				ScriptPubKey: ScriptPubKeyResult(in.WitnessUtxo.PkScript, params),
+			}
+		}
+		if len(in.PartialSigs) > 0 {
+			r.PartialSignatures = make(map[string]string, len(in.PartialSigs))
+			for _, ps := range in.PartialSigs {
+				r.PartialSignatures[hex.EncodeToString(ps.PubKey)] =
+					hex.EncodeToString(ps.Signature)
+			}
+		}
+		if in.SighashType != 0 {
+			r.Sighash = SigHashString(in.SighashType)
+		}
+		r.RedeemScript = psbtScriptResult(in.RedeemScript)
+		r.WitnessScript = psbtScriptResult(in.WitnessScript)
+		r.Bip32Derivs = psbtBip32Result(in.Bip32Derivation)
+		if in.FinalScriptSig != nil {
+			disbuf, _ := txscript.DisasmString(in.FinalScriptSig)
+			r.FinalScriptSig = &btcjson.ScriptSig{
+				Asm: disbuf,
+				Hex: hex.EncodeToString(in.FinalScriptSig),
+			}
+		}
+		if in.FinalScriptWitness != nil {
+			witness, err := readWitness(in.FinalScriptWitness)
+			if err != nil {
+				Error(err)
+				return nil, &btcjson.RPCError{
+					Code:    btcjson.ErrRPCDeserialization,
+					Message: "PSBT decode failed: " + err.Error(),
+				}
+			}
+			r.FinalScriptWitness = make([]string, len(witness))
+			for j, item := range witness {
+				r.FinalScriptWitness[j] = hex.EncodeToString(item)
+			}
+		}
+		r.Unknown = psbtUnknownsResult(in.Unknowns)
+	}
+	for i := range p.Outputs {
+		out := &p.Outputs[i]
+		reply.Outputs[i] = btcjson.DecodePSBTOutput{
+			RedeemScript:  psbtScriptResult(out.RedeemScript),
+			WitnessScript: psbtScriptResult(out.WitnessScript),
+			Bip32Derivs:   psbtBip32Result(out.Bip32Derivation),
+			Unknown:       psbtUnknownsResult(out.Unknowns),
+		}
+	}
+	// The fee can only be known when the previous output of every input is.
+	if fee, err := p.GetTxFee(); err == nil {
+		duo := fee.ToDUO()
+		reply.Fee = &duo
+	}
+	return reply, nil
+}
+
+// HandleFinalizePSBT handles finalizepsbt commands.
+func HandleFinalizePSBT(
+	s *Server,
+	cmd interface{},
+	closeChan <-chan struct{},
+) (interface{}, error) {
+	var msg string
+	c, ok := cmd.(*btcjson.FinalizePSBTCmd)
+	if !ok {
+		h, err := s.HelpCacher.RPCMethodHelp("finalizepsbt")
+		Debug(h, err)
+		if err != nil {
+			msg = err.Error() + "\n\n"
+		}
+		msg += h
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: msg,
+		}
+	}
+	p, err := DecodePSBT(c.PSBT)
+	if err != nil {
+		return nil, err
+	}
+	// Inputs that can't be finalized yet, for lack of signatures, are left
+	// as they are for the other signers to complete.
+	for i := range p.Inputs {
+		if _, err := psbt.MaybeFinalize(p, i); err != nil {
+			Debugf("input %d of PSBT not finalized: %v", i, err)
+		}
+	}
+	reply := btcjson.FinalizePSBTResult{Complete: p.IsComplete()}
+	if reply.Complete && (c.Extract == nil || *c.Extract) {
+		tx, err := psbt.Extract(p)
+		if err != nil {
+			Error(err)
+			return nil, InternalRPCError(err.Error(),
+				"Failed to extract transaction")
+		}
+		reply.Hex, err = MessageToHex(tx)
+		if err != nil {
+			return nil, err
+		}
+		return reply, nil
+	}
+	reply.PSBT, err = EncodePSBT(p)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// DecodePSBT parses a base64 encoded PSBT, returning an RPC deserialization
+// error if it is not valid.
+func DecodePSBT(b64 string) (*psbt.Packet, error) {
+	p, err := psbt.NewFromRawBytes(strings.NewReader(b64), true)
+	if err != nil {
+		Error(err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDeserialization,
+			Message: "PSBT decode failed: " + err.Error(),
+		}
+	}
+	return p, nil
+}
+
+// EncodePSBT returns the base64 encoding of a PSBT.
+func EncodePSBT(p *psbt.Packet) (string, error) {
+	b64, err := p.B64Encode()
+	if err != nil {
+		Error(err)
+		return "", InternalRPCError(err.Error(), "Failed to encode PSBT")
+	}
+	return b64, nil
+}
+
+// SigHashString returns the name of a signature hash type, or its value in
+// hex if it has none.
+func SigHashString(hashType txscript.SigHashType) string {
+	if name, ok := SigHashNames[hashType]; ok {
+		return name
+	}
+	return fmt.Sprintf("%#x", uint32(hashType))
+}
+
+// txRawDecodeResult returns the decoderawtransaction description of a
+// transaction.
+func txRawDecodeResult(mtx *wire.MsgTx,
+	params *netparams.Params) btcjson.TxRawDecodeResult {
+	return btcjson.TxRawDecodeResult{
+		Txid:     mtx.TxHash().String(),
+		Version:  mtx.Version,
+		Locktime: mtx.LockTime,
+		Vin:      CreateVinList(mtx),
+		Vout:     CreateVoutList(mtx, params, nil),
+	}
+}
+
+// psbtScriptResult returns the description of a redeem or witness script of a
+// PSBT, or nil if there is none.
+func psbtScriptResult(script []byte) *btcjson.PSBTScript {
+	if script == nil {
+		return nil
+	}
+	disbuf, _ := txscript.DisasmString(script)
+	return &btcjson.PSBTScript{
+		Asm:  disbuf,
+		Hex:  hex.EncodeToString(script),
+		Type: txscript.GetScriptClass(script).String(),
+	}
+}
+
+// psbtBip32Result returns the description of the BIP32 key derivations of a
+// PSBT input or output, with the paths written as m/44'/0'/0'.
+func psbtBip32Result(derivations []*psbt.Bip32Derivation) []btcjson.PSBTBip32Deriv {
+	if len(derivations) == 0 {
+		return nil
+	}
+	result := make([]btcjson.PSBTBip32Deriv, len(derivations))
+	for i, d := range derivations {
+		var fingerprint [4]byte
+		binary.LittleEndian.PutUint32(fingerprint[:], d.MasterKeyFingerprint)
+		path := "m"
+		for _, index := range d.Bip32Path {
+			if index >= hdkeychain.HardenedKeyStart {
+				path += fmt.Sprintf("/%d'", index-hdkeychain.HardenedKeyStart)
+			} else {
+				path += fmt.Sprintf("/%d", index)
+			}
+		}
+		result[i] = btcjson.PSBTBip32Deriv{
+			PubKey:            hex.EncodeToString(d.PubKey),
+			MasterFingerprint: hex.EncodeToString(fingerprint[:]),
+			Path:              path,
+		}
+	}
+	return result
+}
+
+// psbtUnknownsResult returns the unknown key-value pairs of a PSBT as hex, or
+// nil if there are none.
+func psbtUnknownsResult(unknowns []*psbt.Unknown) map[string]string {
+	if len(unknowns) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(unknowns))
+	for _, u := range unknowns {
+		result[hex.EncodeToString(u.Key)] = hex.EncodeToString(u.Value)
+	}
+	return result
+}
+
+// readWitness parses a witness stack serialized as in a PSBT final script
+// witness.
+func readWitness(serialized []byte) (wire.TxWitness, error) {
+	r := bytes.NewReader(serialized)
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(len(serialized)) {
+		return nil, fmt.Errorf("witness item count %d is too large", count)
+	}
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		witness[i], err = wire.ReadVarBytes(r, 0, txscript.MaxScriptSize,
+			"witness")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return witness, nil
+}
@@ -0,0 +1,595 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/rpc/btcjson"
+	"github.com/p9c/pod/pkg/util"
+)
+
+const (
+	// RESTPath is the HTTP path the REST interface is served under.
+	RESTPath = "/rest/"
+	// maxRESTHeaders is the most block headers returned by a headers request.
+	maxRESTHeaders = 2000
+	// maxRESTAddressTxs is the most transactions returned by an address
+	// request.
+	maxRESTAddressTxs = 1000
+	// defaultRESTAddressTxs is the number of transactions returned by an
+	// address request that does not give a count.
+	defaultRESTAddressTxs = 100
+	// maxRESTOutpoints is the most outpoints that can be queried by a getutxos
+	// request.
+	maxRESTOutpoints = 15
+	// mempoolHeight is the height reported by getutxos for outputs of
+	// transactions in the mempool.
+	mempoolHeight = 0x7fffffff
+)
+
+// restFormat is the format of the reply to a REST request, given by the
+// extension of the last element of its path.
+type restFormat int
+
+const (
+	restJSON restFormat = iota
+	restBinary
+	restHex
+)
+
+// restFormats maps the path extensions to the reply formats.
+var restFormats = map[string]restFormat{
+	".json": restJSON,
+	".bin":  restBinary,
+	".hex":  restHex,
+}
+
+// restHandler replies to a REST request with the elements of its path after
+// the endpoint name. A JSON reply is returned as a value to marshal, while a
+// binary or hex reply is returned as the raw bytes.
+type restHandler func(s *Server, args []string, r *http.Request,
+	format restFormat) (interface{}, []byte, error)
+
+// restHandlers maps the REST endpoints to their handlers.
+var restHandlers = map[string]restHandler{
+	"address":   restAddress,
+	"block":     restBlock,
+	"chaininfo": restChainInfo,
+	"getutxos":  restGetUTXOs,
+	"headers":   restHeaders,
+	"tx":        restTx,
+}
+
+// restError is an error with the HTTP status it is replied to with.
+type restError struct {
+	status int
+	msg    string
+}
+
+func (e *restError) Error() string {
+	return e.msg
+}
+
+// restErrorf returns a restError with a formatted message.
+func restErrorf(status int, format string, a ...interface{}) *restError {
+	return &restError{status: status, msg: fmt.Sprintf(format, a...)}
+}
+
+// HandleREST replies to the requests of the read-only REST interface. No
+// authentication is required, so only public chain data is served.
+func (s *Server) HandleREST(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Connection", "close")
+	r.Close = true
+	// Limit the number of connections to max allowed.
+	if s.LimitConnections(w, r.RemoteAddr) {
+		return
+	}
+	s.IncrementClients()
+	defer s.DecrementClients()
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "only GET requests are supported",
+			http.StatusMethodNotAllowed)
+		return
+	}
+	args := strings.Split(strings.TrimPrefix(r.URL.Path, RESTPath), "/")
+	last := args[len(args)-1]
+	dot := strings.LastIndexByte(last, '.')
+	if dot < 0 {
+		http.Error(w, "the path must end in .json, .bin or .hex",
+			http.StatusNotFound)
+		return
+	}
+	format, ok := restFormats[last[dot:]]
+	if !ok {
+		http.Error(w, "unknown format "+last[dot:]+
+			", the path must end in .json, .bin or .hex",
+			http.StatusNotFound)
+		return
+	}
+	args[len(args)-1] = last[:dot]
+	handler, ok := restHandlers[args[0]]
+	if !ok {
+		http.Error(w, "unknown REST endpoint "+args[0], http.StatusNotFound)
+		return
+	}
+	result, raw, err := handler(s, args[1:], r, format)
+	if err != nil {
+		status := restStatus(err)
+		if status == http.StatusInternalServerError {
+			Error("REST request", r.URL.Path, "failed:", err)
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	var reply []byte
+	switch format {
+	case restJSON:
+		if reply, err = json.Marshal(result); err != nil {
+			Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		reply = append(reply, '\n')
+	case restBinary:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		reply = raw
+	case restHex:
+		w.Header().Set("Content-Type", "text/plain")
+		reply = []byte(hex.EncodeToString(raw) + "\n")
+	}
+	if _, err = w.Write(reply); err != nil {
+		Debug("failed to write REST reply:", err)
+	}
+}
+
+// restStatus returns the HTTP status an error is replied to with. Errors from
+// the RPC handlers are mapped from their code, the parameters of the request
+// having already been checked.
+func restStatus(err error) int {
+	switch e := err.(type) {
+	case *restError:
+		return e.status
+	case *btcjson.RPCError:
+		switch e.Code {
+		case btcjson.ErrRPCInvalidParameter, btcjson.ErrRPCDeserialization:
+			return http.StatusBadRequest
+		case btcjson.ErrRPCBlockNotFound, btcjson.ErrRPCMisc:
+			// the data is not known or no longer stored, such as the blocks
+			// below the prune height
+			return http.StatusNotFound
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// restHash parses a hash given in a REST path.
+func restHash(str string) (*chainhash.Hash, error) {
+	hash, err := chainhash.NewHashFromStr(str)
+	if err != nil || len(str) != chainhash.MaxHashStringSize {
+		return nil, restErrorf(http.StatusBadRequest, "invalid hash %q", str)
+	}
+	return hash, nil
+}
+
+// restFormatNotSupported is the error of a request for an endpoint in a format
+// it does not support.
+func restFormatNotSupported(endpoint string) error {
+	return restErrorf(http.StatusNotFound,
+		"the %s endpoint is only available in JSON", endpoint)
+}
+
+// restBlock replies to block/<hash> with the block and its transactions, or to
+// block/notxdetails/<hash> with the block and the hashes of its transactions.
+func restBlock(s *Server, args []string, r *http.Request,
+	format restFormat) (interface{}, []byte, error) {
+	txDetails := true
+	if len(args) == 2 && args[0] == "notxdetails" {
+		txDetails = false
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return nil, nil, restErrorf(http.StatusBadRequest,
+			"usage: block/[notxdetails/]<hash>")
+	}
+	hash, err := restHash(args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	verbose := format == restJSON
+	result, err := HandleGetBlock(s, &btcjson.GetBlockCmd{
+		Hash:      hash.String(),
+		Verbose:   &verbose,
+		VerboseTx: &txDetails,
+	}, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if verbose {
+		return result, nil, nil
+	}
+	raw, err := hex.DecodeString(result.(string))
+	return nil, raw, err
+}
+
+// restTx replies to tx/<txid> with a transaction in the mempool or, if the
+// transaction index is enabled, in the chain.
+func restTx(s *Server, args []string, r *http.Request,
+	format restFormat) (interface{}, []byte, error) {
+	if len(args) != 1 {
+		return nil, nil, restErrorf(http.StatusBadRequest, "usage: tx/<txid>")
+	}
+	txHash, err := restHash(args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	var verbose int
+	if format == restJSON {
+		verbose = 1
+	}
+	result, err := HandleGetRawTransaction(s, &btcjson.GetRawTransactionCmd{
+		Txid:    txHash.String(),
+		Verbose: &verbose,
+	}, nil)
+	if err != nil {
+		if e, ok := err.(*btcjson.RPCError); ok &&
+			e.Code == btcjson.ErrRPCNoTxInfo {
+			return nil, nil, restErrorf(http.StatusNotFound, "%s", e.Message)
+		}
+		return nil, nil, err
+	}
+	if verbose != 0 {
+		return result, nil, nil
+	}
+	raw, err := hex.DecodeString(result.(string))
+	return nil, raw, err
+}
+
+// restAddress replies to address/<address>/txs with the transactions that pay
+// to or spend from an address, paged with the skip and count query parameters
+// and returned newest first if reverse is set. It requires the address index.
+func restAddress(s *Server, args []string, r *http.Request,
+	format restFormat) (interface{}, []byte, error) {
+	if len(args) != 2 || args[1] != "txs" {
+		return nil, nil, restErrorf(http.StatusBadRequest,
+			"usage: address/<address>/txs")
+	}
+	if s.Cfg.AddrIndex == nil {
+		return nil, nil, restErrorf(http.StatusServiceUnavailable,
+			"the address index must be enabled (--addrindex)")
+	}
+	if _, err := util.DecodeAddress(args[0], s.Cfg.ChainParams); err != nil {
+		return nil, nil, restErrorf(http.StatusBadRequest,
+			"invalid address %q: %v", args[0], err)
+	}
+	query := r.URL.Query()
+	skip, err := restIntParam(query.Get("skip"), 0, 0, -1)
+	if err != nil {
+		return nil, nil, err
+	}
+	count, err := restIntParam(query.Get("count"), defaultRESTAddressTxs, 1,
+		maxRESTAddressTxs)
+	if err != nil {
+		return nil, nil, err
+	}
+	var reverse bool
+	if str := query.Get("reverse"); str != "" {
+		if reverse, err = strconv.ParseBool(str); err != nil {
+			return nil, nil, restErrorf(http.StatusBadRequest,
+				"invalid reverse %q", str)
+		}
+	}
+	var verbose int
+	if format == restJSON {
+		verbose = 1
+	}
+	result, err := HandleSearchRawTransactions(s,
+		&btcjson.SearchRawTransactionsCmd{
+			Address: args[0],
+			Verbose: &verbose,
+			Skip:    &skip,
+			Count:   &count,
+			Reverse: &reverse,
+		}, nil)
+	if err != nil {
+		// an address that was never used, or paged past its last
+		// transaction, has an empty page of transactions
+		if e, ok := err.(*btcjson.RPCError); !ok ||
+			e.Code != btcjson.ErrRPCNoTxInfo {
+			return nil, nil, err
+		}
+		result = nil
+	}
+	if verbose != 0 {
+		if result == nil {
+			result = []btcjson.SearchRawTransactionsResult{}
+		}
+		return result, nil, nil
+	}
+	hexTxns, _ := result.([]string)
+	var buf bytes.Buffer
+	for _, hexTx := range hexTxns {
+		raw, err := hex.DecodeString(hexTx)
+		if err != nil {
+			return nil, nil, err
+		}
+		buf.Write(raw)
+	}
+	return nil, buf.Bytes(), nil
+}
+
+// restIntParam parses an integer query parameter, returning def if it is not
+// given. max is ignored if it is negative.
+func restIntParam(str string, def, min, max int) (int, error) {
+	if str == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(str)
+	if err != nil || n < min || (max >= 0 && n > max) {
+		if max < 0 {
+			return 0, restErrorf(http.StatusBadRequest,
+				"invalid number %q, it must be at least %d", str, min)
+		}
+		return 0, restErrorf(http.StatusBadRequest,
+			"invalid number %q, it must be from %d to %d", str, min, max)
+	}
+	return n, nil
+}
+
+// restHeaders replies to headers/<count>/<hash>, or headers/<hash> with a
+// count query parameter, with up to count headers of the main chain starting
+// from the block with the hash.
+func restHeaders(s *Server, args []string, r *http.Request,
+	format restFormat) (interface{}, []byte, error) {
+	var countStr, hashStr string
+	switch len(args) {
+	case 1:
+		countStr, hashStr = r.URL.Query().Get("count"), args[0]
+		if countStr == "" {
+			countStr = "1"
+		}
+	case 2:
+		countStr, hashStr = args[0], args[1]
+	default:
+		return nil, nil, restErrorf(http.StatusBadRequest,
+			"usage: headers/<count>/<hash>")
+	}
+	count, err := restIntParam(countStr, 1, 1, maxRESTHeaders)
+	if err != nil {
+		return nil, nil, err
+	}
+	hash, err := restHash(hashStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	chain := s.Cfg.Chain
+	height, err := chain.BlockHeightByHash(hash)
+	if err != nil {
+		return nil, nil, restErrorf(http.StatusNotFound,
+			"block %s not found in the main chain", hash)
+	}
+	best := chain.BestSnapshot()
+	results := make([]interface{}, 0, count)
+	var buf bytes.Buffer
+	for end := height + int32(count); height < end &&
+		height <= best.Height; height++ {
+		if hash, err = chain.BlockHashByHeight(height); err != nil {
+			// the chain was reorganized since the request was started
+			break
+		}
+		if format == restJSON {
+			verbose := true
+			result, err := HandleGetBlockHeader(s,
+				&btcjson.GetBlockHeaderCmd{
+					Hash:    hash.String(),
+					Verbose: &verbose,
+				}, nil)
+			if err != nil {
+				return nil, nil, err
+			}
+			results = append(results, result)
+			continue
+		}
+		header, err := chain.HeaderByHash(hash)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = header.Serialize(&buf); err != nil {
+			return nil, nil, err
+		}
+	}
+	return results, buf.Bytes(), nil
+}
+
+// restAlgoInfo is the latest block of a mining algorithm in a chaininfo reply.
+type restAlgoInfo struct {
+	Name       string  `json:"name"`
+	Height     int32   `json:"height"`
+	Bits       string  `json:"bits"`
+	Difficulty float64 `json:"difficulty"`
+}
+
+// restChainInfoResult is the reply to a chaininfo request.
+type restChainInfoResult struct {
+	*btcjson.GetBlockChainInfoResult
+	TxIndex   bool           `json:"txindex"`
+	AddrIndex bool           `json:"addrindex"`
+	Algos     []restAlgoInfo `json:"algos"`
+}
+
+// restChainInfo replies to chaininfo with the state of the chain, the indexes
+// that are enabled and the latest block of each mining algorithm.
+func restChainInfo(s *Server, args []string, r *http.Request,
+	format restFormat) (interface{}, []byte, error) {
+	if format != restJSON {
+		return nil, nil, restFormatNotSupported("chaininfo")
+	}
+	if len(args) != 0 {
+		return nil, nil, restErrorf(http.StatusBadRequest, "usage: chaininfo")
+	}
+	result, err := HandleGetBlockChainInfo(s, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	params := s.Cfg.ChainParams
+	height := s.Cfg.Chain.BestSnapshot().Height
+	reply := restChainInfoResult{
+		GetBlockChainInfoResult: result.(*btcjson.GetBlockChainInfoResult),
+		TxIndex:                 s.Cfg.TxIndex != nil,
+		AddrIndex:               s.Cfg.AddrIndex != nil,
+		Algos:                   []restAlgoInfo{},
+	}
+	for _, b := range LatestAlgoBlocks(s.Cfg.Chain, params,
+		maxAlgoSearchDepth) {
+		reply.Algos = append(reply.Algos, restAlgoInfo{
+			Name:   b.Name,
+			Height: b.Height,
+			Bits:   strconv.FormatInt(int64(b.Bits), 16),
+			Difficulty: GetDifficultyRatio(b.Bits, params,
+				params.Forks.AlgoVer(b.Name, height)),
+		})
+	}
+	return reply, nil, nil
+}
+
+// restUTXO is an unspent output in a getutxos reply.
+type restUTXO struct {
+	Height       int32                      `json:"height"`
+	Value        float64                    `json:"value"`
+	ScriptPubKey btcjson.ScriptPubKeyResult `json:"scriptPubKey"`
+}
+
+// restUTXOsResult is the reply to a getutxos request. The bitmap has a 1 for
+// each of the outpoints that is unspent, in the order they were requested,
+// and the unspent outputs are listed in the same order.
+type restUTXOsResult struct {
+	ChainHeight  int32      `json:"chainHeight"`
+	ChainTipHash string     `json:"chaintipHash"`
+	Bitmap       string     `json:"bitmap"`
+	UTXOs        []restUTXO `json:"utxos"`
+}
+
+// restGetUTXOs replies to getutxos[/checkmempool]/<txid>-<n>/... with which
+// of the outpoints are unspent, and their outputs. With checkmempool the
+// outputs of transactions in the mempool are included, and the outputs spent
+// by them are not.
+func restGetUTXOs(s *Server, args []string, r *http.Request,
+	format restFormat) (interface{}, []byte, error) {
+	checkMempool := len(args) > 0 && args[0] == "checkmempool"
+	if checkMempool {
+		args = args[1:]
+	}
+	if len(args) == 0 || len(args) > maxRESTOutpoints {
+		return nil, nil, restErrorf(http.StatusBadRequest,
+			"from 1 to %d outpoints must be given as <txid>-<n>",
+			maxRESTOutpoints)
+	}
+	outpoints := make([]wire.OutPoint, len(args))
+	for i, arg := range args {
+		dash := strings.IndexByte(arg, '-')
+		if dash < 0 {
+			return nil, nil, restErrorf(http.StatusBadRequest,
+				"invalid outpoint %q, it must be <txid>-<n>", arg)
+		}
+		hash, err := restHash(arg[:dash])
+		if err != nil {
+			return nil, nil, err
+		}
+		index, err := strconv.ParseUint(arg[dash+1:], 10, 32)
+		if err != nil {
+			return nil, nil, restErrorf(http.StatusBadRequest,
+				"invalid outpoint index %q", arg[dash+1:])
+		}
+		outpoints[i] = wire.OutPoint{Hash: *hash, Index: uint32(index)}
+	}
+	mp := s.Cfg.TxMemPool
+	best := s.Cfg.Chain.BestSnapshot()
+	bitmap := make([]byte, (len(outpoints)+7)/8)
+	bitmapStr := make([]byte, len(outpoints))
+	utxos := make([]restUTXO, 0, len(outpoints))
+	var buf bytes.Buffer
+	for i, op := range outpoints {
+		bitmapStr[i] = '0'
+		var height int32
+		var out *wire.TxOut
+		var tx *util.Tx
+		if checkMempool {
+			tx, _ = mp.FetchTransaction(&op.Hash)
+		}
+		if tx != nil {
+			if txOuts := tx.MsgTx().TxOut; op.Index < uint32(len(txOuts)) {
+				height, out = mempoolHeight, txOuts[op.Index]
+			}
+		} else {
+			entry, err := s.Cfg.Chain.FetchUtxoEntry(op)
+			if err != nil {
+				return nil, nil, err
+			}
+			if entry != nil && !entry.IsSpent() {
+				height = entry.BlockHeight()
+				out = wire.NewTxOut(entry.Amount(), entry.PkScript())
+			}
+		}
+		if out == nil || (checkMempool && mp.CheckSpend(op) != nil) {
+			continue
+		}
+		bitmap[i/8] |= 1 << uint(i%8)
+		bitmapStr[i] = '1'
+		if format == restJSON {
+			utxos = append(utxos, restUTXO{
+				Height: height,
+				Value:  util.Amount(out.Value).ToDUO(),
+				ScriptPubKey: ScriptPubKeyResult(out.PkScript,
+					s.Cfg.ChainParams),
+			})
+			continue
+		}
+		// the outputs are serialized after the header, once their count is
+		// known
+		if err := writeRESTUTXO(&buf, height, out); err != nil {
+			return nil, nil, err
+		}
+	}
+	if format == restJSON {
+		return restUTXOsResult{
+			ChainHeight:  best.Height,
+			ChainTipHash: best.Hash.String(),
+			Bitmap:       string(bitmapStr),
+			UTXOs:        utxos,
+		}, nil, nil
+	}
+	var reply bytes.Buffer
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(best.Height))
+	reply.Write(n[:])
+	reply.Write(best.Hash[:])
+	if err := wire.WriteVarBytes(&reply, 0, bitmap); err != nil {
+		return nil, nil, err
+	}
+	if err := wire.WriteVarInt(&reply, 0,
+		uint64(strings.Count(string(bitmapStr), "1"))); err != nil {
+		return nil, nil, err
+	}
+	reply.Write(buf.Bytes())
+	return nil, reply.Bytes(), nil
+}
+
+// writeRESTUTXO serializes an unspent output of a binary getutxos reply as its
+// height, value and public key script.
+func writeRESTUTXO(buf *bytes.Buffer, height int32, out *wire.TxOut) error {
+	var n [8]byte
+	binary.LittleEndian.PutUint32(n[:4], uint32(height))
+	buf.Write(n[:4])
+	binary.LittleEndian.PutUint64(n[:], uint64(out.Value))
+	buf.Write(n[:])
+	return wire.WriteVarBytes(buf, 0, out.PkScript)
+}
@@ -0,0 +1,340 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/chain/wire"
+	database "github.com/p9c/pod/pkg/db"
+	_ "github.com/p9c/pod/pkg/db/ffldb"
+	"github.com/p9c/pod/pkg/pod"
+	"github.com/p9c/pod/pkg/rpc/btcjson"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// testRESTServer returns a server for REST requests with a regression test
+// chain in a temporary directory, and a function that removes it.
+func testRESTServer(t *testing.T) (*Server, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "resttest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := &netparams.RegressionTestParams
+	db, err := database.Create("ffldb", filepath.Join(dir, "blocks"),
+		params.Net)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("unable to create block database: %v", err)
+	}
+	teardown := func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: params,
+		TimeSource:  blockchain.NewMedianTime(),
+		SigCache:    txscript.NewSigCache(1000),
+	})
+	if err != nil {
+		teardown()
+		t.Fatalf("unable to create chain: %v", err)
+	}
+	config, _ := pod.EmptyConfig()
+	*config.RPCMaxClients = 10
+	s := &Server{
+		Config: config,
+		Cfg: ServerConfig{
+			Chain:       chain,
+			ChainParams: params,
+		},
+	}
+	return s, teardown
+}
+
+// mineTestBlock adds a block to the chain of the server with a coinbase paying
+// the subsidy to an anyone can spend output, and returns it.
+func mineTestBlock(t *testing.T, s *Server) *util.Block {
+	t.Helper()
+	chain, params := s.Cfg.Chain, s.Cfg.ChainParams
+	forks := params.Forks
+	best := chain.BestSnapshot()
+	tip, err := chain.HeaderByHash(&best.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	height := best.Height + 1
+	version := forks.AlgoSlices(forks.Current(height))[0].Version
+	algo := forks.AlgoName(version, height)
+	timestamp := tip.Timestamp.Add(time.Second)
+	bits, err := chain.CalcNextRequiredDifficulty(0, timestamp, algo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coinbaseScript, err := txscript.NewScriptBuilder().
+		AddInt64(int64(height)).AddInt64(0).Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		SignatureScript: coinbaseScript,
+		Sequence:        wire.MaxTxInSequenceNum,
+	})
+	coinbase.AddTxOut(&wire.TxOut{
+		Value:    blockchain.CalcBlockSubsidy(height, params, version),
+		PkScript: []byte{txscript.OP_TRUE},
+	})
+	merkles := blockchain.BuildMerkleTreeStore(
+		[]*util.Tx{util.NewTx(coinbase)}, false)
+	block := util.NewBlock(&wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:    version,
+			PrevBlock:  best.Hash,
+			MerkleRoot: *merkles[len(merkles)-1],
+			Timestamp:  timestamp,
+			Bits:       bits,
+		},
+		Transactions: []*wire.MsgTx{coinbase},
+	})
+	block.SetHeight(height)
+	powLimit := forks.MinDiff(algo, height)
+	for blockchain.CheckProofOfWork(block, powLimit, forks, height) != nil {
+		block.MsgBlock().Header.Nonce++
+		// the hash of the block is cached, so it is made anew
+		block = util.NewBlock(block.MsgBlock())
+		block.SetHeight(height)
+	}
+	_, isOrphan, err := chain.ProcessBlock(0, block, blockchain.BFNone, height)
+	if err != nil || isOrphan {
+		t.Fatalf("unable to process block: %v, orphan %v", err, isOrphan)
+	}
+	return block
+}
+
+// restGet replies to a GET request for a REST path.
+func restGet(s *Server, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.HandleREST(w, httptest.NewRequest(http.MethodGet, RESTPath+path, nil))
+	return w
+}
+
+// TestRESTPath ensures the format of REST replies is chosen by the extension
+// of the path, and that requests with unknown paths or methods are refused.
+func TestRESTPath(t *testing.T) {
+	s, teardown := testRESTServer(t)
+	defer teardown()
+	genesis := s.Cfg.Chain.BestSnapshot().Hash
+	genesisHeader, err := s.Cfg.Chain.HeaderByHash(&genesis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header bytes.Buffer
+	if err = genesisHeader.Serialize(&header); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		path        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"json", "headers/1/" + genesis.String() + ".json", http.StatusOK,
+			"application/json", `"hash":"` + genesis.String() + `"`},
+		{"bin", "headers/1/" + genesis.String() + ".bin", http.StatusOK,
+			"application/octet-stream", header.String()},
+		{"hex", "headers/1/" + genesis.String() + ".hex", http.StatusOK,
+			"text/plain", hex.EncodeToString(header.Bytes()) + "\n"},
+		{"no extension", "headers/1/" + genesis.String(),
+			http.StatusNotFound, "", "must end in .json, .bin or .hex"},
+		{"unknown extension", "headers/1/" + genesis.String() + ".xml",
+			http.StatusNotFound, "", "unknown format .xml"},
+		{"unknown endpoint", "blocks/" + genesis.String() + ".json",
+			http.StatusNotFound, "", "unknown REST endpoint blocks"},
+		{"json only", "chaininfo.bin", http.StatusNotFound, "",
+			"only available in JSON"},
+		{"invalid hash", "headers/1/" + genesis.String()[1:] + ".json",
+			http.StatusBadRequest, "", "invalid hash"},
+		{"unknown block", "headers/1/" + strings.Repeat("0", 64) + ".json",
+			http.StatusNotFound, "", "not found in the main chain"},
+	}
+	for _, test := range tests {
+		w := restGet(s, test.path)
+		if w.Code != test.status {
+			t.Errorf("%s: got status %d, want %d", test.name, w.Code,
+				test.status)
+			continue
+		}
+		if got := w.Header().Get("Content-Type"); test.contentType != "" &&
+			got != test.contentType {
+			t.Errorf("%s: got content type %q, want %q", test.name, got,
+				test.contentType)
+		}
+		if test.status == http.StatusOK && test.contentType != "application/json" {
+			if got := w.Body.String(); got != test.body {
+				t.Errorf("%s: got body %x, want %x", test.name, got, test.body)
+			}
+		} else if !strings.Contains(w.Body.String(), test.body) {
+			t.Errorf("%s: got body %q, want one containing %q", test.name,
+				w.Body.String(), test.body)
+		}
+	}
+	// Only GET requests are served.
+	w := httptest.NewRecorder()
+	s.HandleREST(w, httptest.NewRequest(http.MethodPost,
+		RESTPath+"chaininfo.json", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("post: got status %d, want %d", w.Code,
+			http.StatusMethodNotAllowed)
+	}
+	if got := w.Header().Get("Allow"); got != http.MethodGet {
+		t.Errorf("post: got allow %q, want %q", got, http.MethodGet)
+	}
+}
+
+// TestRESTHeadersCount ensures the count of a headers request is refused
+// outside of its bounds, and that no headers past the tip are returned.
+func TestRESTHeadersCount(t *testing.T) {
+	s, teardown := testRESTServer(t)
+	defer teardown()
+	genesis := s.Cfg.Chain.BestSnapshot().Hash.String()
+	mineTestBlock(t, s)
+	mineTestBlock(t, s)
+	tests := []struct {
+		path   string
+		status int
+		count  int
+	}{
+		{"headers/1/" + genesis, http.StatusOK, 1},
+		{"headers/2/" + genesis, http.StatusOK, 2},
+		{"headers/2000/" + genesis, http.StatusOK, 3},
+		{"headers/" + genesis, http.StatusOK, 1},
+		{"headers/" + genesis + "?count=2", http.StatusOK, 2},
+		{"headers/0/" + genesis, http.StatusBadRequest, 0},
+		{"headers/-1/" + genesis, http.StatusBadRequest, 0},
+		{"headers/2001/" + genesis, http.StatusBadRequest, 0},
+		{"headers/two/" + genesis, http.StatusBadRequest, 0},
+		{"headers/" + genesis + "?count=0", http.StatusBadRequest, 0},
+		{"headers/" + genesis + "?count=2001", http.StatusBadRequest, 0},
+		{"headers/1/1/" + genesis, http.StatusBadRequest, 0},
+	}
+	for _, test := range tests {
+		// the query follows the extension
+		path := test.path + ".bin"
+		if i := strings.IndexByte(test.path, '?'); i >= 0 {
+			path = test.path[:i] + ".bin" + test.path[i:]
+		}
+		w := restGet(s, path)
+		if w.Code != test.status {
+			t.Errorf("%s: got status %d, want %d", test.path, w.Code,
+				test.status)
+			continue
+		}
+		if test.status != http.StatusOK {
+			continue
+		}
+		if got := w.Body.Len() / wire.MaxBlockHeaderPayload; got != test.count {
+			t.Errorf("%s: got %d headers, want %d", test.path, got,
+				test.count)
+		}
+		var results []btcjson.GetBlockHeaderVerboseResult
+		w = restGet(s, strings.Replace(path, ".bin", ".json", 1))
+		if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+			t.Errorf("%s: unable to decode JSON reply: %v", test.path, err)
+			continue
+		}
+		if len(results) != test.count {
+			t.Errorf("%s: got %d JSON headers, want %d", test.path,
+				len(results), test.count)
+		}
+		for i, result := range results {
+			if result.Height != int32(i) {
+				t.Errorf("%s: got header %d at height %d", test.path, i,
+					result.Height)
+			}
+		}
+	}
+}
+
+// TestRESTGetUTXOs ensures the binary reply to a getutxos request is laid out
+// as the height and hash of the tip, the bitmap of the unspent outpoints, and
+// the count and serialized outputs of those outpoints.
+func TestRESTGetUTXOs(t *testing.T) {
+	s, teardown := testRESTServer(t)
+	defer teardown()
+	block := mineTestBlock(t, s)
+	coinbase := block.Transactions()[0]
+	out := coinbase.MsgTx().TxOut[0]
+	unspent := coinbase.Hash().String() + "-0"
+	missing := coinbase.Hash().String() + "-1"
+	unknown := strings.Repeat("0", 64) + "-0"
+	// 9 outpoints take a second byte of the bitmap
+	outpoints := []string{missing, unspent, unknown, missing, missing,
+		missing, missing, missing, unspent}
+	w := restGet(s, "getutxos/"+strings.Join(outpoints, "/")+".bin")
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK,
+			w.Body.String())
+	}
+	var want bytes.Buffer
+	var n [8]byte
+	binary.LittleEndian.PutUint32(n[:4], uint32(block.Height()))
+	want.Write(n[:4])
+	want.Write(block.Hash()[:])
+	want.Write([]byte{0x02, 0x02, 0x01, 0x02})
+	for i := 0; i < 2; i++ {
+		binary.LittleEndian.PutUint32(n[:4], uint32(block.Height()))
+		want.Write(n[:4])
+		binary.LittleEndian.PutUint64(n[:], uint64(out.Value))
+		want.Write(n[:])
+		want.Write([]byte{0x01, txscript.OP_TRUE})
+	}
+	if !bytes.Equal(w.Body.Bytes(), want.Bytes()) {
+		t.Errorf("got %x, want %x", w.Body.Bytes(), want.Bytes())
+	}
+	// The hex reply is the binary one in hex.
+	w = restGet(s, "getutxos/"+strings.Join(outpoints, "/")+".hex")
+	if got := w.Body.String(); got != hex.EncodeToString(want.Bytes())+"\n" {
+		t.Errorf("hex: got %s, want %x", got, want.Bytes())
+	}
+	var result restUTXOsResult
+	w = restGet(s, "getutxos/"+strings.Join(outpoints, "/")+".json")
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("unable to decode JSON reply: %v", err)
+	}
+	if result.Bitmap != "010000001" || len(result.UTXOs) != 2 {
+		t.Errorf("json: got bitmap %s with %d outputs, want 010000001 with 2",
+			result.Bitmap, len(result.UTXOs))
+	}
+	refused := []string{
+		"getutxos.bin",
+		"getutxos/" + strings.Repeat(unspent+"/", maxRESTOutpoints) +
+			unspent + ".bin",
+		"getutxos/" + coinbase.Hash().String() + ".bin",
+		"getutxos/" + coinbase.Hash().String() + "-x.bin",
+	}
+	for _, path := range refused {
+		if w := restGet(s, path); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want %d", path, w.Code,
+				http.StatusBadRequest)
+		}
+	}
+}
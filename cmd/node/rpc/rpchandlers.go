@@ -56,11 +56,21 @@ type (
 		Res *None
 		Err error
 	}
+	// CombinePSBTRes is the result from a call to CombinePSBT
+	CombinePSBTRes struct {
+		Res *string
+		Err error
+	}
 	// CreateRawTransactionRes is the result from a call to CreateRawTransaction
 	CreateRawTransactionRes struct {
 		Res *string
 		Err error
 	}
+	// DecodePSBTRes is the result from a call to DecodePSBT
+	DecodePSBTRes struct {
+		Res *btcjson.DecodePSBTResult
+		Err error
+	}
 	// DecodeRawTransactionRes is the result from a call to DecodeRawTransaction
 	DecodeRawTransactionRes struct {
 		Res *btcjson.TxRawDecodeResult
@@ -76,11 +86,21 @@ type (
 		Res *float64
 		Err error
 	}
+	// FinalizePSBTRes is the result from a call to FinalizePSBT
+	FinalizePSBTRes struct {
+		Res *btcjson.FinalizePSBTResult
+		Err error
+	}
 	// GenerateRes is the result from a call to Generate
 	GenerateRes struct {
 		Res *[]string
 		Err error
 	}
+	// GenerateAlgoRes is the result from a call to GenerateAlgo
+	GenerateAlgoRes struct {
+		Res *[]string
+		Err error
+	}
 	// GetAddedNodeInfoRes is the result from a call to GetAddedNodeInfo
 	GetAddedNodeInfoRes struct {
 		Res *[]btcjson.GetAddedNodeInfoResultAddr
@@ -136,6 +156,11 @@ type (
 		Res *string
 		Err error
 	}
+	// GetChainTipsRes is the result from a call to GetChainTips
+	GetChainTipsRes struct {
+		Res *[]btcjson.GetChainTipsResult
+		Err error
+	}
 	// GetConnectionCountRes is the result from a call to GetConnectionCount
 	GetConnectionCountRes struct {
 		Res *int32
@@ -171,6 +196,21 @@ type (
 		Res *btcjson.InfoChainResult0
 		Err error
 	}
+	// GetMempoolAncestorsRes is the result from a call to GetMempoolAncestors
+	GetMempoolAncestorsRes struct {
+		Res *[]string
+		Err error
+	}
+	// GetMempoolDescendantsRes is the result from a call to GetMempoolDescendants
+	GetMempoolDescendantsRes struct {
+		Res *[]string
+		Err error
+	}
+	// GetMempoolEntryRes is the result from a call to GetMempoolEntry
+	GetMempoolEntryRes struct {
+		Res *btcjson.GetMempoolEntryResult
+		Err error
+	}
 	// GetMempoolInfoRes is the result from a call to GetMempoolInfo
 	GetMempoolInfoRes struct {
 		Res *btcjson.GetMempoolInfoResult
@@ -191,11 +231,21 @@ type (
 		Res *[]btcjson.GetPeerInfoResult
 		Err error
 	}
+	// GetNetworkInfoRes is the result from a call to GetNetworkInfo
+	GetNetworkInfoRes struct {
+		Res *btcjson.GetNetworkInfoResult
+		Err error
+	}
 	// GetPeerInfoRes is the result from a call to GetPeerInfo
 	GetPeerInfoRes struct {
 		Res *[]btcjson.GetPeerInfoResult
 		Err error
 	}
+	// GetPoolBalancesRes is the result from a call to GetPoolBalances
+	GetPoolBalancesRes struct {
+		Res *[]btcjson.GetPoolBalancesResult
+		Err error
+	}
 	// GetRawMempoolRes is the result from a call to GetRawMempool
 	GetRawMempoolRes struct {
 		Res *[]string
@@ -216,6 +266,16 @@ type (
 		Res *string
 		Err error
 	}
+	// InvalidateBlockRes is the result from a call to InvalidateBlock
+	InvalidateBlockRes struct {
+		Res *None
+		Err error
+	}
+	// LoadMempoolRes is the result from a call to LoadMempool
+	LoadMempoolRes struct {
+		Res *None
+		Err error
+	}
 	// NodeRes is the result from a call to Node
 	NodeRes struct {
 		Res *None
@@ -226,6 +286,26 @@ type (
 		Res *None
 		Err error
 	}
+	// PoolPayoutRes is the result from a call to PoolPayout
+	PoolPayoutRes struct {
+		Res *btcjson.PoolPayoutResult
+		Err error
+	}
+	// PreciousBlockRes is the result from a call to PreciousBlock
+	PreciousBlockRes struct {
+		Res *None
+		Err error
+	}
+	// PrioritiseTransactionRes is the result from a call to PrioritiseTransaction
+	PrioritiseTransactionRes struct {
+		Res *bool
+		Err error
+	}
+	// ReconsiderBlockRes is the result from a call to ReconsiderBlock
+	ReconsiderBlockRes struct {
+		Res *None
+		Err error
+	}
 	// ResetChainRes is the result from a call to ResetChain
 	ResetChainRes struct {
 		Res *None
@@ -236,6 +316,11 @@ type (
 		Res *None
 		Err error
 	}
+	// SaveMempoolRes is the result from a call to SaveMempool
+	SaveMempoolRes struct {
+		Res *None
+		Err error
+	}
 	// SearchRawTransactionsRes is the result from a call to SearchRawTransactions
 	SearchRawTransactionsRes struct {
 		Res *[]btcjson.SearchRawTransactionsResult
@@ -305,9 +390,15 @@ var RPCHandlersBeforeInit = map[string]CommandHandler{
 	"addnode": {
 		Fn: HandleAddNode, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan AddNodeRes)} }},
+	"combinepsbt": {
+		Fn: HandleCombinePSBT, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan CombinePSBTRes)} }},
 	"createrawtransaction": {
 		Fn: HandleCreateRawTransaction, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan CreateRawTransactionRes)} }},
+	"decodepsbt": {
+		Fn: HandleDecodePSBT, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan DecodePSBTRes)} }},
 	"decoderawtransaction": {
 		Fn: HandleDecodeRawTransaction, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan DecodeRawTransactionRes)} }},
@@ -317,9 +408,15 @@ var RPCHandlersBeforeInit = map[string]CommandHandler{
 	"estimatefee": {
 		Fn: HandleEstimateFee, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan EstimateFeeRes)} }},
+	"finalizepsbt": {
+		Fn: HandleFinalizePSBT, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan FinalizePSBTRes)} }},
 	"generate": {
 		Fn: HandleGenerate, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan GenerateRes)} }},
+	"generatealgo": {
+		Fn: HandleGenerateAlgo, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan GenerateAlgoRes)} }},
 	"getaddednodeinfo": {
 		Fn: HandleGetAddedNodeInfo, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan GetAddedNodeInfoRes)} }},
@@ -353,6 +450,9 @@ var RPCHandlersBeforeInit = map[string]CommandHandler{
 	"getcfilterheader": {
 		Fn: HandleGetCFilterHeader, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan GetCFilterHeaderRes)} }},
+	"getchaintips": {
+		Fn: HandleGetChainTips, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan GetChainTipsRes)} }},
 	"getconnectioncount": {
 		Fn: HandleGetConnectionCount, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan GetConnectionCountRes)} }},
@@ -374,6 +474,15 @@ var RPCHandlersBeforeInit = map[string]CommandHandler{
 	"getinfo": {
 		Fn: HandleGetInfo, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan GetInfoRes)} }},
+	"getmempoolancestors": {
+		Fn: HandleGetMempoolAncestors, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan GetMempoolAncestorsRes)} }},
+	"getmempooldescendants": {
+		Fn: HandleGetMempoolDescendants, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan GetMempoolDescendantsRes)} }},
+	"getmempoolentry": {
+		Fn: HandleGetMempoolEntry, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan GetMempoolEntryRes)} }},
 	"getmempoolinfo": {
 		Fn: HandleGetMempoolInfo, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan GetMempoolInfoRes)} }},
@@ -386,9 +495,15 @@ var RPCHandlersBeforeInit = map[string]CommandHandler{
 	"getnetworkhashps": {
 		Fn: HandleGetNetworkHashPS, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan GetNetworkHashPSRes)} }},
+	"getnetworkinfo": {
+		Fn: HandleGetNetworkInfo, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan GetNetworkInfoRes)} }},
 	"getpeerinfo": {
 		Fn: HandleGetPeerInfo, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan GetPeerInfoRes)} }},
+	"getpoolbalances": {
+		Fn: HandleGetPoolBalances, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan GetPoolBalancesRes)} }},
 	"getrawmempool": {
 		Fn: HandleGetRawMempool, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan GetRawMempoolRes)} }},
@@ -401,18 +516,39 @@ var RPCHandlersBeforeInit = map[string]CommandHandler{
 	"help": {
 		Fn: HandleHelp, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan HelpRes)} }},
+	"invalidateblock": {
+		Fn: HandleInvalidateBlock, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan InvalidateBlockRes)} }},
+	"loadmempool": {
+		Fn: HandleLoadMempool, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan LoadMempoolRes)} }},
 	"node": {
 		Fn: HandleNode, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan NodeRes)} }},
 	"ping": {
 		Fn: HandlePing, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan PingRes)} }},
+	"poolpayout": {
+		Fn: HandlePoolPayout, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan PoolPayoutRes)} }},
+	"preciousblock": {
+		Fn: HandlePreciousBlock, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan PreciousBlockRes)} }},
+	"prioritisetransaction": {
+		Fn: HandlePrioritiseTransaction, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan PrioritiseTransactionRes)} }},
+	"reconsiderblock": {
+		Fn: HandleReconsiderBlock, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan ReconsiderBlockRes)} }},
 	"resetchain": {
 		Fn: HandleResetChain, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan ResetChainRes)} }},
 	"restart": {
 		Fn: HandleRestart, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan RestartRes)} }},
+	"savemempool": {
+		Fn: HandleSaveMempool, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan SaveMempoolRes)} }},
 	"searchrawtransactions": {
 		Fn: HandleSearchRawTransactions, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan SearchRawTransactionsRes)} }},
@@ -492,6 +628,47 @@ func (a API) AddNodeWait(cmd *btcjson.AddNodeCmd) (out *None, err error) {
 	return
 }
 
+// CombinePSBT calls the method with the given parameters
+func (a API) CombinePSBT(cmd *btcjson.CombinePSBTCmd) (err error) {
+	RPCHandlers["combinepsbt"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// CombinePSBTCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) CombinePSBTCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan CombinePSBTRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// CombinePSBTGetRes returns a pointer to the value in the Result field
+func (a API) CombinePSBTGetRes() (out *string, err error) {
+	out, _ = a.Result.(*string)
+	err, _ = a.Result.(error)
+	return
+}
+
+// CombinePSBTWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) CombinePSBTWait(cmd *btcjson.CombinePSBTCmd) (out *string, err error) {
+	RPCHandlers["combinepsbt"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan CombinePSBTRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // CreateRawTransaction calls the method with the given parameters
 func (a API) CreateRawTransaction(cmd *btcjson.CreateRawTransactionCmd) (err error) {
 	RPCHandlers["createrawtransaction"].Call <- API{a.Ch, cmd, nil}
@@ -533,6 +710,47 @@ func (a API) CreateRawTransactionWait(cmd *btcjson.CreateRawTransactionCmd) (out
 	return
 }
 
+// DecodePSBT calls the method with the given parameters
+func (a API) DecodePSBT(cmd *btcjson.DecodePSBTCmd) (err error) {
+	RPCHandlers["decodepsbt"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// DecodePSBTCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) DecodePSBTCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan DecodePSBTRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// DecodePSBTGetRes returns a pointer to the value in the Result field
+func (a API) DecodePSBTGetRes() (out *btcjson.DecodePSBTResult, err error) {
+	out, _ = a.Result.(*btcjson.DecodePSBTResult)
+	err, _ = a.Result.(error)
+	return
+}
+
+// DecodePSBTWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) DecodePSBTWait(cmd *btcjson.DecodePSBTCmd) (out *btcjson.DecodePSBTResult, err error) {
+	RPCHandlers["decodepsbt"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan DecodePSBTRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // DecodeRawTransaction calls the method with the given parameters
 func (a API) DecodeRawTransaction(cmd *btcjson.DecodeRawTransactionCmd) (err error) {
 	RPCHandlers["decoderawtransaction"].Call <- API{a.Ch, cmd, nil}
@@ -656,8 +874,49 @@ func (a API) EstimateFeeWait(cmd *btcjson.EstimateFeeCmd) (out *float64, err err
 	return
 }
 
+// FinalizePSBT calls the method with the given parameters
+func (a API) FinalizePSBT(cmd *btcjson.FinalizePSBTCmd) (err error) {
+	RPCHandlers["finalizepsbt"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// FinalizePSBTCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) FinalizePSBTCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan FinalizePSBTRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// FinalizePSBTGetRes returns a pointer to the value in the Result field
+func (a API) FinalizePSBTGetRes() (out *btcjson.FinalizePSBTResult, err error) {
+	out, _ = a.Result.(*btcjson.FinalizePSBTResult)
+	err, _ = a.Result.(error)
+	return
+}
+
+// FinalizePSBTWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) FinalizePSBTWait(cmd *btcjson.FinalizePSBTCmd) (out *btcjson.FinalizePSBTResult, err error) {
+	RPCHandlers["finalizepsbt"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan FinalizePSBTRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // Generate calls the method with the given parameters
-func (a API) Generate(cmd *None) (err error) {
+func (a API) Generate(cmd *btcjson.GenerateCmd) (err error) {
 	RPCHandlers["generate"].Call <- API{a.Ch, cmd, nil}
 	return
 }
@@ -686,7 +945,7 @@ func (a API) GenerateGetRes() (out *[]string, err error) {
 }
 
 // GenerateWait calls the method and blocks until it returns or 5 seconds passes
-func (a API) GenerateWait(cmd *None) (out *[]string, err error) {
+func (a API) GenerateWait(cmd *btcjson.GenerateCmd) (out *[]string, err error) {
 	RPCHandlers["generate"].Call <- API{a.Ch, cmd, nil}
 	select {
 	case <-time.After(time.Second * 5):
@@ -697,6 +956,47 @@ func (a API) GenerateWait(cmd *None) (out *[]string, err error) {
 	return
 }
 
+// GenerateAlgo calls the method with the given parameters
+func (a API) GenerateAlgo(cmd *btcjson.GenerateAlgoCmd) (err error) {
+	RPCHandlers["generatealgo"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// GenerateAlgoCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) GenerateAlgoCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan GenerateAlgoRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// GenerateAlgoGetRes returns a pointer to the value in the Result field
+func (a API) GenerateAlgoGetRes() (out *[]string, err error) {
+	out, _ = a.Result.(*[]string)
+	err, _ = a.Result.(error)
+	return
+}
+
+// GenerateAlgoWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) GenerateAlgoWait(cmd *btcjson.GenerateAlgoCmd) (out *[]string, err error) {
+	RPCHandlers["generatealgo"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan GenerateAlgoRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // GetAddedNodeInfo calls the method with the given parameters
 func (a API) GetAddedNodeInfo(cmd *btcjson.GetAddedNodeInfoCmd) (err error) {
 	RPCHandlers["getaddednodeinfo"].Call <- API{a.Ch, cmd, nil}
@@ -1148,6 +1448,47 @@ func (a API) GetCFilterHeaderWait(cmd *btcjson.GetCFilterHeaderCmd) (out *string
 	return
 }
 
+// GetChainTips calls the method with the given parameters
+func (a API) GetChainTips(cmd *None) (err error) {
+	RPCHandlers["getchaintips"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// GetChainTipsCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) GetChainTipsCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan GetChainTipsRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// GetChainTipsGetRes returns a pointer to the value in the Result field
+func (a API) GetChainTipsGetRes() (out *[]btcjson.GetChainTipsResult, err error) {
+	out, _ = a.Result.(*[]btcjson.GetChainTipsResult)
+	err, _ = a.Result.(error)
+	return
+}
+
+// GetChainTipsWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) GetChainTipsWait(cmd *None) (out *[]btcjson.GetChainTipsResult, err error) {
+	RPCHandlers["getchaintips"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan GetChainTipsRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // GetConnectionCount calls the method with the given parameters
 func (a API) GetConnectionCount(cmd *None) (err error) {
 	RPCHandlers["getconnectioncount"].Call <- API{a.Ch, cmd, nil}
@@ -1435,17 +1776,17 @@ func (a API) GetInfoWait(cmd *None) (out *btcjson.InfoChainResult0, err error) {
 	return
 }
 
-// GetMempoolInfo calls the method with the given parameters
-func (a API) GetMempoolInfo(cmd *None) (err error) {
-	RPCHandlers["getmempoolinfo"].Call <- API{a.Ch, cmd, nil}
+// GetMempoolAncestors calls the method with the given parameters
+func (a API) GetMempoolAncestors(cmd *btcjson.GetMempoolAncestorsCmd) (err error) {
+	RPCHandlers["getmempoolancestors"].Call <- API{a.Ch, cmd, nil}
 	return
 }
 
-// GetMempoolInfoCheck checks if a new message arrived on the result channel and
+// GetMempoolAncestorsCheck checks if a new message arrived on the result channel and
 // returns true if it does, as well as storing the value in the Result field
-func (a API) GetMempoolInfoCheck() (isNew bool) {
+func (a API) GetMempoolAncestorsCheck() (isNew bool) {
 	select {
-	case o := <-a.Ch.(chan GetMempoolInfoRes):
+	case o := <-a.Ch.(chan GetMempoolAncestorsRes):
 		if o.Err != nil {
 			a.Result = o.Err
 		} else {
@@ -1457,36 +1798,36 @@ func (a API) GetMempoolInfoCheck() (isNew bool) {
 	return
 }
 
-// GetMempoolInfoGetRes returns a pointer to the value in the Result field
-func (a API) GetMempoolInfoGetRes() (out *btcjson.GetMempoolInfoResult, err error) {
-	out, _ = a.Result.(*btcjson.GetMempoolInfoResult)
+// GetMempoolAncestorsGetRes returns a pointer to the value in the Result field
+func (a API) GetMempoolAncestorsGetRes() (out *[]string, err error) {
+	out, _ = a.Result.(*[]string)
 	err, _ = a.Result.(error)
 	return
 }
 
-// GetMempoolInfoWait calls the method and blocks until it returns or 5 seconds passes
-func (a API) GetMempoolInfoWait(cmd *None) (out *btcjson.GetMempoolInfoResult, err error) {
-	RPCHandlers["getmempoolinfo"].Call <- API{a.Ch, cmd, nil}
+// GetMempoolAncestorsWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) GetMempoolAncestorsWait(cmd *btcjson.GetMempoolAncestorsCmd) (out *[]string, err error) {
+	RPCHandlers["getmempoolancestors"].Call <- API{a.Ch, cmd, nil}
 	select {
 	case <-time.After(time.Second * 5):
 		break
-	case o := <-a.Ch.(chan GetMempoolInfoRes):
+	case o := <-a.Ch.(chan GetMempoolAncestorsRes):
 		out, err = o.Res, o.Err
 	}
 	return
 }
 
-// GetMiningInfo calls the method with the given parameters
-func (a API) GetMiningInfo(cmd *None) (err error) {
-	RPCHandlers["getmininginfo"].Call <- API{a.Ch, cmd, nil}
+// GetMempoolDescendants calls the method with the given parameters
+func (a API) GetMempoolDescendants(cmd *btcjson.GetMempoolDescendantsCmd) (err error) {
+	RPCHandlers["getmempooldescendants"].Call <- API{a.Ch, cmd, nil}
 	return
 }
 
-// GetMiningInfoCheck checks if a new message arrived on the result channel and
+// GetMempoolDescendantsCheck checks if a new message arrived on the result channel and
 // returns true if it does, as well as storing the value in the Result field
-func (a API) GetMiningInfoCheck() (isNew bool) {
+func (a API) GetMempoolDescendantsCheck() (isNew bool) {
 	select {
-	case o := <-a.Ch.(chan GetMiningInfoRes):
+	case o := <-a.Ch.(chan GetMempoolDescendantsRes):
 		if o.Err != nil {
 			a.Result = o.Err
 		} else {
@@ -1498,36 +1839,36 @@ func (a API) GetMiningInfoCheck() (isNew bool) {
 	return
 }
 
-// GetMiningInfoGetRes returns a pointer to the value in the Result field
-func (a API) GetMiningInfoGetRes() (out *btcjson.GetMiningInfoResult, err error) {
-	out, _ = a.Result.(*btcjson.GetMiningInfoResult)
+// GetMempoolDescendantsGetRes returns a pointer to the value in the Result field
+func (a API) GetMempoolDescendantsGetRes() (out *[]string, err error) {
+	out, _ = a.Result.(*[]string)
 	err, _ = a.Result.(error)
 	return
 }
 
-// GetMiningInfoWait calls the method and blocks until it returns or 5 seconds passes
-func (a API) GetMiningInfoWait(cmd *None) (out *btcjson.GetMiningInfoResult, err error) {
-	RPCHandlers["getmininginfo"].Call <- API{a.Ch, cmd, nil}
+// GetMempoolDescendantsWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) GetMempoolDescendantsWait(cmd *btcjson.GetMempoolDescendantsCmd) (out *[]string, err error) {
+	RPCHandlers["getmempooldescendants"].Call <- API{a.Ch, cmd, nil}
 	select {
 	case <-time.After(time.Second * 5):
 		break
-	case o := <-a.Ch.(chan GetMiningInfoRes):
+	case o := <-a.Ch.(chan GetMempoolDescendantsRes):
 		out, err = o.Res, o.Err
 	}
 	return
 }
 
-// GetNetTotals calls the method with the given parameters
-func (a API) GetNetTotals(cmd *None) (err error) {
-	RPCHandlers["getnettotals"].Call <- API{a.Ch, cmd, nil}
+// GetMempoolEntry calls the method with the given parameters
+func (a API) GetMempoolEntry(cmd *btcjson.GetMempoolEntryCmd) (err error) {
+	RPCHandlers["getmempoolentry"].Call <- API{a.Ch, cmd, nil}
 	return
 }
 
-// GetNetTotalsCheck checks if a new message arrived on the result channel and
+// GetMempoolEntryCheck checks if a new message arrived on the result channel and
 // returns true if it does, as well as storing the value in the Result field
-func (a API) GetNetTotalsCheck() (isNew bool) {
+func (a API) GetMempoolEntryCheck() (isNew bool) {
 	select {
-	case o := <-a.Ch.(chan GetNetTotalsRes):
+	case o := <-a.Ch.(chan GetMempoolEntryRes):
 		if o.Err != nil {
 			a.Result = o.Err
 		} else {
@@ -1539,36 +1880,36 @@ func (a API) GetNetTotalsCheck() (isNew bool) {
 	return
 }
 
-// GetNetTotalsGetRes returns a pointer to the value in the Result field
-func (a API) GetNetTotalsGetRes() (out *btcjson.GetNetTotalsResult, err error) {
-	out, _ = a.Result.(*btcjson.GetNetTotalsResult)
+// GetMempoolEntryGetRes returns a pointer to the value in the Result field
+func (a API) GetMempoolEntryGetRes() (out *btcjson.GetMempoolEntryResult, err error) {
+	out, _ = a.Result.(*btcjson.GetMempoolEntryResult)
 	err, _ = a.Result.(error)
 	return
 }
 
-// GetNetTotalsWait calls the method and blocks until it returns or 5 seconds passes
-func (a API) GetNetTotalsWait(cmd *None) (out *btcjson.GetNetTotalsResult, err error) {
-	RPCHandlers["getnettotals"].Call <- API{a.Ch, cmd, nil}
+// GetMempoolEntryWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) GetMempoolEntryWait(cmd *btcjson.GetMempoolEntryCmd) (out *btcjson.GetMempoolEntryResult, err error) {
+	RPCHandlers["getmempoolentry"].Call <- API{a.Ch, cmd, nil}
 	select {
 	case <-time.After(time.Second * 5):
 		break
-	case o := <-a.Ch.(chan GetNetTotalsRes):
+	case o := <-a.Ch.(chan GetMempoolEntryRes):
 		out, err = o.Res, o.Err
 	}
 	return
 }
 
-// GetNetworkHashPS calls the method with the given parameters
-func (a API) GetNetworkHashPS(cmd *btcjson.GetNetworkHashPSCmd) (err error) {
-	RPCHandlers["getnetworkhashps"].Call <- API{a.Ch, cmd, nil}
+// GetMempoolInfo calls the method with the given parameters
+func (a API) GetMempoolInfo(cmd *None) (err error) {
+	RPCHandlers["getmempoolinfo"].Call <- API{a.Ch, cmd, nil}
 	return
 }
 
-// GetNetworkHashPSCheck checks if a new message arrived on the result channel and
+// GetMempoolInfoCheck checks if a new message arrived on the result channel and
 // returns true if it does, as well as storing the value in the Result field
-func (a API) GetNetworkHashPSCheck() (isNew bool) {
+func (a API) GetMempoolInfoCheck() (isNew bool) {
 	select {
-	case o := <-a.Ch.(chan GetNetworkHashPSRes):
+	case o := <-a.Ch.(chan GetMempoolInfoRes):
 		if o.Err != nil {
 			a.Result = o.Err
 		} else {
@@ -1580,28 +1921,192 @@ func (a API) GetNetworkHashPSCheck() (isNew bool) {
 	return
 }
 
-// GetNetworkHashPSGetRes returns a pointer to the value in the Result field
-func (a API) GetNetworkHashPSGetRes() (out *[]btcjson.GetPeerInfoResult, err error) {
-	out, _ = a.Result.(*[]btcjson.GetPeerInfoResult)
+// GetMempoolInfoGetRes returns a pointer to the value in the Result field
+func (a API) GetMempoolInfoGetRes() (out *btcjson.GetMempoolInfoResult, err error) {
+	out, _ = a.Result.(*btcjson.GetMempoolInfoResult)
 	err, _ = a.Result.(error)
 	return
 }
 
-// GetNetworkHashPSWait calls the method and blocks until it returns or 5 seconds passes
-func (a API) GetNetworkHashPSWait(cmd *btcjson.GetNetworkHashPSCmd) (out *[]btcjson.GetPeerInfoResult, err error) {
-	RPCHandlers["getnetworkhashps"].Call <- API{a.Ch, cmd, nil}
+// GetMempoolInfoWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) GetMempoolInfoWait(cmd *None) (out *btcjson.GetMempoolInfoResult, err error) {
+	RPCHandlers["getmempoolinfo"].Call <- API{a.Ch, cmd, nil}
 	select {
 	case <-time.After(time.Second * 5):
 		break
-	case o := <-a.Ch.(chan GetNetworkHashPSRes):
+	case o := <-a.Ch.(chan GetMempoolInfoRes):
 		out, err = o.Res, o.Err
 	}
 	return
 }
 
-// GetPeerInfo calls the method with the given parameters
-func (a API) GetPeerInfo(cmd *None) (err error) {
-	RPCHandlers["getpeerinfo"].Call <- API{a.Ch, cmd, nil}
+// GetMiningInfo calls the method with the given parameters
+func (a API) GetMiningInfo(cmd *None) (err error) {
+	RPCHandlers["getmininginfo"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// GetMiningInfoCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) GetMiningInfoCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan GetMiningInfoRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// GetMiningInfoGetRes returns a pointer to the value in the Result field
+func (a API) GetMiningInfoGetRes() (out *btcjson.GetMiningInfoResult, err error) {
+	out, _ = a.Result.(*btcjson.GetMiningInfoResult)
+	err, _ = a.Result.(error)
+	return
+}
+
+// GetMiningInfoWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) GetMiningInfoWait(cmd *None) (out *btcjson.GetMiningInfoResult, err error) {
+	RPCHandlers["getmininginfo"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan GetMiningInfoRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
+// GetNetTotals calls the method with the given parameters
+func (a API) GetNetTotals(cmd *None) (err error) {
+	RPCHandlers["getnettotals"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// GetNetTotalsCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) GetNetTotalsCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan GetNetTotalsRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// GetNetTotalsGetRes returns a pointer to the value in the Result field
+func (a API) GetNetTotalsGetRes() (out *btcjson.GetNetTotalsResult, err error) {
+	out, _ = a.Result.(*btcjson.GetNetTotalsResult)
+	err, _ = a.Result.(error)
+	return
+}
+
+// GetNetTotalsWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) GetNetTotalsWait(cmd *None) (out *btcjson.GetNetTotalsResult, err error) {
+	RPCHandlers["getnettotals"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan GetNetTotalsRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
+// GetNetworkHashPS calls the method with the given parameters
+func (a API) GetNetworkHashPS(cmd *btcjson.GetNetworkHashPSCmd) (err error) {
+	RPCHandlers["getnetworkhashps"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// GetNetworkHashPSCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) GetNetworkHashPSCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan GetNetworkHashPSRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// GetNetworkHashPSGetRes returns a pointer to the value in the Result field
+func (a API) GetNetworkHashPSGetRes() (out *[]btcjson.GetPeerInfoResult, err error) {
+	out, _ = a.Result.(*[]btcjson.GetPeerInfoResult)
+	err, _ = a.Result.(error)
+	return
+}
+
+// GetNetworkHashPSWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) GetNetworkHashPSWait(cmd *btcjson.GetNetworkHashPSCmd) (out *[]btcjson.GetPeerInfoResult, err error) {
+	RPCHandlers["getnetworkhashps"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan GetNetworkHashPSRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
+// GetNetworkInfo calls the method with the given parameters
+func (a API) GetNetworkInfo(cmd *None) (err error) {
+	RPCHandlers["getnetworkinfo"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// GetNetworkInfoCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) GetNetworkInfoCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan GetNetworkInfoRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// GetNetworkInfoGetRes returns a pointer to the value in the Result field
+func (a API) GetNetworkInfoGetRes() (out *btcjson.GetNetworkInfoResult, err error) {
+	out, _ = a.Result.(*btcjson.GetNetworkInfoResult)
+	err, _ = a.Result.(error)
+	return
+}
+
+// GetNetworkInfoWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) GetNetworkInfoWait(cmd *None) (out *btcjson.GetNetworkInfoResult, err error) {
+	RPCHandlers["getnetworkinfo"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan GetNetworkInfoRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
+// GetPeerInfo calls the method with the given parameters
+func (a API) GetPeerInfo(cmd *None) (err error) {
+	RPCHandlers["getpeerinfo"].Call <- API{a.Ch, cmd, nil}
 	return
 }
 
@@ -1640,6 +2145,47 @@ func (a API) GetPeerInfoWait(cmd *None) (out *[]btcjson.GetPeerInfoResult, err e
 	return
 }
 
+// GetPoolBalances calls the method with the given parameters
+func (a API) GetPoolBalances(cmd *None) (err error) {
+	RPCHandlers["getpoolbalances"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// GetPoolBalancesCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) GetPoolBalancesCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan GetPoolBalancesRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// GetPoolBalancesGetRes returns a pointer to the value in the Result field
+func (a API) GetPoolBalancesGetRes() (out *[]btcjson.GetPoolBalancesResult, err error) {
+	out, _ = a.Result.(*[]btcjson.GetPoolBalancesResult)
+	err, _ = a.Result.(error)
+	return
+}
+
+// GetPoolBalancesWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) GetPoolBalancesWait(cmd *None) (out *[]btcjson.GetPoolBalancesResult, err error) {
+	RPCHandlers["getpoolbalances"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan GetPoolBalancesRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // GetRawMempool calls the method with the given parameters
 func (a API) GetRawMempool(cmd *btcjson.GetRawMempoolCmd) (err error) {
 	RPCHandlers["getrawmempool"].Call <- API{a.Ch, cmd, nil}
@@ -1804,6 +2350,88 @@ func (a API) HelpWait(cmd *btcjson.HelpCmd) (out *string, err error) {
 	return
 }
 
+// InvalidateBlock calls the method with the given parameters
+func (a API) InvalidateBlock(cmd *btcjson.InvalidateBlockCmd) (err error) {
+	RPCHandlers["invalidateblock"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// InvalidateBlockCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) InvalidateBlockCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan InvalidateBlockRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// InvalidateBlockGetRes returns a pointer to the value in the Result field
+func (a API) InvalidateBlockGetRes() (out *None, err error) {
+	out, _ = a.Result.(*None)
+	err, _ = a.Result.(error)
+	return
+}
+
+// InvalidateBlockWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) InvalidateBlockWait(cmd *btcjson.InvalidateBlockCmd) (out *None, err error) {
+	RPCHandlers["invalidateblock"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan InvalidateBlockRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
+// LoadMempool calls the method with the given parameters
+func (a API) LoadMempool(cmd *None) (err error) {
+	RPCHandlers["loadmempool"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// LoadMempoolCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) LoadMempoolCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan LoadMempoolRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// LoadMempoolGetRes returns a pointer to the value in the Result field
+func (a API) LoadMempoolGetRes() (out *None, err error) {
+	out, _ = a.Result.(*None)
+	err, _ = a.Result.(error)
+	return
+}
+
+// LoadMempoolWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) LoadMempoolWait(cmd *None) (out *None, err error) {
+	RPCHandlers["loadmempool"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan LoadMempoolRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // Node calls the method with the given parameters
 func (a API) Node(cmd *btcjson.NodeCmd) (err error) {
 	RPCHandlers["node"].Call <- API{a.Ch, cmd, nil}
@@ -1886,6 +2514,170 @@ func (a API) PingWait(cmd *None) (out *None, err error) {
 	return
 }
 
+// PoolPayout calls the method with the given parameters
+func (a API) PoolPayout(cmd *btcjson.PoolPayoutCmd) (err error) {
+	RPCHandlers["poolpayout"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// PoolPayoutCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) PoolPayoutCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan PoolPayoutRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// PoolPayoutGetRes returns a pointer to the value in the Result field
+func (a API) PoolPayoutGetRes() (out *btcjson.PoolPayoutResult, err error) {
+	out, _ = a.Result.(*btcjson.PoolPayoutResult)
+	err, _ = a.Result.(error)
+	return
+}
+
+// PoolPayoutWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) PoolPayoutWait(cmd *btcjson.PoolPayoutCmd) (out *btcjson.PoolPayoutResult, err error) {
+	RPCHandlers["poolpayout"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan PoolPayoutRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
+// PreciousBlock calls the method with the given parameters
+func (a API) PreciousBlock(cmd *btcjson.PreciousBlockCmd) (err error) {
+	RPCHandlers["preciousblock"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// PreciousBlockCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) PreciousBlockCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan PreciousBlockRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// PreciousBlockGetRes returns a pointer to the value in the Result field
+func (a API) PreciousBlockGetRes() (out *None, err error) {
+	out, _ = a.Result.(*None)
+	err, _ = a.Result.(error)
+	return
+}
+
+// PreciousBlockWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) PreciousBlockWait(cmd *btcjson.PreciousBlockCmd) (out *None, err error) {
+	RPCHandlers["preciousblock"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan PreciousBlockRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
+// PrioritiseTransaction calls the method with the given parameters
+func (a API) PrioritiseTransaction(cmd *btcjson.PrioritiseTransactionCmd) (err error) {
+	RPCHandlers["prioritisetransaction"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// PrioritiseTransactionCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) PrioritiseTransactionCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan PrioritiseTransactionRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// PrioritiseTransactionGetRes returns a pointer to the value in the Result field
+func (a API) PrioritiseTransactionGetRes() (out *bool, err error) {
+	out, _ = a.Result.(*bool)
+	err, _ = a.Result.(error)
+	return
+}
+
+// PrioritiseTransactionWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) PrioritiseTransactionWait(cmd *btcjson.PrioritiseTransactionCmd) (out *bool, err error) {
+	RPCHandlers["prioritisetransaction"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan PrioritiseTransactionRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
+// ReconsiderBlock calls the method with the given parameters
+func (a API) ReconsiderBlock(cmd *btcjson.ReconsiderBlockCmd) (err error) {
+	RPCHandlers["reconsiderblock"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// ReconsiderBlockCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) ReconsiderBlockCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan ReconsiderBlockRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// ReconsiderBlockGetRes returns a pointer to the value in the Result field
+func (a API) ReconsiderBlockGetRes() (out *None, err error) {
+	out, _ = a.Result.(*None)
+	err, _ = a.Result.(error)
+	return
+}
+
+// ReconsiderBlockWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) ReconsiderBlockWait(cmd *btcjson.ReconsiderBlockCmd) (out *None, err error) {
+	RPCHandlers["reconsiderblock"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan ReconsiderBlockRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // ResetChain calls the method with the given parameters
 func (a API) ResetChain(cmd *None) (err error) {
 	RPCHandlers["resetchain"].Call <- API{a.Ch, cmd, nil}
@@ -1968,6 +2760,47 @@ func (a API) RestartWait(cmd *None) (out *None, err error) {
 	return
 }
 
+// SaveMempool calls the method with the given parameters
+func (a API) SaveMempool(cmd *None) (err error) {
+	RPCHandlers["savemempool"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// SaveMempoolCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) SaveMempoolCheck() (isNew bool) {
+	select {
+	case o := <-a.Ch.(chan SaveMempoolRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// SaveMempoolGetRes returns a pointer to the value in the Result field
+func (a API) SaveMempoolGetRes() (out *None, err error) {
+	out, _ = a.Result.(*None)
+	err, _ = a.Result.(error)
+	return
+}
+
+// SaveMempoolWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) SaveMempoolWait(cmd *None) (out *None, err error) {
+	RPCHandlers["savemempool"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second * 5):
+		break
+	case o := <-a.Ch.(chan SaveMempoolRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // SearchRawTransactions calls the method with the given parameters
 func (a API) SearchRawTransactions(cmd *btcjson.SearchRawTransactionsCmd) (err error) {
 	RPCHandlers["searchrawtransactions"].Call <- API{a.Ch, cmd, nil}
@@ -2396,6 +3229,13 @@ func RunAPI(server *Server, quit chan struct{}) {
 				if r, ok := res.(None); ok {
 					msg.Ch.(chan AddNodeRes) <- AddNodeRes{&r, err}
 				}
+			case msg := <-nrh["combinepsbt"].Call:
+				if res, err = nrh["combinepsbt"].
+					Fn(server, msg.Params.(*btcjson.CombinePSBTCmd), nil); Check(err) {
+				}
+				if r, ok := res.(string); ok {
+					msg.Ch.(chan CombinePSBTRes) <- CombinePSBTRes{&r, err}
+				}
 			case msg := <-nrh["createrawtransaction"].Call:
 				if res, err = nrh["createrawtransaction"].
 					Fn(server, msg.Params.(*btcjson.CreateRawTransactionCmd), nil); Check(err) {
@@ -2403,6 +3243,13 @@ func RunAPI(server *Server, quit chan struct{}) {
 				if r, ok := res.(string); ok {
 					msg.Ch.(chan CreateRawTransactionRes) <- CreateRawTransactionRes{&r, err}
 				}
+			case msg := <-nrh["decodepsbt"].Call:
+				if res, err = nrh["decodepsbt"].
+					Fn(server, msg.Params.(*btcjson.DecodePSBTCmd), nil); Check(err) {
+				}
+				if r, ok := res.(btcjson.DecodePSBTResult); ok {
+					msg.Ch.(chan DecodePSBTRes) <- DecodePSBTRes{&r, err}
+				}
 			case msg := <-nrh["decoderawtransaction"].Call:
 				if res, err = nrh["decoderawtransaction"].
 					Fn(server, msg.Params.(*btcjson.DecodeRawTransactionCmd), nil); Check(err) {
@@ -2424,13 +3271,27 @@ func RunAPI(server *Server, quit chan struct{}) {
 				if r, ok := res.(float64); ok {
 					msg.Ch.(chan EstimateFeeRes) <- EstimateFeeRes{&r, err}
 				}
+			case msg := <-nrh["finalizepsbt"].Call:
+				if res, err = nrh["finalizepsbt"].
+					Fn(server, msg.Params.(*btcjson.FinalizePSBTCmd), nil); Check(err) {
+				}
+				if r, ok := res.(btcjson.FinalizePSBTResult); ok {
+					msg.Ch.(chan FinalizePSBTRes) <- FinalizePSBTRes{&r, err}
+				}
 			case msg := <-nrh["generate"].Call:
 				if res, err = nrh["generate"].
-					Fn(server, msg.Params.(*None), nil); Check(err) {
+					Fn(server, msg.Params.(*btcjson.GenerateCmd), nil); Check(err) {
 				}
 				if r, ok := res.([]string); ok {
 					msg.Ch.(chan GenerateRes) <- GenerateRes{&r, err}
 				}
+			case msg := <-nrh["generatealgo"].Call:
+				if res, err = nrh["generatealgo"].
+					Fn(server, msg.Params.(*btcjson.GenerateAlgoCmd), nil); Check(err) {
+				}
+				if r, ok := res.([]string); ok {
+					msg.Ch.(chan GenerateAlgoRes) <- GenerateAlgoRes{&r, err}
+				}
 			case msg := <-nrh["getaddednodeinfo"].Call:
 				if res, err = nrh["getaddednodeinfo"].
 					Fn(server, msg.Params.(*btcjson.GetAddedNodeInfoCmd), nil); Check(err) {
@@ -2508,6 +3369,13 @@ func RunAPI(server *Server, quit chan struct{}) {
 				if r, ok := res.(string); ok {
 					msg.Ch.(chan GetCFilterHeaderRes) <- GetCFilterHeaderRes{&r, err}
 				}
+			case msg := <-nrh["getchaintips"].Call:
+				if res, err = nrh["getchaintips"].
+					Fn(server, msg.Params.(*None), nil); Check(err) {
+				}
+				if r, ok := res.([]btcjson.GetChainTipsResult); ok {
+					msg.Ch.(chan GetChainTipsRes) <- GetChainTipsRes{&r, err}
+				}
 			case msg := <-nrh["getconnectioncount"].Call:
 				if res, err = nrh["getconnectioncount"].
 					Fn(server, msg.Params.(*None), nil); Check(err) {
@@ -2557,6 +3425,27 @@ func RunAPI(server *Server, quit chan struct{}) {
 				if r, ok := res.(btcjson.InfoChainResult0); ok {
 					msg.Ch.(chan GetInfoRes) <- GetInfoRes{&r, err}
 				}
+			case msg := <-nrh["getmempoolancestors"].Call:
+				if res, err = nrh["getmempoolancestors"].
+					Fn(server, msg.Params.(*btcjson.GetMempoolAncestorsCmd), nil); Check(err) {
+				}
+				if r, ok := res.([]string); ok {
+					msg.Ch.(chan GetMempoolAncestorsRes) <- GetMempoolAncestorsRes{&r, err}
+				}
+			case msg := <-nrh["getmempooldescendants"].Call:
+				if res, err = nrh["getmempooldescendants"].
+					Fn(server, msg.Params.(*btcjson.GetMempoolDescendantsCmd), nil); Check(err) {
+				}
+				if r, ok := res.([]string); ok {
+					msg.Ch.(chan GetMempoolDescendantsRes) <- GetMempoolDescendantsRes{&r, err}
+				}
+			case msg := <-nrh["getmempoolentry"].Call:
+				if res, err = nrh["getmempoolentry"].
+					Fn(server, msg.Params.(*btcjson.GetMempoolEntryCmd), nil); Check(err) {
+				}
+				if r, ok := res.(btcjson.GetMempoolEntryResult); ok {
+					msg.Ch.(chan GetMempoolEntryRes) <- GetMempoolEntryRes{&r, err}
+				}
 			case msg := <-nrh["getmempoolinfo"].Call:
 				if res, err = nrh["getmempoolinfo"].
 					Fn(server, msg.Params.(*None), nil); Check(err) {
@@ -2585,6 +3474,13 @@ func RunAPI(server *Server, quit chan struct{}) {
 				if r, ok := res.([]btcjson.GetPeerInfoResult); ok {
 					msg.Ch.(chan GetNetworkHashPSRes) <- GetNetworkHashPSRes{&r, err}
 				}
+			case msg := <-nrh["getnetworkinfo"].Call:
+				if res, err = nrh["getnetworkinfo"].
+					Fn(server, msg.Params.(*None), nil); Check(err) {
+				}
+				if r, ok := res.(btcjson.GetNetworkInfoResult); ok {
+					msg.Ch.(chan GetNetworkInfoRes) <- GetNetworkInfoRes{&r, err}
+				}
 			case msg := <-nrh["getpeerinfo"].Call:
 				if res, err = nrh["getpeerinfo"].
 					Fn(server, msg.Params.(*None), nil); Check(err) {
@@ -2592,6 +3488,13 @@ func RunAPI(server *Server, quit chan struct{}) {
 				if r, ok := res.([]btcjson.GetPeerInfoResult); ok {
 					msg.Ch.(chan GetPeerInfoRes) <- GetPeerInfoRes{&r, err}
 				}
+			case msg := <-nrh["getpoolbalances"].Call:
+				if res, err = nrh["getpoolbalances"].
+					Fn(server, msg.Params.(*None), nil); Check(err) {
+				}
+				if r, ok := res.([]btcjson.GetPoolBalancesResult); ok {
+					msg.Ch.(chan GetPoolBalancesRes) <- GetPoolBalancesRes{&r, err}
+				}
 			case msg := <-nrh["getrawmempool"].Call:
 				if res, err = nrh["getrawmempool"].
 					Fn(server, msg.Params.(*btcjson.GetRawMempoolCmd), nil); Check(err) {
@@ -2620,6 +3523,20 @@ func RunAPI(server *Server, quit chan struct{}) {
 				if r, ok := res.(string); ok {
 					msg.Ch.(chan HelpRes) <- HelpRes{&r, err}
 				}
+			case msg := <-nrh["invalidateblock"].Call:
+				if res, err = nrh["invalidateblock"].
+					Fn(server, msg.Params.(*btcjson.InvalidateBlockCmd), nil); Check(err) {
+				}
+				if r, ok := res.(None); ok {
+					msg.Ch.(chan InvalidateBlockRes) <- InvalidateBlockRes{&r, err}
+				}
+			case msg := <-nrh["loadmempool"].Call:
+				if res, err = nrh["loadmempool"].
+					Fn(server, msg.Params.(*None), nil); Check(err) {
+				}
+				if r, ok := res.(None); ok {
+					msg.Ch.(chan LoadMempoolRes) <- LoadMempoolRes{&r, err}
+				}
 			case msg := <-nrh["node"].Call:
 				if res, err = nrh["node"].
 					Fn(server, msg.Params.(*btcjson.NodeCmd), nil); Check(err) {
@@ -2634,6 +3551,34 @@ func RunAPI(server *Server, quit chan struct{}) {
 				if r, ok := res.(None); ok {
 					msg.Ch.(chan PingRes) <- PingRes{&r, err}
 				}
+			case msg := <-nrh["poolpayout"].Call:
+				if res, err = nrh["poolpayout"].
+					Fn(server, msg.Params.(*btcjson.PoolPayoutCmd), nil); Check(err) {
+				}
+				if r, ok := res.(btcjson.PoolPayoutResult); ok {
+					msg.Ch.(chan PoolPayoutRes) <- PoolPayoutRes{&r, err}
+				}
+			case msg := <-nrh["preciousblock"].Call:
+				if res, err = nrh["preciousblock"].
+					Fn(server, msg.Params.(*btcjson.PreciousBlockCmd), nil); Check(err) {
+				}
+				if r, ok := res.(None); ok {
+					msg.Ch.(chan PreciousBlockRes) <- PreciousBlockRes{&r, err}
+				}
+			case msg := <-nrh["prioritisetransaction"].Call:
+				if res, err = nrh["prioritisetransaction"].
+					Fn(server, msg.Params.(*btcjson.PrioritiseTransactionCmd), nil); Check(err) {
+				}
+				if r, ok := res.(bool); ok {
+					msg.Ch.(chan PrioritiseTransactionRes) <- PrioritiseTransactionRes{&r, err}
+				}
+			case msg := <-nrh["reconsiderblock"].Call:
+				if res, err = nrh["reconsiderblock"].
+					Fn(server, msg.Params.(*btcjson.ReconsiderBlockCmd), nil); Check(err) {
+				}
+				if r, ok := res.(None); ok {
+					msg.Ch.(chan ReconsiderBlockRes) <- ReconsiderBlockRes{&r, err}
+				}
 			case msg := <-nrh["resetchain"].Call:
 				if res, err = nrh["resetchain"].
 					Fn(server, msg.Params.(*None), nil); Check(err) {
@@ -2648,6 +3593,13 @@ func RunAPI(server *Server, quit chan struct{}) {
 				if r, ok := res.(None); ok {
 					msg.Ch.(chan RestartRes) <- RestartRes{&r, err}
 				}
+			case msg := <-nrh["savemempool"].Call:
+				if res, err = nrh["savemempool"].
+					Fn(server, msg.Params.(*None), nil); Check(err) {
+				}
+				if r, ok := res.(None); ok {
+					msg.Ch.(chan SaveMempoolRes) <- SaveMempoolRes{&r, err}
+				}
 			case msg := <-nrh["searchrawtransactions"].Call:
 				if res, err = nrh["searchrawtransactions"].
 					Fn(server, msg.Params.(*btcjson.SearchRawTransactionsCmd), nil); Check(err) {
@@ -2741,6 +3693,19 @@ func (c *CAPI) AddNode(req **btcjson.AddNodeCmd, resp *None) (err error) {
 	return
 }
 
+func (c *CAPI) CombinePSBT(req **btcjson.CombinePSBTCmd, resp *string) (err error) {
+	nrh := RPCHandlers
+	res := nrh["combinepsbt"].Result()
+	res.Params = req
+	nrh["combinepsbt"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan string):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
 func (c *CAPI) CreateRawTransaction(req **btcjson.CreateRawTransactionCmd, resp *string) (err error) {
 	nrh := RPCHandlers
 	res := nrh["createrawtransaction"].Result()
@@ -2754,6 +3719,19 @@ func (c *CAPI) CreateRawTransaction(req **btcjson.CreateRawTransactionCmd, resp
 	return
 }
 
+func (c *CAPI) DecodePSBT(req **btcjson.DecodePSBTCmd, resp *btcjson.DecodePSBTResult) (err error) {
+	nrh := RPCHandlers
+	res := nrh["decodepsbt"].Result()
+	res.Params = req
+	nrh["decodepsbt"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan btcjson.DecodePSBTResult):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
 func (c *CAPI) DecodeRawTransaction(req **btcjson.DecodeRawTransactionCmd, resp *btcjson.TxRawDecodeResult) (err error) {
 	nrh := RPCHandlers
 	res := nrh["decoderawtransaction"].Result()
@@ -2784,20 +3762,46 @@ func (c *CAPI) EstimateFee(req **btcjson.EstimateFeeCmd, resp *float64) (err err
 	nrh := RPCHandlers
 	res := nrh["estimatefee"].Result()
 	res.Params = req
-	nrh["estimatefee"].Call <- res
+	nrh["estimatefee"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan float64):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
+func (c *CAPI) FinalizePSBT(req **btcjson.FinalizePSBTCmd, resp *btcjson.FinalizePSBTResult) (err error) {
+	nrh := RPCHandlers
+	res := nrh["finalizepsbt"].Result()
+	res.Params = req
+	nrh["finalizepsbt"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan btcjson.FinalizePSBTResult):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
+func (c *CAPI) Generate(req **btcjson.GenerateCmd, resp *[]string) (err error) {
+	nrh := RPCHandlers
+	res := nrh["generate"].Result()
+	res.Params = req
+	nrh["generate"].Call <- res
 	select {
-	case *resp = <-res.Ch.(chan float64):
+	case *resp = <-res.Ch.(chan []string):
 	case <-time.After(c.Timeout):
 	case <-c.quit:
 	}
 	return
 }
 
-func (c *CAPI) Generate(req **None, resp *[]string) (err error) {
+func (c *CAPI) GenerateAlgo(req **btcjson.GenerateAlgoCmd, resp *[]string) (err error) {
 	nrh := RPCHandlers
-	res := nrh["generate"].Result()
+	res := nrh["generatealgo"].Result()
 	res.Params = req
-	nrh["generate"].Call <- res
+	nrh["generatealgo"].Call <- res
 	select {
 	case *resp = <-res.Ch.(chan []string):
 	case <-time.After(c.Timeout):
@@ -2949,6 +3953,19 @@ func (c *CAPI) GetCFilterHeader(req **btcjson.GetCFilterHeaderCmd, resp *string)
 	return
 }
 
+func (c *CAPI) GetChainTips(req **None, resp *[]btcjson.GetChainTipsResult) (err error) {
+	nrh := RPCHandlers
+	res := nrh["getchaintips"].Result()
+	res.Params = req
+	nrh["getchaintips"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan []btcjson.GetChainTipsResult):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
 func (c *CAPI) GetConnectionCount(req **None, resp *int32) (err error) {
 	nrh := RPCHandlers
 	res := nrh["getconnectioncount"].Result()
@@ -3040,6 +4057,45 @@ func (c *CAPI) GetInfo(req **None, resp *btcjson.InfoChainResult0) (err error) {
 	return
 }
 
+func (c *CAPI) GetMempoolAncestors(req **btcjson.GetMempoolAncestorsCmd, resp *[]string) (err error) {
+	nrh := RPCHandlers
+	res := nrh["getmempoolancestors"].Result()
+	res.Params = req
+	nrh["getmempoolancestors"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan []string):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
+func (c *CAPI) GetMempoolDescendants(req **btcjson.GetMempoolDescendantsCmd, resp *[]string) (err error) {
+	nrh := RPCHandlers
+	res := nrh["getmempooldescendants"].Result()
+	res.Params = req
+	nrh["getmempooldescendants"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan []string):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
+func (c *CAPI) GetMempoolEntry(req **btcjson.GetMempoolEntryCmd, resp *btcjson.GetMempoolEntryResult) (err error) {
+	nrh := RPCHandlers
+	res := nrh["getmempoolentry"].Result()
+	res.Params = req
+	nrh["getmempoolentry"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan btcjson.GetMempoolEntryResult):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
 func (c *CAPI) GetMempoolInfo(req **None, resp *btcjson.GetMempoolInfoResult) (err error) {
 	nrh := RPCHandlers
 	res := nrh["getmempoolinfo"].Result()
@@ -3092,6 +4148,19 @@ func (c *CAPI) GetNetworkHashPS(req **btcjson.GetNetworkHashPSCmd, resp *[]btcjs
 	return
 }
 
+func (c *CAPI) GetNetworkInfo(req **None, resp *btcjson.GetNetworkInfoResult) (err error) {
+	nrh := RPCHandlers
+	res := nrh["getnetworkinfo"].Result()
+	res.Params = req
+	nrh["getnetworkinfo"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan btcjson.GetNetworkInfoResult):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
 func (c *CAPI) GetPeerInfo(req **None, resp *[]btcjson.GetPeerInfoResult) (err error) {
 	nrh := RPCHandlers
 	res := nrh["getpeerinfo"].Result()
@@ -3105,6 +4174,19 @@ func (c *CAPI) GetPeerInfo(req **None, resp *[]btcjson.GetPeerInfoResult) (err e
 	return
 }
 
+func (c *CAPI) GetPoolBalances(req **None, resp *[]btcjson.GetPoolBalancesResult) (err error) {
+	nrh := RPCHandlers
+	res := nrh["getpoolbalances"].Result()
+	res.Params = req
+	nrh["getpoolbalances"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan []btcjson.GetPoolBalancesResult):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
 func (c *CAPI) GetRawMempool(req **btcjson.GetRawMempoolCmd, resp *[]string) (err error) {
 	nrh := RPCHandlers
 	res := nrh["getrawmempool"].Result()
@@ -3157,6 +4239,32 @@ func (c *CAPI) Help(req **btcjson.HelpCmd, resp *string) (err error) {
 	return
 }
 
+func (c *CAPI) InvalidateBlock(req **btcjson.InvalidateBlockCmd, resp *None) (err error) {
+	nrh := RPCHandlers
+	res := nrh["invalidateblock"].Result()
+	res.Params = req
+	nrh["invalidateblock"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan None):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
+func (c *CAPI) LoadMempool(req **None, resp *None) (err error) {
+	nrh := RPCHandlers
+	res := nrh["loadmempool"].Result()
+	res.Params = req
+	nrh["loadmempool"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan None):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
 func (c *CAPI) Node(req **btcjson.NodeCmd, resp *None) (err error) {
 	nrh := RPCHandlers
 	res := nrh["node"].Result()
@@ -3183,6 +4291,58 @@ func (c *CAPI) Ping(req **None, resp *None) (err error) {
 	return
 }
 
+func (c *CAPI) PoolPayout(req **btcjson.PoolPayoutCmd, resp *btcjson.PoolPayoutResult) (err error) {
+	nrh := RPCHandlers
+	res := nrh["poolpayout"].Result()
+	res.Params = req
+	nrh["poolpayout"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan btcjson.PoolPayoutResult):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
+func (c *CAPI) PreciousBlock(req **btcjson.PreciousBlockCmd, resp *None) (err error) {
+	nrh := RPCHandlers
+	res := nrh["preciousblock"].Result()
+	res.Params = req
+	nrh["preciousblock"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan None):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
+func (c *CAPI) PrioritiseTransaction(req **btcjson.PrioritiseTransactionCmd, resp *bool) (err error) {
+	nrh := RPCHandlers
+	res := nrh["prioritisetransaction"].Result()
+	res.Params = req
+	nrh["prioritisetransaction"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan bool):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
+func (c *CAPI) ReconsiderBlock(req **btcjson.ReconsiderBlockCmd, resp *None) (err error) {
+	nrh := RPCHandlers
+	res := nrh["reconsiderblock"].Result()
+	res.Params = req
+	nrh["reconsiderblock"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan None):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
 func (c *CAPI) ResetChain(req **None, resp *None) (err error) {
 	nrh := RPCHandlers
 	res := nrh["resetchain"].Result()
@@ -3209,6 +4369,19 @@ func (c *CAPI) Restart(req **None, resp *None) (err error) {
 	return
 }
 
+func (c *CAPI) SaveMempool(req **None, resp *None) (err error) {
+	nrh := RPCHandlers
+	res := nrh["savemempool"].Result()
+	res.Params = req
+	nrh["savemempool"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan None):
+	case <-time.After(c.Timeout):
+	case <-c.quit:
+	}
+	return
+}
+
 func (c *CAPI) SearchRawTransactions(req **btcjson.SearchRawTransactionsCmd, resp *[]btcjson.SearchRawTransactionsResult) (err error) {
 	nrh := RPCHandlers
 	res := nrh["searchrawtransactions"].Result()
@@ -3351,6 +4524,16 @@ func (r *CAPIClient) AddNode(cmd ...*btcjson.AddNodeCmd) (res None, err error) {
 	return
 }
 
+func (r *CAPIClient) CombinePSBT(cmd ...*btcjson.CombinePSBTCmd) (res string, err error) {
+	var c *btcjson.CombinePSBTCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.CombinePSBT", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) CreateRawTransaction(cmd ...*btcjson.CreateRawTransactionCmd) (res string, err error) {
 	var c *btcjson.CreateRawTransactionCmd
 	if len(cmd) > 0 {
@@ -3361,6 +4544,16 @@ func (r *CAPIClient) CreateRawTransaction(cmd ...*btcjson.CreateRawTransactionCm
 	return
 }
 
+func (r *CAPIClient) DecodePSBT(cmd ...*btcjson.DecodePSBTCmd) (res btcjson.DecodePSBTResult, err error) {
+	var c *btcjson.DecodePSBTCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.DecodePSBT", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) DecodeRawTransaction(cmd ...*btcjson.DecodeRawTransactionCmd) (res btcjson.TxRawDecodeResult, err error) {
 	var c *btcjson.DecodeRawTransactionCmd
 	if len(cmd) > 0 {
@@ -3391,8 +4584,18 @@ func (r *CAPIClient) EstimateFee(cmd ...*btcjson.EstimateFeeCmd) (res float64, e
 	return
 }
 
-func (r *CAPIClient) Generate(cmd ...*None) (res []string, err error) {
-	var c *None
+func (r *CAPIClient) FinalizePSBT(cmd ...*btcjson.FinalizePSBTCmd) (res btcjson.FinalizePSBTResult, err error) {
+	var c *btcjson.FinalizePSBTCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.FinalizePSBT", c, &res); Check(err) {
+	}
+	return
+}
+
+func (r *CAPIClient) Generate(cmd ...*btcjson.GenerateCmd) (res []string, err error) {
+	var c *btcjson.GenerateCmd
 	if len(cmd) > 0 {
 		c = cmd[0]
 	}
@@ -3401,6 +4604,16 @@ func (r *CAPIClient) Generate(cmd ...*None) (res []string, err error) {
 	return
 }
 
+func (r *CAPIClient) GenerateAlgo(cmd ...*btcjson.GenerateAlgoCmd) (res []string, err error) {
+	var c *btcjson.GenerateAlgoCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.GenerateAlgo", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) GetAddedNodeInfo(cmd ...*btcjson.GetAddedNodeInfoCmd) (res []btcjson.GetAddedNodeInfoResultAddr, err error) {
 	var c *btcjson.GetAddedNodeInfoCmd
 	if len(cmd) > 0 {
@@ -3511,6 +4724,16 @@ func (r *CAPIClient) GetCFilterHeader(cmd ...*btcjson.GetCFilterHeaderCmd) (res
 	return
 }
 
+func (r *CAPIClient) GetChainTips(cmd ...*None) (res []btcjson.GetChainTipsResult, err error) {
+	var c *None
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.GetChainTips", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) GetConnectionCount(cmd ...*None) (res int32, err error) {
 	var c *None
 	if len(cmd) > 0 {
@@ -3581,6 +4804,36 @@ func (r *CAPIClient) GetInfo(cmd ...*None) (res btcjson.InfoChainResult0, err er
 	return
 }
 
+func (r *CAPIClient) GetMempoolAncestors(cmd ...*btcjson.GetMempoolAncestorsCmd) (res []string, err error) {
+	var c *btcjson.GetMempoolAncestorsCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.GetMempoolAncestors", c, &res); Check(err) {
+	}
+	return
+}
+
+func (r *CAPIClient) GetMempoolDescendants(cmd ...*btcjson.GetMempoolDescendantsCmd) (res []string, err error) {
+	var c *btcjson.GetMempoolDescendantsCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.GetMempoolDescendants", c, &res); Check(err) {
+	}
+	return
+}
+
+func (r *CAPIClient) GetMempoolEntry(cmd ...*btcjson.GetMempoolEntryCmd) (res btcjson.GetMempoolEntryResult, err error) {
+	var c *btcjson.GetMempoolEntryCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.GetMempoolEntry", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) GetMempoolInfo(cmd ...*None) (res btcjson.GetMempoolInfoResult, err error) {
 	var c *None
 	if len(cmd) > 0 {
@@ -3621,6 +4874,16 @@ func (r *CAPIClient) GetNetworkHashPS(cmd ...*btcjson.GetNetworkHashPSCmd) (res
 	return
 }
 
+func (r *CAPIClient) GetNetworkInfo(cmd ...*None) (res btcjson.GetNetworkInfoResult, err error) {
+	var c *None
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.GetNetworkInfo", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) GetPeerInfo(cmd ...*None) (res []btcjson.GetPeerInfoResult, err error) {
 	var c *None
 	if len(cmd) > 0 {
@@ -3631,6 +4894,16 @@ func (r *CAPIClient) GetPeerInfo(cmd ...*None) (res []btcjson.GetPeerInfoResult,
 	return
 }
 
+func (r *CAPIClient) GetPoolBalances(cmd ...*None) (res []btcjson.GetPoolBalancesResult, err error) {
+	var c *None
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.GetPoolBalances", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) GetRawMempool(cmd ...*btcjson.GetRawMempoolCmd) (res []string, err error) {
 	var c *btcjson.GetRawMempoolCmd
 	if len(cmd) > 0 {
@@ -3671,6 +4944,26 @@ func (r *CAPIClient) Help(cmd ...*btcjson.HelpCmd) (res string, err error) {
 	return
 }
 
+func (r *CAPIClient) InvalidateBlock(cmd ...*btcjson.InvalidateBlockCmd) (res None, err error) {
+	var c *btcjson.InvalidateBlockCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.InvalidateBlock", c, &res); Check(err) {
+	}
+	return
+}
+
+func (r *CAPIClient) LoadMempool(cmd ...*None) (res None, err error) {
+	var c *None
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.LoadMempool", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) Node(cmd ...*btcjson.NodeCmd) (res None, err error) {
 	var c *btcjson.NodeCmd
 	if len(cmd) > 0 {
@@ -3691,6 +4984,46 @@ func (r *CAPIClient) Ping(cmd ...*None) (res None, err error) {
 	return
 }
 
+func (r *CAPIClient) PoolPayout(cmd ...*btcjson.PoolPayoutCmd) (res btcjson.PoolPayoutResult, err error) {
+	var c *btcjson.PoolPayoutCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.PoolPayout", c, &res); Check(err) {
+	}
+	return
+}
+
+func (r *CAPIClient) PreciousBlock(cmd ...*btcjson.PreciousBlockCmd) (res None, err error) {
+	var c *btcjson.PreciousBlockCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.PreciousBlock", c, &res); Check(err) {
+	}
+	return
+}
+
+func (r *CAPIClient) PrioritiseTransaction(cmd ...*btcjson.PrioritiseTransactionCmd) (res bool, err error) {
+	var c *btcjson.PrioritiseTransactionCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.PrioritiseTransaction", c, &res); Check(err) {
+	}
+	return
+}
+
+func (r *CAPIClient) ReconsiderBlock(cmd ...*btcjson.ReconsiderBlockCmd) (res None, err error) {
+	var c *btcjson.ReconsiderBlockCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.ReconsiderBlock", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) ResetChain(cmd ...*None) (res None, err error) {
 	var c *None
 	if len(cmd) > 0 {
@@ -3711,6 +5044,16 @@ func (r *CAPIClient) Restart(cmd ...*None) (res None, err error) {
 	return
 }
 
+func (r *CAPIClient) SaveMempool(cmd ...*None) (res None, err error) {
+	var c *None
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.SaveMempool", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) SearchRawTransactions(cmd ...*btcjson.SearchRawTransactionsCmd) (res []btcjson.SearchRawTransactionsResult, err error) {
 	var c *btcjson.SearchRawTransactionsCmd
 	if len(cmd) > 0 {
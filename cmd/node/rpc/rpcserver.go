@@ -17,6 +17,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -38,6 +39,7 @@ import (
 	"github.com/p9c/pod/pkg/chain/wire"
 	database "github.com/p9c/pod/pkg/db"
 	p "github.com/p9c/pod/pkg/peer"
+	"github.com/p9c/pod/pkg/peer/addrmgr"
 	"github.com/p9c/pod/pkg/pod"
 	"github.com/p9c/pod/pkg/rpc/btcjson"
 	"github.com/p9c/pod/pkg/util"
@@ -112,6 +114,10 @@ type Server struct {
 	NumClients             int32
 	AuthSHA                [sha256.Size]byte
 	LimitAuthSHA           [sha256.Size]byte
+	// Pool is the share ledger of the miner controller when it is run as a
+	// pool, it is set by the controller after the server has started
+	Pool     PoolLedger
+	PoolLock sync.RWMutex
 }
 
 // ServerConfig is a descriptor containing the RPC server configuration.
@@ -133,6 +139,10 @@ type ServerConfig struct {
 	ConnMgr ServerConnManager
 	// SyncMgr defines the sync manager for the RPC server to use.
 	SyncMgr ServerSyncManager
+	// AddrManager holds the local addresses advertised to peers and Services
+	// the services the node offers them.
+	AddrManager *addrmgr.AddrManager
+	Services    wire.ServiceFlag
 	// These fields allow the RPC server to interface with the local block
 	// chain data and state.
 	TimeSource  blockchain.MedianTimeSource
@@ -227,6 +237,20 @@ type ServerPeer interface {
 	GetFeeFilter() int64
 }
 
+// PoolLedger is the share accounting of a miner controller that pays the
+// workers mining for it. The interface contract requires that all of these
+// methods are safe for concurrent access.
+type PoolLedger interface {
+	// PoolBalances returns the account of every worker with shares or
+	// rewards in the ledger.
+	PoolBalances() ([]btcjson.GetPoolBalancesResult, error)
+	// PoolPayout pays the mature balances of at least minAmount to the
+	// workers from the wallet, or only returns what would be paid if dryRun
+	// is set.
+	PoolPayout(minAmount util.Amount, dryRun bool) (btcjson.PoolPayoutResult,
+		error)
+}
+
 // ServerSyncManager represents a sync manager for use with the RPC server.
 // The interface contract requires that all of these methods are safe for
 // concurrent access.
@@ -288,6 +312,12 @@ var (
 		Code:    btcjson.ErrRPCUnimplemented,
 		Message: "Command unimplemented",
 	}
+	// ErrRPCNoPool is an error returned to RPC clients when a pool command
+	// is used while the miner controller is not keeping a share ledger.
+	ErrRPCNoPool = &btcjson.RPCError{
+		Code:    btcjson.ErrRPCMisc,
+		Message: "Pool accounting is not enabled, set --poolpayout",
+	}
 	// GBTCapabilities describes additional capabilities returned with a
 	// block template generated by the getblocktemplate RPC.
 	// It is declared here to avoid the overhead of creating the slice on
@@ -355,9 +385,11 @@ var (
 		"settxfee":               {},
 		"signmessage":            {},
 		"signrawtransaction":     {},
+		"walletcreatefundedpsbt": {},
 		"walletlock":             {},
 		"walletpassphrase":       {},
 		"walletpassphrasechange": {},
+		"walletprocesspsbt":      {},
 	}
 
 	// RPCHandlers maps RPC command strings to appropriate handler functions.
@@ -673,12 +705,17 @@ var (
 		"getblockheader":        {},
 		"getcfilter":            {},
 		"getcfilterheader":      {},
+		"getchaintips":          {},
 		"getcurrentnet":         {},
 		"getdifficulty":         {},
 		"getheaders":            {},
 		"getinfo":               {},
+		"getmempoolancestors":   {},
+		"getmempooldescendants": {},
+		"getmempoolentry":       {},
 		"getnettotals":          {},
 		"getnetworkhashps":      {},
+		"getnetworkinfo":        {},
 		"getrawmempool":         {},
 		"getrawtransaction":     {},
 		"gettxout":              {},
@@ -694,13 +731,7 @@ var (
 	// but should ultimately be.
 	RPCUnimplemented = map[string]struct{}{
 		"estimatepriority": {},
-		"getchaintips":     {},
-		"getmempoolentry":  {},
-		"getnetworkinfo":   {},
 		"getwork":          {},
-		"invalidateblock":  {},
-		"preciousblock":    {},
-		"reconsiderblock":  {},
 	}
 )
 
@@ -1084,6 +1115,21 @@ func (s *Server) RequestedProcessShutdown() <-chan struct{} {
 	return s.RequestProcessShutdown
 }
 
+// SetPoolLedger sets the pool share ledger queried by the pool commands.
+func (s *Server) SetPoolLedger(pool PoolLedger) {
+	s.PoolLock.Lock()
+	s.Pool = pool
+	s.PoolLock.Unlock()
+}
+
+// PoolLedger returns the pool share ledger, or nil if the miner controller is
+// not run as a pool.
+func (s *Server) PoolLedger() PoolLedger {
+	s.PoolLock.RLock()
+	defer s.PoolLock.RUnlock()
+	return s.Pool
+}
+
 // Start is used by server.go_ to start the rpc listener.
 func (s *Server) Start() {
 	if atomic.AddInt32(&s.Started, 1) != 1 {
@@ -1116,6 +1162,10 @@ func (s *Server) Start() {
 		// Read and respond to the request.
 		s.JSONRPCRead(w, r, isAdmin)
 	})
+	// The read-only REST interface, which needs no authentication.
+	if *s.Config.REST {
+		rpcServeMux.HandleFunc(RESTPath, s.HandleREST)
+	}
 	// Websocket endpoint.
 	rpcServeMux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
 		authenticated, isAdmin, err := s.CheckAuth(r, false)
@@ -1475,6 +1525,7 @@ func (s *Server) StandardCmdResult(cmd *ParsedRPCCmd,
 	}
 	return nil, btcjson.ErrRPCMethodNotFound
 handled:
+	defer rpcRequestSeconds.ObserveSince(time.Now(), cmd.Method)
 	return handler.Fn(s, cmd.Cmd, closeChan)
 }
 
@@ -1627,6 +1678,31 @@ func CreateMarshalledReply(id, result interface{}, replyErr error) ([]byte, erro
 	return btcjson.MarshalResponse(id, result, jsonErr)
 }
 
+// ScriptPubKeyResult returns the JSON description of a public key script.
+func ScriptPubKeyResult(pkScript []byte,
+	params *netparams.Params) btcjson.ScriptPubKeyResult {
+	// Disassemble script into single line printable format. The disassembled
+	// string will contain [error] inline if the script doesn't fully parse, so
+	// ignore the error here.
+	disbuf, _ := txscript.DisasmString(pkScript)
+	// Get further info about the script. Ignore the error here since an error
+	// means the script couldn't parse and there is no additional information
+	// about it anyways.
+	scriptClass, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(pkScript,
+		params)
+	addresses := make([]string, len(addrs))
+	for i, addr := range addrs {
+		addresses[i] = addr.EncodeAddress()
+	}
+	return btcjson.ScriptPubKeyResult{
+		Asm:       disbuf,
+		Hex:       hex.EncodeToString(pkScript),
+		ReqSigs:   int32(reqSigs),
+		Type:      scriptClass.String(),
+		Addresses: addresses,
+	}
+}
+
 // CreateTxRawResult converts the passed transaction and associated parameters
 // to a raw transaction JSON object.
 func CreateTxRawResult(chainParams *netparams.Params, mtx *wire.MsgTx,
@@ -1990,6 +2066,45 @@ func GetDifficultyRatio(bits uint32, params *netparams.Params,
 	return diff
 }
 
+// AlgoBlock is the latest block of a mining algorithm on the main chain.
+type AlgoBlock struct {
+	Name   string
+	Height int32
+	Bits   uint32
+}
+
+// LatestAlgoBlocks returns the latest block of each algorithm of the current
+// hard fork, sorted by algorithm name, searching at most maxDepth blocks back
+// from the tip of the main chain. Algorithms without a block in that range are
+// left out.
+func LatestAlgoBlocks(chain *blockchain.BlockChain, params *netparams.Params,
+	maxDepth int) []AlgoBlock {
+	best := chain.BestSnapshot()
+	forks := params.Forks
+	algos := forks.Fork(best.Height).Algos
+	found := make(map[string]AlgoBlock, len(algos))
+	node := chain.Index.LookupNode(&best.Hash)
+	for height, depth := best.Height, 0; node != nil &&
+		len(found) < len(algos) && depth < maxDepth; depth++ {
+		header := node.Header()
+		name := forks.AlgoName(header.Version, height)
+		if _, ok := found[name]; !ok {
+			found[name] = AlgoBlock{Name: name, Height: height,
+				Bits: header.Bits}
+		}
+		node = node.RelativeAncestor(1)
+		height--
+	}
+	blocks := make([]AlgoBlock, 0, len(found))
+	for _, b := range found {
+		blocks = append(blocks, b)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].Name < blocks[j].Name
+	})
+	return blocks
+}
+
 // NormalizeAddress returns addr with the passed default port appended if there
 // is not already a port specified.
 func NormalizeAddress(addr, defaultPort string) string {
@@ -2179,12 +2294,13 @@ func VerifyChain(s *Server, level, depth int32) error {
 
 			return err
 		}
-		powLimit := fork.GetMinDiff(fork.GetAlgoName(block.MsgBlock().Header.
+		forks := s.Cfg.ChainParams.Forks
+		powLimit := forks.MinDiff(forks.AlgoName(block.MsgBlock().Header.
 			Version, height), height)
 		// Level 1 does basic chain sanity checks.
 		if level > 0 {
-			err := blockchain.CheckBlockSanity(block, powLimit, s.Cfg.TimeSource,
-				true, block.Height())
+			err := blockchain.CheckBlockSanity(block, powLimit, forks,
+				s.Cfg.TimeSource, true, block.Height())
 			if err != nil {
 				Errorf(
 					"verify is unable to validate block at hash %v height %d: %v %s",
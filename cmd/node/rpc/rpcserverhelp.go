@@ -40,6 +40,11 @@ var HelpDescsEnUS = map[string]string{
 	// TransactionInput help.
 	"transactioninput-txid": "The hash of the input transaction",
 	"transactioninput-vout": "The specific output of the input transaction to redeem",
+	// CombinePSBTCmd help.
+	"combinepsbt--synopsis": "Combines the key-value pairs of several base64-encoded PSBTs for the same" +
+		" unsigned transaction, such as the signatures of the co-signers of a multisig input, into one.",
+	"combinepsbt-psbts":    "The base64-encoded PSBTs to combine",
+	"combinepsbt--result0": "The combined PSBT, base64-encoded",
 	// CreateRawTransactionCmd help.
 	"createrawtransaction--synopsis": "Returns a new transaction spending" +
 		" the provided inputs and sending to the provided addresses.\n" +
@@ -96,6 +101,55 @@ var HelpDescsEnUS = map[string]string{
 	"txrawdecoderesult-locktime": "The transaction lock time",
 	"txrawdecoderesult-vin":      "The transaction inputs as JSON objects",
 	"txrawdecoderesult-vout":     "The transaction outputs as JSON objects",
+	// PSBTScript help.
+	"psbtscript-asm":  "Disassembly of the script",
+	"psbtscript-hex":  "Hex-encoded bytes of the script",
+	"psbtscript-type": "The type of the script (e.g. 'multisig')",
+	// PSBTBip32Deriv help.
+	"psbtbip32deriv-pubkey":             "The hex-encoded public key",
+	"psbtbip32deriv-master_fingerprint": "The fingerprint of the master key",
+	"psbtbip32deriv-path":               "The derivation path of the public key, such as m/44'/0'/0'/0/1",
+	// PSBTWitnessUtxo help.
+	"psbtwitnessutxo-amount":       "The amount of the output in DUO",
+	"psbtwitnessutxo-scriptPubKey": "The public key script of the output as a JSON object",
+	// DecodePSBTInput help.
+	"decodepsbtinput-non_witness_utxo":          "The decoded transaction of the output the input spends",
+	"decodepsbtinput-witness_utxo":              "The output a witness input spends",
+	"decodepsbtinput-partial_signatures":        "The signatures of the input by public key",
+	"decodepsbtinput-partial_signatures--key":   "pubkey",
+	"decodepsbtinput-partial_signatures--value": "signature",
+	"decodepsbtinput-partial_signatures--desc":  "The hex-encoded signature by the hex-encoded public key",
+	"decodepsbtinput-sighash":                   "The signature hash type the input must be signed with",
+	"decodepsbtinput-redeem_script":             "The redeem script of a pay-to-script-hash input",
+	"decodepsbtinput-witness_script":            "The witness script of a pay-to-witness-script-hash input",
+	"decodepsbtinput-bip32_derivs":              "The derivations of the public keys needed to sign the input",
+	"decodepsbtinput-final_scriptSig":           "The final signature script of the input",
+	"decodepsbtinput-final_scriptwitness":       "The hex-encoded items of the final witness of the input",
+	"decodepsbtinput-unknown":                   "The key-value pairs of the input not known to this node",
+	"decodepsbtinput-unknown--key":              "key",
+	"decodepsbtinput-unknown--value":            "value",
+	"decodepsbtinput-unknown--desc":             "The hex-encoded value by the hex-encoded key",
+	// DecodePSBTOutput help.
+	"decodepsbtoutput-redeem_script":  "The redeem script of a pay-to-script-hash output",
+	"decodepsbtoutput-witness_script": "The witness script of a pay-to-witness-script-hash output",
+	"decodepsbtoutput-bip32_derivs":   "The derivations of the public keys needed to spend the output",
+	"decodepsbtoutput-unknown":        "The key-value pairs of the output not known to this node",
+	"decodepsbtoutput-unknown--key":   "key",
+	"decodepsbtoutput-unknown--value": "value",
+	"decodepsbtoutput-unknown--desc":  "The hex-encoded value by the hex-encoded key",
+	// DecodePSBTResult help.
+	"decodepsbtresult-tx":             "The decoded unsigned transaction",
+	"decodepsbtresult-unknown":        "The global key-value pairs not known to this node",
+	"decodepsbtresult-unknown--key":   "key",
+	"decodepsbtresult-unknown--value": "value",
+	"decodepsbtresult-unknown--desc":  "The hex-encoded value by the hex-encoded key",
+	"decodepsbtresult-inputs":         "The inputs of the PSBT",
+	"decodepsbtresult-outputs":        "The outputs of the PSBT",
+	"decodepsbtresult-fee": "The fee paid by the transaction in DUO, only present when the output" +
+		" spent by every input is known",
+	// DecodePSBTCmd help.
+	"decodepsbt--synopsis": "Returns a JSON object representing the provided base64-encoded PSBT.",
+	"decodepsbt-psbt":      "The base64-encoded PSBT",
 	// DecodeRawTransactionCmd help.
 	"decoderawtransaction--synopsis": "Returns a JSON object representing" +
 		" the provided serialized, hex-encoded transaction.",
@@ -120,12 +174,29 @@ var HelpDescsEnUS = map[string]string{
 		"generated before the transaction is mined.",
 	"estimatefee--result0": "Estimated fee per kilobyte in satoshis for a block to " +
 		"be mined in the next NumBlocks blocks.",
+	// FinalizePSBTResult help.
+	"finalizepsbtresult-psbt":     "The base64-encoded PSBT, if the transaction was not extracted",
+	"finalizepsbtresult-hex":      "The hex-encoded signed transaction, if it was extracted",
+	"finalizepsbtresult-complete": "Whether every input of the transaction is signed",
+	// FinalizePSBTCmd help.
+	"finalizepsbt--synopsis": "Finalizes the inputs of a base64-encoded PSBT that have all the signatures" +
+		" they need, and returns the signed transaction ready for sendrawtransaction if every input is.\n" +
+		"Inputs that can't be finalized yet are left as they are.",
+	"finalizepsbt-psbt":    "The base64-encoded PSBT",
+	"finalizepsbt-extract": "Return the signed transaction instead of the PSBT when it is complete",
 	// GenerateCmd help
 	"generate--synopsis": "Generates a set number of blocks (simnet or" +
 		" regtest only) and returns a JSON\n" +
 		" array of their hashes.",
 	"generate-numblocks": "Number of blocks to generate",
 	"generate--result0":  "The hashes, in order, of blocks generated by the call",
+	// GenerateAlgoCmd help
+	"generatealgo--synopsis": "Generates a set number of blocks with the" +
+		" algorithm of a block version (simnet or regtest only) and returns" +
+		" a JSON\n array of their hashes.",
+	"generatealgo-numblocks": "Number of blocks to generate",
+	"generatealgo-version":   "Block version of the algorithm to mine, which must exist at the height of each block",
+	"generatealgo--result0":  "The hashes, in order, of blocks generated by the call",
 	// GetAddedNodeInfoResultAddr help.
 	"getaddednodeinforesultaddr-address":   "The ip address for this DNS entry",
 	"getaddednodeinforesultaddr-connected": "The connection 'direction' (inbound/outbound/false)",
@@ -272,6 +343,8 @@ var HelpDescsEnUS = map[string]string{
 	"getblockheaderverboseresult-height":        "The height of the block in the block chain",
 	"getblockheaderverboseresult-version":       "The block version",
 	"getblockheaderverboseresult-versionHex":    "The block version in hexadecimal",
+	"getblockheaderverboseresult-pow_algo_id":   "The ID of the proof-of-work algorithm of the block",
+	"getblockheaderverboseresult-pow_algo":      "The name of the proof-of-work algorithm of the block",
 	"getblockheaderverboseresult-merkleroot":    "Root hash of the merkle tree",
 	"getblockheaderverboseresult-time":          "The block time in seconds since 1 Jan 1970 GMT",
 	"getblockheaderverboseresult-nonce":         "The block nonce",
@@ -369,6 +442,16 @@ var HelpDescsEnUS = map[string]string{
 	"getcfilterheader-hash":       "The hash of the block",
 	"getcfilterheader--result0":   "The block's gcs filter header",
 
+	// GetChainTipsResult help.
+	"getchaintipsresult-height":    "The height of the chain tip",
+	"getchaintipsresult-hash":      "The block hash of the chain tip",
+	"getchaintipsresult-branchlen": "The number of blocks since the branch forked from the main chain, zero for the main chain",
+	"getchaintipsresult-status": "The status of the branch, one of 'active'," +
+		" 'invalid', 'headers-only', 'valid-headers' or 'valid-fork'",
+
+	// GetChainTipsCmd help.
+	"getchaintips--synopsis": "Returns the tips of the main chain and of every side branch in the block index.",
+
 	// GetConnectionCountCmd help.
 	"getconnectioncount--synopsis": "Returns the number of active connections to other peers.",
 	"getconnectioncount--result0":  "The number of connections",
@@ -428,12 +511,58 @@ var HelpDescsEnUS = map[string]string{
 	// GetInfoCmd help.
 	"getinfo--synopsis": "Returns a JSON object containing various state info.",
 
+	// GetMempoolAncestorsCmd help.
+	"getmempoolancestors--synopsis":       "Returns the transactions in the memory pool that a transaction spends from, directly or through other transactions in the pool.",
+	"getmempoolancestors-txid":            "The hash of the transaction, which must be in the memory pool",
+	"getmempoolancestors-verbose":         "Returns a JSON object keyed by transaction hash when true or an array of transaction hashes when false",
+	"getmempoolancestors--condition0":     "verbose=false",
+	"getmempoolancestors--condition1":     "verbose=true",
+	"getmempoolancestors--result0":        "Array of transaction hashes",
+	"getmempoolancestors--result1--desc":  "The details of the transactions in the pool it spends from keyed by transaction hash",
+	"getmempoolancestors--result1--key":   "Transaction hash",
+	"getmempoolancestors--result1--value": "Object containing the details of the transaction, as returned by getmempoolentry",
+
+	// GetMempoolDescendantsCmd help.
+	"getmempooldescendants--synopsis":       "Returns the transactions in the memory pool that spend from a transaction, directly or through other transactions in the pool.",
+	"getmempooldescendants-txid":            "The hash of the transaction, which must be in the memory pool",
+	"getmempooldescendants-verbose":         "Returns a JSON object keyed by transaction hash when true or an array of transaction hashes when false",
+	"getmempooldescendants--condition0":     "verbose=false",
+	"getmempooldescendants--condition1":     "verbose=true",
+	"getmempooldescendants--result0":        "Array of transaction hashes",
+	"getmempooldescendants--result1--desc":  "The details of the transactions in the pool spending from it keyed by transaction hash",
+	"getmempooldescendants--result1--key":   "Transaction hash",
+	"getmempooldescendants--result1--value": "Object containing the details of the transaction, as returned by getmempoolentry",
+
+	// GetMempoolEntryCmd help.
+	"getmempoolentry--synopsis": "Returns the details of a transaction in the memory pool with the totals of its ancestors and descendants in the pool.",
+	"getmempoolentry-txid":      "The hash of the transaction, which must be in the memory pool",
+
+	// GetMempoolEntryResult help.
+	"getmempoolentryresult-size":             "The virtual size of the transaction",
+	"getmempoolentryresult-fee":              "Transaction fee in DUO",
+	"getmempoolentryresult-modifiedfee":      "Transaction fee in DUO used for mining priority",
+	"getmempoolentryresult-time":             "Local time transaction entered pool in seconds since 1 Jan 1970 GMT",
+	"getmempoolentryresult-height":           "Block height when transaction entered the pool",
+	"getmempoolentryresult-startingpriority": "Priority when transaction entered the pool",
+	"getmempoolentryresult-currentpriority":  "Current priority",
+	"getmempoolentryresult-descendantcount":  "Number of transactions in the pool that spend from this one, including itself",
+	"getmempoolentryresult-descendantsize":   "Virtual size of the transaction and its descendants in the pool",
+	"getmempoolentryresult-descendantfees":   "Fees in DUO of the transaction and its descendants in the pool",
+	"getmempoolentryresult-ancestorcount":    "Number of transactions in the pool this one spends from, including itself",
+	"getmempoolentryresult-ancestorsize":     "Virtual size of the transaction and its ancestors in the pool",
+	"getmempoolentryresult-ancestorfees":     "Fees in DUO of the transaction and its ancestors in the pool",
+	"getmempoolentryresult-depends":          "Unconfirmed transactions used as inputs for this transaction",
+
 	// GetMempoolInfoCmd help.
 	"getmempoolinfo--synopsis": "Returns memory pool information",
 
 	// GetMempoolInfoResult help.
-	"getmempoolinforesult-bytes": "Size in bytes of the mempool",
-	"getmempoolinforesult-size":  "Number of transactions in the mempool",
+	"getmempoolinforesult-bytes":         "Size in bytes of the mempool",
+	"getmempoolinforesult-size":          "Number of transactions in the mempool",
+	"getmempoolinforesult-usage":         "Total virtual size of the transactions in the mempool",
+	"getmempoolinforesult-maxmempool":    "Limit of the total virtual size of the transactions in the mempool, above which those paying the lowest fees are evicted",
+	"getmempoolinforesult-mempoolminfee": "Minimum fee rate in DUO/kB for a transaction to be accepted into the mempool, raised above minrelaytxfee when transactions are evicted",
+	"getmempoolinforesult-minrelaytxfee": "Minimum fee rate in DUO/kB for a transaction to be relayed",
 
 	// GetMiningInfoResult help.
 	"getmininginforesult-blocks":             "Height of the latest best block",
@@ -466,6 +595,36 @@ var HelpDescsEnUS = map[string]string{
 	"getnettotalsresult-totalbytessent": "Total bytes sent",
 	"getnettotalsresult-timemillis":     "Number of milliseconds since 1 Jan 1970 GMT",
 
+	// GetNetworkInfoCmd help.
+	"getnetworkinfo--synopsis": "Returns a JSON object containing information about the node's peer to peer networking.",
+
+	// GetNetworkInfoResult help.
+	"getnetworkinforesult-version":         "The version of the node as a numeric",
+	"getnetworkinforesult-subversion":      "The user agent the node sends to its peers",
+	"getnetworkinforesult-protocolversion": "The latest protocol version supported by the node",
+	"getnetworkinforesult-localservices":   "Services bitmask which represents the services the node offers",
+	"getnetworkinforesult-localrelay":      "Whether transactions are relayed to peers",
+	"getnetworkinforesult-timeoffset":      "The time offset of the node from its peers",
+	"getnetworkinforesult-connections":     "The number of connected peers",
+	"getnetworkinforesult-networkactive":   "Whether the peer to peer network is enabled",
+	"getnetworkinforesult-networks":        "Whether each kind of network can be reached and through which proxy",
+	"getnetworkinforesult-relayfee":        "Minimum fee per kilobyte in DUO for a transaction to be relayed",
+	"getnetworkinforesult-incrementalfee":  "Minimum fee per kilobyte in DUO a replacement transaction must add",
+	"getnetworkinforesult-localaddresses":  "Addresses the node advertises to its peers",
+	"getnetworkinforesult-warnings":        "Any network and blockchain warnings",
+
+	// NetworksResult help.
+	"networksresult-name":                        "The network, ipv4, ipv6 or onion",
+	"networksresult-limited":                     "Whether connections are not made to the network",
+	"networksresult-reachable":                   "Whether the network can be connected to",
+	"networksresult-proxy":                       "The proxy used to reach the network, if any",
+	"networksresult-proxy_randomize_credentials": "Whether random credentials are used for each proxy connection",
+
+	// LocalAddressesResult help.
+	"localaddressesresult-address": "The advertised address",
+	"localaddressesresult-port":    "The advertised port",
+	"localaddressesresult-score":   "How much the address is trusted to be the node's",
+
 	// GetPeerInfoResult help.
 	"getpeerinforesult-id":             "A unique node ID",
 	"getpeerinforesult-addr":           "The ip address and port of the peer",
@@ -492,6 +651,16 @@ var HelpDescsEnUS = map[string]string{
 	// GetPeerInfoCmd help.
 	"getpeerinfo--synopsis": "Returns data about each connected network peer as an array of json objects.",
 
+	// GetPoolBalancesResult help.
+	"getpoolbalancesresult-address":  "The address the worker's shares are credited to",
+	"getpoolbalancesresult-balance":  "Mature reward not yet paid out in DUO",
+	"getpoolbalancesresult-immature": "Reward from blocks that have not yet matured in DUO",
+	"getpoolbalancesresult-paid":     "Total paid out to the worker in DUO",
+	"getpoolbalancesresult-shares":   "Blocks worth of shares the next block found will be split over",
+
+	// GetPoolBalancesCmd help.
+	"getpoolbalances--synopsis": "Returns the share and reward account of each worker mining for the pool.",
+
 	// GetRawMempoolVerboseResult help.
 	"getrawmempoolverboseresult-size":             "Transaction size in bytes",
 	"getrawmempoolverboseresult-fee":              "Transaction fee in bitcoins",
@@ -540,10 +709,56 @@ var HelpDescsEnUS = map[string]string{
 	"help--result0":    "List of commands",
 	"help--result1":    "Help for specified command",
 
+	// InvalidateBlockCmd help.
+	"invalidateblock--synopsis": "Marks a block and its descendants as invalid," +
+		" disconnecting them if they are in the main chain.\n" +
+		"The branch with the most work that is not invalid becomes the main chain.",
+	"invalidateblock-blockhash": "The hash of the block to invalidate",
+
+	// LoadMempoolCmd help.
+	"loadmempool--synopsis": "Adds the transactions in the mempool file back to the memory pool, validating each of them again.\n" +
+		"The file is written by savemempool and when the node shuts down.",
+
 	// PingCmd help.
 	"ping--synopsis": "Queues a ping to be sent to each connected peer.\n" +
 		"Ping times are provided by getpeerinfo via the pingtime and pingwait fields.",
 
+	// PoolPayoutResult help.
+	"poolpayoutresult-txid":           "The hash of the payout transaction, empty for a dry run",
+	"poolpayoutresult-payouts":        "The amounts paid to each worker",
+	"poolpayoutresult-payouts--key":   "address",
+	"poolpayoutresult-payouts--value": "n.nnn",
+	"poolpayoutresult-payouts--desc":  "The amount in DUO paid to each address",
+
+	// PoolPayoutCmd help.
+	"poolpayout--synopsis": "Pays the mature balances of the pool workers from the wallet.\n" +
+		"Requires the wallet to be running in the same process as the node.",
+	"poolpayout-minamount": "The smallest balance in DUO to pay out",
+	"poolpayout-dryrun":    "Only return the payouts that would be made",
+
+	// PreciousBlockCmd help.
+	"preciousblock--synopsis": "Treats a block as if it were received before" +
+		" others with the same work, making it the tip of the main chain if its" +
+		" branch has at least as much work.",
+	"preciousblock-blockhash": "The hash of the block to mark as precious",
+
+	// PrioritiseTransactionCmd help.
+	"prioritisetransaction--synopsis":     "Changes the fee a transaction is treated as paying when it is chosen for a block, without changing the fee it pays.",
+	"prioritisetransaction-txid":          "The hash of the transaction, which does not need to be in the memory pool yet",
+	"prioritisetransaction-prioritydelta": "Unused, must be 0",
+	"prioritisetransaction-feedelta":      "The amount in satoshis to add to the fee, or to take from it when negative",
+	"prioritisetransaction--result0":      "Returns true",
+
+	// ReconsiderBlockCmd help.
+	"reconsiderblock--synopsis": "Removes the invalid status of a block, its" +
+		" descendants and ancestors, undoing invalidateblock.\n" +
+		"The branch with the most work becomes the main chain, with blocks" +
+		" that were not fully validated checked again.",
+	"reconsiderblock-blockhash": "The hash of the block to reconsider",
+
+	// SaveMempoolCmd help.
+	"savemempool--synopsis": "Writes the transactions in the memory pool to the mempool file, so that they can be loaded with loadmempool or when the node starts.",
+
 	// SearchRawTransactionsCmd help.
 	"searchrawtransactions--synopsis": "Returns raw data for transactions involving the passed address.\n" +
 		"Returned transactions are pulled from both the database, and transactions currently in the mempool.\n" +
@@ -703,12 +918,16 @@ var HelpDescsEnUS = map[string]string{
 // nolint
 var ResultTypes = map[string][]interface{}{
 	"addnode":               nil,
+	"combinepsbt":           {(*string)(nil)},
 	"createrawtransaction":  {(*string)(nil)},
 	"debuglevel":            {(*string)(nil), (*string)(nil)},
+	"decodepsbt":            {(*btcjson.DecodePSBTResult)(nil)},
 	"decoderawtransaction":  {(*btcjson.TxRawDecodeResult)(nil)},
 	"decodescript":          {(*btcjson.DecodeScriptResult)(nil)},
 	"estimatefee":           {(*float64)(nil)},
+	"finalizepsbt":          {(*btcjson.FinalizePSBTResult)(nil)},
 	"generate":              {(*[]string)(nil)},
+	"generatealgo":          {(*[]string)(nil)},
 	"getaddednodeinfo":      {(*[]string)(nil), (*[]btcjson.GetAddedNodeInfoResult)(nil)},
 	"getbestblock":          {(*btcjson.GetBestBlockResult)(nil)},
 	"getbestblockhash":      {(*string)(nil)},
@@ -720,6 +939,7 @@ var ResultTypes = map[string][]interface{}{
 	"getblockchaininfo":     {(*btcjson.GetBlockChainInfoResult)(nil)},
 	"getcfilter":            {(*string)(nil)},
 	"getcfilterheader":      {(*string)(nil)},
+	"getchaintips":          {(*[]btcjson.GetChainTipsResult)(nil)},
 	"getconnectioncount":    {(*int32)(nil)},
 	"getcurrentnet":         {(*uint32)(nil)},
 	"getdifficulty":         {(*float64)(nil)},
@@ -727,17 +947,29 @@ var ResultTypes = map[string][]interface{}{
 	"gethashespersec":       {(*float64)(nil)},
 	"getheaders":            {(*[]string)(nil)},
 	"getinfo":               {(*btcjson.InfoChainResult)(nil)},
+	"getmempoolancestors":   {(*[]string)(nil), (*map[string]btcjson.GetMempoolEntryResult)(nil)},
+	"getmempooldescendants": {(*[]string)(nil), (*map[string]btcjson.GetMempoolEntryResult)(nil)},
+	"getmempoolentry":       {(*btcjson.GetMempoolEntryResult)(nil)},
 	"getmempoolinfo":        {(*btcjson.GetMempoolInfoResult)(nil)},
 	"getmininginfo":         {(*btcjson.GetMiningInfoResult)(nil)},
 	"getnettotals":          {(*btcjson.GetNetTotalsResult)(nil)},
 	"getnetworkhashps":      {(*int64)(nil)},
+	"getnetworkinfo":        {(*btcjson.GetNetworkInfoResult)(nil)},
 	"getpeerinfo":           {(*[]btcjson.GetPeerInfoResult)(nil)},
+	"getpoolbalances":       {(*[]btcjson.GetPoolBalancesResult)(nil)},
 	"getrawmempool":         {(*[]string)(nil), (*btcjson.GetRawMempoolVerboseResult)(nil)},
 	"getrawtransaction":     {(*string)(nil), (*btcjson.TxRawResult)(nil)},
 	"gettxout":              {(*btcjson.GetTxOutResult)(nil)},
+	"invalidateblock":       nil,
 	"node":                  nil,
 	"help":                  {(*string)(nil), (*string)(nil)},
+	"loadmempool":           nil,
 	"ping":                  nil,
+	"poolpayout":            {(*btcjson.PoolPayoutResult)(nil)},
+	"preciousblock":         nil,
+	"prioritisetransaction": {(*bool)(nil)},
+	"reconsiderblock":       nil,
+	"savemempool":           nil,
 	"searchrawtransactions": {(*string)(nil), (*[]btcjson.SearchRawTransactionsResult)(nil)},
 	"sendrawtransaction":    {(*string)(nil)},
 	"setgenerate":           nil,
@@ -11,6 +11,7 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strconv"
@@ -24,6 +25,7 @@ import (
 	log "github.com/p9c/pod/pkg/logi"
 
 	"github.com/p9c/pod/cmd/node/mempool"
+	"github.com/p9c/pod/cmd/node/pubsub"
 	"github.com/p9c/pod/cmd/node/state"
 	"github.com/p9c/pod/cmd/node/upnp"
 	"github.com/p9c/pod/cmd/node/version"
@@ -33,6 +35,7 @@ import (
 	"github.com/p9c/pod/pkg/chain/fork"
 	chainhash "github.com/p9c/pod/pkg/chain/hash"
 	indexers "github.com/p9c/pod/pkg/chain/index"
+	"github.com/p9c/pod/pkg/chain/mining"
 	netsync "github.com/p9c/pod/pkg/chain/sync"
 	txscript "github.com/p9c/pod/pkg/chain/tx/script"
 	"github.com/p9c/pod/pkg/chain/wire"
@@ -157,6 +160,9 @@ type (
 		// The fee estimator keeps track of how long transactions are left in
 		// the mempool before they are mined into blocks.
 		FeeEstimator *mempool.FeeEstimator
+		// PubSub publishes new blocks, transactions and reorgs to subscribers.
+		// It is nil if no notification endpoints are configured.
+		PubSub *pubsub.Notifier
 		// CFCheckptCaches stores a cached slice of filter headers for
 		// cfcheckpt messages for each filter type.
 		CFCheckptCaches    map[wire.FilterType][]CFHeaderKV
@@ -191,6 +197,9 @@ type (
 		IsWhitelisted  bool
 		Persistent     bool
 		DisableRelayTx bool
+		// SentFeeFilter is the minimum fee rate last advertised to the peer
+		// with a feefilter message, used only by the peer handler.
+		SentFeeFilter int64
 	}
 	// SimpleAddr implements the net.Addr interface with two struct fields
 	SimpleAddr struct {
@@ -223,6 +232,13 @@ const (
 	// retries when connecting to persistent peers.  It is adjusted by the
 	// number of retries such that there is a retry backoff.
 	ConnectionRetryInterval = time.Second
+	// MempoolFileName is the name of the file in the network data directory
+	// the mempool is saved to.
+	MempoolFileName = "mempool.dat"
+	// FeeFilterInterval is the time in between checks for whether the
+	// minimum fee rate of the mempool has changed and must be advertised to
+	// peers again.
+	FeeFilterInterval = time.Minute
 )
 
 var (
@@ -319,6 +335,10 @@ func (n *Node) AnnounceNewTransactions(txns []*mempool.TxDesc) {
 			n.RPCServers[i].NotifyNewTransactions(txns)
 		}
 	}
+	// Publish the transactions to the notification subscribers.
+	if n.PubSub != nil {
+		n.PubSub.NotifyNewTransactions(txns)
+	}
 }
 
 // BanPeer bans a peer that has already been connected to the server by ip.
@@ -427,6 +447,13 @@ func (n *Node) Start() {
 	Trace("starting server")
 	// Server startup time. Used for the uptime command for uptime calculation.
 	n.StartupTime = time.Now().Unix()
+	// Add the transactions that were in the mempool when the node last shut
+	// down back to it.
+	if _, err := n.TxMemPool.LoadFile(n.Chain,
+		MempoolFile(n.Config, n.ChainParams)); err != nil &&
+		!os.IsNotExist(err) {
+		Warn("failed to load the mempool:", err)
+	}
 	// Start the peer handler which in turn starts the address and block
 	// managers.
 	n.WG.Add(1)
@@ -435,6 +462,9 @@ func (n *Node) Start() {
 		n.WG.Add(1)
 		go n.UPNPUpdateThread()
 	}
+	if n.PubSub != nil {
+		n.PubSub.Start()
+	}
 	if !*n.Config.DisableRPC {
 		n.WG.Add(1)
 		// Start the rebroadcastHandler, which ensures user tx received by the
@@ -483,6 +513,14 @@ func (n *Node) Stop() (err error) {
 			}
 		}
 	}
+	// Disconnect the notification subscribers.
+	if n.PubSub != nil {
+		n.PubSub.Stop()
+	}
+	// Save the mempool so that it can be loaded when the node starts again.
+	if _, err = n.TxMemPool.SaveFile(MempoolFile(n.Config,
+		n.ChainParams)); Check(err) {
+	}
 	// Save fee estimator state in the database.
 	if err = n.DB.Update(func(tx database.Tx) error {
 		metadata := tx.Metadata()
@@ -617,6 +655,24 @@ func (n *Node) HandleBroadcastMsg(state *PeerState, bmsg *BroadcastMsg) {
 	})
 }
 
+// HandleFeeFilterUpdate advertises the minimum fee rate of the mempool with a
+// feefilter message to the peers that support it and haven't been sent the
+// current rate yet. It is invoked from the peerHandler goroutine.
+func (n *Node) HandleFeeFilterUpdate(state *PeerState) {
+	if *n.Config.BlocksOnly {
+		return
+	}
+	minFee := int64(n.TxMemPool.MinFee())
+	state.ForAllPeers(func(sp *NodePeer) {
+		if !sp.Connected() || sp.SentFeeFilter == minFee ||
+			sp.ProtocolVersion() < wire.FeeFilterVersion {
+			return
+		}
+		sp.QueueMessage(wire.NewMsgFeeFilter(minFee), nil)
+		sp.SentFeeFilter = minFee
+	})
+}
+
 // HandleDonePeerMsg deals with peers that have signalled they are done.  It is
 // invoked from the peerHandler goroutine.
 func (n *Node) HandleDonePeerMsg(state *PeerState, sp *NodePeer) {
@@ -779,10 +835,38 @@ func (n *Node) HandleQuery(state *PeerState, querymsg interface{}) {
 // HandleRelayInvMsg deals with relaying inventory to peers that are not
 // already known to have it.  It is invoked from the peerHandler goroutine.
 func (n *Node) HandleRelayInvMsg(state *PeerState, msg RelayMsg) {
+	// The compact blocks sent to peers that asked for new blocks that way are
+	// only made once for each version.
+	cmpctBlocks := make(map[uint64]*wire.MsgCmpctBlock)
+	cmpctBlock := func(version uint64) *wire.MsgCmpctBlock {
+		if cb, ok := cmpctBlocks[version]; ok {
+			return cb
+		}
+		cb, err := n.NewCmpctBlockMsg(&msg.InvVect.Hash, version)
+		if err != nil {
+			Error(err)
+		}
+		cmpctBlocks[version] = cb
+		return cb
+	}
 	state.ForAllPeers(func(sp *NodePeer) {
 		if !sp.Connected() {
 			return
 		}
+		// If the inventory is a block and the peer asked for new blocks as
+		// compact blocks, send it one right away instead of announcing it.
+		if msg.InvVect.Type == wire.InvTypeBlock && sp.WantsCmpctBlocks() {
+			if sp.HasKnownInventory(msg.InvVect) {
+				return
+			}
+			version := sp.CmpctBlockVersion()
+			if cb := cmpctBlock(version); cb != nil {
+				sp.AddKnownInventory(msg.InvVect)
+				sp.QueueMessageWithEncoding(cb, nil,
+					CmpctBlockEncoding(version))
+				return
+			}
+		}
 		// If the inventory is a block and the peer prefers headers, generate and
 		// send a headers message instead of an inventory message.
 		if msg.InvVect.Type == wire.InvTypeBlock && sp.WantsHeaders() {
@@ -938,6 +1022,8 @@ func (n *Node) PeerHandler() {
 	}
 	Trace("starting connmgr")
 	go n.ConnManager.Start()
+	feeFilterTicker := time.NewTicker(FeeFilterInterval)
+	defer feeFilterTicker.Stop()
 out:
 	for {
 		select {
@@ -962,6 +1048,9 @@ out:
 			n.HandleBroadcastMsg(peerState, &bmsg)
 		case qmsg := <-n.Query:
 			n.HandleQuery(peerState, qmsg)
+		// The minimum fee rate of the mempool may have changed.
+		case <-feeFilterTicker.C:
+			n.HandleFeeFilterUpdate(peerState)
 		case <-n.Quit:
 			// Disconnect all peers on server shutdown.
 			peerState.ForAllPeers(func(sp *NodePeer) {
@@ -1060,6 +1149,59 @@ func (n *Node) PushBlockMsg(sp *NodePeer, hash *chainhash.Hash,
 	return nil
 }
 
+// NewCmpctBlockMsg returns a cmpctblock message of the given compact block
+// version for the main chain block with the provided hash.
+func (n *Node) NewCmpctBlockMsg(hash *chainhash.Hash,
+	version uint64) (*wire.MsgCmpctBlock, error) {
+	blk, err := n.Chain.BlockByHash(hash)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	nonce, err := wire.RandomUint64()
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return wire.NewMsgCmpctBlockFromBlock(blk.MsgBlock(), nonce, version), nil
+}
+
+// CmpctBlockEncoding returns the encoding of the transactions in cmpctblock
+// and blocktxn messages of the given compact block version.
+func CmpctBlockEncoding(version uint64) wire.MessageEncoding {
+	if version == wire.CmpctBlockWitnessVersion {
+		return wire.WitnessEncoding
+	}
+	return wire.BaseEncoding
+}
+
+// PushCmpctBlockMsg sends a cmpctblock message for the provided block hash to
+// the connected peer, of the compact block version agreed with it.  An error
+// is returned if the block hash is not known.
+func (n *Node) PushCmpctBlockMsg(sp *NodePeer, hash *chainhash.Hash,
+	doneChan chan<- struct{}, waitChan <-chan struct{}) error {
+	version := sp.CmpctBlockVersion()
+	if version == 0 {
+		version = wire.CmpctBlockVersion
+	}
+	cmpctBlock, err := n.NewCmpctBlockMsg(hash, version)
+	if err != nil {
+		Errorf("unable to fetch requested block hash %v: %v",
+			hash, err)
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return err
+	}
+	// Once we have fetched data wait for any previous operation to finish.
+	if waitChan != nil {
+		<-waitChan
+	}
+	sp.QueueMessageWithEncoding(cmpctBlock, doneChan,
+		CmpctBlockEncoding(version))
+	return nil
+}
+
 // PushMerkleBlockMsg sends a merkleblock message for the provided block hash
 // to the connected peer.  Since a merkle block requires the peer to have a
 // filter loaded, this call will simply be ignored if there is no filter
@@ -1319,6 +1461,25 @@ func (np *NodePeer) OnBlock(_ *peer.Peer, msg *wire.MsgBlock, buf []byte) {
 	<-np.BlockProcessed
 }
 
+// OnBlockTxn is invoked when a peer receives a blocktxn bitcoin message with
+// the transactions of a block it sent as a compact block that were not in the
+// mempool.  It blocks until the completed block has been fully processed.
+func (np *NodePeer) OnBlockTxn(_ *peer.Peer, msg *wire.MsgBlockTxn) {
+	np.Server.SyncManager.QueueBlockTxn(msg, np.Peer, np.BlockProcessed)
+	<-np.BlockProcessed
+}
+
+// OnCmpctBlock is invoked when a peer receives a cmpctblock bitcoin message.
+// It blocks until the block has been reconstructed from the mempool and fully
+// processed, or the transactions that are missing have been requested.
+func (np *NodePeer) OnCmpctBlock(_ *peer.Peer, msg *wire.MsgCmpctBlock) {
+	// Add the block to the known inventory for the peer.
+	hash := msg.Header.BlockHash()
+	np.AddKnownInventory(wire.NewInvVect(wire.InvTypeBlock, &hash))
+	np.Server.SyncManager.QueueCmpctBlock(msg, np.Peer, np.BlockProcessed)
+	<-np.BlockProcessed
+}
+
 // OnFeeFilter is invoked when a peer receives a feefilter bitcoin message and
 // is used by remote peers to request that no transactions which have a fee
 // rate lower than provided value are inventoried to them.  The peer will be
@@ -1743,6 +1904,36 @@ func (np *NodePeer) OnGetCFilters(_ *peer.Peer,
 	}
 }
 
+// OnGetBlockTxn is invoked when a peer receives a getblocktxn bitcoin message
+// and is used to deliver the transactions of a block sent as a compact block
+// that the peer could not find in its mempool.  The peer will be disconnected
+// if it asks for transactions the block doesn't have.
+func (np *NodePeer) OnGetBlockTxn(_ *peer.Peer,
+	msg *wire.MsgGetBlockTxn) {
+	blk, err := np.Server.Chain.BlockByHash(&msg.BlockHash)
+	if err != nil {
+		Debugf("unable to fetch block %v requested by %v: %v",
+			msg.BlockHash, np, err)
+		return
+	}
+	txs := blk.MsgBlock().Transactions
+	blockTxn := wire.NewMsgBlockTxn(&msg.BlockHash)
+	for _, index := range msg.Indexes {
+		if int(index) >= len(txs) {
+			Debugf("peer %v requested transaction %d of block %v which "+
+				"has %d -- disconnecting", np, index, msg.BlockHash, len(txs))
+			np.Disconnect()
+			return
+		}
+		if err := blockTxn.AddTransaction(txs[index]); err != nil {
+			Error(err)
+			return
+		}
+	}
+	np.QueueMessageWithEncoding(blockTxn, nil,
+		CmpctBlockEncoding(np.CmpctBlockVersion()))
+}
+
 // handleGetData is invoked when a peer receives a getdata bitcoin message and
 // is used to deliver block and transaction information.
 func (np *NodePeer) OnGetData(_ *peer.Peer,
@@ -1786,6 +1977,8 @@ func (np *NodePeer) OnGetData(_ *peer.Peer,
 		case wire.InvTypeBlock:
 			err = np.Server.PushBlockMsg(np, &iv.Hash, c, waitChan,
 				wire.BaseEncoding)
+		case wire.InvTypeCmpctBlock:
+			err = np.Server.PushCmpctBlockMsg(np, &iv.Hash, c, waitChan)
 		case wire.InvTypeFilteredWitnessBlock:
 			err = np.Server.PushMerkleBlockMsg(np, &iv.Hash, c, waitChan,
 				wire.WitnessEncoding)
@@ -2512,9 +2705,12 @@ NewPeerConfig(sp *NodePeer) *peer.Config {
 			OnMemPool:      sp.OnMemPool,
 			OnTx:           sp.OnTx,
 			OnBlock:        sp.OnBlock,
+			OnCmpctBlock:   sp.OnCmpctBlock,
+			OnBlockTxn:     sp.OnBlockTxn,
 			OnInv:          sp.OnInv,
 			OnHeaders:      sp.OnHeaders,
 			OnGetData:      sp.OnGetData,
+			OnGetBlockTxn:  sp.OnGetBlockTxn,
 			OnGetBlocks:    sp.OnGetBlocks,
 			OnGetHeaders:   sp.OnGetHeaders,
 			OnGetCFilters:  sp.OnGetCFilters,
@@ -2548,6 +2744,12 @@ NewPeerConfig(sp *NodePeer) *peer.Config {
 	}
 }
 
+// MempoolFile returns the path of the file the mempool is saved to when the
+// node shuts down and loaded from when it starts.
+func MempoolFile(cfg *pod.Config, params *netparams.Params) string {
+	return filepath.Join(*cfg.DataDir, params.Name, MempoolFileName)
+}
+
 type Context struct {
 	// Config is the pod all-in-one server config
 	Config *pod.Config
@@ -2573,11 +2775,40 @@ NewNode(listenAddrs []string, db database.DB,
 	if *cx.Config.NoCFilters {
 		services &^= wire.SFNodeCF
 	}
+	// A pruned node can only serve recent blocks, and can't build indexes
+	// that need all of them.
+	if *cx.Config.Prune != 0 {
+		if *cx.Config.TxIndex || *cx.Config.AddrIndex {
+			return nil, errors.New("pruning is incompatible with the " +
+				"transaction and address indexes")
+		}
+		services &^= wire.SFNodeNetwork
+		services |= wire.SFNodeNetworkLimited
+	}
+	// A chain started from a utxo snapshot doesn't have the blocks before it
+	// either, and the indexes would have to be built from them.
+	snapshot, err := blockchain.FetchUtxoSnapshot(db)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	if snapshot != nil {
+		if *cx.Config.TxIndex || *cx.Config.AddrIndex {
+			return nil, errors.New("a chain started from a utxo snapshot " +
+				"can't have the transaction and address indexes")
+		}
+		if !*cx.Config.NoCFilters {
+			Warn("committed filter index is disabled on a chain started " +
+				"from a utxo snapshot")
+			*cx.Config.NoCFilters = true
+		}
+		services &^= wire.SFNodeNetwork | wire.SFNodeCF
+		services |= wire.SFNodeNetworkLimited
+	}
 	aMgr := addrmgr.New(*cx.Config.DataDir+string(os.PathSeparator)+cx.ActiveNet.Name, Lookup(cx.StateCfg))
 	var listeners []net.Listener
 	var nat upnp.NAT
 	if !*cx.Config.DisableListen {
-		var err error
 		listeners, nat, err = InitListeners(cx.Config, cx.ActiveNet, aMgr, listenAddrs, services)
 		if err != nil {
 			Error(err)
@@ -2661,7 +2892,6 @@ NewNode(listenAddrs []string, db database.DB,
 			s.ChainParams.Checkpoints, cx.StateCfg.AddedCheckpoints)
 	}
 	// Create a new block chain instance with the appropriate configuration.
-	var err error
 	s.Chain, err = blockchain.New(
 		&blockchain.Config{
 			DB:           s.DB,
@@ -2672,6 +2902,7 @@ NewNode(listenAddrs []string, db database.DB,
 			SigCache:     s.SigCache,
 			IndexManager: indexManager,
 			HashCache:    s.HashCache,
+			PruneTarget:  uint64(*cx.Config.Prune) << 20,
 		},
 	)
 	if err != nil {
@@ -2724,6 +2955,12 @@ NewNode(listenAddrs []string, db database.DB,
 			MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
 			MinRelayTxFee:        cx.StateCfg.ActiveMinRelayTxFee,
 			MaxTxVersion:         2,
+			RejectReplacement:    *cx.Config.RejectReplacement,
+			MaxMempoolSize:       int64(*cx.Config.MaxMempoolSize) * 1024 * 1024,
+			MaxAncestorCount:     mempool.DefaultMaxAncestorCount,
+			MaxAncestorSize:      mempool.DefaultMaxAncestorSize,
+			MaxDescendantCount:   mempool.DefaultMaxDescendantCount,
+			MaxDescendantSize:    mempool.DefaultMaxDescendantSize,
 		},
 		ChainParams:   cx.ActiveNet,
 		FetchUtxoView: s.Chain.FetchUtxoView,
@@ -2760,21 +2997,20 @@ NewNode(listenAddrs []string, db database.DB,
 		Error(err)
 		return nil, err
 	}
-	// // Create the mining policy and block template generator based on the
-	// // configuration options.
-	// // NOTE: The CPU miner relies on the mempool, so the mempool has to be
-	// // created before calling the function to create the CPU miner.
-	// policy := mining.Policy{
-	// 	BlockMinWeight:    uint32(*config.BlockMinWeight),
-	// 	BlockMaxWeight:    uint32(*config.BlockMaxWeight),
-	// 	BlockMinSize:      uint32(*config.BlockMinSize),
-	// 	BlockMaxSize:      uint32(*config.BlockMaxSize),
-	// 	BlockPrioritySize: uint32(*config.BlockPrioritySize),
-	// 	TxMinFreeFee:      stateCfg.ActiveMinRelayTxFee,
-	// }
-	// blockTemplateGenerator := mining.NewBlkTmplGenerator(&policy,
-	// 	s.ChainParams, s.TxMemPool, s.Chain, s.TimeSource,
-	// 	s.SigCache, s.HashCache, s.Algo)
+	// Create the mining policy and block template generator based on the
+	// configuration options. The generator relies on the mempool, so the
+	// mempool has to be created before it.
+	policy := mining.Policy{
+		BlockMinWeight:    uint32(*cx.Config.BlockMinWeight),
+		BlockMaxWeight:    uint32(*cx.Config.BlockMaxWeight),
+		BlockMinSize:      uint32(*cx.Config.BlockMinSize),
+		BlockMaxSize:      uint32(*cx.Config.BlockMaxSize),
+		BlockPrioritySize: uint32(*cx.Config.BlockPrioritySize),
+		TxMinFreeFee:      cx.StateCfg.ActiveMinRelayTxFee,
+	}
+	blockTemplateGenerator := mining.NewBlkTmplGenerator(&policy,
+		s.ChainParams, s.TxMemPool, s.Chain, s.TimeSource,
+		s.SigCache, s.HashCache)
 	// s.CPUMiner = cpuminer.New(&cpuminer.Config{
 	// 	Blockchain:             s.Chain,
 	// 	ChainParams:            chainParams,
@@ -2882,16 +3118,18 @@ NewNode(listenAddrs []string, db database.DB,
 				return nil, errors.New("RPCS: No valid listen address")
 			}
 			rp, err := NewRPCServer(&ServerConfig{
-				Listeners:   rpcListeners,
-				StartupTime: s.StartupTime,
-				ConnMgr:     &ConnManager{&s},
-				SyncMgr:     &SyncManager{&s, s.SyncManager},
-				TimeSource:  s.TimeSource,
-				Chain:       s.Chain,
-				ChainParams: cx.ActiveNet,
-				DB:          db,
-				TxMemPool:   s.TxMemPool,
-				// Generator:    blockTemplateGenerator,
+				Listeners:    rpcListeners,
+				StartupTime:  s.StartupTime,
+				ConnMgr:      &ConnManager{&s},
+				SyncMgr:      &SyncManager{&s, s.SyncManager},
+				AddrManager:  s.AddrManager,
+				Services:     s.Services,
+				TimeSource:   s.TimeSource,
+				Chain:        s.Chain,
+				ChainParams:  cx.ActiveNet,
+				DB:           db,
+				TxMemPool:    s.TxMemPool,
+				Generator:    blockTemplateGenerator,
 				CPUMiner:     s.CPUMiner,
 				TxIndex:      s.TxIndex,
 				AddrIndex:    s.AddrIndex,
@@ -2914,6 +3152,26 @@ NewNode(listenAddrs []string, db database.DB,
 			// interrupt.Request()
 		}()
 	}
+	// Publish new blocks, transactions and reorgs on the configured
+	// notification endpoints.
+	pubEndpoints := map[string][]string{
+		pubsub.TopicHashBlock: *cx.Config.PubHashBlock,
+		pubsub.TopicHashTx:    *cx.Config.PubHashTx,
+		pubsub.TopicRawBlock:  *cx.Config.PubRawBlock,
+		pubsub.TopicRawTx:     *cx.Config.PubRawTx,
+		pubsub.TopicReorg:     *cx.Config.PubReorg,
+	}
+	for _, endpoints := range pubEndpoints {
+		if len(endpoints) == 0 {
+			continue
+		}
+		if s.PubSub, err = pubsub.New(pubEndpoints); err != nil {
+			Error(err)
+			return nil, err
+		}
+		s.Chain.Subscribe(s.PubSub.HandleBlockchainNotification)
+		break
+	}
 	return &s, nil
 }
 
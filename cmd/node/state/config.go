@@ -21,5 +21,7 @@ type Config struct {
 	DropAddrIndex       bool
 	DropTxIndex         bool
 	DropCfIndex         bool
+	LoadUtxoSnapshot    string
+	UtxoSnapshotHash    string
 	Save                bool
 }
@@ -0,0 +1,95 @@
+package node
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/conte"
+	database "github.com/p9c/pod/pkg/db"
+)
+
+// DumpUtxoSnapshot writes a snapshot of the utxo set of the main chain at the
+// given height, or at the best block when it is negative, to a file that a new
+// node can be started from with loadutxo. When no file is given it is written
+// into the directory of the network as utxo-<height>.dat.
+func DumpUtxoSnapshot(cx *conte.Xt, height int32, file string) (err error) {
+	var db database.DB
+	if db, err = loadBlockDB(cx); Check(err) {
+		return
+	}
+	defer func() {
+		if err := db.Close(); Check(err) {
+		}
+	}()
+	var chain *blockchain.BlockChain
+	chain, err = blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: cx.ActiveNet,
+		TimeSource:  blockchain.NewMedianTime(),
+	})
+	if Check(err) {
+		return
+	}
+	if height < 0 {
+		height = chain.BestSnapshot().Height
+	}
+	if file == "" {
+		file = filepath.Join(*cx.Config.DataDir, cx.ActiveNet.Name,
+			fmt.Sprintf("utxo-%d.dat", height))
+	}
+	var f *os.File
+	if f, err = os.Create(file); Check(err) {
+		return
+	}
+	w := bufio.NewWriter(f)
+	var snapshot *blockchain.UtxoSnapshot
+	if snapshot, err = chain.DumpUtxoSnapshot(w, height); !Check(err) {
+		err = w.Flush()
+	}
+	if e := f.Close(); Check(e) && err == nil {
+		err = e
+	}
+	if err != nil {
+		if e := os.Remove(file); Check(e) {
+		}
+		return
+	}
+	Infof("wrote the utxo snapshot of block %v at height %d to '%s', the "+
+		"hash of its utxo set is %v", snapshot.Hash, snapshot.Height, file,
+		snapshot.UtxoHash)
+	return
+}
+
+// loadUtxoSnapshot fills the empty block database from the utxo snapshot that
+// was requested with loadutxo. The utxo set of the snapshot must match the hash
+// given along with it, or the one in the chain parameters for its height.
+func loadUtxoSnapshot(cx *conte.Xt, db database.DB) (err error) {
+	var utxoHash *chainhash.Hash
+	if cx.StateCfg.UtxoSnapshotHash != "" {
+		if utxoHash, err = chainhash.NewHashFromStr(
+			cx.StateCfg.UtxoSnapshotHash); Check(err) {
+			return
+		}
+	}
+	var f *os.File
+	if f, err = os.Open(cx.StateCfg.LoadUtxoSnapshot); Check(err) {
+		return
+	}
+	defer func() {
+		if err := f.Close(); Check(err) {
+		}
+	}()
+	Infof("loading the utxo snapshot from '%s'", cx.StateCfg.LoadUtxoSnapshot)
+	var snapshot *blockchain.UtxoSnapshot
+	if snapshot, err = blockchain.LoadUtxoSnapshot(db, bufio.NewReader(f),
+		cx.ActiveNet, utxoHash); Check(err) {
+		return
+	}
+	Infof("loaded the utxo snapshot of block %v at height %d with %d utxos",
+		snapshot.Hash, snapshot.Height, snapshot.Coins)
+	return
+}
@@ -1635,16 +1635,12 @@ func // startSync will choose the best peer among the available candidate
 			peers.Remove(e)
 			continue
 		}
-		var lastping int64
-		if bestPeer == nil || sp.LastBlock() > bestPeer.LastBlock() {
-			lp := sp.LastPingMicros()
-			// prefer the peer with the lowest ping, only update if it is
-			// lower than the less up to date one (or if equal in block, ping
-			// becomes the criteria)
-			if lp < lastping {
-				bestPeer = sp
-				lastping = lp
-			}
+		// prefer the peer with the latest block, and of peers with the same
+		// block, the one with the lowest ping
+		if bestPeer == nil || sp.LastBlock() > bestPeer.LastBlock() ||
+			(sp.LastBlock() == bestPeer.LastBlock() &&
+				sp.LastPingMicros() < bestPeer.LastPingMicros()) {
+			bestPeer = sp
 		}
 	}
 	// Start syncing from the best peer if one was selected.
@@ -2227,18 +2223,16 @@ func // handleHeadersMsg handles headers messages from all peers.
 func // checkHeaderSanity checks the PoW, and timestamp of a block header.
 (b *blockManager) checkHeaderSanity(blockHeader *wire.BlockHeader,
 	maxTimestamp time.Time, reorgAttempt bool, height int32) error {
-	diff, err := b.calcNextRequiredDifficulty(
-		blockHeader.Timestamp, reorgAttempt)
-	if err != nil {
-		Error(err)
-		return err
-	}
-	blockHeader.Bits = diff
+	// The difficulty adjustment of each algorithm depends on the history of
+	// the blocks mined with it, so the header bits are only checked against
+	// the minimum difficulty of the algorithm, and the hash against the bits.
+	forks := b.server.chainParams.Forks
 	stubBlock := util.NewBlock(&wire.MsgBlock{
 		Header: *blockHeader,
 	})
-	err = blockchain.CheckProofOfWork(stubBlock,
-		fork.GetMinDiff(fork.GetAlgoName(blockHeader.Version, height), height), height)
+	err := blockchain.CheckProofOfWork(stubBlock,
+		forks.MinDiff(forks.AlgoName(blockHeader.Version, height), height),
+		forks, height)
 	if err != nil {
 		Error(err)
 		return err
@@ -36,7 +36,7 @@ func createTestDatabase() (func(), FilterDatabase, error) {
 		db.Close()
 	}
 
-	filterDB, err := New(db, netparams.SimNetParams)
+	filterDB, err := New(db, &netparams.SimNetParams)
 
 	if err != nil {
 		return nil, nil, err
@@ -885,6 +885,7 @@ func // GetBlock gets a block by requesting it from the network, one peer at a
 					// We don't need to check PoW because by the time we get
 					// here, it's been checked during header synchronization
 					s.chainParams.PowLimit,
+					s.chainParams.Forks,
 					s.timeSource,
 					false,
 					block.Height(),
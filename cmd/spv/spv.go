@@ -47,7 +47,7 @@ type (
 		// our connected peers.
 		queryBatch func([]wire.Message, func(*ServerPeer, wire.Message,
 			wire.Message) bool, <-chan struct{}, ...QueryOption)
-		chainParams       netparams.Params
+		chainParams       *netparams.Params
 		addrManager       *addrmgr.AddrManager
 		connManager       *connmgr.ConnManager
 		blockManager      *blockManager
@@ -86,7 +86,7 @@ type (
 		// indexes of teh chain.
 		Database walletdb.DB
 		// ChainParams is the chain that we're running on.
-		ChainParams netparams.Params
+		ChainParams *netparams.Params
 		// ConnectPeers is a slice of hosts that should be connected to on
 		// startup, and be established as persistent peers.
 		//
@@ -263,8 +263,8 @@ func (s *ChainService) BestBlock() (*waddrmgr.BlockStamp, error) {
 		nil
 }
 
-// ChainParams returns a copy of the ChainService's netparams.Params.
-func (s *ChainService) ChainParams() netparams.Params {
+// ChainParams returns the ChainService's netparams.Params.
+func (s *ChainService) ChainParams() *netparams.Params {
 	return s.chainParams
 }
 
@@ -591,7 +591,7 @@ func (s *ChainService) peerHandler() {
 	}
 	if !DisableDNSSeed {
 		// Add peers discovered through DNS to the address manager.
-		connmgr.SeedFromDNS(&s.chainParams, RequiredServices,
+		connmgr.SeedFromDNS(s.chainParams, RequiredServices,
 			s.nameResolver, func(addrs []*wire.NetAddress) {
 				// Bitcoind uses a lookup of the dns seeder
 				// here. This is rather strange since the
@@ -1111,14 +1111,14 @@ func NewChainService(cfg Config) (*ChainService, error) {
 	}
 	s.BlockCache = lru.NewCache(blockCacheSize)
 	s.BlockHeaders, err = headerfs.NewBlockHeaderStore(
-		cfg.DataDir, cfg.Database, &cfg.ChainParams,
+		cfg.DataDir, cfg.Database, cfg.ChainParams,
 	)
 	if err != nil {
 		Error(err)
 		return nil, err
 	}
 	s.RegFilterHeaders, err = headerfs.NewFilterHeaderStore(
-		cfg.DataDir, cfg.Database, headerfs.RegularFilter, &cfg.ChainParams,
+		cfg.DataDir, cfg.Database, headerfs.RegularFilter, cfg.ChainParams,
 	)
 	if err != nil {
 		Error(err)
@@ -1259,7 +1259,7 @@ func newPeerConfig(sp *ServerPeer) *peer.Config {
 		HostToNetAddress: sp.server.addrManager.HostToNetAddress,
 		UserAgentName:    sp.server.userAgentName,
 		UserAgentVersion: sp.server.userAgentVersion,
-		ChainParams:      &sp.server.chainParams,
+		ChainParams:      sp.server.chainParams,
 		Services:         sp.server.services,
 		ProtocolVersion:  wire.FeeFilterVersion,
 		DisableRelayTx:   true,
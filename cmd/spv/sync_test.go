@@ -300,7 +300,7 @@ func testRescan(harness *neutrinoHarness, t *testing.T) {
 	// Generate an address and send it some coins on the h1 chain. We use
 	// this to test rescans and notifications.
 	modParams := harness.svc.ChainParams()
-	secSrc = newSecSource(&modParams)
+	secSrc = newSecSource(modParams)
 	privKey1, err := ec.NewPrivateKey(ec.S256())
 	if err != nil {
 		t.Fatalf("Couldn't generate private key: %s", err)
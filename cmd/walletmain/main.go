@@ -9,6 +9,7 @@ import (
 
 	"github.com/p9c/pod/pkg/chain/mining/addresses"
 	"github.com/p9c/pod/pkg/conte"
+	"github.com/p9c/pod/pkg/metrics"
 
 	"github.com/p9c/pod/pkg/chain/config/netparams"
 	"github.com/p9c/pod/pkg/pod"
@@ -48,6 +49,21 @@ func Main(cx *conte.Xt) (err error) {
 		Error("unable to create RPC servers:", err)
 		return
 	}
+	loader.RunAfterLoad(func(w *wallet.Wallet) {
+		setBirthday(w, *cx.Config.WalletBirthday)
+	})
+	// Serve metrics if requested.
+	var metricsServer *metrics.Server
+	if *cx.Config.WalletMetricsListener != "" {
+		loader.RunAfterLoad(registerWalletMetrics)
+		metricsServer, err = metrics.Listen(*cx.Config.WalletMetricsListener,
+			metrics.Default)
+		if err != nil {
+			Error("unable to serve wallet metrics:", err)
+			return
+		}
+		interrupt.AddHandler(metricsServer.Stop)
+	}
 	loader.RunAfterLoad(func(w *wallet.Wallet) {
 		Warn("starting wallet RPC services", w != nil)
 		startWalletRPCServices(w, legacyServer)
@@ -101,6 +117,9 @@ func Main(cx *conte.Xt) (err error) {
 	select {
 	case <-cx.WalletKill:
 		Warn("wallet killswitch activated")
+		if metricsServer != nil {
+			metricsServer.Stop()
+		}
 		if legacyServer != nil {
 			Warn("stopping wallet RPC server")
 			legacyServer.Stop()
@@ -143,57 +162,36 @@ func ReadCAFile(config *pod.Config) []byte {
 // and to enable additional methods.
 func rpcClientConnectLoop(cx *conte.Xt, legacyServer *legacy.Server,
 	loader *wallet.Loader) {
-	// var certs []byte
-	// if !cx.PodConfig.UseSPV {
-	certs := ReadCAFile(cx.Config)
-	// }
+	var certs []byte
+	if !*cx.Config.UseSPV {
+		certs = ReadCAFile(cx.Config)
+	}
 	for {
 		var (
 			chainClient chain.Interface
+			stopSPV     func()
 			err         error
 		)
-		// if cx.PodConfig.UseSPV {
-		// 	var (
-		// 		chainService *neutrino.ChainService
-		// 		spvdb        walletdb.DB
-		// 	)
-		// 	netDir := networkDir(cx.PodConfig.AppDataDir.Value, ActiveNet.Params)
-		// 	spvdb, err = walletdb.Create("bdb",
-		// 		filepath.Join(netDir, "neutrino.db"))
-		// 	defer spvdb.Close()
-		// 	if err != nil {
-		// 		log<-cl.Errorf{"unable to create Neutrino DB: %s", err)
-		// 		continue
-		// 	}
-		// 	chainService, err = neutrino.NewChainService(
-		// 		neutrino.Config{
-		// 			DataDir:      netDir,
-		// 			Database:     spvdb,
-		// 			ChainParams:  *ActiveNet.Params,
-		// 			ConnectPeers: cx.PodConfig.ConnectPeers,
-		// 			AddPeers:     cx.PodConfig.AddPeers,
-		// 		})
-		// 	if err != nil {
-		// 		log<-cl.Errorf{"couldn't create Neutrino ChainService: %s", err)
-		// 		continue
-		// 	}
-		// 	chainClient = chain.NewNeutrinoClient(ActiveNet.Params, chainService)
-		// 	err = chainClient.Start()
-		// 	if err != nil {
-		// 		log<-cl.Errorf{"couldn't start Neutrino client: %s", err)
-		// 	}
-		// } else {
-		var cc *chain.RPCClient
-		cc, err = startChainRPC(cx.Config, cx.ActiveNet, certs)
-		if err != nil {
-			Error(
-				"unable to open connection to consensus RPC server:", err)
-			continue
+		if *cx.Config.UseSPV {
+			var nc *chain.NeutrinoClient
+			nc, stopSPV, err = startChainSPV(cx)
+			if err != nil {
+				Error("unable to start SPV chain client:", err)
+				return
+			}
+			chainClient = nc
+		} else {
+			var cc *chain.RPCClient
+			cc, err = startChainRPC(cx.Config, cx.ActiveNet, certs)
+			if err != nil {
+				Error(
+					"unable to open connection to consensus RPC server:", err)
+				continue
+			}
+			cx.ChainClient = cc
+			cx.ChainClientReady.Store(true)
+			chainClient = cc
 		}
-		cx.ChainClient = cc
-		cx.ChainClientReady.Store(true)
-		chainClient = cc
-		// }
 		// Rather than inlining this logic directly into the loader
 		// callback, a function variable is used to avoid running any of
 		// this after the client disconnects by setting it to nil.  This
@@ -221,6 +219,9 @@ func rpcClientConnectLoop(cx *conte.Xt, legacyServer *legacy.Server,
 		mu.Lock()
 		associateRPCClient = nil
 		mu.Unlock()
+		if stopSPV != nil {
+			stopSPV()
+		}
 		loadedWallet, ok := loader.LoadedWallet()
 		if ok {
 			// Do not attempt a reconnect when the wallet was explicitly stopped.
@@ -0,0 +1,35 @@
+package walletmain
+
+import (
+	"strconv"
+
+	"github.com/p9c/pod/pkg/metrics"
+	"github.com/p9c/pod/pkg/wallet"
+)
+
+// registerWalletMetrics adds the state of a loaded wallet to the default
+// metrics registry.
+func registerWalletMetrics(w *wallet.Wallet) {
+	metrics.Register("wallet", metrics.CollectorFunc(func(s *metrics.Scrape) {
+		for _, minConf := range []int32{0, 1} {
+			balance, err := w.CalculateBalance(minConf)
+			if err != nil {
+				Debug("unable to calculate wallet balance:", err)
+				continue
+			}
+			s.Gauge("pod_wallet_balance_duo",
+				"Balance of the wallet in DUO, counting the outputs with at "+
+					"least minconf confirmations.",
+				balance.ToDUO(), "minconf", strconv.Itoa(int(minConf)))
+		}
+		s.Gauge("pod_wallet_synced_height",
+			"Height of the block the wallet is synced to.",
+			float64(w.Manager.SyncedTo().Height))
+		s.Gauge("pod_wallet_chain_synced",
+			"Whether the wallet is synced with the chain server.",
+			metrics.BoolValue(w.ChainSynced()))
+		s.Gauge("pod_wallet_locked",
+			"Whether the private keys of the wallet are locked.",
+			metrics.BoolValue(w.Locked()))
+	}))
+}
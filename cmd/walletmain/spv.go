@@ -0,0 +1,81 @@
+package walletmain
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/p9c/pod/cmd/node/rpc"
+	"github.com/p9c/pod/cmd/spv"
+	"github.com/p9c/pod/pkg/conte"
+	"github.com/p9c/pod/pkg/wallet"
+	"github.com/p9c/pod/pkg/wallet/chain"
+	walletdb "github.com/p9c/pod/pkg/wallet/db"
+)
+
+// SPVDbName is the name of the database of the SPV wallet's filter headers
+// and filters in the SPV data directory
+const SPVDbName = "spv.db"
+
+// startChainSPV opens the header and filter stores in the SPV data directory
+// and starts a light client that synchronises them from peers serving compact
+// block filters. The returned function stops the light client and closes the
+// stores once the client has shut down.
+func startChainSPV(cx *conte.Xt) (cc *chain.NeutrinoClient, stop func(),
+	err error) {
+	dataDir := *cx.Config.SPVDataDir
+	Debug("opening SPV stores in", dataDir)
+	if err = os.MkdirAll(dataDir, 0700); Check(err) {
+		return
+	}
+	var db walletdb.DB
+	if db, err = walletdb.Create("bdb", filepath.Join(dataDir,
+		SPVDbName)); Check(err) {
+		return
+	}
+	var cs *spv.ChainService
+	if cs, err = spv.NewChainService(spv.Config{
+		DataDir:      dataDir,
+		Database:     db,
+		ChainParams:  cx.ActiveNet,
+		ConnectPeers: *cx.Config.SPVConnect,
+		Dialer:       rpc.Dial(cx.StateCfg),
+		NameResolver: rpc.Lookup(cx.StateCfg),
+	}); Check(err) {
+		if e := db.Close(); Check(e) {
+		}
+		return
+	}
+	cc = chain.NewNeutrinoClient(cx.ActiveNet, cs)
+	if err = cc.Start(); Check(err) {
+		if e := cs.Stop(); Check(e) {
+		}
+		if e := db.Close(); Check(e) {
+		}
+		return nil, nil, err
+	}
+	stop = func() {
+		if e := cs.Stop(); Check(e) {
+		}
+		if e := db.Close(); Check(e) {
+		}
+		Debug("SPV stores closed")
+	}
+	return
+}
+
+// setBirthday moves the birthday of a wallet to the configured date, if any,
+// so that rescans start from the first block after it
+func setBirthday(w *wallet.Wallet, date string) {
+	if date == "" {
+		return
+	}
+	birthday, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		Error("invalid wallet birthday, expected YYYY-MM-DD:", err)
+		return
+	}
+	Info("setting wallet birthday to", birthday.Format("2006-01-02"))
+	if err = w.SetBirthday(birthday); Check(err) {
+	}
+}
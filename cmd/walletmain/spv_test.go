@@ -0,0 +1,119 @@
+// +build rpctest
+
+package walletmain
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli"
+
+	"github.com/p9c/pod/cmd/node/integration/rpctest"
+	"github.com/p9c/pod/cmd/node/state"
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/conte"
+	"github.com/p9c/pod/pkg/pod"
+	"github.com/p9c/pod/pkg/util"
+	"github.com/p9c/pod/pkg/wallet"
+	waddrmgr "github.com/p9c/pod/pkg/wallet/addrmgr"
+)
+
+// spvSyncTimeout is how long the SPV wallet is given to see a payment mined
+// by the regtest node
+const spvSyncTimeout = time.Minute
+
+// TestSPVSync ensures a wallet synchronised by the SPV light client from the
+// compact block filters of a regtest node sees a payment to it once it is
+// mined.
+func TestSPVSync(t *testing.T) {
+	params := &netparams.RegressionTestParams
+	harness, err := rpctest.New(params, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to create regtest node: %v", err)
+	}
+	if err = harness.SetUp(true, 1); err != nil {
+		t.Fatalf("unable to set up regtest node: %v", err)
+	}
+	defer func() {
+		if err := harness.TearDown(); err != nil {
+			t.Errorf("unable to tear down regtest node: %v", err)
+		}
+	}()
+	dir, err := ioutil.TempDir("", "spvtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config, _ := pod.EmptyConfig()
+	*config.SPVDataDir = filepath.Join(dir, "spv")
+	*config.SPVConnect = cli.StringSlice{harness.P2PAddress()}
+	*config.WalletFile = filepath.Join(dir, "wallet.db")
+	cx := &conte.Xt{
+		Config:    config,
+		ActiveNet: params,
+		StateCfg: &state.Config{
+			Dial:   net.DialTimeout,
+			Lookup: net.LookupIP,
+		},
+	}
+	cc, stopSPV, err := startChainSPV(cx)
+	if err != nil {
+		t.Fatalf("startChainSPV: %v", err)
+	}
+	defer stopSPV()
+	loader := wallet.NewLoader(params, *config.WalletFile, 250)
+	w, err := loader.CreateNewWallet([]byte("public"), []byte("private"), nil,
+		time.Now(), false, config)
+	if err != nil {
+		t.Fatalf("unable to create wallet: %v", err)
+	}
+	defer func() {
+		if err := loader.UnloadWallet(); err != nil {
+			t.Errorf("unable to unload wallet: %v", err)
+		}
+	}()
+	w.SynchronizeRPC(cc)
+	addr, err := w.NewAddress(waddrmgr.DefaultAccountNum,
+		waddrmgr.KeyScopeBIP0044, false)
+	if err != nil {
+		t.Fatalf("unable to get wallet address: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const amount = util.Amount(100000000)
+	if _, err = harness.SendOutputs([]*wire.TxOut{
+		wire.NewTxOut(int64(amount), pkScript)}, 10); err != nil {
+		t.Fatalf("unable to pay the wallet: %v", err)
+	}
+	if _, err = harness.Node.Generate(1); err != nil {
+		t.Fatalf("unable to mine the payment: %v", err)
+	}
+	_, height, err := harness.Node.GetBestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(spvSyncTimeout)
+	for {
+		balance, err := w.CalculateBalance(1)
+		if err != nil {
+			t.Fatalf("CalculateBalance: %v", err)
+		}
+		synced := w.Manager.SyncedTo().Height
+		if balance == amount && synced == height {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got balance %v synced to %d, want %v synced to %d",
+				balance, synced, amount, height)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -55,6 +55,21 @@ func CreateSimulationWallet(activenet *netparams.Params, cfg *Config) error {
 // wallet and generates the wallet accordingly.
 // The new wallet will reside at the provided path.
 func CreateWallet(activenet *netparams.Params, config *pod.Config) error {
+	return createWallet(activenet, config, false)
+}
+
+// RestoreWallet prompts the user for the mnemonic or seed of an existing
+// wallet and the information needed to recreate it, and creates the wallet
+// accordingly.  The addresses used by the wallet are found by scanning the
+// chain the next time the wallet starts.
+func RestoreWallet(activenet *netparams.Params, config *pod.Config) error {
+	return createWallet(activenet, config, true)
+}
+
+// createWallet creates a wallet from a new seed or, when restore is true, from
+// the seed of an existing wallet entered by the user.
+func createWallet(activenet *netparams.Params, config *pod.Config,
+	restore bool) error {
 	dbDir := *config.WalletFile
 	loader := wallet.NewLoader(activenet, dbDir, 250)
 	// When there is a legacy keystore, open it now to ensure any errors
@@ -144,15 +159,28 @@ func CreateWallet(activenet *netparams.Params, config *pod.Config) error {
 	// Ascertain the wallet generation seed.  This will either be an
 	// automatically generated value the user has already confirmed or a
 	// value the user has entered which has already been validated.
-	seed, err := prompt.Seed(reader)
+	var seed []byte
+	existing := restore
+	if restore {
+		seed, err = prompt.RestoreSeed(reader)
+	} else {
+		seed, existing, err = prompt.Seed(reader)
+	}
 	if err != nil {
 		Error(err)
 		Debug(err)
 		time.Sleep(time.Second * 5)
 		return err
 	}
+	// A wallet from an existing seed may have used addresses at any time since
+	// the chain began, so its birthday is set to the genesis block for the
+	// recovery to scan the whole chain for them.
+	bday := time.Now()
+	if existing {
+		bday = activenet.GenesisBlock.Header.Timestamp
+	}
 	Debug("Creating the wallet")
-	w, err := loader.CreateNewWallet(pubPass, privPass, seed, time.Now(), false, config)
+	w, err := loader.CreateNewWallet(pubPass, privPass, seed, bday, false, config)
 	if err != nil {
 		Error(err)
 		Debug(err)
@@ -3,7 +3,6 @@ package blockchain
 import (
 	"fmt"
 
-	"github.com/p9c/pod/pkg/chain/hardfork"
 	database "github.com/p9c/pod/pkg/db"
 	"github.com/p9c/pod/pkg/util"
 )
@@ -53,7 +52,7 @@ func // maybeAcceptBlock potentially accepts a block into the block chain
 		var i int64
 		pn = prevNode
 		for ; i < b.params.AveragingInterval-1; i++ {
-			pn = pn.GetLastWithAlgo(a)
+			pn = pn.GetLastWithAlgo(a, b.params.Forks)
 			if pn == nil {
 				break
 			}
@@ -62,7 +61,7 @@ func // maybeAcceptBlock potentially accepts a block into the block chain
 	// Warn("check for blacklisted addresses")
 	txs := block.Transactions()
 	for i := range txs {
-		if ContainsBlacklisted(b, txs[i], hardfork.Blacklist) {
+		if ContainsBlacklisted(b, txs[i]) {
 			return false, ruleError(ErrBlacklisted, "block contains a blacklisted address ")
 		}
 	}
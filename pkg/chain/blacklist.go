@@ -1,20 +1,21 @@
 package blockchain
 
 import (
-	"github.com/p9c/pod/pkg/chain/fork"
 	txscript "github.com/p9c/pod/pkg/chain/tx/script"
 	"github.com/p9c/pod/pkg/util"
 )
 
-// ContainsBlacklisted returns true if one of the given addresses
-// is found in the transaction
-func ContainsBlacklisted(b *BlockChain, tx *util.Tx, blacklist []util.Address) (hasBlacklisted bool) {
+// ContainsBlacklisted returns true if one of the addresses suspended by the
+// hard fork schedule of the chain is found in the transaction
+func ContainsBlacklisted(b *BlockChain, tx *util.Tx) (hasBlacklisted bool) {
 	// in tests this function is not relevant
 	if b == nil {
 		return false
 	}
-	// blacklist only applies from hard fork
-	if fork.GetCurrent(b.BestSnapshot().Height) < 1 {
+	// blacklist only applies from the hard forks that suspend addresses
+	forks := b.params.Forks
+	height := b.BestSnapshot().Height
+	if len(forks.Blacklist(height)) < 1 {
 		return false
 	}
 	var addrs []util.Address
@@ -41,8 +42,13 @@ func ContainsBlacklisted(b *BlockChain, tx *util.Tx, blacklist []util.Address) (
 			}
 		}
 	}
-	// check if the set of addresses intersects with the blacklist
-	return Intersects(addrs, blacklist)
+	// check if any of the addresses is in the blacklist
+	for i := range addrs {
+		if forks.Blacklisted(addrs[i].EncodeAddress(), height) {
+			return true
+		}
+	}
+	return false
 }
 
 // Intersects returns whether one slice of byte slices contains a match in
@@ -287,12 +287,15 @@ func (node *BlockNode) GetAlgo() int32 {
 	return node.version
 }
 
-// GetLastWithAlgo returns the newest block from node with specified algo
-func (node *BlockNode) GetLastWithAlgo(algo int32) (prev *BlockNode) {
+// GetLastWithAlgo returns the newest block from node with specified algo,
+// with the block versions before the first hard fork of the schedule
+// sanitised as they are by consensus
+func (node *BlockNode) GetLastWithAlgo(algo int32, forks *fork.Schedule) (
+	prev *BlockNode) {
 	if node == nil {
 		return
 	}
-	if fork.GetCurrent(node.height+1) == 0 {
+	if forks.Current(node.height+1) == 0 {
 		// Trace("checking pre-hardfork algo versions")
 		if algo != 514 &&
 			algo != 2 {
@@ -307,7 +310,7 @@ func (node *BlockNode) GetLastWithAlgo(algo int32) (prev *BlockNode) {
 		}
 		// Tracef("node %d %d %8x", prev.height, prev.version, prev.bits)
 		prevversion := prev.version
-		if fork.GetCurrent(prev.height) == 0 {
+		if forks.Current(prev.height) == 0 {
 			// Trace("checking pre-hardfork algo versions")
 			if prev.version != 514 &&
 				prev.version != 2 {
@@ -10,7 +10,7 @@ import (
 
 	chaincfg "github.com/p9c/pod/pkg/chain/config"
 	"github.com/p9c/pod/pkg/chain/config/netparams"
-	"github.com/p9c/pod/pkg/chain/fork"
+	"github.com/p9c/pod/pkg/chain/hardfork"
 	chainhash "github.com/p9c/pod/pkg/chain/hash"
 	txscript "github.com/p9c/pod/pkg/chain/tx/script"
 	"github.com/p9c/pod/pkg/chain/wire"
@@ -97,6 +97,7 @@ BlockChain struct {
 	sigCache            *txscript.SigCache
 	indexManager        IndexManager
 	hashCache           *txscript.HashCache
+	pruneTarget         uint64
 	// The following fields are calculated based upon the provided chain
 	// parameters.  They are also set when the instance is created and can't
 	// be changed afterwards, so there is no need to protect them with
@@ -128,6 +129,12 @@ BlockChain struct {
 	// They are protected by the chain lock.
 	nextCheckpoint *chaincfg.Checkpoint
 	checkpointNode *BlockNode
+	// pruneHeight is the lowest height of the main chain whose block has not
+	// been pruned. It is protected by the chain lock.
+	pruneHeight int32
+	// utxoSnapshot is the utxo snapshot the chain was started from, which is
+	// nil when it was started from the genesis block.
+	utxoSnapshot *UtxoSnapshot
 	// The state is used as a fairly efficient way to cache information about
 	// the current best chain state that is returned to callers when
 	// requested.  It operates on the principle of MVCC such that any time a
@@ -569,6 +576,8 @@ func // connectBlock handles connecting the passed node/block to the end of the
 	state := newBestState(node, blockSize, blockWeight, numTxns,
 		curTotalTxns+numTxns, node.CalcPastMedianTime())
 	// Atomically insert info into the database.
+	pruneHeight := b.pruneHeight
+	var pruned []*BlockNode
 	err = b.db.Update(func(dbTx database.Tx) error {
 		// update best block state.
 		err := dbPutBestState(dbTx, state, node.workSum)
@@ -609,12 +618,32 @@ func // connectBlock handles connecting the passed node/block to the end of the
 				return err
 			}
 		}
+		// Delete the oldest blocks if they take more space than the prune
+		// target now that this one is stored.
+		if b.pruneTarget != 0 {
+			if pruneHeight, pruned, err = b.pruneBlocks(dbTx, node); err != nil {
+				Trace("pruneBlocks", err)
+				return err
+			}
+		}
 		return nil
 	})
 	if err != nil {
 		Trace("error updating database ", err)
 		return err
 	}
+	b.pruneHeight = pruneHeight
+	// The pruned blocks are gone now that the deletion has been committed, so
+	// their nodes no longer have their data stored.
+	if len(pruned) > 0 {
+		for _, n := range pruned {
+			b.Index.UnsetStatusFlags(n, statusDataStored)
+		}
+		if err = b.Index.flushToDB(); err != nil {
+			Error(err)
+			return err
+		}
+	}
 	// Prune fully spent entries and mark all entries in the view unmodified
 	// now that the modifications have been committed to the database.
 	view.commit()
@@ -1583,6 +1612,10 @@ Config struct {
 	// flag. This field can be nil if the caller is not interested in using a
 	// signature cache.
 	HashCache *txscript.HashCache
+	// PruneTarget is the size in bytes the stored blocks are kept under by
+	// deleting the oldest ones, other than the last MinBlocksToKeep blocks.
+	// This field can be zero to keep all blocks.
+	PruneTarget uint64
 }
 
 func // New returns a BlockChain instance using the provided configuration
@@ -1595,6 +1628,10 @@ New(config *Config) (*BlockChain, error) {
 	if config.ChainParams == nil {
 		return nil, AssertError("blockchain.New chain parameters nil")
 	}
+	if err := hardfork.Validate(config.ChainParams); err != nil {
+		return nil, AssertError("blockchain.New invalid hard fork schedule: " +
+			err.Error())
+	}
 	if config.TimeSource == nil {
 		return nil, AssertError("blockchain.New timesource is nil")
 	}
@@ -1631,6 +1668,7 @@ New(config *Config) (*BlockChain, error) {
 		blocksPerRetarget:     int32(targetTimespan / targetTimePerBlock),
 		Index:                 newBlockIndex(config.DB, params),
 		hashCache:             config.HashCache,
+		pruneTarget:           config.PruneTarget,
 		BestChain:             newChainView(nil),
 		orphans:               make(map[chainhash.Hash]*orphanBlock),
 		prevOrphans:           make(map[chainhash.Hash][]*orphanBlock),
@@ -1666,7 +1704,7 @@ New(config *Config) (*BlockChain, error) {
 	bestNode := b.BestChain.Tip()
 	df, ok := bestNode.Diffs.Load().(TargetBits)
 	if df == nil || !ok ||
-		len(df) != len(fork.List[1].AlgoVers) {
+		len(df) != len(b.plan9().AlgoVers) {
 		bitsMap, err := b.CalcNextRequiredDifficultyPlan9Controller(bestNode)
 		if err != nil {
 			Error(err)
@@ -1105,6 +1105,12 @@ func // initChainState attempts to load and initialize the chain state from the
 			initBlockNode(node, header, parent)
 			node.status = status
 			b.Index.addNode(node)
+			// Blocks that build on an invalidated block are invalid too, which
+			// is stored for any that were not marked when it was invalidated.
+			if parent != nil && parent.status.KnownInvalid() &&
+				!status.KnownInvalid() {
+				b.Index.SetStatusFlags(node, statusInvalidAncestor)
+			}
 			lastNode = node
 			i++
 		}
@@ -1151,6 +1157,17 @@ func // initChainState attempts to load and initialize the chain state from the
 		numTxns := uint64(len(block.Transactions))
 		b.stateSnapshot = newBestState(tip, blockSize, blockWeight,
 			numTxns, state.totalTxns, tip.CalcPastMedianTime())
+		b.pruneHeight = dbFetchPruneHeight(dbTx)
+		b.utxoSnapshot, err = dbFetchUtxoSnapshot(dbTx)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		if b.utxoSnapshot != nil && b.utxoSnapshot.Status == SnapshotInvalid {
+			return fmt.Errorf("the chain was started from a utxo snapshot "+
+				"at height %d that the blocks before it do not lead to, "+
+				"it has to be reset", b.utxoSnapshot.Height)
+		}
 		return nil
 	})
 	if err != nil {
@@ -0,0 +1,273 @@
+package blockchain
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+)
+
+// ChainTipStatus is the state of the branch ending at a chain tip
+type ChainTipStatus string
+
+const (
+	// TipActive is the tip of the main chain
+	TipActive ChainTipStatus = "active"
+	// TipInvalid is a branch with a block that failed validation or was
+	// invalidated
+	TipInvalid ChainTipStatus = "invalid"
+	// TipHeadersOnly is a branch whose blocks are not all stored
+	TipHeadersOnly ChainTipStatus = "headers-only"
+	// TipValidHeaders is a branch whose blocks are stored but not all
+	// validated
+	TipValidHeaders ChainTipStatus = "valid-headers"
+	// TipValidFork is a fully validated branch that is not the main chain
+	TipValidFork ChainTipStatus = "valid-fork"
+)
+
+// ChainTip is a block of the block index without children, the end of the
+// main chain or of a side branch
+type ChainTip struct {
+	Height int32
+	Hash   chainhash.Hash
+	// BranchLen is the number of blocks of the branch since it forked from the
+	// main chain, which is zero for the main chain
+	BranchLen int32
+	Status    ChainTipStatus
+}
+
+// ChainTips returns the tips of every branch in the block index, highest
+// first. This function is safe for concurrent access.
+func (b *BlockChain) ChainTips() (tips []ChainTip) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+	best := b.BestChain.Tip()
+	for _, node := range b.leafNodes() {
+		tip := ChainTip{
+			Height: node.height,
+			Hash:   node.hash,
+		}
+		status := b.Index.NodeStatus(node)
+		switch {
+		case node == best:
+			tip.Status = TipActive
+		case status.KnownInvalid():
+			tip.Status = TipInvalid
+		case !status.HaveData():
+			tip.Status = TipHeadersOnly
+		case status.KnownValid():
+			tip.Status = TipValidFork
+		default:
+			tip.Status = TipValidHeaders
+		}
+		tip.BranchLen = node.height - b.BestChain.FindFork(node).height
+		tips = append(tips, tip)
+	}
+	sort.SliceStable(tips, func(i, j int) bool {
+		return tips[i].Height > tips[j].Height
+	})
+	return
+}
+
+// InvalidateBlock marks a block and its descendants as invalid, as if it had
+// failed validation. If it is in the main chain the chain is disconnected back
+// to its parent, and the branch with the most work that is not invalid becomes
+// the main chain. The status is stored in the block index, so it remains
+// after a restart. This function is safe for concurrent access.
+func (b *BlockChain) InvalidateBlock(hash *chainhash.Hash) (err error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+	node := b.Index.LookupNode(hash)
+	if node == nil {
+		return fmt.Errorf("block %v is not known", hash)
+	}
+	if node.parent == nil {
+		return errors.New("the genesis block cannot be invalidated")
+	}
+	b.Index.SetStatusFlags(node, statusValidateFailed)
+	for _, n := range b.descendants(node) {
+		b.Index.SetStatusFlags(n, statusInvalidAncestor)
+	}
+	if b.BestChain.Contains(node) {
+		detachNodes := list.New()
+		for n := b.BestChain.Tip(); n != node.parent; n = n.parent {
+			detachNodes.PushBack(n)
+		}
+		Infof("INVALIDATE: disconnecting %d blocks back to %v (height %d)",
+			detachNodes.Len(), node.parent.hash, node.parent.height)
+		if err = b.reorganizeChain(detachNodes, list.New()); err != nil {
+			Error(err)
+			_ = b.Index.flushToDB()
+			return
+		}
+	}
+	if err = b.activateBestChain(); err != nil {
+		Error(err)
+		_ = b.Index.flushToDB()
+		return
+	}
+	return b.Index.flushToDB()
+}
+
+// ReconsiderBlock clears the invalid status of a block, its descendants and
+// its ancestors, which undoes InvalidateBlock, and switches to the branch with
+// the most work if it is no longer the main chain. Blocks that are not fully
+// validated are validated again when they are connected. This function is
+// safe for concurrent access.
+func (b *BlockChain) ReconsiderBlock(hash *chainhash.Hash) (err error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+	node := b.Index.LookupNode(hash)
+	if node == nil {
+		return fmt.Errorf("block %v is not known", hash)
+	}
+	invalid := statusValidateFailed | statusInvalidAncestor
+	for n := node; n != nil; n = n.parent {
+		if b.Index.NodeStatus(n).KnownInvalid() {
+			b.Index.UnsetStatusFlags(n, invalid)
+		}
+	}
+	for _, n := range b.descendants(node) {
+		if b.Index.NodeStatus(n).KnownInvalid() {
+			b.Index.UnsetStatusFlags(n, invalid)
+		}
+	}
+	if err = b.activateBestChain(); err != nil {
+		Error(err)
+		_ = b.Index.flushToDB()
+		return
+	}
+	return b.Index.flushToDB()
+}
+
+// PreciousBlock makes a block the tip of the main chain if its branch has at
+// least as much work as the main chain since they forked, as though it had
+// been received first. This function is safe for concurrent access.
+func (b *BlockChain) PreciousBlock(hash *chainhash.Hash) (err error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+	node := b.Index.LookupNode(hash)
+	if node == nil {
+		return fmt.Errorf("block %v is not known", hash)
+	}
+	if b.BestChain.Contains(node) {
+		return
+	}
+	status := b.Index.NodeStatus(node)
+	if status.KnownInvalid() || !status.HaveData() {
+		return fmt.Errorf("block %v is invalid or not stored", hash)
+	}
+	forkNode := b.BestChain.FindFork(node)
+	if branchWork(node, forkNode).Cmp(branchWork(b.BestChain.Tip(),
+		forkNode)) < 0 {
+		return fmt.Errorf("block %v has less work than the main chain", hash)
+	}
+	detachNodes, attachNodes := b.getReorganizeNodes(node)
+	Infof("REORGANIZE: block %v is precious", node.hash)
+	err = b.reorganizeChain(detachNodes, attachNodes)
+	if writeErr := b.Index.flushToDB(); writeErr != nil {
+		Error(writeErr)
+	}
+	return
+}
+
+// activateBestChain reorganizes to the branch that is not known to be invalid
+// and has more work than the main chain since they forked, if there is one.
+// Branches that fail validation are marked invalid and the next best is
+// tried. This function may modify node statuses in the block index without
+// flushing. This function MUST be called with the chain state lock held (for
+// writes).
+func (b *BlockChain) activateBestChain() (err error) {
+	for {
+		tip := b.BestChain.Tip()
+		var best *BlockNode
+		var bestExcess *big.Int
+		for _, leaf := range b.leafNodes() {
+			// the highest block of the branch that could be connected
+			node := leaf
+			for node != nil && !b.BestChain.Contains(node) {
+				status := b.Index.NodeStatus(node)
+				if !status.KnownInvalid() && status.HaveData() &&
+					!b.Index.NodeStatus(node.parent).KnownInvalid() {
+					break
+				}
+				node = node.parent
+			}
+			if node == nil || b.BestChain.Contains(node) {
+				continue
+			}
+			forkNode := b.BestChain.FindFork(node)
+			excess := new(big.Int).Sub(branchWork(node, forkNode),
+				branchWork(tip, forkNode))
+			if excess.Sign() > 0 && (bestExcess == nil ||
+				excess.Cmp(bestExcess) > 0) {
+				best, bestExcess = node, excess
+			}
+		}
+		if best == nil {
+			return
+		}
+		detachNodes, attachNodes := b.getReorganizeNodes(best)
+		if attachNodes.Len() == 0 {
+			// an ancestor was found to be invalid and the branch is now marked
+			continue
+		}
+		Infof("REORGANIZE: block %v has the most work", best.hash)
+		if err = b.reorganizeChain(detachNodes, attachNodes); err != nil {
+			if _, ok := err.(RuleError); ok {
+				// the failed block is now marked invalid, try the next branch
+				continue
+			}
+			return
+		}
+	}
+}
+
+// leafNodes returns the blocks of the block index that no other block builds
+// on, and the tip of the main chain. This function MUST be called with the
+// chain state lock held (for reads).
+func (b *BlockChain) leafNodes() (leaves []*BlockNode) {
+	b.Index.RLock()
+	defer b.Index.RUnlock()
+	parents := make(map[*BlockNode]struct{}, len(b.Index.index))
+	for _, node := range b.Index.index {
+		if node.parent != nil {
+			parents[node.parent] = struct{}{}
+		}
+	}
+	tip := b.BestChain.Tip()
+	leaves = append(leaves, tip)
+	for _, node := range b.Index.index {
+		if _, ok := parents[node]; !ok && node != tip {
+			leaves = append(leaves, node)
+		}
+	}
+	return
+}
+
+// descendants returns every block of the block index that builds on a block.
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) descendants(node *BlockNode) (nodes []*BlockNode) {
+	b.Index.RLock()
+	defer b.Index.RUnlock()
+	for _, n := range b.Index.index {
+		if n.height > node.height && n.Ancestor(node.height) == node {
+			nodes = append(nodes, n)
+		}
+	}
+	return
+}
+
+// branchWork returns the work of the blocks from a node back to an ancestor,
+// excluding the ancestor. The work sums stored in the nodes only cover the
+// last blocks, so branches are compared with this instead.
+func branchWork(node, ancestor *BlockNode) *big.Int {
+	work := big.NewInt(0)
+	for n := node; n != nil && n != ancestor; n = n.parent {
+		work.Add(work, CalcWork(n.bits, n.height, n.version))
+	}
+	return work
+}
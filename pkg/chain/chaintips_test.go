@@ -0,0 +1,159 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+)
+
+// checkChainTips ensures the tip of the main chain is the passed block and
+// that the chain tips have the wanted statuses and branch lengths.
+func checkChainTips(t *testing.T, name string, chain *BlockChain,
+	active *BlockNode, want map[*BlockNode]ChainTip) {
+	t.Helper()
+	if tip := chain.BestChain.Tip(); tip != active {
+		t.Errorf("%s: got tip %v (height %d), want %v (height %d)", name,
+			tip.hash, tip.height, active.hash, active.height)
+	}
+	tips := chain.ChainTips()
+	if len(tips) != len(want) {
+		t.Errorf("%s: got %d chain tips, want %d", name, len(tips), len(want))
+	}
+	got := make(map[chainhash.Hash]ChainTip, len(tips))
+	for _, tip := range tips {
+		got[tip.Hash] = tip
+	}
+	for node, w := range want {
+		tip, ok := got[node.hash]
+		if !ok {
+			t.Errorf("%s: block at height %d is not a chain tip", name,
+				node.height)
+			continue
+		}
+		if tip.Status != w.Status || tip.BranchLen != w.BranchLen {
+			t.Errorf("%s: got tip at height %d %s with branch length %d, "+
+				"want %s with branch length %d", name, node.height,
+				tip.Status, tip.BranchLen, w.Status, w.BranchLen)
+		}
+	}
+}
+
+// TestInvalidateReconsiderBlock ensures invalidating a block of the main chain
+// reorganizes to the best branch without it, and that reconsidering it
+// restores the branch with the most work.
+func TestInvalidateReconsiderBlock(t *testing.T) {
+	chain, teardown, err := chainSetup("invalidateblock",
+		&netparams.RegressionTestParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardown()
+	// The main chain has 4 blocks, and a side branch forks from its first
+	// block with 2.
+	a1, err := addTestBlocks(chain, chain.BestChain.Tip(), 1, 0)
+	if err != nil {
+		t.Fatalf("Failed to add test blocks: %v", err)
+	}
+	a4, err := addTestBlocks(chain, a1, 3, 0)
+	if err != nil {
+		t.Fatalf("Failed to add test blocks: %v", err)
+	}
+	b3, err := addTestBlocks(chain, a1, 2, 1)
+	if err != nil {
+		t.Fatalf("Failed to add test blocks: %v", err)
+	}
+	checkChainTips(t, "before", chain, a4, map[*BlockNode]ChainTip{
+		a4: {Status: TipActive},
+		b3: {Status: TipValidHeaders, BranchLen: 2},
+	})
+	a2 := a4.Ancestor(2)
+	if err = chain.InvalidateBlock(&a2.hash); err != nil {
+		t.Fatalf("InvalidateBlock: %v", err)
+	}
+	checkChainTips(t, "invalidated", chain, b3, map[*BlockNode]ChainTip{
+		a4: {Status: TipInvalid, BranchLen: 3},
+		b3: {Status: TipActive},
+	})
+	// The blocks built on the invalid block are not connected again, even
+	// though their branch has the most work.
+	a5, err := addTestBlocks(chain, a4, 1, 0)
+	if err == nil {
+		t.Errorf("added block %v on an invalid block", a5.hash)
+	}
+	if err = chain.ReconsiderBlock(&a2.hash); err != nil {
+		t.Fatalf("ReconsiderBlock: %v", err)
+	}
+	checkChainTips(t, "reconsidered", chain, a4, map[*BlockNode]ChainTip{
+		a4: {Status: TipActive},
+		b3: {Status: TipValidFork, BranchLen: 2},
+	})
+	genesis := chain.BestChain.Genesis()
+	if err = chain.InvalidateBlock(&genesis.hash); err == nil {
+		t.Errorf("invalidated the genesis block")
+	}
+	var unknown chainhash.Hash
+	if err = chain.InvalidateBlock(&unknown); err == nil {
+		t.Errorf("invalidated an unknown block")
+	}
+	if err = chain.ReconsiderBlock(&unknown); err == nil {
+		t.Errorf("reconsidered an unknown block")
+	}
+}
+
+// TestPreciousBlock ensures a branch with as much work as the main chain
+// becomes the main chain when its tip is made precious, and that a branch
+// with less work does not.
+func TestPreciousBlock(t *testing.T) {
+	chain, teardown, err := chainSetup("preciousblock",
+		&netparams.RegressionTestParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardown()
+	genesis := chain.BestChain.Tip()
+	a2, err := addTestBlocks(chain, genesis, 2, 0)
+	if err != nil {
+		t.Fatalf("Failed to add test blocks: %v", err)
+	}
+	b2, err := addTestBlocks(chain, genesis, 2, 1)
+	if err != nil {
+		t.Fatalf("Failed to add test blocks: %v", err)
+	}
+	// The branches have the same work, so the first one received stays the
+	// main chain until the other is made precious.
+	checkChainTips(t, "tie", chain, a2, map[*BlockNode]ChainTip{
+		a2: {Status: TipActive},
+		b2: {Status: TipValidHeaders, BranchLen: 2},
+	})
+	if err = chain.PreciousBlock(&b2.hash); err != nil {
+		t.Fatalf("PreciousBlock: %v", err)
+	}
+	checkChainTips(t, "precious", chain, b2, map[*BlockNode]ChainTip{
+		a2: {Status: TipValidFork, BranchLen: 2},
+		b2: {Status: TipActive},
+	})
+	// A block of the main chain is already precious.
+	if err = chain.PreciousBlock(&b2.parent.hash); err != nil {
+		t.Errorf("PreciousBlock of the main chain: %v", err)
+	}
+	if err = chain.PreciousBlock(&a2.hash); err != nil {
+		t.Fatalf("PreciousBlock: %v", err)
+	}
+	checkChainTips(t, "precious again", chain, a2, map[*BlockNode]ChainTip{
+		a2: {Status: TipActive},
+		b2: {Status: TipValidFork, BranchLen: 2},
+	})
+	// A branch with less work than the main chain is refused.
+	a3, err := addTestBlocks(chain, a2, 1, 0)
+	if err != nil {
+		t.Fatalf("Failed to add test blocks: %v", err)
+	}
+	if err = chain.PreciousBlock(&b2.hash); err == nil {
+		t.Errorf("made a branch with less work precious")
+	}
+	checkChainTips(t, "less work", chain, a3, map[*BlockNode]ChainTip{
+		a3: {Status: TipActive},
+		b2: {Status: TipValidFork, BranchLen: 2},
+	})
+}
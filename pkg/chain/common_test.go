@@ -5,7 +5,6 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,6 +16,7 @@ import (
 	"github.com/p9c/pod/pkg/util"
 
 	chaincfg "github.com/p9c/pod/pkg/chain/config"
+	"github.com/p9c/pod/pkg/chain/fork"
 	"github.com/p9c/pod/pkg/chain/wire"
 	database "github.com/p9c/pod/pkg/db"
 	_ "github.com/p9c/pod/pkg/db/ffldb"
@@ -107,8 +107,8 @@ func loadBlocks(filename string) (blocks []*util.Block, err error) {
 	return
 }
 
-// chainSetup is used to create a new db and chain instance with the genesis block already inserted.  In addition to the new chain instance, it returns a teardown function the caller should invoke when done testing to clean up.
-func chainSetup(dbName string, netparams *netparams.Params) (*BlockChain, func(), error) {
+// dbSetup is used to create a new empty db.  In addition to the db, it returns a teardown function the caller should invoke when done testing to clean up.
+func dbSetup(dbName string) (database.DB, func(), error) {
 	if !isSupportedDbType(testDbType) {
 		return nil, nil, fmt.Errorf("unsupported db type %v", testDbType)
 	}
@@ -146,11 +146,27 @@ func chainSetup(dbName string, netparams *netparams.Params) (*BlockChain, func()
 		teardown = func() {
 			db.Close()
 			os.RemoveAll(dbPath)
-			os.RemoveAll(testDbRoot)
+			// The root is only removed with the last of the test databases.
+			os.Remove(testDbRoot)
 		}
 	}
+	return db, teardown, nil
+}
+
+// chainSetup is used to create a new db and chain instance with the genesis block already inserted.  In addition to the new chain instance, it returns a teardown function the caller should invoke when done testing to clean up.
+func chainSetup(dbName string, params *netparams.Params) (*BlockChain, func(), error) {
+	db, teardown, err := dbSetup(dbName)
+	if err != nil {
+		return nil, nil, err
+	}
 	// Copy the chain netparams to ensure any modifications the tests do to the chain parameters do not affect the global instance.
-	paramsCopy := *netparams
+	chainParams := *params.Params
+	paramsCopy := netparams.Params{
+		Params:              &chainParams,
+		RPCClientPort:       params.RPCClientPort,
+		WalletRPCServerPort: params.WalletRPCServerPort,
+		Forks:               params.Forks,
+	}
 	// Create the main chain instance.
 	chain, err := New(&Config{
 		DB:          db,
@@ -331,3 +347,76 @@ func newFakeNode(parent *BlockNode, blockVersion int32, bits uint32, timestamp t
 	}
 	return NewBlockNode(header, parent)
 }
+
+// solveTestHeader increments the nonce of a header until its hash meets the
+// proof of work required by its bits, which the minimum difficulty of the
+// regression test network makes take a try or two.
+func solveTestHeader(header *wire.BlockHeader, forks *fork.Schedule, height int32) {
+	powLimit := forks.MinDiff(forks.AlgoName(header.Version, height), height)
+	for checkProofOfWork(header, powLimit, forks, BFNone, height) != nil {
+		header.Nonce++
+	}
+}
+
+// newTestBlock returns a solved block on top of the passed parent with a coinbase paying the subsidy to an anyone can spend output and the difficulty the chain requires.  The extra nonce in the coinbase makes blocks on the same parent differ.
+func newTestBlock(chain *BlockChain, parent *BlockNode, extraNonce int64) (*util.Block, error) {
+	forks := chain.params.Forks
+	height := parent.height + 1
+	version := forks.AlgoSlices(forks.Current(height))[0].Version
+	timestamp := time.Unix(parent.timestamp+1, 0)
+	bits, err := chain.calcNextRequiredDifficulty(0, parent, timestamp,
+		forks.AlgoName(version, height), false)
+	if err != nil {
+		return nil, err
+	}
+	coinbaseScript, err := txscript.NewScriptBuilder().AddInt64(int64(height)).
+		AddInt64(extraNonce).Script()
+	if err != nil {
+		return nil, err
+	}
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		SignatureScript: coinbaseScript,
+		Sequence:        wire.MaxTxInSequenceNum,
+	})
+	coinbase.AddTxOut(&wire.TxOut{
+		Value:    CalcBlockSubsidy(height, chain.params, version),
+		PkScript: []byte{txscript.OP_TRUE},
+	})
+	merkles := BuildMerkleTreeStore([]*util.Tx{util.NewTx(coinbase)}, false)
+	msgBlock := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:    version,
+			PrevBlock:  parent.hash,
+			MerkleRoot: *merkles[len(merkles)-1],
+			Timestamp:  timestamp,
+			Bits:       bits,
+		},
+		Transactions: []*wire.MsgTx{coinbase},
+	}
+	solveTestHeader(&msgBlock.Header, forks, height)
+	block := util.NewBlock(msgBlock)
+	block.SetHeight(height)
+	return block, nil
+}
+
+// addTestBlocks processes n new test blocks on top of the passed parent, returning the node of the last one.
+func addTestBlocks(chain *BlockChain, parent *BlockNode, n int, extraNonce int64) (*BlockNode, error) {
+	for i := 0; i < n; i++ {
+		block, err := newTestBlock(chain, parent, extraNonce)
+		if err != nil {
+			return nil, err
+		}
+		_, isOrphan, err := chain.ProcessBlock(0, block, BFNone, block.Height())
+		if err != nil {
+			return nil, err
+		}
+		if isOrphan {
+			return nil, fmt.Errorf("test block %v is an orphan", block.Hash())
+		}
+		parent = chain.Index.LookupNode(block.Hash())
+	}
+	return parent, nil
+}
@@ -0,0 +1,103 @@
+package netparams
+
+import (
+	"github.com/p9c/pod/pkg/chain/fork"
+)
+
+// mainNetForks returns the hard fork schedule of mainnet
+func mainNetForks() *fork.Schedule {
+	hf1 := fork.NewPlan9(250000)
+	hf1.Disbursement = &fork.Disbursement{
+		Payees: []fork.Payee{
+			{Address: "ag7s5bmcA8XoP1CcS1QPjiD4C5hhMWATik", Amount: 4400},
+		},
+		// the 3 of 3 multisig payment for dev costs
+		CorePubkeys: []string{
+			// nWo
+			"021a00c7e054279124e2d3eb8b64a58f1fda515464cd8df3c0823d2ff2931ebf37",
+			// loki
+			"0387484f75bc5e45092b1334684def6b47f3dba1566b4b87f62d11c73d8f98db3e",
+			// trax0r
+			"02daf0bda15f83899f4ebb62fd837c2dd2368ec8ed90ed0f050054d75d35935c99",
+		},
+		CoreSigs:   3,
+		CoreAmount: 30000,
+	}
+	hf1.Blacklist = blacklist()
+	return fork.NewSchedule(fork.NewHalcyon(0), hf1)
+}
+
+// testNetForks returns the hard fork schedule of testnet, where the first hard
+// fork activates at height 1
+func testNetForks() *fork.Schedule {
+	hf1 := fork.NewPlan9(1)
+	hf1.Blacklist = blacklist()
+	// these are made using the following seed for testnet
+	// f4d2c4c542bb52512ed9e6bbfa2d000e576a0c8b4ebd1acafd7efa37247366bc
+	hf1.Disbursement = &fork.Disbursement{
+		Payees: []fork.Payee{
+			{Address: "8K73LTaMHZmwwqe4vTHu7wm7QtwusvRCwC", Amount: 100},
+			// {Address: "8JEEhaMxJf4dZh5rvVCVSA7JKeYBvy8fir", Amount: 15500},
+			{Address: "8bec3m8qpMePrBPHDAyCrkSm7TanGX8yWW", Amount: 1223},
+			{Address: "8MCLEWq8pjXikrpb9rF9M5DpnpaoWPUD2W", Amount: 4000},
+			{Address: "8cYGvT7km339nVukTj3ztfyQDFEHFivBNk", Amount: 2440},
+			{Address: "8YUAAfUeS2mqUnsfiwDwQcEbMfM3tazKr7", Amount: 100},
+			{Address: "8MMam6gxH1ns5LqASfhkHfRV2vsQaoM9VC", Amount: 8800},
+			{Address: "8JABYpdqqyRD5FbACtMJ3XF5HJ38jaytrk", Amount: 422},
+			{Address: "8MUnJMYi5Fo7Bm5Pmpr7JjdL3ZDJ7wqmXJ", Amount: 5000},
+			{Address: "8d2RLbCBE8CiF4DetVuRfFFLEJJaXYjhdH", Amount: 30000},
+		},
+		// the 3 of 4 multisig payment for dev costs
+		CorePubkeys: []string{
+			// "8cL2fDzTSMu9Cd2rFi1dceWitQheAaCgTs",
+			"03f040c0cff7918415974f05154c8ffe126ad93db7216103fb6f4080dc3bcf4803",
+			// "8YUDhyrcGrQk4PMpxnaNk1XyLRpQLa7N47",
+			"03f5a5ff1ce0564c7f4565a108220ebac9bd544b44e79ca5a2a805e585d8297cc6",
+			// "8Rpf7CT4ikJQqXRpSp4EnAypKmidhHADN2",
+			"022976653e490cea689faafa899aa41b6295c32a5fb3e02d0fa201ac698e0c0c24",
+			// "8Yw41PD1A3RviyjFQc38L9VufZasDU1pY8",
+			"029ed2885ea597fddea070a5c4c9f40900a514f67f9d5f662aa7b556e8bc5a26f8",
+		},
+		CoreSigs:   3,
+		CoreAmount: 30000,
+	}
+	return fork.NewSchedule(fork.NewHalcyon(0), hf1)
+}
+
+// devNetForks returns the hard fork schedule of the regression test and
+// simulation networks, without a disbursement. The first hard fork activates
+// at height 1 unless the activation is configured, and every algorithm has the
+// minimum target of the network so blocks are trivial to mine.
+func devNetForks(minBits uint32) *fork.Schedule {
+	hf1 := fork.NewPlan9(1)
+	hf1.Blacklist = blacklist()
+	s := fork.NewSchedule(fork.NewHalcyon(0), hf1)
+	s.SetMinBits(minBits)
+	return s
+}
+
+// WithForkHeight returns a copy of the parameters of a network with the first
+// hard fork activating at a height, as the regression test and simulation
+// networks allow. The parameters it is called on are left unchanged, so other
+// users of the network in the same process keep its own schedule.
+func (p *Params) WithForkHeight(height int32) (np *Params, err error) {
+	forks := p.Forks.Copy()
+	if err = forks.SetActivation(1, height); err != nil {
+		return
+	}
+	np = &Params{
+		Params:              p.Params,
+		RPCClientPort:       p.RPCClientPort,
+		WalletRPCServerPort: p.WalletRPCServerPort,
+		Forks:               forks,
+	}
+	return
+}
+
+// blacklist returns the addresses suspended from the first hard fork
+func blacklist() []string {
+	return []string{
+		// Cryptopia liquidation wallet
+		"8JEEhaMxJf4dZh5rvVCVSA7JKeYBvy8fir",
+	}
+}
@@ -0,0 +1,44 @@
+package netparams
+
+import (
+	"testing"
+)
+
+// TestWithForkHeight ensures the regression test and simulation networks
+// activate the first hard fork at block 1 unless it is moved, and that moving
+// it for one network leaves the schedules of both networks unchanged.
+func TestWithForkHeight(t *testing.T) {
+	nets := []*Params{&RegressionTestParams, &SimNetParams}
+	for _, params := range nets {
+		if params.Forks.Current(0) != 0 || params.Forks.Current(1) != 1 {
+			t.Errorf("%s: first hard fork does not activate at block 1",
+				params.Name)
+		}
+		for _, height := range []int32{1, 100} {
+			np, err := params.WithForkHeight(height)
+			if err != nil {
+				t.Errorf("%s: fork height %d refused: %v", params.Name, height,
+					err)
+				continue
+			}
+			if np.Name != params.Name {
+				t.Errorf("%s: fork height %d changed the network to %s",
+					params.Name, height, np.Name)
+			}
+			if np.Forks.Current(height-1) != 0 ||
+				np.Forks.Current(height) != 1 {
+				t.Errorf("%s: first hard fork does not activate at %d",
+					params.Name, height)
+			}
+			for _, other := range nets {
+				if other.Forks.Current(1) != 1 {
+					t.Errorf("%s: fork height %d moved the fork of %s",
+						params.Name, height, other.Name)
+				}
+			}
+		}
+		if _, err := params.WithForkHeight(0); err == nil {
+			t.Errorf("%s: fork height 0 was not refused", params.Name)
+		}
+	}
+}
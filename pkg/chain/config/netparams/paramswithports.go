@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	chaincfg "github.com/p9c/pod/pkg/chain/config"
+	"github.com/p9c/pod/pkg/chain/fork"
 )
 
 // Params is used to group parameters for various networks such as the main network and test networks.
@@ -12,6 +13,8 @@ type Params struct {
 	*chaincfg.Params
 	RPCClientPort       string
 	WalletRPCServerPort string
+	// Forks is the hard fork schedule of the network
+	Forks *fork.Schedule
 }
 
 // MainNetParams contains parameters specific running btcwallet and pod on the main network (wire.MainNet).
@@ -19,6 +22,7 @@ var MainNetParams = Params{
 	Params:              &chaincfg.MainNetParams,
 	RPCClientPort:       "11048",
 	WalletRPCServerPort: "11046",
+	Forks:               mainNetForks(),
 }
 
 // SimNetParams contains parameters specific to the simulation test network (wire.SimNet).
@@ -26,6 +30,7 @@ var SimNetParams = Params{
 	Params:              &chaincfg.SimNetParams,
 	RPCClientPort:       "41048",
 	WalletRPCServerPort: "41046",
+	Forks:               devNetForks(chaincfg.SimNetParams.PowLimitBits),
 }
 
 // TestNet3Params contains parameters specific running btcwallet and pod on the test network (version 3) (wire.TestNet3).
@@ -33,6 +38,7 @@ var TestNet3Params = Params{
 	Params:              &chaincfg.TestNet3Params,
 	RPCClientPort:       "21048",
 	WalletRPCServerPort: "21046",
+	Forks:               testNetForks(),
 }
 
 // RegressionTestParams contains parameters specific to the simulation test network (wire.SimNet).
@@ -40,4 +46,5 @@ var RegressionTestParams = Params{
 	Params:              &chaincfg.RegressionTestParams,
 	RPCClientPort:       "31048",
 	WalletRPCServerPort: "31046",
+	Forks:               devNetForks(chaincfg.RegressionTestParams.PowLimitBits),
 }
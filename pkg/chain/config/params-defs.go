@@ -89,6 +89,13 @@ type Checkpoint struct {
 	Hash   *chainhash.Hash
 }
 
+// UtxoSnapshot identifies a utxo set snapshot that a node can be started from without validating the blocks before it first. The hash of the utxo set is checked against the one in the snapshot file, and the blocks before it are validated in the background afterwards.
+type UtxoSnapshot struct {
+	Height    int32
+	BlockHash *chainhash.Hash
+	UtxoHash  *chainhash.Hash
+}
+
 // DNSSeed identifies a DNS seed.
 type DNSSeed struct {
 	// Host defines the hostname of the seed.
@@ -156,10 +163,14 @@ type Params struct {
 	ReduceMinDifficulty bool
 	// MinDiffReductionTime is the amount of time after which the minimum required difficulty should be reduced when a block hasn't been found. NOTE: This only applies if ReduceMinDifficulty is true.
 	MinDiffReductionTime time.Duration
+	// PoWNoRetargeting defines whether the network has difficulty retargeting disabled, so every block is mined at the minimum target of its algorithm. This should only be set to true for regtest like networks.
+	PoWNoRetargeting bool
 	// GenerateSupported specifies whether or not CPU mining is allowed.
 	GenerateSupported bool
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints []Checkpoint
+	// UtxoSnapshots are the utxo set snapshots that are trusted without the hash of the utxo set being given by the user.
+	UtxoSnapshots []UtxoSnapshot
 	// These fields are related to voting on consensus rule changes as defined by BIP0009.
 	//
 	// RuleChangeActivationThreshold is the number of blocks in a threshold state retarget window for which a positive vote for a rule change must be cast in order to lock in a rule change. It should typically be 95% for the main network and 75% for test networks.
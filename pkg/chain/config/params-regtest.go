@@ -27,6 +27,7 @@ var RegressionTestParams = Params{
 	RetargetAdjustmentFactor: 2,     // 50% less, 200% more
 	ReduceMinDifficulty:      true,
 	MinDiffReductionTime:     300 * 2,
+	PoWNoRetargeting:         true,
 	GenerateSupported:        true,
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: nil,
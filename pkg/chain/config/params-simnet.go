@@ -28,6 +28,7 @@ var SimNetParams = Params{
 	RetargetAdjustmentFactor: 2,     // 25% less, 400% more
 	ReduceMinDifficulty:      true,
 	MinDiffReductionTime:     time.Minute * 10, // TargetTimePerBlock * 2
+	PoWNoRetargeting:         true,
 	GenerateSupported:        true,
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: nil,
@@ -35,6 +35,10 @@ var TestNet3Params = Params{
 	Checkpoints: []Checkpoint{
 		// {546, newHashFromStr("000000002a936ca763904c3c35fce2f3556c559c0214345d31b1bcebf76acb70")},
 	},
+	// Utxo set snapshots that can be loaded with loadutxo.
+	UtxoSnapshots: []UtxoSnapshot{
+		// {1000, newHashFromStr(""), newHashFromStr("")},
+	},
 	// Consensus rule change deployments.
 	//
 	// The miner confirmation window is defined as:
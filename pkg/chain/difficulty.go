@@ -75,8 +75,8 @@ func (b *BlockChain) calcNextRequiredDifficulty(
 	workerNumber uint32, lastNode *BlockNode, newBlockTime time.Time,
 	algoname string, l bool) (newTargetBits uint32, err error) {
 	nH := lastNode.height + 1
-	cF := fork.GetCurrent(nH)
-	newTargetBits = fork.GetMinBits(algoname, nH)
+	cF := b.params.Forks.Current(nH)
+	newTargetBits = b.params.Forks.MinBits(algoname, nH)
 	// Tracef("calcNextRequiredDifficulty %08x", newTargetBits)
 	switch cF {
 	// Legacy difficulty adjustment
@@ -89,7 +89,7 @@ func (b *BlockChain) calcNextRequiredDifficulty(
 		if bits == nil || !ok {
 			lastNode.Diffs.Store(make(TargetBits))
 		}
-		version := fork.GetAlgoVer(algoname, lastNode.height+1)
+		version := b.params.Forks.AlgoVer(algoname, lastNode.height+1)
 		if bits[version] == 0 {
 			bits, err = b.CalcNextRequiredDifficultyPlan9Controller(lastNode)
 			if err != nil {
@@ -3,6 +3,9 @@ package blockchain
 import (
 	"math/big"
 	"testing"
+	"time"
+
+	"github.com/p9c/pod/pkg/chain/config/netparams"
 )
 
 // TestBigToCompact ensures BigToCompact converts big integers to the expected compact representation.
@@ -62,3 +65,40 @@ func TestCalcWork(t *testing.T) {
 		}
 	}
 }
+
+// TestDevNetPlan9Targets ensures every algorithm of the first hard fork of the
+// regression test and simulation networks is mined at the minimum target of
+// the network from the height the fork activates at, so blocks are trivial to
+// mine.
+func TestDevNetPlan9Targets(t *testing.T) {
+	moved, err := netparams.RegressionTestParams.WithForkHeight(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nets := []*netparams.Params{&netparams.RegressionTestParams,
+		&netparams.SimNetParams, moved}
+	for _, params := range nets {
+		activation := params.Forks.Forks[1].ActivationHeight
+		chain := newFakeChain(params)
+		tip := chain.BestChain.Tip()
+		timestamp := time.Unix(tip.timestamp, 0)
+		version := params.Forks.Forks[0].Algos[params.Forks.
+			AlgoSlices(0)[0].Name].Version
+		for tip.height < activation-1 {
+			timestamp = timestamp.Add(time.Second)
+			tip = newFakeNode(tip, version, params.PowLimitBits, timestamp)
+			chain.BestChain.SetTip(tip)
+		}
+		for name := range params.Forks.Forks[1].Algos {
+			bits, err := chain.calcNextRequiredDifficulty(0, tip,
+				timestamp.Add(time.Second), name, false)
+			if err != nil {
+				t.Fatalf("%s: %v", params.Name, err)
+			}
+			if bits != params.PowLimitBits {
+				t.Errorf("%s: '%s' target at height %d is %08x, want %08x",
+					params.Name, name, activation, bits, params.PowLimitBits)
+			}
+		}
+	}
+}
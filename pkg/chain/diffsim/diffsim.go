@@ -0,0 +1,224 @@
+package diffsim
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"time"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	"github.com/p9c/pod/pkg/chain/fork"
+	"github.com/p9c/pod/pkg/chain/wire"
+)
+
+// Record is a block mined in a simulation and the targets that applied to it
+type Record struct {
+	Height int32 `json:"height"`
+	// Time is the timestamp of the block and ActualTime when it was found
+	Time       int64  `json:"time"`
+	ActualTime int64  `json:"actualtime"`
+	Algo       string `json:"algo"`
+	Version    int32  `json:"version"`
+	// Interval is the seconds since the previous block and AlgoInterval the
+	// seconds since the previous block of the same algorithm
+	Interval     int64 `json:"interval"`
+	AlgoInterval int64 `json:"algointerval"`
+	// Bits are the targets of each algorithm, and Difficulty how many times
+	// harder they are than the minimum difficulty of the algorithm
+	Bits       map[string]string  `json:"bits"`
+	Difficulty map[string]float64 `json:"difficulty"`
+}
+
+// Result is the outcome of a simulation
+type Result struct {
+	Fork    string   `json:"fork"`
+	Algos   []string `json:"algos"`
+	Records []Record `json:"records"`
+}
+
+var oneLsh256 = new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), 256))
+
+// Run mines the blocks of a scenario, computing the targets of each block
+// with the retarget functions of the chain
+func Run(s *Scenario) (res *Result, err error) {
+	var hf int
+	if hf, err = s.forkNumber(); Check(err) {
+		return
+	}
+	// the chain is simulated on mainnet before the hard fork and on testnet
+	// after it, where the hard fork activates at height 1
+	params := &netparams.MainNetParams
+	if hf > 0 {
+		params = &netparams.TestNet3Params
+	}
+	forks := params.Forks
+	algos := forks.Forks[hf].Algos
+	if err = s.validate(algos); Check(err) {
+		return
+	}
+	if hf < len(forks.Forks)-1 &&
+		int32(s.Blocks) >= forks.Forks[hf+1].ActivationHeight {
+		err = fmt.Errorf("can't simulate more than %d blocks before the"+
+			" hard fork", forks.Forks[hf+1].ActivationHeight-1)
+		return
+	}
+	res = &Result{Fork: s.Fork}
+	if res.Fork == "" {
+		res.Fork = Plan9
+	}
+	hashrate := make(map[string]float64)
+	for _, a := range forks.AlgoSlices(hf) {
+		res.Algos = append(res.Algos, a.Name)
+		hashrate[a.Name] = DefaultHashrate
+		if rate, ok := s.Hashrate[a.Name]; ok {
+			hashrate[a.Name] = rate
+		}
+	}
+	rnd := rand.New(rand.NewSource(s.Seed))
+	b := blockchain.NewSimChain(params)
+	start := params.GenesisBlock.Header.Timestamp
+	first := forks.AlgoSlices(0)[0]
+	last := blockchain.NewBlockNode(&wire.BlockHeader{
+		Version:   first.Version,
+		Bits:      forks.Forks[0].Algos[first.Name].MinBits,
+		Timestamp: start,
+	}, nil)
+	actual := float64(start.Unix())
+	prevTime := start.Unix()
+	algoTimes := make(map[string]int64)
+	for height := int32(1); height <= int32(s.Blocks); height++ {
+		var bits blockchain.TargetBits
+		if bits, err = b.CalcNextRequiredDifficultyPlan9Controller(
+			last); Check(err) {
+			return
+		}
+		rates := s.hashrates(height, hashrate, algos, bits)
+		// every algorithm races to find the next block, the time each takes
+		// is exponentially distributed around the work of its target divided
+		// by its hashrate
+		winner, soonest := "", math.Inf(1)
+		for _, name := range res.Algos {
+			if rates[name] <= 0 {
+				continue
+			}
+			t := rnd.ExpFloat64() * work(bits[algos[name].Version]) /
+				rates[name]
+			if t < soonest {
+				winner, soonest = name, t
+			}
+		}
+		if winner == "" {
+			err = fmt.Errorf("no hashrate at height %d", height)
+			return
+		}
+		actual += soonest
+		stamp := s.timestamp(rnd, height, int64(actual),
+			last.CalcPastMedianTime().Unix())
+		version := algos[winner].Version
+		last = blockchain.NewBlockNode(&wire.BlockHeader{
+			Version:   version,
+			Bits:      bits[version],
+			Timestamp: time.Unix(stamp, 0),
+			Nonce:     uint32(height),
+		}, last)
+		r := Record{
+			Height:     height,
+			Time:       stamp,
+			ActualTime: int64(actual),
+			Algo:       winner,
+			Version:    version,
+			Interval:   stamp - prevTime,
+			Bits:       make(map[string]string),
+			Difficulty: make(map[string]float64),
+		}
+		if t, ok := algoTimes[winner]; ok {
+			r.AlgoInterval = stamp - t
+		}
+		for _, name := range res.Algos {
+			v := algos[name].Version
+			r.Bits[name] = fmt.Sprintf("%08x", bits[v])
+			r.Difficulty[name] = difficulty(algos[name].MinBits, bits[v])
+		}
+		res.Records = append(res.Records, r)
+		algoTimes[winner] = stamp
+		prevTime = stamp
+	}
+	return
+}
+
+// hashrates returns the hashrate mined on each algorithm at a height, with
+// the shocks and switching miners active at the height
+func (s *Scenario) hashrates(height int32, base map[string]float64,
+	algos map[string]fork.AlgoParams, bits blockchain.TargetBits) (
+	rates map[string]float64) {
+	rates = make(map[string]float64)
+	for name := range base {
+		rates[name] = base[name]
+	}
+	for _, shock := range s.Shocks {
+		if !active(height, shock.Height, shock.Until) {
+			continue
+		}
+		for name := range rates {
+			if shock.Algo == "" || shock.Algo == name {
+				rates[name] *= shock.Factor
+			}
+		}
+	}
+	var easiest string
+	var easiestWork float64
+	for name := range rates {
+		w := work(bits[algos[name].Version])
+		if easiest == "" || w < easiestWork ||
+			(w == easiestWork && name < easiest) {
+			easiest, easiestWork = name, w
+		}
+	}
+	for _, sw := range s.Switchers {
+		if active(height, sw.Height, sw.Until) {
+			rates[easiest] += sw.Hashrate
+		}
+	}
+	return
+}
+
+// timestamp returns the timestamp of a block found at the actual time, which
+// time warping miners may shift within the limits the chain accepts
+func (s *Scenario) timestamp(rnd *rand.Rand, height int32, actual int64,
+	medianTime int64) (stamp int64) {
+	stamp = actual
+	for _, tw := range s.TimeWarps {
+		if active(height, tw.Height, tw.Until) &&
+			rnd.Float64() < tw.Probability {
+			stamp += tw.Offset
+		}
+	}
+	if max := actual + blockchain.MaxTimeOffsetSeconds; stamp > max {
+		stamp = max
+	}
+	if stamp <= medianTime {
+		stamp = medianTime + 1
+	}
+	return
+}
+
+// work returns the expected number of hashes to find a block at a target
+func work(bits uint32) (w float64) {
+	target := new(big.Float).SetInt(fork.CompactToBig(bits))
+	w, _ = new(big.Float).Quo(oneLsh256, target.Add(target,
+		big.NewFloat(1))).Float64()
+	return
+}
+
+// difficulty returns how many times harder a target is than the minimum
+func difficulty(minBits, bits uint32) (d float64) {
+	target := new(big.Float).SetInt(fork.CompactToBig(bits))
+	if target.Sign() <= 0 {
+		return
+	}
+	d, _ = new(big.Float).Quo(new(big.Float).SetInt(fork.CompactToBig(
+		minBits)), target).Float64()
+	return
+}
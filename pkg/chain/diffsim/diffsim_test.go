@@ -0,0 +1,209 @@
+package diffsim
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+)
+
+// testAlgo is the algorithm of the plan9 fork with the lowest version, which
+// the test scenario gives its own hashrate
+var testAlgo = netparams.TestNet3Params.Forks.AlgoSlices(1)[0].Name
+
+// testScenario returns a scenario of the plan9 fork with every kind of miner,
+// seeded so it mines the same blocks every time.
+func testScenario() *Scenario {
+	return &Scenario{
+		Fork:     Plan9,
+		Blocks:   100,
+		Seed:     1,
+		Hashrate: map[string]float64{testAlgo: 2000},
+		Shocks: []Shock{
+			{Height: 20, Until: 40, Algo: testAlgo, Factor: 10},
+			{Height: 60, Factor: 0.5},
+		},
+		Switchers: []Switcher{{Height: 30, Until: 50, Hashrate: 5000}},
+		TimeWarps: []TimeWarp{{Height: 50, Probability: 0.5, Offset: 3600}},
+	}
+}
+
+// TestRunDeterministic ensures a scenario mines the same blocks every time it
+// is run with the same seed, and different blocks with another.
+func TestRunDeterministic(t *testing.T) {
+	first, err := Run(testScenario())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	second, err := Run(testScenario())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("runs with the same seed mined different blocks")
+	}
+	s := testScenario()
+	s.Seed = 2
+	other, err := Run(s)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if reflect.DeepEqual(first.Records, other.Records) {
+		t.Errorf("runs with different seeds mined the same blocks")
+	}
+}
+
+// TestRunShape ensures the result of a scenario has a record for each block,
+// in order, with the targets of every algorithm of the fork and intervals and
+// timestamps that agree with each other.
+func TestRunShape(t *testing.T) {
+	s := testScenario()
+	res, err := Run(s)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	algos := netparams.TestNet3Params.Forks.Forks[1].Algos
+	if res.Fork != Plan9 {
+		t.Errorf("got fork %s, want %s", res.Fork, Plan9)
+	}
+	if len(res.Algos) != len(algos) {
+		t.Errorf("got %d algorithms, want %d", len(res.Algos), len(algos))
+	}
+	if len(res.Records) != s.Blocks {
+		t.Fatalf("got %d records, want %d", len(res.Records), s.Blocks)
+	}
+	var prevTime, prevActual int64
+	algoTimes := make(map[string]int64)
+	for i, r := range res.Records {
+		if r.Height != int32(i+1) {
+			t.Errorf("record %d: got height %d, want %d", i, r.Height, i+1)
+		}
+		algo, ok := algos[r.Algo]
+		if !ok {
+			t.Errorf("height %d: got unknown algorithm %s", r.Height, r.Algo)
+		} else if r.Version != algo.Version {
+			t.Errorf("height %d: got version %d, want %d", r.Height,
+				r.Version, algo.Version)
+		}
+		if len(r.Bits) != len(algos) || len(r.Difficulty) != len(algos) {
+			t.Errorf("height %d: got %d bits and %d difficulties, want %d",
+				r.Height, len(r.Bits), len(r.Difficulty), len(algos))
+		}
+		for _, name := range res.Algos {
+			if len(r.Bits[name]) != 8 || r.Difficulty[name] <= 0 {
+				t.Errorf("height %d: got %s bits %q difficulty %v", r.Height,
+					name, r.Bits[name], r.Difficulty[name])
+			}
+		}
+		if i > 0 {
+			if r.Interval != r.Time-prevTime {
+				t.Errorf("height %d: got interval %d, want %d", r.Height,
+					r.Interval, r.Time-prevTime)
+			}
+			if r.ActualTime < prevActual {
+				t.Errorf("height %d: got actual time %d before %d", r.Height,
+					r.ActualTime, prevActual)
+			}
+		}
+		if r.Time > r.ActualTime+blockchain.MaxTimeOffsetSeconds {
+			t.Errorf("height %d: got time %d more than the limit past %d",
+				r.Height, r.Time, r.ActualTime)
+		}
+		want := int64(0)
+		if prev, ok := algoTimes[r.Algo]; ok {
+			want = r.Time - prev
+		}
+		if r.AlgoInterval != want {
+			t.Errorf("height %d: got algorithm interval %d, want %d",
+				r.Height, r.AlgoInterval, want)
+		}
+		algoTimes[r.Algo] = r.Time
+		prevTime, prevActual = r.Time, r.ActualTime
+	}
+}
+
+// TestWriteResult ensures a result is written as CSV with a row for each
+// block and a pair of columns for each algorithm, and as JSON that reads back
+// as the same result.
+func TestWriteResult(t *testing.T) {
+	res, err := Run(testScenario())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var buf bytes.Buffer
+	if err = res.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("unable to read CSV: %v", err)
+	}
+	if len(rows) != len(res.Records)+1 {
+		t.Fatalf("got %d CSV rows, want %d", len(rows), len(res.Records)+1)
+	}
+	if want := 7 + 2*len(res.Algos); len(rows[0]) != want {
+		t.Errorf("got %d CSV columns, want %d", len(rows[0]), want)
+	}
+	if got := strings.Join(rows[0][:3], ","); got !=
+		"height,time,actualtime" {
+		t.Errorf("got CSV header %s", got)
+	}
+	want := res.Records[0].Bits[res.Algos[0]]
+	if got := rows[1][7]; got != want {
+		t.Errorf("got first bits %s, want %s", got, want)
+	}
+	buf.Reset()
+	if err = res.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	var got Result
+	if err = json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unable to read JSON: %v", err)
+	}
+	if !reflect.DeepEqual(&got, res) {
+		t.Errorf("JSON did not read back as the same result")
+	}
+}
+
+// TestRunInvalid ensures scenarios that can't be simulated are refused.
+func TestRunInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(s *Scenario)
+		want string
+	}{
+		{"unknown fork", func(s *Scenario) { s.Fork = "plan10" },
+			"unknown fork"},
+		{"no blocks", func(s *Scenario) { s.Blocks = 0 },
+			"must be positive"},
+		{"unknown hashrate algorithm", func(s *Scenario) {
+			s.Hashrate = map[string]float64{"md5": 1}
+		}, "unknown algorithm 'md5'"},
+		{"negative hashrate", func(s *Scenario) {
+			s.Hashrate = map[string]float64{testAlgo: -1}
+		}, "negative hashrate"},
+		{"unknown shock algorithm", func(s *Scenario) {
+			s.Shocks = []Shock{{Algo: "md5", Factor: 1}}
+		}, "unknown algorithm 'md5'"},
+		{"negative switcher", func(s *Scenario) {
+			s.Switchers = []Switcher{{Hashrate: -1}}
+		}, "negative switcher"},
+		{"time warp probability", func(s *Scenario) {
+			s.TimeWarps = []TimeWarp{{Probability: 2}}
+		}, "probability"},
+	}
+	for _, test := range tests {
+		s := testScenario()
+		test.edit(s)
+		_, err := Run(s)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %v, want one containing %q", test.name,
+				err, test.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package diffsim
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteCSV writes the blocks of a simulation as CSV, with a column of the bits
+// and of the difficulty of each algorithm
+func (r *Result) WriteCSV(w io.Writer) (err error) {
+	cw := csv.NewWriter(w)
+	header := []string{"height", "time", "actualtime", "algo", "version",
+		"interval", "algointerval"}
+	for _, name := range r.Algos {
+		header = append(header, name+"_bits", name+"_difficulty")
+	}
+	if err = cw.Write(header); Check(err) {
+		return
+	}
+	for i := range r.Records {
+		rec := &r.Records[i]
+		row := []string{
+			fmt.Sprint(rec.Height),
+			fmt.Sprint(rec.Time),
+			fmt.Sprint(rec.ActualTime),
+			rec.Algo,
+			fmt.Sprint(rec.Version),
+			fmt.Sprint(rec.Interval),
+			fmt.Sprint(rec.AlgoInterval),
+		}
+		for _, name := range r.Algos {
+			row = append(row, rec.Bits[name],
+				strconv.FormatFloat(rec.Difficulty[name], 'g', 8, 64))
+		}
+		if err = cw.Write(row); Check(err) {
+			return
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes a simulation as indented JSON
+func (r *Result) WriteJSON(w io.Writer) (err error) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
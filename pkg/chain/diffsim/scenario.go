@@ -0,0 +1,137 @@
+// Package diffsim simulates the difficulty adjustment of the chain by driving
+// the retarget functions of the block chain with synthetic chains of block
+// nodes mined by simulated miners, so changes to the retarget algorithms can
+// be evaluated before they go into a hard fork
+package diffsim
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/p9c/pod/pkg/chain/fork"
+)
+
+const (
+	// Halcyon simulates the retarget algorithm before the first hard fork
+	Halcyon = "halcyon"
+	// Plan9 simulates the retarget algorithm of the first hard fork
+	Plan9 = "plan9"
+	// DefaultHashrate is the hashrate of the algorithms a scenario does not
+	// give one for
+	DefaultHashrate = 1000
+)
+
+// Scenario describes the miners of a simulation and how they behave
+type Scenario struct {
+	// Fork is the retarget algorithm simulated, Halcyon or Plan9
+	Fork string `json:"fork"`
+	// Blocks is the number of blocks mined
+	Blocks int `json:"blocks"`
+	// Seed seeds the random source, so runs can be repeated
+	Seed int64 `json:"seed"`
+	// Hashrate is the hashes per second mined on each algorithm by name,
+	// those not given are mined at DefaultHashrate
+	Hashrate map[string]float64 `json:"hashrate"`
+	// Shocks are sudden changes of hashrate
+	Shocks []Shock `json:"shocks"`
+	// Switchers are miners that always mine the easiest algorithm
+	Switchers []Switcher `json:"switchers"`
+	// TimeWarps are miners that falsify the timestamps of their blocks
+	TimeWarps []TimeWarp `json:"timewarps"`
+}
+
+// Shock multiplies the hashrate of an algorithm, or of all of them if Algo is
+// empty, from a height until an optional end height
+type Shock struct {
+	Height int32   `json:"height"`
+	Until  int32   `json:"until"`
+	Algo   string  `json:"algo"`
+	Factor float64 `json:"factor"`
+}
+
+// Switcher is a miner that mines the algorithm with the easiest target with
+// its hashrate, from a height until an optional end height
+type Switcher struct {
+	Height   int32   `json:"height"`
+	Until    int32   `json:"until"`
+	Hashrate float64 `json:"hashrate"`
+}
+
+// TimeWarp shifts the timestamps of a fraction of the blocks by an offset in
+// seconds, from a height until an optional end height. Timestamps are kept
+// within the consensus limits of the median time of the past blocks and two
+// hours ahead of the actual time.
+type TimeWarp struct {
+	Height      int32   `json:"height"`
+	Until       int32   `json:"until"`
+	Probability float64 `json:"probability"`
+	Offset      int64   `json:"offset"`
+}
+
+// LoadScenario reads a scenario from a JSON file
+func LoadScenario(path string) (s *Scenario, err error) {
+	var b []byte
+	if b, err = ioutil.ReadFile(path); Check(err) {
+		return
+	}
+	s = &Scenario{}
+	if err = json.Unmarshal(b, s); Check(err) {
+	}
+	return
+}
+
+// active returns whether a height is in a range with an optional end
+func active(height, from, until int32) bool {
+	return height >= from && (until == 0 || height < until)
+}
+
+// forkNumber returns the number in the fork list of the fork a scenario
+// simulates
+func (s *Scenario) forkNumber() (hf int, err error) {
+	switch s.Fork {
+	case Halcyon:
+		return 0, nil
+	case Plan9, "":
+		return 1, nil
+	}
+	return 0, fmt.Errorf("unknown fork '%s', must be %s or %s", s.Fork,
+		Halcyon, Plan9)
+}
+
+// validate checks that the algorithms a scenario names exist in the fork it
+// simulates and its numbers are sane
+func (s *Scenario) validate(algos map[string]fork.AlgoParams) (err error) {
+	if s.Blocks < 1 {
+		return fmt.Errorf("number of blocks must be positive")
+	}
+	for name, rate := range s.Hashrate {
+		if _, ok := algos[name]; !ok {
+			return fmt.Errorf("unknown algorithm '%s' in hashrate", name)
+		}
+		if rate < 0 {
+			return fmt.Errorf("negative hashrate for '%s'", name)
+		}
+	}
+	for _, shock := range s.Shocks {
+		if _, ok := algos[shock.Algo]; shock.Algo != "" && !ok {
+			return fmt.Errorf("unknown algorithm '%s' in shock", shock.Algo)
+		}
+		if shock.Factor < 0 {
+			return fmt.Errorf("negative shock factor at height %d",
+				shock.Height)
+		}
+	}
+	for _, sw := range s.Switchers {
+		if sw.Hashrate < 0 {
+			return fmt.Errorf("negative switcher hashrate at height %d",
+				sw.Height)
+		}
+	}
+	for _, tw := range s.TimeWarps {
+		if tw.Probability < 0 || tw.Probability > 1 {
+			return fmt.Errorf("time warp probability must be between 0 and 1")
+		}
+	}
+	return
+}
@@ -5,22 +5,38 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
-	"math/rand"
 	"sort"
-	"time"
 )
 
 const (
 	Scrypt  = "scrypt"
 	SHA256d = "sha256d"
+	// Blake3 is the base hash of the algorithms of the first hard fork
+	Blake3 = "blake3"
 )
 
-// AlgoParams are the identifying block version number and their minimum target bits
+const (
+	// NoDivHash is the DivHash repetitions of an algorithm that hashes the
+	// block header with its base hash directly
+	NoDivHash = -1
+	// P9HashReps is the number of DivHash repetitions of the algorithms of the
+	// first hard fork. At 5 repetitions (first plus repeats, thus 4), an
+	// example block header produces a number around 48kb in byte size and
+	// ~119000 decimal digits, which is then finally hashed down to 32 bytes
+	P9HashReps = 2
+)
+
+// AlgoParams are the identifying block version number and their minimum target
+// bits, and the hash function the block header is hashed with, which is the
+// base hash from the forkhash registry applied after DivHashReps repetitions
+// of DivHash
 type AlgoParams struct {
 	Version         int32
 	MinBits         uint32
 	AlgoID          uint32
 	VersionInterval int
+	BaseHash        string
+	DivHashReps     int
 }
 
 // HardForks is the details related to a hard fork, number, name and activation height
@@ -32,51 +48,15 @@ type HardForks struct {
 	AlgoVers           map[int32]string
 	TargetTimePerBlock int32
 	AveragingInterval  int32
-	TestnetStart       int32
+	// Disbursement is the special coinbase of the block the hard fork
+	// activates at, if it has one
+	Disbursement *Disbursement
+	// Blacklist is the addresses that are suspended from the activation
+	Blacklist []string
 }
 
 const IntervalBase = 36
 
-func init() {
-	Trace("running fork data init")
-	for i := range p9AlgosNumeric {
-		List[1].AlgoVers[i] = fmt.Sprintf("Div%d", p9AlgosNumeric[i].VersionInterval)
-	}
-	for i, v := range P9AlgoVers {
-		List[1].Algos[v] = p9AlgosNumeric[i]
-	}
-	AlgoSlices = append(AlgoSlices, AlgoSpecs{})
-	for i := range Algos {
-		AlgoSlices[0] = append(AlgoSlices[0], AlgoSpec{
-			List[0].Algos[i].Version,
-			i,
-		})
-	}
-	AlgoSlices = append(AlgoSlices, AlgoSpecs{})
-	for i := range P9Algos {
-		AlgoSlices[1] = append(AlgoSlices[1], AlgoSpec{
-			List[1].Algos[i].Version,
-			i,
-		})
-	}
-	sort.Sort(AlgoSlices[0])
-	sort.Sort(AlgoSlices[1])
-	Trace(P9AlgoVers)
-	baseVersionName := AlgoSlices[1][0].Name
-	baseVersionInterval := float64(P9Algos[baseVersionName].VersionInterval)
-	Trace(baseVersionName, baseVersionInterval)
-	P9Average = 0
-	for _, i := range AlgoSlices[1] {
-		vi := float64(P9Algos[i.Name].VersionInterval)
-		p9a := baseVersionInterval / vi
-		P9Average += p9a
-		Tracef("P9Average %4.4f %4.4f %d %4.4f", p9a, P9Average, IntervalBase, vi)
-	}
-	Trace(P9Average)
-	P9Average = baseVersionInterval / P9Average
-	Trace(P9Average)
-}
-
 type AlgoSpec struct {
 	Version int32
 	Name    string
@@ -96,26 +76,6 @@ func (a AlgoSpecs) Swap(i, j int) {
 }
 
 var (
-	AlgoSlices []AlgoSpecs
-	// AlgoVers is the lookup for pre hardfork
-	//
-	AlgoVers = map[int32]string{
-		2:   SHA256d,
-		514: Scrypt,
-	}
-	// Algos are the specifications identifying the algorithm used in the
-	// block proof
-	Algos = map[string]AlgoParams{
-		AlgoVers[2]: {
-			Version: 2,
-			MinBits: MainPowLimitBits,
-		},
-		AlgoVers[514]: {
-			Version: 514,
-			MinBits: MainPowLimitBits,
-			AlgoID:  1,
-		},
-	}
 	// FirstPowLimit is
 	FirstPowLimit = func() big.Int {
 		mplb, _ := hex.DecodeString(
@@ -124,50 +84,19 @@ var (
 	}()
 	// FirstPowLimitBits is
 	FirstPowLimitBits = BigToCompact(&FirstPowLimit)
-	// IsTestnet is set at startup here to be accessible to all other libraries
-	IsTestnet bool
-	// List is the list of existing hard forks and when they activate
-	List = []HardForks{
-		{
-			Number:             0,
-			Name:               "Halcyon days",
-			ActivationHeight:   0,
-			Algos:              Algos,
-			AlgoVers:           AlgoVers,
-			TargetTimePerBlock: 300,
-			AveragingInterval:  10, // 50 minutes
-			TestnetStart:       0,
-		},
-		{
-			Number:             1,
-			Name:               "Plan 9 from Crypto Space",
-			ActivationHeight:   250000,
-			Algos:              P9Algos,
-			AlgoVers:           P9AlgoVers,
-			TargetTimePerBlock: 36,
-			AveragingInterval:  3600,
-			TestnetStart:       1,
-		},
-	}
-	// P9AlgoVers is the lookup for after 1st hardfork
-	P9AlgoVers = make(map[int32]string)
-
-	// P9Algos is the algorithm specifications after the hard fork
-	P9Algos        = make(map[string]AlgoParams)
+	// p9AlgosNumeric are the algorithms of the first hard fork by version
 	p9AlgosNumeric = map[int32]AlgoParams{
-		5:  {5, FirstPowLimitBits, 0, 1 << 1 * IntervalBase},  // 2
-		6:  {6, FirstPowLimitBits, 1, 1 << 2 * IntervalBase},  // 3
-		7:  {7, FirstPowLimitBits, 2, 1 << 3 * IntervalBase},  // 5
-		8:  {8, FirstPowLimitBits, 3, 1 << 4 * IntervalBase},  // 7
-		9:  {9, FirstPowLimitBits, 4, 1 << 5 * IntervalBase},  // 11
-		10: {10, FirstPowLimitBits, 5, 1 << 6 * IntervalBase}, // 13
-		11: {11, FirstPowLimitBits, 7, 1 << 7 * IntervalBase}, // 17
-		12: {12, FirstPowLimitBits, 6, 1 << 8 * IntervalBase}, // 19
-		13: {13, FirstPowLimitBits, 8, 1 << 9 * IntervalBase}, // 23
+		5:  {5, FirstPowLimitBits, 0, 1 << 1 * IntervalBase, Blake3, P9HashReps},  // 2
+		6:  {6, FirstPowLimitBits, 1, 1 << 2 * IntervalBase, Blake3, P9HashReps},  // 3
+		7:  {7, FirstPowLimitBits, 2, 1 << 3 * IntervalBase, Blake3, P9HashReps},  // 5
+		8:  {8, FirstPowLimitBits, 3, 1 << 4 * IntervalBase, Blake3, P9HashReps},  // 7
+		9:  {9, FirstPowLimitBits, 4, 1 << 5 * IntervalBase, Blake3, P9HashReps},  // 11
+		10: {10, FirstPowLimitBits, 5, 1 << 6 * IntervalBase, Blake3, P9HashReps}, // 13
+		11: {11, FirstPowLimitBits, 7, 1 << 7 * IntervalBase, Blake3, P9HashReps}, // 17
+		12: {12, FirstPowLimitBits, 6, 1 << 8 * IntervalBase, Blake3, P9HashReps}, // 19
+		13: {13, FirstPowLimitBits, 8, 1 << 9 * IntervalBase, Blake3, P9HashReps}, // 23
 	}
 
-	P9Average float64
-
 	// SecondPowLimit is
 	SecondPowLimit = func() big.Int {
 		mplb, _ := hex.DecodeString(
@@ -184,94 +113,88 @@ var (
 	MainPowLimitBits = BigToCompact(&MainPowLimit)
 )
 
-// GetAlgoID returns the 'algo_id' which in pre-hardfork is not the same as the
-// block version number, but is afterwards
-func GetAlgoID(algoname string, height int32) uint32 {
-	if GetCurrent(height) > 1 {
-		return P9Algos[algoname].AlgoID
+// NewHalcyon returns the original consensus of the chain, Halcyon days,
+// activated at a height
+func NewHalcyon(activationHeight int32) HardForks {
+	algos := map[string]AlgoParams{
+		SHA256d: {
+			Version:     2,
+			MinBits:     MainPowLimitBits,
+			BaseHash:    SHA256d,
+			DivHashReps: NoDivHash,
+		},
+		Scrypt: {
+			Version:     514,
+			MinBits:     MainPowLimitBits,
+			AlgoID:      1,
+			BaseHash:    Scrypt,
+			DivHashReps: NoDivHash,
+		},
 	}
-	return Algos[algoname].AlgoID
-}
-
-// GetAlgoName returns the string identifier of an algorithm depending on
-// hard fork activation status
-func GetAlgoName(algoVer int32, height int32) (name string) {
-	hf := GetCurrent(height)
-	var ok bool
-	name, ok = List[hf].AlgoVers[algoVer]
-	if hf < 1 && !ok {
-		name = SHA256d
+	return HardForks{
+		Number:             0,
+		Name:               "Halcyon days",
+		ActivationHeight:   activationHeight,
+		Algos:              algos,
+		AlgoVers:           algoVers(algos),
+		TargetTimePerBlock: 300,
+		AveragingInterval:  10, // 50 minutes
 	}
-	// INFO("GetAlgoName", algoVer, height, name}
-	return
-}
-
-// GetRandomVersion returns a random version relevant to the current hard fork state and height
-func GetRandomVersion(height int32) int32 {
-	rand.Seed(time.Now().UnixNano())
-	return int32(rand.Intn(len(List[GetCurrent(height)].Algos)) + 5)
 }
 
-// GetAlgoVer returns the version number for a given algorithm (by string name)
-// at a given height. If "random" is given, a random number is taken from the
-// system secure random source (for randomised cpu mining)
-func GetAlgoVer(name string, height int32) (version int32) {
-	hf := GetCurrent(height)
-	n := AlgoSlices[hf][0].Name
-	// DEBUG("GetAlgoVer", name, height, hf, n)
-	if _, ok := List[hf].Algos[name]; ok {
-		n = name
+// NewPlan9 returns the first hard fork, Plan 9 from Crypto Space, activated
+// at a height
+func NewPlan9(activationHeight int32) HardForks {
+	algos := make(map[string]AlgoParams)
+	for _, a := range p9AlgosNumeric {
+		algos[fmt.Sprintf("Div%d", a.VersionInterval)] = a
+	}
+	return HardForks{
+		Number:             1,
+		Name:               "Plan 9 from Crypto Space",
+		ActivationHeight:   activationHeight,
+		Algos:              algos,
+		AlgoVers:           algoVers(algos),
+		TargetTimePerBlock: 36,
+		AveragingInterval:  3600,
 	}
-	version = List[hf].Algos[n].Version
-	return
 }
 
-// GetAveragingInterval returns the active block interval target based on
-// hard fork status
-func GetAveragingInterval(height int32) (r int32) {
-	r = List[GetCurrent(height)].AveragingInterval
+// algoVers returns the lookup of algorithm names by version
+func algoVers(algos map[string]AlgoParams) (vers map[int32]string) {
+	vers = make(map[int32]string)
+	for name, a := range algos {
+		vers[a.Version] = name
+	}
 	return
 }
 
-// GetCurrent returns the hardfork number code
-func GetCurrent(height int32) (curr int) {
-	// Trace("istestnet", IsTestnet)
-	if IsTestnet {
-		for i := range List {
-			if height >= List[i].TestnetStart {
-				curr = i
-			}
-		}
-	} else {
-		for i := range List {
-			if height >= List[i].ActivationHeight {
-				curr = i
-			}
+// algoSlices returns the algorithms of each hard fork sorted by version
+func algoSlices(forks []HardForks) (slices []AlgoSpecs) {
+	slices = make([]AlgoSpecs, len(forks))
+	for i := range forks {
+		for name, a := range forks[i].Algos {
+			slices[i] = append(slices[i], AlgoSpec{a.Version, name})
 		}
+		sort.Sort(slices[i])
 	}
 	return
 }
 
-// GetMinBits returns the minimum diff bits based on height and testnet
-func GetMinBits(algoname string, height int32) (mb uint32) {
-	curr := GetCurrent(height)
-	// Trace("GetMinBits", algoname, height, curr, List[curr].Algos)
-	mb = List[curr].Algos[algoname].MinBits
-	// TraceF("minbits %08x, %d", mb, mb)
-	return
-}
-
-// GetMinDiff returns the minimum difficulty in uint256 form
-func GetMinDiff(algoname string, height int32) (md *big.Int) {
-	// Trace("GetMinDiff", algoname)
-	minbits := GetMinBits(algoname, height)
-	// TraceF("mindiff minbits %08x", minbits)
-	return CompactToBig(minbits)
-}
-
-// GetTargetTimePerBlock returns the active block interval target based on
-// hard fork status
-func GetTargetTimePerBlock(height int32) (r int64) {
-	r = int64(List[GetCurrent(height)].TargetTimePerBlock)
-	return
+// averageInterval returns the target time between blocks of any algorithm
+// when each is found at its version interval
+func averageInterval(algos map[string]AlgoParams, slice AlgoSpecs) (
+	average float64) {
+	if len(slice) < 1 {
+		return
+	}
+	baseVersionInterval := float64(algos[slice[0].Name].VersionInterval)
+	for _, i := range slice {
+		vi := float64(algos[i.Name].VersionInterval)
+		if vi <= 0 {
+			return 0
+		}
+		average += baseVersionInterval / vi
+	}
+	return baseVersionInterval / average
 }
@@ -0,0 +1,294 @@
+package fork
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"time"
+)
+
+// Payee is an address and an amount in coins paid to it
+type Payee struct {
+	Address string
+	Amount  float64
+}
+
+// Disbursement is the special coinbase of the block a hard fork activates at,
+// which pays the payees and a multisig address of the core developers that
+// needs CoreSigs of the CorePubkeys to spend
+type Disbursement struct {
+	Payees []Payee
+	// CorePubkeys are the hex encoded public keys of the multisig address
+	CorePubkeys []string
+	CoreSigs    int
+	CoreAmount  float64
+}
+
+// Schedule is the hard forks of a network in the order they activate. Each
+// network has its own, so several networks can be used in one process.
+type Schedule struct {
+	Forks      []HardForks
+	algoSlices []AlgoSpecs
+	averages   []float64
+	// blacklists are the blacklisted addresses of each hard fork as sets
+	blacklists []map[string]struct{}
+}
+
+// NewSchedule returns a schedule of the given hard forks
+func NewSchedule(forks ...HardForks) (s *Schedule) {
+	s = &Schedule{Forks: forks}
+	s.update()
+	return
+}
+
+// update computes the lookups derived from the hard forks
+func (s *Schedule) update() {
+	s.algoSlices = algoSlices(s.Forks)
+	s.averages = make([]float64, len(s.Forks))
+	s.blacklists = make([]map[string]struct{}, len(s.Forks))
+	for i := range s.Forks {
+		s.averages[i] = averageInterval(s.Forks[i].Algos, s.algoSlices[i])
+		s.blacklists[i] = make(map[string]struct{}, len(s.Forks[i].Blacklist))
+		for _, addr := range s.Forks[i].Blacklist {
+			s.blacklists[i][addr] = struct{}{}
+		}
+	}
+}
+
+// Validate checks that a schedule is consistent and updates the lookups
+// derived from it, and must be called after the hard forks are changed, as
+// when they are overridden for a test network
+func (s *Schedule) Validate() (err error) {
+	if s == nil || len(s.Forks) < 1 {
+		return errors.New("no hard forks in schedule")
+	}
+	if s.Forks[0].ActivationHeight != 0 {
+		return errors.New("first hard fork must activate at height 0")
+	}
+	for i := range s.Forks {
+		hf := &s.Forks[i]
+		if hf.Number != uint32(i) {
+			return fmt.Errorf("hard fork %d is numbered %d", i, hf.Number)
+		}
+		if i > 0 && hf.ActivationHeight <= s.Forks[i-1].ActivationHeight {
+			return fmt.Errorf("hard fork %d activates at %d, not after the"+
+				" previous one at %d", i, hf.ActivationHeight,
+				s.Forks[i-1].ActivationHeight)
+		}
+		if hf.TargetTimePerBlock <= 0 || hf.AveragingInterval <= 0 {
+			return fmt.Errorf("hard fork %d must have a positive target time"+
+				" and averaging interval", i)
+		}
+		if len(hf.Algos) < 1 || len(hf.Algos) != len(hf.AlgoVers) {
+			return fmt.Errorf("hard fork %d has %d algorithms and %d"+
+				" versions", i, len(hf.Algos), len(hf.AlgoVers))
+		}
+		for version, name := range hf.AlgoVers {
+			a, ok := hf.Algos[name]
+			if !ok || a.Version != version {
+				return fmt.Errorf("hard fork %d version %d is not of"+
+					" algorithm '%s'", i, version, name)
+			}
+			if a.MinBits == 0 || a.VersionInterval < 0 {
+				return fmt.Errorf("hard fork %d algorithm '%s' has no minimum"+
+					" target or a negative interval", i, name)
+			}
+		}
+		if err = hf.Disbursement.validate(); err != nil {
+			return fmt.Errorf("hard fork %d disbursement: %v", i, err)
+		}
+		for _, addr := range hf.Blacklist {
+			if addr == "" {
+				return fmt.Errorf("hard fork %d has an empty blacklist"+
+					" address", i)
+			}
+		}
+	}
+	s.update()
+	return
+}
+
+func (d *Disbursement) validate() (err error) {
+	if d == nil {
+		return
+	}
+	for _, p := range d.Payees {
+		if p.Address == "" || p.Amount <= 0 {
+			return fmt.Errorf("invalid payee '%s' of %v", p.Address, p.Amount)
+		}
+	}
+	if d.CoreSigs < 1 || d.CoreSigs > len(d.CorePubkeys) || d.CoreSigs > 16 ||
+		len(d.CorePubkeys) > 16 {
+		return fmt.Errorf("%d of %d core signatures", d.CoreSigs,
+			len(d.CorePubkeys))
+	}
+	for _, key := range d.CorePubkeys {
+		var b []byte
+		if b, err = hex.DecodeString(key); err != nil || len(b) != 33 {
+			return fmt.Errorf("invalid core public key '%s'", key)
+		}
+	}
+	if d.CoreAmount <= 0 {
+		return fmt.Errorf("invalid core amount %v", d.CoreAmount)
+	}
+	return
+}
+
+// Copy returns a copy of a schedule that can be changed without changing the
+// schedule it was copied from
+func (s *Schedule) Copy() (c *Schedule) {
+	forks := make([]HardForks, len(s.Forks))
+	for i, hf := range s.Forks {
+		hf.Algos = make(map[string]AlgoParams, len(s.Forks[i].Algos))
+		for name, a := range s.Forks[i].Algos {
+			hf.Algos[name] = a
+		}
+		hf.AlgoVers = algoVers(hf.Algos)
+		if d := s.Forks[i].Disbursement; d != nil {
+			dc := *d
+			dc.Payees = append([]Payee(nil), d.Payees...)
+			dc.CorePubkeys = append([]string(nil), d.CorePubkeys...)
+			hf.Disbursement = &dc
+		}
+		hf.Blacklist = append([]string(nil), s.Forks[i].Blacklist...)
+		forks[i] = hf
+	}
+	return NewSchedule(forks...)
+}
+
+// SetActivation moves the activation of a hard fork to a height, as the
+// regression test and simulation networks allow. The schedule is left as it
+// was if the height is not valid.
+func (s *Schedule) SetActivation(number int, height int32) (err error) {
+	if number < 1 || number >= len(s.Forks) {
+		return fmt.Errorf("hard fork %d cannot be moved", number)
+	}
+	prev := s.Forks[number].ActivationHeight
+	s.Forks[number].ActivationHeight = height
+	if err = s.Validate(); err != nil {
+		s.Forks[number].ActivationHeight = prev
+	}
+	return
+}
+
+// SetMinBits sets the minimum target of every algorithm of every hard fork,
+// which the test networks use to make blocks trivial to mine
+func (s *Schedule) SetMinBits(bits uint32) {
+	for i := range s.Forks {
+		for name, a := range s.Forks[i].Algos {
+			a.MinBits = bits
+			s.Forks[i].Algos[name] = a
+		}
+	}
+}
+
+// Current returns the number of the hard fork active at a height
+func (s *Schedule) Current(height int32) (curr int) {
+	for i := range s.Forks {
+		if height >= s.Forks[i].ActivationHeight {
+			curr = i
+		}
+	}
+	return
+}
+
+// Fork returns the hard fork active at a height
+func (s *Schedule) Fork(height int32) *HardForks {
+	return &s.Forks[s.Current(height)]
+}
+
+// Activates returns whether a hard fork activates at a height
+func (s *Schedule) Activates(number int, height int32) bool {
+	return number < len(s.Forks) && s.Forks[number].ActivationHeight == height
+}
+
+// AlgoSlices returns the algorithms of a hard fork sorted by version
+func (s *Schedule) AlgoSlices(number int) AlgoSpecs {
+	return s.algoSlices[number]
+}
+
+// AverageInterval returns the target time between blocks of any algorithm of
+// a hard fork, when each is found at its version interval
+func (s *Schedule) AverageInterval(number int) float64 {
+	return s.averages[number]
+}
+
+// AlgoID returns the 'algo_id' of an algorithm at a height
+func (s *Schedule) AlgoID(algoname string, height int32) uint32 {
+	return s.Fork(height).Algos[algoname].AlgoID
+}
+
+// AlgoName returns the name of the algorithm of a block version at a height.
+// Before the first hard fork unknown versions are sha256d.
+func (s *Schedule) AlgoName(algoVer int32, height int32) (name string) {
+	hf := s.Current(height)
+	var ok bool
+	name, ok = s.Forks[hf].AlgoVers[algoVer]
+	if hf < 1 && !ok {
+		name = SHA256d
+	}
+	return
+}
+
+// AlgoVer returns the block version of an algorithm at a height, or of the
+// first algorithm if the name is not known
+func (s *Schedule) AlgoVer(name string, height int32) (version int32) {
+	hf := s.Current(height)
+	n := s.algoSlices[hf][0].Name
+	if _, ok := s.Forks[hf].Algos[name]; ok {
+		n = name
+	}
+	return s.Forks[hf].Algos[n].Version
+}
+
+// RandomVersion returns a random block version of the algorithms at a height
+func (s *Schedule) RandomVersion(height int32) int32 {
+	rand.Seed(time.Now().UnixNano())
+	return int32(rand.Intn(len(s.Fork(height).Algos)) + 5)
+}
+
+// AveragingInterval returns the number of blocks averaged by the retarget at
+// a height
+func (s *Schedule) AveragingInterval(height int32) int32 {
+	return s.Fork(height).AveragingInterval
+}
+
+// MinBits returns the minimum difficulty bits of an algorithm at a height
+func (s *Schedule) MinBits(algoname string, height int32) uint32 {
+	return s.Fork(height).Algos[algoname].MinBits
+}
+
+// MinDiff returns the minimum difficulty of an algorithm at a height in
+// uint256 form
+func (s *Schedule) MinDiff(algoname string, height int32) *big.Int {
+	return CompactToBig(s.MinBits(algoname, height))
+}
+
+// TargetTimePerBlock returns the target time between blocks at a height
+func (s *Schedule) TargetTimePerBlock(height int32) int64 {
+	return int64(s.Fork(height).TargetTimePerBlock)
+}
+
+// Disbursement returns the special coinbase of the block at a height, if a
+// hard fork with one activates there
+func (s *Schedule) Disbursement(height int32) *Disbursement {
+	for i := range s.Forks {
+		if s.Forks[i].ActivationHeight == height {
+			return s.Forks[i].Disbursement
+		}
+	}
+	return nil
+}
+
+// Blacklist returns the addresses that are suspended at a height
+func (s *Schedule) Blacklist(height int32) []string {
+	return s.Fork(height).Blacklist
+}
+
+// Blacklisted returns whether an encoded address is suspended at a height
+func (s *Schedule) Blacklisted(addr string, height int32) bool {
+	_, ok := s.blacklists[s.Current(height)][addr]
+	return ok
+}
@@ -0,0 +1,81 @@
+package fork
+
+import (
+	"testing"
+)
+
+// TestSetActivation ensures the activation of a hard fork can be moved to any
+// height after the previous one, and that a schedule is left unchanged by an
+// activation that is not valid.
+func TestSetActivation(t *testing.T) {
+	tests := []struct {
+		name    string
+		number  int
+		height  int32
+		wantErr bool
+	}{
+		{"fork at block 1", 1, 1, false},
+		{"later height", 1, 1000, false},
+		{"same height as the previous fork", 1, 0, true},
+		{"negative height", 1, -1, true},
+		{"first fork", 0, 1, true},
+		{"unknown fork", 2, 1, true},
+	}
+	for _, test := range tests {
+		s := NewSchedule(NewHalcyon(0), NewPlan9(250000))
+		err := s.SetActivation(test.number, test.height)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: moving hard fork %d to %d was not refused",
+					test.name, test.number, test.height)
+			}
+			if s.Forks[1].ActivationHeight != 250000 {
+				t.Errorf("%s: activation changed to %d by a refused move",
+					test.name, s.Forks[1].ActivationHeight)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if got := s.Current(test.height - 1); got != 0 {
+			t.Errorf("%s: hard fork %d active at %d, want 0", test.name, got,
+				test.height-1)
+		}
+		if got := s.Current(test.height); got != 1 {
+			t.Errorf("%s: hard fork %d active at %d, want 1", test.name, got,
+				test.height)
+		}
+		if !s.Activates(1, test.height) {
+			t.Errorf("%s: hard fork 1 does not activate at %d", test.name,
+				test.height)
+		}
+	}
+}
+
+// TestCopy ensures changing a copy of a schedule leaves the schedule it was
+// copied from unchanged.
+func TestCopy(t *testing.T) {
+	hf1 := NewPlan9(250000)
+	hf1.Blacklist = []string{"blacklisted"}
+	s := NewSchedule(NewHalcyon(0), hf1)
+	c := s.Copy()
+	if err := c.SetActivation(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	c.SetMinBits(0x207fffff)
+	if s.Forks[1].ActivationHeight != 250000 {
+		t.Fatalf("copied schedule activates at %d, want 250000",
+			s.Forks[1].ActivationHeight)
+	}
+	for name, a := range s.Forks[1].Algos {
+		if a.MinBits == 0x207fffff {
+			t.Fatalf("minimum target of '%s' changed in the copied schedule",
+				name)
+		}
+	}
+	if !c.Blacklisted("blacklisted", 1) || s.Blacklisted("blacklisted", 1) {
+		t.Fatal("blacklist does not follow the activation of the copy")
+	}
+}
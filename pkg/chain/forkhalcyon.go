@@ -3,8 +3,6 @@ package blockchain
 import (
 	"fmt"
 	"math/big"
-
-	"github.com/p9c/pod/pkg/chain/fork"
 )
 
 // calcNextRequiredDifficultyHalcyon calculates the required difficulty for the
@@ -24,10 +22,14 @@ func (b *BlockChain) CalcNextRequiredDifficultyHalcyon(workerNumber uint32, last
 		return newTargetBits, nil
 	}
 	// this sanitises invalid block versions according to legacy consensus quirks
-	algo := fork.GetAlgoVer(algoname, nH)
-	algoName := fork.GetAlgoName(algo, nH)
-	newTargetBits = fork.GetMinBits(algoName, nH)
-	prevNode := lastNode.GetLastWithAlgo(algo)
+	algo := b.params.Forks.AlgoVer(algoname, nH)
+	algoName := b.params.Forks.AlgoName(algo, nH)
+	newTargetBits = b.params.Forks.MinBits(algoName, nH)
+	// networks without retargeting mine every block at the minimum target
+	if b.params.PoWNoRetargeting {
+		return newTargetBits, nil
+	}
+	prevNode := lastNode.GetLastWithAlgo(algo, b.params.Forks)
 	if prevNode == nil {
 		if l {
 			Debug("prevNode is nil")
@@ -36,9 +38,9 @@ func (b *BlockChain) CalcNextRequiredDifficultyHalcyon(workerNumber uint32, last
 	}
 	firstNode := prevNode
 	for i := int32(0); firstNode != nil &&
-		i < fork.GetAveragingInterval(nH)-1; i++ {
+		i < b.params.Forks.AveragingInterval(nH)-1; i++ {
 		firstNode = firstNode.RelativeAncestor(1)
-		firstNode = firstNode.GetLastWithAlgo(algo)
+		firstNode = firstNode.GetLastWithAlgo(algo, b.params.Forks)
 	}
 	if firstNode == nil {
 		return newTargetBits, nil
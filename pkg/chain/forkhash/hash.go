@@ -1,6 +1,7 @@
 package forkhash
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/bitbandi/go-x11"
@@ -18,15 +19,6 @@ import (
 	chainhash "github.com/p9c/pod/pkg/chain/hash"
 )
 
-// HashReps allows the number of multiplication/division cycles to be
-// repeated before the final hash,
-// on release for mainnet this is probably set to 9 or so to raise the
-// difficulty to a reasonable level for the hard fork.
-// at 5 repetitions (first plus repeats, thus 4),
-// an example block header produces a number around 48kb in byte size and
-// ~119000 decimal digits, which is then finally hashed down to 32 bytes
-var HashReps = 2
-
 // Argon2i takes bytes, generates a Blake3 hash as salt, generates an argon2i key
 func Argon2i(bytes []byte) []byte {
 	return argon2.IDKey(reverse(bytes), bytes, 1, 4*1024, 1, 32)
@@ -144,33 +136,34 @@ func DivHash(hf func([]byte) []byte, blockbytes []byte, howmany int) []byte {
 	return hf(ddd)
 }
 
-// Hash computes the hash of bytes using the named hash
-func Hash(bytes []byte, name string, height int32) (out chainhash.Hash) {
-	hR := HashReps
-	if fork.IsTestnet {
-		switch {
-		case height == 1:
-			hR = 0
-			// case height < 10:
-			//	hR = 6
+// Hash computes the hash of bytes with the backend of a block version at a
+// height in the hard fork schedule of a network. If there is no backend for
+// the version an error is returned with the maximum hash, which meets no
+// proof of work target, so a missing backend cannot make a block valid.
+func Hash(s *fork.Schedule, bytes []byte, version int32,
+	height int32) (out chainhash.Hash, err error) {
+	b, ok := Get(s, version, height)
+	registryMx.RLock()
+	hf, known := hashers[b.BaseHash]
+	registryMx.RUnlock()
+	if !ok || !known {
+		for i := range out {
+			out[i] = 0xff
 		}
+		err = fmt.Errorf("no hash backend for version %d at height %d",
+			version, height)
+		return
 	}
-	switch name {
-	case fork.Scrypt:
-		if fork.GetCurrent(height) > 0 {
-			_ = out.SetBytes(DivHash(Scrypt, bytes, hR))
-		} else {
-			_ = out.SetBytes(Scrypt(bytes))
-		}
-	case fork.SHA256d:
-		if fork.GetCurrent(height) > 0 {
-			_ = out.SetBytes(DivHash(chainhash.DoubleHashB, bytes, hR))
-		} else {
-			_ = out.SetBytes(chainhash.DoubleHashB(
-				bytes))
-		}
-	default:
-		_ = out.SetBytes(DivHash(Blake3, bytes, hR))
+	reps := b.DivHashReps
+	// the block at height 1 of the networks the first hard fork activates on
+	// at that height is hashed without DivHash repetitions
+	if height == 1 && reps > 0 {
+		reps = 0
+	}
+	if reps == fork.NoDivHash {
+		_ = out.SetBytes(hf(bytes))
+	} else {
+		_ = out.SetBytes(DivHash(hf, bytes, reps))
 	}
 	return
 }
@@ -0,0 +1,96 @@
+package forkhash
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/p9c/pod/pkg/chain/fork"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+)
+
+// Names of the base hash functions besides those of the fork package
+const (
+	Argon2iHash = "argon2i"
+	Blake2bHash = "blake2b"
+	KeccakHash  = "keccak"
+	SkeinHash   = "skein"
+	StribogHash = "stribog"
+	X11Hash     = "x11"
+)
+
+// Backend is the hash a block version is mined with from an activation height
+type Backend struct {
+	// BaseHash is the name of the hash function the header is hashed with
+	BaseHash string
+	// DivHashReps is the number of DivHash repetitions before the base hash,
+	// or fork.NoDivHash to hash the header with the base hash directly
+	DivHashReps int
+	// ActivationHeight is the height the backend applies from
+	ActivationHeight int32
+}
+
+var (
+	registryMx sync.RWMutex
+	hashers    = map[string]func([]byte) []byte{
+		fork.Scrypt:  Scrypt,
+		fork.SHA256d: chainhash.DoubleHashB,
+		fork.Blake3:  Blake3,
+		Argon2iHash:  Argon2i,
+		Blake2bHash:  Blake2b,
+		KeccakHash:   Keccak,
+		SkeinHash:    Skein,
+		StribogHash:  Stribog,
+		X11Hash:      X11,
+	}
+)
+
+// RegisterHasher adds a base hash function that the algorithms of a hard fork
+// schedule can use, replacing any with the same name. This allows new hashes
+// to be tested on regtest and simnet by assigning them in the schedule of the
+// network, without changing the consensus code.
+func RegisterHasher(name string, hf func([]byte) []byte) {
+	registryMx.Lock()
+	defer registryMx.Unlock()
+	hashers[name] = hf
+}
+
+// Validate checks that every algorithm of a hard fork schedule has a
+// registered base hash and a valid number of DivHash repetitions
+func Validate(s *fork.Schedule) (err error) {
+	registryMx.RLock()
+	defer registryMx.RUnlock()
+	for i := range s.Forks {
+		for name, a := range s.Forks[i].Algos {
+			if _, ok := hashers[a.BaseHash]; !ok {
+				return fmt.Errorf("unknown base hash '%s' for algorithm '%s'"+
+					" of hard fork %d", a.BaseHash, name, i)
+			}
+			if a.DivHashReps < fork.NoDivHash {
+				return fmt.Errorf("invalid DivHash repetitions %d for"+
+					" algorithm '%s' of hard fork %d", a.DivHashReps, name, i)
+			}
+		}
+	}
+	return
+}
+
+// Get returns the backend of a block version at a height in a hard fork
+// schedule. Each algorithm of the hard fork active at the height is hashed
+// with its own base hash, and versions it does not have are hashed like its
+// first algorithm, or with sha256d before the first hard fork, as they are
+// named by the AlgoName of the schedule.
+func Get(s *fork.Schedule, version int32, height int32) (b Backend, ok bool) {
+	if s == nil || len(s.Forks) < 1 {
+		return
+	}
+	number := s.Current(height)
+	hf := &s.Forks[number]
+	a, found := hf.Algos[s.AlgoName(version, height)]
+	if !found {
+		a, found = hf.Algos[s.AlgoSlices(number)[0].Name]
+	}
+	if !found {
+		return
+	}
+	return Backend{a.BaseHash, a.DivHashReps, hf.ActivationHeight}, true
+}
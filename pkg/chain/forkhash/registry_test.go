@@ -0,0 +1,228 @@
+package forkhash
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/p9c/pod/pkg/chain/fork"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+)
+
+// goldenHeader is a serialized block header hashed by the golden vectors, with
+// the version in its first four bytes replaced by the version of each vector.
+const goldenHeader = "020000003b86630c78db663e96b29b39ac630eaedf1408af8a5e9b29" +
+	"662cb659830e0d65d00f540774a012ea6714678aa02e5fae66613553a753742f68878992" +
+	"eeec74e800096e88ffff0f1e39300000"
+
+// goldenHashes are the hashes of goldenHeader given by the hash switch that
+// preceded the registry, for the versions of every algorithm and an unknown
+// one, on both sides of the first hard fork on mainnet and the test networks.
+var goldenHashes = []struct {
+	testnet bool
+	version int32
+	height  int32
+	hash    string
+}{
+	{false, 2, 0, "effcb82465fa5430b572e18986b0a94c5b20ba01d728fb3f4fc49072c4e4d9d7"},
+	{false, 2, 249999, "effcb82465fa5430b572e18986b0a94c5b20ba01d728fb3f4fc49072c4e4d9d7"},
+	{false, 2, 250000, "cc1a847997f1c030966984dca6cce732005fb17c41ddc25cd460637591262e8a"},
+	{false, 2, 300000, "cc1a847997f1c030966984dca6cce732005fb17c41ddc25cd460637591262e8a"},
+	{false, 514, 0, "acb16411e5a446627140dbdaff2bf2dbb744ed293949339bc1aad627adc4b8db"},
+	{false, 514, 249999, "acb16411e5a446627140dbdaff2bf2dbb744ed293949339bc1aad627adc4b8db"},
+	{false, 514, 250000, "91ae91165281ed67e3cbd730b6eaf68279749722f549cc61840af0ba9cb525c0"},
+	{false, 514, 300000, "91ae91165281ed67e3cbd730b6eaf68279749722f549cc61840af0ba9cb525c0"},
+	{false, 1, 0, "69e54d9e228cd3f5f9061f211107c880f7040ddaa5365833bc87eed9f86015b1"},
+	{false, 1, 249999, "69e54d9e228cd3f5f9061f211107c880f7040ddaa5365833bc87eed9f86015b1"},
+	{false, 1, 250000, "17433e6db855e77963b7420e04c4da1cf937fed2d51ba9511d149f53cf11d581"},
+	{false, 1, 300000, "17433e6db855e77963b7420e04c4da1cf937fed2d51ba9511d149f53cf11d581"},
+	{false, 5, 0, "b134ff1fe881c799af07b43dbd421888729cce742a75f92a6abfdac9a00b7a8e"},
+	{false, 5, 249999, "b134ff1fe881c799af07b43dbd421888729cce742a75f92a6abfdac9a00b7a8e"},
+	{false, 5, 250000, "528ed0a3b8d849ffd406577864b7def43b3da4294ccf523dc6593547c748e78c"},
+	{false, 5, 300000, "528ed0a3b8d849ffd406577864b7def43b3da4294ccf523dc6593547c748e78c"},
+	{false, 6, 0, "2ce50bb2c5eb42767d31ccd248d16914f8fc8ef1bce562df1b7c89636a4f8851"},
+	{false, 6, 249999, "2ce50bb2c5eb42767d31ccd248d16914f8fc8ef1bce562df1b7c89636a4f8851"},
+	{false, 6, 250000, "b99763d0a435974aa88538a4462026c58d19981e6a91368f699fb1a61a060873"},
+	{false, 6, 300000, "b99763d0a435974aa88538a4462026c58d19981e6a91368f699fb1a61a060873"},
+	{false, 7, 0, "6bf54e033a20f0288971bd1342284a13159c58a725e2b83035165046113bdbee"},
+	{false, 7, 249999, "6bf54e033a20f0288971bd1342284a13159c58a725e2b83035165046113bdbee"},
+	{false, 7, 250000, "6ed9e4c84e9d3bad649ee5bd4d7f601c48e94435e787dc4ec765aa0563978d82"},
+	{false, 7, 300000, "6ed9e4c84e9d3bad649ee5bd4d7f601c48e94435e787dc4ec765aa0563978d82"},
+	{false, 8, 0, "3a532f5a469352e76df954cfb3cacac089b49c9bb6127082bc674580c7284b81"},
+	{false, 8, 249999, "3a532f5a469352e76df954cfb3cacac089b49c9bb6127082bc674580c7284b81"},
+	{false, 8, 250000, "a3eb0f8fb393d91aee4a4824fe1f773c8c594632c861be8dae48a6dfb35db883"},
+	{false, 8, 300000, "a3eb0f8fb393d91aee4a4824fe1f773c8c594632c861be8dae48a6dfb35db883"},
+	{false, 9, 0, "820284b98b8d494bb4eeb08e105f26f4cc0e2eaa5f67bb25f4e22ffe953e1e46"},
+	{false, 9, 249999, "820284b98b8d494bb4eeb08e105f26f4cc0e2eaa5f67bb25f4e22ffe953e1e46"},
+	{false, 9, 250000, "672ae7efd43dd54de6ea492a852c49b3884584fbf8c8da8668e201903a4dcffe"},
+	{false, 9, 300000, "672ae7efd43dd54de6ea492a852c49b3884584fbf8c8da8668e201903a4dcffe"},
+	{false, 10, 0, "85b8073dcce105d1c5e01b67f299f4558e1aca92c18004eb8da10dd68b08bbe0"},
+	{false, 10, 249999, "85b8073dcce105d1c5e01b67f299f4558e1aca92c18004eb8da10dd68b08bbe0"},
+	{false, 10, 250000, "6a42f4cf70fe7d9193db3aded729e0e96b4717a2c2fa95fad3c6e12adc3022eb"},
+	{false, 10, 300000, "6a42f4cf70fe7d9193db3aded729e0e96b4717a2c2fa95fad3c6e12adc3022eb"},
+	{false, 11, 0, "14d61488929cdc97959fc0f46ccf472c5231303fec12fdb1e015c07aee0354a5"},
+	{false, 11, 249999, "14d61488929cdc97959fc0f46ccf472c5231303fec12fdb1e015c07aee0354a5"},
+	{false, 11, 250000, "52c16af3e5de60993643ca92c37f4b191e7db314dd40eb6b7b9eb9d23175dff2"},
+	{false, 11, 300000, "52c16af3e5de60993643ca92c37f4b191e7db314dd40eb6b7b9eb9d23175dff2"},
+	{false, 12, 0, "86f5fad20bd76a7ddb42ec651cbde60ed84e6e0a2e7a7233535c75597b156b19"},
+	{false, 12, 249999, "86f5fad20bd76a7ddb42ec651cbde60ed84e6e0a2e7a7233535c75597b156b19"},
+	{false, 12, 250000, "3e978b8712da6e34df90e4e586e5627a75fe7af0e9522af487825df3ec6d99cd"},
+	{false, 12, 300000, "3e978b8712da6e34df90e4e586e5627a75fe7af0e9522af487825df3ec6d99cd"},
+	{false, 13, 0, "d8c1b8331f9f5bc87b7cd453eeec513375c25e5ed4f0fd0adecca7c0f3ef4ec7"},
+	{false, 13, 249999, "d8c1b8331f9f5bc87b7cd453eeec513375c25e5ed4f0fd0adecca7c0f3ef4ec7"},
+	{false, 13, 250000, "1c8e86a5beb5994f47149d052f53a301454f86cb13f85a4f0cb55dc5dd31a6e7"},
+	{false, 13, 300000, "1c8e86a5beb5994f47149d052f53a301454f86cb13f85a4f0cb55dc5dd31a6e7"},
+	{false, 99, 0, "ac236117f6dfcd18372dbea35f2bb50ceefe0f0d902ed6dac040284a7e63c89a"},
+	{false, 99, 249999, "ac236117f6dfcd18372dbea35f2bb50ceefe0f0d902ed6dac040284a7e63c89a"},
+	{false, 99, 250000, "f778bad4703e3c07b7b40b32c937aaf0e7ceab81ae180979a1e251c6e26f4165"},
+	{false, 99, 300000, "f778bad4703e3c07b7b40b32c937aaf0e7ceab81ae180979a1e251c6e26f4165"},
+	{true, 2, 0, "effcb82465fa5430b572e18986b0a94c5b20ba01d728fb3f4fc49072c4e4d9d7"},
+	{true, 2, 1, "fe610feaf5b3e551bdd977be9156d2bd2adf79b7d510f12d76b95edc64b5fb94"},
+	{true, 2, 2, "cc1a847997f1c030966984dca6cce732005fb17c41ddc25cd460637591262e8a"},
+	{true, 2, 1000, "cc1a847997f1c030966984dca6cce732005fb17c41ddc25cd460637591262e8a"},
+	{true, 514, 0, "acb16411e5a446627140dbdaff2bf2dbb744ed293949339bc1aad627adc4b8db"},
+	{true, 514, 1, "2684f1a0acfb10b9100628b37311bd0040edff2745c0c087cea8c187fab62b04"},
+	{true, 514, 2, "91ae91165281ed67e3cbd730b6eaf68279749722f549cc61840af0ba9cb525c0"},
+	{true, 514, 1000, "91ae91165281ed67e3cbd730b6eaf68279749722f549cc61840af0ba9cb525c0"},
+	{true, 1, 0, "69e54d9e228cd3f5f9061f211107c880f7040ddaa5365833bc87eed9f86015b1"},
+	{true, 1, 1, "b6b8e3c7602412f319b1485e897193d065f21aed785ebbd85a6d6d1022d65acc"},
+	{true, 1, 2, "17433e6db855e77963b7420e04c4da1cf937fed2d51ba9511d149f53cf11d581"},
+	{true, 1, 1000, "17433e6db855e77963b7420e04c4da1cf937fed2d51ba9511d149f53cf11d581"},
+	{true, 5, 0, "b134ff1fe881c799af07b43dbd421888729cce742a75f92a6abfdac9a00b7a8e"},
+	{true, 5, 1, "cbd1a2f25467084628a463b6a1f50d1d51582119b762af58693470ed54e5a777"},
+	{true, 5, 2, "528ed0a3b8d849ffd406577864b7def43b3da4294ccf523dc6593547c748e78c"},
+	{true, 5, 1000, "528ed0a3b8d849ffd406577864b7def43b3da4294ccf523dc6593547c748e78c"},
+	{true, 6, 0, "2ce50bb2c5eb42767d31ccd248d16914f8fc8ef1bce562df1b7c89636a4f8851"},
+	{true, 6, 1, "d36bc2f9ae8c1180cf90f154100c0dd3fc301e66f1d99c89352299a45cca720b"},
+	{true, 6, 2, "b99763d0a435974aa88538a4462026c58d19981e6a91368f699fb1a61a060873"},
+	{true, 6, 1000, "b99763d0a435974aa88538a4462026c58d19981e6a91368f699fb1a61a060873"},
+	{true, 7, 0, "6bf54e033a20f0288971bd1342284a13159c58a725e2b83035165046113bdbee"},
+	{true, 7, 1, "3ea834dd6c3b10e6a2809c468c864f1f4d79520b5867b2ca2a824763ca60404d"},
+	{true, 7, 2, "6ed9e4c84e9d3bad649ee5bd4d7f601c48e94435e787dc4ec765aa0563978d82"},
+	{true, 7, 1000, "6ed9e4c84e9d3bad649ee5bd4d7f601c48e94435e787dc4ec765aa0563978d82"},
+	{true, 8, 0, "3a532f5a469352e76df954cfb3cacac089b49c9bb6127082bc674580c7284b81"},
+	{true, 8, 1, "c9b8078d21f1a227dd8bc615370cd463eb2b0e6b7b530b7a19601cce13676744"},
+	{true, 8, 2, "a3eb0f8fb393d91aee4a4824fe1f773c8c594632c861be8dae48a6dfb35db883"},
+	{true, 8, 1000, "a3eb0f8fb393d91aee4a4824fe1f773c8c594632c861be8dae48a6dfb35db883"},
+	{true, 9, 0, "820284b98b8d494bb4eeb08e105f26f4cc0e2eaa5f67bb25f4e22ffe953e1e46"},
+	{true, 9, 1, "9ee4455263e2be055c5cf6bfb9aa09709c8c6119bc92ad6a5e9a168b615e5ad1"},
+	{true, 9, 2, "672ae7efd43dd54de6ea492a852c49b3884584fbf8c8da8668e201903a4dcffe"},
+	{true, 9, 1000, "672ae7efd43dd54de6ea492a852c49b3884584fbf8c8da8668e201903a4dcffe"},
+	{true, 10, 0, "85b8073dcce105d1c5e01b67f299f4558e1aca92c18004eb8da10dd68b08bbe0"},
+	{true, 10, 1, "a1a0bd8f473a28a56892b77ba6c3f8c1d95c17489ef820d671687254ff53ed1b"},
+	{true, 10, 2, "6a42f4cf70fe7d9193db3aded729e0e96b4717a2c2fa95fad3c6e12adc3022eb"},
+	{true, 10, 1000, "6a42f4cf70fe7d9193db3aded729e0e96b4717a2c2fa95fad3c6e12adc3022eb"},
+	{true, 11, 0, "14d61488929cdc97959fc0f46ccf472c5231303fec12fdb1e015c07aee0354a5"},
+	{true, 11, 1, "7f9e97fb5fc1c464c1b510761445d6bff21ffaff6e8dba3b81e1c5bfa30959f4"},
+	{true, 11, 2, "52c16af3e5de60993643ca92c37f4b191e7db314dd40eb6b7b9eb9d23175dff2"},
+	{true, 11, 1000, "52c16af3e5de60993643ca92c37f4b191e7db314dd40eb6b7b9eb9d23175dff2"},
+	{true, 12, 0, "86f5fad20bd76a7ddb42ec651cbde60ed84e6e0a2e7a7233535c75597b156b19"},
+	{true, 12, 1, "0fbd5c9854449a0482b33a3e0d02b2f758ad1dc764c41bd7a06fe1fff630beae"},
+	{true, 12, 2, "3e978b8712da6e34df90e4e586e5627a75fe7af0e9522af487825df3ec6d99cd"},
+	{true, 12, 1000, "3e978b8712da6e34df90e4e586e5627a75fe7af0e9522af487825df3ec6d99cd"},
+	{true, 13, 0, "d8c1b8331f9f5bc87b7cd453eeec513375c25e5ed4f0fd0adecca7c0f3ef4ec7"},
+	{true, 13, 1, "39a8a2218919aba84bd31c9be9306bb12763c969bc2fc53b1e9c7fb39d59fbb5"},
+	{true, 13, 2, "1c8e86a5beb5994f47149d052f53a301454f86cb13f85a4f0cb55dc5dd31a6e7"},
+	{true, 13, 1000, "1c8e86a5beb5994f47149d052f53a301454f86cb13f85a4f0cb55dc5dd31a6e7"},
+	{true, 99, 0, "ac236117f6dfcd18372dbea35f2bb50ceefe0f0d902ed6dac040284a7e63c89a"},
+	{true, 99, 1, "a25e32a52436065c928997d15a6e1adffb93be5ca8757984968cec4e168d5fb2"},
+	{true, 99, 2, "f778bad4703e3c07b7b40b32c937aaf0e7ceab81ae180979a1e251c6e26f4165"},
+	{true, 99, 1000, "f778bad4703e3c07b7b40b32c937aaf0e7ceab81ae180979a1e251c6e26f4165"},
+}
+
+// TestHashGolden ensures the backends of the hard fork schedules of mainnet
+// and the test networks hash every block version as the hash switch that
+// preceded the registry did.
+func TestHashGolden(t *testing.T) {
+	header, err := hex.DecodeString(goldenHeader)
+	if err != nil {
+		t.Fatalf("hex.DecodeString: %v", err)
+	}
+	mainnet := fork.NewSchedule(fork.NewHalcyon(0), fork.NewPlan9(250000))
+	testnet := fork.NewSchedule(fork.NewHalcyon(0), fork.NewPlan9(1))
+	for i, test := range goldenHashes {
+		s := mainnet
+		if test.testnet {
+			s = testnet
+		}
+		binary.LittleEndian.PutUint32(header, uint32(test.version))
+		got, err := Hash(s, header, test.version, test.height)
+		if err != nil {
+			t.Fatalf("Hash #%d: %v", i, err)
+		}
+		if got.String() != test.hash {
+			t.Errorf("Hash #%d (testnet %v version %d height %d): got %s, "+
+				"want %s", i, test.testnet, test.version, test.height, got,
+				test.hash)
+		}
+	}
+}
+
+// TestHashNoBackend ensures hashing fails closed with an error and the maximum
+// hash, which meets no target, when there is no backend for a version.
+func TestHashNoBackend(t *testing.T) {
+	header, err := hex.DecodeString(goldenHeader)
+	if err != nil {
+		t.Fatalf("hex.DecodeString: %v", err)
+	}
+	hf := fork.NewPlan9(1)
+	for name, a := range hf.Algos {
+		a.BaseHash = "unregistered"
+		hf.Algos[name] = a
+	}
+	unregistered := fork.NewSchedule(fork.NewHalcyon(0), hf)
+	version := unregistered.AlgoSlices(1)[0].Version
+	tests := []struct {
+		name string
+		s    *fork.Schedule
+	}{
+		{"nil schedule", nil},
+		{"empty schedule", &fork.Schedule{}},
+		{"unregistered base hash", unregistered},
+	}
+	var max chainhash.Hash
+	for i := range max {
+		max[i] = 0xff
+	}
+	for _, test := range tests {
+		got, err := Hash(test.s, header, version, 10)
+		if err == nil {
+			t.Errorf("%s: hashed without a backend", test.name)
+		}
+		if got != max {
+			t.Errorf("%s: got hash %s, want the maximum hash", test.name,
+				got)
+		}
+	}
+}
+
+// TestValidate ensures schedules with algorithms using unregistered base hashes
+// or invalid DivHash repetitions are rejected.
+func TestValidate(t *testing.T) {
+	newSchedule := func(baseHash string, reps int) *fork.Schedule {
+		hf := fork.NewPlan9(1)
+		for name, a := range hf.Algos {
+			a.BaseHash, a.DivHashReps = baseHash, reps
+			hf.Algos[name] = a
+		}
+		return fork.NewSchedule(fork.NewHalcyon(0), hf)
+	}
+	tests := []struct {
+		name     string
+		baseHash string
+		reps     int
+		valid    bool
+	}{
+		{"registered", KeccakHash, fork.P9HashReps, true},
+		{"no DivHash", SkeinHash, fork.NoDivHash, true},
+		{"unregistered", "md5", fork.P9HashReps, false},
+		{"negative repetitions", fork.Blake3, fork.NoDivHash - 1, false},
+	}
+	for _, test := range tests {
+		err := Validate(newSchedule(test.baseHash, test.reps))
+		if (err == nil) != test.valid {
+			t.Errorf("Validate (%s): got error %v, want valid %v", test.name,
+				err, test.valid)
+		}
+	}
+}
@@ -8,15 +8,14 @@ import (
 	"github.com/VividCortex/ewma"
 
 	"github.com/p9c/pod/pkg/chain/fork"
-	"github.com/p9c/pod/pkg/chain/wire"
 )
 
-func GetAlgStamps(algoName string, startHeight int32, lastNode *BlockNode) (last *BlockNode,
+func (b *BlockChain) GetAlgStamps(algoName string, startHeight int32, lastNode *BlockNode) (last *BlockNode,
 	found bool, algStamps []int64, version int32) {
 
-	version = fork.P9Algos[algoName].Version
+	version = b.plan9().Algos[algoName].Version
 	for ln := lastNode; ln != nil && ln.height > startHeight &&
-		len(algStamps) <= int(fork.List[1].AveragingInterval); ln = ln.
+		len(algStamps) <= int(b.plan9().AveragingInterval); ln = ln.
 		RelativeAncestor(1) {
 		if ln.version == version && ln.height > startHeight {
 			algStamps = append(algStamps, ln.timestamp)
@@ -36,10 +35,10 @@ func GetAlgStamps(algoName string, startHeight int32, lastNode *BlockNode) (last
 	return
 }
 
-func GetAllStamps(startHeight int32, lastNode *BlockNode) (allStamps []int64) {
+func (b *BlockChain) GetAllStamps(startHeight int32, lastNode *BlockNode) (allStamps []int64) {
 
 	for ln := lastNode; ln != nil && ln.height > startHeight &&
-		len(allStamps) <= int(fork.List[1].AveragingInterval); ln = ln.RelativeAncestor(1) {
+		len(allStamps) <= int(b.plan9().AveragingInterval); ln = ln.RelativeAncestor(1) {
 		allStamps = append(allStamps, ln.timestamp)
 	}
 	// Debug(allStamps)
@@ -52,9 +51,9 @@ func GetAllStamps(startHeight int32, lastNode *BlockNode) (allStamps []int64) {
 	return
 }
 
-func GetAll(allStamps []int64) (allAv, allAdj float64) {
+func (b *BlockChain) GetAll(allStamps []int64) (allAv, allAdj float64) {
 	allAdj = 1
-	allAv = fork.P9Average
+	allAv = b.params.Forks.AverageInterval(1)
 	// calculate intervals
 	allIntervals := make([]float64, len(allStamps)-1)
 	for i := range allStamps {
@@ -72,7 +71,7 @@ func GetAll(allStamps []int64) (allAv, allAdj float64) {
 	allAv = aewma.Value()
 	// Warn(allAv)
 	if allAv != 0 {
-		allAdj = allAv / fork.P9Average
+		allAdj = allAv / b.params.Forks.AverageInterval(1)
 	}
 	return
 }
@@ -103,25 +102,25 @@ func GetAlg(algStamps []int64, targetTimePerBlock float64) (algAv, algAdj float6
 func (b *BlockChain) CalcNextRequiredDifficultyPlan9(lastNodeP *BlockNode, algoName string,
 	l bool) (newTargetBits uint32, adjustment float64, err error) {
 	lastNode := lastNodeP
-
-	algoVer := fork.GetAlgoVer(algoName, lastNode.height+1)
-	ttpb := float64(fork.List[1].Algos[algoName].VersionInterval)
+	// networks without retargeting mine every block at the minimum target
+	if b.params.PoWNoRetargeting {
+		return b.params.Forks.MinBits(algoName, lastNode.height+1), 1, nil
+	}
+	algoVer := b.params.Forks.AlgoVer(algoName, lastNode.height+1)
+	ttpb := float64(b.plan9().Algos[algoName].VersionInterval)
 	newTargetBits = fork.SecondPowLimitBits
 	const minAvSamples = 3
 	adjustment = 1
-	var algAdj, allAdj, algAv, allAv float64 = 1, 1, ttpb, fork.P9Average
+	var algAdj, allAdj, algAv, allAv float64 = 1, 1, ttpb, b.params.Forks.AverageInterval(1)
 	if lastNode == nil {
 		Warn("lastNode is nil")
 	}
 	// algoInterval := fork.P9Algos[algoname].VersionInterval
-	startHeight := fork.List[1].ActivationHeight
-	if b.params.Net == wire.TestNet3 {
-		startHeight = fork.List[1].TestnetStart
-	}
-	allStamps := GetAllStamps(startHeight, lastNode)
-	last, _, algStamps, algoVer := GetAlgStamps(algoName, startHeight, lastNode)
+	startHeight := b.plan9().ActivationHeight
+	allStamps := b.GetAllStamps(startHeight, lastNode)
+	last, _, algStamps, algoVer := b.GetAlgStamps(algoName, startHeight, lastNode)
 	if len(allStamps) > minAvSamples {
-		allAv, allAdj = GetAll(allStamps)
+		allAv, allAdj = b.GetAll(allStamps)
 	}
 	if len(algStamps) > minAvSamples {
 		algAv, algAdj = GetAlg(algStamps, ttpb)
@@ -154,7 +153,7 @@ func (b *BlockChain) CalcNextRequiredDifficultyPlan9(lastNodeP *BlockNode, algoN
 	if l {
 		// if lastNode.version == algoVer {
 		Debugc(func() string {
-			an := fork.List[1].AlgoVers[algoVer]
+			an := b.plan9().AlgoVers[algoVer]
 			pad := 8 - len(an)
 			if pad > 0 {
 				an += strings.Repeat(" ", pad)
@@ -176,7 +175,7 @@ func (b *BlockChain) CalcNextRequiredDifficultyPlan9(lastNodeP *BlockNode, algoN
 				an,
 				RightJustify(fmt.Sprintf("%4.2f", algAv), 8),
 				RightJustify(fmt.Sprintf("%4.2f", allAv), 7),
-				fork.P9Average,
+				b.params.Forks.AverageInterval(1),
 				RightJustify(fmt.Sprintf("%4.2f", factor), 7),
 				symbol,
 				bits,
@@ -205,7 +204,7 @@ func (b *BlockChain) CalcNextRequiredDifficultyPlan9old(lastNode *BlockNode, alg
 	}
 	allTimeAv, allTimeDiv, qhourDiv, hourDiv,
 		dayDiv := b.GetCommonP9Averages(lastNode, nH)
-	algoVer := fork.GetAlgoVer(algoName, nH)
+	algoVer := b.params.Forks.AlgoVer(algoName, nH)
 	since, ttpb, timeSinceAlgo, startHeight, last := b.GetP9Since(lastNode, algoVer)
 	if last == nil {
 		return
@@ -226,7 +225,7 @@ func (b *BlockChain) CalcNextRequiredDifficultyPlan9old(lastNode *BlockNode, alg
 		Tracef("newTarget %064x %08x", newTarget, newTargetBits)
 	}
 	if l {
-		an := fork.List[1].AlgoVers[algoVer]
+		an := b.plan9().AlgoVers[algoVer]
 		pad := 9 - len(an)
 		if pad > 0 {
 			an += strings.Repeat(" ", pad)
@@ -244,7 +243,7 @@ func (b *BlockChain) CalcNextRequiredDifficultyPlan9old(lastNode *BlockNode, alg
 				RightJustify(fmt.Sprintf("%3.2fq", qhourDiv*ttpb), 7),
 				RightJustify(fmt.Sprintf("%3.2fA", algDiv*ttpb), 7),
 				RightJustify(fmt.Sprintf("%3.0f %3.3fD",
-					since-ttpb*float64(len(fork.List[1].Algos)), timeSinceAlgo*ttpb), 13),
+					since-ttpb*float64(len(b.plan9().Algos)), timeSinceAlgo*ttpb), 13),
 				RightJustify(fmt.Sprintf("%4.4fx", 1/adjustment), 11),
 				newTargetBits,
 			)
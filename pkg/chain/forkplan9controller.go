@@ -33,25 +33,25 @@ type TargetBits map[int32]uint32
 func (b *BlockChain) CalcNextRequiredDifficultyPlan9Controller(
 	lastNode *BlockNode) (newTargetBits TargetBits, err error) {
 	nH := lastNode.height + 1
-	currFork := fork.GetCurrent(nH)
+	currFork := b.params.Forks.Current(nH)
 	nTB := make(TargetBits)
 	switch currFork {
 	case 0:
-		for i := range fork.List[0].Algos {
-			v := fork.List[0].Algos[i].Version
+		for i := range b.params.Forks.Forks[0].Algos {
+			v := b.params.Forks.Forks[0].Algos[i].Version
 			nTB[v], err = b.CalcNextRequiredDifficultyHalcyon(0, lastNode, i, true)
 		}
 		return nTB, nil
 	case 1:
 		if b.DifficultyHeight.Load() != nH {
 			b.DifficultyHeight.Store(nH)
-			currFork := fork.GetCurrent(nH)
-			algos := make(AlgoList, len(fork.List[currFork].Algos))
+			currFork := b.params.Forks.Current(nH)
+			algos := make(AlgoList, len(b.params.Forks.Forks[currFork].Algos))
 			var counter int
-			for i := range fork.List[1].Algos {
+			for i := range b.plan9().Algos {
 				algos[counter] = Algo{
 					Name:   i,
-					Params: fork.List[currFork].Algos[i],
+					Params: b.params.Forks.Forks[currFork].Algos[i],
 				}
 				counter++
 			}
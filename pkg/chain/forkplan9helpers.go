@@ -4,18 +4,14 @@ import (
 	"github.com/VividCortex/ewma"
 
 	"github.com/p9c/pod/pkg/chain/fork"
-	"github.com/p9c/pod/pkg/chain/wire"
 )
 
 func (b *BlockChain) GetCommonP9Averages(lastNode *BlockNode,
 	nH int32) (allTimeAv, allTimeDiv, qhourDiv, hourDiv, dayDiv float64) {
 	const minAvSamples = 2
 	allTimeAv, allTimeDiv, qhourDiv, hourDiv, dayDiv = 1.0, 1.0, 1.0, 1.0, 1.0
-	ttpb := float64(fork.List[1].TargetTimePerBlock)
-	startHeight := fork.List[1].ActivationHeight
-	if b.params.Net == wire.TestNet3 {
-		startHeight = fork.List[1].TestnetStart
-	}
+	ttpb := float64(b.plan9().TargetTimePerBlock)
+	startHeight := b.plan9().ActivationHeight
 	if nH <= startHeight {
 		Debug("on hard fork", nH, startHeight)
 		return
@@ -43,16 +39,16 @@ func (b *BlockChain) GetCommonP9Averages(lastNode *BlockNode,
 		// the previous if should prevent this occurring
 	}
 	allTimeDiv = capP9Adjustment(allTimeDiv)
-	oneHour := 60 * 60 / fork.List[1].TargetTimePerBlock
+	oneHour := 60 * 60 / b.plan9().TargetTimePerBlock
 	oneDay := oneHour * 24
-	qHour := 60 * 60 / fork.List[1].TargetTimePerBlock / 4
+	qHour := 60 * 60 / b.plan9().TargetTimePerBlock / 4
 	dayBlock := lastNode.RelativeAncestor(oneDay)
 	dayDiv = allTimeDiv
 	if dayBlock != nil {
 		// collect timestamps within averaging interval
 		dayStamps := []int64{lastNode.timestamp}
 		for ln := lastNode; ln != nil && ln.height > startHeight+2 &&
-			len(dayStamps) <= int(fork.List[1].AveragingInterval); {
+			len(dayStamps) <= int(b.plan9().AveragingInterval); {
 			ln = ln.RelativeAncestor(oneDay)
 			if ln == nil || ln.timestamp < oldestStamp || ln.height < startHeight {
 				break
@@ -86,7 +82,7 @@ func (b *BlockChain) GetCommonP9Averages(lastNode *BlockNode,
 		// collect timestamps within averaging interval
 		hourStamps := []int64{lastNode.timestamp}
 		for ln := lastNode; ln.height > startHeight+2 &&
-			len(hourStamps) <= int(fork.List[1].AveragingInterval); {
+			len(hourStamps) <= int(b.plan9().AveragingInterval); {
 			ln = ln.RelativeAncestor(oneHour)
 			if ln == nil || ln.timestamp < oldestStamp || ln.height < startHeight {
 				break
@@ -120,7 +116,7 @@ func (b *BlockChain) GetCommonP9Averages(lastNode *BlockNode,
 		// collect timestamps within averaging interval
 		qhourStamps := []int64{lastNode.timestamp}
 		for ln := lastNode; ln != nil && ln.height > startHeight &&
-			len(qhourStamps) <= int(fork.List[1].AveragingInterval); {
+			len(qhourStamps) <= int(b.plan9().AveragingInterval); {
 			ln = ln.RelativeAncestor(qHour)
 			if ln == nil || ln.timestamp < oldestStamp || ln.height < startHeight {
 				break
@@ -158,7 +154,7 @@ func (b *BlockChain) GetP9AlgoDiv(allTimeDiv float64, last *BlockNode,
 	algDiv = allTimeDiv
 	algStamps := []uint64{uint64(last.timestamp)}
 	for ln := last; ln != nil && ln.height > startHeight &&
-		len(algStamps) <= int(fork.List[1].AveragingInterval); ln = ln.
+		len(algStamps) <= int(b.plan9().AveragingInterval); ln = ln.
 		RelativeAncestor(1) {
 		if ln.version == algoVer && ln.height > startHeight {
 			algStamps = append(algStamps, uint64(ln.timestamp))
@@ -181,8 +177,8 @@ func (b *BlockChain) GetP9AlgoDiv(allTimeDiv float64, last *BlockNode,
 			for _, x := range algIntervals {
 				awi.Add(float64(x))
 			}
-			algDiv = capP9Adjustment(awi.Value() / ttpb / float64(len(fork.
-				P9Algos)))
+			algDiv = capP9Adjustment(awi.Value() / ttpb / float64(len(b.
+				plan9().Algos)))
 		}
 	}
 	return
@@ -203,8 +199,8 @@ func (b *BlockChain) GetP9Since(lastNode *BlockNode, algoVer int32) (since,
 		last = ln
 	}
 	since = float64(lastNode.timestamp - last.timestamp)
-	ttpb = float64(fork.List[1].TargetTimePerBlock)
-	tspb := ttpb * float64(len(fork.List[1].Algos))
+	ttpb = float64(b.plan9().TargetTimePerBlock)
+	tspb := ttpb * float64(len(b.plan9().Algos))
 	// ratio of seconds since to target seconds per block times the
 	// all time divergence ensures the change scales with the divergence
 	// from the target, and favours algos that are later
@@ -215,17 +211,12 @@ func (b *BlockChain) GetP9Since(lastNode *BlockNode, algoVer int32) (since,
 
 func (b *BlockChain) IsP9HardFork(nH int32) bool {
 	// At activation difficulty resets
-	switch b.params.Net {
-	case wire.MainNet:
-		if fork.List[1].ActivationHeight == nH {
-			return true
-		}
-	case wire.TestNet3:
-		if fork.List[1].TestnetStart == nH {
-			return true
-		}
-	}
-	return false
+	return b.params.Forks.Activates(1, nH)
+}
+
+// plan9 returns the Plan 9 hard fork in the schedule of the chain
+func (b *BlockChain) plan9() *fork.HardForks {
+	return &b.params.Forks.Forks[1]
 }
 
 func capP9Adjustment(adjustment float64) float64 {
@@ -274,7 +274,7 @@ func calcMerkleRoot(txns []*wire.MsgTx) chainhash.Hash {
 
 // solveBlock attempts to find a nonce which makes the passed block header hash to a value less than the target difficulty.  When a successful solution is found true is returned and the nonce field of the passed header is updated with the solution.  False is returned if no solution exists.
 // NOTE: This function will never solve blocks with a nonce of 0.  This is done so the 'nextBlock' function can properly detect when a nonce was modified by a munge function.
-func solveBlock(header *wire.BlockHeader, height int32) bool {
+func solveBlock(header *wire.BlockHeader, forks *fork.Schedule, height int32) bool {
 	// sbResult is used by the solver goroutines to send results.
 	type sbResult struct {
 		found bool
@@ -292,7 +292,11 @@ func solveBlock(header *wire.BlockHeader, height int32) bool {
 				return
 			default:
 				hdr.Nonce = i
-				hash := hdr.BlockHashWithAlgos(height)
+				hash, err := hdr.BlockHashWithAlgos(forks, height)
+				if err != nil {
+					results <- sbResult{false, 0}
+					return
+				}
 				if blockchain.HashToBig(&hash).Cmp(
 					targetDifficulty) <= 0 {
 					results <- sbResult{true, i}
@@ -446,7 +450,7 @@ func (g *testGenerator) nextBlock(blockName string, spend *spendableOut, version
 		block.Header.MerkleRoot = calcMerkleRoot(block.Transactions)
 	}
 	// Only solve the block if the nonce wasn't manually changed by a munge function.
-	if block.Header.Nonce == curNonce && !solveBlock(&block.Header, nextHeight) {
+	if block.Header.Nonce == curNonce && !solveBlock(&block.Header, g.params.Forks, nextHeight) {
 		panic(fmt.Sprintf("Unable to solve block at height %d",
 			nextHeight))
 	}
@@ -757,7 +761,7 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	var testInstances []TestInstance
 	for i := uint16(0); i < coinbaseMaturity; i++ {
 		blockName := fmt.Sprintf("bm%d", i)
-		g.nextBlock(blockName, nil, fork.GetRandomVersion(int32(i)))
+		g.nextBlock(blockName, nil, g.params.Forks.RandomVersion(int32(i)))
 		g.saveTipCoinbaseOut()
 		testInstances = append(testInstances, acceptBlock(g.tipName,
 			g.tip, true, false))
@@ -1,9 +1,27 @@
 package hardfork
 
-import "github.com/p9c/pod/pkg/util"
+import (
+	"fmt"
 
-// Blacklist is a list of addresses that have been suspended
-var Blacklist = []util.Address{
-	// Cryptopia liquidation wallet
-	Addr("8JEEhaMxJf4dZh5rvVCVSA7JKeYBvy8fir", mn),
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// validateBlacklists checks that the blacklisted addresses of every hard fork
+// of a network decode for it, and are in the encoding addresses are looked up
+// by in the blacklists of the schedule
+func validateBlacklists(params *netparams.Params) (err error) {
+	for i := range params.Forks.Forks {
+		for _, addr := range params.Forks.Forks[i].Blacklist {
+			var a util.Address
+			if a, err = util.DecodeAddress(addr, params); Check(err) {
+				return
+			}
+			if a.EncodeAddress() != addr {
+				return fmt.Errorf("hard fork %d blacklist address '%s' is"+
+					" not encoded as '%s'", i, addr, a.EncodeAddress())
+			}
+		}
+	}
+	return
 }
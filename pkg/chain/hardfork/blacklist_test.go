@@ -0,0 +1,53 @@
+package hardfork
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+)
+
+// TestBlacklist ensures the blacklisted addresses are looked up in the hard
+// fork active at a height, that a changed schedule is looked up again once it
+// is validated without affecting the network it was copied from, and that
+// addresses which do not decode, or are not in their canonical encoding, are
+// refused.
+func TestBlacklist(t *testing.T) {
+	params, err := netparams.TestNet3Params.WithForkHeight(10)
+	if err != nil {
+		t.Fatalf("WithForkHeight: %v", err)
+	}
+	if err = Validate(params); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	want := params.Forks.Forks[1].Blacklist[0]
+	if params.Forks.Blacklisted(want, 9) {
+		t.Errorf("%s is blacklisted before the hard fork", want)
+	}
+	if !params.Forks.Blacklisted(want, 10) {
+		t.Errorf("%s is not blacklisted from the hard fork", want)
+	}
+	// a changed schedule is looked up again when it is validated
+	changed := netparams.TestNet3Params.Forks.Forks[1].Disbursement.Payees[0].
+		Address
+	params.Forks.Forks[1].Blacklist = []string{changed}
+	if err = Validate(params); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !params.Forks.Blacklisted(changed, 10) ||
+		params.Forks.Blacklisted(want, 10) {
+		t.Errorf("the changed blacklist is not used after validation")
+	}
+	// the network the schedule was copied from keeps its own blacklist
+	orig := netparams.TestNet3Params.Forks
+	height := orig.Forks[1].ActivationHeight
+	if !orig.Blacklisted(want, height) || orig.Blacklisted(changed, height) {
+		t.Errorf("the blacklist of the original network changed")
+	}
+	for _, addr := range []string{"invalid", strings.ToLower(want)} {
+		params.Forks.Forks[1].Blacklist = []string{addr}
+		if err = Validate(params); err == nil {
+			t.Errorf("validated a blacklist with the address %s", addr)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package hardfork
 
 import (
 	"encoding/hex"
+	"errors"
 
 	"github.com/p9c/pod/pkg/chain/config/netparams"
+	"github.com/p9c/pod/pkg/chain/forkhash"
 	"github.com/p9c/pod/pkg/util"
 )
 
@@ -13,86 +15,81 @@ type Payee struct {
 	Amount  util.Amount
 }
 
-var (
-	// The following prepares hard fork disbursement payout transactions
-	tn = &netparams.TestNet3Params
-	mn = &netparams.MainNetParams
-	// Payees are the list of payments to be made on the hard fork activation on
-	// mainnet
-	Payees = []Payee{
-		{Addr("ag7s5bmcA8XoP1CcS1QPjiD4C5hhMWATik", mn), Amt(4400)},
+// Disbursement is the hard fork disbursement of a network decoded from its
+// hard fork schedule, which is made by the coinbase of the activation block
+// in place of the block subsidy. It pays out to the payees and to a multisig
+// address of the core developers for marketing and ongoing development costs.
+type Disbursement struct {
+	Payees []Payee
+	// CorePubkeyBytes are the public keys of the multisig address, which
+	// needs CoreSigs of them to spend
+	CorePubkeyBytes [][]byte
+	CoreSigs        int
+	// CoreAmount is the amount paid into the dev pool
+	CoreAmount util.Amount
+}
+
+// GetDisbursement returns the disbursement of the block at a height on a
+// network, or nil if no hard fork with one activates there
+func GetDisbursement(params *netparams.Params, height int32) (
+	d *Disbursement, err error) {
+	fd := params.Forks.Disbursement(height)
+	if fd == nil {
+		return
 	}
-	// TestnetPayees are the list of payments to be made on the hard fork
-	// activation in the testnet
-	//
-	// these are made using the following seed for testnet
-	// f4d2c4c542bb52512ed9e6bbfa2d000e576a0c8b4ebd1acafd7efa37247366bc
-	TestnetPayees = []Payee{
-		{Addr("8K73LTaMHZmwwqe4vTHu7wm7QtwusvRCwC", tn), Amt(100)},
-		// {Addr("8JEEhaMxJf4dZh5rvVCVSA7JKeYBvy8fir", tn), Amt(15500)},
-		{Addr("8bec3m8qpMePrBPHDAyCrkSm7TanGX8yWW", tn), Amt(1223)},
-		{Addr("8MCLEWq8pjXikrpb9rF9M5DpnpaoWPUD2W", tn), Amt(4000)},
-		{Addr("8cYGvT7km339nVukTj3ztfyQDFEHFivBNk", tn), Amt(2440)},
-		{Addr("8YUAAfUeS2mqUnsfiwDwQcEbMfM3tazKr7", tn), Amt(100)},
-		{Addr("8MMam6gxH1ns5LqASfhkHfRV2vsQaoM9VC", tn), Amt(8800)},
-		{Addr("8JABYpdqqyRD5FbACtMJ3XF5HJ38jaytrk", tn), Amt(422)},
-		{Addr("8MUnJMYi5Fo7Bm5Pmpr7JjdL3ZDJ7wqmXJ", tn), Amt(5000)},
-		{Addr("8d2RLbCBE8CiF4DetVuRfFFLEJJaXYjhdH", tn), Amt(30000)},
+	d = &Disbursement{CoreSigs: fd.CoreSigs}
+	for i := range fd.Payees {
+		var p Payee
+		if p.Address, err = util.DecodeAddress(fd.Payees[i].Address,
+			params); Check(err) {
+			return nil, err
+		}
+		if p.Amount, err = util.NewAmount(fd.Payees[i].Amount); Check(err) {
+			return nil, err
+		}
+		d.Payees = append(d.Payees, p)
 	}
-	// CorePubkeyBytes is the address and public keys for the core dev
-	// disbursement
-	CorePubkeyBytes = [][]byte{
-		// nWo
-		Key("021a00c7e054279124e2d3eb8b64a58f1fda515464cd8df3c0823d2ff2931ebf37"),
-		// loki
-		Key("0387484f75bc5e45092b1334684def6b47f3dba1566b4b87f62d11c73d8f98db3e"),
-		// trax0r
-		Key("02daf0bda15f83899f4ebb62fd837c2dd2368ec8ed90ed0f050054d75d35935c99"),
+	for i := range fd.CorePubkeys {
+		var key []byte
+		if key, err = hex.DecodeString(fd.CorePubkeys[i]); Check(err) {
+			return nil, err
+		}
+		d.CorePubkeyBytes = append(d.CorePubkeyBytes, key)
 	}
-	// CoreAmount is the amount paid into the dev pool
-	CoreAmount = Amt(30000)
-	// TestnetCorePubkeyBytes are the addresses for the 3 of 4 multisig payment
-	// for dev costs
-	//
-	// these are made using the following seed for testnet
-	// f4d2c4c542bb52512ed9e6bbfa2d000e576a0c8b4ebd1acafd7efa37247366bc
-	TestnetCorePubkeyBytes = [][]byte{
-		// "8cL2fDzTSMu9Cd2rFi1dceWitQheAaCgTs",
-		Key("03f040c0cff7918415974f05154c8ffe126ad93db7216103fb6f4080dc3bcf4803"),
-		// "8YUDhyrcGrQk4PMpxnaNk1XyLRpQLa7N47",
-		Key("03f5a5ff1ce0564c7f4565a108220ebac9bd544b44e79ca5a2a805e585d8297cc6"),
-		// "8Rpf7CT4ikJQqXRpSp4EnAypKmidhHADN2",
-		Key("022976653e490cea689faafa899aa41b6295c32a5fb3e02d0fa201ac698e0c0c24"),
-		// "8Yw41PD1A3RviyjFQc38L9VufZasDU1pY8",
-		Key("029ed2885ea597fddea070a5c4c9f40900a514f67f9d5f662aa7b556e8bc5a26f8"),
-	}
-	// TestnetCoreAmount is the amount paid into the dev pool
-	TestnetCoreAmount = Amt(30000)
-)
-
-func Amt(f float64) (amt util.Amount) {
-	amt, err := util.NewAmount(f)
-	if err != nil {
-		Error(err)
-		panic(err)
+	if d.CoreAmount, err = util.NewAmount(fd.CoreAmount); Check(err) {
+		return nil, err
 	}
 	return
 }
 
-func Addr(addr string, defaultNet *netparams.Params) (out util.Address) {
-	out, err := util.DecodeAddress(addr, defaultNet)
-	if err != nil {
-		Error(err)
-		panic(err)
+// Total returns the sum of the payments of a disbursement
+func (d *Disbursement) Total() (total util.Amount) {
+	for i := range d.Payees {
+		total += d.Payees[i].Amount
 	}
-	return
+	return total + d.CoreAmount
 }
 
-func Key(key string) (out []byte) {
-	out, err := hex.DecodeString(key)
-	if err != nil {
-		Error(err)
-		panic(err)
+// Validate checks the hard fork schedule of a network, that the addresses in it
+// are valid and that its algorithms have registered hashes, and should be
+// called before the network is used
+func Validate(params *netparams.Params) (err error) {
+	if params.Forks == nil {
+		return errors.New("network " + params.Name +
+			" has no hard fork schedule")
+	}
+	if err = params.Forks.Validate(); Check(err) {
+		return
+	}
+	if err = forkhash.Validate(params.Forks); Check(err) {
+		return
+	}
+	for i := range params.Forks.Forks {
+		height := params.Forks.Forks[i].ActivationHeight
+		if _, err = GetDisbursement(params, height); Check(err) {
+			return
+		}
 	}
+	err = validateBlacklists(params)
 	return
 }
@@ -0,0 +1,399 @@
+package mining
+
+import (
+	"container/heap"
+	"errors"
+	"testing"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// testPool holds transactions spending from each other as NewBlockTemplate
+// tracks them, along with the outcome of the checks of their inputs.
+type testPool struct {
+	items     map[chainhash.Hash]*txPrioItem
+	dependers map[chainhash.Hash]map[chainhash.Hash]*txPrioItem
+	// sigOps holds the signature operation costs of the transactions, and
+	// invalid those failing the checks of their inputs and scripts.
+	sigOps  map[chainhash.Hash]int
+	invalid map[chainhash.Hash]struct{}
+	// next makes the outputs spent by transactions without parents distinct
+	next uint32
+}
+
+func newTestPool() *testPool {
+	return &testPool{
+		items:     make(map[chainhash.Hash]*txPrioItem),
+		dependers: make(map[chainhash.Hash]map[chainhash.Hash]*txPrioItem),
+		sigOps:    make(map[chainhash.Hash]int),
+		invalid:   make(map[chainhash.Hash]struct{}),
+	}
+}
+
+// add adds a transaction paying fee with padding bytes of signature script,
+// spending the next output of each of the parents, or an output outside of
+// the pool if it has none.
+func (p *testPool) add(fee int64, padding int,
+	parents ...*txPrioItem) *txPrioItem {
+	return p.addWitness(fee, padding, 0, parents...)
+}
+
+// addWitness adds a transaction like add, with witness bytes of witness data
+// on its first input.
+func (p *testPool) addWitness(fee int64, padding, witness int,
+	parents ...*txPrioItem) *txPrioItem {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	for _, parent := range parents {
+		index := uint32(len(p.dependers[*parent.tx.Hash()]))
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.tx.Hash(), index),
+			make([]byte, padding), nil))
+	}
+	if len(parents) == 0 {
+		p.next++
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{},
+			p.next), make([]byte, padding), nil))
+	}
+	if witness > 0 {
+		tx.TxIn[0].Witness = wire.TxWitness{make([]byte, witness)}
+	}
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+	item := &txPrioItem{tx: util.NewTx(tx), fee: fee, modifiedFee: fee,
+		index: -1}
+	item.size = (blockchain.GetTransactionWeight(item.tx) +
+		blockchain.WitnessScaleFactor - 1) / blockchain.WitnessScaleFactor
+	item.feePerKB = fee * 1000 / item.size
+	for _, parent := range parents {
+		hash := *parent.tx.Hash()
+		if item.dependsOn == nil {
+			item.dependsOn = make(map[chainhash.Hash]struct{})
+		}
+		item.dependsOn[hash] = struct{}{}
+		if p.dependers[hash] == nil {
+			p.dependers[hash] = make(map[chainhash.Hash]*txPrioItem)
+		}
+		p.dependers[hash][*item.tx.Hash()] = item
+	}
+	p.items[*item.tx.Hash()] = item
+	return item
+}
+
+// selector returns the selector NewBlockTemplate would choose transactions of
+// the pool with under the passed policy, starting from an empty block, with
+// segwit active and the signature operation costs and checks of the pool.
+func (p *testPool) selector(policy *Policy) *txSelector {
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{},
+		wire.MaxPrevOutIndex), []byte{txscript.OP_0, txscript.OP_0}, nil))
+	coinbase.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_TRUE}))
+	return &txSelector{
+		policy:        policy,
+		items:         p.items,
+		dependers:     p.dependers,
+		sortedByFee:   policy.BlockPrioritySize == 0,
+		segwitActive:  true,
+		witnessWeight: witnessCommitmentWeight(util.NewTx(coinbase)),
+		sigOpCost: func(tx *util.Tx) (int, error) {
+			return p.sigOps[*tx.Hash()], nil
+		},
+		checkTx: func(tx *util.Tx) error {
+			if _, ok := p.invalid[*tx.Hash()]; ok {
+				return errors.New("invalid transaction")
+			}
+			return nil
+		},
+	}
+}
+
+// byFee returns a policy choosing transactions by fee alone for a block of
+// up to the passed weight.
+func byFee(maxWeight uint32) *Policy {
+	return &Policy{BlockMaxWeight: maxWeight}
+}
+
+// checkSelection checks the transactions chosen from the pool are the wanted
+// ones in the wanted order.
+func checkSelection(t *testing.T, name string, got []*util.Tx,
+	want ...*txPrioItem) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s: got %d transactions, want %d", name, len(got),
+			len(want))
+		return
+	}
+	for i := range got {
+		if got[i] != want[i].tx {
+			t.Errorf("%s: got tx %v at %d, want %v", name, got[i].Hash(), i,
+				want[i].tx.Hash())
+		}
+	}
+}
+
+// TestChildPaysForParent ensures a transaction paying a high fee pulls a
+// parent paying a low fee into the block ahead of a transaction paying more
+// than the parent but less than the two of them together.
+func TestChildPaysForParent(t *testing.T) {
+	p := newTestPool()
+	parent := p.add(0, 0)
+	child := p.add(100000, 0, parent)
+	other := p.add(20000, 0)
+	setAncestorStats(p.items)
+	if child.ancestorFee != parent.fee+child.fee ||
+		child.ancestorSize != parent.size+child.size {
+		t.Errorf("got child package fee %d size %d, want %d %d",
+			child.ancestorFee, child.ancestorSize, parent.fee+child.fee,
+			parent.size+child.size)
+	}
+	if parent.ancestorFeePerKB() >= other.ancestorFeePerKB() ||
+		child.ancestorFeePerKB() <= other.ancestorFeePerKB() {
+		t.Fatalf("got package fee rates parent %d child %d other %d",
+			parent.ancestorFeePerKB(), child.ancestorFeePerKB(),
+			other.ancestorFeePerKB())
+	}
+	s := p.selector(byFee(blockchain.MaxBlockWeight))
+	s.selectTxns()
+	checkSelection(t, "child pays for parent", s.blockTxns, parent, child,
+		other)
+	if want := parent.fee + child.fee + other.fee; s.totalFees != want {
+		t.Errorf("got total fees %d, want %d", s.totalFees, want)
+	}
+}
+
+// TestSharedAncestor ensures an ancestor shared by the parents of a
+// transaction counts once in its package, and that adding it with another of
+// its descendants takes it out of the package.
+func TestSharedAncestor(t *testing.T) {
+	p := newTestPool()
+	root := p.add(1000, 0)
+	left := p.add(2000, 0, root)
+	right := p.add(3000, 0, root)
+	joined := p.add(4000, 0, left, right)
+	setAncestorStats(p.items)
+	tests := []struct {
+		name string
+		item *txPrioItem
+		pkg  []*txPrioItem
+	}{
+		{"root", root, []*txPrioItem{root}},
+		{"left", left, []*txPrioItem{root, left}},
+		{"right", right, []*txPrioItem{root, right}},
+		{"joined", joined, []*txPrioItem{root, left, right, joined}},
+	}
+	for _, test := range tests {
+		var fee, size int64
+		for _, item := range test.pkg {
+			fee += item.modifiedFee
+			size += item.size
+		}
+		if test.item.ancestorFee != fee || test.item.ancestorSize != size {
+			t.Errorf("%s: got package fee %d size %d, want %d %d",
+				test.name, test.item.ancestorFee, test.item.ancestorSize, fee,
+				size)
+		}
+		if got := ancestorPackage(test.item, p.items, nil); len(got) !=
+			len(test.pkg) {
+			t.Errorf("%s: got a package of %d transactions, want %d",
+				test.name, len(got), len(test.pkg))
+		}
+	}
+	// Adding the left branch leaves only the right one to be paid for.
+	pq := newTxPriorityQueue(len(p.items), true)
+	for _, item := range p.items {
+		heap.Push(pq, item)
+	}
+	for _, item := range ancestorPackage(left, p.items, nil) {
+		removeAdded(pq, item, p.items, p.dependers)
+	}
+	if fee, size := right.modifiedFee+joined.modifiedFee,
+		right.size+joined.size; joined.ancestorFee != fee ||
+		joined.ancestorSize != size {
+		t.Errorf("after adding left: got package fee %d size %d, want %d %d",
+			joined.ancestorFee, joined.ancestorSize, fee, size)
+	}
+	if pkg := ancestorPackage(joined, p.items, nil); len(pkg) != 2 ||
+		pkg[0] != right || pkg[1] != joined {
+		t.Errorf("after adding left: got a package of %d transactions, "+
+			"want right and joined", len(pkg))
+	}
+}
+
+// TestPackageOverWeight ensures a package that doesn't fit into the block is
+// skipped as a whole instead of its ancestors being added for the fee of the
+// transaction, so that a smaller transaction paying less still gets in, and
+// the ancestors are left to be chosen for their own fees.
+func TestPackageOverWeight(t *testing.T) {
+	p := newTestPool()
+	parent := p.add(0, 2000)
+	child := p.add(1000000, 2000, parent)
+	other := p.add(1000, 0)
+	parentWeight := uint32(blockchain.GetTransactionWeight(parent.tx))
+	childWeight := uint32(blockchain.GetTransactionWeight(child.tx))
+	otherWeight := uint32(blockchain.GetTransactionWeight(other.tx))
+	// The block has room for the parent and the other transaction, but not
+	// for the child as well.
+	maxWeight := parentWeight + otherWeight + childWeight/2
+	pkg := []*txPrioItem{parent, child}
+	tests := []struct {
+		name        string
+		blockWeight uint32
+		maxWeight   uint32
+		want        bool
+	}{
+		{"room to spare", 0, parentWeight + childWeight + 1, true},
+		{"exactly the max weight", 0, parentWeight + childWeight, false},
+		{"child over", 0, maxWeight, false},
+		{"block weight over", otherWeight, parentWeight + childWeight + 1,
+			false},
+		{"overflow", ^uint32(0) - parentWeight, ^uint32(0), false},
+	}
+	for _, test := range tests {
+		got := packageFits(pkg, test.blockWeight, test.maxWeight)
+		if got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+	s := p.selector(byFee(maxWeight))
+	s.selectTxns()
+	checkSelection(t, "package over weight", s.blockTxns, other, parent)
+}
+
+// TestPackageWitnessCommitment ensures the weight of the witness commitment
+// is counted before a package bringing the first witness data into the block
+// is found to fit, so that its ancestors are not added without it.
+func TestPackageWitnessCommitment(t *testing.T) {
+	tests := []struct {
+		name   string
+		segwit bool
+		// maxWeight returns the max block weight from the weights of the
+		// package, the witness commitment and the other transaction.
+		maxWeight  func(pkg, commitment, other uint32) uint32
+		want       []int
+		commitment bool
+	}{
+		{"room for the commitment", true,
+			func(pkg, commitment, other uint32) uint32 {
+				return pkg + commitment + other + 1
+			}, []int{0, 1, 2}, true},
+		{"no room for the commitment", true,
+			func(pkg, commitment, other uint32) uint32 {
+				return pkg + commitment
+			}, []int{2, 0}, false},
+		{"segwit not active", false,
+			func(pkg, commitment, other uint32) uint32 {
+				return pkg + other + 1
+			}, []int{0, 2}, false},
+	}
+	for _, test := range tests {
+		p := newTestPool()
+		parent := p.add(0, 0)
+		child := p.addWitness(1000000, 0, 100, parent)
+		other := p.add(1000, 0)
+		items := []*txPrioItem{parent, child, other}
+		s := p.selector(byFee(0))
+		s.segwitActive = test.segwit
+		s.policy.BlockMaxWeight = test.maxWeight(
+			uint32(blockchain.GetTransactionWeight(parent.tx)+
+				blockchain.GetTransactionWeight(child.tx)), s.witnessWeight,
+			uint32(blockchain.GetTransactionWeight(other.tx)))
+		s.selectTxns()
+		want := make([]*txPrioItem, len(test.want))
+		for i, index := range test.want {
+			want[i] = items[index]
+		}
+		checkSelection(t, test.name, s.blockTxns, want...)
+		if s.witnessIncluded != test.commitment {
+			t.Errorf("%s: got witness commitment %v, want %v", test.name,
+				s.witnessIncluded, test.commitment)
+		}
+	}
+}
+
+// TestPackageMemberFails ensures that when a transaction of a package fails
+// the signature operation or script checks, the ancestors added ahead of it
+// stay in the block, while it and its descendants are skipped.
+func TestPackageMemberFails(t *testing.T) {
+	tests := []struct {
+		name string
+		fail func(p *testPool, s *txSelector, child *txPrioItem)
+	}{
+		{"sigops", func(p *testPool, s *txSelector, child *txPrioItem) {
+			p.sigOps[*child.tx.Hash()] = 10
+			s.blockSigOpCost = blockchain.MaxBlockSigOpsCost - 5
+		}},
+		{"scripts", func(p *testPool, s *txSelector, child *txPrioItem) {
+			p.invalid[*child.tx.Hash()] = struct{}{}
+		}},
+	}
+	for _, test := range tests {
+		p := newTestPool()
+		parent := p.add(1000, 0)
+		child := p.add(100000, 0, parent)
+		grandchild := p.add(200000, 0, child)
+		other := p.add(1000, 0)
+		s := p.selector(byFee(blockchain.MaxBlockWeight))
+		test.fail(p, s, child)
+		s.selectTxns()
+		// The grandchild pulls in the package, which stops at the child,
+		// leaving the parent to be paid for by its own fee.
+		checkSelection(t, test.name, s.blockTxns, parent, other)
+		if want := parent.fee + other.fee; s.totalFees != want {
+			t.Errorf("%s: got total fees %d, want %d", test.name,
+				s.totalFees, want)
+		}
+		if len(s.txFees) != 2 || s.txFees[0] != parent.fee {
+			t.Errorf("%s: got fees %v, want the parent's first", test.name,
+				s.txFees)
+		}
+		if _, ok := s.items[*grandchild.tx.Hash()]; !ok {
+			t.Errorf("%s: the grandchild was taken out of the items",
+				test.name)
+		}
+	}
+}
+
+// TestPrioritySwitch ensures transactions are chosen by priority until the
+// block reaches the priority size or the priority drops to the minimum high
+// priority, and by fee along with their ancestors after that, skipping free
+// transactions.
+func TestPrioritySwitch(t *testing.T) {
+	tests := []struct {
+		name         string
+		prioritySize uint32
+		// want holds the indexes of the wanted transactions out of the high
+		// priority, parent, child, rich and free ones.
+		want []int
+	}{
+		{"priority drops", blockchain.MaxBlockWeight, []int{0, 1, 2, 3}},
+		{"priority size reached", 1, []int{1, 2, 3}},
+	}
+	for _, test := range tests {
+		p := newTestPool()
+		high := p.add(0, 0)
+		high.priority = MinHighPriority.ToDUO() * 10
+		parent := p.add(0, 0)
+		child := p.add(100000, 0, parent)
+		rich := p.add(20000, 0)
+		free := p.add(0, 0)
+		items := []*txPrioItem{high, parent, child, rich, free}
+		s := p.selector(&Policy{
+			BlockMaxWeight:    blockchain.MaxBlockWeight,
+			BlockPrioritySize: test.prioritySize,
+			TxMinFreeFee:      1000,
+		})
+		if s.sortedByFee {
+			t.Fatalf("%s: sorted by fee from the start", test.name)
+		}
+		s.selectTxns()
+		if !s.sortedByFee {
+			t.Errorf("%s: not sorted by fee at the end", test.name)
+		}
+		want := make([]*txPrioItem, len(test.want))
+		for i, index := range test.want {
+			want[i] = items[index]
+		}
+		checkSelection(t, test.name, s.blockTxns, want...)
+	}
+}
@@ -10,14 +10,11 @@ import (
 	"github.com/p9c/pod/pkg/util"
 )
 
-// createHardForkSubsidyTx creates the transaction that must be on the hard fork activation block in place of a standard coinbase transaction. The main difference is the value set on this coinbase and that it pays out to multiple addresses, several being to the developers and to a 3 of 4 multisig to the development team for marketing and ongoing development costs
+// createHardForkSubsidyTx creates the transaction that must be on the hard fork activation block in place of a standard coinbase transaction. The main difference is the value set on this coinbase and that it pays out to multiple addresses, several being to the developers and to a multisig to the development team for marketing and ongoing development costs
 // multisig tx: NUM_SIGS PUBKEY PUBKEY PUBKEY... NUM_PUBKEYS OP_CHECKMULTISIG
 // nolint
-func createHardForkSubsidyTx(params *netparams.Params, coinbaseScript []byte, nextBlockHeight int32, addr util.Address, version int32) (*util.Tx, error) {
-	payees := hardfork.Payees
-	if params.Net == wire.TestNet3 {
-		payees = hardfork.TestnetPayees
-	}
+func createHardForkSubsidyTx(params *netparams.Params, d *hardfork.Disbursement, coinbaseScript []byte, nextBlockHeight int32, addr util.Address, version int32) (*util.Tx, error) {
+	payees := d.Payees
 	tx := wire.NewMsgTx(wire.TxVersion)
 	tx.AddTxIn(&wire.TxIn{
 		// Coinbase transactions have no inputs, so previous outpoint is zero hash and max index.
@@ -35,24 +32,15 @@ func createHardForkSubsidyTx(params *netparams.Params, coinbaseScript []byte, ne
 	}
 	// Add Core multisig payment
 	builder := txscript.NewScriptBuilder()
-	keylist := hardfork.CorePubkeyBytes
-	if params.Net == wire.TestNet3 {
-		keylist = hardfork.TestnetCorePubkeyBytes
+	builder.AddOp(byte(txscript.OP_1 - 1 + d.CoreSigs))
+	for _, key := range d.CorePubkeyBytes {
+		builder.AddData(key)
 	}
-	builder.AddOp(txscript.OP_3).
-		AddData(keylist[0]).
-		AddData(keylist[1]).
-		AddData(keylist[2]).
-		AddData(keylist[3]).
-		AddOp(txscript.OP_4).
+	builder.AddOp(byte(txscript.OP_1 - 1 + len(d.CorePubkeyBytes))).
 		AddOp(txscript.OP_CHECKMULTISIG)
 	script, _ := builder.Script()
-	amt := hardfork.CoreAmount
-	if params.Net == wire.TestNet3 {
-		amt = hardfork.TestnetCoreAmount
-	}
 	tx.AddTxOut(&wire.TxOut{
-		Value:    int64(amt),
+		Value:    int64(d.CoreAmount),
 		PkScript: script,
 	})
 	// add miner's reward based on last non-hf reward
@@ -0,0 +1,85 @@
+package mining
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// TestHardForkSubsidyTx pins the dev fund multisig script and the amounts of
+// the disbursement coinbases of mainnet and testnet.
+//
+// The testnet script is the 3 of 4 multisig the disbursement was always made
+// with. Mainnet only ever had the 3 keys below, and the 3 of 4 script could
+// not be made from them, so its disbursement is a 3 of 3 multisig. The check
+// of the disbursement coinbase compares the keys and not the signature counts,
+// so it accepts both.
+func TestHardForkSubsidyTx(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   *netparams.Params
+		height   int32
+		outputs  int
+		core     int64
+		multisig string
+	}{
+		{
+			name:    "mainnet",
+			params:  &netparams.MainNetParams,
+			height:  250000,
+			outputs: 3,
+			core:    30000 * 1e8,
+			multisig: "53" +
+				"21021a00c7e054279124e2d3eb8b64a58f1fda515464cd8df3c0823d2ff2931ebf37" +
+				"210387484f75bc5e45092b1334684def6b47f3dba1566b4b87f62d11c73d8f98db3e" +
+				"2102daf0bda15f83899f4ebb62fd837c2dd2368ec8ed90ed0f050054d75d35935c99" +
+				"53ae",
+		},
+		{
+			name:    "testnet",
+			params:  &netparams.TestNet3Params,
+			height:  1,
+			outputs: 11,
+			core:    30000 * 1e8,
+			multisig: "53" +
+				"2103f040c0cff7918415974f05154c8ffe126ad93db7216103fb6f4080dc3bcf4803" +
+				"2103f5a5ff1ce0564c7f4565a108220ebac9bd544b44e79ca5a2a805e585d8297cc6" +
+				"21022976653e490cea689faafa899aa41b6295c32a5fb3e02d0fa201ac698e0c0c24" +
+				"21029ed2885ea597fddea070a5c4c9f40900a514f67f9d5f662aa7b556e8bc5a26f8" +
+				"54ae",
+		},
+	}
+	for _, test := range tests {
+		addr, err := util.NewAddressPubKeyHash(make([]byte, 20), test.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		version := test.params.Forks.AlgoVer("", test.height)
+		tx, err := createCoinbaseTx(test.params, []byte{0x51, 0x51},
+			test.height, addr, version)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		txOut := tx.MsgTx().TxOut
+		if len(txOut) != test.outputs {
+			t.Fatalf("%s: disbursement has %d outputs, want %d", test.name,
+				len(txOut), test.outputs)
+		}
+		core := txOut[len(txOut)-2]
+		want, err := hex.DecodeString(test.multisig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(core.PkScript, want) {
+			t.Errorf("%s: dev fund script %x, want %x", test.name,
+				core.PkScript, want)
+		}
+		if core.Value != test.core {
+			t.Errorf("%s: dev fund amount %d, want %d", test.name,
+				core.Value, test.core)
+		}
+	}
+}
@@ -10,9 +10,11 @@ import (
 	chaincfg "github.com/p9c/pod/pkg/chain/config"
 	"github.com/p9c/pod/pkg/chain/config/netparams"
 	"github.com/p9c/pod/pkg/chain/fork"
+	"github.com/p9c/pod/pkg/chain/hardfork"
 	chainhash "github.com/p9c/pod/pkg/chain/hash"
 	txscript "github.com/p9c/pod/pkg/chain/tx/script"
 	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/metrics"
 	"github.com/p9c/pod/pkg/util"
 )
 
@@ -29,6 +31,14 @@ const (
 	CoinbaseFlags = "/P2SH/pod/"
 )
 
+// templateBuildSeconds is the time taken to build block templates by
+// algorithm.
+var templateBuildSeconds = metrics.NewHistogram(
+	"pod_block_template_build_seconds",
+	"Time taken to build new block templates, by mining algorithm.",
+	metrics.LatencyBuckets, "algo",
+)
+
 type (
 	// TxDesc is a descriptor about a transaction in a transaction source along
 	// with additional metadata.
@@ -44,6 +54,9 @@ type (
 		Fee int64
 		// FeePerKB is the fee the transaction pays in Satoshi per 1000 bytes.
 		FeePerKB int64
+		// FeeDelta is added to the fee the transaction pays when choosing
+		// the transactions of a block, without changing the fee it pays.
+		FeeDelta int64
 	}
 	// TxSource represents a source of transactions to consider for inclusion in
 	// new blocks. The interface contract requires that all of these methods are
@@ -67,6 +80,18 @@ type (
 		fee      int64
 		priority float64
 		feePerKB int64
+		// size is the virtual size of the transaction, and modifiedFee the
+		// fee it is treated as paying, including its fee delta.
+		size        int64
+		modifiedFee int64
+		// ancestorFee and ancestorSize are the modified fee and size of the
+		// transaction together with its ancestors in the source pool that
+		// are not in the block yet, which have to be included along with it.
+		ancestorFee  int64
+		ancestorSize int64
+		// index is the position of the item in the priority queue, or -1
+		// when it is not in the queue.
+		index int
 		// dependsOn holds a map of transaction hashes which this one depends
 		// on.
 		// It will only be set when the transaction references other
@@ -149,12 +174,16 @@ func // Swap swaps the items at the passed indices in the priority queue.
 // It is part of the heap.Interface implementation.
 (pq *txPriorityQueue) Swap(i, j int) {
 	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
+	pq.items[i].index = i
+	pq.items[j].index = j
 }
 
 func // Push pushes the passed item onto the priority queue.
 // It is part of the heap.Interface implementation.
 (pq *txPriorityQueue) Push(x interface{}) {
-	pq.items = append(pq.items, x.(*txPrioItem))
+	item := x.(*txPrioItem)
+	item.index = len(pq.items)
+	pq.items = append(pq.items, item)
 }
 
 func // Pop removes the highest priority item (
@@ -163,6 +192,7 @@ func // Pop removes the highest priority item (
 (pq *txPriorityQueue) Pop() interface{} {
 	n := len(pq.items)
 	item := pq.items[n-1]
+	item.index = -1
 	pq.items[n-1] = nil
 	pq.items = pq.items[0 : n-1]
 	return item
@@ -188,15 +218,24 @@ txPQByPriority(pq *txPriorityQueue, i, j int) bool {
 	return pq.items[i].priority > pq.items[j].priority
 }
 
-func // txPQByFee sorts a txPriorityQueue by fees per kilobyte and then
-// transaction priority.
+func // txPQByFee sorts a txPriorityQueue by the fees per kilobyte of the
+// transactions together with their ancestors that are not in the block yet,
+// and then transaction priority.
 txPQByFee(pq *txPriorityQueue, i, j int) bool {
 	// Using > here so that pop gives the highest fee item as opposed to the
 	// lowest.  Sort by fee first, then priority.
-	if pq.items[i].feePerKB == pq.items[j].feePerKB {
+	feeI := pq.items[i].ancestorFeePerKB()
+	feeJ := pq.items[j].ancestorFeePerKB()
+	if feeI == feeJ {
 		return pq.items[i].priority > pq.items[j].priority
 	}
-	return pq.items[i].feePerKB > pq.items[j].feePerKB
+	return feeI > feeJ
+}
+
+func // ancestorFeePerKB returns the fee per kilobyte of the transaction
+// together with its ancestors that are not in the block yet.
+(item *txPrioItem) ancestorFeePerKB() int64 {
+	return item.ancestorFee * 1000 / item.ancestorSize
 }
 
 // newTxPriorityQueue returns a new transaction priority queue that reserves
@@ -250,11 +289,13 @@ func createCoinbaseTx(params *netparams.Params, coinbaseScript []byte, nextBlock
 	addr util.Address, version int32) (*util.Tx, error) {
 	// if this is the hard fork activation height coming up, we create the
 	// special disbursement coinbase
-	if nextBlockHeight == fork.List[1].ActivationHeight &&
-		params.Net == wire.MainNet ||
-		nextBlockHeight == fork.List[1].TestnetStart &&
-			params.Net == wire.TestNet3 {
-		return createHardForkSubsidyTx(params, coinbaseScript, nextBlockHeight, addr, version)
+	d, err := hardfork.GetDisbursement(params, nextBlockHeight)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	if d != nil {
+		return createHardForkSubsidyTx(params, d, coinbaseScript, nextBlockHeight, addr, version)
 	}
 
 	// Create the script to pay to the provided payment address if one was
@@ -319,6 +360,374 @@ logSkippedDeps(tx *util.Tx, deps map[chainhash.Hash]*txPrioItem) {
 	}
 }
 
+func // ancestorPackage returns the item followed by its ancestors in the
+// source pool that are not in the block yet, in an order they can be added to
+// the block in, or nil if any of them can't be added because it is not in the
+// passed items or has been skipped.
+ancestorPackage(item *txPrioItem, items map[chainhash.Hash]*txPrioItem,
+	skipped map[chainhash.Hash]struct{}) []*txPrioItem {
+	var pkg []*txPrioItem
+	added := make(map[chainhash.Hash]struct{})
+	var add func(item *txPrioItem) bool
+	add = func(item *txPrioItem) bool {
+		for hash := range item.dependsOn {
+			if _, ok := added[hash]; ok {
+				continue
+			}
+			parent, ok := items[hash]
+			if !ok {
+				return false
+			}
+			if _, ok := skipped[hash]; ok {
+				return false
+			}
+			if !add(parent) {
+				return false
+			}
+		}
+		added[*item.tx.Hash()] = struct{}{}
+		pkg = append(pkg, item)
+		return true
+	}
+	if !add(item) {
+		return nil
+	}
+	return pkg
+}
+
+func // setAncestorStats sets the fee and size of each item together with its
+// ancestors among the items.
+setAncestorStats(items map[chainhash.Hash]*txPrioItem) {
+	for _, item := range items {
+		item.ancestorFee, item.ancestorSize = item.modifiedFee, item.size
+		seen := make(map[chainhash.Hash]struct{})
+		queue := []*txPrioItem{item}
+		for len(queue) > 0 {
+			next := queue[0]
+			queue = queue[1:]
+			for hash := range next.dependsOn {
+				parent, ok := items[hash]
+				if _, dup := seen[hash]; dup || !ok {
+					continue
+				}
+				seen[hash] = struct{}{}
+				item.ancestorFee += parent.modifiedFee
+				item.ancestorSize += parent.size
+				queue = append(queue, parent)
+			}
+		}
+	}
+}
+
+func // updateDescendants takes the fee and size of a transaction that was
+// added to the block out of those of its descendants among the items, and
+// restores the order of the priority queue for them.
+updateDescendants(pq *txPriorityQueue, added *txPrioItem,
+	items map[chainhash.Hash]*txPrioItem,
+	dependers map[chainhash.Hash]map[chainhash.Hash]*txPrioItem) {
+	seen := make(map[chainhash.Hash]struct{})
+	queue := []*txPrioItem{added}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for hash := range dependers[*next.tx.Hash()] {
+			item, ok := items[hash]
+			if _, dup := seen[hash]; dup || !ok {
+				continue
+			}
+			seen[hash] = struct{}{}
+			item.ancestorFee -= added.modifiedFee
+			item.ancestorSize -= added.size
+			if item.index >= 0 {
+				heap.Fix(pq, item.index)
+			}
+			queue = append(queue, item)
+		}
+	}
+}
+
+func // removeAdded takes a transaction that was added to the block out of the
+// priority queue and the items, out of the dependencies of the transactions
+// spending from it, and out of the fees and sizes of its descendants among the
+// items.
+removeAdded(pq *txPriorityQueue, added *txPrioItem,
+	items map[chainhash.Hash]*txPrioItem,
+	dependers map[chainhash.Hash]map[chainhash.Hash]*txPrioItem) {
+	// An ancestor added ahead of the transaction it was chosen for is no
+	// longer waiting in the priority queue.
+	if added.index >= 0 {
+		heap.Remove(pq, added.index)
+	}
+	delete(items, *added.tx.Hash())
+	for _, item := range dependers[*added.tx.Hash()] {
+		delete(item.dependsOn, *added.tx.Hash())
+	}
+	// The transactions spending from this one no longer have to pay for it.
+	updateDescendants(pq, added, items, dependers)
+}
+
+func // packageFits returns whether the transactions of a package fit into a
+// block of the passed weight without it reaching the max block weight.
+packageFits(pkg []*txPrioItem, blockWeight, maxWeight uint32) bool {
+	weight := blockWeight
+	for _, item := range pkg {
+		next := weight + uint32(blockchain.GetTransactionWeight(item.tx))
+		if next < weight {
+			return false
+		}
+		weight = next
+	}
+	return weight < maxWeight
+}
+
+// txSelector chooses the transactions of a block template out of the items of
+// the source pool, adding them in order of priority and then of fees per
+// kilobyte, each along with its ancestors that are not in the block yet.
+// A package is skipped as a whole if it won't fit into the block, but once
+// its transactions are being added, ancestors added before one of them fails
+// its checks stay in the block, as they are valid on their own.
+type txSelector struct {
+	policy       *Policy
+	items        map[chainhash.Hash]*txPrioItem
+	dependers    map[chainhash.Hash]map[chainhash.Hash]*txPrioItem
+	sortedByFee  bool
+	segwitActive bool
+	// witnessWeight is the weight the witness commitment adds to the
+	// coinbase, which is added to the block with the first transaction
+	// bearing witness data.
+	witnessWeight uint32
+	// sigOpCost returns the signature operation cost of a transaction
+	// spending the outputs available to the block, and checkTx checks its
+	// inputs and scripts against them, making its own outputs available when
+	// they pass.
+	sigOpCost func(tx *util.Tx) (int, error)
+	checkTx   func(tx *util.Tx) error
+	// The transactions added to the block, starting with the coinbase, and
+	// their fees and signature operation costs along with the totals.
+	blockTxns       []*util.Tx
+	txFees          []int64
+	txSigOpCosts    []int64
+	blockWeight     uint32
+	blockSigOpCost  int64
+	totalFees       int64
+	witnessIncluded bool
+}
+
+func // selectTxns adds the transactions chosen out of the items to the block.
+(s *txSelector) selectTxns() {
+	// Add the transactions to the priority queue to mark them ready for
+	// inclusion in the block unless they have dependencies. Once sorted by fee
+	// the transactions with dependencies are ready too, as they are added
+	// along with the transactions they depend on.
+	pq := newTxPriorityQueue(len(s.items), s.sortedByFee)
+	skipped := make(map[chainhash.Hash]struct{})
+	setAncestorStats(s.items)
+	for _, item := range s.items {
+		if item.dependsOn == nil || s.sortedByFee {
+			heap.Push(pq, item)
+		}
+	}
+queueLoop:
+	for pq.Len() > 0 {
+		// Grab the highest priority (or highest fee per kilobyte depending on
+		// the sort order) transaction.
+		pkgItem := heap.Pop(pq).(*txPrioItem)
+		// Once sorted by fee, the transaction is added along with its
+		// ancestors that are not in the block yet, as their fees together
+		// decide whether they are worth including, so that a child can pay
+		// for its parents.
+		pkg := []*txPrioItem{pkgItem}
+		pkgFeePerKB := pkgItem.feePerKB
+		if s.sortedByFee {
+			if pkg = ancestorPackage(pkgItem, s.items, skipped); pkg == nil {
+				Tracef("skipping tx %s because a transaction it depends on"+
+					" was skipped", pkgItem.tx.Hash())
+				skipped[*pkgItem.tx.Hash()] = struct{}{}
+				continue
+			}
+			pkgFeePerKB = pkgItem.ancestorFeePerKB()
+			// The ancestors are only worth adding along with the
+			// transaction, so the package is skipped as a whole if it won't
+			// fit, counting the witness commitment if the package brings the
+			// first witness data into the block.
+			weight := s.blockWeight
+			if s.segwitActive && !s.witnessIncluded && packageHasWitness(pkg) {
+				weight += s.witnessWeight
+			}
+			if !packageFits(pkg, weight, s.policy.BlockMaxWeight) {
+				Tracef("skipping tx %s because its package would exceed the"+
+					" max block weight", pkgItem.tx.Hash())
+				skipped[*pkgItem.tx.Hash()] = struct{}{}
+				continue
+			}
+		}
+		for _, prioItem := range pkg {
+			tx := prioItem.tx
+			// The transaction counts as skipped, along with the transactions
+			// depending on it, unless it is added to the block below.
+			skipped[*tx.Hash()] = struct{}{}
+			switch {
+			// If segregated witness has not been activated yet, then we
+			// shouldn't include any witness transactions in the block.
+			case !s.segwitActive && tx.HasWitness():
+				continue queueLoop
+				// Otherwise, Keep track of if we've included a transaction with
+				// witness data or not. If so, then we'll need to include the
+				// witness commitment as the last output in the coinbase
+				// transaction.
+			case s.segwitActive && !s.witnessIncluded && tx.HasWitness():
+				// If we're about to include a transaction bearing witness
+				// data, then we'll also need to include a witness commitment
+				// in the coinbase transaction, so we account for its weight.
+				s.blockWeight += s.witnessWeight
+				s.witnessIncluded = true
+			}
+			// Grab any transactions which depend on this one.
+			deps := s.dependers[*tx.Hash()]
+			// Enforce maximum block size.  Also check for overflow.
+			txWeight := uint32(blockchain.GetTransactionWeight(tx))
+			blockPlusTxWeight := s.blockWeight + txWeight
+			if blockPlusTxWeight < s.blockWeight ||
+				blockPlusTxWeight >= s.policy.BlockMaxWeight {
+				Tracef("skipping tx %s because it would exceed the max block"+
+					" weight", tx.Hash())
+				logSkippedDeps(tx, deps)
+				continue queueLoop
+			}
+			// Enforce maximum signature operation cost per block.  Also check
+			// for overflow.
+			sigOpCost, err := s.sigOpCost(tx)
+			if err != nil {
+				Tracec(func() string {
+					return "skipping tx " + tx.Hash().String() +
+						"due to error in GetSigOpCost: " + err.Error()
+				})
+				logSkippedDeps(tx, deps)
+				continue queueLoop
+			}
+			if s.blockSigOpCost+int64(sigOpCost) < s.blockSigOpCost ||
+				s.blockSigOpCost+int64(sigOpCost) > blockchain.MaxBlockSigOpsCost {
+				Tracec(func() string {
+					return "skipping tx " + tx.Hash().String() +
+						" because it would exceed the maximum sigops per block"
+				})
+				logSkippedDeps(tx, deps)
+				continue queueLoop
+			}
+			// Skip free transactions once the block is larger than the
+			// minimum block size.
+			if s.sortedByFee &&
+				pkgFeePerKB < int64(s.policy.TxMinFreeFee) &&
+				blockPlusTxWeight >= s.policy.BlockMinWeight {
+				Tracec(func() string {
+					return fmt.Sprint(
+						"skipping tx ", tx.Hash(),
+						" with feePerKB ", pkgFeePerKB,
+						" < TxMinFreeFee ", s.policy.TxMinFreeFee,
+						" and block weight ", blockPlusTxWeight,
+						" >= minBlockWeight ", s.policy.BlockMinWeight,
+					)
+				})
+				logSkippedDeps(tx, deps)
+				continue queueLoop
+			}
+			// Prioritize by fee per kilobyte once the block is larger than
+			// the priority size or there are no more high-priority
+			// transactions.
+			if !s.sortedByFee && (blockPlusTxWeight >= s.policy.BlockPrioritySize ||
+				prioItem.priority <= MinHighPriority.ToDUO()) {
+				Tracef("switching to sort by fees per kilobyte blockSize %d"+
+					" >= BlockPrioritySize %d || priority %.2f <= minHighPriority %.2f",
+					blockPlusTxWeight,
+					s.policy.BlockPrioritySize,
+					prioItem.priority,
+					MinHighPriority)
+				s.sortedByFee = true
+				pq.SetLessFunc(txPQByFee)
+				// The transactions with dependencies are ready to be added
+				// along with the transactions they depend on from now on.
+				for _, item := range s.items {
+					if len(item.dependsOn) > 0 && item.index < 0 {
+						heap.Push(pq, item)
+					}
+				}
+				// Put the transaction back into the priority queue and skip
+				// it so it is re-prioritized by fees if it won't fit into the
+				// high-priority section or the priority is too low.
+				// Otherwise this transaction will be the final one in the
+				// high-priority section, so just fall though to the code
+				// below so it is added now.
+				if blockPlusTxWeight > s.policy.BlockPrioritySize ||
+					prioItem.priority < MinHighPriority.ToDUO() {
+					delete(skipped, *tx.Hash())
+					heap.Push(pq, prioItem)
+					continue queueLoop
+				}
+			}
+			if err := s.checkTx(tx); err != nil {
+				logSkippedDeps(tx, deps)
+				continue queueLoop
+			}
+			// Add the transaction to the block, increment counters, and save
+			// the fees and signature operation counts to the block template.
+			// The fees are the ones the transaction pays, whatever it was
+			// chosen by.
+			delete(skipped, *tx.Hash())
+			s.blockTxns = append(s.blockTxns, tx)
+			s.blockWeight += txWeight
+			s.blockSigOpCost += int64(sigOpCost)
+			s.totalFees += prioItem.fee
+			s.txFees = append(s.txFees, prioItem.fee)
+			s.txSigOpCosts = append(s.txSigOpCosts, int64(sigOpCost))
+			Tracef("adding tx %s (priority %.2f, feePerKB %d, package "+
+				"feePerKB %d)",
+				prioItem.tx.Hash(),
+				prioItem.priority,
+				prioItem.feePerKB,
+				pkgFeePerKB)
+			removeAdded(pq, prioItem, s.items, s.dependers)
+			// Add transactions which depend on this one (and also do not have
+			// any other unsatisified dependencies) to the priority queue.
+			for _, item := range deps {
+				// Add the transaction to the priority queue if there are no
+				// more dependencies after this one.
+				if len(item.dependsOn) == 0 && item.index < 0 && !s.sortedByFee {
+					heap.Push(pq, item)
+				}
+			}
+		}
+	}
+}
+
+func // witnessCommitmentWeight returns the weight the witness commitment adds to
+// the coinbase transaction.
+witnessCommitmentWeight(coinbaseTx *util.Tx) uint32 {
+	// Model the coinbase transaction with a witness commitment and take the
+	// difference of its weight before and after the addition.
+	coinbaseCopy := util.NewTx(coinbaseTx.MsgTx().Copy())
+	coinbaseCopy.MsgTx().TxIn[0].Witness = [][]byte{
+		bytes.Repeat([]byte("a"),
+			blockchain.CoinbaseWitnessDataLen),
+	}
+	coinbaseCopy.MsgTx().AddTxOut(&wire.TxOut{
+		PkScript: bytes.Repeat([]byte("a"),
+			blockchain.CoinbaseWitnessPkScriptLength),
+	})
+	return uint32(blockchain.GetTransactionWeight(coinbaseCopy) -
+		blockchain.GetTransactionWeight(coinbaseTx))
+}
+
+func // packageHasWitness returns whether any transaction of a package bears
+// witness data.
+packageHasWitness(pkg []*txPrioItem) bool {
+	for _, item := range pkg {
+		if item.tx.HasWitness() {
+			return true
+		}
+	}
+	return false
+}
+
 func // MinimumMedianTime returns the minimum allowed timestamp for a block building
 // on the end of the provided best chain.  In particular, it is one second
 // after the median timestamp of the last several blocks per the chain
@@ -354,6 +763,7 @@ NewBlkTmplGenerator(policy *Policy, params *netparams.Params,
 	timeSource blockchain.MedianTimeSource,
 	sigCache *txscript.SigCache,
 	hashCache *txscript.HashCache) *BlkTmplGenerator {
+	metrics.Register("mining", templateBuildSeconds)
 	return &BlkTmplGenerator{
 		Policy:      policy,
 		ChainParams: params,
@@ -392,6 +802,12 @@ func // NewBlockTemplate returns a new block template that is ready to be solved
 // high-priority area (if configured) has been filled with transactions, or the
 // priority falls below what is considered high-priority, the priority queue is
 // updated to prioritize by fees per kilobyte (then priority).
+// When prioritizing by fees, the fees per kilobyte of a transaction are those
+// of the transaction together with its ancestors in the source pool that are
+// not in the block yet, and the ancestors are added along with it, so that a
+// transaction paying a high fee can get the transactions it spends from mined
+// (child pays for parent). The fees per kilobyte include the fee deltas of the
+// transactions, while the coinbase collects the fees they actually pay.
 // When the fees per kilobyte drop below the TxMinFreeFee policy setting, the
 // transaction will be skipped unless the BlockMinSize policy setting is
 // nonzero, in which case the block will be filled with the low-fee/free
@@ -429,8 +845,9 @@ func // NewBlockTemplate returns a new block template that is ready to be solved
 	// Extend the most recently known best block.
 	best := g.Chain.BestSnapshot()
 	nextBlockHeight := best.Height + 1
-	vers := fork.GetAlgoVer(algo, nextBlockHeight)
-	algo = fork.GetAlgoName(vers, nextBlockHeight)
+	vers := g.ChainParams.Forks.AlgoVer(algo, nextBlockHeight)
+	algo = g.ChainParams.Forks.AlgoName(vers, nextBlockHeight)
+	defer templateBuildSeconds.ObserveSince(time.Now(), algo)
 	// Trace("parsed block version", algo, vers)
 	// Create a standard coinbase transaction paying to the provided address.
 	// NOTE: The coinbase value will be updated to include the fees from the
@@ -451,15 +868,11 @@ func // NewBlockTemplate returns a new block template that is ready to be solved
 		return nil, err
 	}
 	coinbaseSigOpCost := int64(blockchain.CountSigOps(coinbaseTx)) * blockchain.WitnessScaleFactor
-	// Get the current source transactions and create a priority queue to hold
-	// the transactions which are ready for inclusion into a block along with
-	// some priority related and fee metadata.  Reserve the same number of items
-	// that are available for the priority queue.  Also, choose the initial sort
-	// order for the priority queue based on whether or not there is an area
-	// allocated for high-priority transactions.
+	// Get the current source transactions and choose the initial sort order
+	// for them based on whether or not there is an area allocated for
+	// high-priority transactions.
 	sourceTxns := g.TxSource.MiningDescs()
 	sortedByFee := g.Policy.BlockPrioritySize == 0
-	priorityQueue := newTxPriorityQueue(len(sourceTxns), sortedByFee)
 	// Create a slice to hold the transactions to be included in the generated
 	// block with reserved space.  Also create a utxo view to house all of the
 	// input transactions so multiple lookups can be avoided.
@@ -472,6 +885,8 @@ func // NewBlockTemplate returns a new block template that is ready to be solved
 	// dependent transactions are now eligible for inclusion in the block once
 	// each transaction has been included.
 	dependers := make(map[chainhash.Hash]map[chainhash.Hash]*txPrioItem)
+	// items holds the transactions that can be considered for the block.
+	items := make(map[chainhash.Hash]*txPrioItem, len(sourceTxns))
 	// Create slices to hold the fees and number of signature operations for
 	// each of the selected transactions and add an entry for the coinbase.
 	// This allows the code below to simply append details about a transaction
@@ -514,7 +929,7 @@ mempoolLoop:
 		}
 		// Setup dependencies for any transactions which reference other
 		// transactions in the mempool so they can be properly ordered below.
-		prioItem := &txPrioItem{tx: tx}
+		prioItem := &txPrioItem{tx: tx, index: -1}
 		for _, txIn := range tx.MsgTx().TxIn {
 			originHash := &txIn.PreviousOutPoint.Hash
 			entry := utxos.LookupEntry(txIn.PreviousOutPoint)
@@ -555,30 +970,20 @@ mempoolLoop:
 		// Calculate the fee in Satoshi/kB.
 		prioItem.feePerKB = txDesc.FeePerKB
 		prioItem.fee = txDesc.Fee
-		// Add the transaction to the priority queue to mark it ready for
-		// inclusion in the block unless it has dependencies.
-		if prioItem.dependsOn == nil {
-			heap.Push(priorityQueue, prioItem)
-		}
+		prioItem.modifiedFee = txDesc.Fee + txDesc.FeeDelta
+		prioItem.size = (blockchain.GetTransactionWeight(tx) +
+			blockchain.WitnessScaleFactor - 1) / blockchain.WitnessScaleFactor
+		items[*tx.Hash()] = prioItem
 		// Merge the referenced outputs from the input transactions to this
 		// transaction into the block utxo view.  This allows the code below to
 		// avoid a second lookup.
 		mergeUtxoView(blockUtxos, utxos)
 	}
-	// Tracec(func() string {
-	//	return fmt.Sprintf(
-	//		"priority queue len %d, dependers len %d",
-	//		priorityQueue.Len(),
-	//		len(dependers),
-	//	)
-	// })
 	// The starting block size is the size of the block header plus the max
 	// possible transaction count size, plus the size of the coinbase
 	// transaction.
 	blockWeight := uint32((blockHeaderOverhead * blockchain.WitnessScaleFactor) +
 		blockchain.GetTransactionWeight(coinbaseTx))
-	blockSigOpCost := coinbaseSigOpCost
-	totalFees := int64(0)
 	// Query the version bits state to see if segwit has been activated, if so
 	// then this means that we'll include any transactions with witness data in
 	// the mempool, and also add the witness commitment as an OP_RETURN output
@@ -589,173 +994,58 @@ mempoolLoop:
 		return nil, err
 	}
 	segwitActive := segwitState == blockchain.ThresholdActive
-	witnessIncluded := false
 	// Choose which transactions make it into the block.
-	for priorityQueue.Len() > 0 {
-		// Grab the highest priority (or highest fee per kilobyte depending on
-		// the sort order) transaction.
-		prioItem := heap.Pop(priorityQueue).(*txPrioItem)
-		tx := prioItem.tx
-		switch {
-		// If segregated witness has not been activated yet, then we shouldn't
-		// include any witness transactions in the block.
-		case !segwitActive && tx.HasWitness():
-			continue
-			// Otherwise, Keep track of if we've included a transaction with witness
-			// data or not. If so, then we'll need to include the witness commitment
-			// as the last output in the coinbase transaction.
-		case segwitActive && !witnessIncluded && tx.HasWitness():
-			// If we're about to include a transaction bearing witness data,
-			// then
-			// we'll also need to include a witness commitment in the coinbase
-			// transaction. Therefore,
-			// we account for the additional weight within
-			// the block with a model coinbase tx with a witness commitment.
-			coinbaseCopy := util.NewTx(coinbaseTx.MsgTx().Copy())
-			coinbaseCopy.MsgTx().TxIn[0].Witness = [][]byte{
-				bytes.Repeat([]byte("a"),
-					blockchain.CoinbaseWitnessDataLen),
+	selector := &txSelector{
+		policy:         g.Policy,
+		items:          items,
+		dependers:      dependers,
+		sortedByFee:    sortedByFee,
+		segwitActive:   segwitActive,
+		witnessWeight:  witnessCommitmentWeight(coinbaseTx),
+		blockTxns:      blockTxns,
+		txFees:         txFees,
+		txSigOpCosts:   txSigOpCosts,
+		blockWeight:    blockWeight,
+		blockSigOpCost: coinbaseSigOpCost,
+		sigOpCost: func(tx *util.Tx) (int, error) {
+			return blockchain.GetSigOpCost(tx, false, blockUtxos, true,
+				segwitActive)
+		},
+		checkTx: func(tx *util.Tx) error {
+			// Ensure the transaction inputs pass all of the necessary
+			// preconditions before allowing it to be added to the block.
+			_, err := blockchain.CheckTransactionInputs(tx, nextBlockHeight,
+				blockUtxos, g.ChainParams)
+			if err != nil {
+				Tracef("skipping tx %s due to error in CheckTransactionInputs"+
+					": %v",
+					tx.Hash(), err)
+				return err
 			}
-			coinbaseCopy.MsgTx().AddTxOut(&wire.TxOut{
-				PkScript: bytes.Repeat([]byte("a"),
-					blockchain.CoinbaseWitnessPkScriptLength),
-			})
-			// In order to accurately account for the weight addition due to
-			// this
-			// coinbase transaction, we'll add the difference of the transaction
-			// before and after the addition of the commitment to the block
-			// weight.
-			weightDiff := blockchain.GetTransactionWeight(coinbaseCopy) -
-				blockchain.GetTransactionWeight(coinbaseTx)
-			blockWeight += uint32(weightDiff)
-			witnessIncluded = true
-		}
-		// Grab any transactions which depend on this one.
-		deps := dependers[*tx.Hash()]
-		// Enforce maximum block size.  Also check for overflow.
-		txWeight := uint32(blockchain.GetTransactionWeight(tx))
-		blockPlusTxWeight := blockWeight + txWeight
-		if blockPlusTxWeight < blockWeight ||
-			blockPlusTxWeight >= g.Policy.BlockMaxWeight {
-			Tracef("skipping tx %s because it would exceed the max block"+
-				" weight", tx.Hash())
-			logSkippedDeps(tx, deps)
-			continue
-		}
-		// Enforce maximum signature operation cost per block.  Also check for
-		// overflow.
-		sigOpCost, err := blockchain.GetSigOpCost(tx, false,
-			blockUtxos, true, segwitActive)
-		if err != nil {
-			Tracec(func() string {
-				return "skipping tx " + tx.Hash().String() +
-					"due to error in GetSigOpCost: " + err.Error()
-			})
-			logSkippedDeps(tx, deps)
-			continue
-		}
-		if blockSigOpCost+int64(sigOpCost) < blockSigOpCost ||
-			blockSigOpCost+int64(sigOpCost) > blockchain.MaxBlockSigOpsCost {
-			Tracec(func() string {
-				return "skipping tx " + tx.Hash().String() +
-					" because it would exceed the maximum sigops per block"
-			})
-			logSkippedDeps(tx, deps)
-			continue
-		}
-		// Skip free transactions once the block is larger than the minimum
-		// block size.
-		if sortedByFee &&
-			prioItem.feePerKB < int64(g.Policy.TxMinFreeFee) &&
-			blockPlusTxWeight >= g.Policy.BlockMinWeight {
-			Tracec(func() string {
-				return fmt.Sprint(
-					"skipping tx ", tx.Hash(),
-					" with feePerKB ", prioItem.feePerKB,
-					" < TxMinFreeFee ", g.Policy.TxMinFreeFee,
-					" and block weight ", blockPlusTxWeight,
-					" >= minBlockWeight ", g.Policy.BlockMinWeight,
-				)
-			})
-			logSkippedDeps(tx, deps)
-			continue
-		}
-		// Prioritize by fee per kilobyte once the block is larger than the
-		// priority size or there are no more high-priority transactions.
-		if !sortedByFee && (blockPlusTxWeight >= g.Policy.BlockPrioritySize ||
-			prioItem.priority <= MinHighPriority.ToDUO()) {
-			Tracef("switching to sort by fees per kilobyte blockSize %d"+
-				" >= BlockPrioritySize %d || priority %.2f <= minHighPriority %.2f",
-				blockPlusTxWeight,
-				g.Policy.BlockPrioritySize,
-				prioItem.priority,
-				MinHighPriority)
-			sortedByFee = true
-			priorityQueue.SetLessFunc(txPQByFee)
-		}
-		// Put the transaction back into the priority queue and skip it
-		// so it is re-prioritized by fees if it won't fit into the high-priority
-		// section or the priority is too low.  Otherwise this transaction
-		// will be the final one in the high-priority section, so just fall
-		// though to the code below so it is added now.
-		if blockPlusTxWeight > g.Policy.BlockPrioritySize ||
-			prioItem.priority < MinHighPriority.ToDUO() {
-			heap.Push(priorityQueue, prioItem)
-			continue
-		}
-
-		// Ensure the transaction inputs pass all of the necessary preconditions
-		// before allowing it to be added to the block.
-		_, err = blockchain.CheckTransactionInputs(tx, nextBlockHeight,
-			blockUtxos, g.ChainParams)
-		if err != nil {
-			Tracef("skipping tx %s due to error in CheckTransactionInputs"+
-				": %v",
-				tx.Hash(), err)
-			logSkippedDeps(tx, deps)
-			continue
-		}
-		err = blockchain.ValidateTransactionScripts(g.Chain, tx, blockUtxos,
-			txscript.StandardVerifyFlags, g.SigCache,
-			g.HashCache)
-		if err != nil {
-			Tracef("skipping tx %s due to error in"+
-				" ValidateTransactionScripts: %v",
-				tx.Hash(), err)
-			logSkippedDeps(tx, deps)
-			continue
-		}
-
-		// Spend the transaction inputs in the block utxo view and add an entry
-		// for it to ensure any transactions which reference this one have it
-		// available as an input and can ensure they aren't double spending.
-		err = spendTransaction(blockUtxos, tx, nextBlockHeight)
-		if err != nil {
-			Error(err)
-		}
-		// Add the transaction to the block, increment counters, and save the
-		// fees and signature operation counts to the block template.
-		blockTxns = append(blockTxns, tx)
-		blockWeight += txWeight
-		blockSigOpCost += int64(sigOpCost)
-		totalFees += prioItem.fee
-		txFees = append(txFees, prioItem.fee)
-		txSigOpCosts = append(txSigOpCosts, int64(sigOpCost))
-		Tracef("adding tx %s (priority %.2f, feePerKB %.2f)",
-			prioItem.tx.Hash(),
-			prioItem.priority,
-			prioItem.feePerKB)
-		// Add transactions which depend on this one (and also do not have any
-		// other unsatisified dependencies) to the priority queue.
-		for _, item := range deps {
-			// Add the transaction to the priority queue if there are no more
-			// dependencies after this one.
-			delete(item.dependsOn, *tx.Hash())
-			if len(item.dependsOn) == 0 {
-				heap.Push(priorityQueue, item)
+			err = blockchain.ValidateTransactionScripts(g.Chain, tx, blockUtxos,
+				txscript.StandardVerifyFlags, g.SigCache,
+				g.HashCache)
+			if err != nil {
+				Tracef("skipping tx %s due to error in"+
+					" ValidateTransactionScripts: %v",
+					tx.Hash(), err)
+				return err
 			}
-		}
+			// Spend the transaction inputs in the block utxo view and add an
+			// entry for it to ensure any transactions which reference this
+			// one have it available as an input and can ensure they aren't
+			// double spending.
+			if err = spendTransaction(blockUtxos, tx, nextBlockHeight); err != nil {
+				Error(err)
+			}
+			return nil
+		},
 	}
+	selector.selectTxns()
+	blockTxns, txFees, txSigOpCosts = selector.blockTxns, selector.txFees,
+		selector.txSigOpCosts
+	blockWeight, blockSigOpCost := selector.blockWeight, selector.blockSigOpCost
+	totalFees, witnessIncluded := selector.totalFees, selector.witnessIncluded
 	// Now that the actual transactions have been selected, update the block
 	// weight for the real transaction count and coinbase value with the total
 	// fees accordingly.
@@ -883,7 +1173,7 @@ func // UpdateBlockTime updates the timestamp in the header of the passed
 	if g.ChainParams.ReduceMinDifficulty {
 		difficulty, err := g.Chain.CalcNextRequiredDifficulty(
 			workerNumber, newTime,
-			fork.GetAlgoName(msgBlock.Header.Version, g.BestSnapshot().Height))
+			g.ChainParams.Forks.AlgoName(msgBlock.Header.Version, g.BestSnapshot().Height))
 		if err != nil {
 			Error(err)
 			return err
@@ -48,8 +48,7 @@ func // ProcessBlock is the main workhorse for handling insertion of new blocks
 	defer b.chainLock.Unlock()
 	fastAdd := flags&BFFastAdd == BFFastAdd
 	blockHash := block.Hash()
-	hf := fork.GetCurrent(blockHeight)
-	bhwa := block.MsgBlock().BlockHashWithAlgos
+	hf := b.params.Forks.Current(blockHeight)
 	var algo int32
 	switch hf {
 	case 0:
@@ -68,7 +67,7 @@ func // ProcessBlock is the main workhorse for handling insertion of new blocks
 		return false, false, err
 	}
 	if exists {
-		str := fmt.Sprintf("already have block %v", bhwa(blockHeight).String())
+		str := fmt.Sprintf("already have block %v", blockHash)
 		return false, false, ruleError(ErrDuplicateBlock, str)
 	}
 	// The block must not already exist as an orphan.
@@ -79,7 +78,7 @@ func // ProcessBlock is the main workhorse for handling insertion of new blocks
 	}
 	// Perform preliminary sanity checks on the block and its transactions.
 	var DoNotCheckPow bool
-	pl := fork.GetMinDiff(fork.GetAlgoName(algo, blockHeight), blockHeight)
+	pl := b.params.Forks.MinDiff(b.params.Forks.AlgoName(algo, blockHeight), blockHeight)
 	// Warnf("powLimit %d %s %d %064x", algo, fork.GetAlgoName(algo,
 	// 	blockHeight), blockHeight, pl)
 	ph := &block.MsgBlock().Header.PrevBlock
@@ -88,14 +87,14 @@ func // ProcessBlock is the main workhorse for handling insertion of new blocks
 		// Warn("found no previous node")
 		DoNotCheckPow = true
 	}
-	pb := pn.GetLastWithAlgo(algo)
+	pb := pn.GetLastWithAlgo(algo, b.params.Forks)
 	if pb == nil {
 		// pl = &netparams.AllOnes !!!!!!!!!!!!!!!!!!
 		DoNotCheckPow = true
 	}
 	// Warnf("checkBlockSanity powLimit %d %s %d %064x", algo,
 	// 	fork.GetAlgoName(algo, blockHeight), blockHeight, pl)
-	err = checkBlockSanity(block, pl, b.timeSource, flags, DoNotCheckPow, blockHeight)
+	err = checkBlockSanity(block, pl, b.params.Forks, b.timeSource, flags, DoNotCheckPow, blockHeight)
 	if err != nil {
 		Error("block processing error: ", err)
 		return false, false, err
@@ -119,7 +118,7 @@ func // ProcessBlock is the main workhorse for handling insertion of new blocks
 		checkpointTime := time.Unix(checkpointNode.timestamp, 0)
 		if blockHeader.Timestamp.Before(checkpointTime) {
 			str := fmt.Sprintf("block %v has timestamp %v before "+
-				"last checkpoint timestamp %v", bhwa(blockHeight).String(),
+				"last checkpoint timestamp %v", blockHash,
 				blockHeader.Timestamp, checkpointTime)
 			return false, false, ruleError(ErrCheckpointTimeTooOld, str)
 		}
@@ -154,7 +153,7 @@ func // ProcessBlock is the main workhorse for handling insertion of new blocks
 		// Warnc(func() string {
 		// 	return fmt.Sprintf(
 		// 		"adding orphan block %v with parent %v",
-		// 		bhwa(blockHeight).String(),
+		// 		blockHash,
 		// 		prevHash,
 		// 	)
 		// })
@@ -181,7 +180,7 @@ func // ProcessBlock is the main workhorse for handling insertion of new blocks
 		return false, false, err
 	}
 	Tracef("accepted block %d %v %s",
-		blockHeight, bhwa(blockHeight).String(), fork.GetAlgoName(block.MsgBlock().
+		blockHeight, blockHash, b.params.Forks.AlgoName(block.MsgBlock().
 			Header.Version, blockHeight))
 	// Warn("finished blockchain.ProcessBlock")
 	return isMainChain, false, nil
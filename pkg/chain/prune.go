@@ -0,0 +1,92 @@
+package blockchain
+
+import (
+	database "github.com/p9c/pod/pkg/db"
+)
+
+const (
+	// MinBlocksToKeep is the number of blocks at the end of the main chain
+	// that are never pruned, so that reorganizations up to this depth can
+	// still be made and the blocks can be served to peers (BIP0159)
+	MinBlocksToKeep = 288
+	// MinPruneTarget is the smallest size in MiB the blocks can be pruned to,
+	// which leaves room for the recent blocks that are kept
+	MinPruneTarget = 550
+)
+
+// pruneHeightKeyName is the name of the db key used to store the lowest height
+// of the main chain whose block has not been pruned
+var pruneHeightKeyName = []byte("pruneheight")
+
+func // dbFetchPruneHeight uses an existing database transaction to retrieve
+// the lowest height of the main chain whose block has not been pruned, which
+// is zero when no blocks have been pruned.
+dbFetchPruneHeight(dbTx database.Tx) int32 {
+	serialized := dbTx.Metadata().Get(pruneHeightKeyName)
+	if serialized == nil {
+		return 0
+	}
+	return int32(byteOrder.Uint32(serialized))
+}
+
+func // dbPutPruneHeight uses an existing database transaction to store the
+// lowest height of the main chain whose block has not been pruned.
+dbPutPruneHeight(dbTx database.Tx, height int32) error {
+	var serialized [4]byte
+	byteOrder.PutUint32(serialized[:], uint32(height))
+	return dbTx.Metadata().Put(pruneHeightKeyName, serialized[:])
+}
+
+func // pruneBlocks deletes the oldest blocks while the blocks take more space
+// than the prune target, never the last MinBlocksToKeep blocks up to the
+// passed tip, and returns the new prune height and the nodes of the pruned
+// blocks. The spend journal entries of the pruned blocks are deleted with them
+// as they are only needed to disconnect blocks. The data stored status of the
+// returned nodes must only be cleared once the transaction has committed.
+// This function MUST be called with the chain state lock held (for writes).
+(b *BlockChain) pruneBlocks(dbTx database.Tx, tip *BlockNode) (int32,
+	[]*BlockNode, error) {
+	pruneHeight := b.pruneHeight
+	keep := tip.RelativeAncestor(MinBlocksToKeep - 1)
+	if keep == nil {
+		return pruneHeight, nil, nil
+	}
+	hashes, err := dbTx.PruneBlocks(b.pruneTarget, &keep.hash)
+	if err != nil {
+		Error(err)
+		return pruneHeight, nil, err
+	}
+	var pruned []*BlockNode
+	for i := range hashes {
+		if err = dbRemoveSpendJournalEntry(dbTx, &hashes[i]); err != nil {
+			Error(err)
+			return pruneHeight, nil, err
+		}
+		node := b.Index.LookupNode(&hashes[i])
+		if node == nil {
+			continue
+		}
+		pruned = append(pruned, node)
+		if b.BestChain.Contains(node) && node.height >= pruneHeight {
+			pruneHeight = node.height + 1
+		}
+	}
+	if pruneHeight != b.pruneHeight {
+		if err = dbPutPruneHeight(dbTx, pruneHeight); err != nil {
+			Error(err)
+			return b.pruneHeight, nil, err
+		}
+		Infof("pruned %d blocks, blocks are stored from height %d",
+			len(hashes), pruneHeight)
+	}
+	return pruneHeight, pruned, nil
+}
+
+func // PruneHeight returns the lowest height of the main chain whose block is
+// still stored, which is zero when no blocks have been pruned.
+// This function is safe for concurrent access.
+(b *BlockChain) PruneHeight() int32 {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+	return b.pruneHeight
+}
@@ -5,7 +5,6 @@ import (
 	"math"
 	"runtime"
 
-	"github.com/p9c/pod/pkg/chain/hardfork"
 	txscript "github.com/p9c/pod/pkg/chain/tx/script"
 	"github.com/p9c/pod/pkg/chain/wire"
 	"github.com/p9c/pod/pkg/util"
@@ -199,7 +198,7 @@ ValidateTransactionScripts(b *BlockChain, tx *util.Tx, utxoView *UtxoViewpoint,
 		// we ensure the sighashes are only computed once.
 		cachedHashes, _ = hashCache.GetSigHashes(tx.Hash())
 	}
-	if ContainsBlacklisted(b, tx, hardfork.Blacklist) {
+	if ContainsBlacklisted(b, tx) {
 		return ruleError(ErrBlacklisted, "transaction contains blacklisted address ")
 	}
 	// Collect all of the transaction inputs and required information for
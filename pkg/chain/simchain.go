@@ -0,0 +1,25 @@
+package blockchain
+
+import (
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+)
+
+// NewSimChain returns a chain without a database or index, whose difficulty
+// adjustment functions can be driven with chains of block nodes created by
+// NewBlockNode, for simulating the retarget algorithms. Nothing else can be
+// done with it.
+func NewSimChain(params *netparams.Params) *BlockChain {
+	targetTimespan := params.TargetTimespan
+	targetTimePerBlock := params.TargetTimePerBlock
+	adjustmentFactor := params.RetargetAdjustmentFactor
+	b := &BlockChain{
+		params:                params,
+		minRetargetTimespan:   targetTimespan / adjustmentFactor,
+		maxRetargetTimespan:   targetTimespan * adjustmentFactor,
+		blocksPerRetarget:     int32(targetTimespan / targetTimePerBlock),
+		BestChain:             newChainView(nil),
+		DifficultyAdjustments: make(map[string]float64),
+	}
+	b.DifficultyBits.Store(make(TargetBits))
+	return b
+}
@@ -0,0 +1,305 @@
+package blockchain
+
+import (
+	"fmt"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/chain/wire"
+	database "github.com/p9c/pod/pkg/db"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// snapshotValidatorLogInterval is the number of blocks between the progress
+// messages of a snapshot validator
+const snapshotValidatorLogInterval = 10000
+
+// snapshotValidatorBucketName is the name of the db bucket used to house the
+// utxo set a snapshot validator builds from the genesis block, in the same
+// format as the utxo set bucket.
+var snapshotValidatorBucketName = []byte("snapshotvalidatorutxos")
+
+// SnapshotValidator validates the blocks before the utxo snapshot the chain
+// was started from, building the utxo set from the genesis block in a scratch
+// bucket of the database, and checks that the blocks lead to the utxo set of the snapshot. The headers
+// of the blocks are checked again along with the blocks, the same as when the
+// snapshot was loaded, and the blocks are not stored.
+type SnapshotValidator struct {
+	b *BlockChain
+	// headers works out the difficulty required of the blocks
+	headers  *BlockChain
+	snapshot UtxoSnapshot
+	// coins is the number of utxos in the scratch bucket
+	coins uint64
+	// next is the height of the next block to be connected
+	next int32
+	// pending holds the blocks that arrived before the ones they build on
+	pending map[chainhash.Hash]*util.Block
+}
+
+// NewSnapshotValidator returns a validator for the blocks before the utxo
+// snapshot the chain was started from, or nil when the chain was not started
+// from one or it has already been validated.
+func (b *BlockChain) NewSnapshotValidator() *SnapshotValidator {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+	if b.utxoSnapshot == nil || b.utxoSnapshot.Status != SnapshotPending {
+		return nil
+	}
+	// The validation starts over from the genesis block, so the utxo set of
+	// one that was stopped before it was over is dropped.
+	err := b.db.Update(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		if meta.Bucket(snapshotValidatorBucketName) != nil {
+			if err := meta.DeleteBucket(snapshotValidatorBucketName); err != nil {
+				return err
+			}
+		}
+		_, err := meta.CreateBucket(snapshotValidatorBucketName)
+		return err
+	})
+	if err != nil {
+		Error(err)
+		return nil
+	}
+	return &SnapshotValidator{
+		b:        b,
+		headers:  newHeaderChain(b.params),
+		snapshot: *b.utxoSnapshot,
+		next:     1,
+		pending:  make(map[chainhash.Hash]*util.Block),
+	}
+}
+
+// Height returns the height of the snapshot being validated
+func (v *SnapshotValidator) Height() int32 {
+	return v.snapshot.Height
+}
+
+// Wanted returns the hashes of up to n of the next blocks the validator needs
+// that it doesn't have yet, in order.
+func (v *SnapshotValidator) Wanted(n int) (hashes []chainhash.Hash) {
+	for height := v.next; height <= v.snapshot.Height &&
+		len(hashes) < n; height++ {
+		node := v.b.BestChain.NodeByHeight(height)
+		if node == nil {
+			break
+		}
+		if _, ok := v.pending[node.hash]; ok {
+			continue
+		}
+		hashes = append(hashes, node.hash)
+	}
+	return
+}
+
+// ProcessBlock adds a block before the snapshot to the validator and connects
+// the blocks it has in order. It returns true once the validation is over,
+// along with an error when a block is invalid or the blocks do not lead to the
+// utxo set of the snapshot, in which case the snapshot is marked invalid and
+// the chain can't be used any more. An error without the validation being
+// over means the block has to be fetched again.
+func (v *SnapshotValidator) ProcessBlock(block *util.Block) (bool, error) {
+	v.pending[*block.Hash()] = block
+	for v.next <= v.snapshot.Height {
+		node := v.b.BestChain.NodeByHeight(v.next)
+		block, ok := v.pending[node.hash]
+		if !ok {
+			return false, nil
+		}
+		delete(v.pending, node.hash)
+		if err := v.connectBlock(node, block); err != nil {
+			Error(err)
+			ruleErr, ok := err.(RuleError)
+			// Transactions that don't match the header are the fault of the
+			// peer that sent the block, so it can be fetched again.
+			if !ok || ruleErr.ErrorCode == ErrBadMerkleRoot ||
+				ruleErr.ErrorCode == ErrDuplicateTx {
+				return false, err
+			}
+			err = fmt.Errorf("block %v at height %d before the utxo "+
+				"snapshot is invalid: %v", node.hash, node.height, err)
+			return true, v.setStatus(SnapshotInvalid, err)
+		}
+		if v.next%snapshotValidatorLogInterval == 0 {
+			Infof("validated the blocks up to height %d of the %d before "+
+				"the utxo snapshot", v.next, v.snapshot.Height)
+		}
+		v.next++
+	}
+	utxoHash, err := v.utxoHash()
+	if err != nil {
+		return false, err
+	}
+	if !utxoHash.IsEqual(&v.snapshot.UtxoHash) {
+		err := fmt.Errorf("the blocks before the utxo snapshot at height %d "+
+			"lead to the utxo set %v, not %v", v.snapshot.Height, utxoHash,
+			v.snapshot.UtxoHash)
+		return true, v.setStatus(SnapshotInvalid, err)
+	}
+	Infof("validated the %d blocks before the utxo snapshot at height %d",
+		v.snapshot.Height, v.snapshot.Height)
+	return true, v.setStatus(SnapshotValid, nil)
+}
+
+// connectBlock validates a block and connects it to the utxo set of the
+// validator.
+func (v *SnapshotValidator) connectBlock(node *BlockNode, block *util.Block) error {
+	b := v.b
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+	block.SetHeight(node.height)
+	algo := b.params.Forks.AlgoName(node.version, node.height)
+	powLimit := b.params.Forks.MinDiff(algo, node.height)
+	err := checkBlockSanity(block, powLimit, b.params.Forks, b.timeSource,
+		BFNone, false, node.height)
+	if err != nil {
+		return err
+	}
+	err = v.headers.checkBlockHeaderDifficulty(0, &block.MsgBlock().Header,
+		node.parent)
+	if err != nil {
+		return err
+	}
+	if err = b.checkBlockTransactionsContext(block, node.parent); err != nil {
+		return err
+	}
+	// The view has to answer for every output the block creates or spends,
+	// or it would look for them in the utxo set of the chain, which is the one
+	// of the snapshot. The outputs are fetched from the scratch bucket, and
+	// the ones that are not in it are added as missing ones.
+	view := NewUtxoViewpoint()
+	view.SetBestHash(&node.parent.hash)
+	err = b.db.View(func(dbTx database.Tx) error {
+		utxoBucket := dbTx.Metadata().Bucket(snapshotValidatorBucketName)
+		fetch := func(outpoint wire.OutPoint) error {
+			if _, ok := view.entries[outpoint]; ok {
+				return nil
+			}
+			key := outpointKey(outpoint)
+			serialized := utxoBucket.Get(*key)
+			recycleOutpointKey(key)
+			if serialized == nil {
+				view.entries[outpoint] = nil
+				return nil
+			}
+			entry, err := deserializeUtxoEntry(serialized)
+			if err != nil {
+				return err
+			}
+			view.entries[outpoint] = entry
+			return nil
+		}
+		inBlock := make(map[chainhash.Hash]struct{})
+		for i, tx := range block.Transactions() {
+			if i > 0 {
+				for _, txIn := range tx.MsgTx().TxIn {
+					if _, ok := inBlock[txIn.PreviousOutPoint.Hash]; ok {
+						continue
+					}
+					if err := fetch(txIn.PreviousOutPoint); err != nil {
+						return err
+					}
+				}
+			}
+			inBlock[*tx.Hash()] = struct{}{}
+			outpoint := wire.OutPoint{Hash: *tx.Hash()}
+			for i := range tx.MsgTx().TxOut {
+				outpoint.Index = uint32(i)
+				if err := fetch(outpoint); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		Error(err)
+		return err
+	}
+	if err = b.checkConnectBlock(node, block, view, nil); err != nil {
+		return err
+	}
+	// Write the outputs the block left unspent to the scratch bucket and
+	// remove the ones it spent.
+	coins := v.coins
+	err = b.db.Update(func(dbTx database.Tx) error {
+		utxoBucket := dbTx.Metadata().Bucket(snapshotValidatorBucketName)
+		for outpoint, entry := range view.entries {
+			if entry == nil || !entry.isModified() {
+				continue
+			}
+			key := outpointKey(outpoint)
+			had := utxoBucket.Get(*key) != nil
+			var err error
+			switch {
+			case entry.IsSpent():
+				if had {
+					coins--
+				}
+				err = utxoBucket.Delete(*key)
+			default:
+				var serialized []byte
+				if serialized, err = serializeUtxoEntry(entry); err == nil {
+					if !had {
+						coins++
+					}
+					err = utxoBucket.Put(*key, serialized)
+				}
+			}
+			recycleOutpointKey(key)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		Error(err)
+		return err
+	}
+	v.coins = coins
+	return nil
+}
+
+// utxoHash returns the hash of the utxo set of the validator as it is in a
+// utxo snapshot, reading it from the scratch bucket in the order of its keys.
+func (v *SnapshotValidator) utxoHash() (utxoHash chainhash.Hash, err error) {
+	err = v.b.db.View(func(dbTx database.Tx) error {
+		hasher := newUtxoHasher(v.coins)
+		cursor := dbTx.Metadata().Bucket(snapshotValidatorBucketName).Cursor()
+		for ok := cursor.First(); ok; ok = cursor.Next() {
+			if err := writeUtxo(hasher, cursor.Key(), cursor.Value()); err != nil {
+				return err
+			}
+		}
+		utxoHash = hasher.utxoHash()
+		return nil
+	})
+	if err != nil {
+		Error(err)
+	}
+	return
+}
+
+// setStatus stores the result of the validation of the snapshot along with
+// removing the scratch bucket, and returns the error that it failed with.
+func (v *SnapshotValidator) setStatus(status SnapshotStatus, failure error) error {
+	b := v.b
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+	s := *b.utxoSnapshot
+	s.Status = status
+	err := b.db.Update(func(dbTx database.Tx) error {
+		err := dbTx.Metadata().DeleteBucket(snapshotValidatorBucketName)
+		if err != nil {
+			return err
+		}
+		return dbPutUtxoSnapshot(dbTx, &s)
+	})
+	if err != nil {
+		Error(err)
+		return err
+	}
+	b.utxoSnapshot = &s
+	return failure
+}
@@ -0,0 +1,287 @@
+package netsync
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/chain/wire"
+	peerpkg "github.com/p9c/pod/pkg/peer"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// maxCmpctHighBandwidthPeers is the maximum number of peers that are asked to
+// send new blocks as cmpctblock messages without announcing them first.
+const maxCmpctHighBandwidthPeers = 3
+
+type (
+	// cmpctBlockMsg packages a bitcoin cmpctblock message and the peer it came
+	// from together so the block handler has access to that information.
+	cmpctBlockMsg struct {
+		cmpctBlock *wire.MsgCmpctBlock
+		peer       *peerpkg.Peer
+		reply      chan struct{}
+	}
+	// blockTxnMsg packages a bitcoin blocktxn message and the peer it came from
+	// together so the block handler has access to that information.
+	blockTxnMsg struct {
+		blockTxn *wire.MsgBlockTxn
+		peer     *peerpkg.Peer
+		reply    chan struct{}
+	}
+	// partialBlock is a block reconstructed from a compact block that is
+	// waiting for the transactions that could not be found in the mempool.
+	partialBlock struct {
+		hash    chainhash.Hash
+		block   *wire.MsgBlock
+		missing []uint32
+	}
+)
+
+// QueueCmpctBlock adds the passed cmpctblock message and peer to the block
+// handling queue. Responds to the done channel argument after the message is
+// processed.
+func (sm *SyncManager) QueueCmpctBlock(msg *wire.MsgCmpctBlock, peer *peerpkg.Peer, done chan struct{}) {
+	// Don't accept more blocks if we're shutting down.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		done <- struct{}{}
+		return
+	}
+	sm.msgChan <- &cmpctBlockMsg{cmpctBlock: msg, peer: peer, reply: done}
+}
+
+// QueueBlockTxn adds the passed blocktxn message and peer to the block
+// handling queue. Responds to the done channel argument after the message is
+// processed.
+func (sm *SyncManager) QueueBlockTxn(msg *wire.MsgBlockTxn, peer *peerpkg.Peer, done chan struct{}) {
+	// Don't accept more blocks if we're shutting down.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		done <- struct{}{}
+		return
+	}
+	sm.msgChan <- &blockTxnMsg{blockTxn: msg, peer: peer, reply: done}
+}
+
+// handleCmpctBlockMsg handles cmpctblock messages from all peers. The block is
+// reconstructed from the transactions in the mempool, and the ones that are
+// not there are requested from the peer with a getblocktxn message.
+func (sm *SyncManager) handleCmpctBlockMsg(workerNumber uint32, cmsg *cmpctBlockMsg) {
+	pp := cmsg.peer
+	state, exists := sm.peerStates[pp]
+	if !exists {
+		Trace("received cmpctblock message from unknown peer", pp)
+		return
+	}
+	msg := cmsg.cmpctBlock
+	blockHash := msg.Header.BlockHash()
+	have, err := sm.chain.HaveBlock(&blockHash)
+	if err != nil {
+		Error(err)
+		return
+	}
+	if have {
+		delete(state.requestedBlocks, blockHash)
+		delete(sm.requestedBlocks, blockHash)
+		return
+	}
+	if _, exists = state.requestedBlocks[blockHash]; !exists {
+		// Only the peers we asked to send new blocks this way may send them
+		// unrequested, and they are only of use once we are current, as
+		// they are expected to be made of transactions in our mempool.
+		if !pp.IsCmpctHighBandwidth() || !sm.current() {
+			Debugf("ignoring unrequested cmpctblock %v from %s",
+				blockHash, pp)
+			return
+		}
+		// The block is already being fetched from another peer.
+		if _, exists = sm.requestedBlocks[blockHash]; exists {
+			return
+		}
+		sm.requestedBlocks[blockHash] = struct{}{}
+		sm.limitMap(sm.requestedBlocks, maxRequestedBlocks)
+		state.requestedBlocks[blockHash] = struct{}{}
+	}
+	block, missing, err := sm.reconstructBlock(msg, pp.CmpctBlockVersion())
+	if err != nil {
+		Debugf("unable to reconstruct block %v from %s: %v", blockHash,
+			pp, err)
+		sm.requestFullBlock(pp, &blockHash)
+		return
+	}
+	if len(missing) > 0 {
+		Tracef("requesting %d of %d transactions of block %v from %s",
+			len(missing), len(block.Transactions), blockHash, pp)
+		state.partialBlock = &partialBlock{
+			hash:    blockHash,
+			block:   block,
+			missing: missing,
+		}
+		pp.QueueMessage(wire.NewMsgGetBlockTxn(&blockHash, missing), nil)
+		return
+	}
+	sm.handleReconstructedBlock(workerNumber, pp, block)
+}
+
+// handleBlockTxnMsg handles blocktxn messages from all peers, which complete
+// the block the peer sent as a compact block before.
+func (sm *SyncManager) handleBlockTxnMsg(workerNumber uint32, bmsg *blockTxnMsg) {
+	pp := bmsg.peer
+	state, exists := sm.peerStates[pp]
+	if !exists {
+		Trace("received blocktxn message from unknown peer", pp)
+		return
+	}
+	msg := bmsg.blockTxn
+	partial := state.partialBlock
+	if partial == nil || !partial.hash.IsEqual(&msg.BlockHash) {
+		Debugf("ignoring unrequested blocktxn %v from %s", msg.BlockHash,
+			pp)
+		return
+	}
+	state.partialBlock = nil
+	if len(msg.Transactions) != len(partial.missing) {
+		Debugf("blocktxn %v from %s has %d transactions, requested %d",
+			msg.BlockHash, pp, len(msg.Transactions), len(partial.missing))
+		sm.requestFullBlock(pp, &partial.hash)
+		return
+	}
+	for i, index := range partial.missing {
+		partial.block.Transactions[index] = msg.Transactions[i]
+	}
+	sm.handleReconstructedBlock(workerNumber, pp, partial.block)
+}
+
+// reconstructBlock fills in the transactions of a compact block with those
+// sent in full and those in the mempool with matching short IDs. It returns
+// the block along with the indexes of the transactions that were not found,
+// in increasing order. Mempool transactions that share a short ID can't be
+// told apart, so those are counted as not found.
+func (sm *SyncManager) reconstructBlock(msg *wire.MsgCmpctBlock, version uint64) (*wire.MsgBlock, []uint32, error) {
+	count := msg.TxCount()
+	if count == 0 {
+		return nil, nil, errors.New("compact block has no transactions")
+	}
+	txs := make([]*wire.MsgTx, count)
+	// The prefilled indexes are checked to be increasing and in range when the
+	// message is decoded.
+	for _, prefilled := range msg.PrefilledTxs {
+		txs[prefilled.Index] = prefilled.Tx
+	}
+	// The short IDs fill the gaps between the prefilled transactions in order.
+	positions := make(map[uint64]int, len(msg.ShortIDs))
+	next := 0
+	for _, id := range msg.ShortIDs {
+		for txs[next] != nil {
+			next++
+		}
+		if _, exists := positions[id]; exists {
+			return nil, nil, fmt.Errorf("duplicate short ID %x", id)
+		}
+		positions[id] = next
+		next++
+	}
+	key := msg.ShortIDKey()
+	found := make(map[uint64]*wire.MsgTx, len(positions))
+	for _, desc := range sm.txMemPool.TxDescs() {
+		hash := desc.Tx.Hash()
+		if version == wire.CmpctBlockWitnessVersion {
+			hash = desc.Tx.WitnessHash()
+		}
+		id := wire.ShortID(&key, hash)
+		if _, exists := positions[id]; !exists {
+			continue
+		}
+		if _, exists := found[id]; exists {
+			found[id] = nil
+			continue
+		}
+		found[id] = desc.Tx.MsgTx()
+	}
+	var missing []uint32
+	for id, pos := range positions {
+		if tx := found[id]; tx != nil {
+			txs[pos] = tx
+			continue
+		}
+		missing = append(missing, uint32(pos))
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return missing[i] < missing[j]
+	})
+	return &wire.MsgBlock{Header: msg.Header, Transactions: txs}, missing, nil
+}
+
+// handleReconstructedBlock processes a block reconstructed from a compact
+// block as if the peer had sent it in full.
+func (sm *SyncManager) handleReconstructedBlock(workerNumber uint32, pp *peerpkg.Peer, msgBlock *wire.MsgBlock) {
+	block := util.NewBlock(msgBlock)
+	// A mempool transaction with the short ID of a different one in the block
+	// gives the wrong transactions, which shows in the merkle root. The block
+	// itself may well be valid, so get all of it rather than rejecting it.
+	merkles := blockchain.BuildMerkleTreeStore(block.Transactions(), false)
+	if !msgBlock.Header.MerkleRoot.IsEqual(merkles[len(merkles)-1]) {
+		Debugf("reconstructed block %v from %s has the wrong merkle root",
+			block.Hash(), pp)
+		sm.requestFullBlock(pp, block.Hash())
+		return
+	}
+	sm.handleBlockMsg(workerNumber, &blockMsg{block: block, peer: pp})
+}
+
+// requestFullBlock asks a peer for all of a block that could not be
+// reconstructed from its compact block. The block stays in the requested maps
+// so it is accepted when it arrives.
+func (sm *SyncManager) requestFullBlock(pp *peerpkg.Peer, hash *chainhash.Hash) {
+	iv := wire.NewInvVect(wire.InvTypeBlock, hash)
+	if pp.IsWitnessEnabled() {
+		iv.Type = wire.InvTypeWitnessBlock
+	}
+	gdmsg := wire.NewMsgGetData()
+	if err := gdmsg.AddInvVect(iv); err != nil {
+		Error(err)
+		return
+	}
+	pp.QueueMessage(gdmsg, nil)
+}
+
+// updateCmpctHighBandwidthPeers asks a peer that has just delivered a new
+// block to send the next ones as cmpctblock messages without announcing them
+// first, so that the peers that were the latest to relay a new block first are
+// the ones that do. The peer that was asked the longest ago is sent back to
+// announcing blocks as usual when there are too many.
+func (sm *SyncManager) updateCmpctHighBandwidthPeers(pp *peerpkg.Peer) {
+	if pp.CmpctBlockVersion() == 0 || !sm.current() {
+		return
+	}
+	peers := sm.cmpctHighBWPeers
+	for i := range peers {
+		if peers[i] == pp {
+			// Move the peer to the back as the latest to deliver a block.
+			copy(peers[i:], peers[i+1:])
+			peers[len(peers)-1] = pp
+			return
+		}
+	}
+	pp.PushSendCmpctMsg(true)
+	peers = append(peers, pp)
+	if len(peers) > maxCmpctHighBandwidthPeers {
+		peers[0].PushSendCmpctMsg(false)
+		peers = peers[1:]
+	}
+	sm.cmpctHighBWPeers = peers
+}
+
+// removeCmpctHighBandwidthPeer forgets a peer that was asked to send new
+// blocks as cmpctblock messages once it has disconnected.
+func (sm *SyncManager) removeCmpctHighBandwidthPeer(pp *peerpkg.Peer) {
+	peers := sm.cmpctHighBWPeers
+	for i := range peers {
+		if peers[i] == pp {
+			sm.cmpctHighBWPeers = append(peers[:i], peers[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,442 @@
+package netsync
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/p9c/pod/cmd/node/mempool"
+	blockchain "github.com/p9c/pod/pkg/chain"
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/chain/wire"
+	database "github.com/p9c/pod/pkg/db"
+	_ "github.com/p9c/pod/pkg/db/ffldb"
+	peerpkg "github.com/p9c/pod/pkg/peer"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// net4Loopback is the address the remote node of the harness reports.
+var net4Loopback = net.IPv4(127, 0, 0, 1)
+
+// testNotifier ignores the notifications of the sync manager for the server.
+type testNotifier struct{}
+
+func (testNotifier) AnnounceNewTransactions(newTxs []*mempool.TxDesc) {}
+func (testNotifier) UpdatePeerHeights(latestBlkHash *chainhash.Hash,
+	latestHeight int32, updateSource *peerpkg.Peer) {
+}
+func (testNotifier) RelayInventory(invVect *wire.InvVect, data interface{}) {}
+func (testNotifier) TransactionConfirmed(tx *util.Tx)                       {}
+
+// cmpctHarness is a sync manager with a regression test chain and mempool,
+// connected to a remote node that reports the messages it is sent.
+type cmpctHarness struct {
+	sm        *SyncManager
+	chain     *blockchain.BlockChain
+	pool      *mempool.TxPool
+	params    *netparams.Params
+	peer      *peerpkg.Peer
+	getData   chan *wire.MsgGetData
+	getBlkTxn chan *wire.MsgGetBlockTxn
+	coinbases []*wire.MsgTx
+	teardown  func()
+}
+
+// newCmpctHarness returns a harness with two blocks mined on the genesis
+// block, whose coinbases can be spent by the next block.
+func newCmpctHarness(t *testing.T) *cmpctHarness {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "cmpctblocktest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the coinbases are spendable in the next block, so the harness only has
+	// to mine a few blocks
+	chainParams := *netparams.RegressionTestParams.Params
+	chainParams.CoinbaseMaturity = 1
+	params := &netparams.Params{
+		Params:              &chainParams,
+		RPCClientPort:       netparams.RegressionTestParams.RPCClientPort,
+		WalletRPCServerPort: netparams.RegressionTestParams.WalletRPCServerPort,
+		Forks:               netparams.RegressionTestParams.Forks,
+	}
+	db, err := database.Create("ffldb", filepath.Join(dir, "blocks"),
+		params.Net)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("unable to create block database: %v", err)
+	}
+	h := &cmpctHarness{
+		params:    params,
+		getData:   make(chan *wire.MsgGetData, 1),
+		getBlkTxn: make(chan *wire.MsgGetBlockTxn, 1),
+	}
+	var listener net.Listener
+	h.teardown = func() {
+		if h.peer != nil {
+			h.peer.Disconnect()
+		}
+		if listener != nil {
+			listener.Close()
+		}
+		db.Close()
+		os.RemoveAll(dir)
+	}
+	if h.chain, err = blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: params,
+		TimeSource:  blockchain.NewMedianTime(),
+		SigCache:    txscript.NewSigCache(1000),
+	}); err != nil {
+		h.teardown()
+		t.Fatalf("unable to create chain: %v", err)
+	}
+	h.pool = mempool.New(&mempool.Config{
+		Policy: mempool.Policy{
+			DisableRelayPriority: true,
+			AcceptNonStd:         true,
+			FreeTxRelayLimit:     15.0,
+			MaxOrphanTxs:         5,
+			MaxOrphanTxSize:      1000,
+			MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
+			MinRelayTxFee:        1000,
+			MaxTxVersion:         wire.TxVersion,
+		},
+		ChainParams:   params,
+		FetchUtxoView: h.chain.FetchUtxoView,
+		BestHeight: func() int32 {
+			return h.chain.BestSnapshot().Height
+		},
+		MedianTimePast: func() time.Time {
+			return h.chain.BestSnapshot().MedianTime
+		},
+		CalcSequenceLock: func(tx *util.Tx, view *blockchain.UtxoViewpoint) (
+			*blockchain.SequenceLock, error) {
+			return h.chain.CalcSequenceLock(tx, view, true)
+		},
+		IsDeploymentActive: h.chain.IsDeploymentActive,
+	})
+	if h.sm, err = New(&Config{
+		PeerNotifier:       testNotifier{},
+		Chain:              h.chain,
+		TxMemPool:          h.pool,
+		ChainParams:        params,
+		DisableCheckpoints: true,
+		MaxPeers:           8,
+	}); err != nil {
+		h.teardown()
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		block := h.newBlock(t)
+		_, isOrphan, err := h.chain.ProcessBlock(0, util.NewBlock(block),
+			blockchain.BFNone, h.chain.BestSnapshot().Height+1)
+		if err != nil || isOrphan {
+			h.teardown()
+			t.Fatalf("unable to process block: %v, orphan %v", err,
+				isOrphan)
+		}
+		h.coinbases = append(h.coinbases, block.Transactions[0])
+	}
+	// connect the peer of the sync manager to a remote node that reports the
+	// requests for blocks and their transactions
+	if listener, err = net.Listen("tcp4", "127.0.0.1:0"); err != nil {
+		h.teardown()
+		t.Fatal(err)
+	}
+	go h.serveRemote(listener)
+	conn, err := net.Dial("tcp4", listener.Addr().String())
+	if err != nil {
+		h.teardown()
+		t.Fatal(err)
+	}
+	verack := make(chan struct{}, 1)
+	if h.peer, err = peerpkg.NewOutboundPeer(&peerpkg.Config{
+		Listeners: peerpkg.MessageListeners{
+			OnVerAck: func(p *peerpkg.Peer, msg *wire.MsgVerAck) {
+				verack <- struct{}{}
+			},
+		},
+		UserAgentName:    "peer",
+		UserAgentVersion: "1.0",
+		ChainParams:      params,
+		TrickleInterval:  time.Second * 10,
+	}, conn.RemoteAddr().String()); err != nil {
+		h.teardown()
+		t.Fatal(err)
+	}
+	h.peer.AssociateConnection(conn)
+	select {
+	case <-verack:
+	case <-time.After(time.Second * 5):
+		h.teardown()
+		t.Fatal("verack timeout")
+	}
+	h.sm.peerStates[h.peer] = &peerSyncState{
+		requestedTxns:   make(map[chainhash.Hash]struct{}),
+		requestedBlocks: make(map[chainhash.Hash]struct{}),
+	}
+	return h
+}
+
+// serveRemote answers the version handshake of the peer of the harness and
+// reports the getdata and getblocktxn messages it sends.
+func (h *cmpctHarness) serveRemote(listener net.Listener) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	pver, btcnet := wire.ProtocolVersion, h.params.Net
+	for {
+		msg, _, err := wire.ReadMessage(conn, pver, btcnet)
+		if err != nil {
+			return
+		}
+		switch msg := msg.(type) {
+		case *wire.MsgVersion:
+			me := wire.NewNetAddressIPPort(net4Loopback, 0, 0)
+			version := wire.NewMsgVersion(me, &msg.AddrMe, msg.Nonce+1, 0)
+			if err = wire.WriteMessage(conn, version, pver, btcnet); err != nil {
+				return
+			}
+			if err = wire.WriteMessage(conn, wire.NewMsgVerAck(), pver,
+				btcnet); err != nil {
+				return
+			}
+		case *wire.MsgGetData:
+			h.getData <- msg
+		case *wire.MsgGetBlockTxn:
+			h.getBlkTxn <- msg
+		}
+	}
+}
+
+// newBlock returns a solved block on the best chain with a coinbase paying
+// the subsidy to an anyone can spend output and the given transactions.
+func (h *cmpctHarness) newBlock(t *testing.T, txs ...*wire.MsgTx) *wire.MsgBlock {
+	t.Helper()
+	forks := h.params.Forks
+	best := h.chain.BestSnapshot()
+	tip, err := h.chain.HeaderByHash(&best.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	height := best.Height + 1
+	version := forks.AlgoSlices(forks.Current(height))[0].Version
+	algo := forks.AlgoName(version, height)
+	timestamp := tip.Timestamp.Add(time.Second)
+	bits, err := h.chain.CalcNextRequiredDifficulty(0, timestamp, algo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coinbaseScript, err := txscript.NewScriptBuilder().
+		AddInt64(int64(height)).AddInt64(0).Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		SignatureScript: coinbaseScript,
+		Sequence:        wire.MaxTxInSequenceNum,
+	})
+	coinbase.AddTxOut(&wire.TxOut{
+		Value:    blockchain.CalcBlockSubsidy(height, h.params, version),
+		PkScript: []byte{txscript.OP_TRUE},
+	})
+	block := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:   version,
+			PrevBlock: best.Hash,
+			Timestamp: timestamp,
+			Bits:      bits,
+		},
+		Transactions: append([]*wire.MsgTx{coinbase}, txs...),
+	}
+	utilTxs := make([]*util.Tx, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		utilTxs[i] = util.NewTx(tx)
+	}
+	merkles := blockchain.BuildMerkleTreeStore(utilTxs, false)
+	block.Header.MerkleRoot = *merkles[len(merkles)-1]
+	powLimit := forks.MinDiff(algo, height)
+	for {
+		b := util.NewBlock(block)
+		b.SetHeight(height)
+		if blockchain.CheckProofOfWork(b, powLimit, forks, height) == nil {
+			return block
+		}
+		block.Header.Nonce++
+	}
+}
+
+// spend returns a transaction spending the output of a mined coinbase to an
+// anyone can spend output.
+func (h *cmpctHarness) spend(coinbase int) *wire.MsgTx {
+	cb := h.coinbases[coinbase]
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 0), nil,
+		nil))
+	tx.TxIn[0].PreviousOutPoint.Hash = cb.TxHash()
+	tx.AddTxOut(wire.NewTxOut(cb.TxOut[0].Value-10000,
+		[]byte{txscript.OP_TRUE}))
+	return tx
+}
+
+// accept adds a transaction to the mempool of the harness.
+func (h *cmpctHarness) accept(t *testing.T, tx *wire.MsgTx) {
+	t.Helper()
+	if _, err := h.pool.ProcessTransaction(h.chain, util.NewTx(tx), false,
+		false, 0); err != nil {
+		t.Fatalf("transaction refused by the mempool: %v", err)
+	}
+}
+
+// handleCmpctBlock passes a compact block of a requested block to the sync
+// manager as if it had come from the peer of the harness.
+func (h *cmpctHarness) handleCmpctBlock(msg *wire.MsgCmpctBlock) {
+	hash := msg.Header.BlockHash()
+	h.sm.requestedBlocks[hash] = struct{}{}
+	h.sm.peerStates[h.peer].requestedBlocks[hash] = struct{}{}
+	h.sm.handleCmpctBlockMsg(0, &cmpctBlockMsg{cmpctBlock: msg, peer: h.peer})
+}
+
+// TestReconstructBlock ensures a compact block is rebuilt with the mempool
+// transactions of its short IDs in their places, and that the transactions
+// that are not in the mempool are reported missing.
+func TestReconstructBlock(t *testing.T) {
+	h := newCmpctHarness(t)
+	defer h.teardown()
+	inPool, notInPool := h.spend(0), h.spend(1)
+	h.accept(t, inPool)
+	block := h.newBlock(t, notInPool, inPool)
+	msg := wire.NewMsgCmpctBlockFromBlock(block, 1, wire.CmpctBlockVersion)
+	got, missing, err := h.sm.reconstructBlock(msg, wire.CmpctBlockVersion)
+	if err != nil {
+		t.Fatalf("reconstructBlock: %v", err)
+	}
+	if len(missing) != 1 || missing[0] != 1 {
+		t.Fatalf("missing transactions %v, want [1]", missing)
+	}
+	if got.Transactions[0] != block.Transactions[0] {
+		t.Fatal("prefilled coinbase not in place")
+	}
+	if got.Transactions[1] != nil {
+		t.Fatal("transaction not in the mempool was filled in")
+	}
+	if got.Transactions[2].TxHash() != inPool.TxHash() {
+		t.Fatal("mempool transaction not placed by its short ID")
+	}
+	// the witness version identifies transactions by their witness hash
+	msg = wire.NewMsgCmpctBlockFromBlock(block, 1,
+		wire.CmpctBlockWitnessVersion)
+	if _, missing, err = h.sm.reconstructBlock(msg,
+		wire.CmpctBlockWitnessVersion); err != nil || len(missing) != 1 {
+		t.Fatalf("witness version missing %v, error %v", missing, err)
+	}
+}
+
+// TestCmpctBlockMissingTxns ensures the transactions of a compact block that
+// are not in the mempool are requested with a getblocktxn message, and that
+// the block is connected once the blocktxn reply supplies them.
+func TestCmpctBlockMissingTxns(t *testing.T) {
+	h := newCmpctHarness(t)
+	defer h.teardown()
+	inPool, notInPool := h.spend(0), h.spend(1)
+	h.accept(t, inPool)
+	block := h.newBlock(t, inPool, notInPool)
+	hash := block.BlockHash()
+	h.handleCmpctBlock(wire.NewMsgCmpctBlockFromBlock(block, 1,
+		wire.CmpctBlockVersion))
+	select {
+	case msg := <-h.getBlkTxn:
+		if msg.BlockHash != hash || len(msg.Indexes) != 1 ||
+			msg.Indexes[0] != 2 {
+			t.Fatalf("getblocktxn for %v %v, want %v [2]", msg.BlockHash,
+				msg.Indexes, hash)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("missing transactions not requested")
+	}
+	if have, _ := h.chain.HaveBlock(&hash); have {
+		t.Fatal("block connected before its missing transactions arrived")
+	}
+	reply := wire.NewMsgBlockTxn(&hash)
+	if err := reply.AddTransaction(notInPool); err != nil {
+		t.Fatal(err)
+	}
+	h.sm.handleBlockTxnMsg(0, &blockTxnMsg{blockTxn: reply, peer: h.peer})
+	if best := h.chain.BestSnapshot(); best.Hash != hash {
+		t.Fatalf("best block is %v, want the reconstructed block %v",
+			best.Hash, hash)
+	}
+	if h.sm.peerStates[h.peer].partialBlock != nil {
+		t.Fatal("partial block kept after it was completed")
+	}
+}
+
+// TestCmpctBlockWrongBlockTxn ensures a blocktxn reply without the requested
+// number of transactions makes the whole block be requested instead.
+func TestCmpctBlockWrongBlockTxn(t *testing.T) {
+	h := newCmpctHarness(t)
+	defer h.teardown()
+	block := h.newBlock(t, h.spend(0), h.spend(1))
+	hash := block.BlockHash()
+	h.handleCmpctBlock(wire.NewMsgCmpctBlockFromBlock(block, 1,
+		wire.CmpctBlockVersion))
+	<-h.getBlkTxn
+	reply := wire.NewMsgBlockTxn(&hash)
+	if err := reply.AddTransaction(block.Transactions[1]); err != nil {
+		t.Fatal(err)
+	}
+	h.sm.handleBlockTxnMsg(0, &blockTxnMsg{blockTxn: reply, peer: h.peer})
+	expectGetData(t, h, hash)
+}
+
+// TestCmpctBlockShortIDCollision ensures a compact block with two
+// transactions sharing a short ID is not reconstructed, and the whole block is
+// requested instead.
+func TestCmpctBlockShortIDCollision(t *testing.T) {
+	h := newCmpctHarness(t)
+	defer h.teardown()
+	first, second := h.spend(0), h.spend(1)
+	h.accept(t, first)
+	h.accept(t, second)
+	block := h.newBlock(t, first, second)
+	hash := block.BlockHash()
+	msg := wire.NewMsgCmpctBlockFromBlock(block, 1, wire.CmpctBlockVersion)
+	msg.ShortIDs[1] = msg.ShortIDs[0]
+	if _, _, err := h.sm.reconstructBlock(msg,
+		wire.CmpctBlockVersion); err == nil {
+		t.Fatal("compact block with a short ID collision reconstructed")
+	}
+	h.handleCmpctBlock(msg)
+	expectGetData(t, h, hash)
+	if h.sm.peerStates[h.peer].partialBlock != nil {
+		t.Fatal("partial block kept for a block that can't be reconstructed")
+	}
+	if have, _ := h.chain.HaveBlock(&hash); have {
+		t.Fatal("block with a short ID collision connected")
+	}
+}
+
+// expectGetData fails the test unless the remote peer of a harness is asked
+// for a whole block.
+func expectGetData(t *testing.T, h *cmpctHarness, hash chainhash.Hash) {
+	t.Helper()
+	select {
+	case msg := <-h.getData:
+		if len(msg.InvList) != 1 || msg.InvList[0].Hash != hash {
+			t.Fatalf("getdata for %v, want block %v", msg.InvList, hash)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("whole block not requested")
+	}
+}
@@ -17,6 +17,7 @@ import (
 	database "github.com/p9c/pod/pkg/db"
 	peerpkg "github.com/p9c/pod/pkg/peer"
 	"github.com/p9c/pod/pkg/util"
+	"github.com/p9c/pod/pkg/util/interrupt"
 )
 
 type (
@@ -50,6 +51,13 @@ type (
 		nextCheckpoint   *chaincfg.Checkpoint
 		// An optional fee estimator.
 		feeEstimator *mempool.FeeEstimator
+		// The following fields are used to validate the blocks before the utxo
+		// snapshot the chain was started from, once the chain is current.
+		snapshotValidator *blockchain.SnapshotValidator
+		validationBlocks  map[chainhash.Hash]struct{}
+		// The peers asked to send new blocks as cmpctblock messages, from the
+		// one that was asked the longest ago.
+		cmpctHighBWPeers []*peerpkg.Peer
 	}
 	// blockMsg packages a bitcoin block message and the peer it came from
 	// together so the block handler has access to that information.
@@ -108,6 +116,7 @@ type (
 		requestQueue    []*wire.InvVect
 		requestedTxns   map[chainhash.Hash]struct{}
 		requestedBlocks map[chainhash.Hash]struct{}
+		partialBlock    *partialBlock
 	}
 	// processBlockMsg is a message type to be sent across the message channel
 	// for requested a block is processed.  Note this call differs from blockMsg
@@ -146,6 +155,9 @@ const (
 	// maxRequestedTxns is the maximum number of requested transactions hashes
 	// to store in memory.
 	maxRequestedTxns = wire.MaxInvPerMsg
+	// maxValidationBlocks is the maximum number of blocks before the utxo
+	// snapshot that are requested at a time.
+	maxValidationBlocks = 64
 )
 
 // zeroHash is the zero value hash (all zeros)
@@ -292,6 +304,12 @@ out:
 			case *blockMsg:
 				sm.handleBlockMsg(0, msg)
 				msg.reply <- struct{}{}
+			case *cmpctBlockMsg:
+				sm.handleCmpctBlockMsg(0, msg)
+				msg.reply <- struct{}{}
+			case *blockTxnMsg:
+				sm.handleBlockTxnMsg(0, msg)
+				msg.reply <- struct{}{}
 			case *invMsg:
 				sm.handleInvMsg(msg)
 			case *headersMsg:
@@ -458,12 +476,18 @@ func (sm *SyncManager) handleBlockMsg(workerNumber uint32, bmsg *blockMsg) {
 	}
 	// If we didn't ask for this block then the peer is misbehaving.
 	blockHash := bmsg.block.Hash()
+	if _, exists = sm.validationBlocks[*blockHash]; exists {
+		delete(state.requestedBlocks, *blockHash)
+		delete(sm.validationBlocks, *blockHash)
+		sm.handleValidationBlock(bmsg.block)
+		return
+	}
 	if _, exists = state.requestedBlocks[*blockHash]; !exists {
 		// The regression test intentionally sends some blocks twice to test
 		// duplicate block insertion fails.  Don't disconnect the peer or ignore
 		// the block when we're in regression test mode in this case so the chain
 		// code is actually fed the duplicate blocks.
-		if sm.chainParams != &netparams.RegressionTestParams {
+		if sm.chainParams.Name != netparams.RegressionTestParams.Name {
 			Warnc(func() string {
 				return fmt.Sprintf(
 					"got unrequested block %v from %s -- disconnecting",
@@ -595,6 +619,8 @@ func (sm *SyncManager) handleBlockMsg(workerNumber uint32, bmsg *blockMsg) {
 		blkHashUpdate = &best.Hash
 		// Clear the rejected transactions.
 		sm.rejectedTxns = make(map[chainhash.Hash]struct{})
+		sm.fetchValidationBlocks()
+		sm.updateCmpctHighBandwidthPeers(pp)
 	}
 	// Update the block height for this peer. But only send a message to the
 	// server for updating peer heights if this is an orphan or our chain is
@@ -661,6 +687,63 @@ func (sm *SyncManager) handleBlockMsg(workerNumber uint32, bmsg *blockMsg) {
 	}
 }
 
+// fetchValidationBlocks requests the next blocks before the utxo snapshot the
+// chain was started from that the snapshot validator needs from the sync peer,
+// once the chain is current.
+func (sm *SyncManager) fetchValidationBlocks() {
+	if sm.snapshotValidator == nil || sm.syncPeer == nil || !sm.current() ||
+		len(sm.validationBlocks) >= maxValidationBlocks/2 {
+		return
+	}
+	syncPeerState, exists := sm.peerStates[sm.syncPeer]
+	if !exists {
+		return
+	}
+	gdmsg := wire.NewMsgGetData()
+	for _, hash := range sm.snapshotValidator.Wanted(maxValidationBlocks) {
+		if len(sm.validationBlocks) >= maxValidationBlocks {
+			break
+		}
+		if _, ok := sm.validationBlocks[hash]; ok {
+			continue
+		}
+		iv := wire.NewInvVect(wire.InvTypeBlock, &hash)
+		if sm.syncPeer.IsWitnessEnabled() {
+			iv.Type = wire.InvTypeWitnessBlock
+		}
+		if err := gdmsg.AddInvVect(iv); err != nil {
+			Error(err)
+			break
+		}
+		sm.validationBlocks[hash] = struct{}{}
+		syncPeerState.requestedBlocks[hash] = struct{}{}
+	}
+	if len(gdmsg.InvList) > 0 {
+		sm.syncPeer.QueueMessage(gdmsg, nil)
+	}
+}
+
+// handleValidationBlock passes a block before the utxo snapshot the chain was
+// started from to the snapshot validator, and requests more of them. The node
+// is shut down if the snapshot turns out to be invalid.
+func (sm *SyncManager) handleValidationBlock(block *util.Block) {
+	done, err := sm.snapshotValidator.ProcessBlock(block)
+	if done {
+		sm.snapshotValidator = nil
+		if err != nil {
+			Error("the utxo snapshot the chain was started from is invalid, "+
+				"shutting down:", err)
+			interrupt.Request()
+		}
+		return
+	}
+	if err != nil {
+		Warn("failed to validate block", block.Hash(), "before the utxo "+
+			"snapshot:", err)
+	}
+	sm.fetchValidationBlocks()
+}
+
 // handleBlockchainNotification handles notifications from blockchain.  It does
 // things such as request orphan block parents and relay accepted blocks to
 // connected peers.
@@ -712,6 +795,9 @@ func (sm *SyncManager) handleBlockchainNotification(notification *blockchain.Not
 			sm.peerNotifier.AnnounceNewTransactions(acceptedTxs)
 			// log<-cl.Debug{nnounced new transactions}
 		}
+		// The transactions of the block have made room in the pool, so its
+		// minimum fee can decay again.
+		sm.txMemPool.BlockConnected()
 		// Register block with the fee estimator, if it exists.
 		if sm.feeEstimator != nil {
 			// log<-cl.Debug{egistering block with fee estimator}
@@ -768,6 +854,7 @@ func (sm *SyncManager) handleDonePeerMsg(peer *peerpkg.Peer) {
 	}
 	// Remove the peer from the list of candidate peers.
 	delete(sm.peerStates, peer)
+	sm.removeCmpctHighBandwidthPeer(peer)
 	Trace("lost peer ", peer)
 	// Remove requested transactions from the global map so that they will be
 	// fetched from elsewhere next time we get an inv.
@@ -1048,6 +1135,12 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 				if peer.IsWitnessEnabled() {
 					iv.Type = wire.InvTypeWitnessBlock
 				}
+				// Once current, new blocks are mostly made of transactions
+				// already in the mempool, so get them as compact blocks from
+				// peers that support them.
+				if sm.current() && peer.CmpctBlockVersion() != 0 {
+					iv.Type = wire.InvTypeCmpctBlock
+				}
 				err := gdmsg.AddInvVect(iv)
 				if err != nil {
 					Error(err)
@@ -1228,7 +1321,7 @@ func (sm *SyncManager) isSyncCandidate(peer *peerpkg.Peer) bool {
 	// Typically a peer is not a candidate for sync if it's not a full node,
 	// however regression test is special in that the regression tool is not a
 	// full node and still needs to be considered a sync candidate.
-	if sm.chainParams == &netparams.RegressionTestParams {
+	if sm.chainParams.Name == netparams.RegressionTestParams.Name {
 		// The peer is not a candidate if it's not coming from localhost or the
 		// hostname can't be determined for some reason.
 		host, _, err := net.SplitHostPort(peer.Addr())
@@ -1337,6 +1430,7 @@ func (sm *SyncManager) startSync() {
 		// Clear the requestedBlocks if the sync peer changes, otherwise we may
 		// ignore blocks we need that the last sync peer failed to send.
 		sm.requestedBlocks = make(map[chainhash.Hash]struct{})
+		sm.validationBlocks = make(map[chainhash.Hash]struct{})
 		locator, err := sm.chain.LatestBlockLocator()
 		if err != nil {
 			Error(err)
@@ -1370,7 +1464,7 @@ func (sm *SyncManager) startSync() {
 		// test mode.
 		if sm.nextCheckpoint != nil &&
 			best.Height < sm.nextCheckpoint.Height &&
-			sm.chainParams != &netparams.RegressionTestParams {
+			sm.chainParams.Name != netparams.RegressionTestParams.Name {
 			err := bestPeer.PushGetHeadersMsg(locator, sm.nextCheckpoint.Hash)
 			if err != nil {
 				Error(err)
@@ -1389,6 +1483,7 @@ func (sm *SyncManager) startSync() {
 			}
 		}
 		sm.syncPeer = bestPeer
+		sm.fetchValidationBlocks()
 	} else {
 		Trace("no sync peer candidates available")
 	}
@@ -1398,19 +1493,20 @@ func (sm *SyncManager) startSync() {
 // block, tx, and inv updates.
 func New(config *Config) (*SyncManager, error) {
 	sm := SyncManager{
-		peerNotifier:    config.PeerNotifier,
-		chain:           config.Chain,
-		txMemPool:       config.TxMemPool,
-		chainParams:     config.ChainParams,
-		rejectedTxns:    make(map[chainhash.Hash]struct{}),
-		requestedTxns:   make(map[chainhash.Hash]struct{}),
-		requestedBlocks: make(map[chainhash.Hash]struct{}),
-		peerStates:      make(map[*peerpkg.Peer]*peerSyncState),
-		progressLogger:  newBlockProgressLogger("processed"),
-		msgChan:         make(chan interface{}, config.MaxPeers*3),
-		headerList:      list.New(),
-		quit:            make(chan struct{}),
-		feeEstimator:    config.FeeEstimator,
+		peerNotifier:     config.PeerNotifier,
+		chain:            config.Chain,
+		txMemPool:        config.TxMemPool,
+		chainParams:      config.ChainParams,
+		rejectedTxns:     make(map[chainhash.Hash]struct{}),
+		requestedTxns:    make(map[chainhash.Hash]struct{}),
+		requestedBlocks:  make(map[chainhash.Hash]struct{}),
+		peerStates:       make(map[*peerpkg.Peer]*peerSyncState),
+		progressLogger:   newBlockProgressLogger("processed"),
+		msgChan:          make(chan interface{}, config.MaxPeers*3),
+		headerList:       list.New(),
+		quit:             make(chan struct{}),
+		feeEstimator:     config.FeeEstimator,
+		validationBlocks: make(map[chainhash.Hash]struct{}),
 	}
 	best := sm.chain.BestSnapshot()
 	if !config.DisableCheckpoints {
@@ -1422,6 +1518,11 @@ func New(config *Config) (*SyncManager, error) {
 	} else {
 		Info("checkpoints are disabled")
 	}
+	if sm.snapshotValidator = sm.chain.NewSnapshotValidator(); sm.snapshotValidator != nil {
+		Infof("the blocks before the utxo snapshot at height %d will be "+
+			"validated once the chain is current",
+			sm.snapshotValidator.Height())
+	}
 	sm.chain.Subscribe(sm.handleBlockchainNotification)
 	return &sm, nil
 }
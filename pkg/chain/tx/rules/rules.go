@@ -13,6 +13,10 @@ import (
 // DefaultRelayFeePerKb is the default minimum relay fee policy for a mempool.
 const DefaultRelayFeePerKb util.Amount = 1e3
 
+// MaxRBFSequence is the highest input sequence number that signals that a
+// transaction can be replaced by one paying a higher fee, as defined by BIP125.
+const MaxRBFSequence = 0xfffffffd
+
 // Transaction rule violations
 var (
 	ErrAmountNegative   = errors.New("transaction output amount is negative")
@@ -86,3 +90,24 @@ func FeeForSerializeSize(relayFeePerKb util.Amount, txSerializeSize int) util.Am
 	}
 	return fee
 }
+
+// SignalsReplacement returns whether a transaction signals that it can be
+// replaced by one paying a higher fee, by having an input with a sequence
+// number no higher than MaxRBFSequence.
+func SignalsReplacement(tx *wire.MsgTx) bool {
+	for _, txIn := range tx.TxIn {
+		if txIn.Sequence <= MaxRBFSequence {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplacementFee returns the lowest fee a transaction of some size has to pay
+// to replace transactions paying origFee in a mempool with the relay fee
+// policy, which is the fee of those it replaces plus the relay fee for its own
+// size.
+func ReplacementFee(origFee util.Amount, relayFeePerKb util.Amount,
+	txSerializeSize int) util.Amount {
+	return origFee + FeeForSerializeSize(relayFeePerKb, txSerializeSize)
+}
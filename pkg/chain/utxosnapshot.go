@@ -0,0 +1,676 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/chain/wire"
+	database "github.com/p9c/pod/pkg/db"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// A utxo snapshot holds the utxo set of the main chain at a block, along with
+// the headers of the blocks up to it and the block itself, which is all a node
+// needs to start from that block without the blocks before it.
+// The serialized format is:
+//   <magic><version><network><block hash><height><total txns><headers>
+//   <block length><block><utxo count><utxos>
+//   Field          Type               Size
+//   magic          [4]byte            4 bytes
+//   version        uint32             4 bytes
+//   network        wire.BitcoinNet    4 bytes
+//   block hash     chainhash.Hash     chainhash.HashSize
+//   height         uint32             4 bytes
+//   total txns     uint64             8 bytes
+//   headers        []wire.BlockHeader blockHdrSize * (height + 1)
+//   block length   uint32             4 bytes
+//   block          wire.MsgBlock      block length
+//   utxo count     uint64             8 bytes
+//   utxos          []utxo             variable
+// Each utxo is the key and the value of its entry in the utxo set bucket, in
+// the order of the keys, as described above for the utxo set:
+//   <key length><key><value length><value>
+//   Field          Type               Size
+//   key length     VarInt             variable
+//   key            []byte             key length
+//   value length   VarInt             variable
+//   value          []byte             value length
+// The hash of the utxo set is the double sha256 of the utxo count and the
+// utxos as they are serialized.
+// -----------------------------------------------------------------------------
+
+const (
+	// utxoSnapshotVersion is the version of the utxo snapshot format
+	utxoSnapshotVersion = 1
+	// utxoSnapshotBatchSize is the number of database entries that are written
+	// in each transaction while loading a utxo snapshot
+	utxoSnapshotBatchSize = 10000
+)
+
+var (
+	// utxoSnapshotMagic starts every utxo snapshot
+	utxoSnapshotMagic = [4]byte{'u', 't', 'x', 'o'}
+	// utxoSnapshotKeyName is the name of the db key used to store the utxo
+	// snapshot the chain was started from
+	utxoSnapshotKeyName = []byte("utxosnapshot")
+)
+
+// SnapshotStatus is the state of the validation of the blocks before a utxo
+// snapshot
+type SnapshotStatus byte
+
+const (
+	// SnapshotPending is the status of a snapshot whose blocks have not all
+	// been validated yet
+	SnapshotPending SnapshotStatus = iota
+	// SnapshotValid is the status of a snapshot that the blocks before it
+	// have been validated to lead to
+	SnapshotValid
+	// SnapshotInvalid is the status of a snapshot that does not match the
+	// blocks before it
+	SnapshotInvalid
+)
+
+// UtxoSnapshot describes a snapshot of the utxo set at a block of the main
+// chain.
+type UtxoSnapshot struct {
+	Height   int32
+	Hash     chainhash.Hash
+	UtxoHash chainhash.Hash
+	Coins    uint64
+	Status   SnapshotStatus
+}
+
+// The utxo snapshot the chain was started from is stored as:
+//   <height><block hash><utxo hash><coins><status>
+//   Field          Type               Size
+//   height         uint32             4 bytes
+//   block hash     chainhash.Hash     chainhash.HashSize
+//   utxo hash      chainhash.Hash     chainhash.HashSize
+//   coins          uint64             8 bytes
+//   status         byte               1 byte
+// -----------------------------------------------------------------------------
+
+const utxoSnapshotRecordSize = 4 + chainhash.HashSize*2 + 8 + 1
+
+func // dbFetchUtxoSnapshot uses an existing database transaction to retrieve
+// the utxo snapshot the chain was started from, which is nil when it was
+// started from the genesis block.
+dbFetchUtxoSnapshot(dbTx database.Tx) (*UtxoSnapshot, error) {
+	serialized := dbTx.Metadata().Get(utxoSnapshotKeyName)
+	if serialized == nil {
+		return nil, nil
+	}
+	if len(serialized) < utxoSnapshotRecordSize {
+		return nil, database.DBError{
+			ErrorCode:   database.ErrCorruption,
+			Description: "corrupt utxo snapshot record",
+		}
+	}
+	s := &UtxoSnapshot{Height: int32(byteOrder.Uint32(serialized))}
+	offset := 4
+	copy(s.Hash[:], serialized[offset:])
+	offset += chainhash.HashSize
+	copy(s.UtxoHash[:], serialized[offset:])
+	offset += chainhash.HashSize
+	s.Coins = byteOrder.Uint64(serialized[offset:])
+	offset += 8
+	s.Status = SnapshotStatus(serialized[offset])
+	return s, nil
+}
+
+func // dbPutUtxoSnapshot uses an existing database transaction to store the
+// utxo snapshot the chain was started from.
+dbPutUtxoSnapshot(dbTx database.Tx, s *UtxoSnapshot) error {
+	serialized := make([]byte, utxoSnapshotRecordSize)
+	byteOrder.PutUint32(serialized, uint32(s.Height))
+	offset := 4
+	copy(serialized[offset:], s.Hash[:])
+	offset += chainhash.HashSize
+	copy(serialized[offset:], s.UtxoHash[:])
+	offset += chainhash.HashSize
+	byteOrder.PutUint64(serialized[offset:], s.Coins)
+	offset += 8
+	serialized[offset] = byte(s.Status)
+	return dbTx.Metadata().Put(utxoSnapshotKeyName, serialized)
+}
+
+// FetchUtxoSnapshot returns the utxo snapshot the chain in the database was
+// started from, or nil if it was started from the genesis block.
+func FetchUtxoSnapshot(db database.DB) (s *UtxoSnapshot, err error) {
+	err = db.View(func(dbTx database.Tx) error {
+		s, err = dbFetchUtxoSnapshot(dbTx)
+		return err
+	})
+	return
+}
+
+// utxoHasher computes the hash of a utxo set from the utxos serialized as they
+// are in a snapshot
+type utxoHasher struct {
+	hash.Hash
+}
+
+func newUtxoHasher(count uint64) utxoHasher {
+	h := utxoHasher{sha256.New()}
+	var serialized [8]byte
+	byteOrder.PutUint64(serialized[:], count)
+	_, _ = h.Write(serialized[:])
+	return h
+}
+
+// utxoHash returns the hash of the utxos that have been written
+func (h utxoHasher) utxoHash() chainhash.Hash {
+	return sha256.Sum256(h.Hash.Sum(nil))
+}
+
+// writeUtxo writes the key and serialized entry of a utxo in the snapshot
+// format
+func writeUtxo(w io.Writer, key, serialized []byte) error {
+	if err := wire.WriteVarBytes(w, 0, key); err != nil {
+		return err
+	}
+	return wire.WriteVarBytes(w, 0, serialized)
+}
+
+// overlayUtxo is an entry of a view that replaces the one in the utxo set
+// while the utxo set is written to a snapshot
+type overlayUtxo struct {
+	key   []byte
+	entry *UtxoEntry
+}
+
+// DumpUtxoSnapshot writes a snapshot of the utxo set at the given height of
+// the main chain, or at the tip of the chain if the height is negative, and
+// returns its description. The blocks after the height are disconnected from
+// a view using the spend journal, so they must not have been pruned.
+// This function is safe for concurrent access.
+func (b *BlockChain) DumpUtxoSnapshot(w io.Writer, height int32) (*UtxoSnapshot, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+	tip := b.BestChain.Tip()
+	if height < 0 {
+		height = tip.height
+	}
+	if height < 1 || height > tip.height {
+		return nil, fmt.Errorf("a utxo snapshot can be made at heights 1 "+
+			"to %d of the chain, not at %d", tip.height, height)
+	}
+	if height < b.pruneHeight {
+		return nil, fmt.Errorf("the block at height %d has been pruned, "+
+			"blocks are stored from height %d", height, b.pruneHeight)
+	}
+	node := b.BestChain.NodeByHeight(height)
+	s := &UtxoSnapshot{Height: height, Hash: node.hash}
+	totalTxns := b.BestSnapshot().TotalTxns
+	err := b.db.View(func(dbTx database.Tx) error {
+		// Roll the utxo set back to the height in a view that takes
+		// precedence over the utxo set in the database.
+		view := NewUtxoViewpoint()
+		for n := tip; n != node; n = n.parent {
+			block, err := dbFetchBlockByNode(dbTx, n)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			stxos, err := dbFetchSpendJournalEntry(dbTx, block)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			err = view.disconnectTransactions(b.db, block, stxos)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			totalTxns -= uint64(len(block.Transactions()))
+		}
+		overlay := make([]overlayUtxo, 0, len(view.entries))
+		for outpoint, entry := range view.entries {
+			key := outpointKey(outpoint)
+			overlay = append(overlay, overlayUtxo{
+				key:   append([]byte(nil), *key...),
+				entry: entry,
+			})
+			recycleOutpointKey(key)
+		}
+		sort.Slice(overlay, func(i, j int) bool {
+			return bytes.Compare(overlay[i].key, overlay[j].key) < 0
+		})
+		// forEachUtxo calls fn with the utxos of the rolled back utxo set in
+		// the order of their keys.
+		forEachUtxo := func(fn func(key, serialized []byte) error) error {
+			i := 0
+			emitOverlay := func() error {
+				o := overlay[i]
+				i++
+				if o.entry == nil || o.entry.IsSpent() {
+					return nil
+				}
+				serialized, err := serializeUtxoEntry(o.entry)
+				if err != nil {
+					return err
+				}
+				return fn(o.key, serialized)
+			}
+			cursor := dbTx.Metadata().Bucket(utxoSetBucketName).Cursor()
+			for ok := cursor.First(); ok; ok = cursor.Next() {
+				key := cursor.Key()
+				for i < len(overlay) && bytes.Compare(overlay[i].key, key) < 0 {
+					if err := emitOverlay(); err != nil {
+						return err
+					}
+				}
+				if i < len(overlay) && bytes.Equal(overlay[i].key, key) {
+					if err := emitOverlay(); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := fn(key, cursor.Value()); err != nil {
+					return err
+				}
+			}
+			for i < len(overlay) {
+				if err := emitOverlay(); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		err := forEachUtxo(func(key, serialized []byte) error {
+			s.Coins++
+			return nil
+		})
+		if err != nil {
+			Error(err)
+			return err
+		}
+		blockBytes, err := dbTx.FetchBlock(&node.hash)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		// Write the description of the snapshot, the headers and the block.
+		var header [4 + 4 + 4 + chainhash.HashSize + 4 + 8]byte
+		copy(header[:], utxoSnapshotMagic[:])
+		byteOrder.PutUint32(header[4:], utxoSnapshotVersion)
+		byteOrder.PutUint32(header[8:], uint32(b.params.Net))
+		copy(header[12:], node.hash[:])
+		byteOrder.PutUint32(header[12+chainhash.HashSize:], uint32(height))
+		byteOrder.PutUint64(header[16+chainhash.HashSize:], totalTxns)
+		if _, err = w.Write(header[:]); err != nil {
+			return err
+		}
+		for h := int32(0); h <= height; h++ {
+			blockHeader := b.BestChain.NodeByHeight(h).Header()
+			if err = blockHeader.Serialize(w); err != nil {
+				return err
+			}
+		}
+		if err = binary.Write(w, byteOrder, uint32(len(blockBytes))); err != nil {
+			return err
+		}
+		if _, err = w.Write(blockBytes); err != nil {
+			return err
+		}
+		// Write the utxos and hash them as they are written.
+		if err = binary.Write(w, byteOrder, s.Coins); err != nil {
+			return err
+		}
+		hasher := newUtxoHasher(s.Coins)
+		mw := io.MultiWriter(w, hasher)
+		err = forEachUtxo(func(key, serialized []byte) error {
+			return writeUtxo(mw, key, serialized)
+		})
+		if err != nil {
+			return err
+		}
+		s.UtxoHash = hasher.utxoHash()
+		return nil
+	})
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	Infof("wrote utxo snapshot of %d coins at height %d (%v), utxo hash %v",
+		s.Coins, s.Height, s.Hash, s.UtxoHash)
+	return s, nil
+}
+
+// LoadUtxoSnapshot initializes an empty database with the chain state of a
+// utxo snapshot, so the chain starts from the block of the snapshot. The hash
+// of the utxo set must match the given one or, when it is nil, the one of the
+// snapshot at the same height in the network parameters. A given hash that is
+// not the one in the network parameters for the same height is refused. The headers before
+// the block are checked the same as the headers of new blocks and to link up
+// to it, and the blocks themselves are not stored, so the blocks before the
+// snapshot are treated as pruned.
+func LoadUtxoSnapshot(db database.DB, r io.Reader, params *netparams.Params,
+	utxoHash *chainhash.Hash) (*UtxoSnapshot, error) {
+	// The snapshot can only be loaded into a database without a chain.
+	err := db.View(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		if meta.Get(chainStateKeyName) != nil ||
+			meta.Bucket(utxoSetBucketName) != nil {
+			return fmt.Errorf("the database already has a chain, " +
+				"a utxo snapshot can only be loaded into an empty one")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var header [4 + 4 + 4 + chainhash.HashSize + 4 + 8]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:4], utxoSnapshotMagic[:]) {
+		return nil, fmt.Errorf("not a utxo snapshot")
+	}
+	if version := byteOrder.Uint32(header[4:]); version != utxoSnapshotVersion {
+		return nil, fmt.Errorf("unsupported utxo snapshot version %d", version)
+	}
+	if net := wire.BitcoinNet(byteOrder.Uint32(header[8:])); net != params.Net {
+		return nil, fmt.Errorf("the utxo snapshot is for network %v, "+
+			"not %v", net, params.Net)
+	}
+	s := &UtxoSnapshot{Status: SnapshotPending}
+	copy(s.Hash[:], header[12:])
+	s.Height = int32(byteOrder.Uint32(header[12+chainhash.HashSize:]))
+	totalTxns := byteOrder.Uint64(header[16+chainhash.HashSize:])
+	if s.Height < 1 {
+		return nil, fmt.Errorf("the utxo snapshot is of the genesis block")
+	}
+	// Find the hash the utxo set is trusted with before reading it.
+	for _, known := range params.UtxoSnapshots {
+		if known.Height != s.Height {
+			continue
+		}
+		if !known.BlockHash.IsEqual(&s.Hash) {
+			return nil, fmt.Errorf("the utxo snapshot block %v at height %d "+
+				"is not the known block %v", s.Hash, s.Height, known.BlockHash)
+		}
+		switch {
+		case utxoHash == nil:
+			utxoHash = known.UtxoHash
+		case !utxoHash.IsEqual(known.UtxoHash):
+			// A hash given for a height that has a known one is not allowed
+			// to override it.
+			return nil, fmt.Errorf("the utxo hash %v is not the known utxo "+
+				"hash %v of the snapshot at height %d", utxoHash,
+				known.UtxoHash, s.Height)
+		}
+	}
+	if utxoHash == nil {
+		return nil, fmt.Errorf("there is no known utxo snapshot at height %d, "+
+			"the hash of its utxo set has to be given", s.Height)
+	}
+	// Read the headers and check that they link from the genesis block to the
+	// block of the snapshot, and that each has the proof of work, difficulty
+	// and timestamp required after the ones before it, so a snapshot can't
+	// bring in headers the chain would not have accepted.
+	hc := newHeaderChain(params)
+	checkpoints := make(map[int32]*chainhash.Hash)
+	for _, checkpoint := range params.Checkpoints {
+		checkpoints[checkpoint.Height] = checkpoint.Hash
+	}
+	// The nodes are added as their headers are read, as the height is not
+	// trusted to size them up front before the headers are there.
+	var nodes []*BlockNode
+	var parent *BlockNode
+	for height := int32(0); height <= s.Height; height++ {
+		var blockHeader wire.BlockHeader
+		if err = blockHeader.Deserialize(r); err != nil {
+			return nil, err
+		}
+		node := NewBlockNode(&blockHeader, parent)
+		node.status = statusValid
+		switch {
+		case parent == nil && !node.hash.IsEqual(params.GenesisHash):
+			return nil, fmt.Errorf("the utxo snapshot headers start with "+
+				"%v, not the genesis block", node.hash)
+		case parent != nil && blockHeader.PrevBlock != parent.hash:
+			return nil, fmt.Errorf("the utxo snapshot header at height %d "+
+				"does not link to the one before it", node.height)
+		case checkpoints[node.height] != nil &&
+			!checkpoints[node.height].IsEqual(&node.hash):
+			return nil, fmt.Errorf("the utxo snapshot header at height %d "+
+				"does not match the checkpoint", node.height)
+		}
+		if parent != nil {
+			if err = hc.checkSnapshotHeader(&blockHeader, parent); err != nil {
+				return nil, fmt.Errorf("the utxo snapshot header at height "+
+					"%d is invalid: %v", node.height, err)
+			}
+		}
+		nodes = append(nodes, node)
+		parent = node
+	}
+	tip := nodes[s.Height]
+	if tip.hash != s.Hash {
+		return nil, fmt.Errorf("the utxo snapshot headers end with %v, "+
+			"not the block %v", tip.hash, s.Hash)
+	}
+	tip.status |= statusDataStored
+	var blockLen uint32
+	if err = binary.Read(r, byteOrder, &blockLen); err != nil {
+		return nil, err
+	}
+	if blockLen > wire.MaxBlockPayload {
+		return nil, fmt.Errorf("the utxo snapshot block is too big")
+	}
+	blockBytes := make([]byte, blockLen)
+	if _, err = io.ReadFull(r, blockBytes); err != nil {
+		return nil, err
+	}
+	block, err := util.NewBlockFromBytes(blockBytes)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	if !block.Hash().IsEqual(&s.Hash) {
+		return nil, fmt.Errorf("the utxo snapshot block is not %v", s.Hash)
+	}
+	block.SetHeight(s.Height)
+	// Read the utxos into the utxo set bucket in batches, hashing them as they
+	// are read. Any failure from here on removes what has been written.
+	err = db.Update(func(dbTx database.Tx) error {
+		_, err := dbTx.Metadata().CreateBucket(utxoSetBucketName)
+		return err
+	})
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	if err = loadUtxoSnapshotState(db, r, s, nodes, block, totalTxns,
+		utxoHash); err != nil {
+		removeUtxoSnapshotState(db)
+		return nil, err
+	}
+	Infof("loaded utxo snapshot of %d coins at height %d (%v)",
+		s.Coins, s.Height, s.Hash)
+	return s, nil
+}
+
+// newHeaderChain returns a chain without a database that is only used to work
+// out the difficulty required of the headers before a utxo snapshot, which
+// only needs the headers before them, so checking them does not change the
+// difficulty cached for the tip of the chain.
+func newHeaderChain(params *netparams.Params) *BlockChain {
+	b := &BlockChain{
+		params:                params,
+		timeSource:            NewMedianTime(),
+		DifficultyAdjustments: make(map[string]float64),
+	}
+	b.DifficultyBits.Store(make(TargetBits))
+	return b
+}
+
+// checkSnapshotHeader checks the proof of work of a header before a utxo
+// snapshot, and that its difficulty and timestamp are the ones required after
+// the header before it, as checkBlockHeaderSanity and checkBlockHeaderContext
+// do for the headers of new blocks.
+func (b *BlockChain) checkSnapshotHeader(header *wire.BlockHeader,
+	prevNode *BlockNode) error {
+	height := prevNode.height + 1
+	algo := b.params.Forks.AlgoName(header.Version, height)
+	powLimit := b.params.Forks.MinDiff(algo, height)
+	err := checkBlockHeaderSanity(header, powLimit, b.params.Forks,
+		b.timeSource, BFNone, height)
+	if err != nil {
+		return err
+	}
+	return b.checkBlockHeaderDifficulty(0, header, prevNode)
+}
+
+// loadUtxoSnapshotState reads the utxos of a snapshot into the utxo set bucket,
+// checks their hash and, when it matches, writes the rest of the chain state.
+func loadUtxoSnapshotState(db database.DB, r io.Reader, s *UtxoSnapshot,
+	nodes []*BlockNode, block *util.Block, totalTxns uint64,
+	utxoHash *chainhash.Hash) (err error) {
+	if err = binary.Read(r, byteOrder, &s.Coins); err != nil {
+		return err
+	}
+	hasher := newUtxoHasher(s.Coins)
+	for loaded := uint64(0); loaded < s.Coins; {
+		err = db.Update(func(dbTx database.Tx) error {
+			utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+			for i := 0; i < utxoSnapshotBatchSize && loaded < s.Coins; i++ {
+				key, err := wire.ReadVarBytes(r, 0, uint32(chainhash.HashSize+
+					maxUint32VLQSerializeSize), "utxo key")
+				if err != nil {
+					return err
+				}
+				serialized, err := wire.ReadVarBytes(r, 0, wire.MaxBlockPayload,
+					"utxo entry")
+				if err != nil {
+					return err
+				}
+				if len(key) <= chainhash.HashSize {
+					return fmt.Errorf("utxo key %x is too short", key)
+				}
+				if _, err = deserializeUtxoEntry(serialized); err != nil {
+					return err
+				}
+				if err = writeUtxo(hasher, key, serialized); err != nil {
+					return err
+				}
+				if err = utxoBucket.Put(key, serialized); err != nil {
+					return err
+				}
+				loaded++
+			}
+			return nil
+		})
+		if err != nil {
+			Error(err)
+			return err
+		}
+		Debugf("loaded %d of %d utxos", loaded, s.Coins)
+	}
+	if s.UtxoHash = hasher.utxoHash(); !s.UtxoHash.IsEqual(utxoHash) {
+		return fmt.Errorf("the hash of the utxo set in the snapshot is %v, "+
+			"not %v", s.UtxoHash, utxoHash)
+	}
+	// Store the block index and the indexes of the main chain in batches, and
+	// the state of the chain at the block with the last batch.
+	err = db.Update(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		for _, bucket := range [][]byte{blockIndexBucketName,
+			hashIndexBucketName, heightIndexBucketName,
+			spendJournalBucketName} {
+			if _, err := meta.CreateBucket(bucket); err != nil {
+				return err
+			}
+		}
+		err := dbPutVersion(dbTx, utxoSetVersionKeyName,
+			latestUtxoSetBucketVersion)
+		if err != nil {
+			return err
+		}
+		return dbPutVersion(dbTx, spendJournalVersionKeyName,
+			latestSpendJournalBucketVersion)
+	})
+	if err != nil {
+		Error(err)
+		return err
+	}
+	for start := 0; start < len(nodes); start += utxoSnapshotBatchSize {
+		end := start + utxoSnapshotBatchSize
+		if end > len(nodes) {
+			end = len(nodes)
+		}
+		err = db.Update(func(dbTx database.Tx) error {
+			for i := start; i < end; i++ {
+				node := nodes[i]
+				if err := dbStoreBlockNode(dbTx, node); err != nil {
+					return err
+				}
+				if err := dbPutBlockIndex(dbTx, &node.hash,
+					node.height); err != nil {
+					return err
+				}
+			}
+			if end < len(nodes) {
+				return nil
+			}
+			tip := nodes[s.Height]
+			numTxns := uint64(len(block.MsgBlock().Transactions))
+			state := newBestState(tip,
+				uint64(block.MsgBlock().SerializeSize()),
+				uint64(GetBlockWeight(block)), numTxns, totalTxns,
+				tip.CalcPastMedianTime())
+			if err := dbPutBestState(dbTx, state, tip.workSum); err != nil {
+				return err
+			}
+			if err := dbPutPruneHeight(dbTx, s.Height); err != nil {
+				return err
+			}
+			if err := dbPutUtxoSnapshot(dbTx, s); err != nil {
+				return err
+			}
+			return dbStoreBlock(dbTx, block)
+		})
+		if err != nil {
+			Error(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// removeUtxoSnapshotState removes what has been written of the chain state of
+// a utxo snapshot that could not be loaded.
+func removeUtxoSnapshotState(db database.DB) {
+	err := db.Update(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		for _, bucket := range [][]byte{utxoSetBucketName,
+			blockIndexBucketName, hashIndexBucketName, heightIndexBucketName,
+			spendJournalBucketName} {
+			if meta.Bucket(bucket) == nil {
+				continue
+			}
+			if err := meta.DeleteBucket(bucket); err != nil {
+				return err
+			}
+		}
+		for _, key := range [][]byte{utxoSetVersionKeyName,
+			spendJournalVersionKeyName} {
+			if err := meta.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		Error("failed to remove the partly loaded utxo snapshot:", err)
+	}
+}
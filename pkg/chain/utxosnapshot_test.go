@@ -0,0 +1,501 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	chaincfg "github.com/p9c/pod/pkg/chain/config"
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/chain/wire"
+	database "github.com/p9c/pod/pkg/db"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// utxoSnapshotHeaderSize is the size of the description at the start of a
+// utxo snapshot
+const utxoSnapshotHeaderSize = 4 + 4 + 4 + chainhash.HashSize + 4 + 8
+
+// snapshotTestParams returns a copy of the regression test network
+// parameters with the hash of its genesis block, which the chain state of a
+// database has to start with when a chain is opened from it.
+func snapshotTestParams() *netparams.Params {
+	regtest := &netparams.RegressionTestParams
+	chainParams := *regtest.Params
+	genesisHash := chainParams.GenesisBlock.BlockHash()
+	chainParams.GenesisHash = &genesisHash
+	return &netparams.Params{
+		Params:              &chainParams,
+		RPCClientPort:       regtest.RPCClientPort,
+		WalletRPCServerPort: regtest.WalletRPCServerPort,
+		Forks:               regtest.Forks,
+	}
+}
+
+// snapshotChain returns a chain of regression test blocks and a utxo snapshot
+// of it a few blocks below its tip.
+func snapshotChain(t *testing.T, dbName string, blocks int, height int32) (
+	*BlockChain, []byte, *UtxoSnapshot, func()) {
+	chain, teardown, err := chainSetup(dbName, snapshotTestParams())
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	if _, err = addTestBlocks(chain, chain.BestChain.Tip(), blocks,
+		0); err != nil {
+		teardown()
+		t.Fatalf("Failed to add test blocks: %v", err)
+	}
+	var buf bytes.Buffer
+	s, err := chain.DumpUtxoSnapshot(&buf, height)
+	if err != nil {
+		teardown()
+		t.Fatalf("DumpUtxoSnapshot: %v", err)
+	}
+	return chain, buf.Bytes(), s, teardown
+}
+
+// loadSnapshotChain loads a utxo snapshot into a new db and returns the chain
+// started from it.
+func loadSnapshotChain(t *testing.T, dbName string, snapshot []byte,
+	utxoHash *chainhash.Hash) (*BlockChain, *UtxoSnapshot, func()) {
+	db, teardown, err := dbSetup(dbName)
+	if err != nil {
+		t.Fatalf("Failed to setup db: %v", err)
+	}
+	params := snapshotTestParams()
+	s, err := LoadUtxoSnapshot(db, bytes.NewReader(snapshot), params, utxoHash)
+	if err != nil {
+		teardown()
+		t.Fatalf("LoadUtxoSnapshot: %v", err)
+	}
+	chain, err := New(&Config{
+		DB:          db,
+		ChainParams: params,
+		TimeSource:  NewMedianTime(),
+		SigCache:    txscript.NewSigCache(1000),
+	})
+	if err != nil {
+		teardown()
+		t.Fatalf("Failed to create chain from snapshot: %v", err)
+	}
+	return chain, s, teardown
+}
+
+// assertEmptyDB fails the test if a db has any of the chain state of a utxo
+// snapshot, as it must not after a snapshot failed to load.
+func assertEmptyDB(t *testing.T, name string, db database.DB) {
+	err := db.View(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		if meta.Get(chainStateKeyName) != nil {
+			t.Errorf("%s: chain state left in the db", name)
+		}
+		for _, bucket := range [][]byte{utxoSetBucketName,
+			blockIndexBucketName, hashIndexBucketName,
+			heightIndexBucketName, spendJournalBucketName} {
+			if meta.Bucket(bucket) != nil {
+				t.Errorf("%s: bucket %s left in the db", name, bucket)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// forgeSnapshot returns a copy of a utxo snapshot with the header at a height
+// changed by forge. The headers after it are linked to it again and solved,
+// and the block and hash of the snapshot are changed to match the new tip, so
+// the snapshot only differs in the forged header. forge is responsible for
+// the proof of work of the forged header itself.
+func forgeSnapshot(t *testing.T, snapshot []byte, height int32,
+	forge func(header *wire.BlockHeader)) []byte {
+	forks := netparams.RegressionTestParams.Forks
+	forged := append([]byte(nil), snapshot...)
+	tip := int32(binary.LittleEndian.Uint32(
+		forged[12+chainhash.HashSize:]))
+	headers := forged[utxoSnapshotHeaderSize:]
+	var prev chainhash.Hash
+	var header wire.BlockHeader
+	for h := int32(0); h <= tip; h++ {
+		serialized := headers[h*blockHdrSize : (h+1)*blockHdrSize]
+		if err := header.Deserialize(bytes.NewReader(serialized)); err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case h == height:
+			header.PrevBlock = prev
+			forge(&header)
+		case h > height:
+			header.PrevBlock = prev
+			solveTestHeader(&header, forks, h)
+		}
+		var buf bytes.Buffer
+		if err := header.Serialize(&buf); err != nil {
+			t.Fatal(err)
+		}
+		copy(serialized, buf.Bytes())
+		prev = header.BlockHash()
+	}
+	copy(forged[12:], prev[:])
+	// The block is the one of the tip, with the header of the new tip.
+	offset := utxoSnapshotHeaderSize + int(tip+1)*blockHdrSize
+	blockLen := int(binary.LittleEndian.Uint32(forged[offset:]))
+	var buf bytes.Buffer
+	if err := header.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	copy(forged[offset+4:offset+4+blockLen], buf.Bytes())
+	return forged
+}
+
+// TestUtxoSnapshotRoundTrip ensures a chain started from a utxo snapshot has
+// the tip and utxo set of the chain the snapshot was made of, and that the
+// blocks before it are validated to lead to its utxo set.
+func TestUtxoSnapshotRoundTrip(t *testing.T) {
+	chain, snapshot, dumped, teardown := snapshotChain(t, "snapshotdump",
+		12, 9)
+	defer teardown()
+	loaded, s, teardown2 := loadSnapshotChain(t, "snapshotload", snapshot,
+		&dumped.UtxoHash)
+	defer teardown2()
+	if s.Height != dumped.Height || s.Hash != dumped.Hash ||
+		s.UtxoHash != dumped.UtxoHash || s.Coins != dumped.Coins {
+		t.Fatalf("loaded snapshot %+v, want %+v", s, dumped)
+	}
+	if s.Coins != 9 {
+		t.Errorf("got %d coins, want 9", s.Coins)
+	}
+	if s.Status != SnapshotPending {
+		t.Errorf("got status %v, want %v", s.Status, SnapshotPending)
+	}
+	best := loaded.BestSnapshot()
+	if best.Height != dumped.Height || best.Hash != dumped.Hash {
+		t.Errorf("got tip %v at %d, want %v at %d", best.Hash, best.Height,
+			dumped.Hash, dumped.Height)
+	}
+	// Every header up to the snapshot is in the main chain of the loaded
+	// chain, and so are the coinbase outputs of the blocks.
+	for height := int32(0); height <= dumped.Height; height++ {
+		want := chain.BestChain.NodeByHeight(height)
+		got := loaded.BestChain.NodeByHeight(height)
+		if got == nil || got.hash != want.hash {
+			t.Fatalf("header at height %d is not %v", height, want.hash)
+		}
+		if height == 0 {
+			continue
+		}
+		block, err := chain.BlockByHeight(height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outpoint := wire.OutPoint{Hash: *block.Transactions()[0].Hash()}
+		entry, err := loaded.FetchUtxoEntry(outpoint)
+		if err != nil || entry == nil || entry.IsSpent() {
+			t.Errorf("coinbase of block %d is not in the utxo set: %v",
+				height, err)
+		}
+	}
+	// The blocks after the snapshot connect to the loaded chain.
+	for height := dumped.Height + 1; height <= chain.BestChain.Tip().height; height++ {
+		block, err := chain.BlockByHeight(height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		isMain, _, err := loaded.ProcessBlock(0, block, BFNone, height)
+		if err != nil || !isMain {
+			t.Fatalf("block %d after the snapshot: main %v, %v", height,
+				isMain, err)
+		}
+	}
+	// The blocks before the snapshot lead to its utxo set.
+	v := loaded.NewSnapshotValidator()
+	if v == nil {
+		t.Fatal("no snapshot validator for a pending snapshot")
+	}
+	done, err := validateSnapshotBlocks(chain, v)
+	if !done || err != nil {
+		t.Fatalf("snapshot validation: done %v, %v", done, err)
+	}
+	if s, err = FetchUtxoSnapshot(loaded.db); err != nil ||
+		s.Status != SnapshotValid {
+		t.Errorf("got status %v, %v, want %v", s.Status, err, SnapshotValid)
+	}
+	err = loaded.db.View(func(dbTx database.Tx) error {
+		if dbTx.Metadata().Bucket(snapshotValidatorBucketName) != nil {
+			t.Errorf("utxo set of the snapshot validator left in the db")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.NewSnapshotValidator() != nil {
+		t.Errorf("snapshot validator for a validated snapshot")
+	}
+}
+
+// validateSnapshotBlocks passes the blocks before a snapshot to a snapshot
+// validator, in reverse order so most have to wait for the ones before them.
+func validateSnapshotBlocks(chain *BlockChain, v *SnapshotValidator) (
+	done bool, err error) {
+	for height := v.Height(); height > 0 && !done; height-- {
+		var block *util.Block
+		if block, err = chain.BlockByHeight(height); err != nil {
+			return
+		}
+		if done, err = v.ProcessBlock(block); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// TestUtxoSnapshotHashMismatch ensures a snapshot whose utxo set does not have
+// the trusted hash is not loaded, and leaves the db as it was.
+func TestUtxoSnapshotHashMismatch(t *testing.T) {
+	_, snapshot, dumped, teardown := snapshotChain(t, "snapshotmismatch", 6,
+		-1)
+	defer teardown()
+	db, teardown2, err := dbSetup("snapshotmismatchload")
+	if err != nil {
+		t.Fatalf("Failed to setup db: %v", err)
+	}
+	defer teardown2()
+	params := snapshotTestParams()
+	wrongHash := dumped.UtxoHash
+	wrongHash[0] ^= 1
+	tests := []struct {
+		name     string
+		snapshot []byte
+		utxoHash *chainhash.Hash
+		want     string
+	}{
+		{
+			name:     "wrong trusted hash",
+			snapshot: snapshot,
+			utxoHash: &wrongHash,
+			want:     "hash of the utxo set",
+		},
+		{
+			name:     "no trusted hash",
+			snapshot: snapshot,
+			want:     "no known utxo snapshot",
+		},
+		{
+			name: "changed utxo",
+			snapshot: func() []byte {
+				changed := append([]byte(nil), snapshot...)
+				// the last byte is part of the value of the last utxo
+				changed[len(changed)-1] ^= 1
+				return changed
+			}(),
+			utxoHash: &dumped.UtxoHash,
+			want:     "",
+		},
+		{
+			name: "changed block hash",
+			snapshot: func() []byte {
+				changed := append([]byte(nil), snapshot...)
+				changed[12] ^= 1
+				return changed
+			}(),
+			utxoHash: &dumped.UtxoHash,
+			want:     "headers end with",
+		},
+		{
+			name: "height beyond the headers",
+			snapshot: func() []byte {
+				changed := append([]byte(nil), snapshot...)
+				byteOrder.PutUint32(changed[12+chainhash.HashSize:],
+					math.MaxInt32)
+				return changed
+			}(),
+			utxoHash: &dumped.UtxoHash,
+			want:     "header at height 7",
+		},
+	}
+	for _, test := range tests {
+		_, err := LoadUtxoSnapshot(db, bytes.NewReader(test.snapshot), params,
+			test.utxoHash)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %v, want one containing %q", test.name,
+				err, test.want)
+		}
+		assertEmptyDB(t, test.name, db)
+	}
+	// A given hash can't override the known one of a snapshot at the same
+	// height, even when it is the hash of the utxo set in the snapshot.
+	known := *params.Params
+	known.UtxoSnapshots = []chaincfg.UtxoSnapshot{{
+		Height:    dumped.Height,
+		BlockHash: &dumped.Hash,
+		UtxoHash:  &wrongHash,
+	}}
+	knownParams := &netparams.Params{
+		Params:              &known,
+		RPCClientPort:       params.RPCClientPort,
+		WalletRPCServerPort: params.WalletRPCServerPort,
+		Forks:               params.Forks,
+	}
+	_, err = LoadUtxoSnapshot(db, bytes.NewReader(snapshot), knownParams,
+		&dumped.UtxoHash)
+	if err == nil || !strings.Contains(err.Error(), "not the known utxo hash") {
+		t.Errorf("hash other than the known one: got error %v, want one "+
+			"containing %q", err, "not the known utxo hash")
+	}
+	assertEmptyDB(t, "hash other than the known one", db)
+	// The db can still take the snapshot after the failures.
+	if _, err = LoadUtxoSnapshot(db, bytes.NewReader(snapshot), params,
+		&dumped.UtxoHash); err != nil {
+		t.Fatalf("LoadUtxoSnapshot after failures: %v", err)
+	}
+}
+
+// TestUtxoSnapshotForgedHeader ensures a snapshot is not loaded when a header
+// before it does not have the proof of work, difficulty or timestamp that the
+// chain requires, even when the headers link up to the snapshot block.
+func TestUtxoSnapshotForgedHeader(t *testing.T) {
+	_, snapshot, dumped, teardown := snapshotChain(t, "snapshotforged", 8, -1)
+	defer teardown()
+	db, teardown2, err := dbSetup("snapshotforgedload")
+	if err != nil {
+		t.Fatalf("Failed to setup db: %v", err)
+	}
+	defer teardown2()
+	params := snapshotTestParams()
+	forks := params.Forks
+	tests := []struct {
+		name   string
+		height int32
+		forge  func(header *wire.BlockHeader)
+		want   string
+	}{
+		{
+			name:   "proof of work not met",
+			height: 3,
+			forge: func(header *wire.BlockHeader) {
+				powLimit := forks.MinDiff(
+					forks.AlgoName(header.Version, 3), 3)
+				for checkProofOfWork(header, powLimit, forks, BFNone,
+					3) == nil {
+					header.Nonce++
+				}
+			},
+			want: "header at height 3 is invalid",
+		},
+		{
+			name:   "unexpected difficulty",
+			height: 4,
+			forge: func(header *wire.BlockHeader) {
+				header.Bits--
+				solveTestHeader(header, forks, 4)
+			},
+			want: "header at height 4 is invalid",
+		},
+		{
+			name:   "timestamp before the median time",
+			height: 5,
+			forge: func(header *wire.BlockHeader) {
+				header.Timestamp = params.GenesisBlock.Header.Timestamp
+				solveTestHeader(header, forks, 5)
+			},
+			want: "header at height 5 is invalid",
+		},
+		{
+			// The headers are solved again after an unchanged one, so the
+			// snapshot loads.
+			name:   "unchanged",
+			height: 3,
+			forge: func(header *wire.BlockHeader) {
+				solveTestHeader(header, forks, 3)
+			},
+		},
+	}
+	for _, test := range tests {
+		// Only the headers are forged, so the utxo set keeps its hash.
+		forged := forgeSnapshot(t, snapshot, test.height, test.forge)
+		_, err := LoadUtxoSnapshot(db, bytes.NewReader(forged), params,
+			&dumped.UtxoHash)
+		if test.want == "" {
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %v, want one containing %q", test.name,
+				err, test.want)
+		}
+		assertEmptyDB(t, test.name, db)
+	}
+}
+
+// TestUtxoSnapshotValidatorFailure ensures a snapshot whose utxo set is not
+// the one the blocks before it lead to is marked invalid by the snapshot
+// validator.
+func TestUtxoSnapshotValidatorFailure(t *testing.T) {
+	chain, snapshot, dumped, teardown := snapshotChain(t,
+		"snapshotvalidatorfail", 6, -1)
+	defer teardown()
+	// Leave the last utxo out of the snapshot and trust the hash of what is
+	// left, as if a wrong utxo set had been published with its hash.
+	offset := utxoSnapshotHeaderSize + int(dumped.Height+1)*blockHdrSize
+	offset += 4 + int(binary.LittleEndian.Uint32(snapshot[offset:]))
+	r := bytes.NewReader(snapshot[offset+8:])
+	var utxos [][2][]byte
+	for r.Len() > 0 {
+		key, err := wire.ReadVarBytes(r, 0, wire.MaxBlockPayload, "utxo key")
+		if err != nil {
+			t.Fatal(err)
+		}
+		serialized, err := wire.ReadVarBytes(r, 0, wire.MaxBlockPayload,
+			"utxo entry")
+		if err != nil {
+			t.Fatal(err)
+		}
+		utxos = append(utxos, [2][]byte{key, serialized})
+	}
+	utxos = utxos[:len(utxos)-1]
+	var buf bytes.Buffer
+	buf.Write(snapshot[:offset])
+	var count [8]byte
+	binary.LittleEndian.PutUint64(count[:], uint64(len(utxos)))
+	buf.Write(count[:])
+	hasher := newUtxoHasher(uint64(len(utxos)))
+	for _, utxo := range utxos {
+		if err := writeUtxo(io.MultiWriter(&buf, hasher), utxo[0],
+			utxo[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	utxoHash := hasher.utxoHash()
+	loaded, s, teardown2 := loadSnapshotChain(t, "snapshotvalidatorfailload",
+		buf.Bytes(), &utxoHash)
+	defer teardown2()
+	if s.Coins != dumped.Coins-1 {
+		t.Fatalf("got %d coins, want %d", s.Coins, dumped.Coins-1)
+	}
+	v := loaded.NewSnapshotValidator()
+	if v == nil {
+		t.Fatal("no snapshot validator for a pending snapshot")
+	}
+	done, err := validateSnapshotBlocks(chain, v)
+	if !done || err == nil {
+		t.Fatalf("snapshot validation: got done %v, %v, want a failure",
+			done, err)
+	}
+	if s, err = FetchUtxoSnapshot(loaded.db); err != nil ||
+		s.Status != SnapshotInvalid {
+		t.Errorf("got status %v, %v, want %v", s.Status, err,
+			SnapshotInvalid)
+	}
+	if loaded.NewSnapshotValidator() != nil {
+		t.Errorf("snapshot validator for an invalid snapshot")
+	}
+}
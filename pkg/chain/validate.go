@@ -215,22 +215,24 @@ func // checkConnectBlock performs several checks to confirm connecting the
 	}
 	// Trace("checking for hard fork block")
 	// if this is the hard fork activation height special disbursement coinbase
-	// must match the specifications in pkg/chain/hardfork/subsidy.go
-	if node.height == fork.List[1].ActivationHeight &&
-		b.params.Net == wire.MainNet ||
-		node.height == fork.List[1].TestnetStart &&
-			b.params.Net == wire.TestNet3 {
+	// must match the disbursement in the hard fork schedule of the network
+	d, err := hardfork.GetDisbursement(b.params, node.height)
+	if err != nil {
+		Error(err)
+		return err
+	}
+	if d != nil {
 		Trace("checking contents of hardfork coinbase tx")
 		btx, err := block.Tx(0)
 		if err != nil {
 			Error(err)
 		}
-		payees := hardfork.Payees
-		if b.params.Net == wire.TestNet3 {
-			payees = hardfork.TestnetPayees
-		}
-
+		payees := d.Payees
 		txo := btx.MsgTx().TxOut
+		if len(txo) <= len(payees) {
+			return ruleError(ErrBadCoinbaseValue,
+				"hardfork coinbase does not pay the payees list and dev fund")
+		}
 		for i := range payees {
 			if txo[i].Value != int64(payees[i].Amount) {
 				return ruleError(ErrBadCoinbaseValue,
@@ -248,19 +250,11 @@ func // checkConnectBlock performs several checks to confirm connecting the
 			}
 		}
 		remtx := txo[len(payees):]
-		coreamount := hardfork.CoreAmount
-		if b.params.Net == wire.TestNet3 {
-			coreamount = hardfork.TestnetCoreAmount
-		}
-		if remtx[0].Value != int64(coreamount) {
+		if remtx[0].Value != int64(d.CoreAmount) {
 			return ruleError(ErrBadCoinbaseValue,
 				"hardfork coinbase does not pay correct amount to dev fund multisig address")
 		}
-
-		corepk := hardfork.CorePubkeyBytes
-		if b.params.Net == wire.TestNet3 {
-			corepk = hardfork.TestnetCorePubkeyBytes
-		}
+		corepk := d.CorePubkeyBytes
 		remscript := remtx[0].PkScript[2:]
 		for i := range corepk {
 			if len(remscript) < len(corepk[i]) {
@@ -391,9 +385,9 @@ func (b *BlockChain) CheckConnectBlockTemplate(workerNumber uint32, block *util.
 	// Trace("algo ", algo)
 	height := block.Height()
 	// Trace("height ", height)
-	algoname := fork.GetAlgoName(algo, height)
+	algoname := b.params.Forks.AlgoName(algo, height)
 	// Trace("algoname ", algoname)
-	powLimit := fork.GetMinDiff(algoname, height)
+	powLimit := b.params.Forks.MinDiff(algoname, height)
 	// Tracef("CheckConnectBlockTemplate %08x %064x", block.MsgBlock().Header.Bits, powLimit)
 	// Skip the proof of work check as this is just a block template.
 	flags := BFNoPoWCheck
@@ -408,7 +402,7 @@ func (b *BlockChain) CheckConnectBlockTemplate(workerNumber uint32, block *util.
 		str := fmt.Sprintf("previous block must be the current chain tip %v, instead got %v", tip.hash, header.PrevBlock)
 		return ruleError(ErrPrevBlockNotBest, str)
 	}
-	err := checkBlockSanity(block, powLimit, b.timeSource, flags, false, block.Height())
+	err := checkBlockSanity(block, powLimit, b.params.Forks, b.timeSource, flags, false, block.Height())
 	if err != nil {
 		Error("block processing error:", err)
 		return err
@@ -486,82 +480,93 @@ func // checkBlockContext peforms several validation checks on the block which
 	}
 	fastAdd := flags&BFFastAdd == BFFastAdd
 	if !fastAdd {
-		// Obtain the latest state of the deployed CSV soft-fork in order to
-		// properly guard the new validation behavior based on the current BIP 9
-		// version bits state.
-		csvState, err := b.deploymentState(prevNode, chaincfg.DeploymentCSV)
+		return b.checkBlockTransactionsContext(block, prevNode)
+	}
+	return nil
+}
+
+func // checkBlockTransactionsContext performs the validation checks on the
+// transactions of the block which depend on its position within the block
+// chain.
+// This function MUST be called with the chain state lock held (for writes).
+(b *BlockChain) checkBlockTransactionsContext(block *util.Block,
+	prevNode *BlockNode) error {
+	header := &block.MsgBlock().Header
+	// Obtain the latest state of the deployed CSV soft-fork in order to
+	// properly guard the new validation behavior based on the current BIP 9
+	// version bits state.
+	csvState, err := b.deploymentState(prevNode, chaincfg.DeploymentCSV)
+	if err != nil {
+		Error(err)
+		return err
+	}
+	// Once the CSV soft-fork is fully active, we'll switch to using the
+	// current median time past of the past block's timestamps for all
+	// lock-time based checks.
+	blockTime := header.Timestamp
+	if csvState == ThresholdActive {
+		blockTime = prevNode.CalcPastMedianTime()
+	}
+	// The height of this block is one more than the referenced previous
+	// block.
+	blockHeight := prevNode.height + 1
+	// Ensure all transactions in the block are finalized.
+	for _, tx := range block.Transactions() {
+		if !IsFinalizedTransaction(tx, blockHeight,
+			blockTime) {
+			str := fmt.Sprintf("block contains unfinalized "+
+				"transaction %v", tx.Hash())
+			Error(str)
+			return ruleError(ErrUnfinalizedTx, str)
+		}
+	}
+	// Ensure coinbase starts with serialized block heights for blocks whose
+	// version is the serializedHeightVersion or newer once a majority of
+	// the
+	// network has upgraded.  This is part of BIP0034.
+	if ShouldHaveSerializedBlockHeight(header) &&
+		blockHeight >= b.params.BIP0034Height {
+		coinbaseTx := block.Transactions()[0]
+		err := checkSerializedHeight(coinbaseTx, blockHeight)
 		if err != nil {
 			Error(err)
 			return err
 		}
-		// Once the CSV soft-fork is fully active, we'll switch to using the
-		// current median time past of the past block's timestamps for all
-		// lock-time based checks.
-		blockTime := header.Timestamp
-		if csvState == ThresholdActive {
-			blockTime = prevNode.CalcPastMedianTime()
-		}
-		// The height of this block is one more than the referenced previous
-		// block.
-		blockHeight := prevNode.height + 1
-		// Ensure all transactions in the block are finalized.
-		for _, tx := range block.Transactions() {
-			if !IsFinalizedTransaction(tx, blockHeight,
-				blockTime) {
-				str := fmt.Sprintf("block contains unfinalized "+
-					"transaction %v", tx.Hash())
-				Error(str)
-				return ruleError(ErrUnfinalizedTx, str)
-			}
-		}
-		// Ensure coinbase starts with serialized block heights for blocks whose
-		// version is the serializedHeightVersion or newer once a majority of
-		// the
-		// network has upgraded.  This is part of BIP0034.
-		if ShouldHaveSerializedBlockHeight(header) &&
-			blockHeight >= b.params.BIP0034Height {
-			coinbaseTx := block.Transactions()[0]
-			err := checkSerializedHeight(coinbaseTx, blockHeight)
-			if err != nil {
-				Error(err)
-				return err
-			}
-		}
-		// Query for the Version Bits state for the segwit soft-fork deployment.
-		// If segwit is active,
-		// we'll switch over to enforcing all the new rules.
-		segwitState, err := b.deploymentState(prevNode,
-			chaincfg.DeploymentSegwit)
-		if err != nil {
+	}
+	// Query for the Version Bits state for the segwit soft-fork deployment.
+	// If segwit is active,
+	// we'll switch over to enforcing all the new rules.
+	segwitState, err := b.deploymentState(prevNode,
+		chaincfg.DeploymentSegwit)
+	if err != nil {
+		Error(err)
+		return err
+	}
+	// If segwit is active,
+	// then we'll need to fully validate the new witness
+	// commitment for adherence to the rules.
+	if segwitState == ThresholdActive {
+		// Validate the witness commitment (if any) within the block.  This
+		// involves asserting that if the coinbase contains the special
+		// commitment output,
+		// then this merkle root matches a computed merkle
+		// root of all the wtxid's of the transactions within the block. In
+		// addition, various other checks against the coinbase's witness
+		// stack.
+		if err := ValidateWitnessCommitment(block); err != nil {
 			Error(err)
 			return err
 		}
-		// If segwit is active,
-		// then we'll need to fully validate the new witness
-		// commitment for adherence to the rules.
-		if segwitState == ThresholdActive {
-			// Validate the witness commitment (if any) within the block.  This
-			// involves asserting that if the coinbase contains the special
-			// commitment output,
-			// then this merkle root matches a computed merkle
-			// root of all the wtxid's of the transactions within the block. In
-			// addition, various other checks against the coinbase's witness
-			// stack.
-			if err := ValidateWitnessCommitment(block); err != nil {
-				Error(err)
-				return err
-			}
-			// Once the witness commitment, witness nonce, and sig op cost have
-			// been validated, we can finally assert that the block's weight
-			// doesn't exceed the current consensus parameter.
-			blockWeight := GetBlockWeight(block)
-			if blockWeight > MaxBlockWeight {
-				str := fmt.Sprintf(
-					"block's weight metric is too high - got %v, max %v",
-					blockWeight, MaxBlockWeight)
-				Error(err)
-				return ruleError(ErrBlockWeightTooHigh, str)
-			}
+		// Once the witness commitment, witness nonce, and sig op cost have
+		// been validated, we can finally assert that the block's weight
+		// doesn't exceed the current consensus parameter.
+		blockWeight := GetBlockWeight(block)
+		if blockWeight > MaxBlockWeight {
+			str := fmt.Sprintf(
+				"block's weight metric is too high - got %v, max %v",
+				blockWeight, MaxBlockWeight)
+			Error(err)
+			return ruleError(ErrBlockWeightTooHigh, str)
 		}
 	}
 	return nil
@@ -581,36 +586,10 @@ func // checkBlockHeaderContext performs several validation checks on the block
 	}
 	fastAdd := flags&BFFastAdd == BFFastAdd
 	if !fastAdd {
-		// Ensure the difficulty specified in the block header matches the
-		// calculated difficulty based on the previous block and difficulty
-		// retarget rules.
-		// a := fork.GetAlgoName(header.Version, prevNode.height+1)
-		// Infof("algo %s %d %8x %d", a, header.Version, header.Bits,
-		// 	prevNode.height+1)
-		expectedDifficulty, err := b.calcNextRequiredDifficulty(
-			workerNumber, prevNode, header.Timestamp,
-			fork.GetAlgoName(header.Version, prevNode.height+1),
-			true)
-		if err != nil {
-			Error(err)
+		if err := b.checkBlockHeaderDifficulty(workerNumber, header,
+			prevNode); err != nil {
 			return err
 		}
-		blockDifficulty := header.Bits
-		if blockDifficulty != expectedDifficulty {
-			str := "block difficulty of %08x %064x is not the expected value of %08x %064x"
-			str = fmt.Sprintf(str, blockDifficulty, CompactToBig(blockDifficulty), expectedDifficulty, CompactToBig(expectedDifficulty))
-			Error(str)
-			return ruleError(ErrUnexpectedDifficulty, str)
-		}
-		// Ensure the timestamp for the block header is after the median time
-		// of the last several blocks (medianTimeBlocks).
-		medianTime := prevNode.CalcPastMedianTime()
-		if !header.Timestamp.After(medianTime) {
-			str := "block timestamp of %v is not after expected %v"
-			str = fmt.Sprintf(str, header.Timestamp, medianTime)
-			Error(str)
-			return ruleError(ErrTimeTooOld, str)
-		}
 	}
 
 	// The height of this block is one more than the referenced previous block.
@@ -653,6 +632,45 @@ func // checkBlockHeaderContext performs several validation checks on the block
 	return nil
 }
 
+func // checkBlockHeaderDifficulty ensures the difficulty of a block header
+// is the one required after the previous block, and that its timestamp is
+// after the median time of the blocks before it.
+// This function MUST be called with the chain state lock held (for writes).
+(b *BlockChain) checkBlockHeaderDifficulty(workerNumber uint32,
+	header *wire.BlockHeader, prevNode *BlockNode) error {
+	// Ensure the difficulty specified in the block header matches the
+	// calculated difficulty based on the previous block and difficulty
+	// retarget rules.
+	// a := fork.GetAlgoName(header.Version, prevNode.height+1)
+	// Infof("algo %s %d %8x %d", a, header.Version, header.Bits,
+	// 	prevNode.height+1)
+	expectedDifficulty, err := b.calcNextRequiredDifficulty(
+		workerNumber, prevNode, header.Timestamp,
+		b.params.Forks.AlgoName(header.Version, prevNode.height+1),
+		true)
+	if err != nil {
+		Error(err)
+		return err
+	}
+	blockDifficulty := header.Bits
+	if blockDifficulty != expectedDifficulty {
+		str := "block difficulty of %08x %064x is not the expected value of %08x %064x"
+		str = fmt.Sprintf(str, blockDifficulty, CompactToBig(blockDifficulty), expectedDifficulty, CompactToBig(expectedDifficulty))
+		Error(str)
+		return ruleError(ErrUnexpectedDifficulty, str)
+	}
+	// Ensure the timestamp for the block header is after the median time
+	// of the last several blocks (medianTimeBlocks).
+	medianTime := prevNode.CalcPastMedianTime()
+	if !header.Timestamp.After(medianTime) {
+		str := "block timestamp of %v is not after expected %v"
+		str = fmt.Sprintf(str, header.Timestamp, medianTime)
+		Error(str)
+		return ruleError(ErrTimeTooOld, str)
+	}
+	return nil
+}
+
 func // CalcBlockSubsidy returns the subsidy amount a block at the provided
 // height should have. This is mainly used for determining how much the
 // coinbase for newly generated blocks awards as well as validating the
@@ -666,31 +684,23 @@ CalcBlockSubsidy(height int32, chainParams *netparams.Params, version int32) (r
 		return int64(baseSubsidy)
 	}
 	// Equivalent to: baseSubsidy / 2^(height/subsidyHalvingInterval)
-	switch fork.GetCurrent(height) {
+	switch chainParams.Forks.Current(height) {
 	case 0:
 		return int64(baseSubsidy) >> uint64(height/chainParams.
 			SubsidyReductionInterval)
 	case 1:
 		var total util.Amount
-		if (chainParams.Net == wire.MainNet &&
-			height == fork.List[1].ActivationHeight) ||
-			(chainParams.Net == wire.TestNet3 &&
-				height == fork.List[1].TestnetStart) {
-			payees := hardfork.Payees
-			if chainParams.Net == wire.TestNet3 {
-				payees = hardfork.TestnetPayees
-			}
-			for i := range payees {
-				total += payees[i].Amount
-			}
+		if d, err := hardfork.GetDisbursement(chainParams, height); Check(err) {
+		} else if d != nil {
+			total = d.Total()
 			total += util.Amount(CalcBlockSubsidy(height+1, chainParams, version))
-			total += hardfork.TestnetCoreAmount
 			return int64(total)
 		}
 		// Plan 9 hard fork prescribes a smooth supply curve made using an
 		// exponential decay formula adjusted to fit the previous halving
 		// cycle and accounting for the block time difference
-		ttpb := float64(fork.List[1].Algos[fork.GetAlgoName(version, height)].VersionInterval)
+		forks := chainParams.Forks
+		ttpb := float64(forks.Forks[1].Algos[forks.AlgoName(version, height)].VersionInterval)
 		r = int64(2.7 * ttpb / 300 * (math.Pow(2.7, -float64(height)*300*9/ttpb/375000.0)) * 100000000 / 9)
 	}
 	return
@@ -699,16 +709,16 @@ CalcBlockSubsidy(height int32, chainParams *netparams.Params, version int32) (r
 func // CheckBlockSanity performs some preliminary checks on a block to
 // ensure it is sane before continuing with block processing.
 // These checks are context free.
-CheckBlockSanity(block *util.Block, powLimit *big.Int, timeSource MedianTimeSource, DoNotCheckPow bool, height int32) error {
+CheckBlockSanity(block *util.Block, powLimit *big.Int, forks *fork.Schedule, timeSource MedianTimeSource, DoNotCheckPow bool, height int32) error {
 	Trace("CheckBlockSanity powlimit %64x", powLimit)
-	return checkBlockSanity(block, powLimit, timeSource, BFNone, DoNotCheckPow, height)
+	return checkBlockSanity(block, powLimit, forks, timeSource, BFNone, DoNotCheckPow, height)
 }
 
 func // CheckProofOfWork ensures the block header bits which indicate the target
 // difficulty is in min/max range and that the block hash is less than the
 // target difficulty as claimed.
-CheckProofOfWork(block *util.Block, powLimit *big.Int, height int32) error {
-	return checkProofOfWork(&block.MsgBlock().Header, powLimit, BFNone, height)
+CheckProofOfWork(block *util.Block, powLimit *big.Int, forks *fork.Schedule, height int32) error {
+	return checkProofOfWork(&block.MsgBlock().Header, powLimit, forks, BFNone, height)
 }
 
 // CheckTransactionInputs performs a series of checks on the inputs to a
@@ -1101,11 +1111,11 @@ func // checkBlockHeaderSanity performs some preliminary checks on a block
 // These checks are context free.
 // The flags do not modify the behavior of this function directly,
 // however they are needed to pass along to checkProofOfWork.
-checkBlockHeaderSanity(header *wire.BlockHeader, powLimit *big.Int, timeSource MedianTimeSource, flags BehaviorFlags, height int32) error {
+checkBlockHeaderSanity(header *wire.BlockHeader, powLimit *big.Int, forks *fork.Schedule, timeSource MedianTimeSource, flags BehaviorFlags, height int32) error {
 	// Tracef("checkBlockHeaderSanity %064x %+v", powLimit, header)
 	// Ensure the proof of work bits in the block header is in min/max range and
 	// the block hash is less than the target value described by the bits.
-	err := checkProofOfWork(header, powLimit, flags, height)
+	err := checkProofOfWork(header, powLimit, forks, flags, height)
 	if err != nil {
 		Errorf("%+v %v", header, err)
 		return err
@@ -1136,11 +1146,11 @@ func // checkBlockSanity performs some preliminary checks on a block to
 // These checks are context free.
 // The flags do not modify the behavior of this function directly,
 // however they are needed to pass along to checkBlockHeaderSanity.
-checkBlockSanity(block *util.Block, powLimit *big.Int, timeSource MedianTimeSource, flags BehaviorFlags, DoNotCheckPow bool, height int32) error {
+checkBlockSanity(block *util.Block, powLimit *big.Int, forks *fork.Schedule, timeSource MedianTimeSource, flags BehaviorFlags, DoNotCheckPow bool, height int32) error {
 	Tracef("checkBlockSanity %08x %064x", block.MsgBlock().Header.Bits, powLimit)
 	msgBlock := block.MsgBlock()
 	header := &msgBlock.Header
-	err := checkBlockHeaderSanity(header, powLimit, timeSource, flags, height)
+	err := checkBlockHeaderSanity(header, powLimit, forks, timeSource, flags, height)
 	if err != nil {
 		Error(err)
 		Debug("block processing error: ", block.MsgBlock().Header.Version, err)
@@ -1242,9 +1252,9 @@ func // checkProofOfWork ensures the block header bits which indicate the target
 // The flags modify the behavior of this function as follows:
 //  - BFNoPoWCheck: The check to ensure the block hash is less than the
 //  target difficulty is not performed.
-checkProofOfWork(header *wire.BlockHeader, powLimit *big.Int, flags BehaviorFlags,
+checkProofOfWork(header *wire.BlockHeader, powLimit *big.Int, forks *fork.Schedule, flags BehaviorFlags,
 	height int32) error {
-	// Tracef("hash %d %s", height, header.BlockHashWithAlgos(height))
+	// Tracef("hash %d %s", height, header.BlockHashWithAlgos(forks, height))
 	// The target difficulty must be larger than zero.
 	if powLimit == nil {
 		return errors.New("PoW limit was not set")
@@ -1269,9 +1279,11 @@ checkProofOfWork(header *wire.BlockHeader, powLimit *big.Int, flags BehaviorFlag
 	if flags&BFNoPoWCheck == 0 {
 		// The block hash must be less than the claimed target.
 		// Unless there is less than 10 previous with the same version (algo)...
-		// Debug("height", height, fork.IsTestnet)
-		hash := header.BlockHashWithAlgos(height)
-		// Debug("blockhashwithalgos", hash, fork.IsTestnet)
+		hash, err := header.BlockHashWithAlgos(forks, height)
+		if err != nil {
+			Error(err)
+			return err
+		}
 		bigHash := HashToBig(&hash)
 		if bigHash.Cmp(target) > 0 {
 			str := fmt.Sprintf("block hash of %d"+
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	chaincfg "github.com/p9c/pod/pkg/chain/config"
+	"github.com/p9c/pod/pkg/chain/config/netparams"
 	chainhash "github.com/p9c/pod/pkg/chain/hash"
 	"github.com/p9c/pod/pkg/chain/wire"
 	"github.com/p9c/pod/pkg/util"
@@ -131,9 +132,10 @@ func TestSequenceLocksActive(t *testing.T) {
 // TestCheckBlockSanity tests the CheckBlockSanity function to ensure it works as expected.
 func TestCheckBlockSanity(t *testing.T) {
 	powLimit := chaincfg.MainNetParams.PowLimit
+	forks := netparams.MainNetParams.Forks
 	block := util.NewBlock(&Block100000)
 	timeSource := NewMedianTime()
-	err := CheckBlockSanity(block, powLimit, timeSource, false, 1)
+	err := CheckBlockSanity(block, powLimit, forks, timeSource, false, 1)
 	if err != nil {
 		t.Errorf("CheckBlockSanity: %v", err)
 	}
@@ -141,7 +143,7 @@ func TestCheckBlockSanity(t *testing.T) {
 	// second fails.
 	timestamp := block.MsgBlock().Header.Timestamp
 	block.MsgBlock().Header.Timestamp = timestamp.Add(time.Nanosecond)
-	err = CheckBlockSanity(block, powLimit, timeSource, false, 1)
+	err = CheckBlockSanity(block, powLimit, forks, timeSource, false, 1)
 	if err == nil {
 		t.Errorf("CheckBlockSanity: error is nil when it shouldn't be")
 	}
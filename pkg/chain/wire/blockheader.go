@@ -41,19 +41,14 @@ func (h *BlockHeader) BlockHash() (out chainhash.Hash) {
 	return
 }
 
-// BlockHashWithAlgos computes the block identifier hash for the given block header. This function is additional because the sync manager and the parallelcoin protocol only use SHA256D hashes for inventories and calculating the scrypt (or other) hash for these blocks when requested via that route causes an 'unrequested block' error.
-func (h *BlockHeader) BlockHashWithAlgos(height int32) (out chainhash.Hash) {
-	// Encode the header and double sha256 everything prior to the number of transactions.  Ignore the error returns since there is no way the encode could fail except being out of memory which would cause a run-time panic.
+// BlockHashWithAlgos computes the block identifier hash for the given block header. This function is additional because the sync manager and the parallelcoin protocol only use SHA256D hashes for inventories and calculating the scrypt (or other) hash for these blocks when requested via that route causes an 'unrequested block' error. The hash is that of the algorithm of the block version at the height in the hard fork schedule of the network, and an error is returned with the maximum hash if the schedule has no hash for it.
+func (h *BlockHeader) BlockHashWithAlgos(forks *fork.Schedule, height int32) (out chainhash.Hash, err error) {
 	buf := bytes.NewBuffer(make([]byte, 0, MaxBlockHeaderPayload))
-	err := writeBlockHeader(buf, 0, h)
-	if err != nil {
+	if err = writeBlockHeader(buf, 0, h); err != nil {
 		Error("error writing block header to buffer", err)
+		return
 	}
-	vers := h.Version
-	algo := fork.GetAlgoName(vers, height)
-	out = forkhash.Hash(buf.Bytes(), algo, height)
-	// L.Prror("BlockHashWithAlgos %d %s %s %s\n", vers, algo, out)
-	return
+	return forkhash.Hash(forks, buf.Bytes(), h.Version, height)
 }
 
 // BtcDecode decodes r using the bitcoin protocol encoding into the receiver. This is part of the Message interface implementation. See Deserialize for decoding block headers stored to disk, such as in a database, as opposed to decoding block headers from the wire.
@@ -48,6 +48,10 @@ const (
 	CmdCFilter      = "cfilter"
 	CmdCFHeaders    = "cfheaders"
 	CmdCFCheckpt    = "cfcheckpt"
+	CmdSendCmpct    = "sendcmpct"
+	CmdCmpctBlock   = "cmpctblock"
+	CmdGetBlockTxn  = "getblocktxn"
+	CmdBlockTxn     = "blocktxn"
 )
 
 // MessageEncoding represents the wire message encoding format to be used.
@@ -136,6 +140,14 @@ func makeEmptyMessage(command string) (Message, error) {
 		msg = &MsgCFHeaders{}
 	case CmdCFCheckpt:
 		msg = &MsgCFCheckpt{}
+	case CmdSendCmpct:
+		msg = &MsgSendCmpct{}
+	case CmdCmpctBlock:
+		msg = &MsgCmpctBlock{}
+	case CmdGetBlockTxn:
+		msg = &MsgGetBlockTxn{}
+	case CmdBlockTxn:
+		msg = &MsgBlockTxn{}
 	default:
 		return nil, fmt.Errorf("unhandled command [%s]", command)
 	}
@@ -68,6 +68,10 @@ func TestMessage(t *testing.T) {
 		[]byte("payload"))
 	msgCFHeaders := NewMsgCFHeaders()
 	msgCFCheckpt := NewMsgCFCheckpt(GCSFilterRegular, &chainhash.Hash{}, 0)
+	msgSendCmpct := NewMsgSendCmpct(true, CmpctBlockVersion)
+	msgCmpctBlock := NewMsgCmpctBlockFromBlock(&blockOne, 0, CmpctBlockVersion)
+	msgGetBlockTxn := NewMsgGetBlockTxn(&chainhash.Hash{}, []uint32{1})
+	msgBlockTxn := NewMsgBlockTxn(&chainhash.Hash{})
 	tests := []struct {
 		in     Message    // Value to encode
 		out    Message    // Expected decoded value
@@ -102,6 +106,10 @@ func TestMessage(t *testing.T) {
 		{msgCFilter, msgCFilter, pver, MainNet, 65},
 		{msgCFHeaders, msgCFHeaders, pver, MainNet, 90},
 		{msgCFCheckpt, msgCFCheckpt, pver, MainNet, 58},
+		{msgSendCmpct, msgSendCmpct, pver, MainNet, 33},
+		{msgCmpctBlock, msgCmpctBlock, pver, MainNet, 249},
+		{msgGetBlockTxn, msgGetBlockTxn, pver, MainNet, 58},
+		{msgBlockTxn, msgBlockTxn, pver, MainNet, 57},
 	}
 	t.Logf("Running %d tests", len(tests))
 	for i, test := range tests {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/p9c/pod/pkg/chain/fork"
 	chainhash "github.com/p9c/pod/pkg/chain/hash"
 )
 
@@ -197,8 +198,8 @@ func (msg *MsgBlock) BlockHash() chainhash.Hash {
 }
 
 // BlockHashWithAlgos computes the block identifier hash for this block.
-func (msg *MsgBlock) BlockHashWithAlgos(h int32) chainhash.Hash {
-	return msg.Header.BlockHashWithAlgos(h)
+func (msg *MsgBlock) BlockHashWithAlgos(forks *fork.Schedule, h int32) (chainhash.Hash, error) {
+	return msg.Header.BlockHashWithAlgos(forks, h)
 }
 
 // TxHashes returns a slice of hashes of all of transactions in this block.
@@ -0,0 +1,171 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestBlockTxn tests the MsgBlockTxn API.
+func TestBlockTxn(t *testing.T) {
+	pver := ProtocolVersion
+	hash := blockOne.BlockHash()
+	msg := NewMsgBlockTxn(&hash)
+	if !msg.BlockHash.IsEqual(&hash) {
+		t.Errorf("NewMsgBlockTxn: wrong block hash - got %v, want %v",
+			msg.BlockHash, hash)
+	}
+	// Ensure the command is expected value.
+	wantCmd := "blocktxn"
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgBlockTxn: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+	// Ensure max payload is expected value for latest protocol version.
+	wantPayload := uint32(MaxBlockPayload)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+	// Ensure we get the same transaction output point data back out.
+	tx := blockOne.Transactions[0].Copy()
+	_ = msg.AddTransaction(tx)
+	if !reflect.DeepEqual(msg.Transactions, []*MsgTx{tx}) {
+		t.Errorf("AddTransaction: wrong transactions - got %v, want %v",
+			spew.Sdump(msg.Transactions), spew.Sdump([]*MsgTx{tx}))
+	}
+}
+
+// TestBlockTxnWire tests the MsgBlockTxn wire encode and decode for various protocol versions.
+func TestBlockTxnWire(t *testing.T) {
+	hash := blockOne.BlockHash()
+	noTxns := NewMsgBlockTxn(&hash)
+	noTxnsEncoded := append(append([]byte{}, hash[:]...),
+		0x00, // Varint for number of transactions
+	)
+	oneTxn := NewMsgBlockTxn(&hash)
+	_ = oneTxn.AddTransaction(blockOne.Transactions[0])
+	tests := []struct {
+		in   *MsgBlockTxn // Message to encode
+		out  *MsgBlockTxn // Expected decoded message
+		buf  []byte       // Wire encoding
+		pver uint32       // Protocol version for wire encoding
+	}{
+		// Latest protocol version with no transactions.
+		{noTxns, noTxns, noTxnsEncoded, ProtocolVersion},
+		// Latest protocol version with one transaction.
+		{oneTxn, oneTxn, blockTxnOneBytes, ProtocolVersion},
+		// Protocol version SendCmpctVersion with one transaction.
+		{oneTxn, oneTxn, blockTxnOneBytes, SendCmpctVersion},
+	}
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.BtcEncode(&buf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("BtcEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("BtcEncode #%d\n got: %s want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+		// Decode the message from wire format.
+		var msg MsgBlockTxn
+		rbuf := bytes.NewReader(test.buf)
+		err = msg.BtcDecode(rbuf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("BtcDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("BtcDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
+
+// TestBlockTxnWireErrors performs negative tests against wire encode and decode of MsgBlockTxn to confirm error paths work correctly.
+func TestBlockTxnWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+	pverNoSendCmpct := SendCmpctVersion - 1
+	wireErr := &MessageError{}
+	hash := blockOne.BlockHash()
+	baseBlockTxn := NewMsgBlockTxn(&hash)
+	_ = baseBlockTxn.AddTransaction(blockOne.Transactions[0])
+	// Message that forces an error by having more than the max allowed transactions.
+	maxTxnsEncoded := append(append([]byte{}, hash[:]...),
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	)
+	tests := []struct {
+		in       *MsgBlockTxn // Value to encode
+		buf      []byte       // Wire encoding
+		pver     uint32       // Protocol version for wire encoding
+		max      int          // Max size of fixed buffer to induce errors
+		writeErr error        // Expected write error
+		readErr  error        // Expected read error
+	}{
+		// Force error in block hash.
+		{baseBlockTxn, blockTxnOneBytes, pver, 0, io.ErrShortWrite, io.EOF},
+		// Force error in transaction count.
+		{baseBlockTxn, blockTxnOneBytes, pver, 32, io.ErrShortWrite, io.EOF},
+		// Force error in transactions.
+		{baseBlockTxn, blockTxnOneBytes, pver, 33, io.ErrShortWrite, io.EOF},
+		// Force error due to unsupported protocol version.
+		{baseBlockTxn, blockTxnOneBytes, pverNoSendCmpct, len(blockTxnOneBytes), wireErr, wireErr},
+		// Force error with greater than max transactions.
+		{baseBlockTxn, maxTxnsEncoded, pver, len(blockTxnOneBytes), nil, wireErr},
+	}
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.BtcEncode(w, test.pver, BaseEncoding)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.writeErr) {
+			t.Errorf("BtcEncode #%d wrong error got: %v, want: %v",
+				i, err, test.writeErr)
+			continue
+		}
+		// For errors which are not of type MessageError, check them for equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.writeErr {
+				t.Errorf("BtcEncode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.writeErr)
+				continue
+			}
+		}
+		// Decode from wire format.
+		var msg MsgBlockTxn
+		r := newFixedReader(test.max, test.buf)
+		err = msg.BtcDecode(r, test.pver, BaseEncoding)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.readErr) {
+			t.Errorf("BtcDecode #%d wrong error got: %v, want: %v",
+				i, err, test.readErr)
+			continue
+		}
+		// For errors which are not of type MessageError, check them for equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.readErr {
+				t.Errorf("BtcDecode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.readErr)
+				continue
+			}
+		}
+	}
+}
+
+// blockTxnOneBytes is the serialized bytes of a blocktxn message for block one with its coinbase transaction.
+var blockTxnOneBytes = func() []byte {
+	hash := blockOne.BlockHash()
+	b := append([]byte{}, hash[:]...)
+	b = append(b, 0x01) // Varint for number of transactions
+	return append(b, blockOneBytes[81:]...)
+}()
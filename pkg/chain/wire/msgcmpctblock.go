@@ -0,0 +1,221 @@
+package wire
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/aead/siphash"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+)
+
+// ShortIDSize is the number of bytes of the short transaction IDs in a compact block.
+const ShortIDSize = 6
+
+// shortIDMask keeps the low ShortIDSize bytes of a SipHash value.
+const shortIDMask = 1<<(ShortIDSize*8) - 1
+
+// PrefilledTx is a transaction sent in full within a compact block along with its index in the block, because the receiver is not expected to have it, such as the coinbase.
+type PrefilledTx struct {
+	Index uint32
+	Tx    *MsgTx
+}
+
+// MsgCmpctBlock implements the Message interface and represents a bitcoin cmpctblock message.  It is used to relay a block as its header and a short ID for each of its transactions, which the receiver looks up in its mempool, along with the transactions it is not expected to have in full.  Those it can't find are requested with a getblocktxn message (MsgGetBlockTxn). This message was not added until protocol versions starting with SendCmpctVersion.
+type MsgCmpctBlock struct {
+	Header       BlockHeader
+	Nonce        uint64
+	ShortIDs     []uint64
+	PrefilledTxs []*PrefilledTx
+}
+
+// TxCount returns the number of transactions in the block, counting both those with short IDs and those sent in full.
+func (msg *MsgCmpctBlock) TxCount() int {
+	return len(msg.ShortIDs) + len(msg.PrefilledTxs)
+}
+
+// ShortIDKey returns the SipHash key the short IDs of the block are made with, which is the first 16 bytes of the single SHA256 hash of its header and nonce.
+func (msg *MsgCmpctBlock) ShortIDKey() (key [16]byte) {
+	buf := bytes.NewBuffer(make([]byte, 0, MaxBlockHeaderPayload+8))
+	_ = writeBlockHeader(buf, 0, &msg.Header)
+	_ = writeElement(buf, msg.Nonce)
+	copy(key[:], chainhash.HashB(buf.Bytes()))
+	return
+}
+
+// ShortID returns the short ID of a transaction hash, which is its SipHash-2-4 value with the key of the compact block truncated to ShortIDSize bytes.  The witness transaction hash is used with CmpctBlockWitnessVersion, and the transaction hash otherwise.
+func ShortID(key *[16]byte, hash *chainhash.Hash) uint64 {
+	return siphash.Sum64(hash[:], key) & shortIDMask
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver. This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < SendCmpctVersion {
+		str := fmt.Sprintf("cmpctblock message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgCmpctBlock.BtcDecode", str)
+	}
+	err := readBlockHeader(r, pver, &msg.Header)
+	if err != nil {
+		Error(err)
+		return err
+	}
+	err = readElement(r, &msg.Nonce)
+	if err != nil {
+		Error(err)
+		return err
+	}
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		Error(err)
+		return err
+	}
+	// Prevent more transactions than could possibly fit into a block. It would be possible to cause memory exhaustion and panics without a sane upper bound on this count.
+	if count > maxTxPerBlock {
+		str := fmt.Sprintf("too many short IDs to fit into a block "+
+			"[count %d, max %d]", count, maxTxPerBlock)
+		return messageError("MsgCmpctBlock.BtcDecode", str)
+	}
+	msg.ShortIDs = make([]uint64, count)
+	var buf [8]byte
+	for i := range msg.ShortIDs {
+		if _, err := io.ReadFull(r, buf[:ShortIDSize]); err != nil {
+			Error(err)
+			return err
+		}
+		msg.ShortIDs[i] = littleEndian.Uint64(buf[:])
+	}
+	prefilledCount, err := ReadVarInt(r, pver)
+	if err != nil {
+		Error(err)
+		return err
+	}
+	if prefilledCount > maxTxPerBlock-count {
+		str := fmt.Sprintf("too many transactions to fit into a block "+
+			"[count %d, max %d]", count+prefilledCount, maxTxPerBlock)
+		return messageError("MsgCmpctBlock.BtcDecode", str)
+	}
+	total := count + prefilledCount
+	msg.PrefilledTxs = make([]*PrefilledTx, 0, prefilledCount)
+	// The indexes are differentially encoded, each as the number of transactions after the previous one.
+	var next uint64
+	for i := uint64(0); i < prefilledCount; i++ {
+		diff, err := ReadVarInt(r, pver)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		if diff >= total-next {
+			str := fmt.Sprintf("prefilled transaction index out of "+
+				"range [index %d, count %d]", next+diff, total)
+			return messageError("MsgCmpctBlock.BtcDecode", str)
+		}
+		tx := MsgTx{}
+		if err := tx.BtcDecode(r, pver, enc); err != nil {
+			Error(err)
+			return err
+		}
+		index := next + diff
+		msg.PrefilledTxs = append(msg.PrefilledTxs,
+			&PrefilledTx{Index: uint32(index), Tx: &tx})
+		next = index + 1
+	}
+	return nil
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding. This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < SendCmpctVersion {
+		str := fmt.Sprintf("cmpctblock message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgCmpctBlock.BtcEncode", str)
+	}
+	err := writeBlockHeader(w, pver, &msg.Header)
+	if err != nil {
+		Error(err)
+		return err
+	}
+	err = writeElement(w, msg.Nonce)
+	if err != nil {
+		Error(err)
+		return err
+	}
+	err = WriteVarInt(w, pver, uint64(len(msg.ShortIDs)))
+	if err != nil {
+		Error(err)
+		return err
+	}
+	var buf [8]byte
+	for _, id := range msg.ShortIDs {
+		littleEndian.PutUint64(buf[:], id)
+		if _, err := w.Write(buf[:ShortIDSize]); err != nil {
+			Error(err)
+			return err
+		}
+	}
+	err = WriteVarInt(w, pver, uint64(len(msg.PrefilledTxs)))
+	if err != nil {
+		Error(err)
+		return err
+	}
+	var next uint32
+	for _, prefilled := range msg.PrefilledTxs {
+		if prefilled.Index < next {
+			str := fmt.Sprintf("prefilled transaction indexes are not "+
+				"in increasing order [index %d after %d]", prefilled.Index,
+				next)
+			return messageError("MsgCmpctBlock.BtcEncode", str)
+		}
+		err = WriteVarInt(w, pver, uint64(prefilled.Index-next))
+		if err != nil {
+			Error(err)
+			return err
+		}
+		if err = prefilled.Tx.BtcEncode(w, pver, enc); err != nil {
+			Error(err)
+			return err
+		}
+		next = prefilled.Index + 1
+	}
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) Command() string {
+	return CmdCmpctBlock
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the receiver.  This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) MaxPayloadLength(pver uint32) uint32 {
+	return MaxBlockPayload
+}
+
+// NewMsgCmpctBlock returns a new bitcoin cmpctblock message that conforms to the Message interface, with no transactions.  See MsgCmpctBlock for details.
+func NewMsgCmpctBlock(header *BlockHeader, nonce uint64) *MsgCmpctBlock {
+	return &MsgCmpctBlock{
+		Header: *header,
+		Nonce:  nonce,
+	}
+}
+
+// NewMsgCmpctBlockFromBlock returns a new bitcoin cmpctblock message for a block, of the given compact block version, that sends its coinbase in full and short IDs for its other transactions.
+func NewMsgCmpctBlockFromBlock(block *MsgBlock, nonce, version uint64) *MsgCmpctBlock {
+	msg := NewMsgCmpctBlock(&block.Header, nonce)
+	if len(block.Transactions) == 0 {
+		return msg
+	}
+	msg.PrefilledTxs = []*PrefilledTx{{Index: 0, Tx: block.Transactions[0]}}
+	msg.ShortIDs = make([]uint64, 0, len(block.Transactions)-1)
+	key := msg.ShortIDKey()
+	for _, tx := range block.Transactions[1:] {
+		var hash chainhash.Hash
+		if version == CmpctBlockWitnessVersion {
+			hash = tx.WitnessHash()
+		} else {
+			hash = tx.TxHash()
+		}
+		msg.ShortIDs = append(msg.ShortIDs, ShortID(&key, &hash))
+	}
+	return msg
+}
@@ -0,0 +1,277 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+)
+
+// TestCmpctBlock tests the MsgCmpctBlock API.
+func TestCmpctBlock(t *testing.T) {
+	pver := ProtocolVersion
+	msg := NewMsgCmpctBlock(&blockOne.Header, 0x0102030405060708)
+	if !reflect.DeepEqual(&msg.Header, &blockOne.Header) {
+		t.Errorf("NewMsgCmpctBlock: wrong header - got %v, want %v",
+			spew.Sdump(&msg.Header), spew.Sdump(&blockOne.Header))
+	}
+	if msg.Nonce != 0x0102030405060708 {
+		t.Errorf("NewMsgCmpctBlock: wrong nonce - got %v", msg.Nonce)
+	}
+	if msg.TxCount() != 0 {
+		t.Errorf("NewMsgCmpctBlock: wrong tx count - got %v, want 0",
+			msg.TxCount())
+	}
+	// Ensure the command is expected value.
+	wantCmd := "cmpctblock"
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("MsgCmpctBlock: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+	// Ensure max payload is expected value for latest protocol version.
+	wantPayload := uint32(MaxBlockPayload)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+}
+
+// TestCmpctBlockFromBlock tests that the short IDs of a compact block made from a block match those of its transactions and that its coinbase is prefilled.
+func TestCmpctBlockFromBlock(t *testing.T) {
+	block := NewMsgBlock(&blockOne.Header)
+	coinbase := blockOne.Transactions[0]
+	_ = block.AddTransaction(coinbase)
+	for i := uint32(0); i < 3; i++ {
+		tx := NewMsgTx(1)
+		tx.AddTxIn(NewTxIn(NewOutPoint(&chainhash.Hash{}, i), nil, nil))
+		tx.AddTxOut(NewTxOut(int64(i), []byte{0x51}))
+		_ = block.AddTransaction(tx)
+	}
+	for _, version := range []uint64{CmpctBlockVersion, CmpctBlockWitnessVersion} {
+		msg := NewMsgCmpctBlockFromBlock(block, 42, version)
+		if msg.TxCount() != len(block.Transactions) {
+			t.Errorf("version %d: wrong tx count - got %d, want %d",
+				version, msg.TxCount(), len(block.Transactions))
+			continue
+		}
+		if len(msg.PrefilledTxs) != 1 || msg.PrefilledTxs[0].Index != 0 ||
+			msg.PrefilledTxs[0].Tx != coinbase {
+			t.Errorf("version %d: coinbase not prefilled - got %v",
+				version, spew.Sdump(msg.PrefilledTxs))
+			continue
+		}
+		key := msg.ShortIDKey()
+		for i, tx := range block.Transactions[1:] {
+			hash := tx.TxHash()
+			if version == CmpctBlockWitnessVersion {
+				hash = tx.WitnessHash()
+			}
+			id := ShortID(&key, &hash)
+			if id>>(ShortIDSize*8) != 0 {
+				t.Errorf("version %d: short ID %x is more than %d bytes",
+					version, id, ShortIDSize)
+			}
+			if msg.ShortIDs[i] != id {
+				t.Errorf("version %d: wrong short ID %d - got %x, want %x",
+					version, i, msg.ShortIDs[i], id)
+			}
+		}
+	}
+	// A different nonce must give a different key.
+	a := NewMsgCmpctBlock(&blockOne.Header, 1).ShortIDKey()
+	b := NewMsgCmpctBlock(&blockOne.Header, 2).ShortIDKey()
+	if a == b {
+		t.Errorf("ShortIDKey: same key for different nonces")
+	}
+}
+
+// TestCmpctBlockWire tests the MsgCmpctBlock wire encode and decode for various protocol versions.
+func TestCmpctBlockWire(t *testing.T) {
+	tests := []struct {
+		in   *MsgCmpctBlock // Message to encode
+		out  *MsgCmpctBlock // Expected decoded message
+		buf  []byte         // Wire encoding
+		pver uint32         // Protocol version for wire encoding
+	}{
+		// Latest protocol version.
+		{
+			cmpctBlockOne,
+			cmpctBlockOne,
+			cmpctBlockOneBytes,
+			ProtocolVersion,
+		},
+		// Protocol version SendCmpctVersion.
+		{
+			cmpctBlockOne,
+			cmpctBlockOne,
+			cmpctBlockOneBytes,
+			SendCmpctVersion,
+		},
+	}
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.BtcEncode(&buf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("BtcEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("BtcEncode #%d\n got: %s want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+		// Decode the message from wire format.
+		var msg MsgCmpctBlock
+		rbuf := bytes.NewReader(test.buf)
+		err = msg.BtcDecode(rbuf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("BtcDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("BtcDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
+
+// TestCmpctBlockWireErrors performs negative tests against wire encode and decode of MsgCmpctBlock to confirm error paths work correctly.
+func TestCmpctBlockWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+	pverNoSendCmpct := SendCmpctVersion - 1
+	wireErr := &MessageError{}
+	tests := []struct {
+		in       *MsgCmpctBlock // Value to encode
+		buf      []byte         // Wire encoding
+		pver     uint32         // Protocol version for wire encoding
+		max      int            // Max size of fixed buffer to induce errors
+		writeErr error          // Expected write error
+		readErr  error          // Expected read error
+	}{
+		// Force error in header.
+		{cmpctBlockOne, cmpctBlockOneBytes, pver, 0, io.ErrShortWrite, io.EOF},
+		// Force error in nonce.
+		{cmpctBlockOne, cmpctBlockOneBytes, pver, 80, io.ErrShortWrite, io.EOF},
+		// Force error in short ID count.
+		{cmpctBlockOne, cmpctBlockOneBytes, pver, 88, io.ErrShortWrite, io.EOF},
+		// Force error in short IDs.
+		{cmpctBlockOne, cmpctBlockOneBytes, pver, 89, io.ErrShortWrite, io.EOF},
+		// Force error in prefilled transaction count.
+		{cmpctBlockOne, cmpctBlockOneBytes, pver, 101, io.ErrShortWrite, io.EOF},
+		// Force error in prefilled transaction index.
+		{cmpctBlockOne, cmpctBlockOneBytes, pver, 102, io.ErrShortWrite, io.EOF},
+		// Force error in prefilled transaction.
+		{cmpctBlockOne, cmpctBlockOneBytes, pver, 103, io.ErrShortWrite, io.EOF},
+		// Force error due to unsupported protocol version.
+		{cmpctBlockOne, cmpctBlockOneBytes, pverNoSendCmpct, len(cmpctBlockOneBytes), wireErr, wireErr},
+	}
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.BtcEncode(w, test.pver, BaseEncoding)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.writeErr) {
+			t.Errorf("BtcEncode #%d wrong error got: %v, want: %v",
+				i, err, test.writeErr)
+			continue
+		}
+		// For errors which are not of type MessageError, check them for equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.writeErr {
+				t.Errorf("BtcEncode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.writeErr)
+				continue
+			}
+		}
+		// Decode from wire format.
+		var msg MsgCmpctBlock
+		r := newFixedReader(test.max, test.buf)
+		err = msg.BtcDecode(r, test.pver, BaseEncoding)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.readErr) {
+			t.Errorf("BtcDecode #%d wrong error got: %v, want: %v",
+				i, err, test.readErr)
+			continue
+		}
+		// For errors which are not of type MessageError, check them for equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.readErr {
+				t.Errorf("BtcDecode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.readErr)
+				continue
+			}
+		}
+	}
+}
+
+// TestCmpctBlockOverflowErrors performs tests to ensure decoding compact blocks that are intentionally crafted to use large values for the number of transactions or out of range prefilled indexes are handled properly.
+func TestCmpctBlockOverflowErrors(t *testing.T) {
+	pver := ProtocolVersion
+	header := cmpctBlockOneBytes[:88]
+	withTail := func(tail ...byte) []byte {
+		return append(append([]byte{}, header...), tail...)
+	}
+	tests := []struct {
+		buf []byte // Wire encoding
+	}{
+		// Short ID count that claims to have more than the max transactions per block.
+		{withTail(0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)},
+		// Prefilled count that takes the total over the max transactions per block.
+		{withTail(0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)},
+		// Prefilled index past the end of the block.
+		{withTail(0x00, 0x01, 0x01)},
+	}
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		var msg MsgCmpctBlock
+		r := bytes.NewReader(test.buf)
+		err := msg.BtcDecode(r, pver, BaseEncoding)
+		if _, ok := err.(*MessageError); !ok {
+			t.Errorf("BtcDecode #%d wrong error got: %v, want: %v",
+				i, err, &MessageError{})
+		}
+	}
+	// Prefilled indexes that are not increasing can't be encoded.
+	msg := NewMsgCmpctBlock(&blockOne.Header, 0)
+	msg.PrefilledTxs = []*PrefilledTx{
+		{Index: 1, Tx: blockOne.Transactions[0]},
+		{Index: 1, Tx: blockOne.Transactions[0]},
+	}
+	var buf bytes.Buffer
+	err := msg.BtcEncode(&buf, pver, BaseEncoding)
+	if _, ok := err.(*MessageError); !ok {
+		t.Errorf("BtcEncode wrong error got: %v, want: %v", err,
+			&MessageError{})
+	}
+}
+
+// cmpctBlockOne is a compact block for block one with two made up short IDs.
+var cmpctBlockOne = &MsgCmpctBlock{
+	Header:   blockOne.Header,
+	Nonce:    0x0807060504030201,
+	ShortIDs: []uint64{0x060504030201, 0x0c0b0a090807},
+	PrefilledTxs: []*PrefilledTx{
+		{Index: 0, Tx: blockOne.Transactions[0]},
+	},
+}
+
+// cmpctBlockOneBytes is the serialized bytes of cmpctBlockOne.
+var cmpctBlockOneBytes = append(append(append([]byte{},
+	blockOneBytes[:80]...), // Header
+	0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, // Nonce
+	0x02,                               // Varint for number of short IDs
+	0x01, 0x02, 0x03, 0x04, 0x05, 0x06, // Short ID
+	0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, // Short ID
+	0x01, // Varint for number of prefilled transactions
+	0x00, // Varint for differential index
+),
+	blockOneBytes[81:]..., // Prefilled transaction
+)
@@ -0,0 +1,112 @@
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+)
+
+// MsgGetBlockTxn implements the Message interface and represents a bitcoin getblocktxn message.  It is used to request the transactions of a compact block (MsgCmpctBlock) that the receiver could not find in its mempool, by their indexes in the block, which are answered with a blocktxn message (MsgBlockTxn). This message was not added until protocol versions starting with SendCmpctVersion.
+type MsgGetBlockTxn struct {
+	BlockHash chainhash.Hash
+	Indexes   []uint32
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver. This is part of the Message interface implementation.
+func (msg *MsgGetBlockTxn) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < SendCmpctVersion {
+		str := fmt.Sprintf("getblocktxn message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgGetBlockTxn.BtcDecode", str)
+	}
+	err := readElement(r, &msg.BlockHash)
+	if err != nil {
+		Error(err)
+		return err
+	}
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		Error(err)
+		return err
+	}
+	// Prevent more transactions than could possibly fit into a block. It would be possible to cause memory exhaustion and panics without a sane upper bound on this count.
+	if count > maxTxPerBlock {
+		str := fmt.Sprintf("too many transaction indexes for a block "+
+			"[count %d, max %d]", count, maxTxPerBlock)
+		return messageError("MsgGetBlockTxn.BtcDecode", str)
+	}
+	msg.Indexes = make([]uint32, 0, count)
+	// The indexes are differentially encoded, each as the number of transactions after the previous one.
+	var next uint64
+	for i := uint64(0); i < count; i++ {
+		diff, err := ReadVarInt(r, pver)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		if diff >= maxTxPerBlock-next {
+			str := fmt.Sprintf("transaction index out of range "+
+				"[index %d, max %d]", next+diff, maxTxPerBlock-1)
+			return messageError("MsgGetBlockTxn.BtcDecode", str)
+		}
+		index := next + diff
+		msg.Indexes = append(msg.Indexes, uint32(index))
+		next = index + 1
+	}
+	return nil
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding. This is part of the Message interface implementation.
+func (msg *MsgGetBlockTxn) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < SendCmpctVersion {
+		str := fmt.Sprintf("getblocktxn message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgGetBlockTxn.BtcEncode", str)
+	}
+	err := writeElement(w, &msg.BlockHash)
+	if err != nil {
+		Error(err)
+		return err
+	}
+	err = WriteVarInt(w, pver, uint64(len(msg.Indexes)))
+	if err != nil {
+		Error(err)
+		return err
+	}
+	var next uint32
+	for _, index := range msg.Indexes {
+		if index < next {
+			str := fmt.Sprintf("transaction indexes are not in "+
+				"increasing order [index %d after %d]", index, next)
+			return messageError("MsgGetBlockTxn.BtcEncode", str)
+		}
+		err = WriteVarInt(w, pver, uint64(index-next))
+		if err != nil {
+			Error(err)
+			return err
+		}
+		next = index + 1
+	}
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part of the Message interface implementation.
+func (msg *MsgGetBlockTxn) Command() string {
+	return CmdGetBlockTxn
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the receiver.  This is part of the Message interface implementation.
+func (msg *MsgGetBlockTxn) MaxPayloadLength(pver uint32) uint32 {
+	// Block hash + num indexes (varInt) + max allowed indexes.
+	return chainhash.HashSize + MaxVarIntPayload +
+		maxTxPerBlock*MaxVarIntPayload
+}
+
+// NewMsgGetBlockTxn returns a new bitcoin getblocktxn message that conforms to the Message interface.  See MsgGetBlockTxn for details.
+func NewMsgGetBlockTxn(blockHash *chainhash.Hash, indexes []uint32) *MsgGetBlockTxn {
+	return &MsgGetBlockTxn{
+		BlockHash: *blockHash,
+		Indexes:   indexes,
+	}
+}
@@ -0,0 +1,184 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+)
+
+// TestGetBlockTxn tests the MsgGetBlockTxn API.
+func TestGetBlockTxn(t *testing.T) {
+	pver := ProtocolVersion
+	hash := blockOne.BlockHash()
+	indexes := []uint32{1, 2, 5}
+	msg := NewMsgGetBlockTxn(&hash, indexes)
+	if !msg.BlockHash.IsEqual(&hash) {
+		t.Errorf("NewMsgGetBlockTxn: wrong block hash - got %v, want %v",
+			msg.BlockHash, hash)
+	}
+	if !reflect.DeepEqual(msg.Indexes, indexes) {
+		t.Errorf("NewMsgGetBlockTxn: wrong indexes - got %v, want %v",
+			msg.Indexes, indexes)
+	}
+	// Ensure the command is expected value.
+	wantCmd := "getblocktxn"
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgGetBlockTxn: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+	// Ensure max payload is expected value for latest protocol version. Block hash + num indexes (varInt) + max allowed indexes.
+	wantPayload := uint32(chainhash.HashSize + MaxVarIntPayload +
+		maxTxPerBlock*MaxVarIntPayload)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+}
+
+// TestGetBlockTxnWire tests the MsgGetBlockTxn wire encode and decode for various protocol versions.
+func TestGetBlockTxnWire(t *testing.T) {
+	hash := chainhash.Hash{0x01, 0x02, 0x03}
+	noIndexes := NewMsgGetBlockTxn(&hash, []uint32{})
+	noIndexesEncoded := append(append([]byte{}, hash[:]...),
+		0x00, // Varint for number of indexes
+	)
+	multiIndexes := NewMsgGetBlockTxn(&hash, []uint32{0, 1, 4, 300})
+	multiIndexesEncoded := append(append([]byte{}, hash[:]...),
+		0x04,             // Varint for number of indexes
+		0x00,             // Index 0
+		0x00,             // Index 1
+		0x02,             // Index 4
+		0xfd, 0x27, 0x01, // Index 300
+	)
+	tests := []struct {
+		in   *MsgGetBlockTxn // Message to encode
+		out  *MsgGetBlockTxn // Expected decoded message
+		buf  []byte          // Wire encoding
+		pver uint32          // Protocol version for wire encoding
+	}{
+		// Latest protocol version with no indexes.
+		{noIndexes, noIndexes, noIndexesEncoded, ProtocolVersion},
+		// Latest protocol version with multiple indexes.
+		{multiIndexes, multiIndexes, multiIndexesEncoded, ProtocolVersion},
+		// Protocol version SendCmpctVersion with multiple indexes.
+		{multiIndexes, multiIndexes, multiIndexesEncoded, SendCmpctVersion},
+	}
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.BtcEncode(&buf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("BtcEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("BtcEncode #%d\n got: %s want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+		// Decode the message from wire format.
+		var msg MsgGetBlockTxn
+		rbuf := bytes.NewReader(test.buf)
+		err = msg.BtcDecode(rbuf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("BtcDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("BtcDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
+
+// TestGetBlockTxnWireErrors performs negative tests against wire encode and decode of MsgGetBlockTxn to confirm error paths work correctly.
+func TestGetBlockTxnWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+	pverNoSendCmpct := SendCmpctVersion - 1
+	wireErr := &MessageError{}
+	hash := chainhash.Hash{0x01, 0x02, 0x03}
+	baseGetBlockTxn := NewMsgGetBlockTxn(&hash, []uint32{1, 2})
+	baseGetBlockTxnEncoded := append(append([]byte{}, hash[:]...),
+		0x02, // Varint for number of indexes
+		0x01, // Index 1
+		0x00, // Index 2
+	)
+	// Message with indexes that are not in increasing order.
+	unorderedGetBlockTxn := NewMsgGetBlockTxn(&hash, []uint32{2, 1})
+	// Message that forces an error by having more than the max allowed indexes.
+	maxIndexesEncoded := append(append([]byte{}, hash[:]...),
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	)
+	// Message that forces an error by having an index past the max transactions per block.
+	bigIndexEncoded := append(append([]byte{}, hash[:]...),
+		0x01, 0xfe, 0xff, 0xff, 0xff, 0xff,
+	)
+	tests := []struct {
+		in       *MsgGetBlockTxn // Value to encode
+		buf      []byte          // Wire encoding
+		pver     uint32          // Protocol version for wire encoding
+		max      int             // Max size of fixed buffer to induce errors
+		writeErr error           // Expected write error
+		readErr  error           // Expected read error
+	}{
+		// Force error in block hash.
+		{baseGetBlockTxn, baseGetBlockTxnEncoded, pver, 0, io.ErrShortWrite, io.EOF},
+		// Force error in index count.
+		{baseGetBlockTxn, baseGetBlockTxnEncoded, pver, 32, io.ErrShortWrite, io.EOF},
+		// Force error in indexes.
+		{baseGetBlockTxn, baseGetBlockTxnEncoded, pver, 33, io.ErrShortWrite, io.EOF},
+		// Force error due to unsupported protocol version.
+		{baseGetBlockTxn, baseGetBlockTxnEncoded, pverNoSendCmpct, 35, wireErr, wireErr},
+		// Force error with indexes that are not increasing.
+		{unorderedGetBlockTxn, baseGetBlockTxnEncoded, pver, 35, wireErr, nil},
+		// Force error with greater than max indexes.
+		{baseGetBlockTxn, maxIndexesEncoded, pver, 41, nil, wireErr},
+		// Force error with an index past the max transactions per block.
+		{baseGetBlockTxn, bigIndexEncoded, pver, 38, nil, wireErr},
+	}
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.BtcEncode(w, test.pver, BaseEncoding)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.writeErr) {
+			t.Errorf("BtcEncode #%d wrong error got: %v, want: %v",
+				i, err, test.writeErr)
+			continue
+		}
+		// For errors which are not of type MessageError, check them for equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.writeErr {
+				t.Errorf("BtcEncode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.writeErr)
+				continue
+			}
+		}
+		// Decode from wire format.
+		var msg MsgGetBlockTxn
+		r := newFixedReader(test.max, test.buf)
+		err = msg.BtcDecode(r, test.pver, BaseEncoding)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.readErr) {
+			t.Errorf("BtcDecode #%d wrong error got: %v, want: %v",
+				i, err, test.readErr)
+			continue
+		}
+		// For errors which are not of type MessageError, check them for equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.readErr {
+				t.Errorf("BtcDecode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.readErr)
+				continue
+			}
+		}
+	}
+}
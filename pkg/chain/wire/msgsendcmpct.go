@@ -0,0 +1,58 @@
+package wire
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	// CmpctBlockVersion is the version of compact blocks whose short IDs are made from transaction hashes, and whose transactions are serialized without witness data.
+	CmpctBlockVersion uint64 = 1
+	// CmpctBlockWitnessVersion is the version of compact blocks whose short IDs are made from witness transaction hashes, and whose transactions are serialized with witness data.
+	CmpctBlockWitnessVersion uint64 = 2
+)
+
+// MsgSendCmpct implements the Message interface and represents a bitcoin sendcmpct message.  It is used to tell the peer that compact blocks of the given version are supported, and whether new blocks should be announced with cmpctblock messages without asking first (high-bandwidth mode) rather than with inventory vectors or headers (low-bandwidth mode).  This message was not added until protocol versions starting with SendCmpctVersion.
+type MsgSendCmpct struct {
+	AnnounceUsingCmpctBlock bool
+	CmpctBlockVersion       uint64
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver. This is part of the Message interface implementation.
+func (msg *MsgSendCmpct) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < SendCmpctVersion {
+		str := fmt.Sprintf("sendcmpct message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgSendCmpct.BtcDecode", str)
+	}
+	return readElements(r, &msg.AnnounceUsingCmpctBlock, &msg.CmpctBlockVersion)
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding. This is part of the Message interface implementation.
+func (msg *MsgSendCmpct) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < SendCmpctVersion {
+		str := fmt.Sprintf("sendcmpct message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgSendCmpct.BtcEncode", str)
+	}
+	return writeElements(w, msg.AnnounceUsingCmpctBlock, msg.CmpctBlockVersion)
+}
+
+// Command returns the protocol command string for the message.  This is part of the Message interface implementation.
+func (msg *MsgSendCmpct) Command() string {
+	return CmdSendCmpct
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the receiver.  This is part of the Message interface implementation.
+func (msg *MsgSendCmpct) MaxPayloadLength(pver uint32) uint32 {
+	// Announce flag 1 byte + version 8 bytes.
+	return 9
+}
+
+// NewMsgSendCmpct returns a new bitcoin sendcmpct message that conforms to the Message interface.  See MsgSendCmpct for details.
+func NewMsgSendCmpct(announce bool, version uint64) *MsgSendCmpct {
+	return &MsgSendCmpct{
+		AnnounceUsingCmpctBlock: announce,
+		CmpctBlockVersion:       version,
+	}
+}
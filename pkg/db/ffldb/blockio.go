@@ -202,6 +202,24 @@ func (s *blockStore) deleteFile(fileNum uint32) error {
 	return nil
 }
 
+// pruneFile closes the block file for the passed flat file number if it is open and removes it.  It must not be the current write file.
+func (s *blockStore) pruneFile(fileNum uint32) error {
+	s.obfMutex.Lock()
+	defer s.obfMutex.Unlock()
+	if blockFile, ok := s.openBlockFiles[fileNum]; ok {
+		// Close the file under the write lock for the file in case any readers are currently reading from it so it's not closed out from under them.
+		s.lruMutex.Lock()
+		s.openBlocksLRU.Remove(s.fileNumToLRUElem[fileNum])
+		delete(s.fileNumToLRUElem, fileNum)
+		s.lruMutex.Unlock()
+		blockFile.Lock()
+		_ = blockFile.file.Close()
+		blockFile.Unlock()
+		delete(s.openBlockFiles, fileNum)
+	}
+	return s.deleteFileFunc(fileNum)
+}
+
 // blockFile attempts to return an existing file handle for the passed flat file number if it is already open as well as marking it as most recently used.  It will also open the file when it's not already open subject to the rules described in openFile.
 // NOTE: The returned block file will already have the read lock acquired and the caller MUST call .RUnlock() to release it once it has finished all read operations.  This is necessary because otherwise it would be possible for a separate goroutine to close the file after it is returned from here, but before the caller has acquired a read lock.
 func (s *blockStore) blockFile(fileNum uint32) (*lockableFile, error) {
@@ -494,11 +512,35 @@ func (s *blockStore) handleRollback(oldBlockFileNum, oldBlockOffset uint32) {
 	}
 }
 
+// firstBlockFile returns the number of the oldest flat block file in the database directory, which is only above zero when the older files have been pruned, or -1 if there are none.
+func firstBlockFile(dbPath string) int {
+	names, err := filepath.Glob(filepath.Join(dbPath, "*.fdb"))
+	if err != nil {
+		Trace(err)
+		return -1
+	}
+	first := -1
+	for _, name := range names {
+		var fileNum int
+		if _, err := fmt.Sscanf(filepath.Base(name), blockFilenameTemplate, &fileNum); err != nil {
+			continue
+		}
+		if first == -1 || fileNum < first {
+			first = fileNum
+		}
+	}
+	return first
+}
+
 // scanBlockFiles searches the database directory for all flat block files to find the end of the most recent file.  This position is considered the current write cursor which is also stored in the metadata.  Thus, it is used to detect unexpected shutdowns in the middle of writes so the block files can be reconciled.
 func scanBlockFiles(dbPath string) (int, uint32) {
 	lastFile := -1
 	fileLen := uint32(0)
-	for i := 0; ; i++ {
+	first := firstBlockFile(dbPath)
+	if first == -1 {
+		first = 0
+	}
+	for i := first; ; i++ {
 		filePath := blockFilePath(dbPath, uint32(i))
 		st, err := os.Stat(filePath)
 		if err != nil {
@@ -874,6 +874,8 @@ type transaction struct {
 	// by block hash.
 	pendingBlocks    map[chainhash.Hash]int
 	pendingBlockData []pendingBlock
+	// Block files that need to be deleted on commit.
+	pendingPrune []uint32
 	// Keys that need to be stored or deleted on commit.
 	pendingKeys   *treap.Mutable
 	pendingRemove *treap.Mutable
@@ -1404,6 +1406,100 @@ func // FetchBlockRegions returns the raw serialized bytes for the given
 	return blockRegions, nil
 }
 
+func // PruneBlocks deletes the oldest flat block files until the blocks take
+// no more than the target size in bytes, and returns the hashes of the blocks
+// that were stored in them.  The file of the block with the keep hash, and
+// the files after it, are never deleted.  The blocks are removed from the
+// block index right away, while the files are deleted when the transaction
+// is committed.
+// Returns the following errors as required by the interface contract:
+//   - ErrBlockNotFound if the block to keep does not exist
+//   - ErrTxNotWritable if attempted against a read-only transaction
+//   - ErrTxClosed if the transaction has already been closed
+// This function is part of the database.Tx interface implementation.
+(tx *transaction) PruneBlocks(targetSize uint64, keep *chainhash.Hash) (
+	[]chainhash.Hash, error) {
+	// Ensure transaction state is valid.
+	if err := tx.checkClosed(); err != nil {
+		return nil, err
+	}
+	// Ensure the transaction is writable.
+	if !tx.writable {
+		str := "prune blocks requires a writable database transaction"
+		return nil, makeDbErr(database.ErrTxNotWritable, str, nil)
+	}
+	keepRow, err := tx.fetchBlockRow(keep)
+	if err != nil {
+		return nil, err
+	}
+	keepFileNum := deserializeBlockLoc(keepRow).blockFileNum
+	wc := tx.db.store.writeCursor
+	wc.RLock()
+	curFileNum, curOffset := wc.curFileNum, wc.curOffset
+	wc.RUnlock()
+	first := firstBlockFile(tx.db.store.basePath)
+	if first == -1 {
+		return nil, nil
+	}
+	pending := make(map[uint32]struct{}, len(tx.pendingPrune))
+	for _, fileNum := range tx.pendingPrune {
+		pending[fileNum] = struct{}{}
+	}
+	// Add up the sizes of the files that are not already going to be deleted.
+	sizes := make(map[uint32]uint64)
+	totalSize := uint64(curOffset)
+	for fileNum := uint32(first); fileNum < curFileNum; fileNum++ {
+		if _, ok := pending[fileNum]; ok {
+			continue
+		}
+		st, err := os.Stat(blockFilePath(tx.db.store.basePath, fileNum))
+		if err != nil {
+			str := fmt.Sprintf("failed to stat block file %d: %v", fileNum, err)
+			return nil, makeDbErr(database.ErrDriverSpecific, str, err)
+		}
+		sizes[fileNum] = uint64(st.Size())
+		totalSize += uint64(st.Size())
+	}
+	// Choose the oldest files until enough space would be freed.
+	prune := make(map[uint32]struct{})
+	for fileNum := uint32(first); fileNum < keepFileNum &&
+		fileNum < curFileNum && totalSize > targetSize; fileNum++ {
+		size, ok := sizes[fileNum]
+		if !ok {
+			continue
+		}
+		prune[fileNum] = struct{}{}
+		totalSize -= size
+	}
+	if len(prune) == 0 {
+		return nil, nil
+	}
+	// Remove the blocks stored in the chosen files from the block index.
+	var pruned []chainhash.Hash
+	err = tx.blockIdxBucket.ForEach(func(k, v []byte) error {
+		if _, ok := prune[deserializeBlockLoc(v).blockFileNum]; ok {
+			var hash chainhash.Hash
+			copy(hash[:], k)
+			pruned = append(pruned, hash)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pruned {
+		if err := tx.blockIdxBucket.Delete(pruned[i][:]); err != nil {
+			return nil, err
+		}
+	}
+	for fileNum := range prune {
+		tx.pendingPrune = append(tx.pendingPrune, fileNum)
+	}
+	Debugf("pruning %d block files holding %d blocks, %d bytes remain",
+		len(prune), len(pruned), totalSize)
+	return pruned, nil
+}
+
 func // close marks the transaction closed then releases any pending data,
 // the underlying snapshot, the transaction read lock,
 // and the write lock when the transaction is writable.
@@ -1412,6 +1508,7 @@ func // close marks the transaction closed then releases any pending data,
 	// Clear pending blocks that would have been written on commit.
 	tx.pendingBlocks = nil
 	tx.pendingBlockData = nil
+	tx.pendingPrune = nil
 	// Clear pending keys that would have been written or deleted on commit.
 	tx.pendingKeys = nil
 	tx.pendingRemove = nil
@@ -1509,7 +1606,17 @@ func // Commit commits all changes that have been made to the root metadata
 		return makeDbErr(database.ErrTxNotWritable, str, nil)
 	}
 	// Write pending data.  The function will rollback if any errors occur.
-	return tx.writePendingAndCommit()
+	if err := tx.writePendingAndCommit(); err != nil {
+		return err
+	}
+	// Delete the pruned block files now that the block index no longer
+	// refers to them.  A file that fails to be deleted only wastes space.
+	for _, fileNum := range tx.pendingPrune {
+		if err := tx.db.store.pruneFile(fileNum); err != nil {
+			Warn("failed to delete pruned block file", fileNum, err)
+		}
+	}
+	return nil
 }
 
 func // Rollback undoes all changes that have been made to the root bucket
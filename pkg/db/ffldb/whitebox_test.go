@@ -15,6 +15,7 @@ import (
 	ldberrors "github.com/btcsuite/goleveldb/leveldb/errors"
 
 	chaincfg "github.com/p9c/pod/pkg/chain/config"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
 	"github.com/p9c/pod/pkg/chain/wire"
 	database "github.com/p9c/pod/pkg/db"
 	"github.com/p9c/pod/pkg/util"
@@ -217,6 +218,120 @@ func TestCornerCases(t *testing.T) {
 	}
 }
 
+// TestPruneBlocks ensures the oldest block files are deleted down to the target size, that the blocks in them are removed from the block index, and that the database can be reopened afterwards.
+func TestPruneBlocks(t *testing.T) {
+	t.Parallel()
+	dbPath := filepath.Join(os.TempDir(), "ffldb-prune")
+	_ = os.RemoveAll(dbPath)
+	idb, err := openDB(dbPath, blockDataNet, true)
+	if err != nil {
+		t.Fatalf("openDB: unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dbPath)
+	// Each block takes 93 bytes in a file, so three fit in each.
+	idb.(*db).store.maxBlockFileSize = 300
+	blocks := make([]*util.Block, 20)
+	for i := range blocks {
+		blocks[i] = util.NewBlock(&wire.MsgBlock{
+			Header: wire.BlockHeader{Nonce: uint32(i)},
+		})
+	}
+	err = idb.Update(func(tx database.Tx) error {
+		for _, block := range blocks {
+			if err := tx.StoreBlock(block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StoreBlock: unexpected error: %v", err)
+	}
+	// Pruning requires a writable transaction and a stored block to keep.
+	err = idb.View(func(tx database.Tx) error {
+		_, err := tx.PruneBlocks(0, blocks[19].Hash())
+		return err
+	})
+	if !checkDbError(t, "PruneBlocks: read-only", err, database.ErrTxNotWritable) {
+		return
+	}
+	err = idb.Update(func(tx database.Tx) error {
+		_, err := tx.PruneBlocks(0, &chainhash.Hash{})
+		return err
+	})
+	if !checkDbError(t, "PruneBlocks: unknown block", err, database.ErrBlockNotFound) {
+		return
+	}
+	tests := []struct {
+		target    uint64
+		keep      int
+		wantFirst int
+		wantFile  uint32
+	}{
+		// 6 files of 279 bytes and one of 186 take 1860 bytes, and the
+		// first four must go to get down to 1000.
+		{target: 1000, keep: 19, wantFirst: 12, wantFile: 4},
+		// File 4 is the only one left before the file of block 15.
+		{target: 0, keep: 15, wantFirst: 15, wantFile: 5},
+		// Nothing is left before the file of block 15.
+		{target: 0, keep: 15, wantFirst: 15, wantFile: 5},
+	}
+	pruned := 0
+	for i, test := range tests {
+		var hashes []chainhash.Hash
+		err = idb.Update(func(tx database.Tx) error {
+			var err error
+			hashes, err = tx.PruneBlocks(test.target, blocks[test.keep].Hash())
+			return err
+		})
+		if err != nil {
+			t.Fatalf("PruneBlocks #%d: unexpected error: %v", i, err)
+		}
+		if len(hashes) != test.wantFirst-pruned {
+			t.Fatalf("PruneBlocks #%d: got %d pruned blocks, want %d", i,
+				len(hashes), test.wantFirst-pruned)
+		}
+		pruned = test.wantFirst
+		if first := firstBlockFile(dbPath); first != int(test.wantFile) {
+			t.Fatalf("PruneBlocks #%d: first block file %d, want %d", i, first,
+				test.wantFile)
+		}
+		err = idb.View(func(tx database.Tx) error {
+			for j, block := range blocks {
+				has, err := tx.HasBlock(block.Hash())
+				if err != nil {
+					return err
+				}
+				if has != (j >= test.wantFirst) {
+					return fmt.Errorf("block %d stored %v", j, has)
+				}
+			}
+			_, err := tx.FetchBlock(blocks[test.wantFirst].Hash())
+			return err
+		})
+		if err != nil {
+			t.Fatalf("PruneBlocks #%d: %v", i, err)
+		}
+	}
+	// The write cursor must be found again after the older files are gone.
+	if err = idb.Close(); err != nil {
+		t.Fatalf("Close: unexpected error: %v", err)
+	}
+	idb, err = openDB(dbPath, blockDataNet, false)
+	if err != nil {
+		t.Fatalf("openDB: unexpected error reopening pruned database: %v", err)
+	}
+	defer idb.Close()
+	err = idb.Update(func(tx database.Tx) error {
+		return tx.StoreBlock(util.NewBlock(&wire.MsgBlock{
+			Header: wire.BlockHeader{Nonce: 100},
+		}))
+	})
+	if err != nil {
+		t.Fatalf("StoreBlock: unexpected error after reopening: %v", err)
+	}
+}
+
 // resetDatabase removes everything from the opened database associated with the test context including all metadata and the mock files.
 // nolint
 func resetDatabase(tc *testContext) bool {
@@ -352,6 +352,22 @@ type Tx interface {
 	// additional data copies and allows support for memory-mapped database
 	// implementations.
 	FetchBlockRegions(regions []BlockRegion) ([][]byte, error)
+	// PruneBlocks deletes the oldest stored blocks until the blocks take no
+	// more than the target size in bytes, and returns the hashes of the
+	// blocks that were deleted.  The block with the keep hash and any block
+	// stored after it are never deleted.  Depending on the backend
+	// implementation, blocks may be deleted in groups, and the space is
+	// only freed when the transaction is committed.
+	//
+	// The interface contract guarantees at least the following errors will
+	// be returned (other implementation-specific errors are possible):
+	//   - ErrBlockNotFound if the block to keep does not exist
+	//   - ErrTxNotWritable if attempted against a read-only transaction
+	//   - ErrTxClosed if the transaction has already been closed
+	//
+	// Other errors are possible depending on the implementation.
+	PruneBlocks(targetSize uint64, keep *chainhash.Hash) ([]chainhash.Hash,
+		error)
 	// ******************************************************************
 	// Methods related to both atomic metadata storage and block storage.
 	// ******************************************************************
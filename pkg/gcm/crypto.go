@@ -10,18 +10,31 @@ import (
 // GetCipher returns a GCM cipher given a password string. Note that this cipher
 // must be renewed every 4gb of encrypted data
 func GetCipher(password string) (gcm cipher.AEAD, err error) {
+	return NewCipher(GetKey(password))
+}
+
+// GetKey derives a 256 bit key from a password string
+func GetKey(password string) (key []byte) {
 	bytes := []byte(password)
+	return argon2.IDKey(reverse(bytes), bytes, 1, 64*1024, 4, 32)
+}
+
+// NewCipher returns a GCM cipher for a 128, 192 or 256 bit key
+func NewCipher(key []byte) (gcm cipher.AEAD, err error) {
 	var c cipher.Block
-	if c, err = aes.NewCipher(argon2.IDKey(reverse(bytes), bytes, 1, 64*1024, 4, 32)); Check(err) {
+	if c, err = aes.NewCipher(key); Check(err) {
+		return
 	}
 	if gcm, err = cipher.NewGCM(c); Check(err) {
 	}
 	return
 }
 
-func reverse(b []byte) []byte {
+// reverse returns a reversed copy of a byte slice
+func reverse(b []byte) (r []byte) {
+	r = make([]byte, len(b))
 	for i := range b {
-		b[i] = b[len(b)-1]
+		r[i] = b[len(b)-1-i]
 	}
-	return b
+	return
 }
@@ -7,6 +7,7 @@ import (
 	"math/rand"
 	"net"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/VividCortex/ewma"
@@ -22,7 +23,10 @@ import (
 	"github.com/p9c/pod/pkg/kopachctrl/job"
 	"github.com/p9c/pod/pkg/kopachctrl/p2padvt"
 	"github.com/p9c/pod/pkg/kopachctrl/pause"
+	"github.com/p9c/pod/pkg/kopachctrl/share"
 	"github.com/p9c/pod/pkg/kopachctrl/sol"
+	"github.com/p9c/pod/pkg/kopachctrl/stratum"
+	"github.com/p9c/pod/pkg/metrics"
 	rav "github.com/p9c/pod/pkg/ring"
 	"github.com/p9c/pod/pkg/simplebuffer/Uint16"
 	"github.com/p9c/pod/pkg/transport"
@@ -34,11 +38,14 @@ const (
 	MaxDatagramSize      = 8192
 	UDP4MulticastAddress = "224.0.0.1:11049"
 	BufferSize           = 4096
+	// hashReportInterval is how often the hash rate reported by the kopach
+	// workers is sampled
+	hashReportInterval = time.Second * 3
 )
 
 type Controller struct {
-	multiConn *transport.Channel
-	// uniConn                *transport.Channel
+	multiConn              *transport.Channel
+	uniConn                *transport.Channel
 	active                 atomic.Bool
 	quit                   chan struct{}
 	cx                     *conte.Xt
@@ -60,7 +67,14 @@ type Controller struct {
 	listenPort             int
 	hashCount              atomic.Uint64
 	hashSampleBuf          *rav.BufferUint64
+	hashrate               atomic.Float64
 	lastNonce              int32
+	stratum                *stratum.Server
+	pool                   *pool
+	// templateMx guards the coinbases and transactions of the current
+	// template, which are replaced by each new template while solutions and
+	// stratum jobs are built from them
+	templateMx sync.Mutex
 }
 
 func Run(cx *conte.Xt) (quit chan struct{}) {
@@ -95,12 +109,30 @@ func Run(cx *conte.Xt) (quit chan struct{}) {
 	ctrl.height.Store(0)
 	ctrl.active.Store(false)
 	var err error
+	if *cx.Config.MinerListener != "" {
+		if ctrl.uniConn, err = transport.NewServerChannel("controller",
+			ctrl, *cx.Config.MinerPass, *cx.Config.MinerListener,
+			MaxDatagramSize, handlersMulticast, ctrl.quit); Check(err) {
+			ctrl.uniConn = nil
+		}
+	}
 	ctrl.multiConn, err = transport.NewBroadcastChannel("controller",
 		ctrl, *cx.Config.MinerPass,
 		transport.DefaultPort, MaxDatagramSize, handlersMulticast,
 		ctrl.quit)
 	if err != nil {
 		Error(err)
+		if ctrl.uniConn == nil {
+			close(ctrl.quit)
+			return
+		}
+		// kopach can still connect by unicast
+		Warn("multicast unavailable, only serving unicast kopach on",
+			*cx.Config.MinerListener)
+		ctrl.multiConn = nil
+		err = nil
+	}
+	if err = ctrl.startPool(); Check(err) {
 		close(ctrl.quit)
 		return
 	}
@@ -111,15 +143,34 @@ func Run(cx *conte.Xt) (quit chan struct{}) {
 		ctrl.active.Store(true)
 	}
 	// ctrl.oldBlocks.Store(pauseShards)
+	if *cx.Config.StratumListener != "" {
+		if ctrl.stratum, err = stratum.New(stratum.Config{
+			Listener:  *cx.Config.StratumListener,
+			Forks:     cx.ActiveNet.Forks,
+			Submit:    ctrl.submitStratumBlock,
+			Share:     ctrl.addStratumShare,
+			Authorize: ctrl.authorizeStratumWorker,
+		}, ctrl.quit); Check(err) {
+		} else {
+			ctrl.stratum.Start()
+		}
+	}
 	interrupt.AddHandler(func() {
 		Debug("miner controller shutting down")
 		ctrl.active.Store(false)
-		err := ctrl.multiConn.SendMany(pause.PauseMagic, pauseShards)
+		err := ctrl.sendMany(pause.PauseMagic, pauseShards)
 		if err != nil {
 			Error(err)
 		}
-		if err = ctrl.multiConn.Close(); Check(err) {
+		if ctrl.multiConn != nil {
+			if err = ctrl.multiConn.Close(); Check(err) {
+			}
+		}
+		if ctrl.uniConn != nil {
+			if err = ctrl.uniConn.Close(); Check(err) {
+			}
 		}
+		ctrl.stopPool()
 	})
 	Debug("sending broadcasts to:", UDP4MulticastAddress)
 	err = ctrl.sendNewBlockTemplate()
@@ -132,7 +183,9 @@ func Run(cx *conte.Xt) (quit chan struct{}) {
 	go rebroadcaster(ctrl)
 	go submitter(ctrl)
 	go advertiser(ctrl)
-	ticker := time.NewTicker(time.Second*3)
+	metrics.Register("controller", metrics.CollectorFunc(ctrl.collectMetrics))
+	defer metrics.Unregister("controller")
+	ticker := time.NewTicker(hashReportInterval)
 	cont := true
 	for cont {
 		select {
@@ -144,7 +197,9 @@ func Run(cx *conte.Xt) (quit chan struct{}) {
 					ctrl.active.Store(true)
 				}
 			}
-			Trace("network hashrate", ctrl.HashReport())
+			ctrl.hashrate.Store(ctrl.HashReport() /
+				hashReportInterval.Seconds())
+			Trace("network hashrate", ctrl.hashrate.Load())
 		case <-ctrl.quit:
 			cont = false
 			ctrl.active.Store(false)
@@ -156,6 +211,20 @@ func Run(cx *conte.Xt) (quit chan struct{}) {
 	return
 }
 
+// sendMany sends a message to the multicast channel and to the kopach
+// connected by unicast, if the unicast listener is enabled
+func (c *Controller) sendMany(magic []byte, shards [][]byte) (err error) {
+	if c.multiConn != nil {
+		if err = c.multiConn.SendMany(magic, shards); Check(err) {
+		}
+	}
+	if c.uniConn != nil {
+		if err = c.uniConn.SendMany(magic, shards); Check(err) {
+		}
+	}
+	return
+}
+
 func (c *Controller) HashReport() float64 {
 	c.hashSampleBuf.Add(c.hashCount.Load())
 	av := ewma.NewMovingAverage(15)
@@ -199,60 +268,44 @@ var handlersMulticast = transport.Handlers{
 			return
 		}
 		// Warn(msgBlock.Header.Version)
-		cb, ok := c.coinbases[msgBlock.Header.Version]
+		coinbases, transactions := c.currentTemplate()
+		cb, ok := coinbases[msgBlock.Header.Version]
 		if !ok {
 			Debug("coinbases not found", cb)
 			return
 		}
 		cbs := []*util.Tx{cb}
 		msgBlock.Transactions = []*wire.MsgTx{}
-		txs := append(cbs, c.transactions...)
+		txs := append(cbs, transactions...)
 		for i := range txs {
 			msgBlock.Transactions = append(msgBlock.Transactions, txs[i].MsgTx())
 		}
 		// set old blocks to pause and send pause directly as block is
 		// probably a solution
-		err = c.multiConn.SendMany(pause.PauseMagic, c.pauseShards)
+		err = c.sendMany(pause.PauseMagic, c.pauseShards)
 		if err != nil {
 			Error(err)
 			return
 		}
-		block := util.NewBlock(msgBlock)
-		isOrphan, err := c.cx.RealNode.SyncManager.ProcessBlock(block,
-			blockchain.BFNone)
-		if err != nil {
-			// Anything other than a rule violation is an unexpected error, so log
-			// that error as an internal error.
-			if _, ok := err.(blockchain.RuleError); !ok {
-				Warnf(
-					"Unexpected error while processing block submitted"+
-						" via kopach miner:", err)
-				return
-			} else {
-				Warn("block submitted via kopach miner rejected:", err)
-				if isOrphan {
-					Warn("block is an orphan")
-					return
-				}
-				return
-			}
+		err = c.processBlock(util.NewBlock(msgBlock), "kopach miner")
+		return
+	},
+	// Shares submitted by workers mining for a pool
+	string(share.Magic): func(ctx interface{}, src net.Addr, dst string,
+		b []byte) (err error) {
+		c := ctx.(*Controller)
+		if !c.active.Load() || c.pool == nil {
+			return
+		}
+		s := share.LoadContainer(b)
+		if int(s.GetSenderPort()) != c.listenPort {
+			return
+		}
+		header := s.GetHeader()
+		if err = c.addShare(s.GetAddress(), &header); err != nil {
+			Debug("share rejected:", err)
+			err = nil
 		}
-		Trace("the block was accepted")
-		coinbaseTx := block.MsgBlock().Transactions[0].TxOut[0]
-		prevHeight := block.Height() - 1
-		prevBlock, _ := c.cx.RealNode.Chain.BlockByHeight(prevHeight)
-		prevTime := prevBlock.MsgBlock().Header.Timestamp.Unix()
-		since := block.MsgBlock().Header.Timestamp.Unix() - prevTime
-		bHash := block.MsgBlock().BlockHashWithAlgos(block.Height())
-		Warnf("new block height %d %08x %s%10d %08x %v %s %ds since prev",
-			block.Height(),
-			prevBlock.MsgBlock().Header.Bits,
-			bHash,
-			block.MsgBlock().Header.Timestamp.Unix(),
-			block.MsgBlock().Header.Bits,
-			util.Amount(coinbaseTx.Value),
-			fork.GetAlgoName(block.MsgBlock().Header.Version,
-				block.Height()), since)
 		return
 	},
 	string(p2padvt.Magic): func(ctx interface{}, src net.Addr, dst string,
@@ -308,6 +361,92 @@ var handlersMulticast = transport.Handlers{
 	},
 }
 
+// processBlock submits a solved block to the sync manager and logs the result
+func (c *Controller) processBlock(block *util.Block, source string) (err error) {
+	isOrphan, err := c.cx.RealNode.SyncManager.ProcessBlock(block,
+		blockchain.BFNone)
+	if err != nil {
+		// Anything other than a rule violation is an unexpected error, so log
+		// that error as an internal error.
+		if _, ok := err.(blockchain.RuleError); !ok {
+			Warnf(
+				"Unexpected error while processing block submitted"+
+					" via %s: %v", source, err)
+			return
+		} else {
+			Warn("block submitted via", source, "rejected:", err)
+			if isOrphan {
+				Warn("block is an orphan")
+				return
+			}
+			return
+		}
+	}
+	Trace("the block was accepted")
+	c.blockFound(block)
+	coinbaseTx := block.MsgBlock().Transactions[0].TxOut[0]
+	prevHeight := block.Height() - 1
+	prevBlock, _ := c.cx.RealNode.Chain.BlockByHeight(prevHeight)
+	prevTime := prevBlock.MsgBlock().Header.Timestamp.Unix()
+	since := block.MsgBlock().Header.Timestamp.Unix() - prevTime
+	forks := c.cx.ActiveNet.Forks
+	bHash, err := block.MsgBlock().BlockHashWithAlgos(forks, block.Height())
+	Check(err)
+	Warnf("new block height %d %08x %s%10d %08x %v %s %ds since prev",
+		block.Height(),
+		prevBlock.MsgBlock().Header.Bits,
+		bHash,
+		block.MsgBlock().Header.Timestamp.Unix(),
+		block.MsgBlock().Header.Bits,
+		util.Amount(coinbaseTx.Value),
+		forks.AlgoName(block.MsgBlock().Header.Version,
+			block.Height()), since)
+	return
+}
+
+// submitStratumBlock processes a block assembled by the stratum server from a
+// share that met the network target
+func (c *Controller) submitStratumBlock(msgBlock *wire.MsgBlock) (err error) {
+	if !msgBlock.Header.PrevBlock.IsEqual(&c.cx.RPCServer.Cfg.Chain.
+		BestSnapshot().Hash) {
+		return errors.New("block submitted by stratum miner is stale")
+	}
+	// pause the kopach workers as they are now working on a stale block
+	if err = c.sendMany(pause.PauseMagic, c.pauseShards); Check(err) {
+	}
+	return c.processBlock(util.NewBlock(msgBlock), "stratum")
+}
+
+// updateStratum hands the current template to the stratum server
+func (c *Controller) updateStratum(mC *job.Container,
+	prevBlock chainhash.Hash) {
+	if c.stratum == nil {
+		return
+	}
+	coinbases, transactions := c.currentTemplate()
+	if err := c.stratum.NewJob(mC.GetNewHeight(), prevBlock, mC.GetBitses(),
+		coinbases, transactions); Check(err) {
+	}
+}
+
+// setTemplate replaces the coinbases and transactions of the current template
+func (c *Controller) setTemplate(coinbases map[int32]*util.Tx,
+	transactions []*util.Tx) {
+	c.templateMx.Lock()
+	defer c.templateMx.Unlock()
+	c.coinbases, c.transactions = coinbases, transactions
+}
+
+// currentTemplate returns the coinbases and transactions of the current
+// template. They are replaced rather than changed by new templates, so they
+// can be used after the lock is released.
+func (c *Controller) currentTemplate() (coinbases map[int32]*util.Tx,
+	transactions []*util.Tx) {
+	c.templateMx.Lock()
+	defer c.templateMx.Unlock()
+	return c.coinbases, c.transactions
+}
+
 func (c *Controller) sendNewBlockTemplate() (err error) {
 	template := getNewBlockTemplate(c.cx, c.blockTemplateGenerator)
 	if template == nil {
@@ -316,19 +455,21 @@ func (c *Controller) sendNewBlockTemplate() (err error) {
 		return
 	}
 	msgB := template.Block
-	c.coinbases = make(map[int32]*util.Tx)
-	var fMC job.Container
-	fMC, c.transactions = job.Get(c.cx, util.NewBlock(msgB), p2padvt.Get(c.cx), &c.coinbases)
+	coinbases := make(map[int32]*util.Tx)
+	fMC, transactions := job.Get(c.cx, util.NewBlock(msgB), p2padvt.Get(c.cx), &coinbases)
+	c.setTemplate(coinbases, transactions)
 	jobShards := transport.GetShards(fMC.Data)
 	shardsLen := len(jobShards)
 	if shardsLen < 1 {
 		Warn("jobShards", shardsLen)
 		return fmt.Errorf("jobShards len %d", shardsLen)
 	}
-	err = c.multiConn.SendMany(job.Magic, jobShards)
+	err = c.sendMany(job.Magic, jobShards)
 	if err != nil {
 		Error(err)
 	}
+	c.updateStratum(&fMC, template.Block.Header.PrevBlock)
+	c.addJob(fMC)
 	c.prevHash.Store(&template.Block.Header.PrevBlock)
 	c.oldBlocks.Store(jobShards)
 	c.lastGenerated.Store(time.Now().UnixNano())
@@ -381,7 +522,7 @@ out:
 	for {
 		select {
 		case <-advertismentTicker.C:
-			err := ctrl.multiConn.SendMany(p2padvt.Magic, ad)
+			err := ctrl.sendMany(p2padvt.Magic, ad)
 			if err != nil {
 				Error(err)
 			}
@@ -424,7 +565,7 @@ out:
 			if !ok {
 				Debug("template is nil")
 			}
-			err := c.multiConn.SendMany(job.Magic, oB)
+			err := c.sendMany(job.Magic, oB)
 			if err != nil {
 				Error(err)
 			}
@@ -480,17 +621,13 @@ func (c *Controller) getNotifier() func(n *blockchain.Notification) {
 }
 
 func (c *Controller) UpdateAndSendTemplate() {
-	c.coinbases = make(map[int32]*util.Tx)
 	template := getNewBlockTemplate(c.cx, c.blockTemplateGenerator)
 	if template != nil {
-		c.transactions = []*util.Tx{}
-		for _, v := range template.Block.Transactions[1:] {
-			c.transactions = append(c.transactions, util.NewTx(v))
-		}
 		msgB := template.Block
-		var mC job.Container
-		mC, c.transactions = job.Get(c.cx, util.NewBlock(msgB),
-			p2padvt.Get(c.cx), &c.coinbases)
+		coinbases := make(map[int32]*util.Tx)
+		mC, transactions := job.Get(c.cx, util.NewBlock(msgB),
+			p2padvt.Get(c.cx), &coinbases)
+		c.setTemplate(coinbases, transactions)
 		nH := mC.GetNewHeight()
 		if c.height.Load() < uint64(nH) {
 			Trace("new height", nH)
@@ -498,8 +635,10 @@ func (c *Controller) UpdateAndSendTemplate() {
 		}
 		shards := transport.GetShards(mC.Data)
 		c.oldBlocks.Store(shards)
-		if err := c.multiConn.SendMany(job.Magic, shards); Check(err) {
+		if err := c.sendMany(job.Magic, shards); Check(err) {
 		}
+		c.updateStratum(&mC, template.Block.Header.PrevBlock)
+		c.addJob(mC)
 		c.prevHash.Store(&template.Block.Header.PrevBlock)
 		c.lastGenerated.Store(time.Now().UnixNano())
 		c.lastTxUpdate.Store(time.Now().UnixNano())
@@ -74,7 +74,7 @@ func Get(cx *conte.Xt, mB *util.Block, msg simplebuffer.Serializers, cbs *map[in
 	var err error
 	df, ok := tip.Diffs.Load().(blockchain.TargetBits)
 	if df == nil || !ok ||
-		len(df) != len(fork.List[1].AlgoVers) {
+		len(df) != len(cx.ActiveNet.Forks.Forks[1].AlgoVers) {
 		bitsMap, err = cx.RealNode.Chain.
 			CalcNextRequiredDifficultyPlan9Controller(tip)
 		if err != nil {
@@ -97,14 +97,9 @@ func Get(cx *conte.Xt, mB *util.Block, msg simplebuffer.Serializers, cbs *map[in
 	mTS := make(map[int32]*chainhash.Hash)
 	txs := mB.Transactions()[0]
 	rtx := mB.Transactions()[1:]
-	for i, v := range rtx {
-		txr[i] = v
-	}
+	txr = append(txr, rtx...)
 	nbH := bH
-	if (cx.ActiveNet.Net == wire.MainNet &&
-		nbH == fork.List[1].ActivationHeight) ||
-		(cx.ActiveNet.Net == wire.TestNet3 &&
-			nbH == fork.List[1].TestnetStart) {
+	if cx.ActiveNet.Forks.Disbursement(nbH) != nil {
 		nbH++
 	}
 	for i := range bitsMap {
@@ -176,7 +171,9 @@ func (j *Container) GetHashes() (out map[int32]*chainhash.Hash) {
 	return Hashes.NewHashes().DecodeOne(j.Get(7)).Get()
 }
 
-func (j *Container) String() (s string) {
+// Describe returns the elements of a job as text, naming the algorithms of its
+// targets by the hard fork schedule of the network
+func (j *Container) Describe(forks *fork.Schedule) (s string) {
 	s += fmt.Sprint("\ntype '"+string(Magic)+"' elements:", j.Count())
 	s += "\n"
 	ips := j.GetIPs()
@@ -207,8 +204,7 @@ func (j *Container) String() (s string) {
 	sort.Ints(sortedBitses)
 	for i := range sortedBitses {
 		s += fmt.Sprintf("  %2d %-10v %d %064x", sortedBitses[i],
-			fork.List[fork.GetCurrent(h)].
-				AlgoVers[int32(sortedBitses[i])],
+			forks.Fork(h).AlgoVers[int32(sortedBitses[i])],
 			bitses[int32(sortedBitses[i])],
 			fork.CompactToBig(bitses[int32(sortedBitses[i])]).Bytes())
 		s += "\n"
@@ -0,0 +1,538 @@
+// Package ledger keeps a persistent record of the shares mined by each worker
+// of a controller that is run as a pool, and splits the rewards of the blocks
+// it finds between them by PPLNS or proportionally
+package ledger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+)
+
+const (
+	// PPLNS pays each block over the most recent shares worth the window
+	PPLNS = "pplns"
+	// PROP pays each block over the shares since the previous block
+	PROP = "prop"
+)
+
+var (
+	sharesBucket  = []byte("shares")
+	blocksBucket  = []byte("blocks")
+	paidBucket    = []byte("paid")
+	payoutsBucket = []byte("payouts")
+	pendingBucket = []byte("pending")
+	metaBucket    = []byte("meta")
+	roundKey      = []byte("round")
+)
+
+// Share is a unit of work credited to a worker. The weight is the share's
+// fraction of the work expected to find a block.
+type Share struct {
+	Worker string
+	Weight float64
+	Time   time.Time
+}
+
+// Block is a block found by the pool and the split of its reward
+type Block struct {
+	Hash   chainhash.Hash
+	Height int32
+	Reward int64
+	Splits map[string]int64
+}
+
+// Balance is the account of a worker
+type Balance struct {
+	Worker string
+	// Mature is the unpaid reward from blocks with enough confirmations to
+	// be spent
+	Mature int64
+	// Immature is the reward from blocks still waiting for confirmations
+	Immature int64
+	// Paid is the total that has been paid out
+	Paid int64
+	// Shares is the weight of the worker's shares that the next block will
+	// be split over
+	Shares float64
+}
+
+// Confirmations returns the number of confirmations of a block, or false if
+// it is not in the main chain
+type Confirmations func(hash *chainhash.Hash, height int32) (confs int32,
+	ok bool)
+
+// Ledger is the share and reward database of a pool
+type Ledger struct {
+	mx     sync.Mutex
+	db     *bolt.DB
+	mode   string
+	window float64
+}
+
+// Open opens or creates the ledger database at path. The window is the number
+// of blocks worth of shares that PPLNS splits rewards over
+func Open(path, mode string, window float64) (l *Ledger, err error) {
+	switch mode {
+	case PPLNS, PROP:
+	default:
+		err = fmt.Errorf("unknown payout scheme '%s'", mode)
+		return
+	}
+	if window <= 0 {
+		err = errors.New("PPLNS window must be positive")
+		return
+	}
+	l = &Ledger{mode: mode, window: window}
+	if l.db, err = bolt.Open(path, 0600,
+		&bolt.Options{Timeout: time.Second}); Check(err) {
+		return
+	}
+	err = l.db.Update(func(tx *bolt.Tx) (err error) {
+		for _, b := range [][]byte{sharesBucket, blocksBucket, paidBucket,
+			payoutsBucket, pendingBucket, metaBucket} {
+			if _, err = tx.CreateBucketIfNotExists(b); Check(err) {
+				return
+			}
+		}
+		return
+	})
+	return
+}
+
+// Close closes the database
+func (l *Ledger) Close() error {
+	return l.db.Close()
+}
+
+// Mode returns the payout scheme of the ledger
+func (l *Ledger) Mode() string {
+	return l.mode
+}
+
+// AddShare records a share for a worker
+func (l *Ledger) AddShare(worker string, weight float64) (err error) {
+	if worker == "" || weight <= 0 {
+		return errors.New("invalid share")
+	}
+	return l.db.Update(func(tx *bolt.Tx) (err error) {
+		b := tx.Bucket(sharesBucket)
+		var seq uint64
+		if seq, err = b.NextSequence(); Check(err) {
+			return
+		}
+		return b.Put(uint64Key(seq), encodeShare(&Share{worker, weight,
+			time.Now()}))
+	})
+}
+
+// BlockFound splits the reward of a block found by the pool between the
+// workers with shares in the current window or round, and records it
+func (l *Ledger) BlockFound(hash chainhash.Hash, height int32,
+	reward int64) (blk *Block, err error) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	blk = &Block{Hash: hash, Height: height, Reward: reward}
+	err = l.db.Update(func(tx *bolt.Tx) (err error) {
+		key := blockKey(height, &hash)
+		bb := tx.Bucket(blocksBucket)
+		if bb.Get(key) != nil {
+			return fmt.Errorf("block %s is already recorded", hash)
+		}
+		var weights map[string]float64
+		var first []byte
+		if weights, first, err = l.shares(tx); Check(err) {
+			return
+		}
+		blk.Splits = split(reward, weights)
+		if err = bb.Put(key, encodeBlock(blk)); Check(err) {
+			return
+		}
+		sb := tx.Bucket(sharesBucket)
+		switch l.mode {
+		case PROP:
+			// the next round starts after the last share of this one
+			if last, _ := sb.Cursor().Last(); last != nil {
+				next := uint64Key(binary.BigEndian.Uint64(last) + 1)
+				if err = tx.Bucket(metaBucket).Put(roundKey,
+					next); Check(err) {
+					return
+				}
+				first = next
+			}
+		}
+		// shares before the window or round can never be paid again
+		return prune(sb, first)
+	})
+	return
+}
+
+// shares returns the weight of each worker's shares in the current window or
+// round, and the key of the first share in it
+func (l *Ledger) shares(tx *bolt.Tx) (weights map[string]float64,
+	first []byte, err error) {
+	weights = make(map[string]float64)
+	c := tx.Bucket(sharesBucket).Cursor()
+	switch l.mode {
+	case PPLNS:
+		var total float64
+		for k, v := c.Last(); k != nil && total < l.window; k, v = c.Prev() {
+			var s *Share
+			if s, err = decodeShare(v); Check(err) {
+				return
+			}
+			w := math.Min(s.Weight, l.window-total)
+			weights[s.Worker] += w
+			total += w
+			first = k
+		}
+	case PROP:
+		first = tx.Bucket(metaBucket).Get(roundKey)
+		var k, v []byte
+		if first == nil {
+			k, v = c.First()
+		} else {
+			k, v = c.Seek(first)
+		}
+		for ; k != nil; k, v = c.Next() {
+			var s *Share
+			if s, err = decodeShare(v); Check(err) {
+				return
+			}
+			weights[s.Worker] += s.Weight
+		}
+	}
+	return
+}
+
+// Balances returns the account of every worker that has shares or rewards
+func (l *Ledger) Balances(confs Confirmations, maturity int32) (
+	balances []Balance, err error) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	m := make(map[string]*Balance)
+	get := func(worker string) *Balance {
+		b, ok := m[worker]
+		if !ok {
+			b = &Balance{Worker: worker}
+			m[worker] = b
+		}
+		return b
+	}
+	err = l.db.View(func(tx *bolt.Tx) (err error) {
+		if err = tx.Bucket(blocksBucket).ForEach(func(k,
+			v []byte) (err error) {
+			var blk *Block
+			if blk, err = decodeBlock(v); Check(err) {
+				return
+			}
+			n, ok := confs(&blk.Hash, blk.Height)
+			if !ok {
+				// orphaned blocks pay nothing unless they return to the
+				// main chain
+				return
+			}
+			for w, amt := range blk.Splits {
+				if n >= maturity {
+					get(w).Mature += amt
+				} else {
+					get(w).Immature += amt
+				}
+			}
+			return
+		}); Check(err) {
+			return
+		}
+		if err = tx.Bucket(paidBucket).ForEach(func(k, v []byte) error {
+			b := get(string(k))
+			b.Paid = int64(binary.BigEndian.Uint64(v))
+			b.Mature -= b.Paid
+			return nil
+		}); Check(err) {
+			return
+		}
+		var weights map[string]float64
+		if weights, _, err = l.shares(tx); Check(err) {
+			return
+		}
+		for w, s := range weights {
+			get(w).Shares = s
+		}
+		return
+	})
+	for _, b := range m {
+		balances = append(balances, *b)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Worker < balances[j].Worker
+	})
+	return
+}
+
+// Payouts returns the mature unpaid balances that are at least min
+func (l *Ledger) Payouts(confs Confirmations, maturity int32,
+	min int64) (payouts map[string]int64, err error) {
+	var balances []Balance
+	if balances, err = l.Balances(confs, maturity); Check(err) {
+		return
+	}
+	payouts = make(map[string]int64)
+	for i := range balances {
+		if balances[i].Mature > 0 && balances[i].Mature >= min {
+			payouts[balances[i].Worker] = balances[i].Mature
+		}
+	}
+	return
+}
+
+// BeginPayout records a payout to workers as pending before it is sent, and
+// counts it as paid so the same balances can't be paid again while it is
+// being sent. It returns the id to finish or cancel it with.
+func (l *Ledger) BeginPayout(amounts map[string]int64) (id uint64, err error) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	err = l.db.Update(func(tx *bolt.Tx) (err error) {
+		pb := tx.Bucket(pendingBucket)
+		if id, err = pb.NextSequence(); Check(err) {
+			return
+		}
+		if err = pb.Put(uint64Key(id), encodeAmounts(amounts)); Check(err) {
+			return
+		}
+		return addPaid(tx.Bucket(paidBucket), amounts, 1)
+	})
+	return
+}
+
+// FinishPayout records a pending payout as sent by a transaction
+func (l *Ledger) FinishPayout(id uint64, txid chainhash.Hash) (err error) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	return l.db.Update(func(tx *bolt.Tx) (err error) {
+		pb := tx.Bucket(pendingBucket)
+		v := pb.Get(uint64Key(id))
+		if v == nil {
+			return fmt.Errorf("no pending payout %d", id)
+		}
+		payouts := tx.Bucket(payoutsBucket)
+		if payouts.Get(txid[:]) != nil {
+			return fmt.Errorf("payout %s is already recorded", txid)
+		}
+		if err = payouts.Put(txid[:], v); Check(err) {
+			return
+		}
+		return pb.Delete(uint64Key(id))
+	})
+}
+
+// CancelPayout removes a pending payout that was not sent, so its amounts are
+// owed to the workers again
+func (l *Ledger) CancelPayout(id uint64) (err error) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	return l.db.Update(func(tx *bolt.Tx) (err error) {
+		pb := tx.Bucket(pendingBucket)
+		v := pb.Get(uint64Key(id))
+		if v == nil {
+			return fmt.Errorf("no pending payout %d", id)
+		}
+		var amounts map[string]int64
+		if amounts, err = decodeAmounts(v); Check(err) {
+			return
+		}
+		if err = addPaid(tx.Bucket(paidBucket), amounts, -1); Check(err) {
+			return
+		}
+		return pb.Delete(uint64Key(id))
+	})
+}
+
+// PendingPayouts returns the payouts that were begun and neither finished nor
+// cancelled, which are left when the process stops while sending them. They
+// stay counted as paid until they are cancelled.
+func (l *Ledger) PendingPayouts() (pending map[uint64]map[string]int64,
+	err error) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	pending = make(map[uint64]map[string]int64)
+	err = l.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(pendingBucket).ForEach(func(k, v []byte) (err error) {
+			var amounts map[string]int64
+			if amounts, err = decodeAmounts(v); Check(err) {
+				return
+			}
+			pending[binary.BigEndian.Uint64(k)] = amounts
+			return
+		})
+	})
+	return
+}
+
+// addPaid adds the amounts to, or with a negative sign subtracts them from,
+// the totals paid to the workers
+func addPaid(b *bolt.Bucket, amounts map[string]int64, sign int64) (
+	err error) {
+	for w, amt := range amounts {
+		var paid int64
+		if v := b.Get([]byte(w)); v != nil {
+			paid = int64(binary.BigEndian.Uint64(v))
+		}
+		if paid += sign * amt; paid < 0 {
+			return fmt.Errorf("paid total of %s would be negative", w)
+		}
+		if err = b.Put([]byte(w), uint64Key(uint64(paid))); Check(err) {
+			return
+		}
+	}
+	return
+}
+
+// split divides a reward in proportion to the weights, with the remainder
+// from rounding going to the largest share
+func split(reward int64, weights map[string]float64) (splits map[string]int64) {
+	splits = make(map[string]int64)
+	var total float64
+	var largest string
+	for w, s := range weights {
+		total += s
+		if largest == "" || s > weights[largest] ||
+			(s == weights[largest] && w < largest) {
+			largest = w
+		}
+	}
+	if total <= 0 {
+		return
+	}
+	var paid int64
+	for w, s := range weights {
+		amt := int64(float64(reward) * s / total)
+		splits[w] = amt
+		paid += amt
+	}
+	splits[largest] += reward - paid
+	return
+}
+
+// prune deletes the shares before the key
+func prune(b *bolt.Bucket, first []byte) (err error) {
+	if first == nil {
+		return
+	}
+	c := b.Cursor()
+	for k, _ := c.First(); k != nil && bytes.Compare(k, first) < 0; k, _ =
+		c.First() {
+		if err = c.Delete(); Check(err) {
+			return
+		}
+	}
+	return
+}
+
+func uint64Key(v uint64) (b []byte) {
+	b = make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return
+}
+
+// blockKey orders blocks by height
+func blockKey(height int32, hash *chainhash.Hash) (k []byte) {
+	k = make([]byte, 4+chainhash.HashSize)
+	binary.BigEndian.PutUint32(k, uint32(height))
+	copy(k[4:], hash[:])
+	return
+}
+
+func encodeShare(s *Share) (b []byte) {
+	b = make([]byte, 16+len(s.Worker))
+	binary.BigEndian.PutUint64(b, math.Float64bits(s.Weight))
+	binary.BigEndian.PutUint64(b[8:], uint64(s.Time.UnixNano()))
+	copy(b[16:], s.Worker)
+	return
+}
+
+func decodeShare(b []byte) (s *Share, err error) {
+	if len(b) < 16 {
+		err = errors.New("corrupt share record")
+		return
+	}
+	s = &Share{
+		Weight: math.Float64frombits(binary.BigEndian.Uint64(b)),
+		Time:   time.Unix(0, int64(binary.BigEndian.Uint64(b[8:]))),
+		Worker: string(b[16:]),
+	}
+	return
+}
+
+// encodeAmounts serializes a set of amounts as a count followed by length
+// prefixed worker names and their amounts
+func encodeAmounts(amounts map[string]int64) []byte {
+	var buf bytes.Buffer
+	n := make([]byte, 8)
+	binary.BigEndian.PutUint32(n, uint32(len(amounts)))
+	buf.Write(n[:4])
+	workers := make([]string, 0, len(amounts))
+	for w := range amounts {
+		workers = append(workers, w)
+	}
+	sort.Strings(workers)
+	for _, w := range workers {
+		binary.BigEndian.PutUint16(n, uint16(len(w)))
+		buf.Write(n[:2])
+		buf.WriteString(w)
+		binary.BigEndian.PutUint64(n, uint64(amounts[w]))
+		buf.Write(n)
+	}
+	return buf.Bytes()
+}
+
+func decodeAmounts(b []byte) (amounts map[string]int64, err error) {
+	errCorrupt := errors.New("corrupt amounts record")
+	if len(b) < 4 {
+		return nil, errCorrupt
+	}
+	count := binary.BigEndian.Uint32(b)
+	b = b[4:]
+	amounts = make(map[string]int64, count)
+	for i := uint32(0); i < count; i++ {
+		if len(b) < 2 {
+			return nil, errCorrupt
+		}
+		l := int(binary.BigEndian.Uint16(b))
+		if len(b) < 2+l+8 {
+			return nil, errCorrupt
+		}
+		amounts[string(b[2:2+l])] = int64(binary.BigEndian.Uint64(b[2+l:]))
+		b = b[2+l+8:]
+	}
+	return
+}
+
+func encodeBlock(blk *Block) []byte {
+	b := make([]byte, chainhash.HashSize+12)
+	copy(b, blk.Hash[:])
+	binary.BigEndian.PutUint32(b[chainhash.HashSize:], uint32(blk.Height))
+	binary.BigEndian.PutUint64(b[chainhash.HashSize+4:], uint64(blk.Reward))
+	return append(b, encodeAmounts(blk.Splits)...)
+}
+
+func decodeBlock(b []byte) (blk *Block, err error) {
+	if len(b) < chainhash.HashSize+12 {
+		err = errors.New("corrupt block record")
+		return
+	}
+	blk = &Block{}
+	copy(blk.Hash[:], b)
+	blk.Height = int32(binary.BigEndian.Uint32(b[chainhash.HashSize:]))
+	blk.Reward = int64(binary.BigEndian.Uint64(b[chainhash.HashSize+4:]))
+	blk.Splits, err = decodeAmounts(b[chainhash.HashSize+12:])
+	return
+}
@@ -0,0 +1,431 @@
+package ledger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	bolt "github.com/coreos/bbolt"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+)
+
+// share is a share added to a ledger in a test
+type share struct {
+	worker string
+	weight float64
+}
+
+// openTestLedger opens a ledger in a new temporary directory and returns a
+// function that closes and removes it
+func openTestLedger(t *testing.T, mode string, window float64) (*Ledger,
+	func()) {
+	dir, err := ioutil.TempDir("", "ledger")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	l, err := Open(filepath.Join(dir, "pool.db"), mode, window)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		t.Fatalf("Open: %v", err)
+	}
+	return l, func() {
+		_ = l.Close()
+		_ = os.RemoveAll(dir)
+	}
+}
+
+// addShares adds shares to a ledger in order
+func addShares(t *testing.T, l *Ledger, shares []share) {
+	for _, s := range shares {
+		if err := l.AddShare(s.worker, s.weight); err != nil {
+			t.Fatalf("AddShare(%s, %v): %v", s.worker, s.weight, err)
+		}
+	}
+}
+
+// blockHash returns a distinct block hash for a test block
+func blockHash(n byte) chainhash.Hash {
+	return chainhash.Hash{n}
+}
+
+// countShares returns the number of shares stored in a ledger
+func countShares(t *testing.T, l *Ledger) (n int) {
+	err := l.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(sharesBucket).Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+// TestSplit ensures rewards are split in proportion to the weights, and that
+// the splits always add up to the reward with the remainder from rounding
+// going to the largest weight.
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		reward  int64
+		weights map[string]float64
+		want    map[string]int64
+	}{
+		{
+			name:    "no shares",
+			reward:  5000,
+			weights: map[string]float64{},
+			want:    map[string]int64{},
+		},
+		{
+			name:    "single worker",
+			reward:  5000,
+			weights: map[string]float64{"a": 0.25},
+			want:    map[string]int64{"a": 5000},
+		},
+		{
+			name:    "even split",
+			reward:  5000,
+			weights: map[string]float64{"a": 1, "b": 1},
+			want:    map[string]int64{"a": 2500, "b": 2500},
+		},
+		{
+			name:    "proportional",
+			reward:  1000,
+			weights: map[string]float64{"a": 3, "b": 1},
+			want:    map[string]int64{"a": 750, "b": 250},
+		},
+		{
+			name:    "rounding to the largest",
+			reward:  100,
+			weights: map[string]float64{"a": 1, "b": 2, "c": 1},
+			want:    map[string]int64{"a": 25, "b": 50, "c": 25},
+		},
+		{
+			name:    "rounding remainder",
+			reward:  100,
+			weights: map[string]float64{"a": 1, "b": 1, "c": 1.5},
+			want:    map[string]int64{"a": 28, "b": 28, "c": 44},
+		},
+		{
+			name:    "rounding tie goes to the first worker",
+			reward:  100,
+			weights: map[string]float64{"a": 1, "b": 1, "c": 1},
+			want:    map[string]int64{"a": 34, "b": 33, "c": 33},
+		},
+		{
+			name:    "dust",
+			reward:  2,
+			weights: map[string]float64{"a": 1, "b": 1, "c": 1},
+			want:    map[string]int64{"a": 2, "b": 0, "c": 0},
+		},
+		{
+			name:    "zero weights",
+			reward:  100,
+			weights: map[string]float64{"a": 0},
+			want:    map[string]int64{},
+		},
+	}
+	for _, test := range tests {
+		got := split(test.reward, test.weights)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+			continue
+		}
+		if len(got) == 0 {
+			continue
+		}
+		var sum int64
+		for _, amt := range got {
+			sum += amt
+		}
+		if sum != test.reward {
+			t.Errorf("%s: splits add up to %d, want %d", test.name, sum,
+				test.reward)
+		}
+	}
+}
+
+// TestPPLNSWindow ensures PPLNS splits blocks over the most recent shares
+// worth the window, cutting the oldest share in it short, and prunes the
+// shares that fall out of the window.
+func TestPPLNSWindow(t *testing.T) {
+	tests := []struct {
+		name   string
+		window float64
+		shares []share
+		reward int64
+		want   map[string]int64
+		// kept is the number of shares left after the block
+		kept int
+	}{
+		{
+			name:   "shares within the window",
+			window: 10,
+			shares: []share{{"a", 1}, {"b", 1}, {"a", 2}},
+			reward: 400,
+			want:   map[string]int64{"a": 300, "b": 100},
+			kept:   3,
+		},
+		{
+			name:   "older shares outside the window",
+			window: 2,
+			shares: []share{{"a", 1}, {"a", 1}, {"b", 1}, {"c", 1}},
+			reward: 400,
+			want:   map[string]int64{"b": 200, "c": 200},
+			kept:   2,
+		},
+		{
+			name:   "oldest share cut to the window",
+			window: 2.5,
+			shares: []share{{"a", 1}, {"b", 1}, {"c", 1}},
+			reward: 500,
+			want:   map[string]int64{"a": 100, "b": 200, "c": 200},
+			kept:   3,
+		},
+		{
+			name:   "no shares",
+			window: 2,
+			reward: 500,
+			want:   map[string]int64{},
+		},
+	}
+	for _, test := range tests {
+		func() {
+			l, teardown := openTestLedger(t, PPLNS, test.window)
+			defer teardown()
+			addShares(t, l, test.shares)
+			blk, err := l.BlockFound(blockHash(1), 10, test.reward)
+			if err != nil {
+				t.Errorf("%s: BlockFound: %v", test.name, err)
+				return
+			}
+			if !reflect.DeepEqual(blk.Splits, test.want) {
+				t.Errorf("%s: got splits %v, want %v", test.name, blk.Splits,
+					test.want)
+			}
+			if n := countShares(t, l); n != test.kept {
+				t.Errorf("%s: got %d shares after the block, want %d",
+					test.name, n, test.kept)
+			}
+			// The window slides over the shares that were kept, so the
+			// next block is split over them again.
+			blk, err = l.BlockFound(blockHash(2), 11, test.reward)
+			if err != nil {
+				t.Errorf("%s: BlockFound: %v", test.name, err)
+				return
+			}
+			if !reflect.DeepEqual(blk.Splits, test.want) {
+				t.Errorf("%s: got splits %v for the next block, want %v",
+					test.name, blk.Splits, test.want)
+			}
+		}()
+	}
+}
+
+// TestPROPRounds ensures PROP splits each block over the shares since the
+// block before it, and prunes the shares of the rounds that have been paid.
+func TestPROPRounds(t *testing.T) {
+	l, teardown := openTestLedger(t, PROP, 1)
+	defer teardown()
+	rounds := []struct {
+		shares []share
+		reward int64
+		want   map[string]int64
+	}{
+		{
+			shares: []share{{"a", 1}, {"b", 3}},
+			reward: 400,
+			want:   map[string]int64{"a": 100, "b": 300},
+		},
+		{
+			shares: []share{{"c", 1}},
+			reward: 400,
+			want:   map[string]int64{"c": 400},
+		},
+		{
+			// a round without shares pays nobody
+			reward: 400,
+			want:   map[string]int64{},
+		},
+		{
+			shares: []share{{"a", 0.5}, {"c", 0.5}},
+			reward: 401,
+			want:   map[string]int64{"a": 201, "c": 200},
+		},
+	}
+	for i, round := range rounds {
+		addShares(t, l, round.shares)
+		blk, err := l.BlockFound(blockHash(byte(i)), int32(i+1), round.reward)
+		if err != nil {
+			t.Fatalf("round %d: BlockFound: %v", i, err)
+		}
+		if !reflect.DeepEqual(blk.Splits, round.want) {
+			t.Errorf("round %d: got splits %v, want %v", i, blk.Splits,
+				round.want)
+		}
+		// the shares of a round are not kept after it is paid
+		if n := countShares(t, l); n != 0 {
+			t.Errorf("round %d: got %d shares after the block, want 0", i, n)
+		}
+	}
+	if _, err := l.BlockFound(blockHash(0), 1, 400); err == nil {
+		t.Errorf("recording a block twice: got no error")
+	}
+}
+
+// TestBalances ensures the balances of workers count the rewards of blocks
+// as mature or immature by their confirmations, leave out orphaned blocks,
+// take off what has been paid, and hold the shares of the next block.
+func TestBalances(t *testing.T) {
+	l, teardown := openTestLedger(t, PROP, 1)
+	defer teardown()
+	const maturity = 100
+	// confirmations of the blocks by their first hash byte, blocks not in it
+	// are orphaned
+	confirmations := map[byte]int32{1: 150, 2: 100, 3: 99}
+	confs := func(hash *chainhash.Hash, height int32) (int32, bool) {
+		n, ok := confirmations[hash[0]]
+		return n, ok
+	}
+	blocks := []struct {
+		shares []share
+		reward int64
+	}{
+		// mature
+		{[]share{{"a", 1}, {"b", 1}}, 1000},
+		// mature at exactly the maturity
+		{[]share{{"a", 1}}, 300},
+		// immature
+		{[]share{{"b", 1}, {"c", 3}}, 400},
+		// orphaned
+		{[]share{{"a", 1}, {"c", 1}}, 5000},
+	}
+	for i, blk := range blocks {
+		addShares(t, l, blk.shares)
+		_, err := l.BlockFound(blockHash(byte(i+1)), int32(i+1), blk.reward)
+		if err != nil {
+			t.Fatalf("BlockFound: %v", err)
+		}
+	}
+	addShares(t, l, []share{{"b", 0.5}, {"d", 0.25}})
+	id, err := l.BeginPayout(map[string]int64{"a": 500})
+	if err != nil {
+		t.Fatalf("BeginPayout: %v", err)
+	}
+	if err = l.FinishPayout(id, blockHash(9)); err != nil {
+		t.Fatalf("FinishPayout: %v", err)
+	}
+	want := []Balance{
+		{Worker: "a", Mature: 300, Paid: 500},
+		{Worker: "b", Mature: 500, Immature: 100, Shares: 0.5},
+		{Worker: "c", Immature: 300},
+		{Worker: "d", Shares: 0.25},
+	}
+	got, err := l.Balances(confs, maturity)
+	if err != nil {
+		t.Fatalf("Balances: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got balances %+v, want %+v", got, want)
+	}
+	payoutTests := []struct {
+		min  int64
+		want map[string]int64
+	}{
+		{0, map[string]int64{"a": 300, "b": 500}},
+		{400, map[string]int64{"b": 500}},
+		{501, map[string]int64{}},
+	}
+	for _, test := range payoutTests {
+		payouts, err := l.Payouts(confs, maturity, test.min)
+		if err != nil {
+			t.Fatalf("Payouts: %v", err)
+		}
+		if !reflect.DeepEqual(payouts, test.want) {
+			t.Errorf("payouts of at least %d: got %v, want %v", test.min,
+				payouts, test.want)
+		}
+	}
+}
+
+// TestPayouts ensures a payout is counted as paid from when it is begun, is
+// owed again when it is cancelled, and is recorded once by its transaction
+// when it is finished.
+func TestPayouts(t *testing.T) {
+	l, teardown := openTestLedger(t, PROP, 1)
+	defer teardown()
+	confs := func(hash *chainhash.Hash, height int32) (int32, bool) {
+		return 100, true
+	}
+	addShares(t, l, []share{{"a", 1}, {"b", 1}})
+	if _, err := l.BlockFound(blockHash(1), 1, 1000); err != nil {
+		t.Fatalf("BlockFound: %v", err)
+	}
+	payouts := func() map[string]int64 {
+		p, err := l.Payouts(confs, 100, 1)
+		if err != nil {
+			t.Fatalf("Payouts: %v", err)
+		}
+		return p
+	}
+	pending := func() map[uint64]map[string]int64 {
+		p, err := l.PendingPayouts()
+		if err != nil {
+			t.Fatalf("PendingPayouts: %v", err)
+		}
+		return p
+	}
+	owed := map[string]int64{"a": 500, "b": 500}
+	if got := payouts(); !reflect.DeepEqual(got, owed) {
+		t.Fatalf("got payouts %v, want %v", got, owed)
+	}
+	// A cancelled payout is owed again.
+	id, err := l.BeginPayout(owed)
+	if err != nil {
+		t.Fatalf("BeginPayout: %v", err)
+	}
+	if got := payouts(); len(got) != 0 {
+		t.Errorf("got payouts %v while a payout is pending, want none", got)
+	}
+	want := map[uint64]map[string]int64{id: owed}
+	if got := pending(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got pending payouts %v, want %v", got, want)
+	}
+	if err = l.CancelPayout(id); err != nil {
+		t.Fatalf("CancelPayout: %v", err)
+	}
+	if got := payouts(); !reflect.DeepEqual(got, owed) {
+		t.Errorf("got payouts %v after a cancelled payout, want %v", got,
+			owed)
+	}
+	if err = l.CancelPayout(id); err == nil {
+		t.Errorf("cancelling a payout twice: got no error")
+	}
+	// A finished payout is recorded by its transaction.
+	if id, err = l.BeginPayout(owed); err != nil {
+		t.Fatalf("BeginPayout: %v", err)
+	}
+	txid := blockHash(7)
+	if err = l.FinishPayout(id, txid); err != nil {
+		t.Fatalf("FinishPayout: %v", err)
+	}
+	if got := payouts(); len(got) != 0 {
+		t.Errorf("got payouts %v after paying, want none", got)
+	}
+	if got := pending(); len(got) != 0 {
+		t.Errorf("got pending payouts %v after paying, want none", got)
+	}
+	if err = l.CancelPayout(id); err == nil {
+		t.Errorf("cancelling a finished payout: got no error")
+	}
+	// The same transaction can't pay twice.
+	if id, err = l.BeginPayout(map[string]int64{"a": 1}); err != nil {
+		t.Fatalf("BeginPayout: %v", err)
+	}
+	if err = l.FinishPayout(id, txid); err == nil {
+		t.Errorf("recording a transaction twice: got no error")
+	}
+}
@@ -0,0 +1,18 @@
+package kopachctrl
+
+import (
+	"github.com/p9c/pod/pkg/metrics"
+)
+
+// collectMetrics adds the state of the controller to a metrics scrape
+func (c *Controller) collectMetrics(s *metrics.Scrape) {
+	s.Gauge("pod_controller_hashrate",
+		"Hashes per second reported by the kopach workers of the controller.",
+		c.hashrate.Load())
+	s.Gauge("pod_controller_ready",
+		"Whether the node is current and the controller is ready to mine.",
+		metrics.BoolValue(c.Ready.Load()))
+	s.Gauge("pod_controller_active",
+		"Whether the controller is sending work to the kopach workers.",
+		metrics.BoolValue(c.active.Load()))
+}
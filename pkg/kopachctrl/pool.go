@@ -0,0 +1,298 @@
+package kopachctrl
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	txrules "github.com/p9c/pod/pkg/chain/tx/rules"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/kopachctrl/job"
+	"github.com/p9c/pod/pkg/kopachctrl/ledger"
+	"github.com/p9c/pod/pkg/kopachctrl/share"
+	"github.com/p9c/pod/pkg/rpc/btcjson"
+	"github.com/p9c/pod/pkg/rpc/legacy"
+	"github.com/p9c/pod/pkg/util"
+	"github.com/p9c/pod/pkg/wallet"
+	waddrmgr "github.com/p9c/pod/pkg/wallet/addrmgr"
+)
+
+// poolJobs is the number of recent jobs on the current block that shares are
+// accepted for
+const poolJobs = 8
+
+// pool is the share accounting of a controller that pays the workers mining
+// for it
+type pool struct {
+	mx sync.Mutex
+	// payMx stops concurrent payouts paying the same balances twice
+	payMx  sync.Mutex
+	ledger *ledger.Ledger
+	jobs   []job.Container
+	seen   map[chainhash.Hash]struct{}
+}
+
+// startPool opens the share ledger if a payout scheme is configured and makes
+// it available to the RPC server
+func (c *Controller) startPool() (err error) {
+	if *c.cx.Config.PoolPayout == "" {
+		return
+	}
+	path := filepath.Join(*c.cx.Config.DataDir, c.cx.ActiveNet.Name,
+		"pool.db")
+	p := &pool{seen: make(map[chainhash.Hash]struct{})}
+	if p.ledger, err = ledger.Open(path, *c.cx.Config.PoolPayout,
+		float64(*c.cx.Config.PoolWindow)); Check(err) {
+		return
+	}
+	c.pool = p
+	c.cx.RPCServer.SetPoolLedger(c)
+	Info("pool share accounting by", p.ledger.Mode(), "in", path)
+	var pending map[uint64]map[string]int64
+	if pending, err = p.ledger.PendingPayouts(); Check(err) {
+		return
+	}
+	for id, amounts := range pending {
+		Warn("pool payout", id, "to", len(amounts), "workers was not"+
+			" recorded as sent, it stays counted as paid")
+	}
+	return
+}
+
+// stopPool closes the share ledger
+func (c *Controller) stopPool() {
+	if c.pool == nil {
+		return
+	}
+	c.cx.RPCServer.SetPoolLedger(nil)
+	if err := c.pool.ledger.Close(); Check(err) {
+	}
+}
+
+// addJob adds a job sent to the workers to those shares are accepted for,
+// forgetting the jobs of the previous block when the block changes
+func (c *Controller) addJob(j job.Container) {
+	if c.pool == nil {
+		return
+	}
+	c.pool.mx.Lock()
+	defer c.pool.mx.Unlock()
+	if len(c.pool.jobs) > 0 && !c.pool.jobs[0].GetPrevBlockHash().
+		IsEqual(j.GetPrevBlockHash()) {
+		c.pool.jobs = nil
+		c.pool.seen = make(map[chainhash.Hash]struct{})
+	}
+	c.pool.jobs = append(c.pool.jobs, j)
+	if len(c.pool.jobs) > poolJobs {
+		c.pool.jobs = c.pool.jobs[len(c.pool.jobs)-poolJobs:]
+	}
+}
+
+// addShare credits a share mined by a kopach worker to an address once it is
+// found to be for a current job and to meet the share target
+func (c *Controller) addShare(address string, header *wire.BlockHeader) (
+	err error) {
+	if c.pool == nil {
+		return
+	}
+	var addr util.Address
+	if addr, err = util.DecodeAddress(address, c.cx.ActiveNet); err != nil {
+		return fmt.Errorf("share for invalid address '%s': %v", address, err)
+	}
+	c.pool.mx.Lock()
+	defer c.pool.mx.Unlock()
+	var height int32
+	var found bool
+	for i := range c.pool.jobs {
+		j := &c.pool.jobs[i]
+		if !j.GetPrevBlockHash().IsEqual(&header.PrevBlock) {
+			continue
+		}
+		root, ok := j.GetHashes()[header.Version]
+		if !ok || !root.IsEqual(&header.MerkleRoot) ||
+			j.GetBitses()[header.Version] != header.Bits {
+			continue
+		}
+		height, found = j.GetNewHeight(), true
+		break
+	}
+	if !found {
+		return errors.New("share is not for a current job")
+	}
+	forks := c.cx.ActiveNet.Forks
+	hash, err := header.BlockHashWithAlgos(forks, height)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.pool.seen[hash]; ok {
+		return errors.New("share was already submitted")
+	}
+	if !share.Meets(header, forks, height) {
+		return errors.New("share does not meet the share target")
+	}
+	c.pool.seen[hash] = struct{}{}
+	return c.pool.ledger.AddShare(addr.EncodeAddress(), 1.0/share.Ratio)
+}
+
+// stratumWorkerAddress returns the address that starts a stratum worker name,
+// which is the address the shares of the worker are paid to
+func (c *Controller) stratumWorkerAddress(worker string) (addr util.Address,
+	err error) {
+	address := strings.SplitN(worker, ".", 2)[0]
+	if addr, err = util.DecodeAddress(address, c.cx.ActiveNet); err != nil {
+		err = fmt.Errorf("worker name '%s' does not start with an address"+
+			" of the network: %v", worker, err)
+	}
+	return
+}
+
+// authorizeStratumWorker refuses stratum workers whose shares could not be
+// paid because their name does not start with an address, when the pool
+// ledger is enabled
+func (c *Controller) authorizeStratumWorker(worker string) (err error) {
+	if c.pool == nil {
+		return
+	}
+	_, err = c.stratumWorkerAddress(worker)
+	return
+}
+
+// addStratumShare credits a share accepted by the stratum server to the
+// address that starts the worker name
+func (c *Controller) addStratumShare(worker string, weight float64,
+	block bool) {
+	if c.pool == nil {
+		return
+	}
+	addr, err := c.stratumWorkerAddress(worker)
+	if err != nil {
+		Debug("stratum share for invalid address:", err)
+		return
+	}
+	if err = c.pool.ledger.AddShare(addr.EncodeAddress(), weight); Check(err) {
+	}
+}
+
+// blockFound splits the reward of a block found by the controller's workers
+// between the shares in the ledger
+func (c *Controller) blockFound(block *util.Block) {
+	if c.pool == nil {
+		return
+	}
+	reward := poolReward(block.MsgBlock().Transactions[0],
+		c.cx.StateCfg.ActiveMiningAddrs)
+	blk, err := c.pool.ledger.BlockFound(*block.Hash(), block.Height(),
+		reward)
+	if Check(err) {
+		return
+	}
+	Info("pool block", block.Height(), "reward", util.Amount(reward),
+		"split between", len(blk.Splits), "workers")
+}
+
+// poolReward returns the value of the outputs of a coinbase that pay one of
+// the pool's mining addresses, leaving out those paying anyone else, such as
+// the development and disbursement outputs of the hard fork block
+func poolReward(coinbase *wire.MsgTx, addrs []util.Address) (reward int64) {
+	scripts := make(map[string]struct{}, len(addrs))
+	for _, addr := range addrs {
+		script, err := txscript.PayToAddrScript(addr)
+		if Check(err) {
+			continue
+		}
+		scripts[string(script)] = struct{}{}
+	}
+	for _, out := range coinbase.TxOut {
+		if _, ok := scripts[string(out.PkScript)]; ok {
+			reward += out.Value
+		}
+	}
+	return
+}
+
+// confirmations returns how many confirmations a block the pool found has
+func (c *Controller) confirmations(hash *chainhash.Hash, height int32) (
+	confs int32, ok bool) {
+	chain := c.cx.RealNode.Chain
+	if !chain.MainChainHasBlock(hash) {
+		return
+	}
+	return chain.BestSnapshot().Height - height + 1, true
+}
+
+// PoolBalances returns the account of every worker in the share ledger
+func (c *Controller) PoolBalances() (balances []btcjson.GetPoolBalancesResult,
+	err error) {
+	var b []ledger.Balance
+	if b, err = c.pool.ledger.Balances(c.confirmations,
+		int32(c.cx.ActiveNet.CoinbaseMaturity)); Check(err) {
+		return
+	}
+	balances = make([]btcjson.GetPoolBalancesResult, len(b))
+	for i := range b {
+		balances[i] = btcjson.GetPoolBalancesResult{
+			Address:  b[i].Worker,
+			Balance:  util.Amount(b[i].Mature).ToDUO(),
+			Immature: util.Amount(b[i].Immature).ToDUO(),
+			Paid:     util.Amount(b[i].Paid).ToDUO(),
+			Shares:   b[i].Shares,
+		}
+	}
+	return
+}
+
+// PoolPayout pays the mature balances of at least minAmount from the wallet
+// running in the same process, and records the payment in the ledger
+func (c *Controller) PoolPayout(minAmount util.Amount, dryRun bool) (
+	result btcjson.PoolPayoutResult, err error) {
+	c.pool.payMx.Lock()
+	defer c.pool.payMx.Unlock()
+	var payouts map[string]int64
+	if payouts, err = c.pool.ledger.Payouts(c.confirmations,
+		int32(c.cx.ActiveNet.CoinbaseMaturity), int64(minAmount)); Check(err) {
+		return
+	}
+	result.Payouts = make(map[string]float64, len(payouts))
+	pairs := make(map[string]util.Amount, len(payouts))
+	for addr, amt := range payouts {
+		result.Payouts[addr] = util.Amount(amt).ToDUO()
+		pairs[addr] = util.Amount(amt)
+	}
+	if dryRun || len(payouts) == 0 {
+		return
+	}
+	w := c.cx.WalletServer
+	if w == nil {
+		err = errors.New("pool payouts require the wallet to be running in" +
+			" the same process as the node")
+		return
+	}
+	var outputs []*wire.TxOut
+	if outputs, err = legacy.MakeOutputs(pairs, c.cx.ActiveNet); Check(err) {
+		return
+	}
+	// the payout is recorded before it is sent, so the balances are not paid
+	// again if the process stops before the transaction is recorded
+	var id uint64
+	if id, err = c.pool.ledger.BeginPayout(payouts); Check(err) {
+		return
+	}
+	var txid *chainhash.Hash
+	if txid, err = w.SendOutputs(outputs, waddrmgr.DefaultAccountNum, 1,
+		txrules.DefaultRelayFeePerKb,
+		wallet.CoinSelectionBranchAndBound); Check(err) {
+		if e := c.pool.ledger.CancelPayout(id); Check(e) {
+		}
+		return
+	}
+	if err = c.pool.ledger.FinishPayout(id, *txid); Check(err) {
+		return
+	}
+	result.TxID = txid.String()
+	Info("pool paid", len(payouts), "workers in", txid)
+	return
+}
@@ -0,0 +1,73 @@
+package kopachctrl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// testAddress returns a pay to pubkey hash address made from a repeated byte
+func testAddress(t *testing.T, b byte) util.Address {
+	t.Helper()
+	addr, err := util.NewAddressPubKeyHash(bytes.Repeat([]byte{b}, 20),
+		&netparams.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	return addr
+}
+
+// TestPoolReward ensures only the outputs of a coinbase that pay the pool's
+// mining addresses count towards the reward split between its workers, as in
+// the hard fork block that also pays the development and disbursement outputs.
+func TestPoolReward(t *testing.T) {
+	pool := []util.Address{testAddress(t, 1), testAddress(t, 2)}
+	dev := testAddress(t, 3)
+	pay := func(addr util.Address, value int64) *wire.TxOut {
+		script, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatalf("unable to create script: %v", err)
+		}
+		return wire.NewTxOut(value, script)
+	}
+	multisig, err := txscript.NewScriptBuilder().AddOp(txscript.OP_1).
+		AddData(bytes.Repeat([]byte{2}, 33)).AddOp(txscript.OP_1).
+		AddOp(txscript.OP_CHECKMULTISIG).Script()
+	if err != nil {
+		t.Fatalf("unable to create script: %v", err)
+	}
+	newCoinbase := func(outs ...*wire.TxOut) *wire.MsgTx {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex), []byte{txscript.OP_0}, nil))
+		for _, out := range outs {
+			tx.AddTxOut(out)
+		}
+		return tx
+	}
+	tests := []struct {
+		name     string
+		coinbase *wire.MsgTx
+		addrs    []util.Address
+		want     int64
+	}{
+		{"single output", newCoinbase(pay(pool[0], 5000)), pool, 5000},
+		{"hard fork outputs", newCoinbase(pay(dev, 1000000),
+			wire.NewTxOut(2000000, multisig), pay(pool[1], 5000)), pool, 5000},
+		{"several pool outputs", newCoinbase(pay(pool[0], 3000),
+			pay(dev, 1000), pay(pool[1], 2000)), pool, 5000},
+		{"no pool outputs", newCoinbase(pay(dev, 5000)), pool, 0},
+		{"no addresses", newCoinbase(pay(pool[0], 5000)), nil, 0},
+	}
+	for _, test := range tests {
+		got := poolReward(test.coinbase, test.addrs)
+		if got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
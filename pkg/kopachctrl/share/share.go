@@ -0,0 +1,80 @@
+// Package share is a message type for shares, solutions of a job at a lower
+// target than the network target, that kopach workers send to a controller
+// which keeps a ledger of the work done by each of them
+package share
+
+import (
+	"math/big"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	"github.com/p9c/pod/pkg/chain/fork"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/simplebuffer"
+	"github.com/p9c/pod/pkg/simplebuffer/Block"
+	"github.com/p9c/pod/pkg/simplebuffer/Int32"
+	"github.com/p9c/pod/pkg/simplebuffer/String"
+)
+
+// Magic is the marker for packets containing a share
+var Magic = []byte{'s', 'h', 'a', 'r'}
+
+// Ratio is how many times easier the share target is than the network target,
+// so each share is worth 1/Ratio of a block whichever algorithm it is for
+const Ratio = 256
+
+var maxTarget = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256),
+	big.NewInt(1))
+
+type Container struct {
+	simplebuffer.Container
+}
+
+// Get creates a share message for the header of a solved job, credited to
+// the given address
+func Get(port uint32, address string, header *wire.BlockHeader) Container {
+	mb := &wire.MsgBlock{Header: *header}
+	return Container{*simplebuffer.Serializers{
+		Int32.New().Put(int32(port)),
+		String.New().Put(address),
+		Block.New().Put(mb),
+	}.CreateContainer(Magic)}
+}
+
+// LoadContainer takes a message byte slice payload and loads it into a
+// container ready to be decoded
+func LoadContainer(b []byte) (out Container) {
+	out.Data = b
+	return
+}
+
+func (c *Container) GetSenderPort() int32 {
+	return Int32.New().DecodeOne(c.Get(0)).Get()
+}
+
+func (c *Container) GetAddress() string {
+	return String.New().DecodeOne(c.Get(1)).Get()
+}
+
+func (c *Container) GetHeader() wire.BlockHeader {
+	return Block.New().DecodeOne(c.Get(2)).Get().Header
+}
+
+// Target returns the target a share must meet for a job with the given
+// network target bits
+func Target(bits uint32) (target *big.Int) {
+	target = new(big.Int).Mul(fork.CompactToBig(bits), big.NewInt(Ratio))
+	if target.Cmp(maxTarget) > 0 {
+		target.Set(maxTarget)
+	}
+	return
+}
+
+// Meets returns whether a header is a valid share at a height in the hard fork
+// schedule of a network
+func Meets(header *wire.BlockHeader, forks *fork.Schedule, height int32) bool {
+	hash, err := header.BlockHashWithAlgos(forks, height)
+	if Check(err) {
+		return false
+	}
+	return blockchain.HashToBig(&hash).Cmp(Target(header.Bits)) <= 0
+}
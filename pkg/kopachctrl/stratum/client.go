@@ -0,0 +1,461 @@
+package stratum
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	"github.com/p9c/pod/pkg/chain/fork"
+)
+
+// request is a stratum JSON-RPC request or notification
+type request struct {
+	ID     interface{}     `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// response is a stratum JSON-RPC response
+type response struct {
+	ID     interface{} `json:"id"`
+	Result interface{} `json:"result"`
+	Error  interface{} `json:"error"`
+}
+
+// notification is a server initiated stratum message
+type notification struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+type client struct {
+	s           *Server
+	id          uint32
+	conn        net.Conn
+	wmx         sync.Mutex
+	enc         *json.Encoder
+	mx          sync.Mutex
+	extraNonce1 []byte
+	subscribed  bool
+	authorized  bool
+	worker      string
+	algo        string
+	diff        float64
+	prevDiff    float64
+	vd          *varDiff
+	submitted   map[shareKey]struct{}
+}
+
+// shareKey identifies a share by its decoded parameters, so the same share
+// cannot be credited twice by resubmitting it with different hex formatting
+type shareKey struct {
+	job         string
+	version     int32
+	extraNonce2 [ExtraNonce2Size]byte
+	nTime       uint32
+	nonce       uint32
+}
+
+func newClient(s *Server, conn net.Conn) *client {
+	return &client{
+		s:           s,
+		id:          s.nextClientID.Inc(),
+		conn:        conn,
+		enc:         json.NewEncoder(conn),
+		extraNonce1: s.newExtraNonce1(),
+		diff:        s.cfg.StartDiff,
+		vd: newVarDiff(s.cfg.TargetShareTime, s.cfg.RetargetTime,
+			s.cfg.MinDiff, s.cfg.MaxDiff),
+		submitted: make(map[shareKey]struct{}),
+	}
+}
+
+func (c *client) run() {
+	defer c.s.removeClient(c)
+	defer c.close()
+	scanner := bufio.NewScanner(c.conn)
+	scanner.Buffer(make([]byte, 4096), 1<<16)
+	for {
+		if err := c.conn.SetReadDeadline(time.Now().Add(idleTimeout)); Check(err) {
+			return
+		}
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				Debug("stratum client read error:", err)
+			}
+			return
+		}
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			Debug("malformed stratum request from", c.conn.RemoteAddr(), err)
+			return
+		}
+		c.handle(&req)
+	}
+}
+
+func (c *client) close() {
+	if err := c.conn.Close(); err != nil {
+		Trace(err)
+	}
+}
+
+func (c *client) send(v interface{}) {
+	c.wmx.Lock()
+	defer c.wmx.Unlock()
+	if err := c.conn.SetWriteDeadline(time.Now().Add(time.Second * 10)); Check(err) {
+	}
+	if err := c.enc.Encode(v); err != nil {
+		Debug("stratum client write error:", err)
+		c.close()
+	}
+}
+
+func (c *client) reply(id interface{}, result interface{}, err *RPCError) {
+	r := response{ID: id, Result: result}
+	if err != nil {
+		r.Error = []interface{}{err.Code, err.Message, nil}
+	}
+	c.send(r)
+}
+
+func (c *client) workerName() string {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.worker
+}
+
+func (c *client) handle(req *request) {
+	var params []interface{}
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			c.reply(req.ID, nil, ErrOther)
+			return
+		}
+	}
+	switch req.Method {
+	case "mining.subscribe":
+		c.handleSubscribe(req.ID)
+	case "mining.authorize":
+		c.handleAuthorize(req.ID, params)
+	case "mining.submit":
+		result, err, nd := c.handleSubmit(params)
+		c.reply(req.ID, result, err)
+		// the new difficulty must follow the reply to the share
+		if nd > 0 {
+			c.retarget(nd)
+		}
+	case "mining.extranonce.subscribe":
+		c.reply(req.ID, true, nil)
+	case "mining.suggest_difficulty":
+		c.handleSuggestDifficulty(req.ID, params)
+	default:
+		Debug("unsupported stratum method", req.Method)
+		c.reply(req.ID, nil, ErrOther)
+	}
+}
+
+func (c *client) handleSubscribe(id interface{}) {
+	c.mx.Lock()
+	c.subscribed = true
+	sid := fmt.Sprintf("%08x", c.id)
+	c.mx.Unlock()
+	c.reply(id, []interface{}{
+		[][]string{
+			{"mining.set_difficulty", sid},
+			{"mining.notify", sid},
+		},
+		hex.EncodeToString(c.extraNonce1),
+		ExtraNonce2Size,
+	}, nil)
+}
+
+// handleAuthorize accepts any worker name the Authorize function of the
+// server accepts. The password field may carry comma separated options:
+// a=<algorithm> selects the algorithm to mine and d=<difficulty> sets the
+// starting share difficulty.
+func (c *client) handleAuthorize(id interface{}, params []interface{}) {
+	if len(params) < 1 {
+		c.reply(id, false, ErrUnauthorized)
+		return
+	}
+	worker, _ := params[0].(string)
+	if c.s.cfg.Authorize != nil {
+		if err := c.s.cfg.Authorize(worker); err != nil {
+			Debug("stratum worker refused", worker, c.conn.RemoteAddr(), err)
+			c.reply(id, false, ErrUnauthorized)
+			return
+		}
+	}
+	var pass string
+	if len(params) > 1 {
+		pass, _ = params[1].(string)
+	}
+	c.mx.Lock()
+	c.worker = worker
+	c.authorized = true
+	for _, opt := range strings.Split(pass, ",") {
+		kv := strings.SplitN(strings.TrimSpace(opt), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "a", "algo":
+			c.algo = kv[1]
+		case "d", "diff":
+			if d, err := strconv.ParseFloat(kv[1], 64); err == nil {
+				c.diff = c.clampDiff(d)
+			}
+		}
+	}
+	diff := c.diff
+	c.mx.Unlock()
+	Debug("stratum worker authorized", worker, c.conn.RemoteAddr())
+	c.reply(id, true, nil)
+	c.sendDifficulty(diff)
+	if j := c.s.Current(); j != nil {
+		c.notify(j)
+	}
+}
+
+func (c *client) handleSuggestDifficulty(id interface{}, params []interface{}) {
+	if len(params) > 0 {
+		if d, ok := params[0].(float64); ok {
+			c.mx.Lock()
+			c.prevDiff = c.diff
+			c.diff = c.clampDiff(d)
+			d = c.diff
+			c.mx.Unlock()
+			c.sendDifficulty(d)
+		}
+	}
+	c.reply(id, true, nil)
+}
+
+func (c *client) clampDiff(d float64) float64 {
+	if d < c.s.cfg.MinDiff {
+		return c.s.cfg.MinDiff
+	}
+	if d > c.s.cfg.MaxDiff {
+		return c.s.cfg.MaxDiff
+	}
+	return d
+}
+
+func (c *client) sendDifficulty(diff float64) {
+	c.send(notification{
+		Method: "mining.set_difficulty",
+		Params: []interface{}{diff},
+	})
+}
+
+// version returns the block version this client mines for a job, falling back
+// to the lowest version in the job if the requested algorithm is unknown
+func (c *client) version(j *Job) (ver int32) {
+	c.mx.Lock()
+	algo := c.algo
+	c.mx.Unlock()
+	ver = c.s.cfg.Forks.AlgoVer(algo, j.Height)
+	if _, ok := j.Coinbases[ver]; ok {
+		return
+	}
+	vers := make([]int, 0, len(j.Coinbases))
+	for v := range j.Coinbases {
+		vers = append(vers, int(v))
+	}
+	sort.Ints(vers)
+	return int32(vers[0])
+}
+
+func (c *client) notify(j *Job) {
+	c.mx.Lock()
+	ready := c.subscribed && c.authorized
+	if ready && j.Clean {
+		c.submitted = make(map[shareKey]struct{})
+		c.prevDiff = 0
+	}
+	c.mx.Unlock()
+	if !ready {
+		return
+	}
+	c.send(notification{
+		Method: "mining.notify",
+		Params: j.NotifyParams(c.version(j)),
+	})
+}
+
+// checkDifficulty applies a vardiff retarget if one is due and resends the
+// current job so the miner starts using it
+func (c *client) checkDifficulty(now time.Time) {
+	c.mx.Lock()
+	if !c.authorized {
+		c.mx.Unlock()
+		return
+	}
+	nd, changed := c.vd.check(now, c.diff)
+	if changed {
+		c.prevDiff, c.diff = c.diff, nd
+	}
+	c.mx.Unlock()
+	if changed {
+		c.retarget(nd)
+	}
+}
+
+func (c *client) retarget(diff float64) {
+	Debug("stratum vardiff", c.workerName(), "difficulty", diff)
+	c.sendDifficulty(diff)
+	if j := c.s.Current(); j != nil {
+		c.send(notification{
+			Method: "mining.notify",
+			Params: j.NotifyParams(c.version(j)),
+		})
+	}
+}
+
+// handleSubmit validates a share and submits the block if it meets the
+// network target. The parameters are worker name, job id, extranonce2, ntime
+// and nonce. If vardiff changed the difficulty it is returned in nd.
+func (c *client) handleSubmit(params []interface{}) (ok bool, serr *RPCError,
+	nd float64) {
+	c.mx.Lock()
+	subscribed, authorized := c.subscribed, c.authorized
+	c.mx.Unlock()
+	if !subscribed {
+		return false, ErrNotSubscribed, 0
+	}
+	if !authorized {
+		return false, ErrUnauthorized, 0
+	}
+	if len(params) < 5 {
+		return false, ErrOther, 0
+	}
+	var p [5]string
+	for i := range p {
+		var isString bool
+		if p[i], isString = params[i].(string); !isString {
+			return false, ErrOther, 0
+		}
+	}
+	j, found := c.s.Job(p[1])
+	if !found {
+		return false, ErrJobNotFound, 0
+	}
+	en2, err := hex.DecodeString(p[2])
+	if err != nil || len(en2) != ExtraNonce2Size {
+		return false, ErrOther, 0
+	}
+	nTime, err := parseHex32(p[3])
+	if err != nil {
+		return false, ErrOther, 0
+	}
+	if int64(nTime) < j.Timestamp.Unix()-int64(time.Hour/time.Second) ||
+		int64(nTime) > time.Now().Unix()+int64(2*time.Hour/time.Second) {
+		return false, &RPCError{20, "ntime out of range"}, 0
+	}
+	nonce, err := parseHex32(p[4])
+	if err != nil {
+		return false, ErrOther, 0
+	}
+	ver := c.version(j)
+	key := shareKey{job: j.ID, version: ver, nTime: nTime, nonce: nonce}
+	copy(key.extraNonce2[:], en2)
+	c.mx.Lock()
+	if _, dup := c.submitted[key]; dup {
+		c.mx.Unlock()
+		return false, ErrDuplicate, 0
+	}
+	c.submitted[key] = struct{}{}
+	diff := c.diff
+	if c.prevDiff > 0 && c.prevDiff < diff {
+		diff = c.prevDiff
+	}
+	worker := c.worker
+	c.mx.Unlock()
+	coinbase, err := j.CoinbaseTx(ver, c.extraNonce1, en2)
+	if err != nil {
+		Error(err)
+		return false, ErrOther, 0
+	}
+	header := j.Header(ver, coinbase, nTime, nonce)
+	forks := c.s.cfg.Forks
+	hash, err := header.BlockHashWithAlgos(forks, j.Height)
+	if err != nil {
+		Error(err)
+		return false, ErrOther, 0
+	}
+	hashNum := blockchain.HashToBig(&hash)
+	networkTarget := j.NetworkTarget(ver)
+	target := shareTarget(forks, diff, ver, j.Height)
+	isBlock := hashNum.Cmp(networkTarget) <= 0
+	if !isBlock && hashNum.Cmp(target) > 0 {
+		return false, ErrLowDifficulty, 0
+	}
+	if isBlock {
+		Info("stratum worker", worker, "found block at height", j.Height,
+			forks.AlgoName(ver, j.Height), hash)
+		if err = c.s.cfg.Submit(j.Block(header, coinbase)); err != nil {
+			Warn("block submitted via stratum rejected:", err)
+			isBlock = false
+		}
+	}
+	if c.s.cfg.Share != nil {
+		c.s.cfg.Share(worker, shareWeight(networkTarget, target), isBlock)
+	}
+	c.mx.Lock()
+	if d, changed := c.vd.share(time.Now(), c.diff); changed {
+		c.prevDiff, c.diff = c.diff, d
+		nd = d
+	}
+	c.mx.Unlock()
+	return true, nil, nd
+}
+
+// shareTarget returns the target a share must meet for a share difficulty.
+// Difficulty 1 is the minimum difficulty target of the algorithm.
+func shareTarget(forks *fork.Schedule, diff float64, version int32,
+	height int32) *big.Int {
+	limit := forks.MinDiff(forks.AlgoName(version, height), height)
+	t := new(big.Float).SetInt(limit)
+	t.Quo(t, big.NewFloat(diff))
+	target, _ := t.Int(nil)
+	return target
+}
+
+// shareWeight returns the fraction of the work expected to find a block that a
+// share at the share target represents
+func shareWeight(networkTarget, target *big.Int) (weight float64) {
+	if target.Sign() <= 0 || networkTarget.Cmp(target) >= 0 {
+		return 1
+	}
+	weight, _ = new(big.Float).Quo(new(big.Float).SetInt(networkTarget),
+		new(big.Float).SetInt(target)).Float64()
+	return
+}
+
+func parseHex32(s string) (v uint32, err error) {
+	var b []byte
+	if b, err = hex.DecodeString(s); err != nil {
+		return
+	}
+	if len(b) != 4 {
+		err = fmt.Errorf("expected 4 bytes, got %d", len(b))
+		return
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
@@ -0,0 +1,62 @@
+package stratum
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// TestSubmitDuplicate ensures a share resubmitted with the same values in
+// different hex case is refused as a duplicate and only credited once.
+func TestSubmitDuplicate(t *testing.T) {
+	forks := netparams.RegressionTestParams.Forks
+	var shares int
+	quit := make(chan struct{})
+	s, err := New(Config{
+		Listener: "127.0.0.1:0",
+		Forks:    forks,
+		Submit:   func(mb *wire.MsgBlock) error { return nil },
+		Share: func(worker string, diff float64, block bool) {
+			shares++
+		},
+	}, quit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+	const height = 1
+	ver := forks.AlgoVer("", height)
+	if err = s.NewJob(height, chainhash.Hash{},
+		blockchain.TargetBits{ver: 0x207fffff},
+		map[int32]*util.Tx{ver: util.NewTx(testCoinbase(4))},
+		nil); err != nil {
+		t.Fatal(err)
+	}
+	conn, other := net.Pipe()
+	defer other.Close()
+	c := newClient(s, conn)
+	c.subscribed, c.authorized, c.worker = true, true, "worker"
+	c.diff = 1e-12
+	j := s.Current()
+	nTime := fmt.Sprintf("%08x", uint32(time.Now().Unix()))
+	params := []interface{}{"worker", j.ID, "00ab00cd", nTime, "0000000a"}
+	if _, serr, _ := c.handleSubmit(params); serr != nil {
+		t.Fatalf("first submission refused: %v", serr)
+	}
+	params = []interface{}{"worker", j.ID, "00AB00CD", strings.ToUpper(nTime),
+		"0000000A"}
+	if _, serr, _ := c.handleSubmit(params); serr != ErrDuplicate {
+		t.Fatalf("resubmission returned %v, want %v", serr, ErrDuplicate)
+	}
+	if shares != 1 {
+		t.Fatalf("share credited %d times, want 1", shares)
+	}
+}
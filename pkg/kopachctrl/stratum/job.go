@@ -0,0 +1,255 @@
+package stratum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	"github.com/p9c/pod/pkg/chain/fork"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/util"
+)
+
+const (
+	// ExtraNonce1Size is the number of bytes of the coinbase extranonce that
+	// are assigned by the server to each connection
+	ExtraNonce1Size = 4
+	// ExtraNonce2Size is the number of bytes of the coinbase extranonce that
+	// are rolled by the miner
+	ExtraNonce2Size = 4
+	extraNonceSize  = ExtraNonce1Size + ExtraNonce2Size
+)
+
+// Coinbase is the coinbase transaction for one algorithm version split around
+// the extranonce so the miner can roll its part of it. The parts are the
+// transaction without its witness, as that is what miners hash into the merkle
+// root, so the witness reserved value of the input is kept to be put back in
+// the block.
+type Coinbase struct {
+	Part1   []byte
+	Part2   []byte
+	Witness wire.TxWitness
+}
+
+// Job is a unit of work handed out to stratum clients. It carries everything
+// needed for every algorithm version so each connection can be given the work
+// for the algorithm it is mining, and later reassemble a full block from a
+// share
+type Job struct {
+	ID           string
+	Height       int32
+	PrevBlock    chainhash.Hash
+	Bits         blockchain.TargetBits
+	Coinbases    map[int32]Coinbase
+	Transactions []*util.Tx
+	MerkleBranch []chainhash.Hash
+	Timestamp    time.Time
+	Clean        bool
+}
+
+// NewJob builds a stratum job from the per-version coinbases and the
+// transactions produced by the controller for a block template. The coinbase
+// signature script of each version is extended with a data push placeholder
+// which is where the extranonces are spliced in when a share is submitted.
+func NewJob(id string, height int32, prevBlock chainhash.Hash,
+	bits blockchain.TargetBits, coinbases map[int32]*util.Tx,
+	txs []*util.Tx, clean bool) (j *Job, err error) {
+	j = &Job{
+		ID:           id,
+		Height:       height,
+		PrevBlock:    prevBlock,
+		Bits:         bits,
+		Coinbases:    make(map[int32]Coinbase),
+		Transactions: txs,
+		Timestamp:    time.Now(),
+		Clean:        clean,
+	}
+	for ver, cb := range coinbases {
+		if _, ok := bits[ver]; !ok {
+			continue
+		}
+		var c Coinbase
+		if c, err = splitCoinbase(cb.MsgTx()); Check(err) {
+			return
+		}
+		j.Coinbases[ver] = c
+	}
+	if len(j.Coinbases) < 1 {
+		err = errors.New("no coinbases matching the difficulty targets")
+		return
+	}
+	hashes := make([]chainhash.Hash, len(txs))
+	for i := range txs {
+		hashes[i] = *txs[i].Hash()
+	}
+	j.MerkleBranch = MerkleBranch(hashes)
+	return
+}
+
+// splitCoinbase appends the extranonce placeholder to the coinbase signature
+// script and returns the serialized transaction cut at the placeholder
+func splitCoinbase(tx *wire.MsgTx) (c Coinbase, err error) {
+	if len(tx.TxIn) != 1 {
+		err = errors.New("coinbase must have exactly one input")
+		return
+	}
+	cb := tx.Copy()
+	script := cb.TxIn[0].SignatureScript
+	script = append(script, byte(extraNonceSize))
+	script = append(script, make([]byte, extraNonceSize)...)
+	if len(script) > blockchain.MaxCoinbaseScriptLen {
+		err = fmt.Errorf("coinbase script length %d exceeds maximum %d",
+			len(script), blockchain.MaxCoinbaseScriptLen)
+		return
+	}
+	cb.TxIn[0].SignatureScript = script
+	var buf bytes.Buffer
+	if err = cb.SerializeNoWitness(&buf); Check(err) {
+		return
+	}
+	b := buf.Bytes()
+	// version, input count, previous outpoint, script length then the script
+	offset := 4 + wire.VarIntSerializeSize(1) + chainhash.HashSize + 4 +
+		wire.VarIntSerializeSize(uint64(len(script))) + len(script) -
+		extraNonceSize
+	c.Part1 = append([]byte{}, b[:offset]...)
+	c.Part2 = append([]byte{}, b[offset+extraNonceSize:]...)
+	c.Witness = cb.TxIn[0].Witness
+	return
+}
+
+// MerkleBranch returns the hashes required to compute the merkle root from a
+// coinbase transaction hash, given the hashes of the other transactions in
+// the block in order
+func MerkleBranch(hashes []chainhash.Hash) (branch []chainhash.Hash) {
+	// the first entry stands in for the coinbase and is never read
+	level := append([]chainhash.Hash{{}}, hashes...)
+	for len(level) > 1 {
+		branch = append(branch, level[1])
+		if len(level)%2 != 0 {
+			level = append(level, level[len(level)-1])
+		}
+		next := []chainhash.Hash{{}}
+		for i := 2; i < len(level); i += 2 {
+			next = append(next, *hashMerkleBranches(&level[i], &level[i+1]))
+		}
+		level = next
+	}
+	return
+}
+
+// MerkleRoot computes the merkle root from the coinbase transaction hash and
+// a merkle branch
+func MerkleRoot(coinbase chainhash.Hash, branch []chainhash.Hash) chainhash.Hash {
+	root := coinbase
+	for i := range branch {
+		root = *hashMerkleBranches(&root, &branch[i])
+	}
+	return root
+}
+
+func hashMerkleBranches(left, right *chainhash.Hash) *chainhash.Hash {
+	var h [chainhash.HashSize * 2]byte
+	copy(h[:chainhash.HashSize], left[:])
+	copy(h[chainhash.HashSize:], right[:])
+	nh := chainhash.DoubleHashH(h[:])
+	return &nh
+}
+
+// CoinbaseTx assembles the coinbase for a version with the given extranonces,
+// along with the witness of its input
+func (j *Job) CoinbaseTx(version int32, en1, en2 []byte) (tx *wire.MsgTx,
+	err error) {
+	cb, ok := j.Coinbases[version]
+	if !ok {
+		err = fmt.Errorf("no coinbase for version %d", version)
+		return
+	}
+	if len(en1) != ExtraNonce1Size || len(en2) != ExtraNonce2Size {
+		err = errors.New("incorrect extranonce size")
+		return
+	}
+	raw := make([]byte, 0, len(cb.Part1)+extraNonceSize+len(cb.Part2))
+	raw = append(raw, cb.Part1...)
+	raw = append(raw, en1...)
+	raw = append(raw, en2...)
+	raw = append(raw, cb.Part2...)
+	tx = &wire.MsgTx{}
+	if err = tx.DeserializeNoWitness(bytes.NewReader(raw)); Check(err) {
+		return
+	}
+	if len(cb.Witness) > 0 {
+		witness := make(wire.TxWitness, len(cb.Witness))
+		for i := range cb.Witness {
+			witness[i] = append([]byte{}, cb.Witness[i]...)
+		}
+		tx.TxIn[0].Witness = witness
+	}
+	return
+}
+
+// Header builds the block header for a share
+func (j *Job) Header(version int32, coinbase *wire.MsgTx, nTime,
+	nonce uint32) (h wire.BlockHeader) {
+	return wire.BlockHeader{
+		Version:    version,
+		PrevBlock:  j.PrevBlock,
+		MerkleRoot: MerkleRoot(coinbase.TxHash(), j.MerkleBranch),
+		Timestamp:  time.Unix(int64(nTime), 0),
+		Bits:       j.Bits[version],
+		Nonce:      nonce,
+	}
+}
+
+// Block assembles the full block from a solved header and its coinbase
+func (j *Job) Block(h wire.BlockHeader, coinbase *wire.MsgTx) (
+	mb *wire.MsgBlock) {
+	mb = &wire.MsgBlock{Header: h}
+	mb.Transactions = append(mb.Transactions, coinbase)
+	for i := range j.Transactions {
+		mb.Transactions = append(mb.Transactions, j.Transactions[i].MsgTx())
+	}
+	return
+}
+
+// NetworkTarget returns the full block target for a version
+func (j *Job) NetworkTarget(version int32) *big.Int {
+	return fork.CompactToBig(j.Bits[version])
+}
+
+// NotifyParams returns the parameters of a mining.notify for the algorithm
+// version a client is mining
+func (j *Job) NotifyParams(version int32) (params []interface{}) {
+	cb := j.Coinbases[version]
+	branch := make([]string, len(j.MerkleBranch))
+	for i := range j.MerkleBranch {
+		branch[i] = hex.EncodeToString(j.MerkleBranch[i][:])
+	}
+	return []interface{}{
+		j.ID,
+		encodePrevHash(&j.PrevBlock),
+		hex.EncodeToString(cb.Part1),
+		hex.EncodeToString(cb.Part2),
+		branch,
+		fmt.Sprintf("%08x", uint32(version)),
+		fmt.Sprintf("%08x", j.Bits[version]),
+		fmt.Sprintf("%08x", uint32(j.Timestamp.Unix())),
+		j.Clean,
+	}
+}
+
+// encodePrevHash produces the stratum encoding of the previous block hash,
+// which is the internal byte order with each 32 bit word byte swapped
+func encodePrevHash(h *chainhash.Hash) string {
+	var b [chainhash.HashSize]byte
+	for i := 0; i < chainhash.HashSize; i += 4 {
+		binary.BigEndian.PutUint32(b[i:], binary.LittleEndian.Uint32(h[i:]))
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,214 @@
+package stratum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// testCoinbase returns a coinbase transaction with a signature script of the
+// given length
+func testCoinbase(scriptLen int) *wire.MsgTx {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		SignatureScript: bytes.Repeat([]byte{0x51}, scriptLen),
+		Sequence:        wire.MaxTxInSequenceNum,
+	})
+	tx.AddTxOut(&wire.TxOut{
+		Value:    5000000000,
+		PkScript: []byte{txscript.OP_TRUE},
+	})
+	return tx
+}
+
+// testTxs returns n distinct transactions spending made up outputs
+func testTxs(n int) (txs []*util.Tx) {
+	for i := 0; i < n; i++ {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{byte(i + 1)},
+				uint32(i)),
+			Sequence: wire.MaxTxInSequenceNum,
+		})
+		tx.AddTxOut(&wire.TxOut{
+			Value:    int64(i + 1),
+			PkScript: []byte{txscript.OP_TRUE},
+		})
+		txs = append(txs, util.NewTx(tx))
+	}
+	return
+}
+
+// TestMerkleBranch ensures the merkle root computed from the coinbase hash
+// and the merkle branch of the other transactions is the one the chain
+// computes from all the transactions of the block, for odd and even numbers
+// of transactions.
+func TestMerkleBranch(t *testing.T) {
+	tests := []struct {
+		// others is the number of transactions besides the coinbase
+		others int
+		// branch is the length of the merkle branch
+		branch int
+	}{
+		{0, 0},
+		{1, 1},
+		{2, 2},
+		{3, 2},
+		{4, 3},
+		{5, 3},
+		{6, 3},
+		{7, 3},
+		{8, 4},
+		{12, 4},
+	}
+	coinbase := util.NewTx(testCoinbase(8))
+	for _, test := range tests {
+		txs := testTxs(test.others)
+		hashes := make([]chainhash.Hash, len(txs))
+		for i := range txs {
+			hashes[i] = *txs[i].Hash()
+		}
+		branch := MerkleBranch(hashes)
+		if len(branch) != test.branch {
+			t.Errorf("%d transactions: got a branch of %d hashes, want %d",
+				test.others+1, len(branch), test.branch)
+		}
+		merkles := blockchain.BuildMerkleTreeStore(
+			append([]*util.Tx{coinbase}, txs...), false)
+		want := *merkles[len(merkles)-1]
+		if got := MerkleRoot(*coinbase.Hash(), branch); got != want {
+			t.Errorf("%d transactions: got merkle root %v, want %v",
+				test.others+1, got, want)
+		}
+	}
+}
+
+// TestCoinbaseRoundTrip ensures a coinbase split around the extranonce is put
+// back together with the extranonces of a share at the end of its signature
+// script, and with the rest of the transaction, including the witness reserved
+// value of a coinbase with a witness commitment, unchanged.
+func TestCoinbaseRoundTrip(t *testing.T) {
+	en1 := []byte{0x01, 0x02, 0x03, 0x04}
+	en2 := []byte{0xfa, 0xfb, 0xfc, 0xfd}
+	tests := []struct {
+		name      string
+		scriptLen int
+		witness   bool
+	}{
+		{"short script", 2, false},
+		{"longest script", blockchain.MaxCoinbaseScriptLen - extraNonceSize -
+			1, false},
+		{"witness", 2, true},
+	}
+	const version = 1
+	for _, test := range tests {
+		tx := testCoinbase(test.scriptLen)
+		if test.witness {
+			nonce := bytes.Repeat([]byte{0x42}, blockchain.CoinbaseWitnessDataLen)
+			tx.TxIn[0].Witness = wire.TxWitness{nonce}
+		}
+		c, err := splitCoinbase(tx)
+		if err != nil {
+			t.Errorf("%s: splitCoinbase: %v", test.name, err)
+			continue
+		}
+		j := &Job{Coinbases: map[int32]Coinbase{version: c}}
+		got, err := j.CoinbaseTx(version, en1, en2)
+		if err != nil {
+			t.Errorf("%s: CoinbaseTx: %v", test.name, err)
+			continue
+		}
+		want := tx.Copy()
+		script := append([]byte{}, tx.TxIn[0].SignatureScript...)
+		script = append(script, byte(extraNonceSize))
+		script = append(script, en1...)
+		want.TxIn[0].SignatureScript = append(script, en2...)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got coinbase %v, want %v", test.name, got, want)
+		}
+		if got.WitnessHash() != want.WitnessHash() {
+			t.Errorf("%s: got witness hash %v, want %v", test.name,
+				got.WitnessHash(), want.WitnessHash())
+		}
+		// the coinbase of the next share does not share the witness
+		if test.witness {
+			got.TxIn[0].Witness[0][0] ^= 1
+			if c.Witness[0][0] != tx.TxIn[0].Witness[0][0] {
+				t.Errorf("%s: changing the coinbase witness changed the job",
+					test.name)
+			}
+		}
+		// the coinbase is left unchanged by splitting it
+		if len(tx.TxIn[0].SignatureScript) != test.scriptLen {
+			t.Errorf("%s: splitCoinbase changed the coinbase", test.name)
+		}
+	}
+	j := &Job{Coinbases: map[int32]Coinbase{version: {}}}
+	if _, err := j.CoinbaseTx(version+1, en1, en2); err == nil {
+		t.Errorf("coinbase for a version without one: got no error")
+	}
+	if _, err := j.CoinbaseTx(version, en1, en2[1:]); err == nil {
+		t.Errorf("coinbase with a short extranonce: got no error")
+	}
+}
+
+// TestSplitCoinbaseErrors ensures coinbases that can't carry an extranonce
+// are refused.
+func TestSplitCoinbaseErrors(t *testing.T) {
+	twoInputs := testCoinbase(2)
+	twoInputs.AddTxIn(twoInputs.TxIn[0])
+	tests := []struct {
+		name string
+		tx   *wire.MsgTx
+		want string
+	}{
+		{"two inputs", twoInputs, "exactly one input"},
+		{"script too long", testCoinbase(blockchain.MaxCoinbaseScriptLen -
+			extraNonceSize), "exceeds maximum"},
+	}
+	for _, test := range tests {
+		_, err := splitCoinbase(test.tx)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %v, want one containing %q", test.name,
+				err, test.want)
+		}
+	}
+}
+
+// TestEncodePrevHash ensures the previous block hash is sent to miners with
+// each 32 bit word of it byte swapped.
+func TestEncodePrevHash(t *testing.T) {
+	var h chainhash.Hash
+	for i := range h {
+		h[i] = byte(i)
+	}
+	want := "03020100070605040b0a09080f0e0d0c" +
+		"13121110171615141b1a19181f1e1d1c"
+	if got := encodePrevHash(&h); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	// swapping the words again gives back the hash
+	b, err := hex.DecodeString(encodePrevHash(&h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var swapped chainhash.Hash
+	copy(swapped[:], b)
+	b, err = hex.DecodeString(encodePrevHash(&swapped))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, h[:]) {
+		t.Errorf("got %x swapping twice, want %x", b, h[:])
+	}
+}
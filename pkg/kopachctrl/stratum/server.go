@@ -0,0 +1,283 @@
+// Package stratum is a Stratum v1 mining server that hands out work built from
+// the block templates of the kopach work controller, so that miners which are
+// not on the controller's multicast segment can mine any of the algorithms of
+// the current hard fork
+package stratum
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	"github.com/p9c/pod/pkg/chain/fork"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/util"
+)
+
+const (
+	// DefaultStartDiff is the share difficulty given to new connections
+	DefaultStartDiff = 1024
+	// DefaultMinDiff is the lowest share difficulty vardiff will set
+	DefaultMinDiff = 1
+	// DefaultMaxDiff is the highest share difficulty vardiff will set
+	DefaultMaxDiff = 1 << 48
+	// DefaultTargetShareTime is the interval between shares vardiff aims for
+	DefaultTargetShareTime = time.Second * 10
+	// DefaultRetargetTime is how often vardiff reconsiders the difficulty
+	DefaultRetargetTime = time.Second * 90
+	// maxJobs is the number of recent jobs that shares are accepted for
+	maxJobs = 16
+	// idleTimeout disconnects clients that have not sent anything for this
+	// long
+	idleTimeout = time.Minute * 10
+)
+
+// Config is the configuration for a stratum server
+type Config struct {
+	// Listener is the address the server listens on
+	Listener string
+	// Forks is the hard fork schedule of the network, which gives the
+	// algorithms shares are hashed with and their minimum targets
+	Forks *fork.Schedule
+	// Submit is called with blocks assembled from shares that meet the
+	// network target
+	Submit func(mb *wire.MsgBlock) (err error)
+	// Authorize is called with the worker name of every mining.authorize
+	// request, and the worker is refused if it returns an error. It may be
+	// nil to accept any worker name.
+	Authorize func(worker string) error
+	// Share is called for every accepted share, with the worker name and the
+	// fraction of the work expected to find a block that the share is worth.
+	// It may be nil.
+	Share           func(worker string, diff float64, block bool)
+	StartDiff       float64
+	MinDiff         float64
+	MaxDiff         float64
+	TargetShareTime time.Duration
+	RetargetTime    time.Duration
+}
+
+// Server is a Stratum v1 server
+type Server struct {
+	cfg            Config
+	listener       net.Listener
+	mx             sync.Mutex
+	clients        map[uint32]*client
+	jobs           map[string]*Job
+	jobOrder       []string
+	current        *Job
+	nextClientID   atomic.Uint32
+	nextJobID      atomic.Uint64
+	nextExtraNonce atomic.Uint32
+	quit           chan struct{}
+}
+
+// New creates a stratum server listening on the configured address
+func New(cfg Config, quit chan struct{}) (s *Server, err error) {
+	if cfg.Submit == nil {
+		err = errors.New("stratum server requires a block submit function")
+		return
+	}
+	if cfg.Forks == nil {
+		err = errors.New("stratum server requires a hard fork schedule")
+		return
+	}
+	if cfg.StartDiff <= 0 {
+		cfg.StartDiff = DefaultStartDiff
+	}
+	if cfg.MinDiff <= 0 {
+		cfg.MinDiff = DefaultMinDiff
+	}
+	if cfg.MaxDiff <= 0 {
+		cfg.MaxDiff = DefaultMaxDiff
+	}
+	if cfg.TargetShareTime <= 0 {
+		cfg.TargetShareTime = DefaultTargetShareTime
+	}
+	if cfg.RetargetTime <= 0 {
+		cfg.RetargetTime = DefaultRetargetTime
+	}
+	s = &Server{
+		cfg:     cfg,
+		clients: make(map[uint32]*client),
+		jobs:    make(map[string]*Job),
+		quit:    quit,
+	}
+	// start extranonces from a time based value so they are unlikely to
+	// collide with work handed out before a restart
+	s.nextExtraNonce.Store(uint32(time.Now().Unix()))
+	if s.listener, err = net.Listen("tcp", cfg.Listener); Check(err) {
+		return
+	}
+	return
+}
+
+// Start begins accepting connections and retargeting share difficulty
+func (s *Server) Start() {
+	Info("stratum server listening on", s.listener.Addr())
+	go s.acceptLoop()
+	go s.retargetLoop()
+}
+
+// Stop closes the listener and disconnects all clients
+func (s *Server) Stop() {
+	if err := s.listener.Close(); Check(err) {
+	}
+	s.mx.Lock()
+	for _, c := range s.clients {
+		c.close()
+	}
+	s.mx.Unlock()
+}
+
+// Addr returns the address the server is listening on
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// NewJob creates a job from a new block template and sends it to all
+// subscribed clients. If the previous block changed the job is marked clean
+// and all older jobs are discarded.
+func (s *Server) NewJob(height int32, prevBlock chainhash.Hash,
+	bits blockchain.TargetBits, coinbases map[int32]*util.Tx,
+	txs []*util.Tx) (err error) {
+	s.mx.Lock()
+	clean := s.current == nil || !s.current.PrevBlock.IsEqual(&prevBlock)
+	id := strconv.FormatUint(s.nextJobID.Inc(), 16)
+	var j *Job
+	if j, err = NewJob(id, height, prevBlock, bits, coinbases, txs,
+		clean); Check(err) {
+		s.mx.Unlock()
+		return
+	}
+	if clean {
+		s.jobs = make(map[string]*Job)
+		s.jobOrder = s.jobOrder[:0]
+	}
+	s.jobs[id] = j
+	s.jobOrder = append(s.jobOrder, id)
+	if len(s.jobOrder) > maxJobs {
+		delete(s.jobs, s.jobOrder[0])
+		s.jobOrder = s.jobOrder[1:]
+	}
+	s.current = j
+	clients := make([]*client, 0, len(s.clients))
+	for _, c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.mx.Unlock()
+	Trace("new stratum job", id, "height", height, "clean", clean)
+	for _, c := range clients {
+		c.notify(j)
+	}
+	return
+}
+
+// Job returns a recent job by its id
+func (s *Server) Job(id string) (j *Job, ok bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	j, ok = s.jobs[id]
+	return
+}
+
+// Current returns the most recent job
+func (s *Server) Current() (j *Job) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.current
+}
+
+// ClientCount returns the number of connected clients
+func (s *Server) ClientCount() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return len(s.clients)
+}
+
+func (s *Server) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+			default:
+				Debug("stratum listener stopped:", err)
+			}
+			return
+		}
+		c := newClient(s, conn)
+		s.mx.Lock()
+		s.clients[c.id] = c
+		s.mx.Unlock()
+		Debug("stratum client connected", conn.RemoteAddr())
+		go c.run()
+	}
+}
+
+func (s *Server) removeClient(c *client) {
+	s.mx.Lock()
+	delete(s.clients, c.id)
+	s.mx.Unlock()
+	Debug("stratum client disconnected", c.conn.RemoteAddr(), c.workerName())
+}
+
+// retargetLoop lowers the difficulty of connections that have stopped
+// producing shares, which share submission alone can never do
+func (s *Server) retargetLoop() {
+	ticker := time.NewTicker(s.cfg.RetargetTime / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.mx.Lock()
+			clients := make([]*client, 0, len(s.clients))
+			for _, c := range s.clients {
+				clients = append(clients, c)
+			}
+			s.mx.Unlock()
+			now := time.Now()
+			for _, c := range clients {
+				c.checkDifficulty(now)
+			}
+		case <-s.quit:
+			s.Stop()
+			return
+		}
+	}
+}
+
+// newExtraNonce1 returns a unique extranonce for a new connection
+func (s *Server) newExtraNonce1() []byte {
+	en := make([]byte, ExtraNonce1Size)
+	binary.BigEndian.PutUint32(en, s.nextExtraNonce.Inc())
+	return en
+}
+
+// RPCError is a stratum protocol error as returned in responses
+type RPCError struct {
+	Code    int
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
+
+// Stratum protocol errors
+var (
+	ErrOther         = &RPCError{20, "Other/Unknown"}
+	ErrJobNotFound   = &RPCError{21, "Job not found (=stale)"}
+	ErrDuplicate     = &RPCError{22, "Duplicate share"}
+	ErrLowDifficulty = &RPCError{23, "Low difficulty share"}
+	ErrUnauthorized  = &RPCError{24, "Unauthorized worker"}
+	ErrNotSubscribed = &RPCError{25, "Not subscribed"}
+)
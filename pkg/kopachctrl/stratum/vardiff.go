@@ -0,0 +1,58 @@
+package stratum
+
+import (
+	"math"
+	"time"
+)
+
+// varDiff tracks the rate of shares arriving from a connection and computes
+// difficulty adjustments that keep the share rate near the target interval
+type varDiff struct {
+	target   time.Duration
+	retarget time.Duration
+	min, max float64
+	start    time.Time
+	count    int
+}
+
+func newVarDiff(target, retarget time.Duration, min, max float64) *varDiff {
+	return &varDiff{
+		target:   target,
+		retarget: retarget,
+		min:      min,
+		max:      max,
+		start:    time.Now(),
+	}
+}
+
+// share records a share and returns the adjusted difficulty if one is due
+func (v *varDiff) share(now time.Time, diff float64) (nd float64,
+	changed bool) {
+	v.count++
+	return v.check(now, diff)
+}
+
+// check computes a new difficulty once the retarget interval has elapsed. A
+// connection that has not produced any shares in that time has its
+// difficulty lowered by the maximum step.
+func (v *varDiff) check(now time.Time, diff float64) (nd float64,
+	changed bool) {
+	elapsed := now.Sub(v.start)
+	if elapsed < v.retarget {
+		return diff, false
+	}
+	ratio := 0.25
+	if v.count > 0 {
+		average := elapsed / time.Duration(v.count)
+		ratio = float64(v.target) / float64(average)
+	}
+	ratio = math.Max(0.25, math.Min(4, ratio))
+	nd = math.Max(v.min, math.Min(v.max, diff*ratio))
+	v.start = now
+	v.count = 0
+	// small adjustments are not worth interrupting the miner for
+	if math.Abs(nd/diff-1) < 0.1 {
+		return diff, false
+	}
+	return nd, true
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds in seconds of the buckets that
+// latencies are counted in.
+var LatencyBuckets = []float64{
+	.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30,
+}
+
+// Histogram counts observed values, such as latencies, in buckets, separately
+// for each combination of the values of its labels.
+type Histogram struct {
+	name       string
+	help       string
+	labelNames []string
+	buckets    []float64
+	mtx        sync.Mutex
+	series     map[string]*histogramSeries
+}
+
+// histogramSeries holds the counts of one combination of label values.
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64
+	count       uint64
+	sum         float64
+}
+
+// NewHistogram returns a Histogram counting values in the buckets with the
+// given upper bounds, which must be in increasing order, for each combination
+// of values of the named labels.
+func NewHistogram(name, help string, buckets []float64,
+	labelNames ...string) *Histogram {
+	return &Histogram{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		buckets:    buckets,
+		series:     make(map[string]*histogramSeries),
+	}
+}
+
+// Observe counts a value with the given label values, one for each label of
+// the histogram.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{
+			labelValues: labelValues,
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+// ObserveSince counts the seconds that have passed since a time with the
+// given label values.
+func (h *Histogram) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// Collect adds the bucket counts, count and sum of each combination of label
+// values to a scrape.
+func (h *Histogram) Collect(s *Scrape) {
+	f := s.family(h.name, h.help, TypeHistogram)
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		series := h.series[key]
+		labels := make([]string, 0, 2*len(h.labelNames)+2)
+		for i, name := range h.labelNames {
+			var value string
+			if i < len(series.labelValues) {
+				value = series.labelValues[i]
+			}
+			labels = append(labels, name, value)
+		}
+		for i, bound := range h.buckets {
+			f.sample(h.name+"_bucket", float64(series.counts[i]),
+				append(labels, "le", formatBound(bound)))
+		}
+		f.sample(h.name+"_bucket", float64(series.count),
+			append(labels, "le", "+Inf"))
+		f.sample(h.name+"_sum", series.sum, labels)
+		f.sample(h.name+"_count", float64(series.count), labels)
+	}
+}
+
+// formatBound formats the upper bound of a bucket for its le label.
+func formatBound(bound float64) string {
+	if math.IsInf(bound, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(bound, 'g', -1, 64)
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"testing"
+)
+
+// TestHistogram ensures the buckets of a histogram are cumulative and end with
+// the +Inf bucket, followed by the _sum and _count series, for each
+// combination of label values in order.
+func TestHistogram(t *testing.T) {
+	h := NewHistogram("pod_rpc_seconds", "RPC latency.", []float64{1, 2.5},
+		"method")
+	for _, v := range []float64{0.5, 1, 2, 3} {
+		h.Observe(v, "getinfo")
+	}
+	h.Observe(10, "a \"quoted\" method")
+	r := NewRegistry()
+	r.Register("rpc", h)
+	want := `# HELP pod_rpc_seconds RPC latency.
+# TYPE pod_rpc_seconds histogram
+pod_rpc_seconds_bucket{method="a \"quoted\" method",le="1"} 0
+pod_rpc_seconds_bucket{method="a \"quoted\" method",le="2.5"} 0
+pod_rpc_seconds_bucket{method="a \"quoted\" method",le="+Inf"} 1
+pod_rpc_seconds_sum{method="a \"quoted\" method"} 10
+pod_rpc_seconds_count{method="a \"quoted\" method"} 1
+pod_rpc_seconds_bucket{method="getinfo",le="1"} 2
+pod_rpc_seconds_bucket{method="getinfo",le="2.5"} 3
+pod_rpc_seconds_bucket{method="getinfo",le="+Inf"} 4
+pod_rpc_seconds_sum{method="getinfo"} 6.5
+pod_rpc_seconds_count{method="getinfo"} 4
+`
+	if got := string(r.Gather()); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestHistogramNoLabels ensures a histogram without labels labels only its
+// buckets, and that nothing but its HELP and TYPE lines is written before a
+// value is observed.
+func TestHistogramNoLabels(t *testing.T) {
+	h := NewHistogram("pod_template_seconds", "Template build time.",
+		[]float64{0.01})
+	r := NewRegistry()
+	r.Register("mining", h)
+	want := `# HELP pod_template_seconds Template build time.
+# TYPE pod_template_seconds histogram
+`
+	if got := string(r.Gather()); got != want {
+		t.Errorf("before observing: got:\n%s\nwant:\n%s", got, want)
+	}
+	h.Observe(0.01)
+	h.Observe(0.25)
+	want += `pod_template_seconds_bucket{le="0.01"} 1
+pod_template_seconds_bucket{le="+Inf"} 2
+pod_template_seconds_sum 0.26
+pod_template_seconds_count 2
+`
+	if got := string(r.Gather()); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,213 @@
+// Package metrics exposes the state of the node, the miner controller and the
+// wallet in the Prometheus text exposition format, so that they can be scraped
+// and alerted on with standard tooling.
+//
+// Subsystems register a Collector under a name, which is called on every
+// scrape to add the current values of their metrics. Values that are only
+// known as they happen, such as request latencies, are accumulated in a
+// Histogram, which is itself a Collector.
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The types of metric families.
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// Collector adds the current values of its metrics to a scrape.
+type Collector interface {
+	Collect(s *Scrape)
+}
+
+// CollectorFunc is a function that is used as a Collector.
+type CollectorFunc func(s *Scrape)
+
+// Collect calls the function.
+func (f CollectorFunc) Collect(s *Scrape) {
+	f(s)
+}
+
+// Registry is a set of named collectors that are scraped together.
+type Registry struct {
+	mtx        sync.Mutex
+	collectors map[string]Collector
+}
+
+// Default is the registry that the package level functions use, and that
+// subsystems register their collectors in.
+var Default = NewRegistry()
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]Collector)}
+}
+
+// Register adds a collector to the registry, replacing the one registered
+// under the same name, if any.
+func (r *Registry) Register(name string, c Collector) {
+	r.mtx.Lock()
+	r.collectors[name] = c
+	r.mtx.Unlock()
+}
+
+// Unregister removes the collector registered under a name.
+func (r *Registry) Unregister(name string) {
+	r.mtx.Lock()
+	delete(r.collectors, name)
+	r.mtx.Unlock()
+}
+
+// Gather scrapes all the collectors and returns their metrics in the text
+// exposition format. The collectors are scraped in the order of their names.
+func (r *Registry) Gather() []byte {
+	r.mtx.Lock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	collectors := make([]Collector, len(names))
+	for i, name := range names {
+		collectors[i] = r.collectors[name]
+	}
+	r.mtx.Unlock()
+	s := newScrape()
+	for _, c := range collectors {
+		c.Collect(s)
+	}
+	return s.bytes()
+}
+
+// ServeHTTP writes the metrics of the registry in reply to a scrape.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := w.Write(r.Gather()); err != nil {
+		Debug("failed to write metrics:", err)
+	}
+}
+
+// Register adds a collector to the default registry.
+func Register(name string, c Collector) {
+	Default.Register(name, c)
+}
+
+// Unregister removes a collector from the default registry.
+func Unregister(name string) {
+	Default.Unregister(name)
+}
+
+// Scrape collects the samples of the metrics added to it, grouped by metric
+// family in the order the families were first added.
+type Scrape struct {
+	families map[string]*family
+	order    []*family
+}
+
+// family is a metric family with the samples that were added to it.
+type family struct {
+	name    string
+	help    string
+	typ     string
+	samples bytes.Buffer
+}
+
+func newScrape() *Scrape {
+	return &Scrape{families: make(map[string]*family)}
+}
+
+// Gauge adds a sample of a gauge. The labels are given as pairs of label
+// names and values.
+func (s *Scrape) Gauge(name, help string, value float64, labels ...string) {
+	s.family(name, help, TypeGauge).sample(name, value, labels)
+}
+
+// Counter adds a sample of a counter. The labels are given as pairs of label
+// names and values.
+func (s *Scrape) Counter(name, help string, value float64, labels ...string) {
+	s.family(name, help, TypeCounter).sample(name, value, labels)
+}
+
+// family returns the family of a metric, adding it if it is new to the scrape.
+func (s *Scrape) family(name, help, typ string) *family {
+	f, ok := s.families[name]
+	if !ok {
+		f = &family{name: name, help: help, typ: typ}
+		s.families[name] = f
+		s.order = append(s.order, f)
+	}
+	return f
+}
+
+// bytes returns the samples of the scrape in the text exposition format.
+func (s *Scrape) bytes() []byte {
+	var buf bytes.Buffer
+	for _, f := range s.order {
+		buf.WriteString("# HELP " + f.name + " " + escapeHelp(f.help) + "\n")
+		buf.WriteString("# TYPE " + f.name + " " + f.typ + "\n")
+		buf.Write(f.samples.Bytes())
+	}
+	return buf.Bytes()
+}
+
+// sample writes a sample line to the family. The name differs from that of
+// the family for the series of a histogram.
+func (f *family) sample(name string, value float64, labels []string) {
+	f.samples.WriteString(name)
+	if len(labels) > 1 {
+		f.samples.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				f.samples.WriteByte(',')
+			}
+			f.samples.WriteString(labels[i] + `="` +
+				escapeLabelValue(labels[i+1]) + `"`)
+		}
+		f.samples.WriteByte('}')
+	}
+	f.samples.WriteString(" " + formatValue(value) + "\n")
+}
+
+// BoolValue returns the sample value of a boolean, 1 for true and 0 for false.
+func BoolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// formatValue formats a sample value the way the exposition format expects.
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
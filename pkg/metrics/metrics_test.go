@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestGather ensures the collectors of a registry are scraped in the order of
+// their names, with the HELP and TYPE lines of each metric family written
+// once ahead of its samples.
+func TestGather(t *testing.T) {
+	r := NewRegistry()
+	r.Register("wallet", CollectorFunc(func(s *Scrape) {
+		s.Gauge("pod_wallet_locked", "Whether the wallet is locked.",
+			BoolValue(true))
+		s.Counter("pod_requests_total", "Requests served.", 2,
+			"server", "wallet")
+	}))
+	r.Register("node", CollectorFunc(func(s *Scrape) {
+		s.Counter("pod_requests_total", "Requests served.", 5,
+			"server", "node", "method", "getinfo")
+		s.Gauge("pod_block_height", "The height of the best block.", 1234)
+	}))
+	r.Register("removed", CollectorFunc(func(s *Scrape) {
+		s.Gauge("pod_removed", "Removed.", 1)
+	}))
+	r.Unregister("removed")
+	want := `# HELP pod_requests_total Requests served.
+# TYPE pod_requests_total counter
+pod_requests_total{server="node",method="getinfo"} 5
+pod_requests_total{server="wallet"} 2
+# HELP pod_block_height The height of the best block.
+# TYPE pod_block_height gauge
+pod_block_height 1234
+# HELP pod_wallet_locked Whether the wallet is locked.
+# TYPE pod_wallet_locked gauge
+pod_wallet_locked 1
+`
+	if got := string(r.Gather()); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestEscaping ensures backslashes and newlines are escaped in help text, and
+// double quotes as well in label values.
+func TestEscaping(t *testing.T) {
+	r := NewRegistry()
+	r.Register("test", CollectorFunc(func(s *Scrape) {
+		s.Gauge("pod_test", "A \\ backslash,\na newline and \"quotes\".", 1,
+			"path", "C:\\pod\n\"data\"")
+	}))
+	want := `# HELP pod_test A \\ backslash,\na newline and "quotes".
+# TYPE pod_test gauge
+pod_test{path="C:\\pod\n\"data\""} 1
+`
+	if got := string(r.Gather()); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestFormatValue ensures sample values are written the way the exposition
+// format expects.
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want string
+	}{
+		{0, "0"},
+		{-1.5, "-1.5"},
+		{1e6, "1e+06"},
+		{0.001, "0.001"},
+		{math.Inf(1), "+Inf"},
+		{math.Inf(-1), "-Inf"},
+		{math.NaN(), "NaN"},
+	}
+	for _, test := range tests {
+		if got := formatValue(test.in); got != test.want {
+			t.Errorf("formatValue(%v): got %s, want %s", test.in, got,
+				test.want)
+		}
+	}
+}
+
+// TestServeHTTP ensures a scrape is answered with the metrics of the registry
+// and the content type of the text exposition format.
+func TestServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.Register("test", CollectorFunc(func(s *Scrape) {
+		s.Counter("pod_test_total", "Test.", 3)
+	}))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	want := "text/plain; version=0.0.4; charset=utf-8"
+	if got := rec.Header().Get("Content-Type"); got != want {
+		t.Errorf("got content type %s, want %s", got, want)
+	}
+	if got := rec.Body.String(); got != string(r.Gather()) {
+		t.Errorf("got body:\n%s\nwant:\n%s", got, r.Gather())
+	}
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"net"
+	"net/http"
+)
+
+// Path is the HTTP path metrics are served on.
+const Path = "/metrics"
+
+// Server serves the metrics of a registry over HTTP.
+type Server struct {
+	listener net.Listener
+	server   *http.Server
+}
+
+// Listen starts serving the metrics of a registry on Path at an address. It
+// fails right away if the address can't be listened on.
+func Listen(addr string, r *Registry) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(Path, r)
+	s := &Server{
+		listener: listener,
+		server:   &http.Server{Handler: mux},
+	}
+	go func() {
+		if err := s.server.Serve(listener); err != nil &&
+			err != http.ErrServerClosed {
+			Error("metrics server", err)
+		}
+	}()
+	Info("serving metrics on", "http://"+listener.Addr().String()+Path)
+	return s, nil
+}
+
+// Stop closes the listener and any scrapes in progress.
+func (s *Server) Stop() {
+	if err := s.server.Close(); err != nil {
+		Debug(err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -63,6 +64,12 @@ type localAddress struct {
 	score AddressPriority
 }
 
+// LocalAddr is a local address advertised to peers and its priority.
+type LocalAddr struct {
+	NA    *wire.NetAddress
+	Score AddressPriority
+}
+
 // AddressPriority type is used to describe the hierarchy of local address
 // routeable methods.
 type AddressPriority int
@@ -901,6 +908,24 @@ func // AddLocalAddress adds na to the list of known local addresses to
 	return nil
 }
 
+func // LocalAddresses returns the known local addresses to advertise, the
+// highest priority first.
+(a *AddrManager) LocalAddresses() []LocalAddr {
+	a.lamtx.Lock()
+	defer a.lamtx.Unlock()
+	addrs := make([]LocalAddr, 0, len(a.localAddresses))
+	for _, la := range a.localAddresses {
+		addrs = append(addrs, LocalAddr{NA: la.na, Score: la.score})
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		if addrs[i].Score != addrs[j].Score {
+			return addrs[i].Score > addrs[j].Score
+		}
+		return NetAddressKey(addrs[i].NA) < NetAddressKey(addrs[j].NA)
+	})
+	return addrs
+}
+
 func // getReachabilityFrom returns the relative reachability of the provided
 // local address to the provided remote address.
 getReachabilityFrom(localAddr, remoteAddr *wire.NetAddress) int {
@@ -185,6 +185,32 @@ func TestAddLocalAddress(t *testing.T) {
 		}
 	}
 }
+
+func TestLocalAddresses(t *testing.T) {
+	amgr := addrmgr.New("testlocaladdresses", nil)
+	if addrs := amgr.LocalAddresses(); len(addrs) != 0 {
+		t.Fatalf("LocalAddresses: got %d addresses, want 0", len(addrs))
+	}
+	for _, la := range []addrmgr.LocalAddr{
+		{&wire.NetAddress{IP: net.ParseIP("204.124.1.1"), Port: 11047},
+			addrmgr.InterfacePrio},
+		{&wire.NetAddress{IP: net.ParseIP("192.168.0.100")},
+			addrmgr.ManualPrio},
+		{&wire.NetAddress{IP: net.ParseIP("2620:100::1"), Port: 11047},
+			addrmgr.ManualPrio},
+	} {
+		_ = amgr.AddLocalAddress(la.NA, la.Score)
+	}
+	addrs := amgr.LocalAddresses()
+	if len(addrs) != 2 {
+		t.Fatalf("LocalAddresses: got %d addresses, want 2", len(addrs))
+	}
+	if addrs[0].Score != addrmgr.ManualPrio ||
+		!addrs[0].NA.IP.Equal(net.ParseIP("2620:100::1")) {
+		t.Errorf("LocalAddresses: highest priority address is %s with %d",
+			addrs[0].NA.IP, addrs[0].Score)
+	}
+}
 func TestAttempt(t *testing.T) {
 	n := addrmgr.New("testattempt", lookupFunc)
 	// Add a new address and get it
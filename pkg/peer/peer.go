@@ -25,7 +25,7 @@ import (
 
 const (
 	// MaxProtocolVersion is the max protocol version the peer supports.
-	MaxProtocolVersion = wire.FeeFilterVersion
+	MaxProtocolVersion = wire.SendCmpctVersion
 	// DefaultTrickleInterval is the min time between attempts to send an inv
 	// message to a peer.
 	DefaultTrickleInterval = time.Second
@@ -153,6 +153,17 @@ type MessageListeners struct {
 	// OnSendHeaders is invoked when a peer receives a sendheaders bitcoin
 	// message.
 	OnSendHeaders func(p *Peer, msg *wire.MsgSendHeaders)
+	// OnSendCmpct is invoked when a peer receives a sendcmpct bitcoin
+	// message.
+	OnSendCmpct func(p *Peer, msg *wire.MsgSendCmpct)
+	// OnCmpctBlock is invoked when a peer receives a cmpctblock bitcoin
+	// message.
+	OnCmpctBlock func(p *Peer, msg *wire.MsgCmpctBlock)
+	// OnGetBlockTxn is invoked when a peer receives a getblocktxn bitcoin
+	// message.
+	OnGetBlockTxn func(p *Peer, msg *wire.MsgGetBlockTxn)
+	// OnBlockTxn is invoked when a peer receives a blocktxn bitcoin message.
+	OnBlockTxn func(p *Peer, msg *wire.MsgBlockTxn)
 	// OnRead is invoked when a peer receives a bitcoin message.
 	// It consists of the number of bytes read, the message,
 	// and whether or not an error in the read occurred.  Typically,
@@ -380,6 +391,9 @@ type Peer struct {
 	sendHeadersPreferred bool   // peer sent a sendheaders message
 	verAckReceived       bool
 	witnessEnabled       bool
+	cmpctBlockVersion    uint64 // compact block version agreed with the peer
+	cmpctHighBandwidth   bool   // peer wants cmpctblock announcements
+	sentCmpctHighBW      bool   // we asked the peer for cmpctblock announcements
 	wireEncoding         wire.MessageEncoding
 	knownInventory       *mruInventoryMap
 	prevGetBlocksMtx     sync.Mutex
@@ -445,6 +459,12 @@ func (p *Peer) AddKnownInventory(invVect *wire.InvVect) {
 	p.knownInventory.Add(invVect)
 }
 
+// HasKnownInventory returns whether the passed inventory is in the cache of
+// known inventory for the peer. This function is safe for concurrent access.
+func (p *Peer) HasKnownInventory(invVect *wire.InvVect) bool {
+	return p.knownInventory.Exists(invVect)
+}
+
 // StatsSnapshot returns a snapshot of the current peer flags and statistics.
 // This function is safe for concurrent access.
 func (p *Peer) StatsSnapshot() *StatsSnap {
@@ -672,6 +692,36 @@ func (p *Peer) WantsHeaders() bool {
 	return sendHeadersPreferred
 }
 
+// CmpctBlockVersion returns the compact block version agreed with the peer,
+// which is the first version we support it sent in a sendcmpct message, or
+// zero if it has not sent one. This function is safe for concurrent access.
+func (p *Peer) CmpctBlockVersion() uint64 {
+	p.flagsMtx.Lock()
+	version := p.cmpctBlockVersion
+	p.flagsMtx.Unlock()
+	return version
+}
+
+// WantsCmpctBlocks returns if the peer has asked to be sent new blocks as
+// cmpctblock messages without announcing them first (BIP0152 high-bandwidth
+// mode). This function is safe for concurrent access.
+func (p *Peer) WantsCmpctBlocks() bool {
+	p.flagsMtx.Lock()
+	wants := p.cmpctBlockVersion != 0 && p.cmpctHighBandwidth
+	p.flagsMtx.Unlock()
+	return wants
+}
+
+// IsCmpctHighBandwidth returns if we have asked the peer to send us new blocks
+// as cmpctblock messages without announcing them first. This function is safe
+// for concurrent access.
+func (p *Peer) IsCmpctHighBandwidth() bool {
+	p.flagsMtx.Lock()
+	highBandwidth := p.sentCmpctHighBW
+	p.flagsMtx.Unlock()
+	return highBandwidth
+}
+
 // IsWitnessEnabled returns true if the peer has signalled that it supports
 // segregated witness. This function is safe for concurrent access.
 func (p *Peer) IsWitnessEnabled() bool {
@@ -681,6 +731,53 @@ func (p *Peer) IsWitnessEnabled() bool {
 	return witnessEnabled
 }
 
+// PushSendCmpctMsg sends sendcmpct messages for the compact block versions we
+// support to the connected peer, asking it to send us new blocks as
+// cmpctblock messages without announcing them first when highBandwidth is
+// set, or to announce them as usual otherwise. The witness version is only
+// offered to peers that support segregated witness, and is offered first as
+// the preferred one. Nothing is sent to peers that don't support compact
+// blocks. This function is safe for concurrent access.
+func (p *Peer) PushSendCmpctMsg(highBandwidth bool) {
+	if p.ProtocolVersion() < wire.SendCmpctVersion {
+		return
+	}
+	p.flagsMtx.Lock()
+	p.sentCmpctHighBW = highBandwidth
+	witnessEnabled := p.witnessEnabled
+	p.flagsMtx.Unlock()
+	if witnessEnabled {
+		p.QueueMessage(wire.NewMsgSendCmpct(highBandwidth,
+			wire.CmpctBlockWitnessVersion), nil)
+	}
+	p.QueueMessage(wire.NewMsgSendCmpct(highBandwidth,
+		wire.CmpctBlockVersion), nil)
+}
+
+// handleSendCmpctMsg is invoked when a peer receives a sendcmpct bitcoin
+// message. The first version we support that the peer sends is the one used
+// with it from then on, and later messages for that version only switch
+// between high and low bandwidth mode.
+func (p *Peer) handleSendCmpctMsg(msg *wire.MsgSendCmpct) {
+	p.flagsMtx.Lock()
+	defer p.flagsMtx.Unlock()
+	switch msg.CmpctBlockVersion {
+	case wire.CmpctBlockVersion:
+	case wire.CmpctBlockWitnessVersion:
+		if !p.witnessEnabled {
+			return
+		}
+	default:
+		return
+	}
+	if p.cmpctBlockVersion == 0 {
+		p.cmpctBlockVersion = msg.CmpctBlockVersion
+	}
+	if msg.CmpctBlockVersion == p.cmpctBlockVersion {
+		p.cmpctHighBandwidth = msg.AnnounceUsingCmpctBlock
+	}
+}
+
 // PushAddrMsg sends an addr message to the connected peer using the provided
 // addresses.  This function is useful over manually sending the message via
 // QueueMessage since it automatically limits the addresses to the maximum
@@ -993,11 +1090,15 @@ func (p *Peer) maybeAddDeadline(pendingResponses map[string]time.Time, msgCmd st
 		// Expects an inv message.
 		pendingResponses[wire.CmdInv] = deadline
 	case wire.CmdGetData:
-		// Expects a block, merkleblock, tx, or notfound message.
+		// Expects a block, cmpctblock, merkleblock, tx, or notfound message.
 		pendingResponses[wire.CmdBlock] = deadline
+		pendingResponses[wire.CmdCmpctBlock] = deadline
 		pendingResponses[wire.CmdMerkleBlock] = deadline
 		pendingResponses[wire.CmdTx] = deadline
 		pendingResponses[wire.CmdNotFound] = deadline
+	case wire.CmdGetBlockTxn:
+		// Expects a blocktxn message.
+		pendingResponses[wire.CmdBlockTxn] = deadline
 	case wire.CmdGetHeaders:
 		// Expects a headers message.
 		// Use a longer deadline since it can take a while for the remote
@@ -1046,12 +1147,15 @@ out:
 				switch msgCmd := msg.message.Command(); msgCmd {
 				case wire.CmdBlock:
 					fallthrough
+				case wire.CmdCmpctBlock:
+					fallthrough
 				case wire.CmdMerkleBlock:
 					fallthrough
 				case wire.CmdTx:
 					fallthrough
 				case wire.CmdNotFound:
 					delete(pendingResponses, wire.CmdBlock)
+					delete(pendingResponses, wire.CmdCmpctBlock)
 					delete(pendingResponses, wire.CmdMerkleBlock)
 					delete(pendingResponses, wire.CmdTx)
 					delete(pendingResponses, wire.CmdNotFound)
@@ -1322,6 +1426,23 @@ out:
 			if p.cfg.Listeners.OnSendHeaders != nil {
 				p.cfg.Listeners.OnSendHeaders(p, msg)
 			}
+		case *wire.MsgSendCmpct:
+			p.handleSendCmpctMsg(msg)
+			if p.cfg.Listeners.OnSendCmpct != nil {
+				p.cfg.Listeners.OnSendCmpct(p, msg)
+			}
+		case *wire.MsgCmpctBlock:
+			if p.cfg.Listeners.OnCmpctBlock != nil {
+				p.cfg.Listeners.OnCmpctBlock(p, msg)
+			}
+		case *wire.MsgGetBlockTxn:
+			if p.cfg.Listeners.OnGetBlockTxn != nil {
+				p.cfg.Listeners.OnGetBlockTxn(p, msg)
+			}
+		case *wire.MsgBlockTxn:
+			if p.cfg.Listeners.OnBlockTxn != nil {
+				p.cfg.Listeners.OnBlockTxn(p, msg)
+			}
 		default:
 			Debugf(
 				"Received unhandled message of type %v from %v %s",
@@ -1857,6 +1978,10 @@ func (p *Peer) start() error {
 	go p.pingHandler()
 	// Send our verack message now that the IO processing machinery has started.
 	p.QueueMessage(wire.NewMsgVerAck(), nil)
+	// Let the peer know we support compact blocks. Blocks are only asked for
+	// as cmpctblock announcements once the peer has proven itself to be one
+	// of the first to relay new blocks.
+	p.PushSendCmpctMsg(false)
 	return nil
 }
 
@@ -405,6 +405,15 @@ func TestPeerListeners(t *testing.T) {
 			OnSendHeaders: func(p *peer.Peer, msg *wire.MsgSendHeaders) {
 				ok <- msg
 			},
+			OnCmpctBlock: func(p *peer.Peer, msg *wire.MsgCmpctBlock) {
+				ok <- msg
+			},
+			OnGetBlockTxn: func(p *peer.Peer, msg *wire.MsgGetBlockTxn) {
+				ok <- msg
+			},
+			OnBlockTxn: func(p *peer.Peer, msg *wire.MsgBlockTxn) {
+				ok <- msg
+			},
 		},
 		UserAgentName:     "peer",
 		UserAgentVersion:  "1.0",
@@ -550,6 +559,19 @@ func TestPeerListeners(t *testing.T) {
 			"OnSendHeaders",
 			wire.NewMsgSendHeaders(),
 		},
+		{
+			"OnCmpctBlock",
+			wire.NewMsgCmpctBlock(wire.NewBlockHeader(1,
+				&chainhash.Hash{}, &chainhash.Hash{}, 1, 1), 0),
+		},
+		{
+			"OnGetBlockTxn",
+			wire.NewMsgGetBlockTxn(&chainhash.Hash{}, []uint32{1}),
+		},
+		{
+			"OnBlockTxn",
+			wire.NewMsgBlockTxn(&chainhash.Hash{}),
+		},
 	}
 	t.Logf("Running %d tests", len(tests))
 	for _, test := range tests {
@@ -566,6 +588,108 @@ func TestPeerListeners(t *testing.T) {
 	outPeer.Disconnect()
 }
 
+// TestPeerCmpctBlockNegotiation tests that peers agree on a compact block
+// version after connecting and switch to high-bandwidth mode when asked.
+func TestPeerCmpctBlockNegotiation(t *testing.T) {
+	verack := make(chan struct{}, 2)
+	sendCmpct := make(chan *wire.MsgSendCmpct, 4)
+	peerCfg := &peer.Config{
+		Listeners: peer.MessageListeners{
+			OnVerAck: func(p *peer.Peer, msg *wire.MsgVerAck) {
+				verack <- struct{}{}
+			},
+			OnSendCmpct: func(p *peer.Peer, msg *wire.MsgSendCmpct) {
+				sendCmpct <- msg
+			},
+		},
+		UserAgentName:     "peer",
+		UserAgentVersion:  "1.0",
+		UserAgentComments: []string{"comment"},
+		ChainParams:       &netparams.MainNetParams,
+		Services:          0,
+		TrickleInterval:   time.Second * 10,
+	}
+	inConn, outConn := pipe(
+		&conn{raddr: "10.0.0.1:11047"},
+		&conn{raddr: "10.0.0.2:11047"},
+	)
+	inPeer := peer.NewInboundPeer(peerCfg)
+	inPeer.AssociateConnection(inConn)
+	outCfg := *peerCfg
+	outCfg.Listeners = peer.MessageListeners{
+		OnVerAck: func(p *peer.Peer, msg *wire.MsgVerAck) {
+			verack <- struct{}{}
+		},
+	}
+	outPeer, err := peer.NewOutboundPeer(&outCfg, "10.0.0.1:11047")
+	if err != nil {
+		t.Errorf("NewOutboundPeer: unexpected err %v\n", err)
+		return
+	}
+	outPeer.AssociateConnection(outConn)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-verack:
+		case <-time.After(time.Second * 1):
+			t.Errorf("TestPeerCmpctBlockNegotiation: verack timeout\n")
+			return
+		}
+	}
+	// The outbound peer offers the non-witness version in low-bandwidth mode
+	// once connected, as neither peer supports segregated witness.
+	select {
+	case msg := <-sendCmpct:
+		if msg.AnnounceUsingCmpctBlock ||
+			msg.CmpctBlockVersion != wire.CmpctBlockVersion {
+			t.Errorf("TestPeerCmpctBlockNegotiation: unexpected "+
+				"sendcmpct %v", msg)
+		}
+	case <-time.After(time.Second * 1):
+		t.Errorf("TestPeerCmpctBlockNegotiation: sendcmpct timeout\n")
+		return
+	}
+	if v := inPeer.CmpctBlockVersion(); v != wire.CmpctBlockVersion {
+		t.Errorf("CmpctBlockVersion: wrong version - got %v, want %v",
+			v, wire.CmpctBlockVersion)
+	}
+	if inPeer.WantsCmpctBlocks() {
+		t.Errorf("WantsCmpctBlocks: peer wants compact blocks before " +
+			"asking for high-bandwidth mode")
+	}
+	// Asking for high-bandwidth mode keeps the agreed version.
+	outPeer.PushSendCmpctMsg(true)
+	select {
+	case <-sendCmpct:
+	case <-time.After(time.Second * 1):
+		t.Errorf("TestPeerCmpctBlockNegotiation: sendcmpct timeout\n")
+		return
+	}
+	if !outPeer.IsCmpctHighBandwidth() {
+		t.Errorf("IsCmpctHighBandwidth: high-bandwidth mode not recorded")
+	}
+	if !inPeer.WantsCmpctBlocks() {
+		t.Errorf("WantsCmpctBlocks: peer does not want compact blocks " +
+			"after asking for high-bandwidth mode")
+	}
+	if v := inPeer.CmpctBlockVersion(); v != wire.CmpctBlockVersion {
+		t.Errorf("CmpctBlockVersion: wrong version - got %v, want %v",
+			v, wire.CmpctBlockVersion)
+	}
+	// Versions that are not supported are ignored.
+	outPeer.QueueMessage(wire.NewMsgSendCmpct(false, 3), nil)
+	select {
+	case <-sendCmpct:
+	case <-time.After(time.Second * 1):
+		t.Errorf("TestPeerCmpctBlockNegotiation: sendcmpct timeout\n")
+		return
+	}
+	if !inPeer.WantsCmpctBlocks() {
+		t.Errorf("WantsCmpctBlocks: unsupported version changed mode")
+	}
+	inPeer.Disconnect()
+	outPeer.Disconnect()
+}
+
 // TestOutboundPeer tests that the outbound peer works as expected.
 func TestOutboundPeer(t *testing.T) {
 	peerCfg := &peer.Config{
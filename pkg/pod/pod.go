@@ -142,6 +142,7 @@ type Config struct {
 	DisableListen          *bool            `group:"node" label:"Disable Listen" description:"disables inbound connections for the peer to peer network" type:"switch" json:"DisableListen" hook:"restart"`
 	DisableRPC             *bool            `group:"rpc" label:"Disable RPC" description:"disable rpc servers" type:"switch" json:"DisableRPC" hook:"restart"`
 	ExternalIPs            *cli.StringSlice `group:"node" label:"External IP Addresses" description:"extra addresses to tell peers they can connect to" type:"stringSlice" inputType:"text" json:"ExternalIPs" hook:"restart"`
+	ForkHeight             *int             `group:"debug" label:"Fork Height" description:"height the first hard fork activates at on regtest and simnet, 0 for the network default" type:"input" inputType:"number" json:"ForkHeight" hook:"restart"`
 	FreeTxRelayLimit       *float64         `group:"policy" label:"Free Tx Relay Limit" description:"limit relay of transactions with no transaction fee to the given amount in thousands of bytes per minute" type:"input" inputType:"decimal" json:"FreeTxRelayLimit" hook:"restart"`
 	Generate               *bool            `group:"mining" label:"Generate Blocks" description:"turn on Kopach CPU miner" type:"switch" json:"Generate" hook:"generate"`
 	GenThreads             *int             `group:"mining" label:"Gen Threads" description:"number of threads to mine with" type:"input" inputType:"number" json:"GenThreads" hook:"genthreads"`
@@ -151,9 +152,14 @@ type Config struct {
 	Listeners              *cli.StringSlice `group:"node" label:"Listeners" description:"list of addresses to bind the node listener to" type:"stringSlice" inputType:"text" json:"Listeners" hook:"restart"`
 	LogDir                 *string          `group:"config" label:"Log Dir" description:"folder where log files are written" type:"input" inputType:"text" json:"LogDir" hook:"restart"`
 	LogLevel               *string          `group:"config" label:"Log Level" description:"maximum log level to output\n(fatal error check warning info debug trace - what is selected includes all items to the left of the one in that list)" type:"input" inputType:"text" json:"LogLevel" hook:"loglevel"`
+	MaxMempoolSize         *int             `group:"policy" label:"Max Mempool Size" description:"maximum size of the mempool in MiB, above which the transactions paying the lowest fees are evicted" type:"input" inputType:"number" json:"MaxMempoolSize" hook:"restart"`
 	MaxOrphanTxs           *int             `group:"policy" label:"Max Orphan Txs" description:"max number of orphan transactions to keep in memory" type:"input" inputType:"number" json:"MaxOrphanTxs" hook:"restart"`
 	MaxPeers               *int             `group:"node" label:"Max Peers" description:"maximum number of peers to hold connections with" type:"input" inputType:"number" json:"MaxPeers" hook:"restart"`
+	MetricsListener        *string          `group:"node" label:"Metrics Listener" description:"address to serve prometheus metrics of the node on at /metrics, empty disables it" type:"input" inputType:"text" json:"MetricsListener" hook:"restart"`
+	MinerControllers       *cli.StringSlice `group:"mining" label:"Miner Controllers" description:"addresses of miner controllers for kopach to connect to directly instead of finding them by multicast" type:"stringSlice" inputType:"text" json:"MinerControllers" hook:"restart"`
+	MinerListener          *string          `group:"mining" label:"Miner Listener" description:"address for the miner controller to accept unicast UDP and TCP kopach connections on, empty disables it" type:"input" inputType:"text" json:"MinerListener" hook:"restart"`
 	MinerPass              *string          `group:"mining" label:"Miner Pass" description:"password that encrypts the connection to the mining controller" type:"input" inputType:"password" json:"MinerPass" hook:"restart"`
+	MinerTCP               *bool            `group:"mining" label:"Miner TCP" description:"connect kopach to the miner controllers with a TCP stream instead of UDP" type:"switch" json:"MinerTCP" hook:"restart"`
 	MiningAddrs            *cli.StringSlice `group:"" label:"Mining Addrs" description:"addresses to pay block rewards to (TODO, make this auto)" type:"stringSlice" inputType:"text" json:"MiningAddrs" hook:"miningaddr"`
 	MinRelayTxFee          *float64         `group:"policy" label:"Min Relay Tx Fee" description:"the minimum transaction fee in DUO/kB to be considered a non-zero fee" type:"input" inputType:"decimal" json:"MinRelayTxFee" hook:"restart"`
 	Network                *string          `group:"node" label:"Network" description:"connect to this network: mainnet, testnet)" type:"input" inputType:"text" json:"Network" hook:"restart"`
@@ -168,12 +174,23 @@ type Config struct {
 	OnionProxyPass         *string          `group:"proxy" label:"Onion Proxy Pass" description:"password for tor proxy" type:"input" inputType:"password" json:"OnionProxyPass" hook:"restart"`
 	OnionProxyUser         *string          `group:"proxy" label:"Onion Proxy User" description:"tor proxy username" type:"input" inputType:"text" json:"OnionProxyUser" hook:"restart"`
 	Password               *string          `group:"rpc" label:"Password" description:"password for client RPC connections" type:"input" inputType:"text" json:"Password" hook:"restart"`
+	PoolAddress            *string          `group:"mining" label:"Pool Address" description:"address kopach asks the miner controller to credit its shares to, empty disables sending shares" type:"input" inputType:"text" json:"PoolAddress" hook:"restart"`
+	PoolPayout             *string          `group:"mining" label:"Pool Payout" description:"scheme the miner controller splits block rewards between the workers' shares with: pplns or prop, empty disables share accounting" type:"input" inputType:"text" json:"PoolPayout" hook:"restart"`
+	PoolWindow             *int             `group:"mining" label:"Pool Window" description:"number of blocks worth of shares that PPLNS splits each block reward over" type:"input" inputType:"number" json:"PoolWindow" hook:"restart"`
 	Profile                *string          `group:"debug" label:"Profile" description:"http profiling on given port (1024-40000)" type:"input" inputType:"text" json:"Profile" hook:"restart"`
+	Prune                  *int             `group:"node" label:"Prune" description:"delete the oldest blocks to keep the block files under this size in MiB, at least 550, or 0 to keep all blocks" type:"input" inputType:"number" json:"Prune" hook:"restart"`
 	Proxy                  *string          `group:"proxy" label:"Proxy" description:"address of proxy to connect to for outbound connections" type:"input" inputType:"text" json:"Proxy" hook:"restart"`
 	ProxyPass              *string          `group:"proxy" label:"Proxy Pass" description:"proxy password, if required" type:"input" inputType:"password" json:"ProxyPass" hook:"restart"`
 	ProxyUser              *string          `group:"proxy" label:"ProxyUser" description:"proxy username, if required" type:"input" inputType:"text" json:"ProxyUser" hook:"restart"`
+	PubHashBlock           *cli.StringSlice `group:"node" label:"Publish Block Hashes" description:"publish the hashes of new blocks on these tcp:// or unix:// endpoints" type:"stringSlice" inputType:"text" json:"PubHashBlock" hook:"restart"`
+	PubHashTx              *cli.StringSlice `group:"node" label:"Publish Transaction Hashes" description:"publish the hashes of new transactions on these tcp:// or unix:// endpoints" type:"stringSlice" inputType:"text" json:"PubHashTx" hook:"restart"`
+	PubRawBlock            *cli.StringSlice `group:"node" label:"Publish Raw Blocks" description:"publish new blocks in full on these tcp:// or unix:// endpoints" type:"stringSlice" inputType:"text" json:"PubRawBlock" hook:"restart"`
+	PubRawTx               *cli.StringSlice `group:"node" label:"Publish Raw Transactions" description:"publish new transactions in full on these tcp:// or unix:// endpoints" type:"stringSlice" inputType:"text" json:"PubRawTx" hook:"restart"`
+	PubReorg               *cli.StringSlice `group:"node" label:"Publish Reorgs" description:"publish the blocks disconnected by chain reorganizations on these tcp:// or unix:// endpoints" type:"stringSlice" inputType:"text" json:"PubReorg" hook:"restart"`
 	RejectNonStd           *bool            `group:"node" label:"Reject Non Std" description:"reject non-standard transactions regardless of the default settings for the active network" type:"switch" json:"RejectNonStd" hook:"restart"`
+	RejectReplacement      *bool            `group:"node" label:"Reject Replacement" description:"reject transactions that replace transactions in the mempool that signal opt-in replace-by-fee" type:"switch" json:"RejectReplacement" hook:"restart"`
 	RelayNonStd            *bool            `group:"node" label:"Relay Non Std" description:"relay non-standard transactions regardless of the default settings for the active network" type:"switch" json:"RelayNonStd" hook:"restart"`
+	REST                   *bool            `group:"rpc" label:"REST" description:"serve the read-only REST interface at /rest/ on the RPC listeners, without authentication" type:"switch" json:"REST" hook:"restart"`
 	RPCCert                *string          `group:"rpc" label:"RPC Cert" description:"location of RPC TLS certificate" type:"input" inputType:"text" json:"RPCCert" hook:"restart"`
 	RPCConnect             *string          `group:"wallet" label:"RPC Connect" description:"full node RPC for wallet" type:"input" inputType:"text" json:"RPCConnect" hook:"restart"`
 	RPCKey                 *string          `group:"rpc" label:"RPC Key" description:"location of rpc TLS key" type:"input" inputType:"text" json:"RPCKey" hook:"restart"`
@@ -186,17 +203,23 @@ type Config struct {
 	ServerTLS              *bool            `group:"wallet" label:"Server TLS" description:"enable TLS for the wallet connection to node RPC server" type:"switch" json:"ServerTLS" hook:"restart"`
 	ServerUser             *string          `group:"rpc" label:"Server User" description:"username for chain server connections" type:"input" inputType:"text" json:"ServerUser" hook:"restart"`
 	SigCacheMaxSize        *int             `group:"node" label:"Sig Cache Max Size" description:"the maximum number of entries in the signature verification cache" type:"input" inputType:"number" json:"SigCacheMaxSize" hook:"restart"`
+	SPVConnect             *cli.StringSlice `group:"wallet" label:"SPV Connect" description:"nodes serving compact block filters that the SPV wallet connects to exclusively, if empty peers are found from the network" type:"stringSlice" inputType:"text" json:"SPVConnect" hook:"restart"`
+	SPVDataDir             *string          `group:"wallet" label:"SPV Data Dir" description:"folder where the SPV wallet stores block headers and filters" type:"input" inputType:"text" json:"SPVDataDir" hook:"restart"`
 	Solo                   *bool            `group:"mining" label:"Solo Generate" description:"mine even if not connected to a network" type:"switch" json:"Solo" hook:"restart"`
+	StratumListener        *string          `group:"mining" label:"Stratum Listener" description:"address for the stratum v1 mining server to listen on, empty disables it" type:"input" inputType:"text" json:"StratumListener" hook:"restart"`
 	TLS                    *bool            `group:"tls" label:"TLS" description:"enable TLS for RPC connections" type:"switch" json:"TLS" hook:"restart"`
 	TLSSkipVerify          *bool            `group:"tls" label:"TLS Skip Verify" description:"skip TLS certificate verification (ignore CA errors)" type:"switch" json:"TLSSkipVerify" hook:"restart"`
 	TorIsolation           *bool            `group:"proxy" label:"Tor Isolation" description:"makes a separate proxy connection for each connection" type:"switch" json:"TorIsolation" hook:"restart"`
 	TrickleInterval        *time.Duration   `group:"policy" label:"Trickle Interval" description:"minimum time between attempts to send new inventory to a connected peer" type:"input" inputType:"time" json:"TrickleInterval" hook:"restart"`
 	TxIndex                *bool            `group:"node" label:"Tx Index" description:"maintain a full hash-based transaction index which makes all transactions available via the getrawtransaction RPC" type:"switch" json:"TxIndex" hook:"droptxindex"`
 	UPNP                   *bool            `group:"node" label:"UPNP" description:"enable UPNP for NAT traversal" type:"switch" json:"UPNP" hook:"restart"`
+	UseSPV                 *bool            `group:"wallet" label:"Use SPV" description:"synchronise the wallet from compact block filters served by peers instead of the RPC of a full node" type:"switch" json:"UseSPV" hook:"restart"`
 	UserAgentComments      *cli.StringSlice `group:"node" label:"User Agent Comments" description:"comment to add to the user agent -- See BIP 14 for more information" type:"stringSlice" inputType:"text" json:"UserAgentComments" hook:"restart"`
 	Username               *string          `group:"rpc" label:"Username" description:"password for client RPC connections" type:"input" inputType:"text" json:"Username" hook:"restart"`
 	Wallet                 *bool            `group:"debug" label:"Connect to Wallet" description:"set ctl to connect to wallet instead of chain server" type:"switch" json:"Wallet"`
+	WalletBirthday         *string          `group:"wallet" label:"Wallet Birthday" description:"date (YYYY-MM-DD) before which the wallet's keys were never used, rescans of an SPV wallet start after it, empty keeps the date the wallet was created" type:"input" inputType:"text" json:"WalletBirthday" hook:"restart"`
 	WalletFile             *string          `group:"config" label:"Wallet File" description:"wallet database file" type:"input" inputType:"text" featured:"true" json:"WalletFile" hook:"restart"`
+	WalletMetricsListener  *string          `group:"wallet" label:"Wallet Metrics Listener" description:"address to serve prometheus metrics of the wallet on at /metrics, empty disables it" type:"input" inputType:"text" json:"WalletMetricsListener" hook:"restart"`
 	WalletOff              *bool            `group:"debug" label:"Wallet Off" description:"turn off the wallet backend" type:"switch" json:"WalletOff" hook:"wallet"`
 	WalletPass             *string          `group:"wallet" label:"Wallet Pass" description:"password encrypting public data in wallet" type:"input" inputType:"text" json:"WalletPass" hook:"restart"`
 	WalletRPCListeners     *cli.StringSlice `group:"wallet" label:"Legacy RPC Listeners" description:"addresses for wallet RPC server to listen on" type:"stringSlice" inputType:"text" json:"WalletRPCListeners" hook:"restart"`
@@ -235,6 +258,7 @@ func EmptyConfig() (c *Config, conf map[string]interface{}) {
 		DisableListen:          newbool(),
 		DisableRPC:             newbool(),
 		ExternalIPs:            newStringSlice(),
+		ForkHeight:             newint(),
 		FreeTxRelayLimit:       new(float64),
 		Generate:               newbool(),
 		GenThreads:             newint(),
@@ -245,9 +269,14 @@ func EmptyConfig() (c *Config, conf map[string]interface{}) {
 		Listeners:              newStringSlice(),
 		LogDir:                 newstring(),
 		LogLevel:               newstring(),
+		MaxMempoolSize:         newint(),
 		MaxOrphanTxs:           newint(),
 		MaxPeers:               newint(),
+		MetricsListener:        newstring(),
+		MinerControllers:       newStringSlice(),
+		MinerListener:          newstring(),
 		MinerPass:              newstring(),
+		MinerTCP:               newbool(),
 		MiningAddrs:            newStringSlice(),
 		MinRelayTxFee:          new(float64),
 		Network:                newstring(),
@@ -262,12 +291,23 @@ func EmptyConfig() (c *Config, conf map[string]interface{}) {
 		OnionProxyPass:         newstring(),
 		OnionProxyUser:         newstring(),
 		Password:               newstring(),
+		PoolAddress:            newstring(),
+		PoolPayout:             newstring(),
+		PoolWindow:             newint(),
 		Profile:                newstring(),
+		Prune:                  newint(),
 		Proxy:                  newstring(),
 		ProxyPass:              newstring(),
 		ProxyUser:              newstring(),
+		PubHashBlock:           newStringSlice(),
+		PubHashTx:              newStringSlice(),
+		PubRawBlock:            newStringSlice(),
+		PubRawTx:               newStringSlice(),
+		PubReorg:               newStringSlice(),
 		RejectNonStd:           newbool(),
+		RejectReplacement:      newbool(),
 		RelayNonStd:            newbool(),
+		REST:                   newbool(),
 		RPCCert:                newstring(),
 		RPCConnect:             newstring(),
 		RPCKey:                 newstring(),
@@ -280,17 +320,23 @@ func EmptyConfig() (c *Config, conf map[string]interface{}) {
 		ServerTLS:              newbool(),
 		ServerUser:             newstring(),
 		SigCacheMaxSize:        newint(),
+		SPVConnect:             newStringSlice(),
+		SPVDataDir:             newstring(),
 		Solo:                   newbool(),
+		StratumListener:        newstring(),
 		TLS:                    newbool(),
 		TLSSkipVerify:          newbool(),
 		TorIsolation:           newbool(),
 		TrickleInterval:        newDuration(),
 		TxIndex:                newbool(),
 		UPNP:                   newbool(),
+		UseSPV:                 newbool(),
 		UserAgentComments:      newStringSlice(),
 		Username:               newstring(),
 		Wallet:                 newbool(),
+		WalletBirthday:         newstring(),
 		WalletFile:             newstring(),
+		WalletMetricsListener:  newstring(),
 		WalletOff:              newbool(),
 		WalletPass:             newstring(),
 		WalletRPCListeners:     newStringSlice(),
@@ -325,6 +371,7 @@ func EmptyConfig() (c *Config, conf map[string]interface{}) {
 		"DisableListen":          c.DisableListen,
 		"DisableRPC":             c.DisableRPC,
 		"ExternalIPs":            c.ExternalIPs,
+		"ForkHeight":             c.ForkHeight,
 		"FreeTxRelayLimit":       c.FreeTxRelayLimit,
 		"Generate":               c.Generate,
 		"GenThreads":             c.GenThreads,
@@ -335,9 +382,14 @@ func EmptyConfig() (c *Config, conf map[string]interface{}) {
 		"Listeners":              c.Listeners,
 		"LogDir":                 c.LogDir,
 		"LogLevel":               c.LogLevel,
+		"MaxMempoolSize":         c.MaxMempoolSize,
 		"MaxOrphanTxs":           c.MaxOrphanTxs,
 		"MaxPeers":               c.MaxPeers,
+		"MetricsListener":        c.MetricsListener,
+		"MinerControllers":       c.MinerControllers,
+		"MinerListener":          c.MinerListener,
 		"MinerPass":              c.MinerPass,
+		"MinerTCP":               c.MinerTCP,
 		"MiningAddrs":            c.MiningAddrs,
 		"MinRelayTxFee":          c.MinRelayTxFee,
 		"Network":                c.Network,
@@ -352,12 +404,23 @@ func EmptyConfig() (c *Config, conf map[string]interface{}) {
 		"OnionProxyPass":         c.OnionProxyPass,
 		"OnionProxyUser":         c.OnionProxyUser,
 		"Password":               c.Password,
+		"PoolAddress":            c.PoolAddress,
+		"PoolPayout":             c.PoolPayout,
+		"PoolWindow":             c.PoolWindow,
 		"Profile":                c.Profile,
+		"Prune":                  c.Prune,
 		"Proxy":                  c.Proxy,
 		"ProxyPass":              c.ProxyPass,
 		"ProxyUser":              c.ProxyUser,
+		"PubHashBlock":           c.PubHashBlock,
+		"PubHashTx":              c.PubHashTx,
+		"PubRawBlock":            c.PubRawBlock,
+		"PubRawTx":               c.PubRawTx,
+		"PubReorg":               c.PubReorg,
 		"RejectNonStd":           c.RejectNonStd,
+		"RejectReplacement":      c.RejectReplacement,
 		"RelayNonStd":            c.RelayNonStd,
+		"REST":                   c.REST,
 		"RPCCert":                c.RPCCert,
 		"RPCConnect":             c.RPCConnect,
 		"RPCKey":                 c.RPCKey,
@@ -370,17 +433,23 @@ func EmptyConfig() (c *Config, conf map[string]interface{}) {
 		"ServerTLS":              c.ServerTLS,
 		"ServerUser":             c.ServerUser,
 		"SigCacheMaxSize":        c.SigCacheMaxSize,
+		"SPVConnect":             c.SPVConnect,
+		"SPVDataDir":             c.SPVDataDir,
 		"Solo":                   c.Solo,
+		"StratumListener":        c.StratumListener,
 		"TLS":                    c.TLS,
 		"TLSSkipVerify":          c.TLSSkipVerify,
 		"TorIsolation":           c.TorIsolation,
 		"TrickleInterval":        c.TrickleInterval,
 		"TxIndex":                c.TxIndex,
 		"UPNP":                   c.UPNP,
+		"UseSPV":                 c.UseSPV,
 		"UserAgentComments":      c.UserAgentComments,
 		"Username":               c.Username,
 		"Wallet":                 c.Wallet,
+		"WalletBirthday":         c.WalletBirthday,
 		"WalletFile":             c.WalletFile,
+		"WalletMetricsListener":  c.WalletMetricsListener,
 		"WalletOff":              c.WalletOff,
 		"WalletPass":             c.WalletPass,
 		"WalletRPCListeners":     c.WalletRPCListeners,
@@ -53,6 +53,20 @@ func NewGenerateCmd(numBlocks uint32) *GenerateCmd {
 	}
 }
 
+// GenerateAlgoCmd defines the generatealgo JSON-RPC command.  This command is not a standard Bitcoin command.  It is an extension for pod.
+type GenerateAlgoCmd struct {
+	NumBlocks uint32
+	Version   int32
+}
+
+// NewGenerateAlgoCmd returns a new instance which can be used to issue a generatealgo JSON-RPC command.
+func NewGenerateAlgoCmd(numBlocks uint32, version int32) *GenerateAlgoCmd {
+	return &GenerateAlgoCmd{
+		NumBlocks: numBlocks,
+		Version:   version,
+	}
+}
+
 // GetBestBlockCmd defines the getbestblock JSON-RPC command.
 type GetBestBlockCmd struct{}
 
@@ -84,6 +98,29 @@ func NewGetHeadersCmd(blockLocators []string, hashStop string) *GetHeadersCmd {
 	}
 }
 
+// GetPoolBalancesCmd defines the getpoolbalances JSON-RPC command.  This command is not a standard Bitcoin command.  It is an extension for pod.
+type GetPoolBalancesCmd struct{}
+
+// NewGetPoolBalancesCmd returns a new instance which can be used to issue a getpoolbalances JSON-RPC command.
+func NewGetPoolBalancesCmd() *GetPoolBalancesCmd {
+	return &GetPoolBalancesCmd{}
+}
+
+// PoolPayoutCmd defines the poolpayout JSON-RPC command.  This command is not a standard Bitcoin command.  It is an extension for pod.
+type PoolPayoutCmd struct {
+	MinAmount *float64 `jsonrpcdefault:"0"`
+	DryRun    *bool    `jsonrpcdefault:"false"`
+}
+
+// NewPoolPayoutCmd returns a new instance which can be used to issue a poolpayout JSON-RPC command.
+// The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+func NewPoolPayoutCmd(minAmount *float64, dryRun *bool) *PoolPayoutCmd {
+	return &PoolPayoutCmd{
+		MinAmount: minAmount,
+		DryRun:    dryRun,
+	}
+}
+
 // VersionCmd defines the version JSON-RPC command. NOTE: This is a btcsuite extension ported from github.com/decred/dcrd/dcrjson.
 type VersionCmd struct{}
 
@@ -98,8 +135,11 @@ func init() {
 	MustRegisterCmd("debuglevel", (*DebugLevelCmd)(nil), flags)
 	MustRegisterCmd("node", (*NodeCmd)(nil), flags)
 	MustRegisterCmd("generate", (*GenerateCmd)(nil), flags)
+	MustRegisterCmd("generatealgo", (*GenerateAlgoCmd)(nil), flags)
 	MustRegisterCmd("getbestblock", (*GetBestBlockCmd)(nil), flags)
 	MustRegisterCmd("getcurrentnet", (*GetCurrentNetCmd)(nil), flags)
 	MustRegisterCmd("getheaders", (*GetHeadersCmd)(nil), flags)
+	MustRegisterCmd("getpoolbalances", (*GetPoolBalancesCmd)(nil), flags)
+	MustRegisterCmd("poolpayout", (*PoolPayoutCmd)(nil), flags)
 	MustRegisterCmd("version", (*VersionCmd)(nil), flags)
 }
@@ -105,6 +105,20 @@ func TestPodExtCmds(t *testing.T) {
 				NumBlocks: 1,
 			},
 		},
+		{
+			name: "generatealgo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("generatealgo", 2, 7)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGenerateAlgoCmd(2, 7)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generatealgo","netparams":[2,7],"id":1}`,
+			unmarshalled: &btcjson.GenerateAlgoCmd{
+				NumBlocks: 2,
+				Version:   7,
+			},
+		},
 		{
 			name: "getbestblock",
 			newCmd: func() (interface{}, error) {
@@ -167,6 +181,46 @@ func TestPodExtCmds(t *testing.T) {
 				HashStop: "000000000000000000ba33b33e1fad70b69e234fc24414dd47113bff38f523f7",
 			},
 		},
+		{
+			name: "getpoolbalances",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getpoolbalances")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetPoolBalancesCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getpoolbalances","netparams":[],"id":1}`,
+			unmarshalled: &btcjson.GetPoolBalancesCmd{},
+		},
+		{
+			name: "poolpayout",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("poolpayout")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewPoolPayoutCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"poolpayout","netparams":[],"id":1}`,
+			unmarshalled: &btcjson.PoolPayoutCmd{
+				MinAmount: btcjson.Float64(0),
+				DryRun:    btcjson.Bool(false),
+			},
+		},
+		{
+			name: "poolpayout optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("poolpayout", 0.5, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewPoolPayoutCmd(btcjson.Float64(0.5),
+					btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"poolpayout","netparams":[0.5,true],"id":1}`,
+			unmarshalled: &btcjson.PoolPayoutCmd{
+				MinAmount: btcjson.Float64(0.5),
+				DryRun:    btcjson.Bool(true),
+			},
+		},
 		{
 			name: "version",
 			newCmd: func() (interface{}, error) {
@@ -9,3 +9,18 @@ type VersionResult struct {
 	Prerelease    string `json:"prerelease"`
 	BuildMetadata string `json:"buildmetadata"`
 }
+
+// GetPoolBalancesResult models the account of a pool worker returned by the getpoolbalances command.  This command is an extension for pod.
+type GetPoolBalancesResult struct {
+	Address  string  `json:"address"`
+	Balance  float64 `json:"balance"`
+	Immature float64 `json:"immature"`
+	Paid     float64 `json:"paid"`
+	Shares   float64 `json:"shares"`
+}
+
+// PoolPayoutResult models the data returned from the poolpayout command.  This command is an extension for pod.
+type PoolPayoutResult struct {
+	TxID    string             `json:"txid,omitempty"`
+	Payouts map[string]float64 `json:"payouts"`
+}
@@ -54,6 +54,24 @@ func NewImportPubKeyCmd(pubKey string, rescan *bool) *ImportPubKeyCmd {
 	}
 }
 
+// ImportXpubCmd defines the importxpub JSON-RPC command.
+type ImportXpubCmd struct {
+	Xpub        string
+	Account     string
+	Rescan      *bool   `jsonrpcdefault:"true"`
+	AddressType *string `jsonrpcdefault:"\"legacy\""`
+}
+
+// NewImportXpubCmd returns a new instance which can be used to issue an importxpub JSON-RPC command.
+func NewImportXpubCmd(xpub, account string, rescan *bool, addressType *string) *ImportXpubCmd {
+	return &ImportXpubCmd{
+		Xpub:        xpub,
+		Account:     account,
+		Rescan:      rescan,
+		AddressType: addressType,
+	}
+}
+
 // ImportWalletCmd defines the importwallet JSON-RPC command.
 type ImportWalletCmd struct {
 	Filename string
@@ -87,6 +105,7 @@ func init() {
 	MustRegisterCmd("importaddress", (*ImportAddressCmd)(nil), flags)
 	MustRegisterCmd("importpubkey", (*ImportPubKeyCmd)(nil), flags)
 	MustRegisterCmd("importwallet", (*ImportWalletCmd)(nil), flags)
+	MustRegisterCmd("importxpub", (*ImportXpubCmd)(nil), flags)
 	MustRegisterCmd("renameaccount", (*RenameAccountCmd)(nil), flags)
 
 }
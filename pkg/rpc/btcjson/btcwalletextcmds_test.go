@@ -104,6 +104,39 @@ func TestBtcWalletExtCmds(t *testing.T) {
 				Rescan: btcjson.Bool(false),
 			},
 		},
+		{
+			name: "importxpub",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("importxpub", "xpub", "acct")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewImportXpubCmd("xpub", "acct", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importxpub","netparams":["xpub","acct"],"id":1}`,
+			unmarshalled: &btcjson.ImportXpubCmd{
+				Xpub:        "xpub",
+				Account:     "acct",
+				Rescan:      btcjson.Bool(true),
+				AddressType: btcjson.String("legacy"),
+			},
+		},
+		{
+			name: "importxpub optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("importxpub", "xpub", "acct", false, "bech32")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewImportXpubCmd("xpub", "acct",
+					btcjson.Bool(false), btcjson.String("bech32"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importxpub","netparams":["xpub","acct",false,"bech32"],"id":1}`,
+			unmarshalled: &btcjson.ImportXpubCmd{
+				Xpub:        "xpub",
+				Account:     "acct",
+				Rescan:      btcjson.Bool(false),
+				AddressType: btcjson.String("bech32"),
+			},
+		},
 		{
 			name: "importwallet",
 			newCmd: func() (interface{}, error) {
@@ -33,6 +33,19 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"addnode","netparams":["127.0.0.1","remove"],"id":1}`,
 			unmarshalled: &btcjson.AddNodeCmd{Addr: "127.0.0.1", SubCmd: btcjson.ANRemove},
 		},
+		{
+			name: "combinepsbt",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("combinepsbt", []string{"cHNidP8=", "cHNidP8="})
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCombinePSBTCmd([]string{"cHNidP8=", "cHNidP8="})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"combinepsbt","netparams":[["cHNidP8=","cHNidP8="]],"id":1}`,
+			unmarshalled: &btcjson.CombinePSBTCmd{
+				PSBTs: []string{"cHNidP8=", "cHNidP8="},
+			},
+		},
 		{
 			name: "createrawtransaction",
 			newCmd: func() (interface{}, error) {
@@ -72,6 +85,17 @@ func TestChainSvrCmds(t *testing.T) {
 				LockTime: btcjson.Int64(12312333333),
 			},
 		},
+		{
+			name: "decodepsbt",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("decodepsbt", "cHNidP8=")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDecodePSBTCmd("cHNidP8=")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"decodepsbt","netparams":["cHNidP8="],"id":1}`,
+			unmarshalled: &btcjson.DecodePSBTCmd{PSBT: "cHNidP8="},
+		},
 		{
 			name: "decoderawtransaction",
 			newCmd: func() (interface{}, error) {
@@ -94,6 +118,34 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"decodescript","netparams":["00"],"id":1}`,
 			unmarshalled: &btcjson.DecodeScriptCmd{HexScript: "00"},
 		},
+		{
+			name: "finalizepsbt",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("finalizepsbt", "cHNidP8=")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewFinalizePSBTCmd("cHNidP8=", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"finalizepsbt","netparams":["cHNidP8="],"id":1}`,
+			unmarshalled: &btcjson.FinalizePSBTCmd{
+				PSBT:    "cHNidP8=",
+				Extract: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "finalizepsbt optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("finalizepsbt", "cHNidP8=", false)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewFinalizePSBTCmd("cHNidP8=", btcjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"finalizepsbt","netparams":["cHNidP8=",false],"id":1}`,
+			unmarshalled: &btcjson.FinalizePSBTCmd{
+				PSBT:    "cHNidP8=",
+				Extract: btcjson.Bool(false),
+			},
+		},
 		{
 			name: "getaddednodeinfo",
 			newCmd: func() (interface{}, error) {
@@ -405,6 +457,62 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getinfo","netparams":[],"id":1}`,
 			unmarshalled: &btcjson.GetInfoCmd{},
 		},
+		{
+			name: "getmempoolancestors",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getmempoolancestors", "txhash")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetMempoolAncestorsCmd("txhash", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempoolancestors","netparams":["txhash"],"id":1}`,
+			unmarshalled: &btcjson.GetMempoolAncestorsCmd{
+				TxID:    "txhash",
+				Verbose: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "getmempoolancestors verbose",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getmempoolancestors", "txhash", true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetMempoolAncestorsCmd("txhash", btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempoolancestors","netparams":["txhash",true],"id":1}`,
+			unmarshalled: &btcjson.GetMempoolAncestorsCmd{
+				TxID:    "txhash",
+				Verbose: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "getmempooldescendants",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getmempooldescendants", "txhash")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetMempoolDescendantsCmd("txhash", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempooldescendants","netparams":["txhash"],"id":1}`,
+			unmarshalled: &btcjson.GetMempoolDescendantsCmd{
+				TxID:    "txhash",
+				Verbose: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "getmempooldescendants verbose",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getmempooldescendants", "txhash", true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetMempoolDescendantsCmd("txhash", btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempooldescendants","netparams":["txhash",true],"id":1}`,
+			unmarshalled: &btcjson.GetMempoolDescendantsCmd{
+				TxID:    "txhash",
+				Verbose: btcjson.Bool(true),
+			},
+		},
 		{
 			name: "getmempoolentry",
 			newCmd: func() (interface{}, error) {
@@ -705,6 +813,17 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "123",
 			},
 		},
+		{
+			name: "loadmempool",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("loadmempool")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewLoadMempoolCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"loadmempool","netparams":[],"id":1}`,
+			unmarshalled: &btcjson.LoadMempoolCmd{},
+		},
 		{
 			name: "ping",
 			newCmd: func() (interface{}, error) {
@@ -729,6 +848,21 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "0123",
 			},
 		},
+		{
+			name: "prioritisetransaction",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("prioritisetransaction", "123", 0.0, 1000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewPrioritiseTransactionCmd("123", 0, 1000)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"prioritisetransaction","netparams":["123",0,1000],"id":1}`,
+			unmarshalled: &btcjson.PrioritiseTransactionCmd{
+				TxID:          "123",
+				PriorityDelta: 0,
+				FeeDelta:      1000,
+			},
+		},
 		{
 			name: "reconsiderblock",
 			newCmd: func() (interface{}, error) {
@@ -742,6 +876,17 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "123",
 			},
 		},
+		{
+			name: "savemempool",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("savemempool")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSaveMempoolCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"savemempool","netparams":[],"id":1}`,
+			unmarshalled: &btcjson.SaveMempoolCmd{},
+		},
 		{
 			name: "searchrawtransactions",
 			newCmd: func() (interface{}, error) {
@@ -19,6 +19,40 @@ type CreateMultiSigResult struct {
 	RedeemScript string `json:"redeemScript"`
 }
 
+// DecodePSBTInput models the data of an input in the reply of the decodepsbt
+// command.
+type DecodePSBTInput struct {
+	NonWitnessUtxo     *TxRawDecodeResult `json:"non_witness_utxo,omitempty"`
+	WitnessUtxo        *PSBTWitnessUtxo   `json:"witness_utxo,omitempty"`
+	PartialSignatures  map[string]string  `json:"partial_signatures,omitempty"`
+	Sighash            string             `json:"sighash,omitempty"`
+	RedeemScript       *PSBTScript        `json:"redeem_script,omitempty"`
+	WitnessScript      *PSBTScript        `json:"witness_script,omitempty"`
+	Bip32Derivs        []PSBTBip32Deriv   `json:"bip32_derivs,omitempty"`
+	FinalScriptSig     *ScriptSig         `json:"final_scriptSig,omitempty"`
+	FinalScriptWitness []string           `json:"final_scriptwitness,omitempty"`
+	Unknown            map[string]string  `json:"unknown,omitempty"`
+}
+
+// DecodePSBTOutput models the data of an output in the reply of the
+// decodepsbt command.
+type DecodePSBTOutput struct {
+	RedeemScript  *PSBTScript       `json:"redeem_script,omitempty"`
+	WitnessScript *PSBTScript       `json:"witness_script,omitempty"`
+	Bip32Derivs   []PSBTBip32Deriv  `json:"bip32_derivs,omitempty"`
+	Unknown       map[string]string `json:"unknown,omitempty"`
+}
+
+// DecodePSBTResult models the data returned from the decodepsbt command. The
+// fee is only set when the previous output of every input is known.
+type DecodePSBTResult struct {
+	Tx      TxRawDecodeResult  `json:"tx"`
+	Unknown map[string]string  `json:"unknown"`
+	Inputs  []DecodePSBTInput  `json:"inputs"`
+	Outputs []DecodePSBTOutput `json:"outputs"`
+	Fee     *float64           `json:"fee,omitempty"`
+}
+
 // DecodeScriptResult models the data returned from the decodescript command.
 type DecodeScriptResult struct {
 	Asm       string   `json:"asm"`
@@ -28,6 +62,15 @@ type DecodeScriptResult struct {
 	P2sh      string   `json:"p2sh,omitempty"`
 }
 
+// FinalizePSBTResult models the data returned from the finalizepsbt command.
+// The transaction is only extracted when the PSBT is complete and extraction
+// was asked for, and the PSBT is returned otherwise.
+type FinalizePSBTResult struct {
+	PSBT     string `json:"psbt,omitempty"`
+	Hex      string `json:"hex,omitempty"`
+	Complete bool   `json:"complete"`
+}
+
 // GetAddedNodeInfoResult models the data from the getaddednodeinfo command.
 type GetAddedNodeInfoResult struct {
 	AddedNode string                        `json:"addednode"`
@@ -64,6 +107,8 @@ type GetBlockHeaderVerboseResult struct {
 	Height        int32   `json:"height"`
 	Version       int32   `json:"version"`
 	VersionHex    string  `json:"versionHex"`
+	PowAlgoID     uint32  `json:"pow_algo_id"`
+	PowAlgo       string  `json:"pow_algo"`
 	MerkleRoot    string  `json:"merkleroot"`
 	Time          int64   `json:"time"`
 	Nonce         uint64  `json:"nonce"`
@@ -148,6 +193,14 @@ type GetBlockVerboseResult struct {
 	NextHash      string        `json:"nextblockhash,omitempty"`
 }
 
+// GetChainTipsResult models the data of each tip returned from the getchaintips command.
+type GetChainTipsResult struct {
+	Height    int32  `json:"height"`
+	Hash      string `json:"hash"`
+	BranchLen int32  `json:"branchlen"`
+	Status    string `json:"status"`
+}
+
 // GetMempoolEntryResult models the data returned from the getmempoolentry command.
 type GetMempoolEntryResult struct {
 	Size             int32    `json:"size"`
@@ -168,8 +221,12 @@ type GetMempoolEntryResult struct {
 
 // GetMempoolInfoResult models the data returned from the getmempoolinfo command.
 type GetMempoolInfoResult struct {
-	Size  int64 `json:"size"`
-	Bytes int64 `json:"bytes"`
+	Size          int64   `json:"size"`
+	Bytes         int64   `json:"bytes"`
+	Usage         int64   `json:"usage"`
+	MaxMempool    int64   `json:"maxmempool"`
+	MempoolMinFee float64 `json:"mempoolminfee"`
+	MinRelayTxFee float64 `json:"minrelaytxfee"`
 }
 
 // GetMiningInfoResult models the data from the getmininginfo command.
@@ -357,6 +414,25 @@ type (
 		Addresses []string `json:"addresses,omitempty"`
 		Value     float64  `json:"value"`
 	}
+	// PSBTBip32Deriv models a BIP32 key derivation of a PSBT input or
+	// output.
+	PSBTBip32Deriv struct {
+		PubKey            string `json:"pubkey"`
+		MasterFingerprint string `json:"master_fingerprint"`
+		Path              string `json:"path"`
+	}
+	// PSBTScript models a redeem or witness script of a PSBT input or output.
+	PSBTScript struct {
+		Asm  string `json:"asm"`
+		Hex  string `json:"hex"`
+		Type string `json:"type"`
+	}
+	// PSBTWitnessUtxo models the previous output of a witness input of a
+	// PSBT.
+	PSBTWitnessUtxo struct {
+		Amount       float64            `json:"amount"`
+		ScriptPubKey ScriptPubKeyResult `json:"scriptPubKey"`
+	}
 	// ScriptPubKeyResult models the scriptPubKey data of a tx script. It is defined separately since it is used by multiple commands.
 	ScriptPubKeyResult struct {
 		Asm       string   `json:"asm"`
@@ -28,6 +28,37 @@ func NewAddWitnessAddressCmd(address string) *AddWitnessAddressCmd {
 	}
 }
 
+// BackupWalletCmd defines the backupwallet JSON-RPC command.
+type BackupWalletCmd struct {
+	Destination string
+}
+
+// NewBackupWalletCmd returns a new instance which can be used to issue a backupwallet JSON-RPC command.
+func NewBackupWalletCmd(destination string) *BackupWalletCmd {
+	return &BackupWalletCmd{
+		Destination: destination,
+	}
+}
+
+// BumpFeeOptions models the options of the bumpfee JSON-RPC command.
+type BumpFeeOptions struct {
+	FeeRate *float64 `json:"fee_rate,omitempty"` // In DUO/kB
+}
+
+// BumpFeeCmd defines the bumpfee JSON-RPC command.
+type BumpFeeCmd struct {
+	TxID    string
+	Options *BumpFeeOptions
+}
+
+// NewBumpFeeCmd returns a new instance which can be used to issue a bumpfee JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+func NewBumpFeeCmd(txID string, options *BumpFeeOptions) *BumpFeeCmd {
+	return &BumpFeeCmd{
+		TxID:    txID,
+		Options: options,
+	}
+}
+
 // CreateMultisigCmd defines the createmultisig JSON-RPC command.
 type CreateMultisigCmd struct {
 	NRequired int
@@ -136,15 +167,17 @@ func NewGetAddressesByAccountCmd(account string) *GetAddressesByAccountCmd {
 
 // GetBalanceCmd defines the getbalance JSON-RPC command.
 type GetBalanceCmd struct {
-	Account *string
-	MinConf *int `jsonrpcdefault:"1"`
+	Account          *string
+	MinConf          *int  `jsonrpcdefault:"1"`
+	IncludeWatchOnly *bool `jsonrpcdefault:"false"`
 }
 
 // NewGetBalanceCmd returns a new instance which can be used to issue a getbalance JSON-RPC command. The parameters that are pointers indicate they are optional. Passing nil for optional parameters will use the default value.
-func NewGetBalanceCmd(account *string, minConf *int) *GetBalanceCmd {
+func NewGetBalanceCmd(account *string, minConf *int, includeWatchOnly *bool) *GetBalanceCmd {
 	return &GetBalanceCmd{
-		Account: account,
-		MinConf: minConf,
+		Account:          account,
+		MinConf:          minConf,
+		IncludeWatchOnly: includeWatchOnly,
 	}
 }
 
@@ -418,37 +451,41 @@ func NewSendFromCmd(fromAccount, toAddress string, amount float64, minConf *int,
 
 // SendManyCmd defines the sendmany JSON-RPC command.
 type SendManyCmd struct {
-	FromAccount string
-	Amounts     map[string]float64 `jsonrpcusage:"{\"address\":amount,...}"` // In DUO
-	MinConf     *int               `jsonrpcdefault:"1"`
-	Comment     *string
+	FromAccount   string
+	Amounts       map[string]float64 `jsonrpcusage:"{\"address\":amount,...}"` // In DUO
+	MinConf       *int               `jsonrpcdefault:"1"`
+	Comment       *string
+	CoinSelection *string `jsonrpcdefault:"\"bnb\""`
 }
 
 // NewSendManyCmd returns a new instance which can be used to issue a sendmany JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
-func NewSendManyCmd(fromAccount string, amounts map[string]float64, minConf *int, comment *string) *SendManyCmd {
+func NewSendManyCmd(fromAccount string, amounts map[string]float64, minConf *int, comment *string, coinSelection *string) *SendManyCmd {
 	return &SendManyCmd{
-		FromAccount: fromAccount,
-		Amounts:     amounts,
-		MinConf:     minConf,
-		Comment:     comment,
+		FromAccount:   fromAccount,
+		Amounts:       amounts,
+		MinConf:       minConf,
+		Comment:       comment,
+		CoinSelection: coinSelection,
 	}
 }
 
 // SendToAddressCmd defines the sendtoaddress JSON-RPC command.
 type SendToAddressCmd struct {
-	Address   string
-	Amount    float64
-	Comment   *string
-	CommentTo *string
+	Address       string
+	Amount        float64
+	Comment       *string
+	CommentTo     *string
+	CoinSelection *string `jsonrpcdefault:"\"bnb\""`
 }
 
 // NewSendToAddressCmd returns a new instance which can be used to issue a sendtoaddress JSON-RPC command. The parameters which are pointers indicate they are optional. Passing nil for optional parameters will use the default value.
-func NewSendToAddressCmd(address string, amount float64, comment, commentTo *string) *SendToAddressCmd {
+func NewSendToAddressCmd(address string, amount float64, comment, commentTo, coinSelection *string) *SendToAddressCmd {
 	return &SendToAddressCmd{
-		Address:   address,
-		Amount:    amount,
-		Comment:   comment,
-		CommentTo: commentTo,
+		Address:       address,
+		Amount:        amount,
+		Comment:       comment,
+		CommentTo:     commentTo,
+		CoinSelection: coinSelection,
 	}
 }
 
@@ -518,6 +555,34 @@ func NewSignRawTransactionCmd(hexEncodedTx string, inputs *[]RawTxInput, privKey
 	}
 }
 
+// WalletCreateFundedPSBTOptions models the options of the walletcreatefundedpsbt JSON-RPC command.
+type WalletCreateFundedPSBTOptions struct {
+	ChangeAddress  *string  `json:"changeAddress,omitempty"`
+	ChangePosition *int64   `json:"changePosition,omitempty"`
+	LockUnspents   *bool    `json:"lockUnspents,omitempty"`
+	FeeRate        *float64 `json:"feeRate,omitempty"` // In DUO/kB
+	Replaceable    *bool    `json:"replaceable,omitempty"`
+}
+
+// WalletCreateFundedPSBTCmd defines the walletcreatefundedpsbt JSON-RPC command.
+type WalletCreateFundedPSBTCmd struct {
+	Inputs   []TransactionInput
+	Outputs  map[string]float64 `jsonrpcusage:"{\"address\":amount,...}"` // In DUO
+	LockTime *int64
+	Options  *WalletCreateFundedPSBTOptions
+}
+
+// NewWalletCreateFundedPSBTCmd returns a new instance which can be used to issue a walletcreatefundedpsbt JSON-RPC command. Amounts are in DUO. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+func NewWalletCreateFundedPSBTCmd(inputs []TransactionInput, outputs map[string]float64, lockTime *int64,
+	options *WalletCreateFundedPSBTOptions) *WalletCreateFundedPSBTCmd {
+	return &WalletCreateFundedPSBTCmd{
+		Inputs:   inputs,
+		Outputs:  outputs,
+		LockTime: lockTime,
+		Options:  options,
+	}
+}
+
 // WalletLockCmd defines the walletlock JSON-RPC command.
 type WalletLockCmd struct{}
 
@@ -553,11 +618,29 @@ func NewWalletPassphraseChangeCmd(oldPassphrase, newPassphrase string) *WalletPa
 		NewPassphrase: newPassphrase,
 	}
 }
+
+// WalletProcessPSBTCmd defines the walletprocesspsbt JSON-RPC command.
+type WalletProcessPSBTCmd struct {
+	PSBT        string
+	Sign        *bool   `jsonrpcdefault:"true"`
+	SighashType *string `jsonrpcdefault:"\"ALL\""`
+}
+
+// NewWalletProcessPSBTCmd returns a new instance which can be used to issue a walletprocesspsbt JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+func NewWalletProcessPSBTCmd(psbt string, sign *bool, sighashType *string) *WalletProcessPSBTCmd {
+	return &WalletProcessPSBTCmd{
+		PSBT:        psbt,
+		Sign:        sign,
+		SighashType: sighashType,
+	}
+}
 func init() {
 	// The commands in this file are only usable with a wallet server.
 	flags := UFWalletOnly
 	MustRegisterCmd("addmultisigaddress", (*AddMultisigAddressCmd)(nil), flags)
 	MustRegisterCmd("addwitnessaddress", (*AddWitnessAddressCmd)(nil), flags)
+	MustRegisterCmd("backupwallet", (*BackupWalletCmd)(nil), flags)
+	MustRegisterCmd("bumpfee", (*BumpFeeCmd)(nil), flags)
 	MustRegisterCmd("createmultisig", (*CreateMultisigCmd)(nil), flags)
 	MustRegisterCmd("dropwallethistory", (*DropWalletHistoryCmd)(nil), flags)
 	MustRegisterCmd("dumpprivkey", (*DumpPrivKeyCmd)(nil), flags)
@@ -593,7 +676,9 @@ func init() {
 	MustRegisterCmd("settxfee", (*SetTxFeeCmd)(nil), flags)
 	MustRegisterCmd("signmessage", (*SignMessageCmd)(nil), flags)
 	MustRegisterCmd("signrawtransaction", (*SignRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("walletcreatefundedpsbt", (*WalletCreateFundedPSBTCmd)(nil), flags)
 	MustRegisterCmd("walletlock", (*WalletLockCmd)(nil), flags)
 	MustRegisterCmd("walletpassphrase", (*WalletPassphraseCmd)(nil), flags)
 	MustRegisterCmd("walletpassphrasechange", (*WalletPassphraseChangeCmd)(nil), flags)
+	MustRegisterCmd("walletprocesspsbt", (*WalletProcessPSBTCmd)(nil), flags)
 }
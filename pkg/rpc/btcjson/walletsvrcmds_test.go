@@ -66,6 +66,52 @@ func TestWalletSvrCmds(t *testing.T) {
 				Address: "1address",
 			},
 		},
+		{
+			name: "backupwallet",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("backupwallet", "/backups/wallet.db")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewBackupWalletCmd("/backups/wallet.db")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"backupwallet","netparams":["/backups/wallet.db"],"id":1}`,
+			unmarshalled: &btcjson.BackupWalletCmd{
+				Destination: "/backups/wallet.db",
+			},
+		},
+		{
+			name: "bumpfee",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("bumpfee", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewBumpFeeCmd("123", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"bumpfee","netparams":["123"],"id":1}`,
+			unmarshalled: &btcjson.BumpFeeCmd{
+				TxID:    "123",
+				Options: nil,
+			},
+		},
+		{
+			name: "bumpfee optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("bumpfee", "123",
+					`{"fee_rate":0.0002}`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewBumpFeeCmd("123", &btcjson.BumpFeeOptions{
+					FeeRate: btcjson.Float64(0.0002),
+				})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"bumpfee","netparams":["123",{"fee_rate":0.0002}],"id":1}`,
+			unmarshalled: &btcjson.BumpFeeCmd{
+				TxID: "123",
+				Options: &btcjson.BumpFeeOptions{
+					FeeRate: btcjson.Float64(0.0002),
+				},
+			},
+		},
 		{
 			name: "createmultisig",
 			newCmd: func() (interface{}, error) {
@@ -178,12 +224,13 @@ func TestWalletSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("getbalance")
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetBalanceCmd(nil, nil)
+				return btcjson.NewGetBalanceCmd(nil, nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getbalance","netparams":[],"id":1}`,
 			unmarshalled: &btcjson.GetBalanceCmd{
-				Account: nil,
-				MinConf: btcjson.Int(1),
+				Account:          nil,
+				MinConf:          btcjson.Int(1),
+				IncludeWatchOnly: btcjson.Bool(false),
 			},
 		},
 		{
@@ -192,12 +239,13 @@ func TestWalletSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("getbalance", "acct")
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetBalanceCmd(btcjson.String("acct"), nil)
+				return btcjson.NewGetBalanceCmd(btcjson.String("acct"), nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getbalance","netparams":["acct"],"id":1}`,
 			unmarshalled: &btcjson.GetBalanceCmd{
-				Account: btcjson.String("acct"),
-				MinConf: btcjson.Int(1),
+				Account:          btcjson.String("acct"),
+				MinConf:          btcjson.Int(1),
+				IncludeWatchOnly: btcjson.Bool(false),
 			},
 		},
 		{
@@ -206,12 +254,29 @@ func TestWalletSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("getbalance", "acct", 6)
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetBalanceCmd(btcjson.String("acct"), btcjson.Int(6))
+				return btcjson.NewGetBalanceCmd(btcjson.String("acct"), btcjson.Int(6), nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getbalance","netparams":["acct",6],"id":1}`,
 			unmarshalled: &btcjson.GetBalanceCmd{
-				Account: btcjson.String("acct"),
-				MinConf: btcjson.Int(6),
+				Account:          btcjson.String("acct"),
+				MinConf:          btcjson.Int(6),
+				IncludeWatchOnly: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "getbalance optional3",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getbalance", "*", 6, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBalanceCmd(btcjson.String("*"),
+					btcjson.Int(6), btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getbalance","netparams":["*",6,true],"id":1}`,
+			unmarshalled: &btcjson.GetBalanceCmd{
+				Account:          btcjson.String("*"),
+				MinConf:          btcjson.Int(6),
+				IncludeWatchOnly: btcjson.Bool(true),
 			},
 		},
 		{
@@ -954,14 +1019,15 @@ func TestWalletSvrCmds(t *testing.T) {
 			},
 			staticCmd: func() interface{} {
 				amounts := map[string]float64{"1Address": 0.5}
-				return btcjson.NewSendManyCmd("from", amounts, nil, nil)
+				return btcjson.NewSendManyCmd("from", amounts, nil, nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"sendmany","netparams":["from",{"1Address":0.5}],"id":1}`,
 			unmarshalled: &btcjson.SendManyCmd{
-				FromAccount: "from",
-				Amounts:     map[string]float64{"1Address": 0.5},
-				MinConf:     btcjson.Int(1),
-				Comment:     nil,
+				FromAccount:   "from",
+				Amounts:       map[string]float64{"1Address": 0.5},
+				MinConf:       btcjson.Int(1),
+				Comment:       nil,
+				CoinSelection: btcjson.String("bnb"),
 			},
 		},
 		{
@@ -971,14 +1037,15 @@ func TestWalletSvrCmds(t *testing.T) {
 			},
 			staticCmd: func() interface{} {
 				amounts := map[string]float64{"1Address": 0.5}
-				return btcjson.NewSendManyCmd("from", amounts, btcjson.Int(6), nil)
+				return btcjson.NewSendManyCmd("from", amounts, btcjson.Int(6), nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"sendmany","netparams":["from",{"1Address":0.5},6],"id":1}`,
 			unmarshalled: &btcjson.SendManyCmd{
-				FromAccount: "from",
-				Amounts:     map[string]float64{"1Address": 0.5},
-				MinConf:     btcjson.Int(6),
-				Comment:     nil,
+				FromAccount:   "from",
+				Amounts:       map[string]float64{"1Address": 0.5},
+				MinConf:       btcjson.Int(6),
+				Comment:       nil,
+				CoinSelection: btcjson.String("bnb"),
 			},
 		},
 		{
@@ -988,14 +1055,34 @@ func TestWalletSvrCmds(t *testing.T) {
 			},
 			staticCmd: func() interface{} {
 				amounts := map[string]float64{"1Address": 0.5}
-				return btcjson.NewSendManyCmd("from", amounts, btcjson.Int(6), btcjson.String("comment"))
+				return btcjson.NewSendManyCmd("from", amounts, btcjson.Int(6), btcjson.String("comment"), nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"sendmany","netparams":["from",{"1Address":0.5},6,"comment"],"id":1}`,
 			unmarshalled: &btcjson.SendManyCmd{
-				FromAccount: "from",
-				Amounts:     map[string]float64{"1Address": 0.5},
-				MinConf:     btcjson.Int(6),
-				Comment:     btcjson.String("comment"),
+				FromAccount:   "from",
+				Amounts:       map[string]float64{"1Address": 0.5},
+				MinConf:       btcjson.Int(6),
+				Comment:       btcjson.String("comment"),
+				CoinSelection: btcjson.String("bnb"),
+			},
+		},
+		{
+			name: "sendmany optional3",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("sendmany", "from", `{"1Address":0.5}`, 6, "comment", "avoidreuse")
+			},
+			staticCmd: func() interface{} {
+				amounts := map[string]float64{"1Address": 0.5}
+				return btcjson.NewSendManyCmd("from", amounts, btcjson.Int(6), btcjson.String("comment"),
+					btcjson.String("avoidreuse"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"sendmany","netparams":["from",{"1Address":0.5},6,"comment","avoidreuse"],"id":1}`,
+			unmarshalled: &btcjson.SendManyCmd{
+				FromAccount:   "from",
+				Amounts:       map[string]float64{"1Address": 0.5},
+				MinConf:       btcjson.Int(6),
+				Comment:       btcjson.String("comment"),
+				CoinSelection: btcjson.String("avoidreuse"),
 			},
 		},
 		{
@@ -1004,14 +1091,15 @@ func TestWalletSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("sendtoaddress", "1Address", 0.5)
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewSendToAddressCmd("1Address", 0.5, nil, nil)
+				return btcjson.NewSendToAddressCmd("1Address", 0.5, nil, nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"sendtoaddress","netparams":["1Address",0.5],"id":1}`,
 			unmarshalled: &btcjson.SendToAddressCmd{
-				Address:   "1Address",
-				Amount:    0.5,
-				Comment:   nil,
-				CommentTo: nil,
+				Address:       "1Address",
+				Amount:        0.5,
+				Comment:       nil,
+				CommentTo:     nil,
+				CoinSelection: btcjson.String("bnb"),
 			},
 		},
 		{
@@ -1021,14 +1109,33 @@ func TestWalletSvrCmds(t *testing.T) {
 			},
 			staticCmd: func() interface{} {
 				return btcjson.NewSendToAddressCmd("1Address", 0.5, btcjson.String("comment"),
-					btcjson.String("commentto"))
+					btcjson.String("commentto"), nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"sendtoaddress","netparams":["1Address",0.5,"comment","commentto"],"id":1}`,
 			unmarshalled: &btcjson.SendToAddressCmd{
-				Address:   "1Address",
-				Amount:    0.5,
-				Comment:   btcjson.String("comment"),
-				CommentTo: btcjson.String("commentto"),
+				Address:       "1Address",
+				Amount:        0.5,
+				Comment:       btcjson.String("comment"),
+				CommentTo:     btcjson.String("commentto"),
+				CoinSelection: btcjson.String("bnb"),
+			},
+		},
+		{
+			name: "sendtoaddress optional2",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("sendtoaddress", "1Address", 0.5, "comment", "commentto", "consolidate")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSendToAddressCmd("1Address", 0.5, btcjson.String("comment"),
+					btcjson.String("commentto"), btcjson.String("consolidate"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"sendtoaddress","netparams":["1Address",0.5,"comment","commentto","consolidate"],"id":1}`,
+			unmarshalled: &btcjson.SendToAddressCmd{
+				Address:       "1Address",
+				Amount:        0.5,
+				Comment:       btcjson.String("comment"),
+				CommentTo:     btcjson.String("commentto"),
+				CoinSelection: btcjson.String("consolidate"),
 			},
 		},
 		{
@@ -1156,6 +1263,49 @@ func TestWalletSvrCmds(t *testing.T) {
 				Flags:    btcjson.String("ALL"),
 			},
 		},
+		{
+			name: "walletcreatefundedpsbt",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("walletcreatefundedpsbt", `[]`, `{"1Address":0.5}`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWalletCreateFundedPSBTCmd([]btcjson.TransactionInput{},
+					map[string]float64{"1Address": 0.5}, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"walletcreatefundedpsbt","netparams":[[],{"1Address":0.5}],"id":1}`,
+			unmarshalled: &btcjson.WalletCreateFundedPSBTCmd{
+				Inputs:  []btcjson.TransactionInput{},
+				Outputs: map[string]float64{"1Address": 0.5},
+			},
+		},
+		{
+			name: "walletcreatefundedpsbt optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("walletcreatefundedpsbt", `[{"txid":"123","vout":1}]`,
+					`{"1Address":0.5}`, 0, `{"changePosition":1,"lockUnspents":true,"feeRate":0.0002}`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWalletCreateFundedPSBTCmd(
+					[]btcjson.TransactionInput{{Txid: "123", Vout: 1}},
+					map[string]float64{"1Address": 0.5}, btcjson.Int64(0),
+					&btcjson.WalletCreateFundedPSBTOptions{
+						ChangePosition: btcjson.Int64(1),
+						LockUnspents:   btcjson.Bool(true),
+						FeeRate:        btcjson.Float64(0.0002),
+					})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"walletcreatefundedpsbt","netparams":[[{"txid":"123","vout":1}],{"1Address":0.5},0,{"changePosition":1,"lockUnspents":true,"feeRate":0.0002}],"id":1}`,
+			unmarshalled: &btcjson.WalletCreateFundedPSBTCmd{
+				Inputs:   []btcjson.TransactionInput{{Txid: "123", Vout: 1}},
+				Outputs:  map[string]float64{"1Address": 0.5},
+				LockTime: btcjson.Int64(0),
+				Options: &btcjson.WalletCreateFundedPSBTOptions{
+					ChangePosition: btcjson.Int64(1),
+					LockUnspents:   btcjson.Bool(true),
+					FeeRate:        btcjson.Float64(0.0002),
+				},
+			},
+		},
 		{
 			name: "walletlock",
 			newCmd: func() (interface{}, error) {
@@ -1195,6 +1345,37 @@ func TestWalletSvrCmds(t *testing.T) {
 				NewPassphrase: "new",
 			},
 		},
+		{
+			name: "walletprocesspsbt",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("walletprocesspsbt", "cHNidP8=")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWalletProcessPSBTCmd("cHNidP8=", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"walletprocesspsbt","netparams":["cHNidP8="],"id":1}`,
+			unmarshalled: &btcjson.WalletProcessPSBTCmd{
+				PSBT:        "cHNidP8=",
+				Sign:        btcjson.Bool(true),
+				SighashType: btcjson.String("ALL"),
+			},
+		},
+		{
+			name: "walletprocesspsbt optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("walletprocesspsbt", "cHNidP8=", false, "SINGLE")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWalletProcessPSBTCmd("cHNidP8=", btcjson.Bool(false),
+					btcjson.String("SINGLE"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"walletprocesspsbt","netparams":["cHNidP8=",false,"SINGLE"],"id":1}`,
+			unmarshalled: &btcjson.WalletProcessPSBTCmd{
+				PSBT:        "cHNidP8=",
+				Sign:        btcjson.Bool(false),
+				SighashType: btcjson.String("SINGLE"),
+			},
+		},
 	}
 	t.Logf("Running %d tests", len(tests))
 	for i, test := range tests {
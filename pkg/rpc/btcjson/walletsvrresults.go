@@ -1,6 +1,17 @@
 package btcjson
 
 type (
+	// BumpFeeResult models the data from the bumpfee command.
+	BumpFeeResult struct {
+		TxID    string   `json:"txid"`
+		OrigFee float64  `json:"origfee"`
+		Fee     float64  `json:"fee"`
+		Errors  []string `json:"errors"`
+	}
+	// DumpWalletResult models the data from the dumpwallet command.
+	DumpWalletResult struct {
+		Filename string `json:"filename"`
+	}
 	// GetTransactionDetailsResult models the details data from the gettransaction command. This models the "short" version of the ListTransactionsResult type, which excludes fields common to the transaction.  These common fields are instead part of the GetTransactionResult.
 	GetTransactionDetailsResult struct {
 		Account           string   `json:"account"`
@@ -26,6 +37,19 @@ type (
 		Details         []GetTransactionDetailsResult `json:"details"`
 		Hex             string                        `json:"hex"`
 	}
+	// GetWalletInfoResult models the data from the getwalletinfo command.
+	GetWalletInfoResult struct {
+		WalletVersion         int32   `json:"walletversion"`
+		Balance               float64 `json:"balance"`
+		UnconfirmedBalance    float64 `json:"unconfirmed_balance"`
+		ImmatureBalance       float64 `json:"immature_balance"`
+		TxCount               int64   `json:"txcount"`
+		KeypoolSize           int32   `json:"keypoolsize"`
+		KeypoolSizeHDInternal int32   `json:"keypoolsize_hd_internal"`
+		UnlockedUntil         int64   `json:"unlocked_until"`
+		PaytxFee              float64 `json:"paytxfee"`
+		PrivateKeysEnabled    bool    `json:"private_keys_enabled"`
+	}
 	// InfoWalletResult models the data returned by the wallet server getinfo command.
 	InfoWalletResult struct {
 		Version         int32   `json:"version"`
@@ -69,6 +93,12 @@ type (
 		Comment           string   `json:"comment,omitempty"`
 		OtherAccount      string   `json:"otheraccount,omitempty"`
 	}
+	// ListAddressGroupingsResult models an address of a group from the listaddressgroupings command.
+	ListAddressGroupingsResult struct {
+		Address string  `json:"address"`
+		Amount  float64 `json:"amount"`
+		Account string  `json:"account"`
+	}
 	// ListReceivedByAccountResult models the data from the listreceivedbyaccount command.
 	ListReceivedByAccountResult struct {
 		Account       string  `json:"account"`
@@ -135,4 +165,15 @@ type (
 		Hash   string `json:"hash"`
 		Height int32  `json:"height"`
 	}
+	// WalletCreateFundedPSBTResult models the data from the walletcreatefundedpsbt command.
+	WalletCreateFundedPSBTResult struct {
+		PSBT      string  `json:"psbt"`
+		Fee       float64 `json:"fee"`
+		ChangePos int64   `json:"changepos"`
+	}
+	// WalletProcessPSBTResult models the data from the walletprocesspsbt command.
+	WalletProcessPSBTResult struct {
+		PSBT     string `json:"psbt"`
+		Complete bool   `json:"complete"`
+	}
 )
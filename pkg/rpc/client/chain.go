@@ -8,6 +8,7 @@ import (
 	chainhash "github.com/p9c/pod/pkg/chain/hash"
 	"github.com/p9c/pod/pkg/chain/wire"
 	"github.com/p9c/pod/pkg/rpc/btcjson"
+	"github.com/p9c/pod/pkg/util"
 )
 
 // FutureGetBestBlockHashResult is a future promise to deliver the result of a GetBestBlockAsync RPC invocation (or an applicable error).
@@ -373,6 +374,70 @@ func (c *Client) GetMempoolEntry(txHash string) (*btcjson.GetMempoolEntryResult,
 	return c.GetMempoolEntryAsync(txHash).Receive()
 }
 
+// FutureGetMempoolEntriesResult is a future promise to deliver the result of a GetMempoolAncestorsVerboseAsync or GetMempoolDescendantsVerboseAsync RPC invocation (or an applicable error).
+type FutureGetMempoolEntriesResult chan *response
+
+// Receive waits for the response promised by the future and returns a map of transaction hashes to an associated data structure with information about the transaction in the memory pool.
+func (r FutureGetMempoolEntriesResult) Receive() (map[string]btcjson.GetMempoolEntryResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	// Unmarshal the result as a map of strings (tx shas) to their detailed results.
+	var entries map[string]btcjson.GetMempoolEntryResult
+	err = js.Unmarshal(res, &entries)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetMempoolAncestorsAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See GetMempoolAncestors for the blocking version and more details.
+func (c *Client) GetMempoolAncestorsAsync(txHash string) FutureGetRawMempoolResult {
+	cmd := btcjson.NewGetMempoolAncestorsCmd(txHash, btcjson.Bool(false))
+	return c.sendCmd(cmd)
+}
+
+// GetMempoolAncestors returns the hashes of the transactions in the memory pool that a transaction spends from, directly or through other transactions in the pool. See GetMempoolAncestorsVerbose to retrieve data structures with information about the transactions instead.
+func (c *Client) GetMempoolAncestors(txHash string) ([]*chainhash.Hash, error) {
+	return c.GetMempoolAncestorsAsync(txHash).Receive()
+}
+
+// GetMempoolAncestorsVerboseAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See GetMempoolAncestorsVerbose for the blocking version and more details.
+func (c *Client) GetMempoolAncestorsVerboseAsync(txHash string) FutureGetMempoolEntriesResult {
+	cmd := btcjson.NewGetMempoolAncestorsCmd(txHash, btcjson.Bool(true))
+	return c.sendCmd(cmd)
+}
+
+// GetMempoolAncestorsVerbose returns a map of transaction hashes to an associated data structure with information about the transaction for the transactions in the memory pool that a transaction spends from. See GetMempoolAncestors to retrieve only the transaction hashes instead.
+func (c *Client) GetMempoolAncestorsVerbose(txHash string) (map[string]btcjson.GetMempoolEntryResult, error) {
+	return c.GetMempoolAncestorsVerboseAsync(txHash).Receive()
+}
+
+// GetMempoolDescendantsAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See GetMempoolDescendants for the blocking version and more details.
+func (c *Client) GetMempoolDescendantsAsync(txHash string) FutureGetRawMempoolResult {
+	cmd := btcjson.NewGetMempoolDescendantsCmd(txHash, btcjson.Bool(false))
+	return c.sendCmd(cmd)
+}
+
+// GetMempoolDescendants returns the hashes of the transactions in the memory pool that spend from a transaction, directly or through other transactions in the pool. See GetMempoolDescendantsVerbose to retrieve data structures with information about the transactions instead.
+func (c *Client) GetMempoolDescendants(txHash string) ([]*chainhash.Hash, error) {
+	return c.GetMempoolDescendantsAsync(txHash).Receive()
+}
+
+// GetMempoolDescendantsVerboseAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See GetMempoolDescendantsVerbose for the blocking version and more details.
+func (c *Client) GetMempoolDescendantsVerboseAsync(txHash string) FutureGetMempoolEntriesResult {
+	cmd := btcjson.NewGetMempoolDescendantsCmd(txHash, btcjson.Bool(true))
+	return c.sendCmd(cmd)
+}
+
+// GetMempoolDescendantsVerbose returns a map of transaction hashes to an associated data structure with information about the transaction for the transactions in the memory pool that spend from a transaction. See GetMempoolDescendants to retrieve only the transaction hashes instead.
+func (c *Client) GetMempoolDescendantsVerbose(txHash string) (map[string]btcjson.GetMempoolEntryResult, error) {
+	return c.GetMempoolDescendantsVerboseAsync(txHash).Receive()
+}
+
 // FutureGetRawMempoolResult is a future promise to deliver the result of a GetRawMempoolAsync RPC invocation (or an applicable error).
 type FutureGetRawMempoolResult chan *response
 
@@ -626,6 +691,82 @@ func (c *Client) InvalidateBlock(blockHash *chainhash.Hash) error {
 	return c.InvalidateBlockAsync(blockHash).Receive()
 }
 
+// FuturePreciousBlockResult is a future promise to deliver the result of a PreciousBlockAsync RPC invocation (or an applicable error).
+type FuturePreciousBlockResult chan *response
+
+// Receive waits for the response promised by the future and returns an error if the block could not be made the tip of the main chain.
+func (r FuturePreciousBlockResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// PreciousBlockAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See PreciousBlock for the blocking version and more details.
+func (c *Client) PreciousBlockAsync(blockHash *chainhash.Hash) FuturePreciousBlockResult {
+	hash := ""
+	if blockHash != nil {
+		hash = blockHash.String()
+	}
+	cmd := btcjson.NewPreciousBlockCmd(hash)
+	return c.sendCmd(cmd)
+}
+
+// PreciousBlock treats a block as if it were received before others with the same work.
+func (c *Client) PreciousBlock(blockHash *chainhash.Hash) error {
+	return c.PreciousBlockAsync(blockHash).Receive()
+}
+
+// FutureReconsiderBlockResult is a future promise to deliver the result of a ReconsiderBlockAsync RPC invocation (or an applicable error).
+type FutureReconsiderBlockResult chan *response
+
+// Receive waits for the response promised by the future and returns an error if the block could not be reconsidered.
+func (r FutureReconsiderBlockResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// ReconsiderBlockAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See ReconsiderBlock for the blocking version and more details.
+func (c *Client) ReconsiderBlockAsync(blockHash *chainhash.Hash) FutureReconsiderBlockResult {
+	hash := ""
+	if blockHash != nil {
+		hash = blockHash.String()
+	}
+	cmd := btcjson.NewReconsiderBlockCmd(hash)
+	return c.sendCmd(cmd)
+}
+
+// ReconsiderBlock removes the invalid status of a block and its descendants and ancestors.
+func (c *Client) ReconsiderBlock(blockHash *chainhash.Hash) error {
+	return c.ReconsiderBlockAsync(blockHash).Receive()
+}
+
+// FutureGetChainTipsResult is a future promise to deliver the result of a GetChainTipsAsync RPC invocation (or an applicable error).
+type FutureGetChainTipsResult chan *response
+
+// Receive waits for the response promised by the future and returns the tips of the branches of the block index.
+func (r FutureGetChainTipsResult) Receive() ([]btcjson.GetChainTipsResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	var tips []btcjson.GetChainTipsResult
+	if err := js.Unmarshal(res, &tips); err != nil {
+		return nil, err
+	}
+	return tips, nil
+}
+
+// GetChainTipsAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See GetChainTips for the blocking version and more details.
+func (c *Client) GetChainTipsAsync() FutureGetChainTipsResult {
+	cmd := btcjson.NewGetChainTipsCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetChainTips returns the tips of the main chain and of every side branch known to the server.
+func (c *Client) GetChainTips() ([]btcjson.GetChainTipsResult, error) {
+	return c.GetChainTipsAsync().Receive()
+}
+
 // FutureGetCFilterResult is a future promise to deliver the result of a GetCFilterAsync RPC invocation (or an applicable error).
 type FutureGetCFilterResult chan *response
 
@@ -716,3 +857,67 @@ func (c *Client) GetCFilterHeader(blockHash *chainhash.Hash,
 	filterType wire.FilterType) (*wire.MsgCFHeaders, error) {
 	return c.GetCFilterHeaderAsync(blockHash, filterType).Receive()
 }
+
+// FutureSaveMempoolResult is a future promise to deliver the result of a SaveMempoolAsync RPC invocation (or an applicable error).
+type FutureSaveMempoolResult chan *response
+
+// Receive waits for the response promised by the future and returns an error if the memory pool could not be saved.
+func (r FutureSaveMempoolResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// SaveMempoolAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See SaveMempool for the blocking version and more details.
+func (c *Client) SaveMempoolAsync() FutureSaveMempoolResult {
+	cmd := btcjson.NewSaveMempoolCmd()
+	return c.sendCmd(cmd)
+}
+
+// SaveMempool writes the transactions in the memory pool of the server to its mempool file.
+func (c *Client) SaveMempool() error {
+	return c.SaveMempoolAsync().Receive()
+}
+
+// FutureLoadMempoolResult is a future promise to deliver the result of a LoadMempoolAsync RPC invocation (or an applicable error).
+type FutureLoadMempoolResult chan *response
+
+// Receive waits for the response promised by the future and returns an error if the memory pool could not be loaded.
+func (r FutureLoadMempoolResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// LoadMempoolAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See LoadMempool for the blocking version and more details.
+func (c *Client) LoadMempoolAsync() FutureLoadMempoolResult {
+	cmd := btcjson.NewLoadMempoolCmd()
+	return c.sendCmd(cmd)
+}
+
+// LoadMempool adds the transactions in the mempool file of the server back to its memory pool.
+func (c *Client) LoadMempool() error {
+	return c.LoadMempoolAsync().Receive()
+}
+
+// FuturePrioritiseTransactionResult is a future promise to deliver the result of a PrioritiseTransactionAsync RPC invocation (or an applicable error).
+type FuturePrioritiseTransactionResult chan *response
+
+// Receive waits for the response promised by the future and returns an error if the fee delta could not be set.
+func (r FuturePrioritiseTransactionResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// PrioritiseTransactionAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See PrioritiseTransaction for the blocking version and more details.
+func (c *Client) PrioritiseTransactionAsync(txHash *chainhash.Hash, feeDelta util.Amount) FuturePrioritiseTransactionResult {
+	hash := ""
+	if txHash != nil {
+		hash = txHash.String()
+	}
+	cmd := btcjson.NewPrioritiseTransactionCmd(hash, 0, int64(feeDelta))
+	return c.sendCmd(cmd)
+}
+
+// PrioritiseTransaction adds feeDelta to the fee a transaction is treated as paying when the server chooses it for a block.
+func (c *Client) PrioritiseTransaction(txHash *chainhash.Hash, feeDelta util.Amount) error {
+	return c.PrioritiseTransactionAsync(txHash, feeDelta).Receive()
+}
@@ -50,6 +50,17 @@ func (c *Client) Generate(numBlocks uint32) ([]*chainhash.Hash, error) {
 	return c.GenerateAsync(numBlocks).Receive()
 }
 
+// GenerateAlgoAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See GenerateAlgo for the blocking version and more details. NOTE: This is a pod extension.
+func (c *Client) GenerateAlgoAsync(numBlocks uint32, version int32) FutureGenerateResult {
+	cmd := btcjson.NewGenerateAlgoCmd(numBlocks, version)
+	return c.sendCmd(cmd)
+}
+
+// GenerateAlgo generates numBlocks blocks with the algorithm of a block version and returns their hashes. NOTE: This is a pod extension.
+func (c *Client) GenerateAlgo(numBlocks uint32, version int32) ([]*chainhash.Hash, error) {
+	return c.GenerateAlgoAsync(numBlocks, version).Receive()
+}
+
 // FutureGetGenerateResult is a future promise to deliver the result of a GetGenerateAsync RPC invocation (or an applicable error).
 type FutureGetGenerateResult chan *response
 
@@ -482,3 +482,96 @@ func (c *Client) DecodeScriptAsync(serializedScript []byte) FutureDecodeScriptRe
 func (c *Client) DecodeScript(serializedScript []byte) (*btcjson.DecodeScriptResult, error) {
 	return c.DecodeScriptAsync(serializedScript).Receive()
 }
+
+// FutureDecodePSBTResult is a future promise to deliver the result of a DecodePSBTAsync RPC invocation (or an applicable error).
+type FutureDecodePSBTResult chan *response
+
+// Receive waits for the response promised by the future and returns information about a PSBT.
+func (r FutureDecodePSBTResult) Receive() (*btcjson.DecodePSBTResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	// Unmarshal result as a decodepsbt result object.
+	var decodePSBTResult btcjson.DecodePSBTResult
+	err = js.Unmarshal(res, &decodePSBTResult)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return &decodePSBTResult, nil
+}
+
+// DecodePSBTAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See DecodePSBT for the blocking version and more details.
+func (c *Client) DecodePSBTAsync(psbt string) FutureDecodePSBTResult {
+	cmd := btcjson.NewDecodePSBTCmd(psbt)
+	return c.sendCmd(cmd)
+}
+
+// DecodePSBT returns information about a base64-encoded PSBT.
+func (c *Client) DecodePSBT(psbt string) (*btcjson.DecodePSBTResult, error) {
+	return c.DecodePSBTAsync(psbt).Receive()
+}
+
+// FutureCombinePSBTResult is a future promise to deliver the result of a CombinePSBTAsync RPC invocation (or an applicable error).
+type FutureCombinePSBTResult chan *response
+
+// Receive waits for the response promised by the future and returns the combined base64-encoded PSBT.
+func (r FutureCombinePSBTResult) Receive() (string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		Error(err)
+		return "", err
+	}
+	// Unmarshal result as a string.
+	var psbt string
+	err = js.Unmarshal(res, &psbt)
+	if err != nil {
+		Error(err)
+		return "", err
+	}
+	return psbt, nil
+}
+
+// CombinePSBTAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See CombinePSBT for the blocking version and more details.
+func (c *Client) CombinePSBTAsync(psbts []string) FutureCombinePSBTResult {
+	cmd := btcjson.NewCombinePSBTCmd(psbts)
+	return c.sendCmd(cmd)
+}
+
+// CombinePSBT combines several base64-encoded PSBTs for the same unsigned transaction, such as the ones signed by each of the co-signers of a multisig input, into one.
+func (c *Client) CombinePSBT(psbts []string) (string, error) {
+	return c.CombinePSBTAsync(psbts).Receive()
+}
+
+// FutureFinalizePSBTResult is a future promise to deliver the result of a FinalizePSBTAsync RPC invocation (or an applicable error).
+type FutureFinalizePSBTResult chan *response
+
+// Receive waits for the response promised by the future and returns the finalized PSBT, or the signed transaction extracted from it.
+func (r FutureFinalizePSBTResult) Receive() (*btcjson.FinalizePSBTResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	// Unmarshal result as a finalizepsbt result object.
+	var finalizePSBTResult btcjson.FinalizePSBTResult
+	err = js.Unmarshal(res, &finalizePSBTResult)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return &finalizePSBTResult, nil
+}
+
+// FinalizePSBTAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See FinalizePSBT for the blocking version and more details.
+func (c *Client) FinalizePSBTAsync(psbt string, extract bool) FutureFinalizePSBTResult {
+	cmd := btcjson.NewFinalizePSBTCmd(psbt, &extract)
+	return c.sendCmd(cmd)
+}
+
+// FinalizePSBT finalizes the inputs of a base64-encoded PSBT that have all their signatures. When every input is finalized and extract is true, the signed transaction is returned in place of the PSBT.
+func (c *Client) FinalizePSBT(psbt string, extract bool) (*btcjson.FinalizePSBTResult, error) {
+	return c.FinalizePSBTAsync(psbt, extract).Receive()
+}
@@ -412,6 +412,146 @@ func (c *Client) SetTxFee(fee util.Amount) error {
 	return c.SetTxFeeAsync(fee).Receive()
 }
 
+// FutureBumpFeeResult is a future promise to deliver the result of a
+// BumpFeeAsync RPC invocation (or an applicable error).
+type FutureBumpFeeResult chan *response
+
+// Receive waits for the response promised by the future and returns the hash
+// of the replacement transaction along with the fees of the replaced and
+// replacement transactions.
+func (r FutureBumpFeeResult) Receive() (*btcjson.BumpFeeResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	// Unmarshal result as a bumpfee result object.
+	var bumpFeeResult btcjson.BumpFeeResult
+	err = js.Unmarshal(res, &bumpFeeResult)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return &bumpFeeResult, nil
+}
+
+// BumpFeeAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+// See BumpFee for the blocking version and more details.
+func (c *Client) BumpFeeAsync(txHash *chainhash.Hash, feeRate util.Amount) FutureBumpFeeResult {
+	hash := ""
+	if txHash != nil {
+		hash = txHash.String()
+	}
+	var options *btcjson.BumpFeeOptions
+	if feeRate > 0 {
+		options = &btcjson.BumpFeeOptions{FeeRate: btcjson.Float64(feeRate.ToDUO())}
+	}
+	cmd := btcjson.NewBumpFeeCmd(hash, options)
+	return c.sendCmd(cmd)
+}
+
+// BumpFee replaces an unmined wallet transaction that signals opt-in
+// replace-by-fee with one paying a higher fee. A zero fee rate pays the lowest
+// fee the replacement requires.
+func (c *Client) BumpFee(txHash *chainhash.Hash, feeRate util.Amount) (*btcjson.BumpFeeResult, error) {
+	return c.BumpFeeAsync(txHash, feeRate).Receive()
+}
+
+// FutureWalletCreateFundedPSBTResult is a future promise to deliver the
+// result of a WalletCreateFundedPSBTAsync RPC invocation (or an applicable
+// error).
+type FutureWalletCreateFundedPSBTResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// funded PSBT along with its fee and the position of its change output.
+func (r FutureWalletCreateFundedPSBTResult) Receive() (*btcjson.WalletCreateFundedPSBTResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	// Unmarshal result as a walletcreatefundedpsbt result object.
+	var result btcjson.WalletCreateFundedPSBTResult
+	err = js.Unmarshal(res, &result)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return &result, nil
+}
+
+// WalletCreateFundedPSBTAsync returns an instance of a type that can be used
+// to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+// See WalletCreateFundedPSBT for the blocking version and more details.
+func (c *Client) WalletCreateFundedPSBTAsync(inputs []btcjson.TransactionInput,
+	amounts map[util.Address]util.Amount, lockTime *int64,
+	options *btcjson.WalletCreateFundedPSBTOptions) FutureWalletCreateFundedPSBTResult {
+	convertedAmts := make(map[string]float64, len(amounts))
+	for addr, amount := range amounts {
+		convertedAmts[addr.String()] = amount.ToDUO()
+	}
+	if inputs == nil {
+		inputs = []btcjson.TransactionInput{}
+	}
+	cmd := btcjson.NewWalletCreateFundedPSBTCmd(inputs, convertedAmts,
+		lockTime, options)
+	return c.sendCmd(cmd)
+}
+
+// WalletCreateFundedPSBT creates an unsigned PSBT paying the given amounts,
+// funded with the given inputs or, if there are none, with inputs selected
+// by the wallet, and with a change output if one is needed.
+func (c *Client) WalletCreateFundedPSBT(inputs []btcjson.TransactionInput,
+	amounts map[util.Address]util.Amount, lockTime *int64,
+	options *btcjson.WalletCreateFundedPSBTOptions) (*btcjson.WalletCreateFundedPSBTResult, error) {
+	return c.WalletCreateFundedPSBTAsync(inputs, amounts, lockTime,
+		options).Receive()
+}
+
+// FutureWalletProcessPSBTResult is a future promise to deliver the result of
+// a WalletProcessPSBTAsync RPC invocation (or an applicable error).
+type FutureWalletProcessPSBTResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// updated PSBT and whether it is complete.
+func (r FutureWalletProcessPSBTResult) Receive() (*btcjson.WalletProcessPSBTResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	// Unmarshal result as a walletprocesspsbt result object.
+	var result btcjson.WalletProcessPSBTResult
+	err = js.Unmarshal(res, &result)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return &result, nil
+}
+
+// WalletProcessPSBTAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+// See WalletProcessPSBT for the blocking version and more details.
+func (c *Client) WalletProcessPSBTAsync(psbt string, sign bool,
+	hashType SigHashType) FutureWalletProcessPSBTResult {
+	cmd := btcjson.NewWalletProcessPSBTCmd(psbt, &sign,
+		btcjson.String(string(hashType)))
+	return c.sendCmd(cmd)
+}
+
+// WalletProcessPSBT adds the information the wallet has about the inputs of
+// a base64-encoded PSBT to it and, if sign is true, signs the inputs it has
+// the keys for.
+func (c *Client) WalletProcessPSBT(psbt string, sign bool,
+	hashType SigHashType) (*btcjson.WalletProcessPSBTResult, error) {
+	return c.WalletProcessPSBTAsync(psbt, sign, hashType).Receive()
+}
+
 // FutureSendToAddressResult is a future promise to deliver the result of a
 // SendToAddressAsync RPC invocation (or an applicable error).
 type FutureSendToAddressResult chan *response
@@ -440,7 +580,7 @@ func (r FutureSendToAddressResult) Receive() (*chainhash.Hash, error) {
 // See SendToAddress for the blocking version and more details.
 func (c *Client) SendToAddressAsync(address util.Address, amount util.Amount) FutureSendToAddressResult {
 	addr := address.EncodeAddress()
-	cmd := btcjson.NewSendToAddressCmd(addr, amount.ToDUO(), nil, nil)
+	cmd := btcjson.NewSendToAddressCmd(addr, amount.ToDUO(), nil, nil, nil)
 	return c.sendCmd(cmd)
 }
 
@@ -463,7 +603,7 @@ func (c *Client) SendToAddressCommentAsync(address util.Address,
 	commentTo string) FutureSendToAddressResult {
 	addr := address.EncodeAddress()
 	cmd := btcjson.NewSendToAddressCmd(addr, amount.ToDUO(), &comment,
-		&commentTo)
+		&commentTo, nil)
 	return c.sendCmd(cmd)
 }
 
@@ -481,6 +621,30 @@ func (c *Client) SendToAddressComment(address util.Address, amount util.Amount,
 		commentTo).Receive()
 }
 
+// SendToAddressCoinSelectionAsync returns an instance of a type that can be
+// used to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+// See SendToAddressCoinSelection for the blocking version and more details.
+func (c *Client) SendToAddressCoinSelectionAsync(address util.Address,
+	amount util.Amount, coinSelection string) FutureSendToAddressResult {
+	addr := address.EncodeAddress()
+	cmd := btcjson.NewSendToAddressCmd(addr, amount.ToDUO(), nil, nil,
+		&coinSelection)
+	return c.sendCmd(cmd)
+}
+
+// SendToAddressCoinSelection sends the passed amount to the given address,
+// spending outputs chosen by the coin selection strategy, which is one of
+// "bnb", "avoidreuse" or "consolidate".
+// See SendToAddress to use the default strategy.
+// NOTE: This function requires to the wallet to be unlocked.  See the
+// WalletPassphrase function for more details.
+func (c *Client) SendToAddressCoinSelection(address util.Address,
+	amount util.Amount, coinSelection string) (*chainhash.Hash, error) {
+	return c.SendToAddressCoinSelectionAsync(address, amount,
+		coinSelection).Receive()
+}
+
 // FutureSendFromResult is a future promise to deliver the result of a
 // SendFromAsync, SendFromMinConfAsync, or SendFromCommentAsync RPC invocation
 // (or an applicable error).
@@ -611,7 +775,7 @@ func (c *Client) SendManyAsync(fromAccount string, amounts map[util.Address]util
 	for addr, amount := range amounts {
 		convertedAmounts[addr.EncodeAddress()] = amount.ToDUO()
 	}
-	cmd := btcjson.NewSendManyCmd(fromAccount, convertedAmounts, nil, nil)
+	cmd := btcjson.NewSendManyCmd(fromAccount, convertedAmounts, nil, nil, nil)
 	return c.sendCmd(cmd)
 }
 
@@ -637,7 +801,7 @@ func (c *Client) SendManyMinConfAsync(fromAccount string,
 		convertedAmounts[addr.EncodeAddress()] = amount.ToDUO()
 	}
 	cmd := btcjson.NewSendManyCmd(fromAccount, convertedAmounts,
-		&minConfirms, nil)
+		&minConfirms, nil, nil)
 	return c.sendCmd(cmd)
 }
 
@@ -666,7 +830,7 @@ func (c *Client) SendManyCommentAsync(fromAccount string,
 		convertedAmounts[addr.EncodeAddress()] = amount.ToDUO()
 	}
 	cmd := btcjson.NewSendManyCmd(fromAccount, convertedAmounts,
-		&minConfirms, &comment)
+		&minConfirms, &comment, nil)
 	return c.sendCmd(cmd)
 }
 
@@ -685,6 +849,36 @@ func (c *Client) SendManyComment(fromAccount string,
 		comment).Receive()
 }
 
+// SendManyCoinSelectionAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+// See SendManyCoinSelection for the blocking version and more details.
+func (c *Client) SendManyCoinSelectionAsync(fromAccount string,
+	amounts map[util.Address]util.Amount, minConfirms int,
+	coinSelection string) FutureSendManyResult {
+	convertedAmounts := make(map[string]float64, len(amounts))
+	for addr, amount := range amounts {
+		convertedAmounts[addr.EncodeAddress()] = amount.ToDUO()
+	}
+	cmd := btcjson.NewSendManyCmd(fromAccount, convertedAmounts,
+		&minConfirms, nil, &coinSelection)
+	return c.sendCmd(cmd)
+}
+
+// SendManyCoinSelection sends multiple amounts to multiple addresses using the
+// provided account as a source of funds in a single transaction, spending
+// outputs with the passed number of minimum confirmations chosen by the coin
+// selection strategy, which is one of "bnb", "avoidreuse" or "consolidate".
+// See SendMany and SendManyMinConf to use defaults.
+// NOTE: This function requires to the wallet to be unlocked.  See the
+// WalletPassphrase function for more details.
+func (c *Client) SendManyCoinSelection(fromAccount string,
+	amounts map[util.Address]util.Amount, minConfirms int,
+	coinSelection string) (*chainhash.Hash, error) {
+	return c.SendManyCoinSelectionAsync(fromAccount, amounts, minConfirms,
+		coinSelection).Receive()
+}
+
 // *************************
 // Address/Account Functions
 // *************************
@@ -1378,7 +1572,7 @@ func (r FutureGetBalanceParseResult) Receive() (util.Amount, error) {
 // returned instance.
 // See GetBalance for the blocking version and more details.
 func (c *Client) GetBalanceAsync(account string) FutureGetBalanceResult {
-	cmd := btcjson.NewGetBalanceCmd(&account, nil)
+	cmd := btcjson.NewGetBalanceCmd(&account, nil, nil)
 	return c.sendCmd(cmd)
 }
 
@@ -1395,7 +1589,7 @@ func (c *Client) GetBalance(account string) (util.Amount, error) {
 // the returned instance.
 // See GetBalanceMinConf for the blocking version and more details.
 func (c *Client) GetBalanceMinConfAsync(account string, minConfirms int) FutureGetBalanceResult {
-	cmd := btcjson.NewGetBalanceCmd(&account, &minConfirms)
+	cmd := btcjson.NewGetBalanceCmd(&account, &minConfirms, nil)
 	return c.sendCmd(cmd)
 }
 
@@ -1411,6 +1605,25 @@ func (c *Client) GetBalanceMinConf(account string, minConfirms int) (util.Amount
 	return c.GetBalanceMinConfAsync(account, minConfirms).Receive()
 }
 
+// GetBalanceWatchOnlyAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+// See GetBalanceWatchOnly for the blocking version and more details.
+func (c *Client) GetBalanceWatchOnlyAsync(account string, minConfirms int,
+	includeWatchOnly bool) FutureGetBalanceResult {
+	cmd := btcjson.NewGetBalanceCmd(&account, &minConfirms, &includeWatchOnly)
+	return c.sendCmd(cmd)
+}
+
+// GetBalanceWatchOnly returns the balance from the server for the specified
+// account using the specified number of minimum confirmations, including the
+// balance of watch-only addresses when includeWatchOnly is true.  The account
+// may be "*" for all accounts.
+func (c *Client) GetBalanceWatchOnly(account string, minConfirms int,
+	includeWatchOnly bool) (util.Amount, error) {
+	return c.GetBalanceWatchOnlyAsync(account, minConfirms, includeWatchOnly).Receive()
+}
+
 // FutureGetReceivedByAccountResult is a future promise to deliver the result of
 // a GetReceivedByAccountAsync or GetReceivedByAccountMinConfAsync RPC
 // invocation (or an applicable error).
@@ -2084,6 +2297,126 @@ func (c *Client) ImportPubKeyRescan(pubKey string, rescan bool) error {
 	return c.ImportPubKeyRescanAsync(pubKey, rescan).Receive()
 }
 
+// FutureImportXpubResult is a future promise to deliver the result of an
+// ImportXpubAsync RPC invocation (or an applicable error).
+type FutureImportXpubResult chan *response
+
+// Receive waits for the response promised by the future and returns the result
+// of importing the passed extended public key.
+func (r FutureImportXpubResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// ImportXpubAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+// See ImportXpub for the blocking version and more details.
+func (c *Client) ImportXpubAsync(xpub, account string, rescan bool,
+	addressType string) FutureImportXpubResult {
+	cmd := btcjson.NewImportXpubCmd(xpub, account, &rescan, &addressType)
+	return c.sendCmd(cmd)
+}
+
+// ImportXpub imports the passed extended public key as a new watch-only
+// account deriving addresses of addressType, which is one of "legacy",
+// "p2sh-segwit" or "bech32". When rescan is true, the block history is scanned
+// for transactions addressed to the account.
+func (c *Client) ImportXpub(xpub, account string, rescan bool,
+	addressType string) error {
+	return c.ImportXpubAsync(xpub, account, rescan, addressType).Receive()
+}
+
+// FutureImportWalletResult is a future promise to deliver the result of an
+// ImportWalletAsync RPC invocation (or an applicable error).
+type FutureImportWalletResult chan *response
+
+// Receive waits for the response promised by the future and returns the result
+// of importing the wallet dump.
+func (r FutureImportWalletResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// ImportWalletAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+// See ImportWallet for the blocking version and more details.
+func (c *Client) ImportWalletAsync(filename string) FutureImportWalletResult {
+	cmd := btcjson.NewImportWalletCmd(filename)
+	return c.sendCmd(cmd)
+}
+
+// ImportWallet imports the private keys and redeem scripts of a wallet dump
+// file on the server, as written by DumpWallet, and rescans the block history
+// for transactions addressed to them.
+func (c *Client) ImportWallet(filename string) error {
+	return c.ImportWalletAsync(filename).Receive()
+}
+
+// FutureBackupWalletResult is a future promise to deliver the result of a
+// BackupWalletAsync RPC invocation (or an applicable error).
+type FutureBackupWalletResult chan *response
+
+// Receive waits for the response promised by the future and returns the result
+// of backing up the wallet.
+func (r FutureBackupWalletResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// BackupWalletAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+// See BackupWallet for the blocking version and more details.
+func (c *Client) BackupWalletAsync(destination string) FutureBackupWalletResult {
+	cmd := btcjson.NewBackupWalletCmd(destination)
+	return c.sendCmd(cmd)
+}
+
+// BackupWallet writes a copy of the wallet database to the passed destination
+// on the server, which is either a file or a directory to write wallet.db to.
+func (c *Client) BackupWallet(destination string) error {
+	return c.BackupWalletAsync(destination).Receive()
+}
+
+// FutureDumpWalletResult is a future promise to deliver the result of a
+// DumpWalletAsync RPC invocation (or an applicable error).
+type FutureDumpWalletResult chan *response
+
+// Receive waits for the response promised by the future and returns the path
+// of the wallet dump written by the server.
+func (r FutureDumpWalletResult) Receive() (*btcjson.DumpWalletResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	// Unmarshal result as a dumpwallet result object.
+	var dumpRes btcjson.DumpWalletResult
+	err = js.Unmarshal(res, &dumpRes)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return &dumpRes, nil
+}
+
+// DumpWalletAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+// See DumpWallet for the blocking version and more details.
+func (c *Client) DumpWalletAsync(filename string) FutureDumpWalletResult {
+	cmd := btcjson.NewDumpWalletCmd(filename)
+	return c.sendCmd(cmd)
+}
+
+// DumpWallet writes the private keys and redeem scripts of the wallet to a new
+// file on the server with the passed name. The wallet must be unlocked.
+func (c *Client) DumpWallet(filename string) (*btcjson.DumpWalletResult, error) {
+	return c.DumpWalletAsync(filename).Receive()
+}
+
 // ***********************
 // Miscellaneous Functions
 // ***********************
@@ -2129,12 +2462,85 @@ func (c *Client) GetInfo() (*btcjson.InfoWalletResult, error) {
 	return c.GetInfoAsync().Receive()
 }
 
+// FutureGetWalletInfoResult is a future promise to deliver the result of a
+// GetWalletInfoAsync RPC invocation (or an applicable error).
+type FutureGetWalletInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns the wallet
+// info provided by the server.
+func (r FutureGetWalletInfoResult) Receive() (*btcjson.GetWalletInfoResult,
+	error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	// Unmarshal result as a getwalletinfo result object.
+	var infoRes btcjson.GetWalletInfoResult
+	err = js.Unmarshal(res, &infoRes)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return &infoRes, nil
+}
+
+// GetWalletInfoAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+// See GetWalletInfo for the blocking version and more details.
+func (c *Client) GetWalletInfoAsync() FutureGetWalletInfoResult {
+	cmd := btcjson.NewGetWalletInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetWalletInfo returns the balances, transaction count, unused addresses and
+// lock state of the wallet.
+func (c *Client) GetWalletInfo() (*btcjson.GetWalletInfoResult, error) {
+	return c.GetWalletInfoAsync().Receive()
+}
+
+// FutureListAddressGroupingsResult is a future promise to deliver the result
+// of a ListAddressGroupingsAsync RPC invocation (or an applicable error).
+type FutureListAddressGroupingsResult chan *response
+
+// Receive waits for the response promised by the future and returns the groups
+// of wallet addresses known to be held together.
+func (r FutureListAddressGroupingsResult) Receive() (
+	[][]btcjson.ListAddressGroupingsResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	// Unmarshal result as an array of arrays of listaddressgroupings result
+	// objects.
+	var groupings [][]btcjson.ListAddressGroupingsResult
+	err = js.Unmarshal(res, &groupings)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return groupings, nil
+}
+
+// ListAddressGroupingsAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+// See ListAddressGroupings for the blocking version and more details.
+func (c *Client) ListAddressGroupingsAsync() FutureListAddressGroupingsResult {
+	cmd := btcjson.NewListAddressGroupingsCmd()
+	return c.sendCmd(cmd)
+}
+
+// ListAddressGroupings returns the addresses of the wallet that have been paid
+// to, in groups known to be held together because outputs to them were spent
+// by the same transaction or received its change.
+func (c *Client) ListAddressGroupings() (
+	[][]btcjson.ListAddressGroupingsResult, error) {
+	return c.ListAddressGroupingsAsync().Receive()
+}
+
 // TODO(davec): Implement
-// backupwallet (NYI in btcwallet)
 // encryptwallet (Won't be supported by btcwallet since it's always encrypted)
-// getwalletinfo (NYI in btcwallet or json)
-// listaddressgroupings (NYI in btcwallet)
 // listreceivedbyaccount (NYI in btcwallet)
-// DUMP
-// importwallet (NYI in btcwallet)
-// dumpwallet (NYI in btcwallet)
@@ -9,6 +9,20 @@ var helpDescsEnUS = map[string]string{
 	"addmultisigaddress-keys":      "Pubkeys and/or pay-to-pubkey-hash addresses to partially control the multisig address",
 	"addmultisigaddress-nrequired": "The number of signatures required to redeem outputs paid to this address",
 	"addmultisigaddress--result0":  "The imported pay-to-script-hash address",
+	// BackupWalletCmd help.
+	"backupwallet--synopsis":   "Writes a copy of the wallet database, taken while the wallet keeps running.",
+	"backupwallet-destination": "The file to write, or a directory to write wallet.db to",
+	// BumpFeeCmd help.
+	"bumpfee--synopsis": "Replaces an unmined wallet transaction that signals opt-in replace-by-fee with one paying a higher fee, taken from its change output.",
+	"bumpfee-txid":      "The hash of the transaction to replace",
+	"bumpfee-options":   "Options for the replacement transaction",
+	// BumpFeeOptions help.
+	"bumpfeeoptions-fee_rate": "The fee rate of the replacement in DUO/kB (default: the lowest fee the replacement requires)",
+	// BumpFeeResult help.
+	"bumpfeeresult-txid":    "The hash of the replacement transaction",
+	"bumpfeeresult-origfee": "The fee of the replaced transaction",
+	"bumpfeeresult-fee":     "The fee of the replacement transaction",
+	"bumpfeeresult-errors":  "Errors encountered while replacing the transaction",
 	// CreateMultisigCmd help.
 	"createmultisig--synopsis": "Generate a multisig address and redeem script.",
 	"createmultisig-keys":      "Pubkeys and/or pay-to-pubkey-hash addresses to partially control the multisig address",
@@ -20,6 +34,11 @@ var helpDescsEnUS = map[string]string{
 	"dumpprivkey--synopsis": "Returns the private key in WIF encoding that controls some wallet address.",
 	"dumpprivkey-address":   "The address to return a private key for",
 	"dumpprivkey--result0":  "The WIF-encoded private key",
+	// DumpWalletCmd help.
+	"dumpwallet--synopsis": "Writes the private keys and redeem scripts of the wallet to a new file as text, with their accounts and derivation paths. Keys of watch-only addresses and accounts are not included.",
+	"dumpwallet-filename":  "The file to write, which must not exist yet",
+	// DumpWalletResult help.
+	"dumpwalletresult-filename": "The full path of the written file",
 	// GetAccountCmd help.
 	"getaccount--synopsis": "DEPRECATED -- Lookup the account name that some wallet address belongs to.",
 	"getaccount-address":   "The address to query the account for",
@@ -34,13 +53,14 @@ var helpDescsEnUS = map[string]string{
 	"getaddressesbyaccount-account":   "Account name to fetch addresses for",
 	"getaddressesbyaccount--result0":  "All addresses controlled by 'account'",
 	// GetBalanceCmd help.
-	"getbalance--synopsis":   "Calculates and returns the balance of one or all accounts.",
-	"getbalance-minconf":     "Minimum number of block confirmations required before an unspent output's value is included in the balance",
-	"getbalance-account":     "DEPRECATED -- The account name to query the balance for, or \"*\" to consider all accounts (default=\"*\")",
-	"getbalance--condition0": "account != \"*\"",
-	"getbalance--condition1": "account = \"*\"",
-	"getbalance--result0":    "The balance of 'account' valued in bitcoin",
-	"getbalance--result1":    "The balance of all accounts valued in bitcoin",
+	"getbalance--synopsis":        "Calculates and returns the balance of one or all accounts.",
+	"getbalance-minconf":          "Minimum number of block confirmations required before an unspent output's value is included in the balance",
+	"getbalance-account":          "DEPRECATED -- The account name to query the balance for, or \"*\" to consider all accounts (default=\"*\")",
+	"getbalance-includewatchonly": "Also include the balance of watch-only addresses",
+	"getbalance--condition0":      "account != \"*\"",
+	"getbalance--condition1":      "account = \"*\"",
+	"getbalance--result0":         "The balance of 'account' valued in bitcoin",
+	"getbalance--result1":         "The balance of all accounts valued in bitcoin",
 	// GetBestBlockHashCmd help.
 	"getbestblockhash--synopsis": "Returns the hash of the newest block in the best chain that wallet has finished syncing with.",
 	"getbestblockhash--result0":  "The hash of the most recent synced-to block",
@@ -88,6 +108,19 @@ var helpDescsEnUS = map[string]string{
 	"gettransaction--synopsis":        "Returns a JSON object with details regarding a transaction relevant to this wallet.",
 	"gettransaction-txid":             "Hash of the transaction to query",
 	"gettransaction-includewatchonly": "Also consider transactions involving watched addresses",
+	// GetWalletInfoCmd help.
+	"getwalletinfo--synopsis": "Returns a JSON object with the balances, transaction count, unused addresses and lock state of the wallet.",
+	// GetWalletInfoResult help.
+	"getwalletinforesult-walletversion":           "The version of the wallet database",
+	"getwalletinforesult-balance":                 "The balance of confirmed outputs valued in bitcoin, excluding watch-only addresses",
+	"getwalletinforesult-unconfirmed_balance":     "The balance of unconfirmed outputs valued in bitcoin, excluding watch-only addresses",
+	"getwalletinforesult-immature_balance":        "The balance of coinbase outputs that cannot be spent yet valued in bitcoin",
+	"getwalletinforesult-txcount":                 "The number of transactions of the wallet, including unmined ones",
+	"getwalletinforesult-keypoolsize":             "The number of payment addresses derived from the wallet seed that have not been paid to yet",
+	"getwalletinforesult-keypoolsize_hd_internal": "The number of change addresses derived from the wallet seed that have not been paid to yet",
+	"getwalletinforesult-unlocked_until":          "The Unix time the wallet locks at, or 0 when it is locked or unlocked without a time limit",
+	"getwalletinforesult-paytxfee":                "The transaction fee per kilobyte valued in bitcoin",
+	"getwalletinforesult-private_keys_enabled":    "Whether the wallet holds private keys",
 	// HelpCmd help.
 	"help--synopsis":   "Returns a list of all commands or help for a specified command.",
 	"help-command":     "The command to retrieve help for",
@@ -115,12 +148,30 @@ var helpDescsEnUS = map[string]string{
 	"gettransactiondetailsresult-amount":            "The amount of a received output",
 	"gettransactiondetailsresult-fee":               "The included fee for a sent transaction",
 	"gettransactiondetailsresult-vout":              "The transaction output index",
-	"gettransactiondetailsresult-involveswatchonly": "Unset",
+	"gettransactiondetailsresult-involveswatchonly": "Whether the detail involves a watch-only address",
 	// ImportPrivKeyCmd help.
 	"importprivkey--synopsis": "Imports a WIF-encoded private key to the 'imported' account.",
 	"importprivkey-privkey":   "The WIF-encoded private key",
 	"importprivkey-label":     "Unused (must be unset or 'imported')",
 	"importprivkey-rescan":    "Rescan the blockchain (since the genesis block) for outputs controlled by the imported key",
+	// ImportAddressCmd help.
+	"importaddress--synopsis": "Imports an address to the 'imported' account as watch-only, so payments to it are tracked but cannot be spent.",
+	"importaddress-address":   "The address to watch",
+	"importaddress-account":   "Unused (must be unset or 'imported')",
+	"importaddress-rescan":    "Rescan the blockchain (since the genesis block) for outputs paying to the address",
+	// ImportPubKeyCmd help.
+	"importpubkey--synopsis": "Imports the address of a public key to the 'imported' account as watch-only, so payments to it are tracked but cannot be spent.",
+	"importpubkey-pubkey":    "The hex-encoded public key",
+	"importpubkey-rescan":    "Rescan the blockchain (since the genesis block) for outputs paying to the public key",
+	// ImportWalletCmd help.
+	"importwallet--synopsis": "Imports the private keys and redeem scripts of a file written by dumpwallet to the 'imported' account, and rescans the blockchain (since the genesis block) for outputs paying to them. Keys the wallet already has are skipped.",
+	"importwallet-filename":  "The file to import",
+	// ImportXpubCmd help.
+	"importxpub--synopsis":   "Creates a new watch-only account from the extended public key of an account of another wallet. Addresses of the account are tracked but cannot be spent.",
+	"importxpub-xpub":        "The extended public key of the account, derived at depth 3 (m/purpose'/coin_type'/account')",
+	"importxpub-account":     "The name of the new account",
+	"importxpub-rescan":      "Rescan the blockchain (since the genesis block) for outputs paying to the account",
+	"importxpub-addresstype": "The type of addresses derived from the key: \"legacy\", \"p2sh-segwit\" or \"bech32\"",
 	// KeypoolRefillCmd help.
 	"keypoolrefill--synopsis": "DEPRECATED -- This request does nothing since no keypool is maintained.",
 	"keypoolrefill-newsize":   "Unused",
@@ -130,6 +181,12 @@ var helpDescsEnUS = map[string]string{
 	"listaccounts--result0--desc":  "JSON object with account names as keys and bitcoin amounts as values",
 	"listaccounts--result0--key":   "The account name",
 	"listaccounts--result0--value": "The account balance valued in bitcoin",
+	// ListAddressGroupingsCmd help.
+	"listaddressgroupings--synopsis": "Returns the addresses of the wallet that have been paid to, in groups known to be held together because outputs to them were spent by the same transaction or received its change.",
+	// ListAddressGroupingsResult help.
+	"listaddressgroupingsresult-address": "The payment address",
+	"listaddressgroupingsresult-amount":  "The amount of unspent outputs to the address valued in bitcoin",
+	"listaddressgroupingsresult-account": "The account of the address",
 	// ListLockUnspentCmd help.
 	"listlockunspent--synopsis": "Returns a JSON array of outpoints marked as locked (with lockunspent) for this wallet session.",
 	// TransactionInput help.
@@ -160,7 +217,7 @@ var helpDescsEnUS = map[string]string{
 	"listsinceblock--synopsis":           "Returns a JSON array of objects listing details of all wallet transactions after some block.",
 	"listsinceblock-blockhash":           "Hash of the parent block of the first block to consider transactions from, or unset to list all transactions",
 	"listsinceblock-targetconfirmations": "Minimum number of block confirmations of the last block in the result object.  Must be 1 or greater.  Note: The transactions array in the result object is not affected by this parameter",
-	"listsinceblock-includewatchonly":    "Unused",
+	"listsinceblock-includewatchonly":    "Also include transactions involving watch-only addresses",
 	"listsinceblock--condition0":         "blockhash specified",
 	"listsinceblock--condition1":         "no blockhash specified",
 	"listsinceblock--result0":            "Lists all transactions, including unmined transactions, since the specified block",
@@ -184,7 +241,7 @@ var helpDescsEnUS = map[string]string{
 	"listtransactionsresult-walletconflicts":    "Unset",
 	"listtransactionsresult-time":               "The earliest Unix time this transaction was known to exist",
 	"listtransactionsresult-timereceived":       "The earliest Unix time this transaction was known to exist",
-	"listtransactionsresult-involveswatchonly":  "Unset",
+	"listtransactionsresult-involveswatchonly":  "Whether the transaction involves a watch-only address",
 	"listtransactionsresult-comment":            "Unset",
 	"listtransactionsresult-otheraccount":       "Unset",
 	"listtransactionsresult-trusted":            "Unset",
@@ -195,7 +252,7 @@ var helpDescsEnUS = map[string]string{
 	"listtransactions-account":          "DEPRECATED -- Unused (must be unset or \"*\")",
 	"listtransactions-count":            "Maximum number of transactions to create results from",
 	"listtransactions-from":             "Number of transactions to skip before results are created",
-	"listtransactions-includewatchonly": "Unused",
+	"listtransactions-includewatchonly": "Also include transactions involving watch-only addresses",
 	// ListUnspentCmd help.
 	"listunspent--synopsis": "Returns a JSON array of objects representing unlocked unspent outputs controlled by wallet keys.",
 	"listunspent-minconf":   "Minimum number of block confirmations required before a transaction output is considered",
@@ -239,16 +296,18 @@ var helpDescsEnUS = map[string]string{
 	"sendmany-amounts--value": "Amount to send to the payment address valued in bitcoin",
 	"sendmany-minconf":        "Minimum number of block confirmations required before a transaction output is eligible to be spent",
 	"sendmany-comment":        "Unused",
+	"sendmany-coinselection":  "How the outputs to spend are selected: \"bnb\" to avoid a change output where possible, \"avoidreuse\" to also spend all outputs of an address together, or \"consolidate\" to spend many small outputs while fees are low",
 	"sendmany--result0":       "The transaction hash of the sent transaction",
 	// SendToAddressCmd help.
 	"sendtoaddress--synopsis": "Authors, signs, and sends a transaction that outputs some amount to a payment address.\n" +
 		"Unlike sendfrom, outputs are always chosen from the default account.\n" +
 		"A change output is automatically included to send extra output value back to the original account.",
-	"sendtoaddress-address":   "Address to pay",
-	"sendtoaddress-amount":    "Amount to send to the payment address valued in bitcoin",
-	"sendtoaddress-comment":   "Unused",
-	"sendtoaddress-commentto": "Unused",
-	"sendtoaddress--result0":  "The transaction hash of the sent transaction",
+	"sendtoaddress-address":       "Address to pay",
+	"sendtoaddress-amount":        "Amount to send to the payment address valued in bitcoin",
+	"sendtoaddress-comment":       "Unused",
+	"sendtoaddress-commentto":     "Unused",
+	"sendtoaddress-coinselection": "How the outputs to spend are selected: \"bnb\" to avoid a change output where possible, \"avoidreuse\" to also spend all outputs of an address together, or \"consolidate\" to spend many small outputs while fees are low",
+	"sendtoaddress--result0":      "The transaction hash of the sent transaction",
 	// SetTxFeeCmd help.
 	"settxfee--synopsis": "Modify the increment used each time more fee is required for an authored transaction.",
 	"settxfee-amount":    "The new fee increment valued in bitcoin",
@@ -287,7 +346,7 @@ var helpDescsEnUS = map[string]string{
 	"validateaddresswalletresult-isvalid":      "Whether or not the address is valid",
 	"validateaddresswalletresult-address":      "The payment address (only when isvalid is true)",
 	"validateaddresswalletresult-ismine":       "Whether this address is controlled by the wallet (only when isvalid is true)",
-	"validateaddresswalletresult-iswatchonly":  "Unset",
+	"validateaddresswalletresult-iswatchonly":  "Whether the wallet only watches the address without holding its private key",
 	"validateaddresswalletresult-isscript":     "Whether the payment address is a pay-to-script-hash address (only when isvalid is true)",
 	"validateaddresswalletresult-pubkey":       "The associated public key of the payment address, if any (only when isvalid is true)",
 	"validateaddresswalletresult-iscompressed": "Whether the address was created by hashing a compressed public key, if any (only when isvalid is true)",
@@ -302,6 +361,26 @@ var helpDescsEnUS = map[string]string{
 	"verifymessage-signature": "The signature to verify",
 	"verifymessage-message":   "The message to verify",
 	"verifymessage--result0":  "Whether the message was signed with the private key of 'address'",
+	// WalletCreateFundedPSBTCmd help.
+	"walletcreatefundedpsbt--synopsis": "Creates an unsigned PSBT paying the given outputs, funded with the given inputs or, when there are none, with inputs selected by the wallet.\n" +
+		"Change is returned to the wallet when it is not dust, and the previous transactions and redeem scripts of the inputs are included so the PSBT can be signed offline.",
+	"walletcreatefundedpsbt-inputs":         "The outputs to spend, all of which are used (may be empty for the wallet to select them)",
+	"walletcreatefundedpsbt-outputs":        "JSON object using addresses as keys and amounts as values",
+	"walletcreatefundedpsbt-outputs--key":   "address",
+	"walletcreatefundedpsbt-outputs--value": "n.nnn",
+	"walletcreatefundedpsbt-outputs--desc":  "The destination address as the key and the amount in DUO as the value",
+	"walletcreatefundedpsbt-locktime":       "The lock time of the transaction",
+	"walletcreatefundedpsbt-options":        "Options for funding the transaction",
+	// WalletCreateFundedPSBTOptions help.
+	"walletcreatefundedpsbtoptions-changeAddress":  "The address to send the change to (default: a new change address)",
+	"walletcreatefundedpsbtoptions-changePosition": "The index of the change output (default: random)",
+	"walletcreatefundedpsbtoptions-lockUnspents":   "Lock the outputs the PSBT spends",
+	"walletcreatefundedpsbtoptions-feeRate":        "The fee rate in DUO/kB (default: the relay fee)",
+	"walletcreatefundedpsbtoptions-replaceable":    "Signal that the transaction can be replaced with one paying a higher fee",
+	// WalletCreateFundedPSBTResult help.
+	"walletcreatefundedpsbtresult-psbt":      "The base64-encoded unsigned PSBT",
+	"walletcreatefundedpsbtresult-fee":       "The fee the transaction pays in DUO",
+	"walletcreatefundedpsbtresult-changepos": "The index of the change output, or -1 if there is none",
 	// WalletLockCmd help.
 	"walletlock--synopsis": "Lock the wallet.",
 	// WalletPassphraseCmd help.
@@ -312,6 +391,15 @@ var helpDescsEnUS = map[string]string{
 	"walletpassphrasechange--synopsis":     "Change the wallet passphrase.",
 	"walletpassphrasechange-oldpassphrase": "The old wallet passphrase",
 	"walletpassphrasechange-newpassphrase": "The new wallet passphrase",
+	// WalletProcessPSBTCmd help.
+	"walletprocesspsbt--synopsis": "Adds the previous outputs and redeem scripts the wallet knows of to the inputs of a PSBT and signs the inputs it has the keys for, finalizing the ones that are fully signed.\n" +
+		"The wallet must be unlocked to sign.",
+	"walletprocesspsbt-psbt":        "The base64-encoded PSBT",
+	"walletprocesspsbt-sign":        "Sign the inputs the wallet has the keys for",
+	"walletprocesspsbt-sighashtype": "The signature hash type: ALL, NONE, SINGLE, ALL|ANYONECANPAY, NONE|ANYONECANPAY or SINGLE|ANYONECANPAY",
+	// WalletProcessPSBTResult help.
+	"walletprocesspsbtresult-psbt":     "The base64-encoded PSBT",
+	"walletprocesspsbtresult-complete": "Whether every input of the PSBT is finalized",
 	// CreateNewAccountCmd help.
 	"createnewaccount--synopsis": "Creates a new account.\n" +
 		"The wallet must be unlocked for this request to succeed.",
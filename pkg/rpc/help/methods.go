@@ -21,8 +21,11 @@ var Methods = []struct {
 	ResultTypes []interface{}
 }{
 	{"addmultisigaddress", returnsString},
+	{"backupwallet", nil},
+	{"bumpfee", []interface{}{(*btcjson.BumpFeeResult)(nil)}},
 	{"createmultisig", []interface{}{(*btcjson.CreateMultiSigResult)(nil)}},
 	{"dumpprivkey", returnsString},
+	{"dumpwallet", []interface{}{(*btcjson.DumpWalletResult)(nil)}},
 	{"getaccount", returnsString},
 	{"getaccountaddress", returnsString},
 	{"getaddressesbyaccount", returnsStringArray},
@@ -35,10 +38,16 @@ var Methods = []struct {
 	{"getreceivedbyaccount", returnsNumber},
 	{"getreceivedbyaddress", returnsNumber},
 	{"gettransaction", []interface{}{(*btcjson.GetTransactionResult)(nil)}},
+	{"getwalletinfo", []interface{}{(*btcjson.GetWalletInfoResult)(nil)}},
 	{"help", append(returnsString, returnsString[0])},
+	{"importaddress", nil},
 	{"importprivkey", nil},
+	{"importpubkey", nil},
+	{"importwallet", nil},
+	{"importxpub", nil},
 	{"keypoolrefill", nil},
 	{"listaccounts", []interface{}{(*map[string]float64)(nil)}},
+	{"listaddressgroupings", []interface{}{(*[][]btcjson.ListAddressGroupingsResult)(nil)}},
 	{"listlockunspent", []interface{}{(*[]btcjson.TransactionInput)(nil)}},
 	{"listreceivedbyaccount", []interface{}{(*[]btcjson.ListReceivedByAccountResult)(nil)}},
 	{"listreceivedbyaddress", []interface{}{(*[]btcjson.ListReceivedByAddressResult)(nil)}},
@@ -54,9 +63,11 @@ var Methods = []struct {
 	{"signrawtransaction", []interface{}{(*btcjson.SignRawTransactionResult)(nil)}},
 	{"validateaddress", []interface{}{(*btcjson.ValidateAddressWalletResult)(nil)}},
 	{"verifymessage", returnsBool},
+	{"walletcreatefundedpsbt", []interface{}{(*btcjson.WalletCreateFundedPSBTResult)(nil)}},
 	{"walletlock", nil},
 	{"walletpassphrase", nil},
 	{"walletpassphrasechange", nil},
+	{"walletprocesspsbt", []interface{}{(*btcjson.WalletProcessPSBTResult)(nil)}},
 	{"createnewaccount", nil},
 	{"exportwatchingwallet", returnsString},
 	{"getbestblock", []interface{}{(*btcjson.GetBestBlockResult)(nil)}},
@@ -33,6 +33,18 @@ var handlers = handlersT{
 		Cmd:     "*btcjson.AddMultisigAddressCmd",
 		ResType: "string",
 	},
+	{
+		Method:  "backupwallet",
+		Handler: "BackupWallet",
+		Cmd:     "*btcjson.BackupWalletCmd",
+		ResType: "None",
+	},
+	{
+		Method:  "bumpfee",
+		Handler: "BumpFee",
+		Cmd:     "*btcjson.BumpFeeCmd",
+		ResType: "btcjson.BumpFeeResult",
+	},
 	{
 		Method:  "createmultisig",
 		Handler: "CreateMultiSig",
@@ -45,6 +57,12 @@ var handlers = handlersT{
 		Cmd:     "*btcjson.DumpPrivKeyCmd",
 		ResType: "string",
 	},
+	{
+		Method:  "dumpwallet",
+		Handler: "DumpWallet",
+		Cmd:     "*btcjson.DumpWalletCmd",
+		ResType: "btcjson.DumpWalletResult",
+	},
 	{
 		Method:  "getaccount",
 		Handler: "GetAccount",
@@ -117,6 +135,12 @@ var handlers = handlersT{
 		Cmd:     "*btcjson.GetTransactionCmd",
 		ResType: "btcjson.GetTransactionResult",
 	},
+	{
+		Method:  "getwalletinfo",
+		Handler: "GetWalletInfo",
+		Cmd:     "*None",
+		ResType: "btcjson.GetWalletInfoResult",
+	},
 	{
 		Method:           "help",
 		Handler:          "HelpNoChainRPC",
@@ -124,12 +148,36 @@ var handlers = handlersT{
 		Cmd:              "btcjson.HelpCmd",
 		ResType:          "string",
 	},
+	{
+		Method:  "importaddress",
+		Handler: "ImportAddress",
+		Cmd:     "*btcjson.ImportAddressCmd",
+		ResType: "None",
+	},
 	{
 		Method:  "importprivkey",
 		Handler: "ImportPrivKey",
 		Cmd:     "*btcjson.ImportPrivKeyCmd",
 		ResType: "None",
 	},
+	{
+		Method:  "importpubkey",
+		Handler: "ImportPubKey",
+		Cmd:     "*btcjson.ImportPubKeyCmd",
+		ResType: "None",
+	},
+	{
+		Method:  "importwallet",
+		Handler: "ImportWallet",
+		Cmd:     "*btcjson.ImportWalletCmd",
+		ResType: "None",
+	},
+	{
+		Method:  "importxpub",
+		Handler: "ImportXpub",
+		Cmd:     "*btcjson.ImportXpubCmd",
+		ResType: "None",
+	},
 	{
 		Method:  "keypoolrefill",
 		Handler: "KeypoolRefill",
@@ -142,6 +190,12 @@ var handlers = handlersT{
 		Cmd:     "*btcjson.ListAccountsCmd",
 		ResType: "map[string]float64",
 	},
+	{
+		Method:  "listaddressgroupings",
+		Handler: "ListAddressGroupings",
+		Cmd:     "*None",
+		ResType: "[][]btcjson.ListAddressGroupingsResult",
+	},
 	{
 		Method:  "listlockunspent",
 		Handler: "ListLockUnspent",
@@ -229,6 +283,12 @@ var handlers = handlersT{
 		Cmd:     "*btcjson.VerifyMessageCmd",
 		ResType: "bool",
 	},
+	{
+		Method:  "walletcreatefundedpsbt",
+		Handler: "WalletCreateFundedPSBT",
+		Cmd:     "*btcjson.WalletCreateFundedPSBTCmd",
+		ResType: "btcjson.WalletCreateFundedPSBTResult",
+	},
 	{
 		Method:  "walletlock",
 		Handler: "WalletLock",
@@ -247,6 +307,12 @@ var handlers = handlersT{
 		Cmd:     "*btcjson.WalletPassphraseChangeCmd",
 		ResType: "None",
 	},
+	{
+		Method:  "walletprocesspsbt",
+		Handler: "WalletProcessPSBT",
+		Cmd:     "*btcjson.WalletProcessPSBTCmd",
+		ResType: "btcjson.WalletProcessPSBTResult",
+	},
 	{
 		Method:  "createnewaccount",
 		Handler: "CreateNewAccount",
@@ -7,6 +7,9 @@ import (
 	js "encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,7 +23,9 @@ import (
 	rpcclient "github.com/p9c/pod/pkg/rpc/client"
 	"github.com/p9c/pod/pkg/util"
 	ec "github.com/p9c/pod/pkg/util/elliptic"
+	"github.com/p9c/pod/pkg/util/hdkeychain"
 	"github.com/p9c/pod/pkg/util/interrupt"
+	"github.com/p9c/pod/pkg/util/psbt"
 	"github.com/p9c/pod/pkg/wallet"
 	waddrmgr "github.com/p9c/pod/pkg/wallet/addrmgr"
 	"github.com/p9c/pod/pkg/wallet/chain"
@@ -246,12 +251,6 @@ func Confirms(txHeight, curHeight int32) int32 {
 // 		Params:  make(chan btcjson.WalletPassphraseChangeCmd),
 // 		Return:  func() interface{} { return make(chan WalletPassphraseChangeRes) },
 // 	},
-// 	// Reference implementation methods (still unimplemented)
-// 	"backupwallet":         {Handler: Unimplemented, NoHelp: true},
-// 	"dumpwallet":           {Handler: Unimplemented, NoHelp: true},
-// 	"getwalletinfo":        {Handler: Unimplemented, NoHelp: true},
-// 	"importwallet":         {Handler: Unimplemented, NoHelp: true},
-// 	"listaddressgroupings": {Handler: Unimplemented, NoHelp: true},
 // 	// Reference methods which can't be implemented by btcwallet due to
 // 	// design decision differences
 // 	"encryptwallet": {Handler: Unsupported, NoHelp: true},
@@ -537,6 +536,73 @@ func AddMultiSigAddress(icmd interface{}, w *wallet.Wallet,
 	return p2shAddr.EncodeAddress(), nil
 }
 
+// BackupWallet handles a backupwallet request by writing a copy of the wallet
+// database to the destination file, or into the destination directory.
+func BackupWallet(icmd interface{}, w *wallet.Wallet,
+	chainClient ...*chain.RPCClient) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.BackupWalletCmd)
+	if !ok {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: HelpDescsEnUS()["backupwallet"],
+		}
+	}
+	if err := w.BackupWallet(cmd.Destination); err != nil {
+		Error(err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCWallet,
+			Message: "Wallet backup failed: " + err.Error(),
+		}
+	}
+	return nil, nil
+}
+
+// BumpFee handles a bumpfee request by replacing an unmined wallet
+// transaction that signals replaceability with one paying a higher fee.
+func BumpFee(icmd interface{}, w *wallet.Wallet,
+	chainClient ...*chain.RPCClient) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.BumpFeeCmd)
+	if !ok {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: HelpDescsEnUS()["bumpfee"],
+		}
+	}
+	txHash, err := chainhash.NewHashFromStr(cmd.TxID)
+	if err != nil {
+		Error(err)
+		return nil, DeserializationError{err}
+	}
+	var feeSatPerKb util.Amount
+	if cmd.Options != nil && cmd.Options.FeeRate != nil {
+		feeSatPerKb, err = util.NewAmount(*cmd.Options.FeeRate)
+		if err != nil {
+			Error(err)
+			return nil, err
+		}
+		if feeSatPerKb <= 0 {
+			return nil, ErrNeedPositiveAmount
+		}
+	}
+	tx, origFee, fee, err := w.BumpFee(txHash, feeSatPerKb)
+	if err != nil {
+		Error(err)
+		if waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+			return nil, &ErrWalletUnlockNeeded
+		}
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCWallet,
+			Message: err.Error(),
+		}
+	}
+	return btcjson.BumpFeeResult{
+		TxID:    tx.TxHash().String(),
+		OrigFee: origFee.ToDUO(),
+		Fee:     fee.ToDUO(),
+		Errors:  []string{},
+	}, nil
+}
+
 // CreateMultiSig handles an createmultisig request by returning a
 // multisig address for the given inputs.
 func CreateMultiSig(icmd interface{}, w *wallet.Wallet,
@@ -599,16 +665,45 @@ func DumpPrivKey(icmd interface{}, w *wallet.Wallet,
 	return key, err
 }
 
-// // dumpWallet handles a dumpwallet request by returning  all private
-// // keys in a wallet, or an appropiate error if the wallet is locked.
-// // TODO: finish this to match bitcoind by writing the dump to a file.
-// func dumpWallet(// 	icmd interface{}, w *wallet.Wallet) (interface{}, error) {
-// 	keys, err := w.DumpPrivKeys()
-// 	if waddrmgr.IsError(err, waddrmgr.ErrLocked) {
-// 		return nil, &ErrWalletUnlockNeeded
-// 	}
-// 	return keys, err
-// }
+// DumpWallet handles a dumpwallet request by writing all private keys and
+// redeem scripts of the wallet to a new file, and returning its full path.
+// Existing files are not overwritten.
+func DumpWallet(icmd interface{}, w *wallet.Wallet,
+	chainClient ...*chain.RPCClient) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.DumpWalletCmd)
+	if !ok {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: HelpDescsEnUS()["dumpwallet"],
+		}
+	}
+	filename, err := filepath.Abs(cmd.Filename)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		Error(err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Cannot create wallet dump file: " + err.Error(),
+		}
+	}
+	err = w.DumpWallet(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		Error(err)
+		_ = os.Remove(filename)
+		if waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+			return nil, &ErrWalletUnlockNeeded
+		}
+		return nil, err
+	}
+	return btcjson.DumpWalletResult{Filename: filename}, nil
+}
 
 // GetAddressesByAccount handles a getaddressesbyaccount request by returning
 // all addresses for an account, or an error if the requested account does
@@ -659,12 +754,22 @@ func GetBalance(icmd interface{}, w *wallet.Wallet,
 	if cmd.Account != nil {
 		accountName = *cmd.Account
 	}
+	includeWatchOnly := cmd.IncludeWatchOnly != nil && *cmd.IncludeWatchOnly
 	if accountName == "*" {
 		balance, err = w.CalculateBalance(int32(*cmd.MinConf))
 		if err != nil {
 			Error(err)
 			return nil, err
 		}
+		if includeWatchOnly {
+			var watchOnly util.Amount
+			watchOnly, err = w.CalculateWatchOnlyBalance(int32(*cmd.MinConf))
+			if err != nil {
+				Error(err)
+				return nil, err
+			}
+			balance += watchOnly
+		}
 	} else {
 		var account uint32
 		account, err = w.AccountNumber(waddrmgr.KeyScopeBIP0044, accountName)
@@ -678,6 +783,9 @@ func GetBalance(icmd interface{}, w *wallet.Wallet,
 			return nil, err
 		}
 		balance = bals.Spendable
+		if includeWatchOnly {
+			balance += bals.WatchOnly
+		}
 	}
 	return balance.ToDUO(), nil
 }
@@ -736,7 +844,7 @@ func GetInfo(icmd interface{}, w *wallet.Wallet, chainClient ...*chain.RPCClient
 	//  to using the manager version.
 	info.WalletVersion = int32(waddrmgr.LatestMgrVersion)
 	info.Balance = bal.ToDUO()
-	info.PaytxFee = float64(txrules.DefaultRelayFeePerKb)
+	info.PaytxFee = w.FeeRate().ToDUO()
 	// We don't set the following since they don't make much sense in the
 	// wallet architecture:
 	//  - unlocked_until
@@ -898,6 +1006,150 @@ func ImportPrivKey(icmd interface{}, w *wallet.Wallet,
 	return nil, err
 }
 
+// ImportAddress handles an importaddress request by adding an address to the
+// imported account as watch-only, so that payments to it are tracked without
+// the wallet being able to spend them.
+func ImportAddress(icmd interface{}, w *wallet.Wallet,
+	chainClient ...*chain.RPCClient) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.ImportAddressCmd)
+	if !ok {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: HelpDescsEnUS()["importaddress"],
+		}
+	}
+	// Ensure that addresses are only imported to the correct account.
+	if cmd.Account != "" && cmd.Account != waddrmgr.ImportedAddrAccountName {
+		return nil, &ErrNotImportedAccount
+	}
+	addr, err := DecodeAddress(cmd.Address, w.ChainParams())
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	_, err = w.ImportAddress(waddrmgr.KeyScopeBIP0044, addr, nil, *cmd.Rescan)
+	if waddrmgr.IsError(err, waddrmgr.ErrDuplicateAddress) {
+		// Do not return duplicate address errors to the client.
+		return nil, nil
+	}
+	return nil, err
+}
+
+// ImportPubKey handles an importpubkey request by adding the address of a hex
+// encoded public key to the imported account as watch-only.
+func ImportPubKey(icmd interface{}, w *wallet.Wallet,
+	chainClient ...*chain.RPCClient) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.ImportPubKeyCmd)
+	if !ok {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: HelpDescsEnUS()["importpubkey"],
+		}
+	}
+	serializedPubKey, err := DecodeHexStr(cmd.PubKey)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	pubKey, err := ec.ParsePubKey(serializedPubKey, ec.S256())
+	if err != nil {
+		Error(err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Public key parse failed: " + err.Error(),
+		}
+	}
+	compressed := len(serializedPubKey) == ec.PubKeyBytesLenCompressed
+	_, err = w.ImportPublicKey(
+		waddrmgr.KeyScopeBIP0044, pubKey, compressed, nil, *cmd.Rescan,
+	)
+	if waddrmgr.IsError(err, waddrmgr.ErrDuplicateAddress) {
+		// Do not return duplicate key errors to the client.
+		return nil, nil
+	}
+	return nil, err
+}
+
+// ImportWallet handles an importwallet request by importing the private keys and
+// redeem scripts of a file written by dumpwallet to the imported account, and
+// rescanning the chain for payments to them.
+func ImportWallet(icmd interface{}, w *wallet.Wallet,
+	chainClient ...*chain.RPCClient) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.ImportWalletCmd)
+	if !ok {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: HelpDescsEnUS()["importwallet"],
+		}
+	}
+	f, err := os.Open(cmd.Filename)
+	if err != nil {
+		Error(err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Cannot open wallet dump file: " + err.Error(),
+		}
+	}
+	defer f.Close()
+	_, err = w.ImportWallet(f)
+	switch {
+	case waddrmgr.IsError(err, waddrmgr.ErrLocked):
+		return nil, &ErrWalletUnlockNeeded
+	case err != nil:
+		Error(err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCWallet,
+			Message: "Wallet dump import failed: " + err.Error(),
+		}
+	}
+	return nil, nil
+}
+
+// ImportXpub handles an importxpub request by creating a new watch-only account
+// from the extended public key of an account of another wallet, deriving
+// addresses of the given address type.
+func ImportXpub(icmd interface{}, w *wallet.Wallet,
+	chainClient ...*chain.RPCClient) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.ImportXpubCmd)
+	if !ok {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: HelpDescsEnUS()["importxpub"],
+		}
+	}
+	var scope waddrmgr.KeyScope
+	switch *cmd.AddressType {
+	case "legacy":
+		scope = waddrmgr.KeyScopeBIP0044
+	case "p2sh-segwit":
+		scope = waddrmgr.KeyScopeBIP0049Plus
+	case "bech32":
+		scope = waddrmgr.KeyScopeBIP0084
+	default:
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Unknown address type %q, must be "+
+				"legacy, p2sh-segwit or bech32", *cmd.AddressType),
+		}
+	}
+	acctKeyPub, err := hdkeychain.NewKeyFromString(cmd.Xpub)
+	if err != nil {
+		Error(err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Extended public key decode failed: " + err.Error(),
+		}
+	}
+	if acctKeyPub.IsPrivate() {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Extended key must be public, not private",
+		}
+	}
+	_, err = w.ImportAccount(scope, cmd.Account, acctKeyPub, nil, *cmd.Rescan)
+	return nil, err
+}
+
 // KeypoolRefill handles the keypoolrefill command. Since we handle the keypool
 // automatically this does nothing since refilling is never manually required.
 func KeypoolRefill(icmd interface{}, w *wallet.Wallet,
@@ -1147,10 +1399,11 @@ func GetTransaction(icmd interface{}, w *wallet.Wallet,
 	for _, deb := range details.Debits {
 		debitTotal += deb.Amount
 	}
-	for _, cred := range details.Credits {
-		if !cred.Change {
-			creditTotal += cred.Amount
-		}
+	includeWatchOnly := cmd.IncludeWatchOnly != nil && *cmd.IncludeWatchOnly
+	spendsWatchOnly, err := w.TxSpendsWatchOnly(details)
+	if err != nil {
+		Error(err)
+		return nil, err
 	}
 	// Fee can only be determined if every input is a debit.
 	if len(details.Debits) == len(details.MsgTx.TxIn) {
@@ -1161,7 +1414,7 @@ func GetTransaction(icmd interface{}, w *wallet.Wallet,
 		fee = debitTotal - outputTotal
 		feeF64 = fee.ToDUO()
 	}
-	if len(details.Debits) == 0 {
+	if len(details.Debits) == 0 || (spendsWatchOnly && !includeWatchOnly) {
 		// Credits must be set later, but since we know the full length
 		// of the details slice, allocate it with the correct cap.
 		ret.Details = make([]btcjson.GetTransactionDetailsResult, 0, len(details.Credits))
@@ -1169,7 +1422,6 @@ func GetTransaction(icmd interface{}, w *wallet.Wallet,
 		ret.Details = make([]btcjson.GetTransactionDetailsResult, 1, len(details.Credits)+1)
 		ret.Details[0] = btcjson.GetTransactionDetailsResult{
 			// Fields left zeroed:
-			//   InvolvesWatchOnly
 			//   Account
 			//   Address
 			//   Vout
@@ -1179,9 +1431,10 @@ func GetTransaction(icmd interface{}, w *wallet.Wallet,
 			// core.  Instead, gettransaction should only be adding
 			// details for transaction outputs, just like
 			// listtransactions (but using the short result format).
-			Category: "send",
-			Amount:   (-debitTotal).ToDUO(), // negative since it is a send
-			Fee:      &feeF64,
+			Category:          "send",
+			Amount:            (-debitTotal).ToDUO(), // negative since it is a send
+			Fee:               &feeF64,
+			InvolvesWatchOnly: spendsWatchOnly,
 		}
 		ret.Fee = feeF64
 	}
@@ -1193,34 +1446,68 @@ func GetTransaction(icmd interface{}, w *wallet.Wallet,
 		}
 		var address string
 		var accountName string
+		var watchOnly bool
 		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
 			details.MsgTx.TxOut[cred.Index].PkScript, w.ChainParams())
 		if err == nil && len(addrs) == 1 {
 			addr := addrs[0]
 			address = addr.EncodeAddress()
-			account, err := w.AccountOfAddress(addr)
+			ma, err := w.AddressInfo(addr)
 			if err == nil {
-				name, err := w.AccountName(waddrmgr.KeyScopeBIP0044, account)
+				watchOnly = ma.WatchOnly()
+				name, err := w.AccountName(waddrmgr.KeyScopeBIP0044, ma.Account())
 				if err == nil {
 					accountName = name
 				}
 			}
 		}
+		// Watch-only credits are only included when requested.
+		if watchOnly && !includeWatchOnly {
+			continue
+		}
+		creditTotal += cred.Amount
 		ret.Details = append(ret.Details, btcjson.GetTransactionDetailsResult{
 			// Fields left zeroed:
-			//   InvolvesWatchOnly
 			//   Fee
-			Account:  accountName,
-			Address:  address,
-			Category: credCat,
-			Amount:   cred.Amount.ToDUO(),
-			Vout:     cred.Index,
+			Account:           accountName,
+			Address:           address,
+			Category:          credCat,
+			Amount:            cred.Amount.ToDUO(),
+			Vout:              cred.Index,
+			InvolvesWatchOnly: watchOnly,
 		})
 	}
 	ret.Amount = creditTotal.ToDUO()
 	return ret, nil
 }
 
+// GetWalletInfo handles a getwalletinfo request by returning the balances,
+// transaction count, unused addresses and lock state of the wallet.
+func GetWalletInfo(icmd interface{}, w *wallet.Wallet,
+	chainClient ...*chain.RPCClient) (interface{}, error) {
+	info, err := w.WalletInfo()
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	var unlockedUntil int64
+	if !info.UnlockedUntil.IsZero() {
+		unlockedUntil = info.UnlockedUntil.Unix()
+	}
+	return btcjson.GetWalletInfoResult{
+		WalletVersion:         int32(waddrmgr.LatestMgrVersion),
+		Balance:               info.Balance.ToDUO(),
+		UnconfirmedBalance:    info.UnconfirmedBalance.ToDUO(),
+		ImmatureBalance:       info.ImmatureBalance.ToDUO(),
+		TxCount:               int64(info.TxCount),
+		KeypoolSize:           int32(info.UnusedAddresses),
+		KeypoolSizeHDInternal: int32(info.UnusedChangeAddresses),
+		UnlockedUntil:         unlockedUntil,
+		PaytxFee:              info.FeeRate.ToDUO(),
+		PrivateKeysEnabled:    !info.WatchOnly,
+	}, nil
+}
+
 func HandleDropWalletHistory(icmd interface{}, w *wallet.Wallet,
 	chainClient ...*chain.RPCClient) (out interface{}, err error) {
 	Debug("dropping wallet history")
@@ -1383,6 +1670,30 @@ func ListAccounts(icmd interface{}, w *wallet.Wallet,
 	return accountBalances, nil
 }
 
+// ListAddressGroupings handles a listaddressgroupings request by returning the
+// addresses of the wallet that have been paid to, grouped by the ones known to
+// be held together because they were spent from together or received change.
+func ListAddressGroupings(icmd interface{}, w *wallet.Wallet,
+	chainClient ...*chain.RPCClient) (interface{}, error) {
+	groupings, err := w.AddressGroupings()
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	result := make([][]btcjson.ListAddressGroupingsResult, len(groupings))
+	for i, group := range groupings {
+		result[i] = make([]btcjson.ListAddressGroupingsResult, len(group))
+		for j := range group {
+			result[i][j] = btcjson.ListAddressGroupingsResult{
+				Address: group[j].Address.EncodeAddress(),
+				Amount:  group[j].Amount.ToDUO(),
+				Account: group[j].Account,
+			}
+		}
+	}
+	return result, nil
+}
+
 // ListLockUnspent handles a listlockunspent request by returning an slice of
 // all locked outpoints.
 func ListLockUnspent(icmd interface{}, w *wallet.Wallet,
@@ -1577,6 +1888,7 @@ func ListSinceBlock(icmd interface{}, w *wallet.Wallet,
 		Error(err)
 		return nil, err
 	}
+	txInfoList = filterWatchOnly(txInfoList, cmd.IncludeWatchOnly)
 	// Done with work, get the response.
 	blockHash, err := gbh.Receive()
 	if err != nil {
@@ -1620,8 +1932,28 @@ func ListTransactions(icmd interface{}, w *wallet.Wallet,
 			Message: "Transactions are not yet grouped by account",
 		}
 	}
-	txs, err = w.ListTransactions(*cmd.From, *cmd.Count)
-	return txs, err
+	txList, err := w.ListTransactions(*cmd.From, *cmd.Count)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return filterWatchOnly(txList, cmd.IncludeWatchOnly), nil
+}
+
+// filterWatchOnly removes the results involving watch-only addresses from a
+// transaction list unless includeWatchOnly is set.
+func filterWatchOnly(txList []btcjson.ListTransactionsResult,
+	includeWatchOnly *bool) []btcjson.ListTransactionsResult {
+	if includeWatchOnly != nil && *includeWatchOnly {
+		return txList
+	}
+	filtered := txList[:0]
+	for _, tx := range txList {
+		if !tx.InvolvesWatchOnly {
+			filtered = append(filtered, tx)
+		}
+	}
+	return filtered
 }
 
 // ListAddressTransactions handles a listaddresstransactions request by
@@ -1762,17 +2094,20 @@ func MakeOutputs(pairs map[string]util.Amount, chainParams *netparams.Params) ([
 	return outputs, nil
 }
 
-// SendPairs creates and sends payment transactions.
+// SendPairs creates and sends payment transactions, spending outputs chosen by
+// the coin selection strategy.
 // It returns the transaction hash in string format upon success
 // All errors are returned in json.RPCError format
 func SendPairs(w *wallet.Wallet, amounts map[string]util.Amount,
-	account uint32, minconf int32, feeSatPerKb util.Amount) (string, error) {
+	account uint32, minconf int32, feeSatPerKb util.Amount,
+	strategy wallet.CoinSelectionStrategy) (string, error) {
 	outputs, err := MakeOutputs(amounts, w.ChainParams())
 	if err != nil {
 		Error(err)
 		return "", err
 	}
-	txHash, err := w.SendOutputs(outputs, account, minconf, feeSatPerKb)
+	txHash, err := w.SendOutputs(outputs, account, minconf, feeSatPerKb,
+		strategy)
 	if err != nil {
 		Error(err)
 		if err == txrules.ErrAmountNegative {
@@ -1794,6 +2129,27 @@ func SendPairs(w *wallet.Wallet, amounts map[string]util.Amount,
 	Info("successfully sent transaction", txHashStr)
 	return txHashStr, nil
 }
+// CoinSelectionStrategy returns the wallet coin selection strategy named by the
+// coinselection parameter of a send request, which defaults to branch and
+// bound when it is not given.
+func CoinSelectionStrategy(name *string) (wallet.CoinSelectionStrategy, error) {
+	if name == nil {
+		return wallet.CoinSelectionBranchAndBound, nil
+	}
+	switch *name {
+	case "bnb":
+		return wallet.CoinSelectionBranchAndBound, nil
+	case "avoidreuse":
+		return wallet.CoinSelectionAvoidReuse, nil
+	case "consolidate":
+		return wallet.CoinSelectionConsolidate, nil
+	}
+	return 0, &btcjson.RPCError{
+		Code: btcjson.ErrRPCInvalidParameter,
+		Message: fmt.Sprintf("Unknown coin selection %q, must be bnb, "+
+			"avoidreuse or consolidate", *name),
+	}
+}
 func IsNilOrEmpty(s *string) bool {
 	return s == nil || *s == ""
 }
@@ -1844,8 +2200,8 @@ func SendFrom(icmd interface{}, w *wallet.Wallet, chainClient *chain.RPCClient)
 	pairs := map[string]util.Amount{
 		cmd.ToAddress: amt,
 	}
-	return SendPairs(w, pairs, account, minConf,
-		txrules.DefaultRelayFeePerKb)
+	return SendPairs(w, pairs, account, minConf, w.FeeRate(),
+		wallet.CoinSelectionBranchAndBound)
 }
 
 // SendMany handles a sendmany RPC request by creating a new transaction
@@ -1881,6 +2237,11 @@ func SendMany(icmd interface{}, w *wallet.Wallet,
 	if minConf < 0 {
 		return nil, ErrNeedPositiveMinconf
 	}
+	strategy, err := CoinSelectionStrategy(cmd.CoinSelection)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
 	// Recreate address/amount pairs, using dcrutil.Amount.
 	pairs := make(map[string]util.Amount, len(cmd.Amounts))
 	for k, v := range cmd.Amounts {
@@ -1891,7 +2252,7 @@ func SendMany(icmd interface{}, w *wallet.Wallet,
 		}
 		pairs[k] = amt
 	}
-	return SendPairs(w, pairs, account, minConf, txrules.DefaultRelayFeePerKb)
+	return SendPairs(w, pairs, account, minConf, w.FeeRate(), strategy)
 }
 
 // SendToAddress handles a sendtoaddress RPC request by creating a new
@@ -1926,16 +2287,22 @@ func SendToAddress(icmd interface{}, w *wallet.Wallet,
 	if amt < 0 {
 		return nil, ErrNeedPositiveAmount
 	}
+	strategy, err := CoinSelectionStrategy(cmd.CoinSelection)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
 	// Mock up map of address and amount pairs.
 	pairs := map[string]util.Amount{
 		cmd.Address: amt,
 	}
 	// sendtoaddress always spends from the default account, this matches bitcoind
-	return SendPairs(w, pairs, waddrmgr.DefaultAccountNum, 1,
-		txrules.DefaultRelayFeePerKb)
+	return SendPairs(w, pairs, waddrmgr.DefaultAccountNum, 1, w.FeeRate(),
+		strategy)
 }
 
-// SetTxFee sets the transaction fee per kilobyte added to transactions.
+// SetTxFee sets the fee per kilobyte paid by the transactions the wallet sends.
+// An amount of zero restores the default fee.
 func SetTxFee(icmd interface{}, w *wallet.Wallet,
 	chainClient ...*chain.RPCClient) (interface{}, error) {
 	cmd, ok := icmd.(*btcjson.SetTxFeeCmd)
@@ -1950,6 +2317,12 @@ func SetTxFee(icmd interface{}, w *wallet.Wallet,
 	if cmd.Amount < 0 {
 		return nil, ErrNeedPositiveAmount
 	}
+	feeRate, err := util.NewAmount(cmd.Amount)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	w.SetFeeRate(feeRate)
 	// A boolean true result is returned upon success.
 	return true, nil
 }
@@ -1997,6 +2370,17 @@ func SignMessage(icmd interface{}, w *wallet.Wallet,
 	return base64.StdEncoding.EncodeToString(sigbytes), nil
 }
 
+// SigHashTypes are the signature hash types by the names they are given to
+// signrawtransaction and walletprocesspsbt.
+var SigHashTypes = map[string]txscript.SigHashType{
+	"ALL":                 txscript.SigHashAll,
+	"NONE":                txscript.SigHashNone,
+	"SINGLE":              txscript.SigHashSingle,
+	"ALL|ANYONECANPAY":    txscript.SigHashAll | txscript.SigHashAnyOneCanPay,
+	"NONE|ANYONECANPAY":   txscript.SigHashNone | txscript.SigHashAnyOneCanPay,
+	"SINGLE|ANYONECANPAY": txscript.SigHashSingle | txscript.SigHashAnyOneCanPay,
+}
+
 // SignRawTransaction handles the signrawtransaction command.
 func SignRawTransaction(icmd interface{}, w *wallet.Wallet,
 	cc ...*chain.RPCClient) (interface{}, error) {
@@ -2027,21 +2411,8 @@ func SignRawTransaction(icmd interface{}, w *wallet.Wallet,
 		e := errors.New("TX decode failed")
 		return nil, DeserializationError{e}
 	}
-	var hashType txscript.SigHashType
-	switch *cmd.Flags {
-	case "ALL":
-		hashType = txscript.SigHashAll
-	case "NONE":
-		hashType = txscript.SigHashNone
-	case "SINGLE":
-		hashType = txscript.SigHashSingle
-	case "ALL|ANYONECANPAY":
-		hashType = txscript.SigHashAll | txscript.SigHashAnyOneCanPay
-	case "NONE|ANYONECANPAY":
-		hashType = txscript.SigHashNone | txscript.SigHashAnyOneCanPay
-	case "SINGLE|ANYONECANPAY":
-		hashType = txscript.SigHashSingle | txscript.SigHashAnyOneCanPay
-	default:
+	hashType, ok := SigHashTypes[*cmd.Flags]
+	if !ok {
 		e := errors.New("Invalid sighash parameter")
 		return nil, InvalidParameterError{e}
 	}
@@ -2213,8 +2584,10 @@ func ValidateAddress(icmd interface{}, w *wallet.Wallet,
 		return nil, err
 	}
 	// The address lookup was successful which means there is further
-	// information about it available and it is "mine".
-	result.IsMine = true
+	// information about it available and it is "mine", unless the wallet
+	// only watches it.
+	result.IsWatchOnly = ainfo.WatchOnly()
+	result.IsMine = !result.IsWatchOnly
 	acctName, err := w.AccountName(waddrmgr.KeyScopeBIP0044, ainfo.Account())
 	if err != nil {
 		Error(err)
@@ -2321,6 +2694,123 @@ func VerifyMessage(icmd interface{}, w *wallet.Wallet,
 	}
 }
 
+// WalletCreateFundedPSBT handles a walletcreatefundedpsbt request by creating
+// an unsigned PSBT paying the given outputs, funded by the wallet.
+func WalletCreateFundedPSBT(icmd interface{}, w *wallet.Wallet,
+	chainClient ...*chain.RPCClient) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.WalletCreateFundedPSBTCmd)
+	if !ok {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: HelpDescsEnUS()["walletcreatefundedpsbt"],
+		}
+	}
+	inputs := make([]*wire.OutPoint, len(cmd.Inputs))
+	for i, input := range cmd.Inputs {
+		txHash, err := chainhash.NewHashFromStr(input.Txid)
+		if err != nil {
+			Error(err)
+			return nil, DeserializationError{err}
+		}
+		inputs[i] = wire.NewOutPoint(txHash, input.Vout)
+	}
+	pairs := make(map[string]util.Amount, len(cmd.Outputs))
+	for k, v := range cmd.Outputs {
+		amt, err := util.NewAmount(v)
+		if err != nil {
+			Error(err)
+			return nil, err
+		}
+		if amt <= 0 {
+			return nil, ErrNeedPositiveAmount
+		}
+		pairs[k] = amt
+	}
+	outputs, err := MakeOutputs(pairs, w.ChainParams())
+	if err != nil {
+		Error(err)
+		return nil, InvalidParameterError{err}
+	}
+	var lockTime uint32
+	if cmd.LockTime != nil {
+		if *cmd.LockTime < 0 || *cmd.LockTime > int64(wire.MaxTxInSequenceNum) {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Locktime out of range",
+			}
+		}
+		lockTime = uint32(*cmd.LockTime)
+	}
+	opts := &wallet.PSBTFundingOptions{
+		ChangePosition: -1,
+		FeeSatPerKb:    w.FeeRate(),
+		PrevTxs:        make(map[chainhash.Hash]*wire.MsgTx),
+	}
+	// The previous transactions of the inputs are looked up on the node for
+	// the ones that are not in the wallet, such as outputs of a multisig it
+	// has one of the keys of. Mined ones need the node's transaction index.
+	if len(chainClient) > 0 && chainClient[0] != nil {
+		for _, op := range inputs {
+			tx, err := chainClient[0].GetRawTransaction(&op.Hash)
+			if err != nil {
+				Debug(err)
+				continue
+			}
+			opts.PrevTxs[op.Hash] = tx.MsgTx()
+		}
+	}
+	if o := cmd.Options; o != nil {
+		if o.ChangeAddress != nil {
+			opts.ChangeAddress, err = DecodeAddress(*o.ChangeAddress,
+				w.ChainParams())
+			if err != nil {
+				return nil, err
+			}
+		}
+		if o.ChangePosition != nil {
+			if *o.ChangePosition < 0 ||
+				*o.ChangePosition > int64(len(outputs)) {
+				return nil, &btcjson.RPCError{
+					Code:    btcjson.ErrRPCInvalidParameter,
+					Message: "changePosition out of bounds",
+				}
+			}
+			opts.ChangePosition = int(*o.ChangePosition)
+		}
+		if o.FeeRate != nil {
+			opts.FeeSatPerKb, err = util.NewAmount(*o.FeeRate)
+			if err != nil {
+				Error(err)
+				return nil, err
+			}
+			if opts.FeeSatPerKb <= 0 {
+				return nil, ErrNeedPositiveAmount
+			}
+		}
+		opts.LockUnspents = o.LockUnspents != nil && *o.LockUnspents
+		opts.Replaceable = o.Replaceable != nil && *o.Replaceable
+	}
+	packet, fee, changeIndex, err := w.CreateFundedPSBT(inputs, outputs,
+		lockTime, opts)
+	if err != nil {
+		Error(err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCWalletInsufficientFunds,
+			Message: err.Error(),
+		}
+	}
+	b64, err := packet.B64Encode()
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return btcjson.WalletCreateFundedPSBTResult{
+		PSBT:      b64,
+		Fee:       fee.ToDUO(),
+		ChangePos: int64(changeIndex),
+	}, nil
+}
+
 // WalletIsLocked handles the walletislocked extension request by
 // returning the current lock state (false for unlocked, true for locked)
 // of an account.
@@ -2352,11 +2842,7 @@ func WalletPassphrase(icmd interface{}, w *wallet.Wallet,
 		}
 	}
 	timeout := time.Second * time.Duration(cmd.Timeout)
-	var unlockAfter <-chan time.Time
-	if timeout != 0 {
-		unlockAfter = time.After(timeout)
-	}
-	err := w.Unlock([]byte(cmd.Passphrase), unlockAfter)
+	err := w.UnlockTimeout([]byte(cmd.Passphrase), timeout)
 	return nil, err
 }
 
@@ -2388,6 +2874,54 @@ func WalletPassphraseChange(icmd interface{}, w *wallet.Wallet,
 	return nil, err
 }
 
+// WalletProcessPSBT handles a walletprocesspsbt request by adding what the
+// wallet knows of the inputs of a PSBT to it and signing the ones it has the
+// keys for.
+func WalletProcessPSBT(icmd interface{}, w *wallet.Wallet,
+	chainClient ...*chain.RPCClient) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.WalletProcessPSBTCmd)
+	if !ok {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: HelpDescsEnUS()["walletprocesspsbt"],
+		}
+	}
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(cmd.PSBT), true)
+	if err != nil {
+		Error(err)
+		return nil, DeserializationError{err}
+	}
+	hashType := txscript.SigHashAll
+	if cmd.SighashType != nil {
+		hashType, ok = SigHashTypes[*cmd.SighashType]
+		if !ok {
+			e := errors.New("Invalid sighash parameter")
+			return nil, InvalidParameterError{e}
+		}
+	}
+	sign := cmd.Sign == nil || *cmd.Sign
+	complete, err := w.ProcessPSBT(packet, sign, hashType)
+	if err != nil {
+		Error(err)
+		if waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+			return nil, &ErrWalletUnlockNeeded
+		}
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCWallet,
+			Message: err.Error(),
+		}
+	}
+	b64, err := packet.B64Encode()
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return btcjson.WalletProcessPSBTResult{
+		PSBT:     b64,
+		Complete: complete,
+	}, nil
+}
+
 // DecodeHexStr decodes the hex encoding of a string, possibly prepending a
 // leading '0' character if there is an odd number of bytes in the hex string.
 // This is to prevent an error for an invalid hex string when using an odd
@@ -55,6 +55,10 @@ type (
 	None struct{} 
 	// AddMultiSigAddressRes is the result from a call to AddMultiSigAddress
 	AddMultiSigAddressRes struct { Res *string; Err error }
+	// BackupWalletRes is the result from a call to BackupWallet
+	BackupWalletRes struct { Res *None; Err error }
+	// BumpFeeRes is the result from a call to BumpFee
+	BumpFeeRes struct { Res *btcjson.BumpFeeResult; Err error }
 	// CreateMultiSigRes is the result from a call to CreateMultiSig
 	CreateMultiSigRes struct { Res *btcjson.CreateMultiSigResult; Err error }
 	// CreateNewAccountRes is the result from a call to CreateNewAccount
@@ -63,6 +67,8 @@ type (
 	HandleDropWalletHistoryRes struct { Res *string; Err error }
 	// DumpPrivKeyRes is the result from a call to DumpPrivKey
 	DumpPrivKeyRes struct { Res *string; Err error }
+	// DumpWalletRes is the result from a call to DumpWallet
+	DumpWalletRes struct { Res *btcjson.DumpWalletResult; Err error }
 	// GetAccountRes is the result from a call to GetAccount
 	GetAccountRes struct { Res *string; Err error }
 	// GetAccountAddressRes is the result from a call to GetAccountAddress
@@ -91,14 +97,26 @@ type (
 	GetTransactionRes struct { Res *btcjson.GetTransactionResult; Err error }
 	// GetUnconfirmedBalanceRes is the result from a call to GetUnconfirmedBalance
 	GetUnconfirmedBalanceRes struct { Res *float64; Err error }
+	// GetWalletInfoRes is the result from a call to GetWalletInfo
+	GetWalletInfoRes struct { Res *btcjson.GetWalletInfoResult; Err error }
 	// HelpNoChainRPCRes is the result from a call to HelpNoChainRPC
 	HelpNoChainRPCRes struct { Res *string; Err error }
+	// ImportAddressRes is the result from a call to ImportAddress
+	ImportAddressRes struct { Res *None; Err error }
 	// ImportPrivKeyRes is the result from a call to ImportPrivKey
 	ImportPrivKeyRes struct { Res *None; Err error }
+	// ImportPubKeyRes is the result from a call to ImportPubKey
+	ImportPubKeyRes struct { Res *None; Err error }
+	// ImportWalletRes is the result from a call to ImportWallet
+	ImportWalletRes struct { Res *None; Err error }
+	// ImportXpubRes is the result from a call to ImportXpub
+	ImportXpubRes struct { Res *None; Err error }
 	// KeypoolRefillRes is the result from a call to KeypoolRefill
 	KeypoolRefillRes struct { Res *None; Err error }
 	// ListAccountsRes is the result from a call to ListAccounts
 	ListAccountsRes struct { Res *map[string]float64; Err error }
+	// ListAddressGroupingsRes is the result from a call to ListAddressGroupings
+	ListAddressGroupingsRes struct { Res *[][]btcjson.ListAddressGroupingsResult; Err error }
 	// ListAddressTransactionsRes is the result from a call to ListAddressTransactions
 	ListAddressTransactionsRes struct { Res *[]btcjson.ListTransactionsResult; Err error }
 	// ListAllTransactionsRes is the result from a call to ListAllTransactions
@@ -133,6 +151,8 @@ type (
 	ValidateAddressRes struct { Res *btcjson.ValidateAddressWalletResult; Err error }
 	// VerifyMessageRes is the result from a call to VerifyMessage
 	VerifyMessageRes struct { Res *bool; Err error }
+	// WalletCreateFundedPSBTRes is the result from a call to WalletCreateFundedPSBT
+	WalletCreateFundedPSBTRes struct { Res *btcjson.WalletCreateFundedPSBTResult; Err error }
 	// WalletIsLockedRes is the result from a call to WalletIsLocked
 	WalletIsLockedRes struct { Res *bool; Err error }
 	// WalletLockRes is the result from a call to WalletLock
@@ -141,6 +161,8 @@ type (
 	WalletPassphraseRes struct { Res *None; Err error }
 	// WalletPassphraseChangeRes is the result from a call to WalletPassphraseChange
 	WalletPassphraseChangeRes struct { Res *None; Err error }
+	// WalletProcessPSBTRes is the result from a call to WalletProcessPSBT
+	WalletProcessPSBTRes struct { Res *btcjson.WalletProcessPSBTResult; Err error }
 )
 
 // RequestHandler is a handler function to handle an unmarshaled and parsed
@@ -186,6 +208,12 @@ var RPCHandlers = map[string]struct {
 	"addmultisigaddress":{ 
 		Handler: AddMultiSigAddress, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan AddMultiSigAddressRes)} }}, 
+	"backupwallet":{ 
+		Handler: BackupWallet, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan BackupWalletRes)} }}, 
+	"bumpfee":{ 
+		Handler: BumpFee, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan BumpFeeRes)} }}, 
 	"createmultisig":{ 
 		Handler: CreateMultiSig, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan CreateMultiSigRes)} }}, 
@@ -198,6 +226,9 @@ var RPCHandlers = map[string]struct {
 	"dumpprivkey":{ 
 		Handler: DumpPrivKey, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan DumpPrivKeyRes)} }}, 
+	"dumpwallet":{ 
+		Handler: DumpWallet, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan DumpWalletRes)} }}, 
 	"getaccount":{ 
 		Handler: GetAccount, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan GetAccountRes)} }}, 
@@ -240,18 +271,36 @@ var RPCHandlers = map[string]struct {
 	"getunconfirmedbalance":{ 
 		Handler: GetUnconfirmedBalance, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan GetUnconfirmedBalanceRes)} }}, 
+	"getwalletinfo":{ 
+		Handler: GetWalletInfo, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan GetWalletInfoRes)} }}, 
 	"help":{ 
 		Handler: HelpNoChainRPC, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan HelpNoChainRPCRes)} }}, 
+	"importaddress":{ 
+		Handler: ImportAddress, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan ImportAddressRes)} }}, 
 	"importprivkey":{ 
 		Handler: ImportPrivKey, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan ImportPrivKeyRes)} }}, 
+	"importpubkey":{ 
+		Handler: ImportPubKey, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan ImportPubKeyRes)} }}, 
+	"importwallet":{ 
+		Handler: ImportWallet, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan ImportWalletRes)} }}, 
+	"importxpub":{ 
+		Handler: ImportXpub, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan ImportXpubRes)} }}, 
 	"keypoolrefill":{ 
 		Handler: KeypoolRefill, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan KeypoolRefillRes)} }}, 
 	"listaccounts":{ 
 		Handler: ListAccounts, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan ListAccountsRes)} }}, 
+	"listaddressgroupings":{ 
+		Handler: ListAddressGroupings, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan ListAddressGroupingsRes)} }}, 
 	"listaddresstransactions":{ 
 		Handler: ListAddressTransactions, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan ListAddressTransactionsRes)} }}, 
@@ -303,6 +352,9 @@ var RPCHandlers = map[string]struct {
 	"verifymessage":{ 
 		Handler: VerifyMessage, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan VerifyMessageRes)} }}, 
+	"walletcreatefundedpsbt":{ 
+		Handler: WalletCreateFundedPSBT, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan WalletCreateFundedPSBTRes)} }}, 
 	"walletislocked":{ 
 		Handler: WalletIsLocked, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan WalletIsLockedRes)} }}, 
@@ -315,6 +367,9 @@ var RPCHandlers = map[string]struct {
 	"walletpassphrasechange":{ 
 		Handler: WalletPassphraseChange, Call: make(chan API, 32),
 		Result: func() API { return API{Ch: make(chan WalletPassphraseChangeRes)} }}, 
+	"walletprocesspsbt":{ 
+		Handler: WalletProcessPSBT, Call: make(chan API, 32),
+		Result: func() API { return API{Ch: make(chan WalletProcessPSBTRes)} }}, 
 
 }
 
@@ -366,6 +421,88 @@ func (a API) AddMultiSigAddressWait(cmd *btcjson.AddMultisigAddressCmd) (out *st
 	return
 }
 
+// BackupWallet calls the method with the given parameters
+func (a API) BackupWallet(cmd *btcjson.BackupWalletCmd) (err error) {
+	RPCHandlers["backupwallet"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// BackupWalletCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) BackupWalletCheck() (isNew bool) {
+	select {
+	case o := <- a.Ch.(chan BackupWalletRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// BackupWalletGetRes returns a pointer to the value in the Result field
+func (a API) BackupWalletGetRes() (out *None, err error) {
+	out, _ = a.Result.(*None)
+	err, _ = a.Result.(error)
+	return 
+}
+
+// BackupWalletWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) BackupWalletWait(cmd *btcjson.BackupWalletCmd) (out *None, err error) {
+	RPCHandlers["backupwallet"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second*5):
+		break
+	case o := <- a.Ch.(chan BackupWalletRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
+// BumpFee calls the method with the given parameters
+func (a API) BumpFee(cmd *btcjson.BumpFeeCmd) (err error) {
+	RPCHandlers["bumpfee"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// BumpFeeCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) BumpFeeCheck() (isNew bool) {
+	select {
+	case o := <- a.Ch.(chan BumpFeeRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// BumpFeeGetRes returns a pointer to the value in the Result field
+func (a API) BumpFeeGetRes() (out *btcjson.BumpFeeResult, err error) {
+	out, _ = a.Result.(*btcjson.BumpFeeResult)
+	err, _ = a.Result.(error)
+	return 
+}
+
+// BumpFeeWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) BumpFeeWait(cmd *btcjson.BumpFeeCmd) (out *btcjson.BumpFeeResult, err error) {
+	RPCHandlers["bumpfee"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second*5):
+		break
+	case o := <- a.Ch.(chan BumpFeeRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // CreateMultiSig calls the method with the given parameters
 func (a API) CreateMultiSig(cmd *btcjson.CreateMultisigCmd) (err error) {
 	RPCHandlers["createmultisig"].Call <- API{a.Ch, cmd, nil}
@@ -530,6 +667,47 @@ func (a API) DumpPrivKeyWait(cmd *btcjson.DumpPrivKeyCmd) (out *string, err erro
 	return
 }
 
+// DumpWallet calls the method with the given parameters
+func (a API) DumpWallet(cmd *btcjson.DumpWalletCmd) (err error) {
+	RPCHandlers["dumpwallet"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// DumpWalletCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) DumpWalletCheck() (isNew bool) {
+	select {
+	case o := <- a.Ch.(chan DumpWalletRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// DumpWalletGetRes returns a pointer to the value in the Result field
+func (a API) DumpWalletGetRes() (out *btcjson.DumpWalletResult, err error) {
+	out, _ = a.Result.(*btcjson.DumpWalletResult)
+	err, _ = a.Result.(error)
+	return 
+}
+
+// DumpWalletWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) DumpWalletWait(cmd *btcjson.DumpWalletCmd) (out *btcjson.DumpWalletResult, err error) {
+	RPCHandlers["dumpwallet"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second*5):
+		break
+	case o := <- a.Ch.(chan DumpWalletRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // GetAccount calls the method with the given parameters
 func (a API) GetAccount(cmd *btcjson.GetAccountCmd) (err error) {
 	RPCHandlers["getaccount"].Call <- API{a.Ch, cmd, nil}
@@ -1104,6 +1282,47 @@ func (a API) GetUnconfirmedBalanceWait(cmd *btcjson.GetUnconfirmedBalanceCmd) (o
 	return
 }
 
+// GetWalletInfo calls the method with the given parameters
+func (a API) GetWalletInfo(cmd *None) (err error) {
+	RPCHandlers["getwalletinfo"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// GetWalletInfoCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) GetWalletInfoCheck() (isNew bool) {
+	select {
+	case o := <- a.Ch.(chan GetWalletInfoRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// GetWalletInfoGetRes returns a pointer to the value in the Result field
+func (a API) GetWalletInfoGetRes() (out *btcjson.GetWalletInfoResult, err error) {
+	out, _ = a.Result.(*btcjson.GetWalletInfoResult)
+	err, _ = a.Result.(error)
+	return 
+}
+
+// GetWalletInfoWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) GetWalletInfoWait(cmd *None) (out *btcjson.GetWalletInfoResult, err error) {
+	RPCHandlers["getwalletinfo"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second*5):
+		break
+	case o := <- a.Ch.(chan GetWalletInfoRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // HelpNoChainRPC calls the method with the given parameters
 func (a API) HelpNoChainRPC(cmd btcjson.HelpCmd) (err error) {
 	RPCHandlers["help"].Call <- API{a.Ch, cmd, nil}
@@ -1145,6 +1364,47 @@ func (a API) HelpNoChainRPCWait(cmd btcjson.HelpCmd) (out *string, err error) {
 	return
 }
 
+// ImportAddress calls the method with the given parameters
+func (a API) ImportAddress(cmd *btcjson.ImportAddressCmd) (err error) {
+	RPCHandlers["importaddress"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// ImportAddressCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) ImportAddressCheck() (isNew bool) {
+	select {
+	case o := <- a.Ch.(chan ImportAddressRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// ImportAddressGetRes returns a pointer to the value in the Result field
+func (a API) ImportAddressGetRes() (out *None, err error) {
+	out, _ = a.Result.(*None)
+	err, _ = a.Result.(error)
+	return 
+}
+
+// ImportAddressWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) ImportAddressWait(cmd *btcjson.ImportAddressCmd) (out *None, err error) {
+	RPCHandlers["importaddress"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second*5):
+		break
+	case o := <- a.Ch.(chan ImportAddressRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // ImportPrivKey calls the method with the given parameters
 func (a API) ImportPrivKey(cmd *btcjson.ImportPrivKeyCmd) (err error) {
 	RPCHandlers["importprivkey"].Call <- API{a.Ch, cmd, nil}
@@ -1186,6 +1446,129 @@ func (a API) ImportPrivKeyWait(cmd *btcjson.ImportPrivKeyCmd) (out *None, err er
 	return
 }
 
+// ImportPubKey calls the method with the given parameters
+func (a API) ImportPubKey(cmd *btcjson.ImportPubKeyCmd) (err error) {
+	RPCHandlers["importpubkey"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// ImportPubKeyCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) ImportPubKeyCheck() (isNew bool) {
+	select {
+	case o := <- a.Ch.(chan ImportPubKeyRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// ImportPubKeyGetRes returns a pointer to the value in the Result field
+func (a API) ImportPubKeyGetRes() (out *None, err error) {
+	out, _ = a.Result.(*None)
+	err, _ = a.Result.(error)
+	return 
+}
+
+// ImportPubKeyWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) ImportPubKeyWait(cmd *btcjson.ImportPubKeyCmd) (out *None, err error) {
+	RPCHandlers["importpubkey"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second*5):
+		break
+	case o := <- a.Ch.(chan ImportPubKeyRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
+// ImportWallet calls the method with the given parameters
+func (a API) ImportWallet(cmd *btcjson.ImportWalletCmd) (err error) {
+	RPCHandlers["importwallet"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// ImportWalletCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) ImportWalletCheck() (isNew bool) {
+	select {
+	case o := <- a.Ch.(chan ImportWalletRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// ImportWalletGetRes returns a pointer to the value in the Result field
+func (a API) ImportWalletGetRes() (out *None, err error) {
+	out, _ = a.Result.(*None)
+	err, _ = a.Result.(error)
+	return 
+}
+
+// ImportWalletWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) ImportWalletWait(cmd *btcjson.ImportWalletCmd) (out *None, err error) {
+	RPCHandlers["importwallet"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second*5):
+		break
+	case o := <- a.Ch.(chan ImportWalletRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
+// ImportXpub calls the method with the given parameters
+func (a API) ImportXpub(cmd *btcjson.ImportXpubCmd) (err error) {
+	RPCHandlers["importxpub"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// ImportXpubCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) ImportXpubCheck() (isNew bool) {
+	select {
+	case o := <- a.Ch.(chan ImportXpubRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// ImportXpubGetRes returns a pointer to the value in the Result field
+func (a API) ImportXpubGetRes() (out *None, err error) {
+	out, _ = a.Result.(*None)
+	err, _ = a.Result.(error)
+	return 
+}
+
+// ImportXpubWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) ImportXpubWait(cmd *btcjson.ImportXpubCmd) (out *None, err error) {
+	RPCHandlers["importxpub"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second*5):
+		break
+	case o := <- a.Ch.(chan ImportXpubRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // KeypoolRefill calls the method with the given parameters
 func (a API) KeypoolRefill(cmd *None) (err error) {
 	RPCHandlers["keypoolrefill"].Call <- API{a.Ch, cmd, nil}
@@ -1268,6 +1651,47 @@ func (a API) ListAccountsWait(cmd *btcjson.ListAccountsCmd) (out *map[string]flo
 	return
 }
 
+// ListAddressGroupings calls the method with the given parameters
+func (a API) ListAddressGroupings(cmd *None) (err error) {
+	RPCHandlers["listaddressgroupings"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// ListAddressGroupingsCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) ListAddressGroupingsCheck() (isNew bool) {
+	select {
+	case o := <- a.Ch.(chan ListAddressGroupingsRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// ListAddressGroupingsGetRes returns a pointer to the value in the Result field
+func (a API) ListAddressGroupingsGetRes() (out *[][]btcjson.ListAddressGroupingsResult, err error) {
+	out, _ = a.Result.(*[][]btcjson.ListAddressGroupingsResult)
+	err, _ = a.Result.(error)
+	return 
+}
+
+// ListAddressGroupingsWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) ListAddressGroupingsWait(cmd *None) (out *[][]btcjson.ListAddressGroupingsResult, err error) {
+	RPCHandlers["listaddressgroupings"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second*5):
+		break
+	case o := <- a.Ch.(chan ListAddressGroupingsRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 // ListAddressTransactions calls the method with the given parameters
 func (a API) ListAddressTransactions(cmd *btcjson.ListAddressTransactionsCmd) (err error) {
 	RPCHandlers["listaddresstransactions"].Call <- API{a.Ch, cmd, nil}
@@ -1918,23 +2342,64 @@ func (a API) ValidateAddressWait(cmd *btcjson.ValidateAddressCmd) (out *btcjson.
 	select {
 	case <-time.After(time.Second*5):
 		break
-	case o := <- a.Ch.(chan ValidateAddressRes):
+	case o := <- a.Ch.(chan ValidateAddressRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
+// VerifyMessage calls the method with the given parameters
+func (a API) VerifyMessage(cmd *btcjson.VerifyMessageCmd) (err error) {
+	RPCHandlers["verifymessage"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// VerifyMessageCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) VerifyMessageCheck() (isNew bool) {
+	select {
+	case o := <- a.Ch.(chan VerifyMessageRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// VerifyMessageGetRes returns a pointer to the value in the Result field
+func (a API) VerifyMessageGetRes() (out *bool, err error) {
+	out, _ = a.Result.(*bool)
+	err, _ = a.Result.(error)
+	return 
+}
+
+// VerifyMessageWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) VerifyMessageWait(cmd *btcjson.VerifyMessageCmd) (out *bool, err error) {
+	RPCHandlers["verifymessage"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second*5):
+		break
+	case o := <- a.Ch.(chan VerifyMessageRes):
 		out, err = o.Res, o.Err
 	}
 	return
 }
 
-// VerifyMessage calls the method with the given parameters
-func (a API) VerifyMessage(cmd *btcjson.VerifyMessageCmd) (err error) {
-	RPCHandlers["verifymessage"].Call <- API{a.Ch, cmd, nil}
+// WalletCreateFundedPSBT calls the method with the given parameters
+func (a API) WalletCreateFundedPSBT(cmd *btcjson.WalletCreateFundedPSBTCmd) (err error) {
+	RPCHandlers["walletcreatefundedpsbt"].Call <- API{a.Ch, cmd, nil}
 	return
 }
 
-// VerifyMessageCheck checks if a new message arrived on the result channel and
+// WalletCreateFundedPSBTCheck checks if a new message arrived on the result channel and
 // returns true if it does, as well as storing the value in the Result field
-func (a API) VerifyMessageCheck() (isNew bool) {
+func (a API) WalletCreateFundedPSBTCheck() (isNew bool) {
 	select {
-	case o := <- a.Ch.(chan VerifyMessageRes):
+	case o := <- a.Ch.(chan WalletCreateFundedPSBTRes):
 		if o.Err != nil {
 			a.Result = o.Err
 		} else {
@@ -1946,20 +2411,20 @@ func (a API) VerifyMessageCheck() (isNew bool) {
 	return
 }
 
-// VerifyMessageGetRes returns a pointer to the value in the Result field
-func (a API) VerifyMessageGetRes() (out *bool, err error) {
-	out, _ = a.Result.(*bool)
+// WalletCreateFundedPSBTGetRes returns a pointer to the value in the Result field
+func (a API) WalletCreateFundedPSBTGetRes() (out *btcjson.WalletCreateFundedPSBTResult, err error) {
+	out, _ = a.Result.(*btcjson.WalletCreateFundedPSBTResult)
 	err, _ = a.Result.(error)
 	return 
 }
 
-// VerifyMessageWait calls the method and blocks until it returns or 5 seconds passes
-func (a API) VerifyMessageWait(cmd *btcjson.VerifyMessageCmd) (out *bool, err error) {
-	RPCHandlers["verifymessage"].Call <- API{a.Ch, cmd, nil}
+// WalletCreateFundedPSBTWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) WalletCreateFundedPSBTWait(cmd *btcjson.WalletCreateFundedPSBTCmd) (out *btcjson.WalletCreateFundedPSBTResult, err error) {
+	RPCHandlers["walletcreatefundedpsbt"].Call <- API{a.Ch, cmd, nil}
 	select {
 	case <-time.After(time.Second*5):
 		break
-	case o := <- a.Ch.(chan VerifyMessageRes):
+	case o := <- a.Ch.(chan WalletCreateFundedPSBTRes):
 		out, err = o.Res, o.Err
 	}
 	return
@@ -2129,6 +2594,47 @@ func (a API) WalletPassphraseChangeWait(cmd *btcjson.WalletPassphraseChangeCmd)
 	return
 }
 
+// WalletProcessPSBT calls the method with the given parameters
+func (a API) WalletProcessPSBT(cmd *btcjson.WalletProcessPSBTCmd) (err error) {
+	RPCHandlers["walletprocesspsbt"].Call <- API{a.Ch, cmd, nil}
+	return
+}
+
+// WalletProcessPSBTCheck checks if a new message arrived on the result channel and
+// returns true if it does, as well as storing the value in the Result field
+func (a API) WalletProcessPSBTCheck() (isNew bool) {
+	select {
+	case o := <- a.Ch.(chan WalletProcessPSBTRes):
+		if o.Err != nil {
+			a.Result = o.Err
+		} else {
+			a.Result = o.Res
+		}
+		isNew = true
+	default:
+	}
+	return
+}
+
+// WalletProcessPSBTGetRes returns a pointer to the value in the Result field
+func (a API) WalletProcessPSBTGetRes() (out *btcjson.WalletProcessPSBTResult, err error) {
+	out, _ = a.Result.(*btcjson.WalletProcessPSBTResult)
+	err, _ = a.Result.(error)
+	return 
+}
+
+// WalletProcessPSBTWait calls the method and blocks until it returns or 5 seconds passes
+func (a API) WalletProcessPSBTWait(cmd *btcjson.WalletProcessPSBTCmd) (out *btcjson.WalletProcessPSBTResult, err error) {
+	RPCHandlers["walletprocesspsbt"].Call <- API{a.Ch, cmd, nil}
+	select {
+	case <-time.After(time.Second*5):
+		break
+	case o := <- a.Ch.(chan WalletProcessPSBTRes):
+		out, err = o.Res, o.Err
+	}
+	return
+}
+
 
 // RunAPI starts up the api handler server that receives rpc.API messages and runs the handler and returns the result
 // Note that the parameters are type asserted to prevent the consumer of the API from sending wrong message types not
@@ -2149,6 +2655,20 @@ func RunAPI(chainRPC *chain.RPCClient, wallet *wallet.Wallet,
 				}
 				if r, ok := res.(string); ok { 
 					msg.Ch.(chan AddMultiSigAddressRes) <- AddMultiSigAddressRes{&r, err} } 
+			case msg := <-nrh["backupwallet"].Call:
+				if res, err = nrh["backupwallet"].
+					Handler(msg.Params.(*btcjson.BackupWalletCmd), wallet, 
+						chainRPC); Check(err) {
+				}
+				if r, ok := res.(None); ok { 
+					msg.Ch.(chan BackupWalletRes) <- BackupWalletRes{&r, err} } 
+			case msg := <-nrh["bumpfee"].Call:
+				if res, err = nrh["bumpfee"].
+					Handler(msg.Params.(*btcjson.BumpFeeCmd), wallet, 
+						chainRPC); Check(err) {
+				}
+				if r, ok := res.(btcjson.BumpFeeResult); ok { 
+					msg.Ch.(chan BumpFeeRes) <- BumpFeeRes{&r, err} } 
 			case msg := <-nrh["createmultisig"].Call:
 				if res, err = nrh["createmultisig"].
 					Handler(msg.Params.(*btcjson.CreateMultisigCmd), wallet, 
@@ -2177,6 +2697,13 @@ func RunAPI(chainRPC *chain.RPCClient, wallet *wallet.Wallet,
 				}
 				if r, ok := res.(string); ok { 
 					msg.Ch.(chan DumpPrivKeyRes) <- DumpPrivKeyRes{&r, err} } 
+			case msg := <-nrh["dumpwallet"].Call:
+				if res, err = nrh["dumpwallet"].
+					Handler(msg.Params.(*btcjson.DumpWalletCmd), wallet, 
+						chainRPC); Check(err) {
+				}
+				if r, ok := res.(btcjson.DumpWalletResult); ok { 
+					msg.Ch.(chan DumpWalletRes) <- DumpWalletRes{&r, err} } 
 			case msg := <-nrh["getaccount"].Call:
 				if res, err = nrh["getaccount"].
 					Handler(msg.Params.(*btcjson.GetAccountCmd), wallet, 
@@ -2275,6 +2802,13 @@ func RunAPI(chainRPC *chain.RPCClient, wallet *wallet.Wallet,
 				}
 				if r, ok := res.(float64); ok { 
 					msg.Ch.(chan GetUnconfirmedBalanceRes) <- GetUnconfirmedBalanceRes{&r, err} } 
+			case msg := <-nrh["getwalletinfo"].Call:
+				if res, err = nrh["getwalletinfo"].
+					Handler(msg.Params.(*None), wallet, 
+						chainRPC); Check(err) {
+				}
+				if r, ok := res.(btcjson.GetWalletInfoResult); ok { 
+					msg.Ch.(chan GetWalletInfoRes) <- GetWalletInfoRes{&r, err} } 
 			case msg := <-nrh["help"].Call:
 				if res, err = nrh["help"].
 					Handler(msg.Params.(btcjson.HelpCmd), wallet, 
@@ -2282,6 +2816,13 @@ func RunAPI(chainRPC *chain.RPCClient, wallet *wallet.Wallet,
 				}
 				if r, ok := res.(string); ok { 
 					msg.Ch.(chan HelpNoChainRPCRes) <- HelpNoChainRPCRes{&r, err} } 
+			case msg := <-nrh["importaddress"].Call:
+				if res, err = nrh["importaddress"].
+					Handler(msg.Params.(*btcjson.ImportAddressCmd), wallet, 
+						chainRPC); Check(err) {
+				}
+				if r, ok := res.(None); ok { 
+					msg.Ch.(chan ImportAddressRes) <- ImportAddressRes{&r, err} } 
 			case msg := <-nrh["importprivkey"].Call:
 				if res, err = nrh["importprivkey"].
 					Handler(msg.Params.(*btcjson.ImportPrivKeyCmd), wallet, 
@@ -2289,6 +2830,27 @@ func RunAPI(chainRPC *chain.RPCClient, wallet *wallet.Wallet,
 				}
 				if r, ok := res.(None); ok { 
 					msg.Ch.(chan ImportPrivKeyRes) <- ImportPrivKeyRes{&r, err} } 
+			case msg := <-nrh["importpubkey"].Call:
+				if res, err = nrh["importpubkey"].
+					Handler(msg.Params.(*btcjson.ImportPubKeyCmd), wallet, 
+						chainRPC); Check(err) {
+				}
+				if r, ok := res.(None); ok { 
+					msg.Ch.(chan ImportPubKeyRes) <- ImportPubKeyRes{&r, err} } 
+			case msg := <-nrh["importwallet"].Call:
+				if res, err = nrh["importwallet"].
+					Handler(msg.Params.(*btcjson.ImportWalletCmd), wallet, 
+						chainRPC); Check(err) {
+				}
+				if r, ok := res.(None); ok { 
+					msg.Ch.(chan ImportWalletRes) <- ImportWalletRes{&r, err} } 
+			case msg := <-nrh["importxpub"].Call:
+				if res, err = nrh["importxpub"].
+					Handler(msg.Params.(*btcjson.ImportXpubCmd), wallet, 
+						chainRPC); Check(err) {
+				}
+				if r, ok := res.(None); ok { 
+					msg.Ch.(chan ImportXpubRes) <- ImportXpubRes{&r, err} } 
 			case msg := <-nrh["keypoolrefill"].Call:
 				if res, err = nrh["keypoolrefill"].
 					Handler(msg.Params.(*None), wallet, 
@@ -2303,6 +2865,13 @@ func RunAPI(chainRPC *chain.RPCClient, wallet *wallet.Wallet,
 				}
 				if r, ok := res.(map[string]float64); ok { 
 					msg.Ch.(chan ListAccountsRes) <- ListAccountsRes{&r, err} } 
+			case msg := <-nrh["listaddressgroupings"].Call:
+				if res, err = nrh["listaddressgroupings"].
+					Handler(msg.Params.(*None), wallet, 
+						chainRPC); Check(err) {
+				}
+				if r, ok := res.([][]btcjson.ListAddressGroupingsResult); ok { 
+					msg.Ch.(chan ListAddressGroupingsRes) <- ListAddressGroupingsRes{&r, err} } 
 			case msg := <-nrh["listaddresstransactions"].Call:
 				if res, err = nrh["listaddresstransactions"].
 					Handler(msg.Params.(*btcjson.ListAddressTransactionsCmd), wallet, 
@@ -2422,6 +2991,13 @@ func RunAPI(chainRPC *chain.RPCClient, wallet *wallet.Wallet,
 				}
 				if r, ok := res.(bool); ok { 
 					msg.Ch.(chan VerifyMessageRes) <- VerifyMessageRes{&r, err} } 
+			case msg := <-nrh["walletcreatefundedpsbt"].Call:
+				if res, err = nrh["walletcreatefundedpsbt"].
+					Handler(msg.Params.(*btcjson.WalletCreateFundedPSBTCmd), wallet, 
+						chainRPC); Check(err) {
+				}
+				if r, ok := res.(btcjson.WalletCreateFundedPSBTResult); ok { 
+					msg.Ch.(chan WalletCreateFundedPSBTRes) <- WalletCreateFundedPSBTRes{&r, err} } 
 			case msg := <-nrh["walletislocked"].Call:
 				if res, err = nrh["walletislocked"].
 					Handler(msg.Params.(*None), wallet, 
@@ -2450,6 +3026,13 @@ func RunAPI(chainRPC *chain.RPCClient, wallet *wallet.Wallet,
 				}
 				if r, ok := res.(None); ok { 
 					msg.Ch.(chan WalletPassphraseChangeRes) <- WalletPassphraseChangeRes{&r, err} } 
+			case msg := <-nrh["walletprocesspsbt"].Call:
+				if res, err = nrh["walletprocesspsbt"].
+					Handler(msg.Params.(*btcjson.WalletProcessPSBTCmd), wallet, 
+						chainRPC); Check(err) {
+				}
+				if r, ok := res.(btcjson.WalletProcessPSBTResult); ok { 
+					msg.Ch.(chan WalletProcessPSBTRes) <- WalletProcessPSBTRes{&r, err} } 
 			case <-quit:
 				Debug("stopping wallet cAPI")
 				return
@@ -2473,6 +3056,32 @@ func (c *CAPI) AddMultiSigAddress(req **btcjson.AddMultisigAddressCmd, resp *str
 	return 
 }
 
+func (c *CAPI) BackupWallet(req **btcjson.BackupWalletCmd, resp *None) (err error) {
+	nrh := RPCHandlers
+	res := nrh["backupwallet"].Result()
+	res.Params = req
+	nrh["backupwallet"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan None):
+	case <-time.After(c.Timeout):
+	case <- c.quit:
+	} 
+	return 
+}
+
+func (c *CAPI) BumpFee(req **btcjson.BumpFeeCmd, resp *btcjson.BumpFeeResult) (err error) {
+	nrh := RPCHandlers
+	res := nrh["bumpfee"].Result()
+	res.Params = req
+	nrh["bumpfee"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan btcjson.BumpFeeResult):
+	case <-time.After(c.Timeout):
+	case <- c.quit:
+	} 
+	return 
+}
+
 func (c *CAPI) CreateMultiSig(req **btcjson.CreateMultisigCmd, resp *btcjson.CreateMultiSigResult) (err error) {
 	nrh := RPCHandlers
 	res := nrh["createmultisig"].Result()
@@ -2525,6 +3134,19 @@ func (c *CAPI) DumpPrivKey(req **btcjson.DumpPrivKeyCmd, resp *string) (err erro
 	return 
 }
 
+func (c *CAPI) DumpWallet(req **btcjson.DumpWalletCmd, resp *btcjson.DumpWalletResult) (err error) {
+	nrh := RPCHandlers
+	res := nrh["dumpwallet"].Result()
+	res.Params = req
+	nrh["dumpwallet"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan btcjson.DumpWalletResult):
+	case <-time.After(c.Timeout):
+	case <- c.quit:
+	} 
+	return 
+}
+
 func (c *CAPI) GetAccount(req **btcjson.GetAccountCmd, resp *string) (err error) {
 	nrh := RPCHandlers
 	res := nrh["getaccount"].Result()
@@ -2707,6 +3329,19 @@ func (c *CAPI) GetUnconfirmedBalance(req **btcjson.GetUnconfirmedBalanceCmd, res
 	return 
 }
 
+func (c *CAPI) GetWalletInfo(req **None, resp *btcjson.GetWalletInfoResult) (err error) {
+	nrh := RPCHandlers
+	res := nrh["getwalletinfo"].Result()
+	res.Params = req
+	nrh["getwalletinfo"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan btcjson.GetWalletInfoResult):
+	case <-time.After(c.Timeout):
+	case <- c.quit:
+	} 
+	return 
+}
+
 func (c *CAPI) HelpNoChainRPC(req *btcjson.HelpCmd, resp *string) (err error) {
 	nrh := RPCHandlers
 	res := nrh["help"].Result()
@@ -2720,6 +3355,19 @@ func (c *CAPI) HelpNoChainRPC(req *btcjson.HelpCmd, resp *string) (err error) {
 	return 
 }
 
+func (c *CAPI) ImportAddress(req **btcjson.ImportAddressCmd, resp *None) (err error) {
+	nrh := RPCHandlers
+	res := nrh["importaddress"].Result()
+	res.Params = req
+	nrh["importaddress"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan None):
+	case <-time.After(c.Timeout):
+	case <- c.quit:
+	} 
+	return 
+}
+
 func (c *CAPI) ImportPrivKey(req **btcjson.ImportPrivKeyCmd, resp *None) (err error) {
 	nrh := RPCHandlers
 	res := nrh["importprivkey"].Result()
@@ -2733,6 +3381,45 @@ func (c *CAPI) ImportPrivKey(req **btcjson.ImportPrivKeyCmd, resp *None) (err er
 	return 
 }
 
+func (c *CAPI) ImportPubKey(req **btcjson.ImportPubKeyCmd, resp *None) (err error) {
+	nrh := RPCHandlers
+	res := nrh["importpubkey"].Result()
+	res.Params = req
+	nrh["importpubkey"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan None):
+	case <-time.After(c.Timeout):
+	case <- c.quit:
+	} 
+	return 
+}
+
+func (c *CAPI) ImportWallet(req **btcjson.ImportWalletCmd, resp *None) (err error) {
+	nrh := RPCHandlers
+	res := nrh["importwallet"].Result()
+	res.Params = req
+	nrh["importwallet"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan None):
+	case <-time.After(c.Timeout):
+	case <- c.quit:
+	} 
+	return 
+}
+
+func (c *CAPI) ImportXpub(req **btcjson.ImportXpubCmd, resp *None) (err error) {
+	nrh := RPCHandlers
+	res := nrh["importxpub"].Result()
+	res.Params = req
+	nrh["importxpub"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan None):
+	case <-time.After(c.Timeout):
+	case <- c.quit:
+	} 
+	return 
+}
+
 func (c *CAPI) KeypoolRefill(req **None, resp *None) (err error) {
 	nrh := RPCHandlers
 	res := nrh["keypoolrefill"].Result()
@@ -2759,6 +3446,19 @@ func (c *CAPI) ListAccounts(req **btcjson.ListAccountsCmd, resp *map[string]floa
 	return 
 }
 
+func (c *CAPI) ListAddressGroupings(req **None, resp *[][]btcjson.ListAddressGroupingsResult) (err error) {
+	nrh := RPCHandlers
+	res := nrh["listaddressgroupings"].Result()
+	res.Params = req
+	nrh["listaddressgroupings"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan [][]btcjson.ListAddressGroupingsResult):
+	case <-time.After(c.Timeout):
+	case <- c.quit:
+	} 
+	return 
+}
+
 func (c *CAPI) ListAddressTransactions(req **btcjson.ListAddressTransactionsCmd, resp *[]btcjson.ListTransactionsResult) (err error) {
 	nrh := RPCHandlers
 	res := nrh["listaddresstransactions"].Result()
@@ -2980,6 +3680,19 @@ func (c *CAPI) VerifyMessage(req **btcjson.VerifyMessageCmd, resp *bool) (err er
 	return 
 }
 
+func (c *CAPI) WalletCreateFundedPSBT(req **btcjson.WalletCreateFundedPSBTCmd, resp *btcjson.WalletCreateFundedPSBTResult) (err error) {
+	nrh := RPCHandlers
+	res := nrh["walletcreatefundedpsbt"].Result()
+	res.Params = req
+	nrh["walletcreatefundedpsbt"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan btcjson.WalletCreateFundedPSBTResult):
+	case <-time.After(c.Timeout):
+	case <- c.quit:
+	} 
+	return 
+}
+
 func (c *CAPI) WalletIsLocked(req **None, resp *bool) (err error) {
 	nrh := RPCHandlers
 	res := nrh["walletislocked"].Result()
@@ -3032,6 +3745,19 @@ func (c *CAPI) WalletPassphraseChange(req **btcjson.WalletPassphraseChangeCmd, r
 	return 
 }
 
+func (c *CAPI) WalletProcessPSBT(req **btcjson.WalletProcessPSBTCmd, resp *btcjson.WalletProcessPSBTResult) (err error) {
+	nrh := RPCHandlers
+	res := nrh["walletprocesspsbt"].Result()
+	res.Params = req
+	nrh["walletprocesspsbt"].Call <- res
+	select {
+	case *resp = <-res.Ch.(chan btcjson.WalletProcessPSBTResult):
+	case <-time.After(c.Timeout):
+	case <- c.quit:
+	} 
+	return 
+}
+
 // Client call wrappers for a CAPI client with a given Conn
 
 func (r *CAPIClient) AddMultiSigAddress(cmd ...*btcjson.AddMultisigAddressCmd) (res string, err error) {
@@ -3044,6 +3770,26 @@ func (r *CAPIClient) AddMultiSigAddress(cmd ...*btcjson.AddMultisigAddressCmd) (
 	return
 }
 
+func (r *CAPIClient) BackupWallet(cmd ...*btcjson.BackupWalletCmd) (res None, err error) {
+	var c *btcjson.BackupWalletCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.BackupWallet", c, &res); Check(err) {
+	}
+	return
+}
+
+func (r *CAPIClient) BumpFee(cmd ...*btcjson.BumpFeeCmd) (res btcjson.BumpFeeResult, err error) {
+	var c *btcjson.BumpFeeCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.BumpFee", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) CreateMultiSig(cmd ...*btcjson.CreateMultisigCmd) (res btcjson.CreateMultiSigResult, err error) {
 	var c *btcjson.CreateMultisigCmd
 	if len(cmd) > 0 {
@@ -3084,6 +3830,16 @@ func (r *CAPIClient) DumpPrivKey(cmd ...*btcjson.DumpPrivKeyCmd) (res string, er
 	return
 }
 
+func (r *CAPIClient) DumpWallet(cmd ...*btcjson.DumpWalletCmd) (res btcjson.DumpWalletResult, err error) {
+	var c *btcjson.DumpWalletCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.DumpWallet", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) GetAccount(cmd ...*btcjson.GetAccountCmd) (res string, err error) {
 	var c *btcjson.GetAccountCmd
 	if len(cmd) > 0 {
@@ -3224,6 +3980,16 @@ func (r *CAPIClient) GetUnconfirmedBalance(cmd ...*btcjson.GetUnconfirmedBalance
 	return
 }
 
+func (r *CAPIClient) GetWalletInfo(cmd ...*None) (res btcjson.GetWalletInfoResult, err error) {
+	var c *None
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.GetWalletInfo", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) HelpNoChainRPC(cmd ...btcjson.HelpCmd) (res string, err error) {
 	var c btcjson.HelpCmd
 	if len(cmd) > 0 {
@@ -3234,6 +4000,16 @@ func (r *CAPIClient) HelpNoChainRPC(cmd ...btcjson.HelpCmd) (res string, err err
 	return
 }
 
+func (r *CAPIClient) ImportAddress(cmd ...*btcjson.ImportAddressCmd) (res None, err error) {
+	var c *btcjson.ImportAddressCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.ImportAddress", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) ImportPrivKey(cmd ...*btcjson.ImportPrivKeyCmd) (res None, err error) {
 	var c *btcjson.ImportPrivKeyCmd
 	if len(cmd) > 0 {
@@ -3244,6 +4020,36 @@ func (r *CAPIClient) ImportPrivKey(cmd ...*btcjson.ImportPrivKeyCmd) (res None,
 	return
 }
 
+func (r *CAPIClient) ImportPubKey(cmd ...*btcjson.ImportPubKeyCmd) (res None, err error) {
+	var c *btcjson.ImportPubKeyCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.ImportPubKey", c, &res); Check(err) {
+	}
+	return
+}
+
+func (r *CAPIClient) ImportWallet(cmd ...*btcjson.ImportWalletCmd) (res None, err error) {
+	var c *btcjson.ImportWalletCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.ImportWallet", c, &res); Check(err) {
+	}
+	return
+}
+
+func (r *CAPIClient) ImportXpub(cmd ...*btcjson.ImportXpubCmd) (res None, err error) {
+	var c *btcjson.ImportXpubCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.ImportXpub", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) KeypoolRefill(cmd ...*None) (res None, err error) {
 	var c *None
 	if len(cmd) > 0 {
@@ -3264,6 +4070,16 @@ func (r *CAPIClient) ListAccounts(cmd ...*btcjson.ListAccountsCmd) (res map[stri
 	return
 }
 
+func (r *CAPIClient) ListAddressGroupings(cmd ...*None) (res [][]btcjson.ListAddressGroupingsResult, err error) {
+	var c *None
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.ListAddressGroupings", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) ListAddressTransactions(cmd ...*btcjson.ListAddressTransactionsCmd) (res []btcjson.ListTransactionsResult, err error) {
 	var c *btcjson.ListAddressTransactionsCmd
 	if len(cmd) > 0 {
@@ -3434,6 +4250,16 @@ func (r *CAPIClient) VerifyMessage(cmd ...*btcjson.VerifyMessageCmd) (res bool,
 	return
 }
 
+func (r *CAPIClient) WalletCreateFundedPSBT(cmd ...*btcjson.WalletCreateFundedPSBTCmd) (res btcjson.WalletCreateFundedPSBTResult, err error) {
+	var c *btcjson.WalletCreateFundedPSBTCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.WalletCreateFundedPSBT", c, &res); Check(err) {
+	}
+	return
+}
+
 func (r *CAPIClient) WalletIsLocked(cmd ...*None) (res bool, err error) {
 	var c *None
 	if len(cmd) > 0 {
@@ -3474,3 +4300,13 @@ func (r *CAPIClient) WalletPassphraseChange(cmd ...*btcjson.WalletPassphraseChan
 	return
 }
 
+func (r *CAPIClient) WalletProcessPSBT(cmd ...*btcjson.WalletProcessPSBTCmd) (res btcjson.WalletProcessPSBTResult, err error) {
+	var c *btcjson.WalletProcessPSBTCmd
+	if len(cmd) > 0 {
+		c = cmd[0]
+	}
+	if err = r.Call("CAPI.WalletProcessPSBT", c, &res); Check(err) {
+	}
+	return
+}
+
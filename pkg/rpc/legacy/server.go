@@ -16,12 +16,21 @@ import (
 
 	"github.com/btcsuite/websocket"
 
+	"github.com/p9c/pod/pkg/metrics"
 	"github.com/p9c/pod/pkg/rpc/btcjson"
 	"github.com/p9c/pod/pkg/util/interrupt"
 	"github.com/p9c/pod/pkg/wallet"
 	"github.com/p9c/pod/pkg/wallet/chain"
 )
 
+// rpcRequestSeconds is the latency of the wallet RPC requests by method.
+var rpcRequestSeconds = metrics.NewHistogram(
+	"pod_wallet_rpc_request_duration_seconds",
+	"Time taken to handle wallet RPC requests, by method. Requests passed "+
+		"through to the node are counted together as passthrough.",
+	metrics.LatencyBuckets, "method",
+)
+
 type WebsocketClient struct {
 	conn          *websocket.Conn
 	authenticated bool
@@ -80,6 +89,7 @@ func JSONAuthFail(w http.ResponseWriter) {
 // NewServer creates a new server for serving legacy RPC client connections,
 // both HTTP POST and websocket.
 func NewServer(opts *Options, walletLoader *wallet.Loader, listeners []net.Listener) *Server {
+	metrics.Register("wallet_rpc", rpcRequestSeconds)
 	serveMux := http.NewServeMux()
 	const rpcAuthTimeoutSeconds = 10
 	server := &Server{
@@ -260,7 +270,17 @@ func (s *Server) HandlerClosure(request *btcjson.Request) LazyHandler {
 		s.ChainClient = chainClient
 	}
 	s.HandlerMutex.Unlock()
-	return LazyApplyHandler(request, wllt, chainClient)
+	handler := LazyApplyHandler(request, wllt, chainClient)
+	// Methods the wallet doesn't handle itself are timed together, so that
+	// arbitrary method names don't each add to the metrics.
+	method := request.Method
+	if _, ok := RPCHandlers[method]; !ok {
+		method = "passthrough"
+	}
+	return func() (interface{}, *btcjson.RPCError) {
+		defer rpcRequestSeconds.ObserveSince(time.Now(), method)
+		return handler()
+	}
 }
 
 // ErrNoAuth represents an error where authentication could not succeed
@@ -1,14 +1,13 @@
 package transport
 
 import (
-	"crypto/cipher"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
 	"runtime"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/p9c/pod/pkg/fec"
@@ -35,16 +34,26 @@ type (
 	Handlers    map[string]HandlerFunc
 	Channel     struct {
 		buffers         map[string]*MsgBuffer
+		bufMx           sync.Mutex
 		Ready           chan struct{}
 		context         interface{}
 		Creator         string
 		firstSender     *string
 		lastSent        *time.Time
 		MaxDatagramSize int
-		receiveCiph     cipher.AEAD
+		recv            *sessions
 		Receiver        *net.UDPConn
-		sendCiph        cipher.AEAD
+		send            *sendSession
 		Sender          *net.UDPConn
+		// the following are used by unicast server and client channels,
+		// the peers map is only created for channels that accept subscribers
+		mx       sync.Mutex
+		peers    map[string]*peer
+		streams  map[net.Conn]struct{}
+		listener net.Listener
+		remote   *net.UDPAddr
+		done     chan struct{}
+		doneOnce sync.Once
 	}
 )
 
@@ -57,43 +66,165 @@ func (c *Channel) SetDestination(dst string) (err error) {
 }
 
 // Send fires off some data through the configured channel's outbound.
-func (c *Channel) Send(magic []byte, nonce []byte, data []byte) (n int, err error) {
+func (c *Channel) Send(magic []byte, message uint64, data []byte) (n int, err error) {
 	if len(data) == 0 {
 		err = errors.New("not sending empty packet")
 		Error(err)
 		return
 	}
 	var msg []byte
-	if msg, err = EncryptMessage(c.Creator, c.sendCiph, magic, nonce, data); Check(err) {
+	var rekeyed bool
+	if msg, rekeyed, err = c.send.seal(magic, message, data); Check(err) {
+		return
+	}
+	if rekeyed {
+		// the receivers need the key of the new epoch before its packets
+		if err = c.receiveOwn(); Check(err) {
+		}
+		for _, key := range c.send.keyPackets() {
+			if _, err = c.transmit(key); Check(err) {
+			}
+		}
 	}
-	n, err = c.Sender.Write(msg)
+	return c.transmit(msg)
+}
+
+// transmit writes a packet to the outbound connection, the server and the
+// subscribers and streams of a channel
+func (c *Channel) transmit(msg []byte) (n int, err error) {
+	if c.Sender != nil {
+		n, err = c.Sender.Write(msg)
+	}
+	if c.remote != nil {
+		n, err = c.Receiver.WriteToUDP(msg, c.remote)
+	}
+	c.sendPeers(msg)
 	// DEBUG(msg)
 	return
 }
 
 // SendMany sends a BufIter of shards as produced by GetShards
 func (c *Channel) SendMany(magic []byte, b [][]byte) (err error) {
-	if nonce, err := GetNonce(c.sendCiph); Check(err) {
-	} else {
-		for i := 0; i < len(b); i++ {
-			// DEBUG(i)
-			if _, err = c.Send(magic, nonce, b[i]); Check(err) {
-				// debug.PrintStack()
-			}
+	message := c.send.nextMessage()
+	for i := 0; i < len(b); i++ {
+		// DEBUG(i)
+		if _, err = c.Send(magic, message, b[i]); Check(err) {
+			// debug.PrintStack()
 		}
-		Trace(c.Creator, "sent packets", string(magic),
-			hex.EncodeToString(nonce), c.Sender.LocalAddr(),
-			c.Sender.RemoteAddr())
+	}
+	if c.Sender != nil {
+		Trace(c.Creator, "sent packets", string(magic), message,
+			c.Sender.LocalAddr(), c.Sender.RemoteAddr())
+	} else {
+		Trace(c.Creator, "sent packets", string(magic), message)
 	}
 	return
 }
 
-// Close the channel
+// setKey starts the sending session of a channel and prepares it to receive
+// the sessions of others, only channels with the same pre shared key can
+// exchange the keys of their sessions
+func (c *Channel) setKey(key string) (err error) {
+	psk := gcm.GetKey(key)
+	if c.send, err = newSendSession(psk); Check(err) {
+		return
+	}
+	if c.recv, err = newSessions(psk, c.send.id); Check(err) {
+		return
+	}
+	return c.receiveOwn()
+}
+
+// receiveOwn gives the receiving side of a channel the key of its own session,
+// so a broadcast channel can receive the packets it sends like any other
+func (c *Channel) receiveOwn() (err error) {
+	epoch, key := c.send.current()
+	return c.recv.addEpoch(string(c.send.id), epoch, key)
+}
+
+// sayHello sends a hello announcing the channel to the channels it sends to
+func (c *Channel) sayHello() {
+	hello, err := c.recv.hello()
+	if Check(err) {
+		return
+	}
+	if _, err = c.transmit(hello); Check(err) {
+	}
+}
+
+// keepAnnounced sends hellos until the channel is closed, so that new
+// channels send their keys to it, the keys of the channel itself are renewed
+// and the subscription of a unicast client stays open
+func (c *Channel) keepAnnounced(quit chan struct{}) {
+	ticker := time.NewTicker(HelloInterval)
+	defer ticker.Stop()
+	for {
+		c.sayHello()
+		select {
+		case <-ticker.C:
+		case <-quit:
+			return
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// handleHello answers an authentic hello with the key of the current epoch of
+// the channel, and with a hello of its own when the sender was not known yet
+// so that it sends its key back without waiting for its next hello. A hello
+// also subscribes a unicast client to a server channel.
+func (c *Channel) handleHello(src net.Addr, msg []byte) {
+	id, public, ts, err := c.recv.openHello(msg)
+	if err != nil {
+		Trace(c.Creator, "dropped hello from", src, err)
+		return
+	}
+	var known bool
+	if known, err = c.send.addReceiver(id, public, ts); err != nil {
+		Trace(c.Creator, "dropped hello from", src, err)
+		return
+	}
+	c.subscribe(src)
+	var key []byte
+	if key, err = c.send.keyPacket(id); Check(err) {
+		return
+	}
+	if _, err = c.transmit(key); Check(err) {
+	}
+	if !known {
+		c.sayHello()
+	}
+}
+
+// Close the channel. Broadcast channels are left open, the sockets and
+// streams of unicast channels are closed
 func (c *Channel) Close() (err error) {
 	// if err = c.Sender.Close(); Check(err) {
 	// }
 	// if err = c.Receiver.Close(); Check(err) {
 	// }
+	if c.done == nil {
+		return
+	}
+	c.doneOnce.Do(func() {
+		close(c.done)
+		if c.listener != nil {
+			if err = c.listener.Close(); Check(err) {
+			}
+		}
+		c.mx.Lock()
+		for conn := range c.streams {
+			if err = conn.Close(); Check(err) {
+			}
+			delete(c.streams, conn)
+		}
+		c.mx.Unlock()
+		if c.Receiver != nil {
+			if err = c.Receiver.Close(); Check(err) {
+			}
+		}
+	})
 	return
 }
 
@@ -114,21 +245,26 @@ func NewUnicastChannel(creator string, ctx interface{}, key, sender, receiver st
 		MaxDatagramSize: maxDatagramSize,
 		buffers:         make(map[string]*MsgBuffer),
 		context:         ctx,
+		Ready:           make(chan struct{}),
+		done:            make(chan struct{}),
 	}
 	var magics []string
 
 	for i := range handlers {
 		magics = append(magics, i)
 	}
-	if channel.sendCiph, err = gcm.GetCipher(key); Check(err) {
+	if err = channel.setKey(key); Check(err) {
+		return
 	}
-	if channel.receiveCiph, err = gcm.GetCipher(key); Check(err) {
+	if channel.Receiver, err = Listen(receiver, channel, maxDatagramSize, handlers, quit); Check(err) {
+		return
 	}
-	channel.Receiver, err = Listen(receiver, channel, maxDatagramSize, handlers, quit)
 	channel.Sender, err = NewSender(sender, maxDatagramSize)
 	if err != nil {
 		Error(err)
 	}
+	close(channel.Ready)
+	go channel.keepAnnounced(quit)
 	Warn("starting unicast channel:", channel.Creator, sender, receiver, magics)
 	return
 }
@@ -175,21 +311,17 @@ func NewBroadcastChannel(creator string, ctx interface{}, key string, port int,
 	quit chan struct{}) (channel *Channel, err error) {
 	channel = &Channel{Creator: creator, MaxDatagramSize: maxDatagramSize,
 		buffers: make(map[string]*MsgBuffer), context: ctx, Ready: make(chan struct{})}
-	if channel.sendCiph, err = gcm.GetCipher(key); Check(err) {
-	}
-	if channel.sendCiph == nil {
-		panic("nil send cipher")
-	}
-	if channel.receiveCiph, err = gcm.GetCipher(key); Check(err) {
-	}
-	if channel.receiveCiph == nil {
-		panic("nil receive cipher")
+	if err = channel.setKey(key); Check(err) {
+		return
 	}
 	if channel.Receiver, err = ListenBroadcast(port, channel, maxDatagramSize, handlers, quit); Check(err) {
+		return
 	}
 	if channel.Sender, err = NewBroadcaster(port, maxDatagramSize); Check(err) {
+		return
 	}
 	close(channel.Ready)
+	go channel.keepAnnounced(quit)
 	return
 }
 
@@ -248,17 +380,17 @@ func Handle(address string, channel *Channel,
 	buffer := make([]byte, maxDatagramSize)
 	Debug("starting handler for", channel.Creator, "listener")
 	// Loop forever reading from the socket until it is closed
-	// seenNonce := ""
 	var err error
 	var numBytes int
 	var src net.Addr
-	// var seenNonce string
 	<-channel.Ready
 out:
 	for {
 		select {
 		case <-quit:
 			break out
+		case <-channel.done:
+			break out
 		default:
 		}
 		if numBytes, src, err = channel.Receiver.ReadFromUDP(buffer); Check(err) {
@@ -270,74 +402,80 @@ out:
 			case success:
 			}
 		}
-		// Filter messages by magic, if there is no match in the map the packet is ignored
-		magic := string(buffer[:4])
-		if handler, ok := handlers[magic]; ok {
-			// if caller needs to know the liveness status of the
-			// controller it is working on, the code below
-			if channel.lastSent != nil && channel.firstSender != nil {
-				*channel.lastSent = time.Now()
-			}
-			msg := buffer[:numBytes]
-			nL := channel.receiveCiph.NonceSize()
-			nonceBytes := msg[4 : 4+nL]
-			nonce := string(nonceBytes)
-			// if nonce == seenNonce {
-			// 	DEBUG("seen this one")
-			// 	continue
-			// }
-			// seenNonce = nonce
-			// decipher
-			var shard []byte
-			if shard, err = channel.receiveCiph.Open(nil, nonceBytes, msg[4+len(nonceBytes):], nil); err != nil {
-				continue
-			}
-			// DEBUG("read", numBytes, "from", src, err, hex.EncodeToString(msg))
-			if bn, ok := channel.buffers[nonce]; ok {
-				if !bn.Decoded {
-					bn.Buffers = append(bn.Buffers, shard)
-					if len(bn.Buffers) >= 3 {
-						// DEBUG(len(bn.Buffers))
-						// try to decode it
-						var cipherText []byte
-						cipherText, err = fec.Decode(bn.Buffers)
-						if err != nil {
-							Error(err)
-							continue
-						}
-						bn.Decoded = true
-						// DEBUG(numBytes, src, err)
-						if err = handler(channel.context, src, address, cipherText); Check(err) {
-							continue
-						}
-						// src = nil
-						// buffer = buffer[:0]
-					}
+		channel.handlePacket(address, handlers, src, buffer[:numBytes])
+	}
+}
+
+// handlePacket deciphers a packet and adds the shard it contains to the buffer
+// for its message, when enough shards have arrived the message is recovered
+// and passed to the handler matching its magic. Packets with a magic that no
+// handler matches are ignored.
+func (c *Channel) handlePacket(address string, handlers Handlers,
+	src net.Addr, msg []byte) {
+	if len(msg) < 4 {
+		return
+	}
+	magic := string(msg[:4])
+	switch magic {
+	case string(HelloMagic):
+		c.handleHello(src, msg)
+		return
+	case string(KeyMagic):
+		if err := c.recv.installKey(msg); err != nil {
+			Trace(c.Creator, "dropped key packet from", src, err)
+		}
+		return
+	}
+	handler, ok := handlers[magic]
+	if !ok {
+		return
+	}
+	// if caller needs to know the liveness status of the
+	// controller it is working on, the code below
+	if c.lastSent != nil && c.firstSender != nil {
+		*c.lastSent = time.Now()
+	}
+	// authenticate and decipher
+	var err error
+	var message string
+	var shard []byte
+	if message, shard, err = c.recv.open(msg); err != nil {
+		Trace(c.Creator, "dropped packet from", src, err)
+		return
+	}
+	var cipherText []byte
+	var decoded bool
+	c.bufMx.Lock()
+	if bn, ok := c.buffers[message]; ok {
+		if !bn.Decoded {
+			bn.Buffers = append(bn.Buffers, shard)
+			if len(bn.Buffers) >= 3 {
+				// try to decode it
+				if cipherText, err = fec.Decode(bn.Buffers); err != nil {
+					Error(err)
 				} else {
-					// if nonce == seenNonce {
-					// 	continue
-					// }
-					// seenNonce = nonce
-					for i := range channel.buffers {
-						if i != nonce || (channel.buffers[i].Decoded &&
-							len(channel.buffers[i].Buffers) > 8) {
-							// superseded messages can be deleted from the
-							// buffers, we don't add more data for the already
-							// decoded.
-							delete(channel.buffers, i)
-						}
-					}
+					bn.Decoded = true
+					decoded = true
 				}
-			} else {
-				channel.buffers[nonce] = &MsgBuffer{[][]byte{},
-					time.Now(), false, src}
-				channel.buffers[nonce].Buffers = append(channel.buffers[nonce].
-					Buffers, shard)
 			}
+		} else {
+			for i := range c.buffers {
+				if i != message || (c.buffers[i].Decoded &&
+					len(c.buffers[i].Buffers) > 8) {
+					// superseded messages can be deleted from the
+					// buffers, we don't add more data for the already
+					// decoded.
+					delete(c.buffers, i)
+				}
+			}
+		}
+	} else {
+		c.buffers[message] = &MsgBuffer{[][]byte{shard}, time.Now(), false, src}
+	}
+	c.bufMx.Unlock()
+	if decoded {
+		if err = handler(c.context, src, address, cipherText); Check(err) {
 		}
-		// for i := range buffer {
-		// 	buffer[i] = 0
-		// }
 	}
 }
 
@@ -1,44 +1,651 @@
 package transport
 
 import (
+	"bytes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
-	"encoding/hex"
+	"crypto/sha256"
+	"encoding/binary"
 	"errors"
-	"fmt"
 	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/p9c/pod/pkg/gcm"
 )
 
-func DecryptMessage(creator string, ciph cipher.AEAD, data []byte) (msg []byte, err error) {
-	nonceSize := ciph.NonceSize()
-	msg, err = ciph.Open(nil, data[:nonceSize], data[nonceSize:], nil)
-	if err != nil {
-		err = errors.New(fmt.Sprintf("%s %s", creator, err.Error()))
-	} else {
-		Debug("decrypted message", hex.EncodeToString(data[:nonceSize]))
+// Every packet is sent in a session, which is identified by a random id chosen
+// by the sender when the channel is created. Each epoch of a session is
+// encrypted with a random key, and a new epoch is started well before the GCM
+// limit of 4gb per key is reached. The packet counter is the GCM nonce and
+// together with the timestamp lets receivers reject replayed packets.
+//
+// The keys of the epochs are never derived from the pre shared key, so that
+// knowing it later does not reveal the traffic of the past. Instead each
+// channel announces an ephemeral x25519 key in a hello, authenticated with the
+// pre shared key, and the senders that hear it send the key of their current
+// epoch to it in a key packet. The key packet is encrypted with a key derived
+// from the pre shared key and the x25519 exchange between the ephemeral key of
+// the hello and a new ephemeral key of the sender, following the NNpsk0
+// handshake of the Noise protocol framework, so only channels that know the
+// pre shared key can take part, and the epoch keys can't be recovered once the
+// ephemeral keys are discarded. Channels replace the ephemeral key of their
+// hellos as often as a session changes its epoch.
+//
+// A packet is laid out as follows, everything before the ciphertext is
+// authenticated as additional data:
+//
+//   magic      4 bytes
+//   session    8 bytes
+//   epoch      4 bytes, big endian
+//   counter    8 bytes, big endian
+//   message    8 bytes, big endian, the same for all shards of a message
+//   timestamp  8 bytes, big endian unix nanoseconds
+//   ciphertext
+//
+// A hello is laid out as follows, the mac is the HMAC-SHA256 of everything
+// before it with a key derived from the pre shared key:
+//
+//   magic      4 bytes
+//   session    8 bytes
+//   timestamp  8 bytes, big endian unix nanoseconds
+//   ephemeral  32 bytes, the x25519 public key key packets are sent to
+//   mac        32 bytes
+//
+// A key packet is laid out as follows, everything before the ciphertext is
+// authenticated as additional data:
+//
+//   magic      4 bytes
+//   receiver   8 bytes, the session of the hello that is answered
+//   session    8 bytes
+//   epoch      4 bytes, big endian
+//   timestamp  8 bytes, big endian unix nanoseconds
+//   ephemeral  32 bytes, the x25519 public key of the sender
+//   ciphertext 48 bytes, the key of the epoch
+
+const (
+	// MaxClockSkew is the furthest the timestamp of a packet can be from the
+	// local clock for it to be accepted
+	MaxClockSkew = time.Second * 30
+	// RekeyAfterBytes is the amount of data encrypted under one key before a
+	// session moves to its next epoch
+	RekeyAfterBytes = 1 << 31
+	// RekeyAfterTime is the longest a session key or the ephemeral key of a
+	// hello is used for
+	RekeyAfterTime = time.Hour
+	// HelloInterval is how often a channel sends a hello, which also renews
+	// the subscription of a unicast client to its server
+	HelloInterval = time.Second * 3
+	sessionIDSize = 8
+	magicSize     = 4
+	keySize       = 32
+	headerSize    = magicSize + sessionIDSize + 4 + 8 + 8 + 8
+	helloSize     = magicSize + sessionIDSize + 8 + keySize + sha256.Size
+	keyHeaderSize = magicSize + sessionIDSize*2 + 4 + 8 + keySize
+	// sessionTimeout is how long a receiver remembers a session it has not
+	// heard from. It must be longer than the clock skew allowance so expired
+	// sessions can't be replayed
+	sessionTimeout = MaxClockSkew * 4
+	// replayWindowSize is the number of packets behind the newest one that
+	// can still be accepted when they arrive out of order
+	replayWindowSize = 1024
+)
+
+var (
+	// HelloMagic is the magic of the hellos a channel announces the key it
+	// receives the keys of other sessions with in. They are handled by the
+	// channel itself and never passed to handlers.
+	HelloMagic = []byte{'h', 'e', 'l', 'o'}
+	// KeyMagic is the magic of the packets that send the key of an epoch of a
+	// session to one receiver. They are handled by the channel itself and
+	// never passed to handlers.
+	KeyMagic          = []byte{'s', 'k', 'e', 'y'}
+	errShortPacket    = errors.New("packet too short")
+	errClockSkew      = errors.New("packet timestamp outside allowed clock skew")
+	errReplayed       = errors.New("packet replayed or too old")
+	errStaleEpoch     = errors.New("packet from a superseded session epoch")
+	errUnknownSession = errors.New("packet from a session epoch without a key")
+	errOwnPacket      = errors.New("packet from this channel")
+	errNotAddressed   = errors.New("key packet for another receiver")
+	errBadHello       = errors.New("hello failed authentication")
+	helloKeyInfo      = []byte("p9c transport hello")
+	protocolName      = []byte("Noise_NNpsk0_25519_AESGCM_SHA256 p9c transport")
+)
+
+// ephemeral is an x25519 key pair that is discarded after use
+type ephemeral struct {
+	private, public []byte
+	created         time.Time
+}
+
+func newEphemeral() (e *ephemeral, err error) {
+	e = &ephemeral{private: make([]byte, keySize), created: time.Now()}
+	if _, err = io.ReadFull(rand.Reader, e.private); Check(err) {
+		return
+	}
+	e.public, err = curve25519.X25519(e.private, curve25519.Basepoint)
+	return
+}
+
+// helloKey derives the key hellos are authenticated with from the pre shared
+// key
+func helloKey(psk []byte) (key []byte, err error) {
+	key = make([]byte, sha256.Size)
+	_, err = io.ReadFull(hkdf.New(sha256.New, psk, nil, helloKeyInfo), key)
+	return
+}
+
+// wrapKey derives the cipher a key packet is encrypted with from the pre
+// shared key and the result of the x25519 exchange, bound to the header of the
+// key packet and the ephemeral key of the hello it answers
+func wrapKey(psk, shared, header, receiver []byte) (aead cipher.AEAD,
+	err error) {
+	h := sha256.New()
+	h.Write(protocolName)
+	h.Write(header)
+	h.Write(receiver)
+	key := make([]byte, keySize)
+	if _, err = io.ReadFull(hkdf.New(sha256.New, shared, psk, h.Sum(nil)),
+		key); Check(err) {
+		return
+	}
+	return gcm.NewCipher(key)
+}
+
+// checkSkew returns an error if a timestamp is too far from the local clock
+func checkSkew(now, ts time.Time) (err error) {
+	if skew := now.Sub(ts); skew > MaxClockSkew || skew < -MaxClockSkew {
+		err = errClockSkew
+	}
+	return
+}
+
+// receiver is a channel that has sent a hello, which the keys of the epochs
+// of a session are sent to
+type receiver struct {
+	public   []byte
+	hello    time.Time
+	lastSeen time.Time
+}
+
+// sendSession is the sending side of a session
+type sendSession struct {
+	mx        sync.Mutex
+	psk       []byte
+	id        []byte
+	epoch     uint32
+	counter   uint64
+	message   uint64
+	bytes     uint64
+	started   time.Time
+	key       []byte
+	aead      cipher.AEAD
+	receivers map[string]*receiver
+}
+
+func newSendSession(psk []byte) (s *sendSession, err error) {
+	s = &sendSession{psk: psk, id: make([]byte, sessionIDSize),
+		receivers: make(map[string]*receiver)}
+	if _, err = io.ReadFull(rand.Reader, s.id); Check(err) {
+		return
+	}
+	err = s.rekey()
+	return
+}
+
+// rekey moves the session to its next epoch with a new random key
+func (s *sendSession) rekey() (err error) {
+	key := make([]byte, keySize)
+	if _, err = io.ReadFull(rand.Reader, key); Check(err) {
+		return
+	}
+	var aead cipher.AEAD
+	if aead, err = gcm.NewCipher(key); Check(err) {
+		return
+	}
+	if s.aead != nil {
+		s.epoch++
+		Debug("rotating transport session key to epoch", s.epoch)
+	}
+	s.key, s.aead = key, aead
+	s.counter = 0
+	s.bytes = 0
+	s.started = time.Now()
+	return
+}
+
+// nextMessage returns the id for a new message
+func (s *sendSession) nextMessage() (message uint64) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.message++
+	return s.message
+}
+
+// seal encrypts data into a packet. If the session moved to a new epoch for
+// it, rekeyed is set and the key packets of the epoch must be sent before the
+// packet.
+func (s *sendSession) seal(magic []byte, message uint64, data []byte) (
+	packet []byte, rekeyed bool, err error) {
+	if len(magic) != magicSize {
+		err = errors.New("magic must be 4 bytes long")
+		return
+	}
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if s.bytes+uint64(len(data)) > RekeyAfterBytes ||
+		time.Since(s.started) > RekeyAfterTime {
+		if err = s.rekey(); Check(err) {
+			return
+		}
+		rekeyed = true
+	}
+	s.counter++
+	s.bytes += uint64(len(data))
+	packet = make([]byte, headerSize, headerSize+len(data)+s.aead.Overhead())
+	copy(packet, magic)
+	h := packet[magicSize:]
+	copy(h, s.id)
+	h = h[sessionIDSize:]
+	binary.BigEndian.PutUint32(h, s.epoch)
+	binary.BigEndian.PutUint64(h[4:], s.counter)
+	binary.BigEndian.PutUint64(h[12:], message)
+	binary.BigEndian.PutUint64(h[20:], uint64(time.Now().UnixNano()))
+	packet = s.aead.Seal(packet, nonce(s.epoch, s.counter), data,
+		packet[:headerSize])
+	return
+}
+
+func nonce(epoch uint32, counter uint64) (n []byte) {
+	n = make([]byte, 12)
+	binary.BigEndian.PutUint32(n, epoch)
+	binary.BigEndian.PutUint64(n[4:], counter)
+	return
+}
+
+// addReceiver records the ephemeral key of an authentic hello, returning
+// whether its session was already known. A hello that is not newer than the
+// last one of its session is a replay and is rejected.
+func (s *sendSession) addReceiver(id string, public []byte, hello time.Time) (
+	known bool, err error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	now := time.Now()
+	r, known := s.receivers[id]
+	if !known {
+		r = &receiver{}
+		s.receivers[id] = r
+	} else if !hello.After(r.hello) {
+		err = errReplayed
+		return
+	}
+	r.public, r.hello, r.lastSeen = public, hello, now
+	return
+}
+
+// current returns the number and key of the current epoch
+func (s *sendSession) current() (epoch uint32, key []byte) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.epoch, s.key
+}
+
+// keyPacket returns a key packet sending the key of the current epoch to a
+// receiver
+func (s *sendSession) keyPacket(to string) (packet []byte, err error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	r, ok := s.receivers[to]
+	if !ok {
+		err = errors.New("no hello from receiver")
+		return
+	}
+	return s.wrap(to, r.public)
+}
+
+// keyPackets returns key packets sending the key of the current epoch to all
+// the receivers that have sent a hello recently, forgetting the others
+func (s *sendSession) keyPackets() (packets [][]byte) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	now := time.Now()
+	for id, r := range s.receivers {
+		if now.Sub(r.lastSeen) > sessionTimeout {
+			delete(s.receivers, id)
+			continue
+		}
+		if packet, err := s.wrap(id, r.public); !Check(err) {
+			packets = append(packets, packet)
+		}
+	}
+	return
+}
+
+// wrap encrypts the key of the current epoch to the ephemeral key of a
+// receiver, with a new ephemeral key for each packet so no wrapping key is
+// ever used twice
+func (s *sendSession) wrap(to string, public []byte) (packet []byte,
+	err error) {
+	var e *ephemeral
+	if e, err = newEphemeral(); Check(err) {
+		return
+	}
+	var shared []byte
+	if shared, err = curve25519.X25519(e.private, public); Check(err) {
+		return
+	}
+	packet = make([]byte, keyHeaderSize, keyHeaderSize+keySize+16)
+	copy(packet, KeyMagic)
+	h := packet[magicSize:]
+	copy(h, to)
+	copy(h[sessionIDSize:], s.id)
+	h = h[sessionIDSize*2:]
+	binary.BigEndian.PutUint32(h, s.epoch)
+	binary.BigEndian.PutUint64(h[4:], uint64(time.Now().UnixNano()))
+	copy(h[12:], e.public)
+	var aead cipher.AEAD
+	if aead, err = wrapKey(s.psk, shared, packet, public); Check(err) {
+		return
+	}
+	packet = aead.Seal(packet, make([]byte, aead.NonceSize()), s.key, packet)
+	return
+}
+
+// replayWindow tracks the packet counters seen in a session epoch
+type replayWindow struct {
+	highest uint64
+	bits    [replayWindowSize / 64]uint64
+}
+
+// check returns whether a counter has not been seen and is recent enough
+func (w *replayWindow) check(counter uint64) bool {
+	if counter == 0 {
+		return false
+	}
+	if counter > w.highest {
+		return true
+	}
+	if w.highest-counter >= replayWindowSize {
+		return false
+	}
+	i := counter % replayWindowSize
+	return w.bits[i/64]&(1<<(i%64)) == 0
+}
+
+// accept marks a counter as seen, it must only be called after check and
+// authentication of the packet
+func (w *replayWindow) accept(counter uint64) {
+	if counter > w.highest {
+		// clear the slots skipped over, which now belong to the new counters
+		if counter-w.highest >= replayWindowSize {
+			w.bits = [replayWindowSize / 64]uint64{}
+		} else {
+			for c := w.highest + 1; c < counter; c++ {
+				i := c % replayWindowSize
+				w.bits[i/64] &^= 1 << (i % 64)
+			}
+		}
+		w.highest = counter
+	}
+	i := counter % replayWindowSize
+	w.bits[i/64] |= 1 << (i % 64)
+}
+
+type recvEpoch struct {
+	epoch  uint32
+	aead   cipher.AEAD
+	window replayWindow
+}
+
+// recvSession is the receiving side of a session of another sender, it keeps
+// the previous epoch so packets in flight during a rekey are not lost
+type recvSession struct {
+	current, previous *recvEpoch
+	lastSeen          time.Time
+}
+
+// sessions are the sessions of all the senders a channel receives from, and
+// the ephemeral keys their epoch keys are sent to
+type sessions struct {
+	mx        sync.Mutex
+	psk       []byte
+	helloKey  []byte
+	id        string
+	dh        *ephemeral
+	prevDH    *ephemeral
+	m         map[string]*recvSession
+	lastPurge time.Time
+}
+
+// newSessions returns the receiving side of a channel, id is the session of
+// the channel itself, which the keys of other sessions are addressed to
+func newSessions(psk, id []byte) (s *sessions, err error) {
+	s = &sessions{psk: psk, id: string(id), m: make(map[string]*recvSession),
+		lastPurge: time.Now()}
+	if s.helloKey, err = helloKey(psk); Check(err) {
+		return
+	}
+	s.dh, err = newEphemeral()
+	return
+}
+
+// purge forgets the sessions that have not been heard from for a while, it
+// must be called with the mutex locked
+func (s *sessions) purge(now time.Time) {
+	if now.Sub(s.lastPurge) <= sessionTimeout {
+		return
+	}
+	for i := range s.m {
+		// the session of the channel itself is only replaced by a rekey
+		if i != s.id && now.Sub(s.m[i].lastSeen) > sessionTimeout {
+			delete(s.m, i)
+		}
+	}
+	s.lastPurge = now
+}
+
+// hello returns a hello announcing the ephemeral key of the channel, which is
+// replaced by a new one once it is older than RekeyAfterTime. The previous key
+// is kept so key packets answering hellos in flight are not lost.
+func (s *sessions) hello() (packet []byte, err error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if time.Since(s.dh.created) > RekeyAfterTime {
+		var e *ephemeral
+		if e, err = newEphemeral(); Check(err) {
+			return
+		}
+		s.prevDH, s.dh = s.dh, e
+	}
+	packet = make([]byte, helloSize-sha256.Size, helloSize)
+	copy(packet, HelloMagic)
+	h := packet[magicSize:]
+	copy(h, s.id)
+	binary.BigEndian.PutUint64(h[sessionIDSize:],
+		uint64(time.Now().UnixNano()))
+	copy(h[sessionIDSize+8:], s.dh.public)
+	mac := hmac.New(sha256.New, s.helloKey)
+	mac.Write(packet)
+	packet = mac.Sum(packet)
+	return
+}
+
+// openHello authenticates a hello, returning the session and ephemeral key it
+// announces and its timestamp
+func (s *sessions) openHello(packet []byte) (id string, public []byte,
+	ts time.Time, err error) {
+	if len(packet) < helloSize {
+		err = errShortPacket
+		return
+	}
+	mac := hmac.New(sha256.New, s.helloKey)
+	mac.Write(packet[:helloSize-sha256.Size])
+	if !hmac.Equal(mac.Sum(nil), packet[helloSize-sha256.Size:helloSize]) {
+		err = errBadHello
+		return
+	}
+	h := packet[magicSize:]
+	if id = string(h[:sessionIDSize]); id == s.id {
+		err = errOwnPacket
+		return
 	}
+	ts = time.Unix(0, int64(binary.BigEndian.Uint64(h[sessionIDSize:])))
+	if err = checkSkew(time.Now(), ts); err != nil {
+		return
+	}
+	public = append([]byte(nil), h[sessionIDSize+8:sessionIDSize+8+keySize]...)
 	return
 }
 
-// EncryptMessage encrypts a message, if the nonce is given it uses that otherwise it generates a new one.
-// If there is no cipher this just returns a message with the given magic prepended.
-func EncryptMessage(creator string, ciph cipher.AEAD, magic []byte, nonce, data []byte) (msg []byte, err error) {
-	if ciph != nil {
-		if nonce == nil {
-			nonce, err = GetNonce(ciph)
+// installKey opens a key packet addressed to the channel and starts receiving
+// the epoch of the session it carries the key of. Key packets for epochs that
+// are already known are ignored, so a replayed key packet can't reset the
+// replay window of an epoch.
+func (s *sessions) installKey(packet []byte) (err error) {
+	if len(packet) < keyHeaderSize+keySize {
+		err = errShortPacket
+		return
+	}
+	h := packet[magicSize:keyHeaderSize]
+	if string(h[:sessionIDSize]) != s.id {
+		err = errNotAddressed
+		return
+	}
+	id := string(h[sessionIDSize : sessionIDSize*2])
+	if id == s.id {
+		err = errOwnPacket
+		return
+	}
+	h = h[sessionIDSize*2:]
+	epoch := binary.BigEndian.Uint32(h)
+	ts := time.Unix(0, int64(binary.BigEndian.Uint64(h[4:])))
+	if err = checkSkew(time.Now(), ts); err != nil {
+		return
+	}
+	s.mx.Lock()
+	dhs := []*ephemeral{s.dh, s.prevDH}
+	s.mx.Unlock()
+	var key []byte
+	for _, dh := range dhs {
+		if dh == nil {
+			continue
+		}
+		if key, err = unwrap(s.psk, dh, packet); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return
+	}
+	return s.addEpoch(id, epoch, key)
+}
+
+// addEpoch starts receiving an epoch of a session with its key. Epochs that
+// are already known are left as they are.
+func (s *sessions) addEpoch(id string, epoch uint32, key []byte) (err error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	now := time.Now()
+	s.purge(now)
+	rs := s.m[id]
+	if rs != nil {
+		switch {
+		case epoch < rs.current.epoch &&
+			(rs.previous == nil || epoch != rs.previous.epoch):
+			return errStaleEpoch
+		case epoch <= rs.current.epoch:
+			rs.lastSeen = now
+			return
 		}
-		msg = append(append(magic, nonce...), ciph.Seal(nil, nonce, data, nil)...)
+	}
+	e := &recvEpoch{epoch: epoch}
+	if e.aead, err = gcm.NewCipher(key); Check(err) {
+		return
+	}
+	if rs == nil {
+		rs = &recvSession{current: e}
+		s.m[id] = rs
 	} else {
-		msg = append(magic, data...)
+		rs.previous, rs.current = rs.current, e
 	}
+	rs.lastSeen = now
+	return
+}
 
+// unwrap decrypts the epoch key in a key packet sent to an ephemeral key
+func unwrap(psk []byte, dh *ephemeral, packet []byte) (key []byte, err error) {
+	public := packet[keyHeaderSize-keySize : keyHeaderSize]
+	var shared []byte
+	if shared, err = curve25519.X25519(dh.private, public); err != nil {
+		return
+	}
+	var aead cipher.AEAD
+	if aead, err = wrapKey(psk, shared, packet[:keyHeaderSize],
+		dh.public); err != nil {
+		return
+	}
+	if key, err = aead.Open(nil, make([]byte, aead.NonceSize()),
+		packet[keyHeaderSize:], packet[:keyHeaderSize]); err != nil {
+		return
+	}
+	if len(key) != keySize || bytes.Equal(key, make([]byte, keySize)) {
+		err = errors.New("invalid session key")
+	}
 	return
 }
 
-func GetNonce(ciph cipher.AEAD) (nonce []byte, err error) {
-	// get a nonce for the packet, it is both message ID and salt
-	nonce = make([]byte, ciph.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); Check(err) {
+// open authenticates and decrypts a packet, returning the data and an id for
+// the message it is part of which is unique among all senders
+func (s *sessions) open(packet []byte) (message string, data []byte,
+	err error) {
+	if len(packet) < headerSize {
+		err = errShortPacket
+		return
+	}
+	h := packet[magicSize:headerSize]
+	id := string(h[:sessionIDSize])
+	h = h[sessionIDSize:]
+	epoch := binary.BigEndian.Uint32(h)
+	counter := binary.BigEndian.Uint64(h[4:])
+	ts := time.Unix(0, int64(binary.BigEndian.Uint64(h[20:])))
+	now := time.Now()
+	if err = checkSkew(now, ts); err != nil {
+		return
+	}
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.purge(now)
+	rs := s.m[id]
+	var e *recvEpoch
+	switch {
+	case rs == nil:
+		err = errUnknownSession
+		return
+	case rs.current.epoch == epoch:
+		e = rs.current
+	case rs.previous != nil && rs.previous.epoch == epoch:
+		e = rs.previous
+	case epoch < rs.current.epoch:
+		err = errStaleEpoch
+		return
+	default:
+		err = errUnknownSession
+		return
+	}
+	if !e.window.check(counter) {
+		err = errReplayed
+		return
+	}
+	if data, err = e.aead.Open(nil, nonce(epoch, counter),
+		packet[headerSize:], packet[:headerSize]); err != nil {
+		return
 	}
+	e.window.accept(counter)
+	rs.lastSeen = now
+	message = id + string(h[12:20])
 	return
 }
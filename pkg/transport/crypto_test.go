@@ -0,0 +1,358 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+var testMagic = []byte{'t', 'e', 's', 't'}
+
+// endpoint is the sending and receiving side of the sessions of a channel
+type endpoint struct {
+	send *sendSession
+	recv *sessions
+}
+
+func newEndpoint(t *testing.T, psk string) (e *endpoint) {
+	var err error
+	e = &endpoint{}
+	if e.send, err = newSendSession([]byte(psk)); err != nil {
+		t.Fatal(err)
+	}
+	if e.recv, err = newSessions([]byte(psk), e.send.id); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+// handshake sends the key of the current epoch of from to to, the way a
+// channel answers a hello
+func handshake(t *testing.T, from, to *endpoint) {
+	hello, err := to.recv.hello()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, public, ts, err := from.recv.openHello(hello)
+	if err != nil {
+		t.Fatalf("openHello: %v", err)
+	}
+	if _, err = from.send.addReceiver(id, public, ts); err != nil {
+		t.Fatalf("addReceiver: %v", err)
+	}
+	key, err := from.send.keyPacket(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = to.recv.installKey(key); err != nil {
+		t.Fatalf("installKey: %v", err)
+	}
+}
+
+func seal(t *testing.T, s *sendSession, data string) (packet []byte,
+	rekeyed bool) {
+	var err error
+	if packet, rekeyed, err = s.seal(testMagic, s.nextMessage(),
+		[]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+// TestReplayWindow ensures counters are accepted once and only while they are
+// within the window behind the highest one seen.
+func TestReplayWindow(t *testing.T) {
+	type step struct {
+		counter uint64
+		want    bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name:  "counter zero",
+			steps: []step{{0, false}, {1, true}, {0, false}},
+		},
+		{
+			name:  "in order",
+			steps: []step{{1, true}, {2, true}, {3, true}, {2, false}, {3, false}},
+		},
+		{
+			name: "out of order",
+			steps: []step{{5, true}, {3, true}, {4, true}, {3, false},
+				{6, true}, {1, true}, {2, true}, {5, false}, {1, false}},
+		},
+		{
+			name: "window edge",
+			steps: []step{{2000, true}, {2000 - replayWindowSize + 1, true},
+				{2000 - replayWindowSize, false}, {1, false}},
+		},
+		{
+			name: "wrap onto the same slot",
+			steps: []step{{1, true}, {1 + replayWindowSize, true}, {1, false},
+				{1 + replayWindowSize, false}},
+		},
+		{
+			name: "slots skipped over are cleared",
+			steps: []step{{1, true}, {2, true}, {3, true},
+				{3 + replayWindowSize, true}, {1 + replayWindowSize, true},
+				{2 + replayWindowSize, true}, {2 + replayWindowSize, false},
+				{3, false}},
+		},
+		{
+			name: "jump past the window",
+			steps: []step{{10, true}, {11, true}, {10 + replayWindowSize*5, true},
+				{11 + replayWindowSize*4, true}, {10, false}, {11, false},
+				{11 + replayWindowSize*4, false}},
+		},
+	}
+	for _, test := range tests {
+		var w replayWindow
+		for i, s := range test.steps {
+			got := w.check(s.counter)
+			if got != s.want {
+				t.Errorf("%s #%d: check(%d) got %v, want %v", test.name, i,
+					s.counter, got, s.want)
+			}
+			if got {
+				w.accept(s.counter)
+			}
+		}
+	}
+}
+
+// TestRekey ensures a session moves to a new epoch with a new key once it has
+// encrypted RekeyAfterBytes or is older than RekeyAfterTime.
+func TestRekey(t *testing.T) {
+	a, b := newEndpoint(t, "psk"), newEndpoint(t, "psk")
+	handshake(t, a, b)
+	if _, rekeyed := seal(t, a.send, "first"); rekeyed {
+		t.Fatalf("new session rekeyed")
+	}
+	tests := []struct {
+		name  string
+		setup func(s *sendSession)
+	}{
+		{
+			name: "bytes",
+			setup: func(s *sendSession) {
+				s.bytes = RekeyAfterBytes - 1
+			},
+		},
+		{
+			name: "time",
+			setup: func(s *sendSession) {
+				s.started = time.Now().Add(-RekeyAfterTime - time.Second)
+			},
+		},
+	}
+	for i, test := range tests {
+		prevKey := a.send.key
+		test.setup(a.send)
+		packet, rekeyed := seal(t, a.send, "data")
+		if !rekeyed {
+			t.Errorf("%s: session not rekeyed", test.name)
+			continue
+		}
+		want := uint32(i + 1)
+		if a.send.epoch != want {
+			t.Errorf("%s: got epoch %d, want %d", test.name, a.send.epoch, want)
+		}
+		if got := binary.BigEndian.Uint32(packet[magicSize+sessionIDSize:]); got != want {
+			t.Errorf("%s: got packet epoch %d, want %d", test.name, got, want)
+		}
+		if a.send.counter != 1 || a.send.bytes != 4 {
+			t.Errorf("%s: got counter %d and bytes %d, want 1 and 4",
+				test.name, a.send.counter, a.send.bytes)
+		}
+		if bytes.Equal(prevKey, a.send.key) {
+			t.Errorf("%s: key of the new epoch is the same", test.name)
+		}
+		if _, _, err := b.recv.open(packet); err != errUnknownSession {
+			t.Errorf("%s: got %v before the key packet, want %v", test.name,
+				err, errUnknownSession)
+		}
+		for _, key := range a.send.keyPackets() {
+			if err := b.recv.installKey(key); err != nil {
+				t.Fatalf("%s: installKey: %v", test.name, err)
+			}
+		}
+		if _, data, err := b.recv.open(packet); err != nil ||
+			string(data) != "data" {
+			t.Errorf("%s: got %q, %v, want \"data\"", test.name, data, err)
+		}
+		if _, rekeyed = seal(t, a.send, "next"); rekeyed {
+			t.Errorf("%s: rekeyed again", test.name)
+		}
+	}
+}
+
+// TestHandshake ensures the keys of sessions are only exchanged between
+// channels with the same pre shared key and only by the receiver addressed.
+func TestHandshake(t *testing.T) {
+	a, b := newEndpoint(t, "psk"), newEndpoint(t, "psk")
+	handshake(t, a, b)
+	packet, _ := seal(t, a.send, "hello")
+	if _, data, err := b.recv.open(packet); err != nil ||
+		string(data) != "hello" {
+		t.Fatalf("got %q, %v, want \"hello\"", data, err)
+	}
+	// a channel ignores its own hellos
+	hello, err := a.recv.hello()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err = a.recv.openHello(hello); err != errOwnPacket {
+		t.Errorf("own hello: got %v, want %v", err, errOwnPacket)
+	}
+	// a hello with another pre shared key fails
+	c := newEndpoint(t, "other")
+	if hello, err = c.recv.hello(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err = a.recv.openHello(hello); err != errBadHello {
+		t.Errorf("other psk hello: got %v, want %v", err, errBadHello)
+	}
+	hello[len(hello)-1] ^= 1
+	if _, _, _, err = c.recv.openHello(hello); err != errBadHello {
+		t.Errorf("tampered hello: got %v, want %v", err, errBadHello)
+	}
+	// a replayed hello is rejected
+	if hello, err = b.recv.hello(); err != nil {
+		t.Fatal(err)
+	}
+	id, public, ts, err := a.recv.openHello(hello)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = a.send.addReceiver(id, public, ts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = a.send.addReceiver(id, public, ts); err != errReplayed {
+		t.Errorf("replayed hello: got %v, want %v", err, errReplayed)
+	}
+	// a key packet only opens for the receiver it is addressed to
+	d := newEndpoint(t, "psk")
+	key, err := a.send.keyPacket(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = d.recv.installKey(key); err != errNotAddressed {
+		t.Errorf("key packet for b given to d: got %v, want %v", err,
+			errNotAddressed)
+	}
+	copy(key[magicSize:], d.send.id)
+	if err = d.recv.installKey(key); err == nil {
+		t.Errorf("readdressed key packet installed")
+	}
+	// a key packet with another pre shared key fails
+	e := newEndpoint(t, "other")
+	e.recv.id = b.recv.id
+	e.recv.dh = b.recv.dh
+	if key, err = a.send.keyPacket(id); err != nil {
+		t.Fatal(err)
+	}
+	if err = e.recv.installKey(key); err == nil {
+		t.Errorf("key packet opened with another psk")
+	}
+	// once the ephemeral key a key packet was sent to is discarded it can't
+	// be opened any more
+	f := newEndpoint(t, "psk")
+	if hello, err = f.recv.hello(); err != nil {
+		t.Fatal(err)
+	}
+	if id, public, ts, err = a.recv.openHello(hello); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = a.send.addReceiver(id, public, ts); err != nil {
+		t.Fatal(err)
+	}
+	if key, err = a.send.keyPacket(id); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		f.recv.dh.created = time.Now().Add(-RekeyAfterTime - time.Second)
+		if _, err = f.recv.hello(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = f.recv.installKey(key); err == nil {
+		t.Errorf("key packet opened after its ephemeral key was discarded")
+	}
+}
+
+// TestSessionsOpen ensures packets are rejected when they are replayed, come
+// from an epoch that was superseded or are outside the clock skew allowance,
+// and that packets of the previous epoch are still accepted.
+func TestSessionsOpen(t *testing.T) {
+	a, b := newEndpoint(t, "psk"), newEndpoint(t, "psk")
+	// packets of a session that sent no key can't be opened
+	p0, _ := seal(t, a.send, "zero")
+	if _, _, err := b.recv.open(p0); err != errUnknownSession {
+		t.Errorf("unknown session: got %v, want %v", err, errUnknownSession)
+	}
+	handshake(t, a, b)
+	if _, _, err := b.recv.open(p0); err != nil {
+		t.Fatalf("first packet: %v", err)
+	}
+	if _, _, err := b.recv.open(p0); err != errReplayed {
+		t.Errorf("replay: got %v, want %v", err, errReplayed)
+	}
+	// the timestamp is checked before the packet is authenticated
+	ts := magicSize + sessionIDSize + 4 + 8 + 8
+	for _, skew := range []time.Duration{MaxClockSkew * 2, -MaxClockSkew * 2} {
+		p, _ := seal(t, a.send, "skewed")
+		binary.BigEndian.PutUint64(p[ts:],
+			uint64(time.Now().Add(skew).UnixNano()))
+		if _, _, err := b.recv.open(p); err != errClockSkew {
+			t.Errorf("skew %v: got %v, want %v", skew, err, errClockSkew)
+		}
+	}
+	// packets of the previous epoch are accepted after a rekey, older ones
+	// are not
+	late0, _ := seal(t, a.send, "late zero")
+	stale0, _ := seal(t, a.send, "stale zero")
+	rekey := func() (p []byte, keys [][]byte) {
+		a.send.started = time.Now().Add(-RekeyAfterTime - time.Second)
+		p, rekeyed := seal(t, a.send, "rekeyed")
+		if !rekeyed {
+			t.Fatal("session not rekeyed")
+		}
+		keys = a.send.keyPackets()
+		for _, key := range keys {
+			if err := b.recv.installKey(key); err != nil {
+				t.Fatalf("installKey: %v", err)
+			}
+		}
+		if _, _, err := b.recv.open(p); err != nil {
+			t.Fatalf("packet of epoch %d: %v", a.send.epoch, err)
+		}
+		return
+	}
+	p1, keys1 := rekey()
+	late1, _ := seal(t, a.send, "late one")
+	if _, data, err := b.recv.open(late0); err != nil ||
+		string(data) != "late zero" {
+		t.Errorf("previous epoch: got %q, %v, want \"late zero\"", data, err)
+	}
+	rekey()
+	if _, _, err := b.recv.open(stale0); err != errStaleEpoch {
+		t.Errorf("stale epoch: got %v, want %v", err, errStaleEpoch)
+	}
+	if _, data, err := b.recv.open(late1); err != nil ||
+		string(data) != "late one" {
+		t.Errorf("previous epoch: got %q, %v, want \"late one\"", data, err)
+	}
+	// a replayed key packet doesn't reset the replay window of its epoch
+	for _, key := range keys1 {
+		if err := b.recv.installKey(key); err != nil {
+			t.Errorf("replayed key packet: %v", err)
+		}
+	}
+	if _, _, err := b.recv.open(p1); err != errReplayed {
+		t.Errorf("replay after key packet replay: got %v, want %v", err,
+			errReplayed)
+	}
+}
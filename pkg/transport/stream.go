@@ -0,0 +1,118 @@
+package transport
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+const (
+	// streamWriteTimeout is how long a stream can block a send before it is
+	// dropped
+	streamWriteTimeout = time.Second * 5
+	// streamRedialInterval is the delay before a client channel reconnects
+	// its stream after it fails
+	streamRedialInterval = time.Second * 3
+)
+
+// Streams carry the same packets as the UDP channels, each prefixed with its
+// length as a big endian 16 bit integer, so they are encrypted and sharded
+// exactly the same way and are handled by the same code
+
+// writeFrame writes a length prefixed packet to a stream
+func writeFrame(conn net.Conn, msg []byte) (err error) {
+	if len(msg) > 0xffff {
+		return errors.New("packet too large for stream frame")
+	}
+	frame := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(frame, uint16(len(msg)))
+	copy(frame[2:], msg)
+	if err = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); Check(err) {
+	}
+	_, err = conn.Write(frame)
+	return
+}
+
+// ListenStream accepts TCP connections on the given address for a channel.
+// Packets received on them are handled the same as UDP packets and every
+// message sent through the channel is written to all of them.
+func ListenStream(address string, channel *Channel, handlers Handlers) (
+	err error) {
+	if channel.listener, err = net.Listen("tcp4", address); Check(err) {
+		return
+	}
+	Debug("starting stream listener on", channel.listener.Addr())
+	go func() {
+		for {
+			conn, err := channel.listener.Accept()
+			if err != nil {
+				select {
+				case <-channel.done:
+				default:
+					Error("stream listener stopped:", err)
+				}
+				return
+			}
+			Debug(channel.Creator, "new stream from", conn.RemoteAddr())
+			go channel.handleStream(address, conn, handlers)
+		}
+	}()
+	return
+}
+
+// dialStream keeps a client channel connected to the stream listener at
+// address until the channel is closed
+func (c *Channel) dialStream(address string, handlers Handlers) {
+	for {
+		conn, err := net.DialTimeout("tcp4", address, streamRedialInterval)
+		if err != nil {
+			Debug(c.Creator, "unable to connect stream:", err)
+		} else {
+			Debug(c.Creator, "connected stream to", conn.RemoteAddr())
+			c.handleStream(address, conn, handlers)
+		}
+		select {
+		case <-time.After(streamRedialInterval):
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// handleStream registers a stream with a channel and reads packets from it
+// until it fails or the channel is closed
+func (c *Channel) handleStream(address string, conn net.Conn,
+	handlers Handlers) {
+	c.mx.Lock()
+	select {
+	case <-c.done:
+		c.mx.Unlock()
+		if err := conn.Close(); Check(err) {
+		}
+		return
+	default:
+	}
+	c.streams[conn] = struct{}{}
+	c.mx.Unlock()
+	// the other end needs a hello to send its key over the stream
+	c.sayHello()
+	r := bufio.NewReader(conn)
+	length := make([]byte, 2)
+	buffer := make([]byte, 0xffff)
+	var err error
+	for {
+		if _, err = io.ReadFull(r, length); err != nil {
+			break
+		}
+		msg := buffer[:binary.BigEndian.Uint16(length)]
+		if _, err = io.ReadFull(r, msg); err != nil {
+			break
+		}
+		c.handlePacket(address, handlers, conn.RemoteAddr(), msg)
+	}
+	Debug(c.Creator, "stream from", conn.RemoteAddr(), "closed:", err)
+	c.dropStream(conn)
+}
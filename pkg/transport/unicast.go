@@ -0,0 +1,170 @@
+package transport
+
+import (
+	"net"
+	"time"
+)
+
+// PeerTimeout is how long a unicast subscriber is sent messages after the
+// last hello it sent
+const PeerTimeout = HelloInterval * 3
+
+type peer struct {
+	addr     *net.UDPAddr
+	lastSeen time.Time
+}
+
+// NewServerChannel returns a channel that listens for UDP packets and TCP
+// streams on the given address. Clients that send hellos over UDP and all
+// connected streams are sent every message sent through the channel.
+func NewServerChannel(creator string, ctx interface{}, key, address string,
+	maxDatagramSize int, handlers Handlers, quit chan struct{}) (
+	channel *Channel, err error) {
+	if channel, err = newUnicastChannel(creator, ctx, key,
+		maxDatagramSize); Check(err) {
+		return
+	}
+	channel.peers = make(map[string]*peer)
+	if channel.Receiver, err = Listen(address, channel, maxDatagramSize,
+		handlers, quit); Check(err) {
+		return
+	}
+	close(channel.Ready)
+	if err = ListenStream(address, channel, handlers); Check(err) {
+		if e := channel.Close(); Check(e) {
+		}
+		return
+	}
+	go func() {
+		select {
+		case <-quit:
+			if err := channel.Close(); Check(err) {
+			}
+		case <-channel.done:
+		}
+	}()
+	go channel.keepAnnounced(quit)
+	Info("starting unicast server channel:", creator, address)
+	return
+}
+
+// NewClientChannel returns a channel connected to a server channel at the
+// given address, either by UDP or, if tcp is set, by a TCP stream which is
+// reconnected when it fails
+func NewClientChannel(creator string, ctx interface{}, key, address string,
+	tcp bool, maxDatagramSize int, handlers Handlers, quit chan struct{}) (
+	channel *Channel, err error) {
+	if channel, err = newUnicastChannel(creator, ctx, key,
+		maxDatagramSize); Check(err) {
+		return
+	}
+	go func() {
+		select {
+		case <-quit:
+			if err := channel.Close(); Check(err) {
+			}
+		case <-channel.done:
+		}
+	}()
+	if tcp {
+		close(channel.Ready)
+		go channel.dialStream(address, handlers)
+		go channel.keepAnnounced(quit)
+		Info("starting stream client channel:", creator, address)
+		return
+	}
+	if channel.remote, err = net.ResolveUDPAddr("udp4", address); Check(err) {
+		return
+	}
+	if channel.Receiver, err = net.ListenUDP("udp4", nil); Check(err) {
+		return
+	}
+	if err = channel.Receiver.SetReadBuffer(maxDatagramSize); Check(err) {
+	}
+	close(channel.Ready)
+	go Handle(address, channel, handlers, maxDatagramSize, quit)
+	go channel.keepAnnounced(quit)
+	Info("starting unicast client channel:", creator,
+		channel.Receiver.LocalAddr(), "->", address)
+	return
+}
+
+func newUnicastChannel(creator string, ctx interface{}, key string,
+	maxDatagramSize int) (channel *Channel, err error) {
+	channel = &Channel{
+		Creator:         creator,
+		MaxDatagramSize: maxDatagramSize,
+		buffers:         make(map[string]*MsgBuffer),
+		context:         ctx,
+		Ready:           make(chan struct{}),
+		streams:         make(map[net.Conn]struct{}),
+		done:            make(chan struct{}),
+	}
+	err = channel.setKey(key)
+	return
+}
+
+// subscribe adds or refreshes the sender of an authentic hello in the peers of
+// a server channel
+func (c *Channel) subscribe(src net.Addr) {
+	if c.peers == nil {
+		return
+	}
+	addr, ok := src.(*net.UDPAddr)
+	if !ok {
+		return
+	}
+	key := addr.String()
+	c.mx.Lock()
+	if p, ok := c.peers[key]; ok {
+		p.lastSeen = time.Now()
+	} else {
+		Debug(c.Creator, "new unicast subscriber", key)
+		c.peers[key] = &peer{addr: addr, lastSeen: time.Now()}
+	}
+	c.mx.Unlock()
+}
+
+// sendPeers writes a packet to the subscribers and streams of a channel,
+// dropping subscribers that have expired and streams that fail. The lists are
+// copied under the lock and written to without it, so a stalled stream does
+// not hold up the others or the registration of new peers.
+func (c *Channel) sendPeers(msg []byte) {
+	c.mx.Lock()
+	now := time.Now()
+	addrs := make([]*net.UDPAddr, 0, len(c.peers))
+	for key, p := range c.peers {
+		if now.Sub(p.lastSeen) > PeerTimeout {
+			Debug(c.Creator, "unicast subscriber expired", key)
+			delete(c.peers, key)
+			continue
+		}
+		addrs = append(addrs, p.addr)
+	}
+	conns := make([]net.Conn, 0, len(c.streams))
+	for conn := range c.streams {
+		conns = append(conns, conn)
+	}
+	c.mx.Unlock()
+	for _, addr := range addrs {
+		if _, err := c.Receiver.WriteToUDP(msg, addr); Check(err) {
+		}
+	}
+	for _, conn := range conns {
+		if err := writeFrame(conn, msg); Check(err) {
+			c.dropStream(conn)
+		}
+	}
+}
+
+// dropStream closes a stream and removes it from a channel if it has not been
+// removed already
+func (c *Channel) dropStream(conn net.Conn) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if _, ok := c.streams[conn]; ok {
+		delete(c.streams, conn)
+		if err := conn.Close(); Check(err) {
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package transport
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+const testDatagramSize = 8192
+
+// freeAddress returns a loopback address with a port that is free for both
+// the UDP and TCP listeners of a server channel
+func freeAddress(t *testing.T) string {
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := l.Addr().String()
+	if err = l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return address
+}
+
+// newTestClient returns a client channel of a server channel and a channel
+// that receives the messages it is sent with the test magic
+func newTestClient(t *testing.T, key, address string, tcp bool,
+	quit chan struct{}) (received chan string) {
+	received = make(chan string, 16)
+	handlers := Handlers{
+		string(testMagic): func(ctx interface{}, src net.Addr, dst string,
+			b []byte) (err error) {
+			received <- string(b)
+			return
+		},
+	}
+	if _, err := NewClientChannel("client", nil, key, address, tcp,
+		testDatagramSize, handlers, quit); err != nil {
+		t.Fatalf("NewClientChannel: %v", err)
+	}
+	return
+}
+
+// delivered sends a message from a server channel until it is received, and
+// returns whether it was before the timeout
+func delivered(t *testing.T, server *Channel, received chan string,
+	msg string, timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(time.Millisecond * 100)
+	defer ticker.Stop()
+	for {
+		if err := server.SendMany(testMagic,
+			GetShards([]byte(msg))); err != nil {
+			t.Fatalf("SendMany: %v", err)
+		}
+		select {
+		case got := <-received:
+			if got != msg {
+				t.Fatalf("received %q, want %q", got, msg)
+			}
+			return true
+		case <-ticker.C:
+		case <-deadline:
+			return false
+		}
+	}
+}
+
+// TestUnicastRoundTrip ensures messages sent through a server channel reach
+// the client channels connected to it over UDP and TCP, that a client with a
+// wrong pass receives none of them, and that a client stream reconnects after
+// it is dropped.
+func TestUnicastRoundTrip(t *testing.T) {
+	const key = "pa55word"
+	quit := make(chan struct{})
+	defer close(quit)
+	address := freeAddress(t)
+	server, err := NewServerChannel("server", nil, key, address,
+		testDatagramSize, Handlers{}, quit)
+	if err != nil {
+		t.Fatalf("NewServerChannel: %v", err)
+	}
+	udp := newTestClient(t, key, address, false, quit)
+	if !delivered(t, server, udp, "udp", time.Second*10) {
+		t.Fatal("message not received over UDP")
+	}
+	tcp := newTestClient(t, key, address, true, quit)
+	if !delivered(t, server, tcp, "tcp", time.Second*10) {
+		t.Fatal("message not received over TCP")
+	}
+	// drop the stream from the server side, the client dials it again
+	server.mx.Lock()
+	for conn := range server.streams {
+		if err = conn.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server.mx.Unlock()
+	if !delivered(t, server, tcp, "reconnected",
+		streamRedialInterval+time.Second*10) {
+		t.Fatal("message not received after the stream reconnected")
+	}
+	for _, tcp := range []bool{false, true} {
+		wrong := newTestClient(t, "wrong", address, tcp, quit)
+		if delivered(t, server, wrong, "secret", time.Second*2) {
+			t.Errorf("client with a wrong pass received a message (tcp %v)",
+				tcp)
+		}
+	}
+}
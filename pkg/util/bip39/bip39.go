@@ -0,0 +1,160 @@
+// Package bip39 implements the mnemonic codes of BIP39 for generating
+// deterministic keys, which encode a wallet seed's entropy as a list of words
+// that can be written down and later used to recover the wallet. The format is
+// defined at https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki
+package bip39
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// MinEntropyBits is the fewest bits of entropy a mnemonic may encode, giving
+	// 12 words.
+	MinEntropyBits = 128
+	// MaxEntropyBits is the most bits of entropy a mnemonic may encode, giving
+	// 24 words.
+	MaxEntropyBits = 256
+	// SeedLen is the length in bytes of the seed derived from a mnemonic.
+	SeedLen = 64
+	// seedIterations is the number of PBKDF2 rounds used to derive a seed.
+	seedIterations = 2048
+	// bitsPerWord is the number of bits encoded by each word of a mnemonic.
+	bitsPerWord = 11
+)
+
+var (
+	// ErrEntropyLength is returned when entropy is not a multiple of 32 bits
+	// between MinEntropyBits and MaxEntropyBits.
+	ErrEntropyLength = errors.New(
+		"entropy length must be a multiple of 32 bits between 128 and 256")
+	// ErrWordCount is returned when a mnemonic does not have 12, 15, 18, 21 or
+	// 24 words.
+	ErrWordCount = errors.New("mnemonic must have 12, 15, 18, 21 or 24 words")
+	// ErrUnknownWord is returned when a mnemonic has a word that is not in the
+	// wordlist.
+	ErrUnknownWord = errors.New("mnemonic has a word not in the wordlist")
+	// ErrChecksum is returned when the checksum of a mnemonic does not match its
+	// entropy, such as when a word was mistyped or the words are out of order.
+	ErrChecksum = errors.New("mnemonic checksum is invalid")
+)
+
+// NewEntropy returns bits of random entropy for a new mnemonic. bits must be a
+// multiple of 32 between MinEntropyBits and MaxEntropyBits.
+func NewEntropy(bits int) (entropy []byte, err error) {
+	if err = validateEntropyBits(bits); err != nil {
+		return
+	}
+	entropy = make([]byte, bits/8)
+	if _, err = rand.Read(entropy); err != nil {
+		Error(err)
+		return nil, err
+	}
+	return
+}
+
+// EntropyBits returns the bits of entropy encoded by a mnemonic of words words.
+func EntropyBits(words int) (bits int, err error) {
+	if words < 12 || words > 24 || words%3 != 0 {
+		return 0, ErrWordCount
+	}
+	// each 3 words encode 32 bits of entropy and a bit of checksum
+	return words / 3 * 32, nil
+}
+
+// NewMnemonic returns the mnemonic encoding entropy.
+func NewMnemonic(entropy []byte) (mnemonic string, err error) {
+	bits := len(entropy) * 8
+	if err = validateEntropyBits(bits); err != nil {
+		return
+	}
+	checksumBits := bits / 32
+	words := (bits + checksumBits) / bitsPerWord
+	// append the checksum, the first bits of the hash of the entropy, to the
+	// entropy and split the result into 11 bit word indexes
+	hash := sha256.Sum256(entropy)
+	data := new(big.Int).SetBytes(entropy)
+	data.Lsh(data, uint(checksumBits))
+	data.Or(data, big.NewInt(int64(hash[0]>>(8-uint(checksumBits)))))
+	mask := big.NewInt(1<<bitsPerWord - 1)
+	index := new(big.Int)
+	out := make([]string, words)
+	for i := words - 1; i >= 0; i-- {
+		index.And(data, mask)
+		out[i] = English[index.Int64()]
+		data.Rsh(data, bitsPerWord)
+	}
+	return strings.Join(out, " "), nil
+}
+
+// MnemonicToEntropy returns the entropy encoded by a mnemonic, after checking
+// its words and checksum.
+func MnemonicToEntropy(mnemonic string) (entropy []byte, err error) {
+	words := strings.Fields(mnemonic)
+	var bits int
+	if bits, err = EntropyBits(len(words)); err != nil {
+		return
+	}
+	checksumBits := bits / 32
+	data := new(big.Int)
+	for _, w := range words {
+		index, ok := englishIndex[w]
+		if !ok {
+			return nil, ErrUnknownWord
+		}
+		data.Lsh(data, bitsPerWord)
+		data.Or(data, big.NewInt(int64(index)))
+	}
+	checksum := new(big.Int).And(data, big.NewInt(1<<uint(checksumBits)-1))
+	data.Rsh(data, uint(checksumBits))
+	// big.Int drops leading zero bytes, so pad the entropy back to its length
+	entropy = make([]byte, bits/8)
+	b := data.Bytes()
+	copy(entropy[len(entropy)-len(b):], b)
+	hash := sha256.Sum256(entropy)
+	if checksum.Int64() != int64(hash[0]>>(8-uint(checksumBits))) {
+		return nil, ErrChecksum
+	}
+	return
+}
+
+// IsMnemonicValid returns whether a mnemonic has a valid number of words, all
+// from the wordlist, and a valid checksum.
+func IsMnemonicValid(mnemonic string) bool {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err == nil
+}
+
+// NewSeed returns the wallet seed derived from a mnemonic and an optional
+// passphrase. The mnemonic is not checked, so any passphrase gives a valid but
+// different seed; use NewSeedChecked for mnemonics entered by a user.
+func NewSeed(mnemonic, passphrase string) []byte {
+	password := norm.NFKD.String(strings.Join(strings.Fields(mnemonic), " "))
+	salt := norm.NFKD.String("mnemonic" + passphrase)
+	return pbkdf2.Key([]byte(password), []byte(salt), seedIterations, SeedLen,
+		sha512.New)
+}
+
+// NewSeedChecked returns the wallet seed derived from a mnemonic and an
+// optional passphrase after checking the mnemonic is valid.
+func NewSeedChecked(mnemonic, passphrase string) (seed []byte, err error) {
+	if _, err = MnemonicToEntropy(mnemonic); err != nil {
+		return
+	}
+	return NewSeed(mnemonic, passphrase), nil
+}
+
+func validateEntropyBits(bits int) error {
+	if bits < MinEntropyBits || bits > MaxEntropyBits || bits%32 != 0 {
+		return ErrEntropyLength
+	}
+	return nil
+}
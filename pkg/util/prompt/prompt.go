@@ -10,6 +10,7 @@ import (
 
 	"github.com/btcsuite/golangcrypto/ssh/terminal"
 
+	"github.com/p9c/pod/pkg/util/bip39"
 	"github.com/p9c/pod/pkg/util/hdkeychain"
 	"github.com/p9c/pod/pkg/util/legacy/keystore"
 )
@@ -239,64 +240,134 @@ func PublicPass(reader *bufio.Reader, privPass []byte,
 	return pubPass, nil
 }
 
-// Seed prompts the user whether they want to use an existing wallet generation
-// seed.  When the user answers no, a seed will be generated and displayed to
-// the user along with prompting them for confirmation.  When the user answers
-// yes, a the user is prompted for it.  All prompts are repeated until the user
-// enters a valid response.
-func Seed(reader *bufio.Reader) ([]byte, error) {
+// Seed prompts the user whether they want to use an existing wallet seed or
+// mnemonic.  When the user answers no, a BIP39 mnemonic of 12 or 24 words is
+// generated, protected by an optional passphrase, and displayed to the user
+// along with prompting them for confirmation.  When the user answers yes, they
+// are prompted for it as in RestoreSeed, and existing is returned true so the
+// wallet can scan the chain for the addresses it has used.  All prompts are
+// repeated until the user enters a valid response.
+func Seed(reader *bufio.Reader) (seed []byte, existing bool, err error) {
 	// Ascertain the wallet generation seed.
 	useUserSeed, err := promptListBool(reader, "Do you have an "+
-		"existing wallet seed you want to use?", "no")
+		"existing wallet seed or mnemonic you want to use?", "no")
 	if err != nil {
 		Error(err)
-		return nil, err
+		return nil, false, err
+	}
+	if useUserSeed {
+		seed, err = RestoreSeed(reader)
+		return seed, true, err
 	}
-	if !useUserSeed {
-		seed, err := hdkeychain.GenerateSeed(hdkeychain.RecommendedSeedLen)
+	words, err := promptList(reader, "How many words should the mnemonic "+
+		"have?", []string{"12", "24"}, "24")
+	if err != nil {
+		Error(err)
+		return nil, false, err
+	}
+	bits := bip39.MaxEntropyBits
+	if words == "12" {
+		bits = bip39.MinEntropyBits
+	}
+	entropy, err := bip39.NewEntropy(bits)
+	if err != nil {
+		Error(err)
+		return nil, false, err
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		Error(err)
+		return nil, false, err
+	}
+	usePass, err := promptListBool(reader, "Do you want to protect the "+
+		"mnemonic with an additional passphrase?", "no")
+	if err != nil {
+		Error(err)
+		return nil, false, err
+	}
+	var mnemonicPass []byte
+	if usePass {
+		mnemonicPass, err = promptPass(reader, "Enter the mnemonic passphrase",
+			true)
 		if err != nil {
 			Error(err)
-			return nil, err
+			return nil, false, err
 		}
-		fmt.Println("\nYour wallet generation seed is:")
-		fmt.Printf("\n%x\n\n", seed)
-		fmt.Print("IMPORTANT: Keep the seed in a safe place as you will NOT be" +
-			" able to restore your wallet without it.\n\n")
-		fmt.Print("Please keep in mind that anyone who has access to the seed" +
-			" can also restore your wallet thereby giving them access to all your funds, so it is imperative that you keep it in a secure location.\n\n")
-		for {
-			fmt.Print(`Once you have stored the seed in a safe ` +
-				`and secure location, enter "OK" to continue: `)
-			confirmSeed, err := reader.ReadString('\n')
-			if err != nil {
-				Error(err)
-				return nil, err
-			}
-			confirmSeed = strings.TrimSpace(confirmSeed)
-			confirmSeed = strings.Trim(confirmSeed, `"`)
-			if confirmSeed == "OK" {
-				break
-			}
+	}
+	fmt.Println("\nYour wallet mnemonic is:")
+	fmt.Printf("\n%s\n\n", mnemonic)
+	fmt.Print("IMPORTANT: Write the words down in order and keep them in a " +
+		"safe place as you will NOT be able to restore your wallet without " +
+		"them.\n\n")
+	if usePass {
+		fmt.Print("The mnemonic passphrase is needed along with the words to " +
+			"restore your wallet, and a different passphrase restores a " +
+			"different, empty wallet.\n\n")
+	}
+	fmt.Print("Please keep in mind that anyone who has access to the mnemonic" +
+		" can also restore your wallet thereby giving them access to all your funds, so it is imperative that you keep it in a secure location.\n\n")
+	for {
+		fmt.Print(`Once you have stored the mnemonic in a safe ` +
+			`and secure location, enter "OK" to continue: `)
+		confirmSeed, err := reader.ReadString('\n')
+		if err != nil {
+			Error(err)
+			return nil, false, err
+		}
+		confirmSeed = strings.TrimSpace(confirmSeed)
+		confirmSeed = strings.Trim(confirmSeed, `"`)
+		if confirmSeed == "OK" {
+			break
 		}
-		return seed, nil
 	}
+	return bip39.NewSeed(mnemonic, string(mnemonicPass)), false, nil
+}
+
+// RestoreSeed prompts the user for the BIP39 mnemonic of a wallet being
+// restored, and its passphrase if it has one, and returns the seed derived from
+// them.  A hexadecimal seed as shown by earlier versions of the wallet is also
+// accepted.  All prompts are repeated until the user enters a valid response.
+func RestoreSeed(reader *bufio.Reader) ([]byte, error) {
 	for {
-		fmt.Print("Enter existing wallet seed: ")
+		fmt.Print("Enter existing wallet mnemonic or seed: ")
 		seedStr, err := reader.ReadString('\n')
 		if err != nil {
 			Error(err)
 			return nil, err
 		}
-		seedStr = strings.TrimSpace(strings.ToLower(seedStr))
-		seed, err := hex.DecodeString(seedStr)
-		if err != nil || len(seed) < hdkeychain.MinSeedBytes ||
-			len(seed) > hdkeychain.MaxSeedBytes {
-			Errorf("Invalid seed specified.  Must be a "+
-				"hexadecimal value that is at least %d bits and "+
-				"at most %d bits\n", hdkeychain.MinSeedBytes*8,
-				hdkeychain.MaxSeedBytes*8)
+		words := strings.Fields(strings.ToLower(seedStr))
+		if len(words) == 1 {
+			seed, err := hex.DecodeString(words[0])
+			if err != nil || len(seed) < hdkeychain.MinSeedBytes ||
+				len(seed) > hdkeychain.MaxSeedBytes {
+				Errorf("Invalid seed specified.  Must be a "+
+					"hexadecimal value that is at least %d bits and "+
+					"at most %d bits\n", hdkeychain.MinSeedBytes*8,
+					hdkeychain.MaxSeedBytes*8)
+				continue
+			}
+			return seed, nil
+		}
+		mnemonic := strings.Join(words, " ")
+		if _, err = bip39.MnemonicToEntropy(mnemonic); err != nil {
+			Error("Invalid mnemonic specified: ", err)
 			continue
 		}
-		return seed, nil
+		usePass, err := promptListBool(reader, "Is the mnemonic protected "+
+			"with a passphrase?", "no")
+		if err != nil {
+			Error(err)
+			return nil, err
+		}
+		var mnemonicPass []byte
+		if usePass {
+			mnemonicPass, err = promptPass(reader,
+				"Enter the mnemonic passphrase", false)
+			if err != nil {
+				Error(err)
+				return nil, err
+			}
+		}
+		return bip39.NewSeed(mnemonic, string(mnemonicPass)), nil
 	}
 }
@@ -0,0 +1,135 @@
+package psbt
+
+// The Combiner merges the key-value pairs of several PSBTs for the same
+// unsigned transaction, such as the partial signatures of co-signers who each
+// signed their own copy, into one.
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrCombineMismatch indicates that the PSBTs passed to Combine are not for
+// the same unsigned transaction.
+var ErrCombineMismatch = errors.New("PSBTs to combine must have the same " +
+	"unsigned transaction")
+
+// Combine returns a new Packet with the union of the key-value pairs of the
+// given packets, which must all have the same unsigned transaction. Where
+// more than one packet has a value for the same key, the value of the first
+// packet that has one is kept. The given packets are not modified.
+func Combine(packets ...*Packet) (*Packet, error) {
+	if len(packets) == 0 {
+		return nil, errors.New("no PSBTs to combine")
+	}
+	for _, p := range packets {
+		if err := VerifyInputOutputLen(p, false, false); err != nil {
+			return nil, err
+		}
+	}
+
+	txHash := packets[0].UnsignedTx.TxHash()
+	for _, p := range packets[1:] {
+		if p.UnsignedTx.TxHash() != txHash {
+			return nil, ErrCombineMismatch
+		}
+	}
+
+	combined, err := NewFromUnsignedTx(packets[0].UnsignedTx.Copy())
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range packets {
+		combined.Unknowns = combineUnknowns(combined.Unknowns, p.Unknowns)
+		for i := range p.Inputs {
+			combineInput(&combined.Inputs[i], &p.Inputs[i])
+		}
+		for i := range p.Outputs {
+			combineOutput(&combined.Outputs[i], &p.Outputs[i])
+		}
+	}
+
+	if err := combined.SanityCheck(); err != nil {
+		return nil, err
+	}
+	return combined, nil
+}
+
+// combineInput adds the fields of src that dst does not have yet to dst.
+func combineInput(dst, src *PInput) {
+	if dst.NonWitnessUtxo == nil {
+		dst.NonWitnessUtxo = src.NonWitnessUtxo
+	}
+	if dst.WitnessUtxo == nil {
+		dst.WitnessUtxo = src.WitnessUtxo
+	}
+	if dst.SighashType == 0 {
+		dst.SighashType = src.SighashType
+	}
+	if dst.RedeemScript == nil {
+		dst.RedeemScript = src.RedeemScript
+	}
+	if dst.WitnessScript == nil {
+		dst.WitnessScript = src.WitnessScript
+	}
+	if dst.FinalScriptSig == nil {
+		dst.FinalScriptSig = src.FinalScriptSig
+	}
+	if dst.FinalScriptWitness == nil {
+		dst.FinalScriptWitness = src.FinalScriptWitness
+	}
+
+next:
+	for _, ps := range src.PartialSigs {
+		for _, x := range dst.PartialSigs {
+			if bytes.Equal(x.PubKey, ps.PubKey) {
+				continue next
+			}
+		}
+		dst.PartialSigs = append(dst.PartialSigs, ps)
+	}
+	dst.Bip32Derivation = combineBip32(dst.Bip32Derivation, src.Bip32Derivation)
+	dst.Unknowns = combineUnknowns(dst.Unknowns, src.Unknowns)
+}
+
+// combineOutput adds the fields of src that dst does not have yet to dst.
+func combineOutput(dst, src *POutput) {
+	if dst.RedeemScript == nil {
+		dst.RedeemScript = src.RedeemScript
+	}
+	if dst.WitnessScript == nil {
+		dst.WitnessScript = src.WitnessScript
+	}
+	dst.Bip32Derivation = combineBip32(dst.Bip32Derivation, src.Bip32Derivation)
+	dst.Unknowns = combineUnknowns(dst.Unknowns, src.Unknowns)
+}
+
+// combineBip32 returns dst with the derivations of src for public keys that
+// are not in dst yet appended.
+func combineBip32(dst, src []*Bip32Derivation) []*Bip32Derivation {
+next:
+	for _, d := range src {
+		for _, x := range dst {
+			if bytes.Equal(x.PubKey, d.PubKey) {
+				continue next
+			}
+		}
+		dst = append(dst, d)
+	}
+	return dst
+}
+
+// combineUnknowns returns dst with the pairs of src for keys that are not in
+// dst yet appended.
+func combineUnknowns(dst, src []*Unknown) []*Unknown {
+next:
+	for _, u := range src {
+		for _, x := range dst {
+			if bytes.Equal(x.Key, u.Key) {
+				continue next
+			}
+		}
+		dst = append(dst, u)
+	}
+	return dst
+}
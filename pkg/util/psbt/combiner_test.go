@@ -0,0 +1,186 @@
+package psbt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/util"
+	ec "github.com/p9c/pod/pkg/util/elliptic"
+)
+
+// multiSigPacket returns the keys of an m-of-n multisig, its script, and an
+// unsigned packet spending an output paying to it with the previous
+// transaction filled in. The output pays to the script bare if bare is true,
+// and otherwise to its P2SH address, with the redeem script filled in too.
+func multiSigPacket(t *testing.T, m, n int, bare bool) ([]*ec.PrivateKey,
+	[]byte, *Packet) {
+
+	keys := make([]*ec.PrivateKey, n)
+	pubKeys := make([]*util.AddressPubKey, n)
+	for i := range keys {
+		key, err := ec.NewPrivateKey(ec.S256())
+		if err != nil {
+			t.Fatalf("unable to create key: %v", err)
+		}
+		pubKey, err := util.NewAddressPubKey(
+			key.PubKey().SerializeCompressed(), &netparams.MainNetParams,
+		)
+		if err != nil {
+			t.Fatalf("unable to create address: %v", err)
+		}
+		keys[i], pubKeys[i] = key, pubKey
+	}
+	redeemScript, err := txscript.MultiSigScript(pubKeys, m)
+	if err != nil {
+		t.Fatalf("unable to create redeem script: %v", err)
+	}
+	pkScript := redeemScript
+	if !bare {
+		addr, err := util.NewAddressScriptHash(
+			redeemScript, &netparams.MainNetParams,
+		)
+		if err != nil {
+			t.Fatalf("unable to create script address: %v", err)
+		}
+		pkScript, err = txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatalf("unable to create pkScript: %v", err)
+		}
+	}
+
+	prevTx := wire.NewMsgTx(1)
+	prevTx.AddTxIn(&wire.TxIn{Sequence: wire.MaxTxInSequenceNum})
+	prevTx.AddTxOut(wire.NewTxOut(100000000, pkScript))
+
+	prevHash := prevTx.TxHash()
+	packet, err := New(
+		[]*wire.OutPoint{wire.NewOutPoint(&prevHash, 0)},
+		[]*wire.TxOut{wire.NewTxOut(99990000, pkScript)}, 1, 0,
+		[]uint32{wire.MaxTxInSequenceNum},
+	)
+	if err != nil {
+		t.Fatalf("unable to create packet: %v", err)
+	}
+	packet.Inputs[0].NonWitnessUtxo = prevTx
+	if !bare {
+		packet.Inputs[0].RedeemScript = redeemScript
+	}
+
+	return keys, redeemScript, packet
+}
+
+// signCopy returns a copy of packet signed by key.
+func signCopy(t *testing.T, packet *Packet, key *ec.PrivateKey,
+	redeemScript []byte) *Packet {
+
+	var b bytes.Buffer
+	if err := packet.Serialize(&b); err != nil {
+		t.Fatalf("unable to serialize packet: %v", err)
+	}
+	signed, err := NewFromRawBytes(&b, false)
+	if err != nil {
+		t.Fatalf("unable to parse packet: %v", err)
+	}
+	sig, err := txscript.RawTxInSignature(
+		signed.UnsignedTx, 0, redeemScript, txscript.SigHashAll, key,
+	)
+	if err != nil {
+		t.Fatalf("unable to sign: %v", err)
+	}
+	u, err := NewUpdater(signed)
+	if err != nil {
+		t.Fatalf("unable to create updater: %v", err)
+	}
+	outcome, err := u.Sign(
+		0, sig, key.PubKey().SerializeCompressed(), nil, nil,
+	)
+	if err != nil || outcome != SignSuccesful {
+		t.Fatalf("unable to add signature: %v", err)
+	}
+	return signed
+}
+
+// TestCombineMultiSig checks that the signatures of co-signers who each
+// signed their own copy of a PSBT are combined into one that can be
+// finalized, including when more of them signed than the script requires,
+// and when the output pays to the script bare, as the core developers' share
+// of a hard fork disbursement does.
+func TestCombineMultiSig(t *testing.T) {
+	tests := []struct {
+		name    string
+		m, n    int
+		bare    bool
+		signers []int
+	}{
+		{name: "2 of 3", m: 2, n: 3, signers: []int{2, 0}},
+		{name: "3 of 4", m: 3, n: 4, signers: []int{3, 1, 0}},
+		{name: "3 of 4 signed by all", m: 3, n: 4,
+			signers: []int{0, 1, 2, 3}},
+		{name: "bare 3 of 4", m: 3, n: 4, bare: true,
+			signers: []int{1, 3, 2}},
+	}
+	for _, test := range tests {
+		keys, redeemScript, packet := multiSigPacket(
+			t, test.m, test.n, test.bare,
+		)
+
+		var signed []*Packet
+		for _, i := range test.signers {
+			signed = append(
+				signed, signCopy(t, packet, keys[i], redeemScript),
+			)
+		}
+
+		// One signature is not enough to finalize.
+		if _, err := MaybeFinalize(signed[0], 0); err == nil {
+			t.Fatalf("%s: finalized with a single signature",
+				test.name)
+		}
+
+		combined, err := Combine(signed...)
+		if err != nil {
+			t.Fatalf("%s: unable to combine: %v", test.name, err)
+		}
+		if len(combined.Inputs[0].PartialSigs) != len(test.signers) {
+			t.Fatalf("%s: expected %d signatures, got %d", test.name,
+				len(test.signers),
+				len(combined.Inputs[0].PartialSigs))
+		}
+		if err := MaybeFinalizeAll(combined); err != nil {
+			t.Fatalf("%s: unable to finalize: %v", test.name, err)
+		}
+		tx, err := Extract(combined)
+		if err != nil {
+			t.Fatalf("%s: unable to extract: %v", test.name, err)
+		}
+
+		prevOut := packet.Inputs[0].NonWitnessUtxo.TxOut[0]
+		vm, err := txscript.NewEngine(
+			prevOut.PkScript, tx, 0, txscript.StandardVerifyFlags,
+			nil, nil, prevOut.Value,
+		)
+		if err != nil {
+			t.Fatalf("%s: unable to create engine: %v", test.name, err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Fatalf("%s: invalid final script: %v", test.name, err)
+		}
+	}
+}
+
+// TestCombineMismatch checks that PSBTs for different transactions are not
+// combined.
+func TestCombineMismatch(t *testing.T) {
+	_, _, packet1 := multiSigPacket(t, 2, 3, false)
+	_, _, packet2 := multiSigPacket(t, 2, 3, false)
+
+	if _, err := Combine(packet1, packet2); err != ErrCombineMismatch {
+		t.Fatalf("expected ErrCombineMismatch, got %v", err)
+	}
+	if _, err := Combine(); err == nil {
+		t.Fatalf("expected an error combining no packets")
+	}
+}
@@ -66,6 +66,10 @@ type ManagedAddress interface {
 	// be used to quickly discern the address type without further
 	// processing
 	AddrType() AddressType
+	// WatchOnly returns true if the private key or script needed to spend
+	// from the backing address is not known to the wallet, such as for
+	// addresses of an account imported from an extended public key.
+	WatchOnly() bool
 }
 
 // ManagedPubKeyAddress extends ManagedAddress and additionally provides the
@@ -114,6 +118,7 @@ type managedAddress struct {
 	imported         bool
 	internal         bool
 	compressed       bool
+	watchOnly        bool
 	// used             bool
 }
 
@@ -169,6 +174,15 @@ func (a *managedAddress) AddrType() AddressType {
 	return a.addrType
 }
 
+// WatchOnly returns true if the address was derived from an account imported
+// from an extended public key, was imported from a public key, or belongs to a
+// watching-only address manager.
+//
+// This is part of the ManagedAddress interface implementation.
+func (a *managedAddress) WatchOnly() bool {
+	return a.watchOnly || a.manager.rootManager.WatchOnly()
+}
+
 // Address returns the util.Address which represents the managed address.
 // This will be a pay-to-pubkey-hash address.
 //
@@ -252,8 +266,9 @@ func (a *managedAddress) ExportPubKey() string {
 //
 // This is part of the ManagedPubKeyAddress interface implementation.
 func (a *managedAddress) PrivKey() (*ec.PrivateKey, error) {
-	// No private keys are available for a watching-only address manager.
-	if a.manager.rootManager.WatchOnly() {
+	// No private keys are available for a watching-only address manager or
+	// a watch-only address.
+	if a.WatchOnly() {
 		return nil, managerError(ErrWatchingOnly, errWatchingOnly, nil)
 	}
 	a.manager.mtx.Lock()
@@ -567,6 +582,14 @@ func (a *scriptAddress) Used(ns walletdb.ReadBucket) bool {
 	return a.manager.fetchUsed(ns, a.AddrHash())
 }
 
+// WatchOnly returns true if the address belongs to a watching-only address
+// manager.
+//
+// This is part of the ManagedAddress interface implementation.
+func (a *scriptAddress) WatchOnly() bool {
+	return a.manager.rootManager.WatchOnly()
+}
+
 // Script returns the script associated with the address.
 //
 // This implements the ScriptAddress interface.
@@ -604,3 +627,99 @@ func newScriptAddress(m *ScopedKeyManager, account uint32, scriptHash,
 		scriptEncrypted: scriptEncrypted,
 	}, nil
 }
+
+// watchAddress represents an address imported without its public key or script,
+// such as with importaddress, so that its funds can be watched but not spent.
+type watchAddress struct {
+	manager *ScopedKeyManager
+	account uint32
+	address util.Address
+}
+
+// Enforce watchAddress satisfies the ManagedAddress interface.
+var _ ManagedAddress = (*watchAddress)(nil)
+
+// Account returns the account the address is associated with.  This will always
+// be the ImportedAddrAccount constant for watch addresses.
+//
+// This is part of the ManagedAddress interface implementation.
+func (a *watchAddress) Account() uint32 {
+	return a.account
+}
+
+// AddrType returns the address type of the managed address. This can be used
+// to quickly discern the address type without further processing
+//
+// This is part of the ManagedAddress interface implementation.
+func (a *watchAddress) AddrType() AddressType {
+	switch a.address.(type) {
+	case *util.AddressPubKeyHash:
+		return PubKeyHash
+	case *util.AddressWitnessPubKeyHash:
+		return WitnessPubKey
+	}
+	return Script
+}
+
+// Address returns the util.Address which represents the managed address.
+//
+// This is part of the ManagedAddress interface implementation.
+func (a *watchAddress) Address() util.Address {
+	return a.address
+}
+
+// AddrHash returns the key or script hash for the address.
+//
+// This is part of the ManagedAddress interface implementation.
+func (a *watchAddress) AddrHash() []byte {
+	return a.address.ScriptAddress()
+}
+
+// Imported always returns true since watch addresses are always imported
+// addresses and not part of any chain.
+//
+// This is part of the ManagedAddress interface implementation.
+func (a *watchAddress) Imported() bool {
+	return true
+}
+
+// Internal always returns false since watch addresses are always imported
+// addresses and not part of any chain in order to be for internal use.
+//
+// This is part of the ManagedAddress interface implementation.
+func (a *watchAddress) Internal() bool {
+	return false
+}
+
+// Compressed returns false since the public key of a watch address, if any, is
+// not known.
+//
+// This is part of the ManagedAddress interface implementation.
+func (a *watchAddress) Compressed() bool {
+	return false
+}
+
+// Used returns true if the address has been used in a transaction.
+//
+// This is part of the ManagedAddress interface implementation.
+func (a *watchAddress) Used(ns walletdb.ReadBucket) bool {
+	return a.manager.fetchUsed(ns, a.AddrHash())
+}
+
+// WatchOnly always returns true since nothing needed to spend from a watch
+// address is known.
+//
+// This is part of the ManagedAddress interface implementation.
+func (a *watchAddress) WatchOnly() bool {
+	return true
+}
+
+// newWatchAddress initializes and returns a new watch address.
+func newWatchAddress(m *ScopedKeyManager, account uint32,
+	address util.Address) *watchAddress {
+	return &watchAddress{
+		manager: m,
+		account: account,
+		address: address,
+	}
+}
@@ -59,6 +59,7 @@ const (
 	adtChain  addressType = 0
 	adtImport addressType = 1 // not iota as they need to be stable for db
 	adtScript addressType = 2
+	adtWatch  addressType = 3
 )
 
 // accountType represents a type of address stored in the database.
@@ -123,6 +124,14 @@ type dbScriptAddressRow struct {
 	encryptedScript []byte
 }
 
+// dbWatchAddressRow houses additional information stored about an address
+// imported for watching only, without its public key or script, in the
+// database.
+type dbWatchAddressRow struct {
+	dbAddressRow
+	encryptedAddr []byte
+}
+
 // Key names for various database fields.
 // these are variables but only because they are not able to be constants
 // nolint
@@ -1201,6 +1210,44 @@ func serializeScriptAddress(encryptedHash, encryptedScript []byte) []byte {
 	return rawData
 }
 
+// deserializeWatchAddress deserializes the raw data from the passed address row
+// as a watch address.
+func deserializeWatchAddress(row *dbAddressRow) (*dbWatchAddressRow, error) {
+	// The serialized watch address raw data format is:
+	//   <encaddrlen><encaddr>
+	//
+	// 4 bytes encrypted address len + encrypted address
+	if len(row.rawData) < 4 {
+		str := "malformed serialized watch address"
+		return nil, managerError(ErrDatabase, str, nil)
+	}
+	addrLen := binary.LittleEndian.Uint32(row.rawData[0:4])
+	if uint32(len(row.rawData)) < 4+addrLen {
+		str := "malformed serialized watch address"
+		return nil, managerError(ErrDatabase, str, nil)
+	}
+	retRow := dbWatchAddressRow{
+		dbAddressRow: *row,
+	}
+	retRow.encryptedAddr = make([]byte, addrLen)
+	copy(retRow.encryptedAddr, row.rawData[4:4+addrLen])
+	return &retRow, nil
+}
+
+// serializeWatchAddress returns the serialization of the raw data field for a
+// watch address.
+func serializeWatchAddress(encryptedAddr []byte) []byte {
+	// The serialized watch address raw data format is:
+	//   <encaddrlen><encaddr>
+	//
+	// 4 bytes encrypted address len + encrypted address
+	addrLen := uint32(len(encryptedAddr))
+	rawData := make([]byte, 4+addrLen)
+	binary.LittleEndian.PutUint32(rawData[0:4], addrLen)
+	copy(rawData[4:4+addrLen], encryptedAddr)
+	return rawData
+}
+
 // fetchAddressByHash loads address information for the provided address hash
 // from the database.  The returned value is one of the address rows for the
 // specific address type.  The caller should use type assertions to ascertain
@@ -1231,6 +1278,8 @@ func fetchAddressByHash(ns walletdb.ReadBucket, scope *KeyScope,
 		return deserializeImportedAddress(row)
 	case adtScript:
 		return deserializeScriptAddress(row)
+	case adtWatch:
+		return deserializeWatchAddress(row)
 	}
 	str := fmt.Sprintf("unsupported address type '%d'", row.addrType)
 	return nil, managerError(ErrDatabase, str, nil)
@@ -1402,6 +1451,22 @@ func putScriptAddress(ns walletdb.ReadWriteBucket, scope *KeyScope,
 	return nil
 }
 
+// putWatchAddress stores the provided watch address information to the
+// database.
+func putWatchAddress(ns walletdb.ReadWriteBucket, scope *KeyScope,
+	addressID []byte, account uint32, status syncStatus,
+	encryptedAddr []byte) error {
+	rawData := serializeWatchAddress(encryptedAddr)
+	addrRow := dbAddressRow{
+		addrType:   adtWatch,
+		account:    account,
+		addTime:    uint64(time.Now().Unix()),
+		syncStatus: status,
+		rawData:    rawData,
+	}
+	return putAddress(ns, scope, addressID, &addrRow)
+}
+
 // existsAddress returns whether or not the address id exists in the database.
 func existsAddress(ns walletdb.ReadBucket, scope *KeyScope, addressID []byte) bool {
 	scopedBucket, err := fetchReadScopeBucket(ns, scope)
@@ -135,6 +135,9 @@ type accountInfo struct {
 	// The internal branch is used for all adddresses which are only
 	// intended for internal wallet use such as change addresses.
 	nextInternalIndex uint32
+	// watchOnly is set for accounts imported from an extended public key,
+	// which have no private extended key to decrypt or derive from.
+	watchOnly bool
 }
 
 // AccountProperties contains properties associated with each account, such as
@@ -145,6 +148,7 @@ type AccountProperties struct {
 	ExternalKeyCount uint32
 	InternalKeyCount uint32
 	ImportedKeyCount uint32
+	WatchOnly        bool
 }
 
 // unlockDeriveInfo houses the information needed to derive a private key for a
@@ -500,7 +504,7 @@ func (m *Manager) FetchScopedKeyManager(scope KeyScope) (*ScopedKeyManager, erro
 func (m *Manager) ActiveScopedKeyManagers() []*ScopedKeyManager {
 	m.mtx.RLock()
 	defer m.mtx.RUnlock()
-	scopedManagers := make([]*ScopedKeyManager, len(m.scopedManagers))
+	scopedManagers := make([]*ScopedKeyManager, 0, len(m.scopedManagers))
 	for _, smgr := range m.scopedManagers {
 		scopedManagers = append(scopedManagers, smgr)
 	}
@@ -995,6 +999,9 @@ func (m *Manager) Unlock(ns walletdb.ReadBucket, passphrase []byte) error {
 	// extended keys.
 	for _, manager := range m.scopedManagers {
 		for account, acctInfo := range manager.acctInfo {
+			if acctInfo.watchOnly {
+				continue
+			}
 			decrypted, err := m.cryptoKeyPriv.Decrypt(acctInfo.acctKeyEncrypted)
 			if err != nil {
 				Error(err)
@@ -16,6 +16,8 @@ import (
 	"github.com/p9c/pod/pkg/chain/config/netparams"
 	chainhash "github.com/p9c/pod/pkg/chain/hash"
 	"github.com/p9c/pod/pkg/util"
+	ec "github.com/p9c/pod/pkg/util/elliptic"
+	"github.com/p9c/pod/pkg/util/hdkeychain"
 	"github.com/p9c/pod/pkg/util/snacl"
 	waddrmgr "github.com/p9c/pod/pkg/wallet/addrmgr"
 	walletdb "github.com/p9c/pod/pkg/wallet/db"
@@ -2275,3 +2277,170 @@ func TestNewRawAccount(t *testing.T) {
 			accountTargetAddr.AddrHash())
 	}
 }
+
+// TestImportWatchOnly tests that an account imported from an extended public
+// key, an imported public key and an imported address are watch-only, derive
+// the expected addresses and survive unlocking and reopening the manager.
+func TestImportWatchOnly(t *testing.T) {
+	t.Parallel()
+	teardown, db, mgr := setupManager(t)
+	defer teardown()
+	// Derive the account extended public key of another wallet to import,
+	// at m/84'/0'/0'.
+	master, err := hdkeychain.NewMaster(
+		bytes.Repeat([]byte{0x01}, 32), &netparams.MainNetParams,
+	)
+	if err != nil {
+		t.Fatalf("unable to create master key: %v", err)
+	}
+	acctKey := master
+	for _, i := range []uint32{84, 0, 0} {
+		acctKey, err = acctKey.Child(hdkeychain.HardenedKeyStart + i)
+		if err != nil {
+			t.Fatalf("unable to derive account key: %v", err)
+		}
+	}
+	acctKeyPub, err := acctKey.Neuter()
+	if err != nil {
+		t.Fatalf("unable to neuter account key: %v", err)
+	}
+	firstKey, err := acctKeyPub.Child(waddrmgr.ExternalBranch)
+	if err == nil {
+		firstKey, err = firstKey.Child(0)
+	}
+	if err != nil {
+		t.Fatalf("unable to derive first address key: %v", err)
+	}
+	firstPubKey, err := firstKey.ECPubKey()
+	if err != nil {
+		t.Fatalf("unable to get first public key: %v", err)
+	}
+	firstAddr, err := util.NewAddressWitnessPubKeyHash(
+		util.Hash160(firstPubKey.SerializeCompressed()),
+		&netparams.MainNetParams,
+	)
+	if err != nil {
+		t.Fatalf("unable to create first address: %v", err)
+	}
+	importedPubKey, _ := hex.DecodeString("0279be667ef9dcbbac55a06295ce870" +
+		"b07029bfcdb2dce28d959f2815b16f81798")
+	// Public keys imported into the BIP0084 scope get its p2wkh addresses.
+	pubKeyAddr, err := util.NewAddressWitnessPubKeyHash(
+		util.Hash160(importedPubKey), &netparams.MainNetParams,
+	)
+	if err != nil {
+		t.Fatalf("unable to create public key address: %v", err)
+	}
+	watchAddr, err := util.NewAddressScriptHashFromHash(
+		bytes.Repeat([]byte{0x02}, 20), &netparams.MainNetParams,
+	)
+	if err != nil {
+		t.Fatalf("unable to create script address: %v", err)
+	}
+	scopedMgr, err := mgr.FetchScopedKeyManager(waddrmgr.KeyScopeBIP0084)
+	if err != nil {
+		t.Fatalf("unable to fetch scope %v: %v", waddrmgr.KeyScopeBIP0084, err)
+	}
+	// A private extended key must not be importable as a watch-only
+	// account.
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		_, err := scopedMgr.ImportAccount(ns, "private", acctKey)
+		return err
+	})
+	if !waddrmgr.IsError(err, waddrmgr.ErrKeyChain) {
+		t.Fatalf("ImportAccount private key: want ErrKeyChain, got %v", err)
+	}
+	// The manager is locked, which must not matter for watch-only imports.
+	var account uint32
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		account, err = scopedMgr.ImportAccount(ns, "watch", acctKeyPub)
+		if err != nil {
+			return err
+		}
+		addrs, err := scopedMgr.NextExternalAddresses(ns, account, 1)
+		if err != nil {
+			return err
+		}
+		if addrs[0].Address().String() != firstAddr.String() {
+			return fmt.Errorf("first address: want %v, got %v",
+				firstAddr, addrs[0].Address())
+		}
+		pubKey, err := ec.ParsePubKey(importedPubKey, ec.S256())
+		if err != nil {
+			return err
+		}
+		_, err = scopedMgr.ImportPublicKey(
+			ns, pubKey, true, &waddrmgr.BlockStamp{},
+		)
+		if err != nil {
+			return err
+		}
+		_, err = scopedMgr.ImportAddress(ns, watchAddr, &waddrmgr.BlockStamp{})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unable to import: %v", err)
+	}
+	// Unlocking must skip the watch-only account, which has no private key
+	// to decrypt, and the imports must stay watch-only afterwards, also
+	// once reloaded from the database.
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+		return mgr.Unlock(ns, privPassphrase)
+	})
+	if err != nil {
+		t.Fatalf("unable to unlock: %v", err)
+	}
+	mgr.Close()
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+		mgr, err = waddrmgr.Open(ns, pubPassphrase, &netparams.MainNetParams)
+		if err != nil {
+			return err
+		}
+		if err = mgr.Unlock(ns, privPassphrase); err != nil {
+			return err
+		}
+		scopedMgr, err = mgr.FetchScopedKeyManager(waddrmgr.KeyScopeBIP0084)
+		if err != nil {
+			return err
+		}
+		props, err := scopedMgr.AccountProperties(ns, account)
+		if err != nil {
+			return err
+		}
+		if !props.WatchOnly || props.AccountName != "watch" ||
+			props.ExternalKeyCount != 1 {
+			return fmt.Errorf("unexpected account properties %+v", props)
+		}
+		for _, addr := range []util.Address{firstAddr, pubKeyAddr,
+			watchAddr} {
+			ma, err := mgr.Address(ns, addr)
+			if err != nil {
+				return fmt.Errorf("address %v: %v", addr, err)
+			}
+			if !ma.WatchOnly() {
+				return fmt.Errorf("address %v is not watch-only", addr)
+			}
+			if ma.Address().EncodeAddress() != addr.EncodeAddress() {
+				return fmt.Errorf("address: want %v, got %v", addr,
+					ma.Address())
+			}
+			pka, ok := ma.(waddrmgr.ManagedPubKeyAddress)
+			if !ok {
+				continue
+			}
+			_, err = pka.PrivKey()
+			if !waddrmgr.IsError(err, waddrmgr.ErrWatchingOnly) {
+				return fmt.Errorf("PrivKey of %v: want "+
+					"ErrWatchingOnly, got %v", addr, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("watch-only imports: %v", err)
+	}
+}
@@ -193,11 +193,13 @@ func (s *ScopedKeyManager) Close() {
 // keyToManaged returns a new managed address for the provided derived key and
 // its derivation path which consists of the account, branch, and index.
 //
-// The passed derivedKey is zeroed after the new address is created.
+// The passed derivedKey is zeroed after the new address is created.  The
+// watchOnly flag marks addresses of an account imported from an extended public
+// key, whose private keys are never derived.
 //
 // This function MUST be called with the manager lock held for writes.
 func (s *ScopedKeyManager) keyToManaged(derivedKey *hdkeychain.ExtendedKey,
-	account, branch, index uint32) (ManagedAddress, error) {
+	account, branch, index uint32, watchOnly bool) (ManagedAddress, error) {
 	var addrType AddressType
 	if branch == InternalBranch {
 		addrType = s.addrSchema.InternalAddrType
@@ -220,7 +222,8 @@ func (s *ScopedKeyManager) keyToManaged(derivedKey *hdkeychain.ExtendedKey,
 		Error(err)
 		return nil, err
 	}
-	if !derivedKey.IsPrivate() {
+	ma.watchOnly = watchOnly
+	if !derivedKey.IsPrivate() && !watchOnly {
 		// Add the managed address to the list of addresses that need
 		// their private keys derived when the address manager is next
 		// unlocked.
@@ -316,8 +319,12 @@ func (s *ScopedKeyManager) loadAccountInfo(ns walletdb.ReadBucket,
 		acctKeyPub:        acctKeyPub,
 		nextExternalIndex: row.nextExternalIndex,
 		nextInternalIndex: row.nextInternalIndex,
+		watchOnly:         len(row.privKeyEncrypted) == 0,
 	}
-	if !s.rootManager.isLocked() {
+	// Private keys are only available for accounts that were not imported
+	// from an extended public key.
+	private := !s.rootManager.isLocked() && !acctInfo.watchOnly
+	if private {
 		// Use the crypto private key to decrypt the account private
 		// extended keys.
 		decrypted, err := s.rootManager.cryptoKeyPriv.Decrypt(acctInfo.acctKeyEncrypted)
@@ -341,14 +348,14 @@ func (s *ScopedKeyManager) loadAccountInfo(ns walletdb.ReadBucket,
 	if index > 0 {
 		index--
 	}
-	lastExtKey, err := s.deriveKey(
-		acctInfo, branch, index, !s.rootManager.isLocked(),
-	)
+	lastExtKey, err := s.deriveKey(acctInfo, branch, index, private)
 	if err != nil {
 		Error(err)
 		return nil, err
 	}
-	lastExtAddr, err := s.keyToManaged(lastExtKey, account, branch, index)
+	lastExtAddr, err := s.keyToManaged(
+		lastExtKey, account, branch, index, acctInfo.watchOnly,
+	)
 	if err != nil {
 		Error(err)
 		return nil, err
@@ -359,14 +366,14 @@ func (s *ScopedKeyManager) loadAccountInfo(ns walletdb.ReadBucket,
 	if index > 0 {
 		index--
 	}
-	lastIntKey, err := s.deriveKey(
-		acctInfo, branch, index, !s.rootManager.isLocked(),
-	)
+	lastIntKey, err := s.deriveKey(acctInfo, branch, index, private)
 	if err != nil {
 		Error(err)
 		return nil, err
 	}
-	lastIntAddr, err := s.keyToManaged(lastIntKey, account, branch, index)
+	lastIntAddr, err := s.keyToManaged(
+		lastIntKey, account, branch, index, acctInfo.watchOnly,
+	)
 	if err != nil {
 		Error(err)
 		return nil, err
@@ -404,6 +411,7 @@ func (s *ScopedKeyManager) AccountProperties(ns walletdb.ReadBucket,
 		props.AccountName = acctInfo.acctName
 		props.ExternalKeyCount = acctInfo.nextExternalIndex
 		props.InternalKeyCount = acctInfo.nextInternalIndex
+		props.WatchOnly = acctInfo.watchOnly
 	} else {
 		props.AccountName = ImportedAddrAccountName // reserved, nonchangable
 		// Could be more efficient if this was tracked by the db.
@@ -429,14 +437,22 @@ func (s *ScopedKeyManager) DeriveFromKeyPath(ns walletdb.ReadBucket,
 	kp DerivationPath) (ManagedAddress, error) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
-	extKey, err := s.deriveKeyFromPath(
-		ns, kp.Account, kp.Branch, kp.Index, !s.rootManager.IsLocked(),
+	acctInfo, err := s.loadAccountInfo(ns, kp.Account)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	extKey, err := s.deriveKey(
+		acctInfo, kp.Branch, kp.Index,
+		!s.rootManager.IsLocked() && !acctInfo.watchOnly,
 	)
 	if err != nil {
 		Error(err)
 		return nil, err
 	}
-	return s.keyToManaged(extKey, kp.Account, kp.Branch, kp.Index)
+	return s.keyToManaged(
+		extKey, kp.Account, kp.Branch, kp.Index, acctInfo.watchOnly,
+	)
 }
 
 // deriveKeyFromPath returns either a public or private derived extended key
@@ -463,14 +479,21 @@ func (s *ScopedKeyManager) chainAddressRowToManaged(ns walletdb.ReadBucket,
 	// Since the manger's mutex is assumed to held when invoking this
 	// function, we use the internal isLocked to avoid a deadlock.
 	isLocked := s.rootManager.isLocked()
-	addressKey, err := s.deriveKeyFromPath(
-		ns, row.account, row.branch, row.index, !isLocked,
+	acctInfo, err := s.loadAccountInfo(ns, row.account)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	addressKey, err := s.deriveKey(
+		acctInfo, row.branch, row.index, !isLocked && !acctInfo.watchOnly,
 	)
 	if err != nil {
 		Error(err)
 		return nil, err
 	}
-	return s.keyToManaged(addressKey, row.account, row.branch, row.index)
+	return s.keyToManaged(
+		addressKey, row.account, row.branch, row.index, acctInfo.watchOnly,
+	)
 }
 
 // importedAddressRowToManaged returns a new managed address based on imported
@@ -505,6 +528,7 @@ func (s *ScopedKeyManager) importedAddressRowToManaged(row *dbImportedAddressRow
 	}
 	ma.privKeyEncrypted = row.encryptedPrivKey
 	ma.imported = true
+	ma.watchOnly = len(row.encryptedPrivKey) == 0
 	return ma, nil
 }
 
@@ -521,6 +545,27 @@ func (s *ScopedKeyManager) scriptAddressRowToManaged(row *dbScriptAddressRow) (M
 	return newScriptAddress(s, row.account, scriptHash, row.encryptedScript)
 }
 
+// watchAddressRowToManaged returns a new managed address based on watch
+// address data loaded from the database.
+func (s *ScopedKeyManager) watchAddressRowToManaged(row *dbWatchAddressRow) (ManagedAddress, error) {
+	// Use the crypto public key to decrypt the imported address.
+	encodedAddr, err := s.rootManager.cryptoKeyPub.Decrypt(row.encryptedAddr)
+	if err != nil {
+		Error(err)
+		str := "failed to decrypt imported address"
+		return nil, managerError(ErrCrypto, str, err)
+	}
+	address, err := util.DecodeAddress(
+		string(encodedAddr), s.rootManager.chainParams,
+	)
+	if err != nil {
+		Error(err)
+		str := "invalid imported address"
+		return nil, managerError(ErrDatabase, str, err)
+	}
+	return newWatchAddress(s, row.account, address), nil
+}
+
 // rowInterfaceToManaged returns a new managed address based on the given
 // address data loaded from the database.  It will automatically select the
 // appropriate type.
@@ -535,6 +580,8 @@ func (s *ScopedKeyManager) rowInterfaceToManaged(ns walletdb.ReadBucket,
 		return s.importedAddressRowToManaged(row)
 	case *dbScriptAddressRow:
 		return s.scriptAddressRowToManaged(row)
+	case *dbWatchAddressRow:
+		return s.watchAddressRowToManaged(row)
 	}
 	str := fmt.Sprintf("unsupported address type %T", rowInterface)
 	return nil, managerError(ErrDatabase, str, nil)
@@ -638,9 +685,9 @@ func (s *ScopedKeyManager) nextAddresses(ns walletdb.ReadWriteBucket,
 		return nil, err
 	}
 	// Choose the account key to used based on whether the address manager
-	// is locked.
+	// is locked and the account has a private key.
 	acctKey := acctInfo.acctKeyPub
-	if !s.rootManager.IsLocked() {
+	if !s.rootManager.IsLocked() && !acctInfo.watchOnly {
 		acctKey = acctInfo.acctKeyPriv
 	}
 	// Choose the branch key and index depending on whether or not this is
@@ -721,6 +768,7 @@ func (s *ScopedKeyManager) nextAddresses(ns walletdb.ReadWriteBucket,
 		if internal {
 			addr.internal = true
 		}
+		addr.watchOnly = acctInfo.watchOnly
 		managedAddr := addr
 		nextKey.Zero()
 		info := unlockDeriveInfo{
@@ -773,7 +821,8 @@ func (s *ScopedKeyManager) nextAddresses(ns walletdb.ReadWriteBucket,
 		// Add the new managed address to the list of addresses that
 		// need their private keys derived when the address manager is
 		// next unlocked.
-		if s.rootManager.IsLocked() && !s.rootManager.WatchOnly() {
+		if s.rootManager.IsLocked() && !s.rootManager.WatchOnly() &&
+			!acctInfo.watchOnly {
 			s.deriveOnUnlock = append(s.deriveOnUnlock, info)
 		}
 		managedAddresses = append(managedAddresses, ma)
@@ -807,9 +856,9 @@ func (s *ScopedKeyManager) extendAddresses(ns walletdb.ReadWriteBucket,
 		return err
 	}
 	// Choose the account key to used based on whether the address manager
-	// is locked.
+	// is locked and the account has a private key.
 	acctKey := acctInfo.acctKeyPub
-	if !s.rootManager.IsLocked() {
+	if !s.rootManager.IsLocked() && !acctInfo.watchOnly {
 		acctKey = acctInfo.acctKeyPriv
 	}
 	// Choose the branch key and index depending on whether or not this is
@@ -896,6 +945,7 @@ func (s *ScopedKeyManager) extendAddresses(ns walletdb.ReadWriteBucket,
 		if internal {
 			addr.internal = true
 		}
+		addr.watchOnly = acctInfo.watchOnly
 		managedAddr := addr
 		nextKey.Zero()
 		info := unlockDeriveInfo{
@@ -947,7 +997,8 @@ func (s *ScopedKeyManager) extendAddresses(ns walletdb.ReadWriteBucket,
 		// Add the new managed address to the list of addresses that
 		// need their private keys derived when the address manager is
 		// next unlocked.
-		if s.rootManager.IsLocked() && !s.rootManager.WatchOnly() {
+		if s.rootManager.IsLocked() && !s.rootManager.WatchOnly() &&
+			!acctInfo.watchOnly {
 			s.deriveOnUnlock = append(s.deriveOnUnlock, info)
 		}
 	}
@@ -1214,6 +1265,82 @@ func (s *ScopedKeyManager) newAccount(ns walletdb.ReadWriteBucket,
 	return putLastAccount(ns, &s.scope, account)
 }
 
+// ImportAccount creates a new watch-only account named name from the extended
+// public key of a BIP0044-like account, at depth 3 of the m/purpose'/coin_type'/
+// account' path of this manager's key scope, and returns its account number.
+// Addresses of the account are derived from acctKeyPub as usual, but since no
+// private key is known the account can only be used to watch for funds, so the
+// manager does not need to be unlocked.  If an account with the same name
+// already exists, ErrDuplicateAccount will be returned.
+func (s *ScopedKeyManager) ImportAccount(ns walletdb.ReadWriteBucket,
+	name string, acctKeyPub *hdkeychain.ExtendedKey) (uint32, error) {
+	if acctKeyPub.IsPrivate() {
+		str := "extended key to import as a watch-only account must be public"
+		return 0, managerError(ErrKeyChain, str, nil)
+	}
+	// Ensure the key is intended for network the address manager is
+	// associated with.
+	if !acctKeyPub.IsForNet(s.rootManager.chainParams) {
+		str := fmt.Sprintf("extended public key is not for the same "+
+			"network the address manager is configured for (%s)",
+			s.rootManager.chainParams.Name)
+		return 0, managerError(ErrWrongNet, str, nil)
+	}
+	if acctKeyPub.Depth() != 3 {
+		str := fmt.Sprintf("extended public key has depth %d, but an "+
+			"account key has depth 3", acctKeyPub.Depth())
+		return 0, managerError(ErrKeyChain, str, nil)
+	}
+	// Ensure the branches needed for the external and internal addresses
+	// can be derived.
+	if err := checkBranchKeys(acctKeyPub); err != nil {
+		Error(err)
+		str := "failed to derive branches of account extended public key"
+		return 0, managerError(ErrKeyChain, str, err)
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	// Validate the account name.
+	if err := ValidateAccountName(name); err != nil {
+		return 0, err
+	}
+	// Check that account with the same name does not exist
+	if _, err := s.lookupAccount(ns, name); err == nil {
+		str := fmt.Sprintf("account with the same name already exists")
+		return 0, managerError(ErrDuplicateAccount, str, err)
+	}
+	account, err := fetchLastAccount(ns, &s.scope)
+	if err != nil {
+		Error(err)
+		return 0, err
+	}
+	account++
+	if account > MaxAccountNum {
+		str := fmt.Sprintf("account number %d would exceed the maximum "+
+			"allowed account number of %d", account, MaxAccountNum)
+		return 0, managerError(ErrAccountNumTooHigh, str, nil)
+	}
+	// Only the public key is stored, so the account is loaded as watch-only.
+	acctPubEnc, err := s.rootManager.cryptoKeyPub.Encrypt(
+		[]byte(acctKeyPub.String()),
+	)
+	if err != nil {
+		Error(err)
+		str := "failed to encrypt public key for account"
+		return 0, managerError(ErrCrypto, str, err)
+	}
+	err = putAccountInfo(ns, &s.scope, account, acctPubEnc, nil, 0, 0, name)
+	if err != nil {
+		Error(err)
+		return 0, err
+	}
+	if err = putLastAccount(ns, &s.scope, account); err != nil {
+		Error(err)
+		return 0, err
+	}
+	return account, nil
+}
+
 // RenameAccount renames an account stored in the manager based on the given
 // account number with the given name.  If an account with the same name
 // already exists, ErrDuplicateAccount will be returned.
@@ -1299,14 +1426,43 @@ func (s *ScopedKeyManager) ImportPrivateKey(ns walletdb.ReadWriteBucket,
 			s.rootManager.chainParams.Name)
 		return nil, managerError(ErrWrongNet, str, nil)
 	}
+	pubKey := (*ec.PublicKey)(&wif.PrivKey.PublicKey)
+	return s.importPublicKey(ns, pubKey, wif.CompressPubKey, wif.PrivKey, bs)
+}
+
+// ImportPublicKey imports a public key into the address manager as a watch-only
+// address, created using either the compressed or uncompressed serialized
+// public key.  As no private key is known, funds sent to it can be watched but
+// not spent, so the manager does not need to be unlocked.
+//
+// All imported addresses will be part of the account defined by the
+// ImportedAddrAccount constant.
+//
+// This function will return an error if the address already exists.  Any other
+// errors returned are generally unexpected.
+func (s *ScopedKeyManager) ImportPublicKey(ns walletdb.ReadWriteBucket,
+	pubKey *ec.PublicKey, compressed bool,
+	bs *BlockStamp) (ManagedPubKeyAddress, error) {
+	return s.importPublicKey(ns, pubKey, compressed, nil, bs)
+}
+
+// importPublicKey imports the address of a public key and, unless it is nil or
+// the address manager is watching-only, its private key.
+func (s *ScopedKeyManager) importPublicKey(ns walletdb.ReadWriteBucket,
+	pubKey *ec.PublicKey, compressed bool, privKey *ec.PrivateKey,
+	bs *BlockStamp) (ManagedPubKeyAddress, error) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 	// The manager must be unlocked to encrypt the imported private key.
-	if s.rootManager.IsLocked() && !s.rootManager.WatchOnly() {
+	withPrivKey := privKey != nil && !s.rootManager.WatchOnly()
+	if withPrivKey && s.rootManager.IsLocked() {
 		return nil, managerError(ErrLocked, errLocked, nil)
 	}
 	// Prevent duplicates.
-	serializedPubKey := wif.SerializePubKey()
+	serializedPubKey := pubKey.SerializeUncompressed()
+	if compressed {
+		serializedPubKey = pubKey.SerializeCompressed()
+	}
 	pubKeyHash := util.Hash160(serializedPubKey)
 	alreadyExists := s.existsAddress(ns, pubKeyHash)
 	if alreadyExists {
@@ -1324,10 +1480,11 @@ func (s *ScopedKeyManager) ImportPrivateKey(ns walletdb.ReadWriteBucket,
 			serializedPubKey)
 		return nil, managerError(ErrCrypto, str, err)
 	}
-	// Encrypt the private key when not a watching-only address manager.
+	// Encrypt the private key when there is one and this is not a
+	// watching-only address manager.
 	var encryptedPrivKey []byte
-	if !s.rootManager.WatchOnly() {
-		privKeyBytes := wif.PrivKey.Serialize()
+	if withPrivKey {
+		privKeyBytes := privKey.Serialize()
 		encryptedPrivKey, err = s.rootManager.cryptoKeyPriv.Encrypt(privKeyBytes)
 		zero.Bytes(privKeyBytes)
 		if err != nil {
@@ -1337,11 +1494,6 @@ func (s *ScopedKeyManager) ImportPrivateKey(ns walletdb.ReadWriteBucket,
 			return nil, managerError(ErrCrypto, str, err)
 		}
 	}
-	// The start block needs to be updated when the newly imported address
-	// is before the current one.
-	s.rootManager.mtx.Lock()
-	updateStartBlock := bs.Height < s.rootManager.syncState.startBlock.Height
-	s.rootManager.mtx.Unlock()
 	// Save the new imported address to the db and update start block (if
 	// needed) in a single transaction.
 	err = putImportedAddress(
@@ -1352,19 +1504,9 @@ func (s *ScopedKeyManager) ImportPrivateKey(ns walletdb.ReadWriteBucket,
 		Error(err)
 		return nil, err
 	}
-	if updateStartBlock {
-		err := putStartBlock(ns, bs)
-		if err != nil {
-			Error(err)
-			return nil, err
-		}
-	}
-	// Now that the database has been updated, update the start block in
-	// memory too if needed.
-	if updateStartBlock {
-		s.rootManager.mtx.Lock()
-		s.rootManager.syncState.startBlock = *bs
-		s.rootManager.mtx.Unlock()
+	if err = s.updateStartBlock(ns, bs); err != nil {
+		Error(err)
+		return nil, err
 	}
 	// The full derivation path for an imported key is incomplete as we
 	// don't know exactly how it was derived.
@@ -1373,15 +1515,14 @@ func (s *ScopedKeyManager) ImportPrivateKey(ns walletdb.ReadWriteBucket,
 	}
 	// Create a new managed address based on the imported address.
 	var managedAddr *managedAddress
-	if !s.rootManager.WatchOnly() {
+	if withPrivKey {
 		managedAddr, err = newManagedAddress(
-			s, importedDerivationPath, wif.PrivKey,
-			wif.CompressPubKey, s.addrSchema.ExternalAddrType,
+			s, importedDerivationPath, privKey,
+			compressed, s.addrSchema.ExternalAddrType,
 		)
 	} else {
-		pubKey := (*ec.PublicKey)(&wif.PrivKey.PublicKey)
 		managedAddr, err = newManagedAddressWithoutPrivKey(
-			s, importedDerivationPath, pubKey, wif.CompressPubKey,
+			s, importedDerivationPath, pubKey, compressed,
 			s.addrSchema.ExternalAddrType,
 		)
 	}
@@ -1390,12 +1531,38 @@ func (s *ScopedKeyManager) ImportPrivateKey(ns walletdb.ReadWriteBucket,
 		return nil, err
 	}
 	managedAddr.imported = true
+	managedAddr.watchOnly = privKey == nil
 	// Add the new managed address to the cache of recent addresses and
 	// return it.
 	s.addrs[addrKey(managedAddr.Address().ScriptAddress())] = managedAddr
 	return managedAddr, nil
 }
 
+// updateStartBlock sets the start block of the address manager to bs when an
+// address imported as of bs is older than it, so that rescans from the start
+// block find the address' transactions.
+//
+// This function MUST be called with the manager lock held for writes.
+func (s *ScopedKeyManager) updateStartBlock(ns walletdb.ReadWriteBucket,
+	bs *BlockStamp) error {
+	s.rootManager.mtx.Lock()
+	update := bs.Height < s.rootManager.syncState.startBlock.Height
+	s.rootManager.mtx.Unlock()
+	if !update {
+		return nil
+	}
+	if err := putStartBlock(ns, bs); err != nil {
+		Error(err)
+		return err
+	}
+	// Now that the database has been updated, update the start block in
+	// memory too.
+	s.rootManager.mtx.Lock()
+	s.rootManager.syncState.startBlock = *bs
+	s.rootManager.mtx.Unlock()
+	return nil
+}
+
 // ImportScript imports a user-provided script into the address manager.  The
 // imported script will act as a pay-to-script-hash address.
 //
@@ -1500,6 +1667,66 @@ func (s *ScopedKeyManager) ImportScript(ns walletdb.ReadWriteBucket,
 	return scriptAddr, nil
 }
 
+// ImportAddress imports an address into the address manager without its public
+// key or script, so that funds sent to it can be watched but not spent.  Pay to
+// public key addresses should be imported with ImportPublicKey instead.
+//
+// All imported addresses will be part of the account defined by the
+// ImportedAddrAccount constant.
+//
+// This function will return an error if the address is not for the network of
+// the address manager or already exists.  Any other errors returned are
+// generally unexpected.
+func (s *ScopedKeyManager) ImportAddress(ns walletdb.ReadWriteBucket,
+	address util.Address, bs *BlockStamp) (ManagedAddress, error) {
+	// Ensure the address is intended for network the address manager is
+	// associated with.
+	if !address.IsForNet(s.rootManager.chainParams) {
+		str := fmt.Sprintf("address is not for the same network the "+
+			"address manager is configured for (%s)",
+			s.rootManager.chainParams.Name)
+		return nil, managerError(ErrWrongNet, str, nil)
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	// Prevent duplicates.
+	addressID := address.ScriptAddress()
+	if s.existsAddress(ns, addressID) {
+		str := fmt.Sprintf("address %s already exists",
+			address.EncodeAddress())
+		return nil, managerError(ErrDuplicateAddress, str, nil)
+	}
+	// Encrypt the encoded address, which is all that is known about it.
+	encryptedAddr, err := s.rootManager.cryptoKeyPub.Encrypt(
+		[]byte(address.EncodeAddress()),
+	)
+	if err != nil {
+		Error(err)
+		str := fmt.Sprintf("failed to encrypt address %s",
+			address.EncodeAddress())
+		return nil, managerError(ErrCrypto, str, err)
+	}
+	// Save the new imported address to the db and update start block (if
+	// needed) in a single transaction.
+	err = putWatchAddress(
+		ns, &s.scope, addressID, ImportedAddrAccount, ssNone,
+		encryptedAddr,
+	)
+	if err != nil {
+		Error(err)
+		return nil, maybeConvertDbError(err)
+	}
+	if err = s.updateStartBlock(ns, bs); err != nil {
+		Error(err)
+		return nil, err
+	}
+	// Add the new managed address to the cache of recent addresses and
+	// return it.
+	watchAddr := newWatchAddress(s, ImportedAddrAccount, address)
+	s.addrs[addrKey(addressID)] = watchAddr
+	return watchAddr, nil
+}
+
 // lookupAccount loads account number stored in the manager for the given
 // account name
 //
@@ -0,0 +1,334 @@
+package wallet
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	"github.com/p9c/pod/pkg/util"
+	waddrmgr "github.com/p9c/pod/pkg/wallet/addrmgr"
+	walletdb "github.com/p9c/pod/pkg/wallet/db"
+)
+
+// BackupWallet writes a copy of the wallet database to destination, or to a
+// file named like the database inside it when destination is a directory. The
+// copy is taken in a single read transaction, so it is consistent while the
+// wallet keeps running, and it only replaces destination once it is complete.
+func (w *Wallet) BackupWallet(destination string) (err error) {
+	if fi, err := os.Stat(destination); err == nil && fi.IsDir() {
+		destination = filepath.Join(destination, WalletDbName)
+	}
+	if w.PodConfig != nil && w.PodConfig.WalletFile != nil {
+		walletFile, _ := filepath.Abs(*w.PodConfig.WalletFile)
+		if dest, _ := filepath.Abs(destination); dest == walletFile {
+			return errors.New("the backup cannot replace the wallet database")
+		}
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(destination),
+		filepath.Base(destination)+".tmp")
+	if err != nil {
+		Error(err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if err = w.db.Copy(tmp); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), destination)
+	}
+	if err != nil {
+		Error(err)
+		return err
+	}
+	Info("backed up wallet to", destination)
+	return nil
+}
+
+// DumpWallet writes the private keys and redeem scripts of the wallet to out as
+// text, one per line, labeled with the account they belong to and the
+// derivation path of keys derived from the wallet seed. Keys of watch-only
+// addresses and accounts are not held by the wallet and are left out. The
+// wallet must be unlocked, and is kept from locking until the dump is written.
+func (w *Wallet) DumpWallet(out io.Writer) error {
+	heldUnlock, err := w.holdUnlock()
+	if err != nil {
+		Error(err)
+		return err
+	}
+	defer heldUnlock.release()
+	birthday := w.Manager.Birthday().UTC().Format(time.RFC3339)
+	// The keys and scripts are only taken out of the addresses once they are
+	// all listed, as that needs the lock of the key manager the listing
+	// holds.
+	var addrs []waddrmgr.ManagedAddress
+	var accounts []string
+	err = walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		for _, manager := range w.Manager.ActiveScopedKeyManagers() {
+			err := manager.ForEachAccount(addrmgrNs, func(account uint32) error {
+				name, err := manager.AccountName(addrmgrNs, account)
+				if err != nil {
+					Error(err)
+					return err
+				}
+				return manager.ForEachAccountAddress(addrmgrNs, account,
+					func(ma waddrmgr.ManagedAddress) error {
+						addrs = append(addrs, ma)
+						accounts = append(accounts, name)
+						return nil
+					},
+				)
+			})
+			if err != nil {
+				Error(err)
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		Error(err)
+		return err
+	}
+	var lines []string
+	for i, ma := range addrs {
+		line, err := dumpLine(ma, accounts[i], birthday)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	syncedTo := w.Manager.SyncedTo()
+	bw := bufio.NewWriter(out)
+	fmt.Fprintf(bw, "# Wallet dump created by pod\n")
+	fmt.Fprintf(bw, "# * Created on %s\n", time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(bw, "# * Best block at time of backup was %d (%s)\n",
+		syncedTo.Height, syncedTo.Hash)
+	fmt.Fprintf(bw, "#\n# Keys of watch-only addresses and accounts are not included.\n\n")
+	for _, line := range lines {
+		fmt.Fprintln(bw, line)
+	}
+	fmt.Fprintf(bw, "\n# End of dump\n")
+	return bw.Flush()
+}
+
+// dumpLine returns the line of a wallet dump for an address of the named
+// account, or an empty string when the wallet holds no key or script for it.
+func dumpLine(ma waddrmgr.ManagedAddress, account,
+	birthday string) (string, error) {
+	if ma.WatchOnly() {
+		return "", nil
+	}
+	var key, kind, path string
+	switch a := ma.(type) {
+	case waddrmgr.ManagedPubKeyAddress:
+		wif, err := a.ExportPrivKey()
+		if err != nil {
+			Error(err)
+			return "", err
+		}
+		key = wif.String()
+		if scope, dp, ok := a.DerivationInfo(); ok && !a.Imported() {
+			path = fmt.Sprintf(" hdkeypath=m/%d'/%d'/%d'/%d/%d",
+				scope.Purpose, scope.Coin, dp.Account, dp.Branch, dp.Index)
+		}
+	case waddrmgr.ManagedScriptAddress:
+		script, err := a.Script()
+		if err != nil {
+			Error(err)
+			return "", err
+		}
+		key, kind = hex.EncodeToString(script), " script=1"
+	default:
+		return "", nil
+	}
+	label := "label=" + encodeDumpString(account)
+	if ma.Internal() {
+		label = "change=1"
+	}
+	return fmt.Sprintf("%s %s %s%s # addr=%s%s", key, birthday, label, kind,
+		ma.Address().EncodeAddress(), path), nil
+}
+
+// encodeDumpString escapes the characters of a label that would break up the
+// line of a wallet dump, the same way bitcoind does.
+func encodeDumpString(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if c <= 32 || c >= 128 || c == '%' {
+			fmt.Fprintf(&b, "%%%02x", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// dumpEntry is a private key or redeem script read from a wallet dump.
+type dumpEntry struct {
+	wif    *util.WIF
+	script []byte
+	// addr is the address given for the entry, if any.
+	addr util.Address
+}
+
+// scope returns the key scope an entry is imported to, which is the one
+// creating addresses of the type given for it. Scripts are imported to the
+// same scope as by ImportP2SHRedeemScript.
+func (e *dumpEntry) scope() waddrmgr.KeyScope {
+	if e.script != nil {
+		return waddrmgr.KeyScopeBIP0084
+	}
+	switch e.addr.(type) {
+	case *util.AddressWitnessPubKeyHash:
+		return waddrmgr.KeyScopeBIP0084
+	case *util.AddressScriptHash:
+		return waddrmgr.KeyScopeBIP0049Plus
+	}
+	return waddrmgr.KeyScopeBIP0044
+}
+
+// readWalletDump reads the private keys and redeem scripts of a wallet dump,
+// as written by DumpWallet or bitcoind.
+func readWalletDump(r io.Reader, params *netparams.Params) ([]dumpEntry,
+	error) {
+	var entries []dumpEntry
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var comment string
+		if i := strings.Index(text, "#"); i >= 0 {
+			text, comment = text[:i], text[i+1:]
+		}
+		fields := strings.Fields(text)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d of the wallet dump is "+
+				"malformed", line)
+		}
+		var entry dumpEntry
+		for _, field := range strings.Fields(comment) {
+			if !strings.HasPrefix(field, "addr=") {
+				continue
+			}
+			addr, err := util.DecodeAddress(field[len("addr="):], params)
+			if err == nil {
+				entry.addr = addr
+			}
+		}
+		var err error
+		if hasDumpFlag(fields[2:], "script=1") {
+			entry.script, err = hex.DecodeString(fields[0])
+		} else {
+			entry.wif, err = util.DecodeWIF(fields[0])
+		}
+		if err != nil {
+			Error(err)
+			return nil, fmt.Errorf("line %d of the wallet dump: %v",
+				line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// hasDumpFlag returns whether flag is among the fields of a wallet dump line.
+func hasDumpFlag(fields []string, flag string) bool {
+	for _, field := range fields {
+		if field == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportWallet imports the private keys and redeem scripts of a wallet dump read
+// from in to the imported accounts, and returns how many were new to the wallet.
+// Keys the wallet already has, such as the ones derived from its own seed, are
+// skipped. The chain is then rescanned from the genesis block for payments to
+// the new addresses in the background.
+func (w *Wallet) ImportWallet(in io.Reader) (int, error) {
+	entries, err := readWalletDump(in, w.chainParams)
+	if err != nil {
+		Error(err)
+		return 0, err
+	}
+	bs, newBirthday := w.importBirthday(nil)
+	var addrs []util.Address
+	var props []*waddrmgr.AccountProperties
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		managers := make(map[waddrmgr.KeyScope]*waddrmgr.ScopedKeyManager)
+		for i := range entries {
+			entry := &entries[i]
+			manager, err := w.Manager.FetchScopedKeyManager(entry.scope())
+			if err != nil {
+				Error(err)
+				return err
+			}
+			var maddr waddrmgr.ManagedAddress
+			if entry.script != nil {
+				maddr, err = manager.ImportScript(addrmgrNs, entry.script, bs)
+			} else {
+				maddr, err = manager.ImportPrivateKey(addrmgrNs, entry.wif, bs)
+			}
+			switch {
+			case waddrmgr.IsError(err, waddrmgr.ErrDuplicateAddress):
+				continue
+			case err != nil:
+				Error(err)
+				return err
+			}
+			managers[entry.scope()] = manager
+			addrs = append(addrs, maddr.Address())
+		}
+		for _, manager := range managers {
+			acctProps, err := manager.AccountProperties(
+				addrmgrNs, waddrmgr.ImportedAddrAccount,
+			)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			props = append(props, acctProps)
+		}
+		return w.Manager.SetBirthday(addrmgrNs, newBirthday)
+	})
+	if err != nil {
+		Error(err)
+		return 0, err
+	}
+	if len(addrs) != 0 {
+		if err = w.watchAddresses(addrs, bs, true); err != nil {
+			Error(err)
+			return 0, err
+		}
+	}
+	Infof("imported %d of %d keys and scripts of a wallet dump",
+		len(addrs), len(entries))
+	for _, acctProps := range props {
+		w.NtfnServer.notifyAccountProperties(acctProps)
+	}
+	return len(addrs), nil
+}
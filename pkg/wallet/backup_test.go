@@ -0,0 +1,317 @@
+package wallet
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	"github.com/p9c/pod/pkg/util"
+	ec "github.com/p9c/pod/pkg/util/elliptic"
+	waddrmgr "github.com/p9c/pod/pkg/wallet/addrmgr"
+	walletdb "github.com/p9c/pod/pkg/wallet/db"
+	_ "github.com/p9c/pod/pkg/wallet/db/bdb"
+)
+
+var (
+	testPubPass  = []byte("public")
+	testPrivPass = []byte("private")
+)
+
+// testWallet returns a started and unlocked wallet on the test network,
+// created from seed in a temporary directory, and a function that stops it
+// and removes the directory. The wallet has no chain client, so its rescan
+// jobs are answered as done without rescanning anything.
+func testWallet(t *testing.T, seed []byte) (*Wallet, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "wallettest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := walletdb.Create("bdb", filepath.Join(dir, WalletDbName))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("unable to create wallet database: %v", err)
+	}
+	params := &netparams.TestNet3Params
+	err = Create(db, testPubPass, testPrivPass, seed, params, time.Now())
+	if err != nil {
+		db.Close()
+		os.RemoveAll(dir)
+		t.Fatalf("unable to create wallet: %v", err)
+	}
+	w, err := Open(db, testPubPass, nil, params, 0, nil)
+	if err != nil {
+		db.Close()
+		os.RemoveAll(dir)
+		t.Fatalf("unable to open wallet: %v", err)
+	}
+	w.Start()
+	quit := w.quitChan()
+	go func() {
+		for {
+			select {
+			case job := <-w.rescanAddJob:
+				job.err <- nil
+			case <-quit:
+				return
+			}
+		}
+	}()
+	if err = w.Unlock(testPrivPass, nil); err != nil {
+		t.Fatalf("unable to unlock wallet: %v", err)
+	}
+	return w, func() {
+		w.Stop()
+		w.WaitForShutdown()
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// TestEncodeDumpString ensures the characters of labels that would break up
+// the line of a wallet dump are escaped the way bitcoind escapes them.
+func TestEncodeDumpString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"savings", "savings"},
+		{"my savings", "my%20savings"},
+		{"100%", "100%25"},
+		{"tab\there\nnewline", "tab%09here%0anewline"},
+		{"#hash=kept", "#hash=kept"},
+		{"caf\u00e9", "caf%c3%a9"},
+		{"\x7f\x80", "\x7f%80"},
+	}
+	for _, test := range tests {
+		if got := encodeDumpString(test.in); got != test.want {
+			t.Errorf("%q: got %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+// TestReadWalletDump ensures the keys and scripts of wallet dumps are read
+// along with the addresses given for them, and that malformed lines are
+// refused.
+func TestReadWalletDump(t *testing.T) {
+	params := &netparams.TestNet3Params
+	key, err := ec.NewPrivateKey(ec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wif, err := util.NewWIF(key, params, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkh, err := util.NewAddressPubKeyHash(
+		util.Hash160(key.PubKey().SerializeCompressed()), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wpkh, err := util.NewAddressWitnessPubKeyHash(
+		util.Hash160(key.PubKey().SerializeCompressed()), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := []byte{0x51}
+	sh, err := util.NewAddressScriptHash(script, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump := strings.Join([]string{
+		"# Wallet dump created by bitcoind",
+		"",
+		wif.String() + " 2020-01-01T00:00:00Z label=my%20savings # addr=" +
+			pkh.EncodeAddress() + " hdkeypath=m/44'/1'/0'/0/0",
+		"  " + wif.String() + " 2020-01-01T00:00:00Z change=1 # addr=" +
+			wpkh.EncodeAddress(),
+		"51 0 script=1 # addr=" + sh.EncodeAddress(),
+		wif.String() + " 0 reserve=1",
+		wif.String() + " 0 # addr=not-an-address",
+		"# End of dump",
+	}, "\n")
+	entries, err := readWalletDump(strings.NewReader(dump), params)
+	if err != nil {
+		t.Fatalf("readWalletDump: %v", err)
+	}
+	tests := []struct {
+		name   string
+		script []byte
+		addr   util.Address
+		scope  waddrmgr.KeyScope
+	}{
+		{"labeled key", nil, pkh, waddrmgr.KeyScopeBIP0044},
+		{"change key", nil, wpkh, waddrmgr.KeyScopeBIP0084},
+		{"script", script, sh, waddrmgr.KeyScopeBIP0084},
+		{"key without address", nil, nil, waddrmgr.KeyScopeBIP0044},
+		{"key with a bad address", nil, nil, waddrmgr.KeyScopeBIP0044},
+	}
+	if len(entries) != len(tests) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(tests))
+	}
+	for i, test := range tests {
+		entry := &entries[i]
+		if test.script != nil {
+			if entry.wif != nil || !bytes.Equal(entry.script, test.script) {
+				t.Errorf("%s: got script %x, want %x", test.name,
+					entry.script, test.script)
+			}
+		} else if entry.wif == nil || entry.wif.String() != wif.String() {
+			t.Errorf("%s: got key %v, want %v", test.name, entry.wif, wif)
+		}
+		switch {
+		case test.addr == nil && entry.addr != nil:
+			t.Errorf("%s: got address %v, want none", test.name, entry.addr)
+		case test.addr != nil && (entry.addr == nil ||
+			entry.addr.EncodeAddress() != test.addr.EncodeAddress()):
+			t.Errorf("%s: got address %v, want %v", test.name, entry.addr,
+				test.addr)
+		}
+		if got := entry.scope(); got != test.scope {
+			t.Errorf("%s: got scope %v, want %v", test.name, got, test.scope)
+		}
+	}
+	malformed := []struct {
+		name string
+		dump string
+		want string
+	}{
+		{"key alone", wif.String(), "line 1 of the wallet dump is malformed"},
+		{"bad key", "# header\nnotakey 0 label=", "line 2 of the wallet dump"},
+		{"bad script", "zz 0 script=1", "line 1 of the wallet dump"},
+	}
+	for _, test := range malformed {
+		_, err := readWalletDump(strings.NewReader(test.dump), params)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %v, want one containing %q", test.name,
+				err, test.want)
+		}
+	}
+}
+
+// TestDumpImportWallet ensures the keys and scripts written by DumpWallet are
+// imported by ImportWallet into another wallet, with the labels of accounts
+// escaped in the dump, and that watch-only addresses are left out of it.
+func TestDumpImportWallet(t *testing.T) {
+	src, stopSrc := testWallet(t, bytes.Repeat([]byte{0x01}, 32))
+	defer stopSrc()
+	scope := waddrmgr.KeyScopeBIP0044
+	var want []util.Address
+	addr, err := src.NewAddress(waddrmgr.DefaultAccountNum, scope, true)
+	if err != nil {
+		t.Fatalf("NewAddress: %v", err)
+	}
+	want = append(want, addr)
+	const label = "my savings 100%"
+	account, err := src.NextAccount(scope, label)
+	if err != nil {
+		t.Fatalf("NextAccount: %v", err)
+	}
+	if addr, err = src.NewAddress(account, scope, true); err != nil {
+		t.Fatalf("NewAddress: %v", err)
+	}
+	want = append(want, addr)
+	err = walletdb.Update(src.db, func(tx walletdb.ReadWriteTx) error {
+		manager, err := src.Manager.FetchScopedKeyManager(scope)
+		if err != nil {
+			return err
+		}
+		change, err := manager.NextInternalAddresses(
+			tx.ReadWriteBucket(waddrmgrNamespaceKey),
+			waddrmgr.DefaultAccountNum, 1)
+		if err == nil {
+			want = append(want, change[0].Address())
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unable to derive change address: %v", err)
+	}
+	key, err := ec.NewPrivateKey(ec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wif, err := util.NewWIF(key, src.chainParams, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := src.ImportPrivateKey(scope, wif, nil, true)
+	if err != nil {
+		t.Fatalf("ImportPrivateKey: %v", err)
+	}
+	if addr, err = util.DecodeAddress(imported, src.chainParams); err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, addr)
+	p2sh, err := src.ImportP2SHRedeemScript([]byte{0x51})
+	if err != nil {
+		t.Fatalf("ImportP2SHRedeemScript: %v", err)
+	}
+	want = append(want, p2sh)
+	watchKey, err := ec.NewPrivateKey(ec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	watched, err := src.ImportPublicKey(scope, watchKey.PubKey(), true, nil,
+		true)
+	if err != nil {
+		t.Fatalf("ImportPublicKey: %v", err)
+	}
+	var dump bytes.Buffer
+	if err = src.DumpWallet(&dump); err != nil {
+		t.Fatalf("DumpWallet: %v", err)
+	}
+	text := dump.String()
+	if !strings.Contains(text, " label=my%20savings%20100%25 ") {
+		t.Errorf("dump has no escaped label for %q:\n%s", label, text)
+	}
+	if strings.Contains(text, watched) {
+		t.Errorf("dump has watch-only address %s:\n%s", watched, text)
+	}
+	for _, addr := range want {
+		if !strings.Contains(text, "addr="+addr.EncodeAddress()) {
+			t.Errorf("dump has no address %v:\n%s", addr, text)
+		}
+	}
+	entries, err := readWalletDump(strings.NewReader(text), src.chainParams)
+	if err != nil {
+		t.Fatalf("readWalletDump: %v", err)
+	}
+	dst, stopDst := testWallet(t, bytes.Repeat([]byte{0x02}, 32))
+	defer stopDst()
+	n, err := dst.ImportWallet(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("ImportWallet: %v", err)
+	}
+	if n != len(entries) {
+		t.Errorf("ImportWallet: imported %d, want %d", n, len(entries))
+	}
+	for _, addr := range want {
+		ma, err := dst.AddressInfo(addr)
+		if err != nil {
+			t.Errorf("imported wallet has no address %v: %v", addr, err)
+			continue
+		}
+		if ma.WatchOnly() {
+			t.Errorf("imported address %v is watch-only", addr)
+		}
+	}
+	watchedAddr, err := util.DecodeAddress(watched, src.chainParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, err := dst.HaveAddress(watchedAddr); err != nil || have {
+		t.Errorf("imported wallet has watch-only address: %v, %v", have, err)
+	}
+	// Importing the dump again adds nothing.
+	if n, err = dst.ImportWallet(strings.NewReader(text)); err != nil ||
+		n != 0 {
+		t.Errorf("ImportWallet again: imported %d, %v, want 0", n, err)
+	}
+}
@@ -0,0 +1,174 @@
+package wallet
+
+import (
+	"fmt"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	txauthor "github.com/p9c/pod/pkg/chain/tx/author"
+	wtxmgr "github.com/p9c/pod/pkg/chain/tx/mgr"
+	txrules "github.com/p9c/pod/pkg/chain/tx/rules"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	txsizes "github.com/p9c/pod/pkg/chain/tx/sizes"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/util"
+	walletdb "github.com/p9c/pod/pkg/wallet/db"
+)
+
+// BumpFee replaces an unmined transaction of the wallet that signals that it
+// can be replaced with a transaction paying a higher fee, taken from its
+// change output, and publishes the replacement. The replacement pays the fee
+// rate feeSatPerKb, or when it is zero the lowest fee that replacing the
+// transaction requires, which is its fee plus the relay fee for the size of
+// the replacement. All the inputs of the transaction must belong to the
+// wallet, and none of its outputs may be spent yet. The wallet must be
+// unlocked to sign the replacement. It returns the replacement along with the
+// fees of both transactions.
+func (w *Wallet) BumpFee(txHash *chainhash.Hash, feeSatPerKb util.Amount) (
+	tx *wire.MsgTx, origFee, fee util.Amount, err error) {
+	heldUnlock, err := w.holdUnlock()
+	if err != nil {
+		Error(err)
+		return
+	}
+	defer heldUnlock.release()
+	var origRec *wtxmgr.TxRecord
+	err = walletdb.View(w.db, func(dbtx walletdb.ReadTx) error {
+		addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
+		txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+		details, err := w.TxStore.TxDetails(txmgrNs, txHash)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		switch {
+		case details == nil:
+			return fmt.Errorf("transaction %v is not in the wallet", txHash)
+		case details.Block.Height != -1:
+			return fmt.Errorf("transaction %v is already mined", txHash)
+		case !txrules.SignalsReplacement(&details.MsgTx):
+			return fmt.Errorf("transaction %v does not signal that it can "+
+				"be replaced", txHash)
+		case len(details.Debits) != len(details.MsgTx.TxIn):
+			return fmt.Errorf("transaction %v spends outputs that don't "+
+				"belong to the wallet", txHash)
+		}
+		changeIndex := -1
+		for _, credit := range details.Credits {
+			if credit.Spent {
+				return fmt.Errorf("transaction %v has outputs spent by "+
+					"other transactions of the wallet", txHash)
+			}
+			if credit.Change && changeIndex < 0 {
+				changeIndex = int(credit.Index)
+			}
+		}
+		if changeIndex < 0 {
+			return fmt.Errorf("transaction %v has no change output to pay "+
+				"a higher fee from", txHash)
+		}
+		// Find the scripts and values of the outputs the transaction spends
+		// in order to sign the replacement and estimate its size.
+		inputValues := make([]util.Amount, len(details.MsgTx.TxIn))
+		for _, debit := range details.Debits {
+			inputValues[debit.Index] = debit.Amount
+		}
+		prevScripts := make([][]byte, len(details.MsgTx.TxIn))
+		var nested, p2wpkh, p2pkh int
+		for i, txIn := range details.MsgTx.TxIn {
+			prevOut := txIn.PreviousOutPoint
+			prev, err := w.TxStore.TxDetails(txmgrNs, &prevOut.Hash)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			if prev == nil || int(prevOut.Index) >= len(prev.MsgTx.TxOut) {
+				return fmt.Errorf("output %v spent by transaction %v is "+
+					"not in the wallet", prevOut, txHash)
+			}
+			prevScripts[i] = prev.MsgTx.TxOut[prevOut.Index].PkScript
+			switch {
+			case txscript.IsPayToScriptHash(prevScripts[i]):
+				nested++
+			case txscript.IsPayToWitnessPubKeyHash(prevScripts[i]):
+				p2wpkh++
+			default:
+				p2pkh++
+			}
+			origFee += inputValues[i]
+		}
+		for _, txOut := range details.MsgTx.TxOut {
+			origFee -= util.Amount(txOut.Value)
+		}
+		size := txsizes.EstimateVirtualSize(p2pkh, p2wpkh, nested,
+			details.MsgTx.TxOut, false)
+		minFee := txrules.ReplacementFee(origFee,
+			txrules.DefaultRelayFeePerKb, size)
+		fee = minFee
+		if feeSatPerKb > 0 {
+			fee = txrules.FeeForSerializeSize(feeSatPerKb, size)
+			if fee < minFee {
+				return fmt.Errorf("a fee rate of %v/kB pays %v, which is "+
+					"less than the %v required to replace transaction %v",
+					feeSatPerKb, fee, minFee, txHash)
+			}
+		}
+		tx = details.MsgTx.Copy()
+		change := tx.TxOut[changeIndex]
+		change.Value -= int64(fee - origFee)
+		if change.Value < 0 || txrules.IsDustOutput(change,
+			txrules.DefaultRelayFeePerKb) {
+			return fmt.Errorf("the change output of transaction %v is too "+
+				"small to pay a fee of %v", txHash, fee)
+		}
+		for _, txIn := range tx.TxIn {
+			txIn.SignatureScript = nil
+			txIn.Witness = nil
+		}
+		err = txauthor.AddAllInputScripts(tx, prevScripts, inputValues,
+			secretSource{w.Manager, addrmgrNs})
+		if err != nil {
+			Error(err)
+			return err
+		}
+		origRec = &details.TxRecord
+		return validateMsgTx(tx, prevScripts, inputValues)
+	})
+	if err != nil {
+		Error(err)
+		return nil, 0, 0, err
+	}
+	// The original transaction is removed from the wallet before the
+	// replacement is published, as they spend the same outputs, and it is
+	// put back if the replacement is rejected.
+	err = walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) error {
+		txmgrNs := dbtx.ReadWriteBucket(wtxmgrNamespaceKey)
+		return w.TxStore.RemoveUnminedTx(txmgrNs, origRec)
+	})
+	if err != nil {
+		Error(err)
+		return nil, 0, 0, err
+	}
+	if _, err = w.publishTransaction(tx); err != nil {
+		Error(err)
+		e := walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) error {
+			txmgrNs := dbtx.ReadWriteBucket(wtxmgrNamespaceKey)
+			rec, err := wtxmgr.NewTxRecordFromMsgTx(tx, origRec.Received)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			if err = w.TxStore.RemoveUnminedTx(txmgrNs, rec); err != nil {
+				Error(err)
+				return err
+			}
+			return w.addRelevantTx(dbtx, origRec, nil)
+		})
+		if e != nil {
+			Error(e)
+		}
+		return nil, 0, 0, err
+	}
+	Infof("replaced transaction %v paying %v with %v paying %v", txHash,
+		origFee, tx.TxHash(), fee)
+	return tx, origFee, fee, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	tm "github.com/p9c/pod/pkg/chain/tx/mgr"
 	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/util"
 	wm "github.com/p9c/pod/pkg/wallet/addrmgr"
 	"github.com/p9c/pod/pkg/wallet/chain"
 	walletdb "github.com/p9c/pod/pkg/wallet/db"
@@ -257,6 +258,7 @@ func (w *Wallet) addRelevantTx(dbtx walletdb.ReadWriteTx, rec *tm.TxRecord, bloc
 	}
 	// Check every output to determine whether it is controlled by a wallet
 	// key.  If so, mark the output as a credit.
+	var watchAddrs []util.Address
 	for i, output := range rec.MsgTx.TxOut {
 		_, addrs, _, err := txscript.ExtractPkScriptAddrs(output.PkScript,
 			w.chainParams)
@@ -283,6 +285,16 @@ func (w *Wallet) addRelevantTx(dbtx walletdb.ReadWriteTx, rec *tm.TxRecord, bloc
 					return err
 				}
 				Trace("marked address used:", addr)
+				// Keep watching addresses past the used one
+				// when it is of a watch-only account.
+				newAddrs, err := w.extendUsedWatchOnlyAccount(
+					addrmgrNs, ma,
+				)
+				if err != nil {
+					Error(err)
+					return err
+				}
+				watchAddrs = append(watchAddrs, newAddrs...)
 				continue
 			}
 			// Missing addresses are skipped.  Other errors should
@@ -292,6 +304,13 @@ func (w *Wallet) addRelevantTx(dbtx walletdb.ReadWriteTx, rec *tm.TxRecord, bloc
 			}
 		}
 	}
+	if chainClient := w.ChainClient(); len(watchAddrs) != 0 &&
+		chainClient != nil {
+		if err := chainClient.NotifyReceived(watchAddrs); err != nil {
+			Error(err)
+			return err
+		}
+	}
 	// Send notification of mined or unmined transaction to any interested
 	// clients.
 	//
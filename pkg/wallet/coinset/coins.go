@@ -172,6 +172,20 @@ func (s MinNumberCoinSelector) CoinSelect(targetValue util.Amount, coins []Coin)
 	return MinIndexCoinSelector(s).CoinSelect(targetValue, sortedCoins)
 }
 
+// MaxNumberCoinSelector is a CoinSelector that attempts to construct a selection of coins whose total value is at least targetValue that uses as many of the smallest inputs as possible. This is useful to consolidate small coins into fewer ones while fees are low.
+type MaxNumberCoinSelector struct {
+	MaxInputs       int
+	MinChangeAmount util.Amount
+}
+
+// CoinSelect will attempt to select coins using the algorithm described in the MaxNumberCoinSelector struct.
+func (s MaxNumberCoinSelector) CoinSelect(targetValue util.Amount, coins []Coin) (Coins, error) {
+	sortedCoins := make([]Coin, 0, len(coins))
+	sortedCoins = append(sortedCoins, coins...)
+	sort.Sort(byAmount(sortedCoins))
+	return MinIndexCoinSelector(s).CoinSelect(targetValue, sortedCoins)
+}
+
 // MaxValueAgeCoinSelector is a CoinSelector that attempts to construct a selection of coins whose total value is at least targetValue that has as much input value-age as possible. This would be useful in the case where you want to maximize likelihood of the inclusion of your transaction in the next mined block.
 type MaxValueAgeCoinSelector struct {
 	MaxInputs       int
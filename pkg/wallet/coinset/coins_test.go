@@ -30,7 +30,7 @@ func NewCoin(index int64, value util.Amount, numConfs int64) coinset.Coin {
 	h := sha256.New()
 	_, err := h.Write([]byte(fmt.Sprintf("%d", index)))
 	if err != nil {
-		Error(err)
+		panic(err)
 	}
 	hash, _ := chainhash.NewHash(h.Sum(nil))
 	c := &TestCoin{
@@ -158,6 +158,98 @@ func TestMinNumberSelector(t *testing.T) {
 	testCoinSelector(minNumberTests, t)
 }
 
+var maxNumberSelectors = []coinset.MaxNumberCoinSelector{
+	{MaxInputs: 10, MinChangeAmount: 10000},
+	{MaxInputs: 2, MinChangeAmount: 10000},
+}
+var maxNumberTests = []coinSelectTest{
+	{maxNumberSelectors[0], coins, 10000000, []coinset.Coin{coins[1]}, nil},
+	{maxNumberSelectors[0], coins, 30000000, []coinset.Coin{coins[1], coins[3]}, nil},
+	{maxNumberSelectors[0], coins, 80000000, []coinset.Coin{coins[1], coins[3], coins[2]}, nil},
+	{maxNumberSelectors[0], coins, 185000000, []coinset.Coin{coins[1], coins[3], coins[2], coins[0]}, nil},
+	{maxNumberSelectors[0], coins, 200000000, nil, coinset.ErrCoinsNoSelectionAvailable},
+	{maxNumberSelectors[1], coins, 30000000, []coinset.Coin{coins[1], coins[3]}, nil},
+	{maxNumberSelectors[1], coins, 80000000, nil, coinset.ErrCoinsNoSelectionAvailable},
+}
+
+func TestMaxNumberSelector(t *testing.T) {
+	testCoinSelector(maxNumberTests, t)
+}
+
+var branchAndBoundSelectors = []coinset.BranchAndBoundCoinSelector{
+	{MaxInputs: 10},
+	{MaxInputs: 10, CostOfChange: 15000000},
+	{MaxInputs: 1},
+}
+var branchAndBoundTests = []coinSelectTest{
+	{branchAndBoundSelectors[0], coins, 60000000, []coinset.Coin{coins[2], coins[1]}, nil},
+	{branchAndBoundSelectors[0], coins, 75000000, []coinset.Coin{coins[2], coins[3]}, nil},
+	{branchAndBoundSelectors[0], coins, 185000000, []coinset.Coin{coins[0], coins[2], coins[3], coins[1]}, nil},
+	{branchAndBoundSelectors[0], coins, 61000000, nil, coinset.ErrCoinsNoSelectionAvailable},
+	{branchAndBoundSelectors[0], coins, 200000000, nil, coinset.ErrCoinsNoSelectionAvailable},
+	{branchAndBoundSelectors[1], coins, 61000000, []coinset.Coin{coins[2], coins[3]}, nil},
+	{branchAndBoundSelectors[1], coins, 90000000, []coinset.Coin{coins[0]}, nil},
+	{branchAndBoundSelectors[2], coins, 50000000, []coinset.Coin{coins[2]}, nil},
+	{branchAndBoundSelectors[2], coins, 60000000, nil, coinset.ErrCoinsNoSelectionAvailable},
+}
+
+func TestBranchAndBoundSelector(t *testing.T) {
+	testCoinSelector(branchAndBoundTests, t)
+}
+
+var knapsackSelectors = []coinset.KnapsackCoinSelector{
+	{MaxInputs: 10, MinChangeAmount: 10000},
+	{MaxInputs: 10, MinChangeAmount: 1000000},
+	{MaxInputs: 1, MinChangeAmount: 10000},
+}
+var knapsackTests = []coinSelectTest{
+	{knapsackSelectors[0], coins, 50000000, []coinset.Coin{coins[2]}, nil},
+	{knapsackSelectors[0], coins, 35000000, []coinset.Coin{coins[1], coins[3]}, nil},
+	{knapsackSelectors[0], coins, 40000000, []coinset.Coin{coins[2]}, nil},
+	{knapsackSelectors[0], coins, 200000000, nil, coinset.ErrCoinsNoSelectionAvailable},
+	{knapsackSelectors[1], coins, 80000000, []coinset.Coin{coins[2], coins[3], coins[1]}, nil},
+	{knapsackSelectors[2], coins, 80000000, []coinset.Coin{coins[0]}, nil},
+	{knapsackSelectors[2], coins, 150000000, nil, coinset.ErrCoinsNoSelectionAvailable},
+}
+
+func TestKnapsackSelector(t *testing.T) {
+	testCoinSelector(knapsackTests, t)
+}
+
+var fallbackTests = []coinSelectTest{
+	{coinset.FallbackCoinSelector{branchAndBoundSelectors[0], knapsackSelectors[0]}, coins, 60000000, []coinset.Coin{coins[2], coins[1]}, nil},
+	{coinset.FallbackCoinSelector{branchAndBoundSelectors[0], knapsackSelectors[0]}, coins, 40000000, []coinset.Coin{coins[2]}, nil},
+	{coinset.FallbackCoinSelector{branchAndBoundSelectors[0], knapsackSelectors[0]}, coins, 200000000, nil, coinset.ErrCoinsNoSelectionAvailable},
+}
+
+func TestFallbackSelector(t *testing.T) {
+	testCoinSelector(fallbackTests, t)
+}
+
+// scriptCoin is a TestCoin paying to an output script.
+type scriptCoin struct {
+	coinset.Coin
+	script []byte
+}
+
+func (c *scriptCoin) PkScript() []byte { return c.script }
+
+var reusedCoins = []coinset.Coin{
+	&scriptCoin{coins[0], []byte{1}},
+	&scriptCoin{coins[1], []byte{2}},
+	&scriptCoin{coins[2], []byte{3}},
+	&scriptCoin{coins[3], []byte{2}},
+}
+var avoidReuseTests = []coinSelectTest{
+	{coinset.AvoidReuseCoinSelector{branchAndBoundSelectors[0]}, reusedCoins, 35000000, []coinset.Coin{reusedCoins[1], reusedCoins[3]}, nil},
+	{coinset.AvoidReuseCoinSelector{knapsackSelectors[0]}, reusedCoins, 20000000, []coinset.Coin{reusedCoins[1], reusedCoins[3]}, nil},
+	{coinset.AvoidReuseCoinSelector{branchAndBoundSelectors[0]}, reusedCoins, 10000000, nil, coinset.ErrCoinsNoSelectionAvailable},
+}
+
+func TestAvoidReuseSelector(t *testing.T) {
+	testCoinSelector(avoidReuseTests, t)
+}
+
 var maxValueAgeSelectors = []coinset.MaxValueAgeCoinSelector{
 	{MaxInputs: 10, MinChangeAmount: 10000},
 	{MaxInputs: 2, MinChangeAmount: 10000},
@@ -0,0 +1,298 @@
+package coinset
+
+import (
+	"math/rand"
+	"sort"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// DefaultBranchAndBoundTries is the number of steps a BranchAndBoundCoinSelector searches for when MaxTries is not set.
+const DefaultBranchAndBoundTries = 100000
+
+// DefaultKnapsackIterations is the number of random passes a KnapsackCoinSelector makes when Iterations is not set.
+const DefaultKnapsackIterations = 1000
+
+// BranchAndBoundCoinSelector is a CoinSelector that searches for a selection of coins whose total value is between targetValue and targetValue plus CostOfChange, so that a transaction spending them needs no change output. Coins should be valued at their effective value, that is their amount less the fee to spend them, and CostOfChange is the fee of creating and later spending a change output. Of the selections found within MaxTries steps of a depth first search, the one with the least value above targetValue is returned.
+type BranchAndBoundCoinSelector struct {
+	MaxInputs    int
+	CostOfChange util.Amount
+	MaxTries     int
+}
+
+// CoinSelect will attempt to select coins using the algorithm described in the BranchAndBoundCoinSelector struct.
+func (s BranchAndBoundCoinSelector) CoinSelect(targetValue util.Amount, coins []Coin) (Coins, error) {
+	sortedCoins := make([]Coin, 0, len(coins))
+	var available util.Amount
+	for _, coin := range coins {
+		// Coins that cost more to spend than they are worth never help.
+		if coin.Value() > 0 {
+			sortedCoins = append(sortedCoins, coin)
+			available += coin.Value()
+		}
+	}
+	if available < targetValue {
+		return nil, ErrCoinsNoSelectionAvailable
+	}
+	sort.Sort(sort.Reverse(byAmount(sortedCoins)))
+	maxTries := s.MaxTries
+	if maxTries <= 0 {
+		maxTries = DefaultBranchAndBoundTries
+	}
+	// selection holds the decision to include or exclude each coin up to the
+	// current depth of the search.
+	selection := make([]bool, 0, len(sortedCoins))
+	var best []bool
+	var bestWaste util.Amount
+	var total util.Amount
+	var numInputs int
+search:
+	for try := 0; try < maxTries; try++ {
+		backtrack := false
+		switch {
+		case total+available < targetValue, total > targetValue+s.CostOfChange,
+			numInputs > s.MaxInputs:
+			// This branch cannot reach the target window.
+			backtrack = true
+		case total >= targetValue:
+			waste := total - targetValue
+			if best == nil || waste < bestWaste {
+				best = append(best[:0], selection...)
+				bestWaste = waste
+			}
+			if waste == 0 {
+				// No selection can do better.
+				break search
+			}
+			backtrack = true
+		}
+		if !backtrack {
+			// Include the next coin, unless the previous coin had the same
+			// value and was excluded, as that branch was already searched.
+			coin := sortedCoins[len(selection)]
+			available -= coin.Value()
+			last := len(selection) - 1
+			if last >= 0 && !selection[last] &&
+				sortedCoins[last].Value() == coin.Value() {
+				selection = append(selection, false)
+			} else {
+				selection = append(selection, true)
+				total += coin.Value()
+				numInputs++
+			}
+			continue
+		}
+		// Walk back to the last included coin and exclude it instead.
+		for len(selection) != 0 && !selection[len(selection)-1] {
+			available += sortedCoins[len(selection)-1].Value()
+			selection = selection[:len(selection)-1]
+		}
+		if len(selection) == 0 {
+			// Every branch has been searched.
+			break search
+		}
+		last := len(selection) - 1
+		selection[last] = false
+		total -= sortedCoins[last].Value()
+		numInputs--
+	}
+	if best == nil {
+		return nil, ErrCoinsNoSelectionAvailable
+	}
+	cs := NewCoinSet(nil)
+	for i, included := range best {
+		if included {
+			cs.PushCoin(sortedCoins[i])
+		}
+	}
+	return cs, nil
+}
+
+// KnapsackCoinSelector is a CoinSelector that attempts to construct a selection of coins whose total value is at least targetValue with as little value above it as possible, and that either leaves no change or change of at least MinChangeAmount. A single coin of exactly targetValue is preferred, then the combination of coins smaller than the target that comes closest to it, found by Iterations random passes, unless the smallest coin larger than the target is closer.
+type KnapsackCoinSelector struct {
+	MaxInputs       int
+	MinChangeAmount util.Amount
+	Iterations      int
+}
+
+// CoinSelect will attempt to select coins using the algorithm described in the KnapsackCoinSelector struct.
+func (s KnapsackCoinSelector) CoinSelect(targetValue util.Amount, coins []Coin) (Coins, error) {
+	var lowestLarger Coin
+	var smaller []Coin
+	var smallerTotal util.Amount
+	for _, coin := range coins {
+		switch {
+		case coin.Value() <= 0:
+		case coin.Value() == targetValue:
+			return NewCoinSet([]Coin{coin}), nil
+		case coin.Value() < targetValue+s.MinChangeAmount:
+			smaller = append(smaller, coin)
+			smallerTotal += coin.Value()
+		case lowestLarger == nil || coin.Value() < lowestLarger.Value():
+			lowestLarger = coin
+		}
+	}
+	if smallerTotal == targetValue && len(smaller) <= s.MaxInputs {
+		return NewCoinSet(smaller), nil
+	}
+	var best []Coin
+	if smallerTotal >= targetValue {
+		sort.Sort(sort.Reverse(byAmount(smaller)))
+		best = s.bestSubset(smaller, targetValue)
+		bestTotal := NewCoinSet(best).TotalValue()
+		if bestTotal != targetValue {
+			if smallerTotal >= targetValue+s.MinChangeAmount {
+				best = s.bestSubset(smaller, targetValue+s.MinChangeAmount)
+			} else {
+				best = nil
+			}
+		}
+	}
+	bestSet := NewCoinSet(best)
+	valid := best != nil && bestSet.Num() <= s.MaxInputs &&
+		satisfiesTargetValue(targetValue, s.MinChangeAmount, bestSet.TotalValue())
+	if lowestLarger != nil && s.MaxInputs > 0 &&
+		(!valid || lowestLarger.Value() <= bestSet.TotalValue()) {
+		return NewCoinSet([]Coin{lowestLarger}), nil
+	}
+	if !valid {
+		return nil, ErrCoinsNoSelectionAvailable
+	}
+	return bestSet, nil
+}
+
+// bestSubset returns the subset of the coins, sorted from largest to smallest, with the smallest total value that is at least targetValue found in a number of random passes.
+func (s KnapsackCoinSelector) bestSubset(coins []Coin, targetValue util.Amount) []Coin {
+	iterations := s.Iterations
+	if iterations <= 0 {
+		iterations = DefaultKnapsackIterations
+	}
+	best := make([]bool, len(coins))
+	var bestTotal util.Amount
+	for i := range coins {
+		best[i] = true
+		bestTotal += coins[i].Value()
+	}
+	included := make([]bool, len(coins))
+	for n := 0; n < iterations && bestTotal != targetValue; n++ {
+		for i := range included {
+			included[i] = false
+		}
+		var total util.Amount
+		reachedTarget := false
+		// The first pass includes coins at random, the second includes the
+		// coins left out by the first until the target is reached.
+		for pass := 0; pass < 2 && !reachedTarget; pass++ {
+			for i, coin := range coins {
+				if pass == 0 && rand.Intn(2) == 0 || pass == 1 && included[i] {
+					continue
+				}
+				total += coin.Value()
+				included[i] = true
+				if total >= targetValue {
+					reachedTarget = true
+					if total < bestTotal {
+						bestTotal = total
+						copy(best, included)
+					}
+					total -= coin.Value()
+					included[i] = false
+				}
+			}
+		}
+	}
+	var subset []Coin
+	for i, coin := range coins {
+		if best[i] {
+			subset = append(subset, coin)
+		}
+	}
+	return subset
+}
+
+// FallbackCoinSelector is a CoinSelector that tries each of its selectors in turn and returns the first selection found.
+type FallbackCoinSelector []CoinSelector
+
+// CoinSelect will attempt to select coins using the algorithm described in the FallbackCoinSelector type.
+func (s FallbackCoinSelector) CoinSelect(targetValue util.Amount, coins []Coin) (Coins, error) {
+	for _, selector := range s {
+		cs, err := selector.CoinSelect(targetValue, coins)
+		if err == nil {
+			return cs, nil
+		}
+	}
+	return nil, ErrCoinsNoSelectionAvailable
+}
+
+// AvoidReuseCoinSelector is a CoinSelector that keeps the coins paying to the same output script together, so that Selector selects either all or none of the coins of an address. Spending every coin of an address at once avoids linking its history with that of other addresses in later transactions. The MaxInputs of Selector counts addresses rather than coins.
+type AvoidReuseCoinSelector struct {
+	Selector CoinSelector
+}
+
+// CoinSelect will attempt to select coins using the algorithm described in the AvoidReuseCoinSelector struct.
+func (s AvoidReuseCoinSelector) CoinSelect(targetValue util.Amount, coins []Coin) (Coins, error) {
+	groups := make([]Coin, 0, len(coins))
+	groupIndex := make(map[string]int)
+	for _, coin := range coins {
+		script := string(coin.PkScript())
+		if i, ok := groupIndex[script]; ok {
+			groups[i].(*coinGroup).PushCoin(coin)
+			continue
+		}
+		groupIndex[script] = len(groups)
+		groups = append(groups, &coinGroup{NewCoinSet([]Coin{coin})})
+	}
+	selected, err := s.Selector.CoinSelect(targetValue, groups)
+	if err != nil {
+		return nil, err
+	}
+	cs := NewCoinSet(nil)
+	for _, group := range selected.Coins() {
+		for _, coin := range group.(*coinGroup).Coins() {
+			cs.PushCoin(coin)
+		}
+	}
+	return cs, nil
+}
+
+// coinGroup is a Coin made of all the coins paying to an output script.
+type coinGroup struct {
+	*CoinSet
+}
+
+// Hash returns the hash of the transaction of the first coin in the group.
+func (g *coinGroup) Hash() *chainhash.Hash {
+	return g.coinList.Front().Value.(Coin).Hash()
+}
+
+// Index returns the output index of the first coin in the group.
+func (g *coinGroup) Index() uint32 {
+	return g.coinList.Front().Value.(Coin).Index()
+}
+
+// Value returns the total value of the coins in the group.
+func (g *coinGroup) Value() util.Amount {
+	return g.TotalValue()
+}
+
+// PkScript returns the output script shared by the coins in the group.
+func (g *coinGroup) PkScript() []byte {
+	return g.coinList.Front().Value.(Coin).PkScript()
+}
+
+// NumConfs returns the number of confirmations of the least confirmed coin in the group.
+func (g *coinGroup) NumConfs() int64 {
+	numConfs := int64(-1)
+	for _, coin := range g.Coins() {
+		if numConfs < 0 || coin.NumConfs() < numConfs {
+			numConfs = coin.NumConfs()
+		}
+	}
+	return numConfs
+}
+
+// ValueAge returns the total value-age of the coins in the group.
+func (g *coinGroup) ValueAge() int64 {
+	return g.TotalValueAge()
+}
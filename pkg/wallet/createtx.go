@@ -4,15 +4,19 @@ package wallet
 
 import (
 	"fmt"
-	"sort"
 
+	blockchain "github.com/p9c/pod/pkg/chain"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
 	txauthor "github.com/p9c/pod/pkg/chain/tx/author"
 	wtxmgr "github.com/p9c/pod/pkg/chain/tx/mgr"
+	txrules "github.com/p9c/pod/pkg/chain/tx/rules"
 	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	txsizes "github.com/p9c/pod/pkg/chain/tx/sizes"
 	"github.com/p9c/pod/pkg/chain/wire"
 	"github.com/p9c/pod/pkg/util"
 	ec "github.com/p9c/pod/pkg/util/elliptic"
 	waddrmgr "github.com/p9c/pod/pkg/wallet/addrmgr"
+	"github.com/p9c/pod/pkg/wallet/coinset"
 	walletdb "github.com/p9c/pod/pkg/wallet/db"
 )
 
@@ -23,28 +27,182 @@ type byAmount []wtxmgr.Credit
 func (s byAmount) Len() int           { return len(s) }
 func (s byAmount) Less(i, j int) bool { return s[i].Amount < s[j].Amount }
 func (s byAmount) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
-func makeInputSource(eligible []wtxmgr.Credit) txauthor.InputSource {
-	// Pick largest outputs first.  This is only done for compatibility with
-	// previous tx creation code, not because it's a good idea.
-	sort.Sort(sort.Reverse(byAmount(eligible)))
-	// Current inputs and their total value.  These are closed over by the
-	// returned input source and reused across multiple calls.
-	currentTotal := util.Amount(0)
-	currentInputs := make([]*wire.TxIn, 0, len(eligible))
-	currentScripts := make([][]byte, 0, len(eligible))
-	currentInputValues := make([]util.Amount, 0, len(eligible))
+// CoinSelectionStrategy chooses how the outputs spent by a new transaction are
+// selected from the eligible outputs of an account.
+type CoinSelectionStrategy uint8
+
+const (
+	// CoinSelectionBranchAndBound looks for outputs adding up to the amount
+	// sent and its fee closely enough that no change output is needed, and
+	// otherwise selects the outputs leaving the least change.
+	CoinSelectionBranchAndBound CoinSelectionStrategy = iota
+	// CoinSelectionAvoidReuse selects as CoinSelectionBranchAndBound does, but
+	// spends all the outputs paying to an address together, so that the
+	// address is not linked again to other addresses by later transactions.
+	CoinSelectionAvoidReuse
+	// CoinSelectionConsolidate spends as many small outputs as possible while
+	// the fee rate is low, and as few outputs as possible otherwise.
+	CoinSelectionConsolidate
+)
+
+const (
+	// maxSelectedInputs limits the number of inputs selected for a
+	// transaction, keeping it well below the maximum standard size.
+	maxSelectedInputs = 500
+	// consolidateFeePerKb is the highest fee rate at which
+	// CoinSelectionConsolidate spends many small outputs.
+	consolidateFeePerKb = 3 * txrules.DefaultRelayFeePerKb
+)
+
+// creditCoin is a coinset.Coin for an eligible output, valued at its effective
+// value, which is its amount less the fee to spend it.
+type creditCoin struct {
+	credit         *wtxmgr.Credit
+	effectiveValue util.Amount
+	numConfs       int64
+}
+
+func (c *creditCoin) Hash() *chainhash.Hash { return &c.credit.OutPoint.Hash }
+func (c *creditCoin) Index() uint32         { return c.credit.OutPoint.Index }
+func (c *creditCoin) Value() util.Amount    { return c.effectiveValue }
+func (c *creditCoin) PkScript() []byte      { return c.credit.PkScript }
+func (c *creditCoin) NumConfs() int64       { return c.numConfs }
+func (c *creditCoin) ValueAge() int64       { return c.numConfs * int64(c.effectiveValue) }
+
+// p2wpkhScript is a P2WPKH output script, the kind of output txauthor
+// estimates the first input of a transaction to spend.
+var p2wpkhScript = append([]byte{txscript.OP_0, txscript.OP_DATA_20},
+	make([]byte, 20)...)
+
+// inputFee returns the fee at the rate for an input spending an output with the
+// script, rounded up. Inputs are sized by the same script classes that
+// txauthor.NewUnsignedTransaction uses to estimate the transaction size.
+func inputFee(pkScript []byte, feeSatPerKb util.Amount) util.Amount {
+	witnessSize := (txsizes.RedeemP2WPKHInputWitnessWeight + 3) /
+		blockchain.WitnessScaleFactor
+	var size int
+	switch {
+	case txscript.IsPayToScriptHash(pkScript):
+		size = txsizes.RedeemNestedP2WPKHInputSize + witnessSize
+	case txscript.IsPayToWitnessPubKeyHash(pkScript):
+		size = txsizes.RedeemP2WPKHInputSize + witnessSize
+	default:
+		size = txsizes.RedeemP2PKHInputSize
+	}
+	return (feeSatPerKb*util.Amount(size) + 999) / 1000
+}
+
+// coinSelector returns the coin selector implementing the strategy at the fee
+// rate.
+func coinSelector(strategy CoinSelectionStrategy,
+	feeSatPerKb util.Amount) coinset.CoinSelector {
+	// Change costs the fee of its output now and of the input spending it
+	// later, and must not be dust.
+	costOfChange := txrules.FeeForSerializeSize(feeSatPerKb,
+		txsizes.P2WPKHOutputSize) + inputFee(nil, feeSatPerKb)
+	minChange := txrules.GetDustThreshold(txsizes.P2WPKHPkScriptSize,
+		feeSatPerKb)
+	knapsack := coinset.KnapsackCoinSelector{
+		MaxInputs:       maxSelectedInputs,
+		MinChangeAmount: minChange,
+	}
+	changeless := coinset.FallbackCoinSelector{
+		coinset.BranchAndBoundCoinSelector{
+			MaxInputs:    maxSelectedInputs,
+			CostOfChange: costOfChange,
+		},
+		knapsack,
+	}
+	switch strategy {
+	case CoinSelectionAvoidReuse:
+		return coinset.AvoidReuseCoinSelector{Selector: changeless}
+	case CoinSelectionConsolidate:
+		if feeSatPerKb <= consolidateFeePerKb {
+			return coinset.FallbackCoinSelector{
+				coinset.MaxNumberCoinSelector{
+					MaxInputs:       maxSelectedInputs,
+					MinChangeAmount: minChange,
+				},
+				knapsack,
+			}
+		}
+		return coinset.FallbackCoinSelector{
+			coinset.MinNumberCoinSelector{
+				MaxInputs:       maxSelectedInputs,
+				MinChangeAmount: minChange,
+			},
+			knapsack,
+		}
+	default:
+		return changeless
+	}
+}
+
+func makeInputSource(eligible []wtxmgr.Credit, strategy CoinSelectionStrategy,
+	feeSatPerKb util.Amount, height int32) txauthor.InputSource {
+	// Outputs are selected by their effective value so that the fee of
+	// each input is paid for by the input itself. Outputs worth less than
+	// the fee to spend them are never selected.
+	coins := make([]coinset.Coin, 0, len(eligible))
+	for i := range eligible {
+		credit := &eligible[i]
+		effectiveValue := credit.Amount - inputFee(credit.PkScript, feeSatPerKb)
+		if effectiveValue <= 0 {
+			continue
+		}
+		coins = append(coins, &creditCoin{
+			credit:         credit,
+			effectiveValue: effectiveValue,
+			numConfs:       int64(confirms(credit.Height, height)),
+		})
+	}
+	selector := coinSelector(strategy, feeSatPerKb)
+	// The target txauthor asks for already includes the fee of the inputs
+	// it estimated the transaction with, which is a P2WPKH input at first
+	// and the inputs selected last after that. As the effective values
+	// have the fees of the inputs taken out, so is the target they are
+	// selected against.
+	inputFees := inputFee(p2wpkhScript, feeSatPerKb)
 	return func(target util.Amount) (util.Amount, []*wire.TxIn,
 		[]util.Amount, [][]byte, error) {
-		for currentTotal < target && len(eligible) != 0 {
-			nextCredit := &eligible[0]
-			eligible = eligible[1:]
-			nextInput := wire.NewTxIn(&nextCredit.OutPoint, nil, nil)
-			currentTotal += nextCredit.Amount
-			currentInputs = append(currentInputs, nextInput)
-			currentScripts = append(currentScripts, nextCredit.PkScript)
-			currentInputValues = append(currentInputValues, nextCredit.Amount)
+		var selectedCoins []coinset.Coin
+		total := util.Amount(0)
+		// The estimates of the fees may differ, so the selection is
+		// repeated for more until the amounts of the selected outputs
+		// reach the target, which keeps the target of each call above the
+		// last.
+		for effectiveTarget := target - inputFees; ; {
+			selected, err := selector.CoinSelect(effectiveTarget, coins)
+			if err != nil {
+				// Providing no inputs reports insufficient funds.
+				return 0, nil, nil, nil, nil
+			}
+			selectedCoins = selected.Coins()
+			total = 0
+			for _, coin := range selectedCoins {
+				total += coin.(*creditCoin).credit.Amount
+			}
+			if total >= target {
+				break
+			}
+			effectiveTarget += target - total
 		}
-		return currentTotal, currentInputs, currentInputValues, currentScripts, nil
+		inputFees = 0
+		inputs := make([]*wire.TxIn, 0, len(selectedCoins))
+		scripts := make([][]byte, 0, len(selectedCoins))
+		inputValues := make([]util.Amount, 0, len(selectedCoins))
+		for _, coin := range selectedCoins {
+			credit := coin.(*creditCoin).credit
+			input := wire.NewTxIn(&credit.OutPoint, nil, nil)
+			// Signal that the transaction can be replaced, so that its fee
+			// can be raised with BumpFee if it doesn't get mined.
+			input.Sequence = txrules.MaxRBFSequence
+			inputFees += inputFee(credit.PkScript, feeSatPerKb)
+			inputs = append(inputs, input)
+			scripts = append(scripts, credit.PkScript)
+			inputValues = append(inputValues, credit.Amount)
+		}
+		return total, inputs, inputValues, scripts, nil
 	}
 }
 
@@ -93,9 +251,11 @@ func (s secretSource) GetScript(addr util.Address) ([]byte, error) {
 // outputs.  Previous outputs to reedeem are chosen from the passed account's
 // UTXO set and minconf policy. An additional output may be added to return
 // change to the wallet.  An appropriate fee is included based on the wallet's
-// current relay fee.  The wallet must be unlocked to create the transaction.
+// current relay fee.  The strategy chooses which outputs are spent.  The
+// wallet must be unlocked to create the transaction.
 func (w *Wallet) txToOutputs(outputs []*wire.TxOut, account uint32,
-	minconf int32, feeSatPerKb util.Amount) (tx *txauthor.AuthoredTx, err error) {
+	minconf int32, feeSatPerKb util.Amount,
+	strategy CoinSelectionStrategy) (tx *txauthor.AuthoredTx, err error) {
 	chainClient, err := w.requireChainClient()
 	if err != nil {
 		Error(err)
@@ -114,7 +274,8 @@ func (w *Wallet) txToOutputs(outputs []*wire.TxOut, account uint32,
 			Error(err)
 			return err
 		}
-		inputSource := makeInputSource(eligible)
+		inputSource := makeInputSource(eligible, strategy, feeSatPerKb,
+			bs.Height)
 		changeSource := func() ([]byte, error) {
 			// Derive the change output script.  As a hack to allow
 			// spending from the imported account, change addresses
@@ -211,6 +372,10 @@ func (w *Wallet) findEligibleOutputs(dbtx walletdb.ReadTx, account uint32, minco
 		if err != nil || addrAcct != account {
 			continue
 		}
+		// Outputs to watch-only addresses cannot be signed for.
+		if isWatchOnlyAddress(addrmgrNs, w.Manager, addrs[0]) {
+			continue
+		}
 		eligible = append(eligible, *output)
 	}
 	return eligible, nil
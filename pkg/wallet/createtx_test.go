@@ -0,0 +1,84 @@
+package wallet
+
+import (
+	"testing"
+
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	txauthor "github.com/p9c/pod/pkg/chain/tx/author"
+	wtxmgr "github.com/p9c/pod/pkg/chain/tx/mgr"
+	txrules "github.com/p9c/pod/pkg/chain/tx/rules"
+	txsizes "github.com/p9c/pod/pkg/chain/tx/sizes"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// TestInputSourceFees ensures the outputs selected for txauthor pay the fee
+// of their inputs once, so that an output worth the amount sent and the fee is
+// enough on its own with every strategy.
+func TestInputSourceFees(t *testing.T) {
+	const feeRate = util.Amount(100000)
+	credit := func(index uint32, amount util.Amount) wtxmgr.Credit {
+		script := append([]byte(nil), p2wpkhScript...)
+		script[2] = byte(index)
+		return wtxmgr.Credit{
+			OutPoint:  wire.OutPoint{Hash: chainhash.Hash{0x01}, Index: index},
+			Amount:    amount,
+			PkScript:  script,
+			BlockMeta: wtxmgr.BlockMeta{Block: wtxmgr.Block{Height: 1}},
+		}
+	}
+	big := credit(0, util.SatoshiPerBitcoin)
+	small := credit(1, util.SatoshiPerBitcoin/2)
+	eligible := []wtxmgr.Credit{big, small}
+	outputs := func(amount util.Amount) []*wire.TxOut {
+		return []*wire.TxOut{wire.NewTxOut(int64(amount), p2wpkhScript)}
+	}
+	// The big output pays the fee of a transaction spending it and leaves
+	// change that is not dust, with less than the fee of another input to
+	// spare, or falls short by that much.
+	oneInputFee := txrules.FeeForSerializeSize(feeRate,
+		txsizes.EstimateVirtualSize(0, 1, 0, outputs(0), true))
+	minChange := txrules.GetDustThreshold(txsizes.P2WPKHPkScriptSize,
+		feeRate)
+	margin := inputFee(p2wpkhScript, feeRate) / 2
+	tests := []struct {
+		name   string
+		amount util.Amount
+		inputs int
+	}{
+		{"one output", big.Amount - oneInputFee - minChange - margin, 1},
+		{"both outputs", big.Amount - oneInputFee + margin, 2},
+	}
+	strategies := []CoinSelectionStrategy{CoinSelectionBranchAndBound,
+		CoinSelectionAvoidReuse, CoinSelectionConsolidate}
+	for _, strategy := range strategies {
+		for _, test := range tests {
+			source := makeInputSource(eligible, strategy, feeRate, 10)
+			tx, err := txauthor.NewUnsignedTransaction(outputs(test.amount),
+				feeRate, source, func() ([]byte, error) {
+					return p2wpkhScript, nil
+				})
+			if err != nil {
+				t.Errorf("%s, strategy %d: NewUnsignedTransaction: %v",
+					test.name, strategy, err)
+				continue
+			}
+			if len(tx.Tx.TxIn) != test.inputs {
+				t.Errorf("%s, strategy %d: got %d inputs, want %d",
+					test.name, strategy, len(tx.Tx.TxIn), test.inputs)
+			}
+			var out int64
+			for _, txOut := range tx.Tx.TxOut {
+				out += txOut.Value
+			}
+			fee := tx.TotalInput - util.Amount(out)
+			want := txrules.FeeForSerializeSize(feeRate,
+				txsizes.EstimateVirtualSize(0, len(tx.Tx.TxIn), 0,
+					tx.Tx.TxOut, false))
+			if fee < want {
+				t.Errorf("%s, strategy %d: got fee %v, want at least %v",
+					test.name, strategy, fee, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package wallet
+
+import (
+	"time"
+
+	wtxmgr "github.com/p9c/pod/pkg/chain/tx/mgr"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/util"
+	waddrmgr "github.com/p9c/pod/pkg/wallet/addrmgr"
+	walletdb "github.com/p9c/pod/pkg/wallet/db"
+)
+
+// WalletInfo describes the balances, transactions, unused addresses and lock
+// state of a wallet.
+type WalletInfo struct {
+	// Balance is the amount of confirmed and mature outputs, UnconfirmedBalance
+	// the amount of unconfirmed ones and ImmatureBalance the amount of coinbase
+	// outputs that cannot be spent yet. Outputs to watch-only addresses are not
+	// included in any of them.
+	Balance            util.Amount
+	UnconfirmedBalance util.Amount
+	ImmatureBalance    util.Amount
+	// TxCount is the number of transactions of the wallet, including unmined
+	// ones.
+	TxCount int
+	// UnusedAddresses and UnusedChangeAddresses count the addresses derived
+	// from the wallet seed that have not received payments yet. They are the
+	// keypool of bitcoind, although addresses are derived as needed here.
+	UnusedAddresses       int
+	UnusedChangeAddresses int
+	// UnlockedUntil is the time the wallet relocks at, as returned by
+	// UnlockedUntil.
+	UnlockedUntil time.Time
+	// WatchOnly is set when the wallet holds no private keys.
+	WatchOnly bool
+	// FeeRate is the fee per kilobyte paid by the transactions the wallet
+	// sends.
+	FeeRate util.Amount
+}
+
+// WalletInfo returns the balances, transaction count, unused addresses and lock
+// state of the wallet.
+func (w *Wallet) WalletInfo() (*WalletInfo, error) {
+	info := &WalletInfo{
+		UnlockedUntil: w.UnlockedUntil(),
+		WatchOnly:     w.Manager.WatchOnly(),
+		FeeRate:       w.FeeRate(),
+	}
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+		syncHeight := w.Manager.SyncedTo().Height
+		unspent, err := w.TxStore.UnspentOutputs(txmgrNs)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		for i := range unspent {
+			output := &unspent[i]
+			_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+				output.PkScript, w.chainParams)
+			if err == nil && len(addrs) > 0 &&
+				isWatchOnlyAddress(addrmgrNs, w.Manager, addrs[0]) {
+				continue
+			}
+			switch {
+			case output.FromCoinBase && !confirmed(
+				int32(w.chainParams.CoinbaseMaturity), output.Height,
+				syncHeight,
+			):
+				info.ImmatureBalance += output.Amount
+			case confirmed(1, output.Height, syncHeight):
+				info.Balance += output.Amount
+			default:
+				info.UnconfirmedBalance += output.Amount
+			}
+		}
+		err = w.TxStore.RangeTransactions(txmgrNs, 0, -1,
+			func(details []wtxmgr.TxDetails) (bool, error) {
+				info.TxCount += len(details)
+				return false, nil
+			},
+		)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		for _, manager := range w.Manager.ActiveScopedKeyManagers() {
+			err := manager.ForEachAccount(addrmgrNs, func(account uint32) error {
+				if account == waddrmgr.ImportedAddrAccount {
+					return nil
+				}
+				return manager.ForEachAccountAddress(addrmgrNs, account,
+					func(ma waddrmgr.ManagedAddress) error {
+						switch {
+						case ma.WatchOnly(), ma.Used(addrmgrNs):
+						case ma.Internal():
+							info.UnusedChangeAddresses++
+						default:
+							info.UnusedAddresses++
+						}
+						return nil
+					},
+				)
+			})
+			if err != nil {
+				Error(err)
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return info, nil
+}
+
+// AddressGrouping is an address in a group returned by AddressGroupings.
+type AddressGrouping struct {
+	Address util.Address
+	// Amount is the amount of the unspent outputs to the address.
+	Amount util.Amount
+	// Account is the name of the account of the address.
+	Account string
+}
+
+// AddressGroupings returns the addresses of the wallet that have been paid to in
+// groups that are publicly known to be held together, because outputs to them
+// have been spent by the same transaction, or one received the change of a
+// transaction spending outputs to the others. Groups are ordered by when their
+// first address was paid.
+func (w *Wallet) AddressGroupings() ([][]AddressGrouping, error) {
+	var groupings [][]AddressGrouping
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+		groups := newAddressGroups()
+		err := w.TxStore.RangeTransactions(txmgrNs, 0, -1,
+			func(details []wtxmgr.TxDetails) (bool, error) {
+				for i := range details {
+					if err := w.groupTxAddresses(txmgrNs, &details[i],
+						groups); err != nil {
+						Error(err)
+						return false, err
+					}
+				}
+				return false, nil
+			},
+		)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		unspent, err := w.TxStore.UnspentOutputs(txmgrNs)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		amounts := make(map[string]util.Amount)
+		for i := range unspent {
+			if addr := w.pkScriptAddress(unspent[i].PkScript); addr != nil {
+				amounts[addr.EncodeAddress()] += unspent[i].Amount
+			}
+		}
+		indexes := make(map[string]int)
+		for _, addr := range groups.addrs {
+			encoded := addr.EncodeAddress()
+			grouping := AddressGrouping{
+				Address: addr,
+				Amount:  amounts[encoded],
+			}
+			manager, account, err := w.Manager.AddrAccount(addrmgrNs, addr)
+			if err == nil {
+				grouping.Account, _ = manager.AccountName(addrmgrNs, account)
+			}
+			root := groups.find(encoded)
+			index, ok := indexes[root]
+			if !ok {
+				index = len(groupings)
+				indexes[root] = index
+				groupings = append(groupings, nil)
+			}
+			groupings[index] = append(groupings[index], grouping)
+		}
+		return nil
+	})
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return groupings, nil
+}
+
+// groupTxAddresses adds the addresses of the wallet paid by a transaction to
+// groups, joining the ones of the outputs it spends and its change.
+func (w *Wallet) groupTxAddresses(txmgrNs walletdb.ReadBucket,
+	details *wtxmgr.TxDetails, groups *addressGroups) error {
+	var joined []util.Address
+	if len(details.Debits) != 0 {
+		var block *wtxmgr.Block
+		if details.Block.Height != -1 {
+			block = &details.Block.Block
+		}
+		pkScripts, err := w.TxStore.PreviousPkScripts(
+			txmgrNs, &details.TxRecord, block,
+		)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		for _, pkScript := range pkScripts {
+			if addr := w.pkScriptAddress(pkScript); addr != nil {
+				joined = append(joined, addr)
+			}
+		}
+	}
+	for _, cred := range details.Credits {
+		addr := w.pkScriptAddress(details.MsgTx.TxOut[cred.Index].PkScript)
+		if addr == nil {
+			continue
+		}
+		groups.add(addr)
+		if cred.Change && len(joined) != 0 {
+			joined = append(joined, addr)
+		}
+	}
+	for _, addr := range joined {
+		groups.add(addr)
+		groups.union(joined[0].EncodeAddress(), addr.EncodeAddress())
+	}
+	return nil
+}
+
+// pkScriptAddress returns the address an output script pays to, or nil when it
+// does not pay to a single address.
+func (w *Wallet) pkScriptAddress(pkScript []byte) util.Address {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, w.chainParams)
+	if err != nil || len(addrs) != 1 {
+		return nil
+	}
+	return addrs[0]
+}
+
+// addressGroups is a disjoint set of encoded addresses, which keeps the
+// addresses in the order they were added.
+type addressGroups struct {
+	addrs  []util.Address
+	parent map[string]string
+}
+
+func newAddressGroups() *addressGroups {
+	return &addressGroups{parent: make(map[string]string)}
+}
+
+// add adds an address to its own group, unless it has been added before.
+func (g *addressGroups) add(addr util.Address) {
+	encoded := addr.EncodeAddress()
+	if _, ok := g.parent[encoded]; ok {
+		return
+	}
+	g.parent[encoded] = encoded
+	g.addrs = append(g.addrs, addr)
+}
+
+// find returns the address representing the group of an added address.
+func (g *addressGroups) find(addr string) string {
+	for g.parent[addr] != addr {
+		g.parent[addr] = g.parent[g.parent[addr]]
+		addr = g.parent[addr]
+	}
+	return addr
+}
+
+// union joins the groups of two added addresses.
+func (g *addressGroups) union(a, b string) {
+	if rootA, rootB := g.find(a), g.find(b); rootA != rootB {
+		g.parent[rootB] = rootA
+	}
+}
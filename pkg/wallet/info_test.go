@@ -0,0 +1,42 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+
+	txrules "github.com/p9c/pod/pkg/chain/tx/rules"
+	"github.com/p9c/pod/pkg/util"
+)
+
+// TestWalletInfoFeeRate ensures the wallet information reports the fee rate
+// set for sending, and that setting a zero fee rate restores the default.
+func TestWalletInfoFeeRate(t *testing.T) {
+	w, stop := testWallet(t, bytes.Repeat([]byte{0x01}, 32))
+	defer stop()
+	tests := []struct {
+		name string
+		set  util.Amount
+		want util.Amount
+	}{
+		{"default", -1, txrules.DefaultRelayFeePerKb},
+		{"set", 5000, 5000},
+		{"zero", 0, txrules.DefaultRelayFeePerKb},
+	}
+	for _, test := range tests {
+		if test.set >= 0 {
+			w.SetFeeRate(test.set)
+		}
+		if got := w.FeeRate(); got != test.want {
+			t.Errorf("%s: got fee rate %v, want %v", test.name, got,
+				test.want)
+		}
+		info, err := w.WalletInfo()
+		if err != nil {
+			t.Fatalf("%s: WalletInfo: %v", test.name, err)
+		}
+		if info.FeeRate != test.want {
+			t.Errorf("%s: got info fee rate %v, want %v", test.name,
+				info.FeeRate, test.want)
+		}
+	}
+}
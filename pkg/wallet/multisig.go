@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"errors"
+	"fmt"
 
 	txscript "github.com/p9c/pod/pkg/chain/tx/script"
 	"github.com/p9c/pod/pkg/util"
@@ -52,8 +53,14 @@ func (w *Wallet) MakeMultiSigScript(addrs []util.Address, nRequired int) ([]byte
 				Error(err)
 				return nil, err
 			}
-			serializedPubKey := addrInfo.(waddrmgr.ManagedPubKeyAddress).
-				PubKey().SerializeCompressed()
+			// Addresses imported without their public key, such as
+			// with importaddress, cannot be used.
+			pkAddrInfo, ok := addrInfo.(waddrmgr.ManagedPubKeyAddress)
+			if !ok {
+				return nil, fmt.Errorf("public key of address %s is "+
+					"not known", addr.EncodeAddress())
+			}
+			serializedPubKey := pkAddrInfo.PubKey().SerializeCompressed()
 			pubKeyAddr, err := util.NewAddressPubKey(
 				serializedPubKey, w.chainParams)
 			if err != nil {
@@ -0,0 +1,635 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sort"
+
+	blockchain "github.com/p9c/pod/pkg/chain"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	wtxmgr "github.com/p9c/pod/pkg/chain/tx/mgr"
+	txrules "github.com/p9c/pod/pkg/chain/tx/rules"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	txsizes "github.com/p9c/pod/pkg/chain/tx/sizes"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/util"
+	ec "github.com/p9c/pod/pkg/util/elliptic"
+	"github.com/p9c/pod/pkg/util/psbt"
+	waddrmgr "github.com/p9c/pod/pkg/wallet/addrmgr"
+	walletdb "github.com/p9c/pod/pkg/wallet/db"
+)
+
+// PSBTFundingOptions are the options of CreateFundedPSBT.
+type PSBTFundingOptions struct {
+	// ChangeAddress receives the change, or when it is nil a new change
+	// address of the default account does.
+	ChangeAddress util.Address
+	// ChangePosition is the index of the change output, or when it is
+	// negative a random one.
+	ChangePosition int
+	// LockUnspents locks the outputs the PSBT spends, so that they are not
+	// selected for other transactions until it is published.
+	LockUnspents bool
+	// FeeSatPerKb is the fee rate the transaction pays.
+	FeeSatPerKb util.Amount
+	// Replaceable signals that the transaction can be replaced with one
+	// paying a higher fee.
+	Replaceable bool
+	// PrevTxs are the previous transactions of given inputs that are not
+	// in the wallet, such as the multisig outputs of other co-signers.
+	PrevTxs map[chainhash.Hash]*wire.MsgTx
+}
+
+// CreateFundedPSBT creates an unsigned PSBT that pays the given outputs,
+// funded with the given inputs or, when there are none, with outputs of the
+// default account selected by the wallet, with change returned to the wallet
+// when it is not dust. The previous transactions and redeem scripts of the
+// inputs are added to the PSBT so that it can be signed offline. It returns the
+// PSBT along with the fee it pays and the index of its change output, which is
+// -1 when there is none.
+func (w *Wallet) CreateFundedPSBT(inputs []*wire.OutPoint,
+	outputs []*wire.TxOut, lockTime uint32, opts *PSBTFundingOptions) (
+	packet *psbt.Packet, fee util.Amount, changeIndex int, err error) {
+	chainClient, err := w.requireChainClient()
+	if err != nil {
+		Error(err)
+		return nil, 0, -1, err
+	}
+	if opts.ChangePosition > len(outputs) {
+		return nil, 0, -1, fmt.Errorf("change position %d is out of "+
+			"bounds", opts.ChangePosition)
+	}
+	var target util.Amount
+	for _, txOut := range outputs {
+		target += util.Amount(txOut.Value)
+	}
+	sequence := uint32(wire.MaxTxInSequenceNum)
+	switch {
+	case opts.Replaceable:
+		sequence = txrules.MaxRBFSequence
+	case lockTime != 0:
+		// The lock time is only enforced when an input is not final.
+		sequence = wire.MaxTxInSequenceNum - 1
+	}
+	changeIndex = -1
+	err = walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) error {
+		addrmgrNs := dbtx.ReadWriteBucket(waddrmgrNamespaceKey)
+		txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+		// The candidate inputs are the ones given, all of which are spent,
+		// or the eligible outputs of the default account, largest first.
+		var candidates []wtxmgr.Credit
+		if len(inputs) > 0 {
+			for _, op := range inputs {
+				prevTx, err := w.prevTx(txmgrNs, &op.Hash, opts.PrevTxs)
+				if err != nil {
+					Error(err)
+					return err
+				}
+				if prevTx == nil || int(op.Index) >= len(prevTx.TxOut) {
+					return fmt.Errorf("input %v is not in the wallet", op)
+				}
+				txOut := prevTx.TxOut[op.Index]
+				candidates = append(candidates, wtxmgr.Credit{
+					OutPoint: *op,
+					Amount:   util.Amount(txOut.Value),
+					PkScript: txOut.PkScript,
+				})
+			}
+		} else {
+			bs, err := chainClient.BlockStamp()
+			if err != nil {
+				Error(err)
+				return err
+			}
+			candidates, err = w.findEligibleOutputs(dbtx, 0, 1, bs)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			sort.Sort(sort.Reverse(byAmount(candidates)))
+		}
+		var selected []wtxmgr.Credit
+		var total util.Amount
+		var inputSize, witnessWeight int
+		for {
+			size := estimatePSBTVirtualSize(len(selected), inputSize,
+				witnessWeight, outputs, false)
+			fee = txrules.FeeForSerializeSize(opts.FeeSatPerKb, size)
+			enough := len(selected) > 0 && total >= target+fee
+			if enough && (len(inputs) == 0 || len(selected) == len(candidates)) {
+				break
+			}
+			if len(inputs) > 0 && len(selected) == len(candidates) {
+				return fmt.Errorf("the inputs pay %v, which is less than "+
+					"the %v needed", total, target+fee)
+			}
+			if len(selected) == len(candidates) {
+				return fmt.Errorf("insufficient funds: %v available, %v "+
+					"needed", total, target+fee)
+			}
+			credit := candidates[len(selected)]
+			redeemScript, err := w.redeemScript(addrmgrNs, credit.PkScript)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			inSize, weight := estimatePSBTInputSize(credit.PkScript, redeemScript)
+			inputSize += inSize
+			witnessWeight += weight
+			total += credit.Amount
+			selected = append(selected, credit)
+		}
+		// When there is enough left over to pay for a change output and
+		// still not be dust, it is returned to the wallet.
+		size := estimatePSBTVirtualSize(len(selected), inputSize,
+			witnessWeight, outputs, true)
+		changeFee := txrules.FeeForSerializeSize(opts.FeeSatPerKb, size)
+		changeAmount := total - target - changeFee
+		changeScriptSize := txsizes.P2WPKHPkScriptSize
+		var changeScript []byte
+		if opts.ChangeAddress != nil {
+			var err error
+			changeScript, err = txscript.PayToAddrScript(opts.ChangeAddress)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			changeScriptSize = len(changeScript)
+		}
+		if total > target+changeFee && !txrules.IsDustAmount(changeAmount,
+			changeScriptSize, txrules.DefaultRelayFeePerKb) {
+			if changeScript == nil {
+				changeAddr, err := w.newChangeAddress(addrmgrNs, 0)
+				if err != nil {
+					Error(err)
+					return err
+				}
+				changeScript, err = txscript.PayToAddrScript(changeAddr)
+				if err != nil {
+					Error(err)
+					return err
+				}
+			}
+			changeIndex = opts.ChangePosition
+			if changeIndex < 0 {
+				r, err := rand.Int(rand.Reader,
+					big.NewInt(int64(len(outputs)+1)))
+				if err != nil {
+					Error(err)
+					return err
+				}
+				changeIndex = int(r.Int64())
+			}
+			withChange := make([]*wire.TxOut, 0, len(outputs)+1)
+			withChange = append(withChange, outputs[:changeIndex]...)
+			withChange = append(withChange,
+				wire.NewTxOut(int64(changeAmount), changeScript))
+			outputs = append(withChange, outputs[changeIndex:]...)
+			fee = changeFee
+		}
+		if changeIndex < 0 {
+			// Whatever is left over goes to the miner.
+			fee = total - target
+		}
+		outPoints := make([]*wire.OutPoint, len(selected))
+		sequences := make([]uint32, len(selected))
+		for i := range selected {
+			outPoints[i] = &selected[i].OutPoint
+			sequences[i] = sequence
+		}
+		var err error
+		packet, err = psbt.New(outPoints, outputs, wire.TxVersion, lockTime,
+			sequences)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		return w.updatePSBT(addrmgrNs, txmgrNs, packet, opts.PrevTxs)
+	})
+	if err != nil {
+		Error(err)
+		return nil, 0, -1, err
+	}
+	if opts.LockUnspents {
+		for _, txIn := range packet.UnsignedTx.TxIn {
+			w.LockOutpoint(txIn.PreviousOutPoint)
+		}
+	}
+	return packet, fee, changeIndex, nil
+}
+
+// ProcessPSBT adds the previous outputs and redeem scripts the wallet knows of
+// to the inputs of a PSBT that are missing them and, if sign is true, signs
+// the P2PKH, P2WPKH, nested P2WPKH and P2SH multisig inputs it has keys for
+// with hashType, finalizing the ones that have all their signatures. The
+// wallet must be unlocked to sign. The PSBT is modified in place, and it
+// returns whether every input of it is finalized.
+//
+// BIP32 derivation paths are not added, as the wallet does not record the
+// fingerprint of its master key, and signers are expected to find their keys
+// from the scripts.
+func (w *Wallet) ProcessPSBT(packet *psbt.Packet, sign bool,
+	hashType txscript.SigHashType) (bool, error) {
+	if sign {
+		heldUnlock, err := w.holdUnlock()
+		if err != nil {
+			Error(err)
+			return false, err
+		}
+		defer heldUnlock.release()
+	}
+	err := walletdb.View(w.db, func(dbtx walletdb.ReadTx) error {
+		addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
+		txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+		err := w.updatePSBT(addrmgrNs, txmgrNs, packet, nil)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		if !sign {
+			return nil
+		}
+		return w.signPSBT(addrmgrNs, packet, hashType)
+	})
+	if err != nil {
+		Error(err)
+		return false, err
+	}
+	// Inputs that are still missing signatures are left for the other
+	// signers.
+	for i := range packet.Inputs {
+		if _, err := psbt.MaybeFinalize(packet, i); err != nil {
+			Debugf("input %d of PSBT not finalized: %v", i, err)
+		}
+	}
+	return packet.IsComplete(), nil
+}
+
+// updatePSBT adds the previous outputs of the transactions of the wallet, or
+// of prevTxs, and the redeem scripts of its script addresses to the inputs of
+// a PSBT that are missing them.
+func (w *Wallet) updatePSBT(addrmgrNs, txmgrNs walletdb.ReadBucket,
+	packet *psbt.Packet, prevTxs map[chainhash.Hash]*wire.MsgTx) error {
+	for i, txIn := range packet.UnsignedTx.TxIn {
+		in := &packet.Inputs[i]
+		if in.FinalScriptSig != nil || in.FinalScriptWitness != nil {
+			continue
+		}
+		prevOut := txIn.PreviousOutPoint
+		pkScript := psbtInputPkScript(packet, i)
+		if pkScript == nil {
+			prevTx, err := w.prevTx(txmgrNs, &prevOut.Hash, prevTxs)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			if prevTx == nil || int(prevOut.Index) >= len(prevTx.TxOut) {
+				// Another signer may know of it.
+				continue
+			}
+			txOut := prevTx.TxOut[prevOut.Index]
+			pkScript = txOut.PkScript
+			if txscript.IsWitnessProgram(pkScript) {
+				in.WitnessUtxo = wire.NewTxOut(txOut.Value, pkScript)
+			} else {
+				in.NonWitnessUtxo = prevTx.Copy()
+			}
+		}
+		if in.RedeemScript == nil {
+			redeemScript, err := w.redeemScript(addrmgrNs, pkScript)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			in.RedeemScript = redeemScript
+		}
+	}
+	return nil
+}
+
+// prevTx returns the transaction of the wallet with a hash, or the one in
+// prevTxs, or nil if there is none.
+func (w *Wallet) prevTx(txmgrNs walletdb.ReadBucket, hash *chainhash.Hash,
+	prevTxs map[chainhash.Hash]*wire.MsgTx) (*wire.MsgTx, error) {
+	details, err := w.TxStore.TxDetails(txmgrNs, hash)
+	if err != nil {
+		return nil, err
+	}
+	if details != nil {
+		return &details.MsgTx, nil
+	}
+	return prevTxs[*hash], nil
+}
+
+// signPSBT adds the signatures of the keys of the wallet to the inputs of a
+// PSBT it can sign.
+func (w *Wallet) signPSBT(addrmgrNs walletdb.ReadBucket, packet *psbt.Packet,
+	hashType txscript.SigHashType) error {
+	updater, err := psbt.NewUpdater(packet)
+	if err != nil {
+		Error(err)
+		return err
+	}
+	tx := packet.UnsignedTx
+	sigHashes := txscript.NewTxSigHashes(tx)
+	for i := range packet.Inputs {
+		in := &packet.Inputs[i]
+		if in.FinalScriptSig != nil || in.FinalScriptWitness != nil {
+			continue
+		}
+		if in.SighashType != 0 && in.SighashType != hashType {
+			return fmt.Errorf("input %d is to be signed with sighash "+
+				"type %v, not %v", i, in.SighashType, hashType)
+		}
+		// The output being signed for must be the one the input spends, or
+		// the signature could commit to a value it doesn't have.
+		prevOut := tx.TxIn[i].PreviousOutPoint
+		if in.NonWitnessUtxo != nil &&
+			in.NonWitnessUtxo.TxHash() != prevOut.Hash {
+			return fmt.Errorf("previous transaction of input %d is not "+
+				"%v", i, prevOut.Hash)
+		}
+		pkScript := psbtInputPkScript(packet, i)
+		if pkScript == nil {
+			continue
+		}
+		script := pkScript
+		if txscript.IsPayToScriptHash(pkScript) {
+			if in.RedeemScript == nil {
+				continue
+			}
+			script = in.RedeemScript
+		}
+		var sigs, pubKeys [][]byte
+		switch txscript.GetScriptClass(script) {
+		case txscript.PubKeyHashTy:
+			key, pubKey, err := w.psbtKey(addrmgrNs, script)
+			if err != nil || key == nil {
+				continue
+			}
+			sig, err := txscript.RawTxInSignature(tx, i, script, hashType,
+				key)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			sigs, pubKeys = append(sigs, sig), append(pubKeys, pubKey)
+		case txscript.WitnessV0PubKeyHashTy:
+			if in.WitnessUtxo == nil && in.NonWitnessUtxo == nil {
+				continue
+			}
+			// The key of a nested P2WPKH address is found by the P2SH
+			// script paying to it.
+			key, pubKey, err := w.psbtKey(addrmgrNs, pkScript)
+			if err != nil || key == nil {
+				continue
+			}
+			// The witness program is signed as the equivalent P2PKH
+			// script, as BIP143 specifies.
+			pkh, err := util.NewAddressPubKeyHash(script[2:],
+				w.chainParams)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			subScript, err := txscript.PayToAddrScript(pkh)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			sig, err := txscript.RawTxInWitnessSignature(tx, sigHashes, i,
+				psbtInputValue(packet, i), subScript, hashType, key)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			sigs, pubKeys = append(sigs, sig), append(pubKeys, pubKey)
+		case txscript.MultiSigTy:
+			// Each of the co-signers adds the signatures of the keys
+			// they have.
+			_, addrs, _, err := txscript.ExtractPkScriptAddrs(script,
+				w.chainParams)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			for _, addr := range addrs {
+				pka, ok := addr.(*util.AddressPubKey)
+				if !ok {
+					continue
+				}
+				key, _, err := w.psbtKeyForAddress(addrmgrNs,
+					pka.AddressPubKeyHash())
+				if err != nil || key == nil {
+					continue
+				}
+				sig, err := txscript.RawTxInSignature(tx, i, script,
+					hashType, key)
+				if err != nil {
+					Error(err)
+					return err
+				}
+				sigs = append(sigs, sig)
+				pubKeys = append(pubKeys, pka.ScriptAddress())
+			}
+		}
+		for j := range sigs {
+			outcome, err := updater.Sign(i, sigs[j], pubKeys[j], nil, nil)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			if outcome != psbt.SignSuccesful {
+				return fmt.Errorf("unable to add signature to input %d", i)
+			}
+		}
+		if len(sigs) > 0 && in.SighashType == 0 {
+			err := updater.AddInSighashType(hashType, i)
+			if err != nil {
+				Error(err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// psbtKey returns the private key of the wallet that a P2PKH or P2WPKH
+// script pays to along with its serialized public key, or a nil key if the
+// wallet doesn't have it.
+func (w *Wallet) psbtKey(addrmgrNs walletdb.ReadBucket, script []byte) (
+	*ec.PrivateKey, []byte, error) {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(script, w.chainParams)
+	if err != nil || len(addrs) != 1 {
+		return nil, nil, err
+	}
+	return w.psbtKeyForAddress(addrmgrNs, addrs[0])
+}
+
+// psbtKeyForAddress returns the private key of the wallet for an address along
+// with its serialized public key, or a nil key if the wallet doesn't have it.
+func (w *Wallet) psbtKeyForAddress(addrmgrNs walletdb.ReadBucket,
+	addr util.Address) (*ec.PrivateKey, []byte, error) {
+	ma, err := w.Manager.Address(addrmgrNs, addr)
+	if err != nil {
+		if waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	pka, ok := ma.(waddrmgr.ManagedPubKeyAddress)
+	if !ok {
+		return nil, nil, nil
+	}
+	key, err := pka.PrivKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	if pka.Compressed() {
+		return key, pka.PubKey().SerializeCompressed(), nil
+	}
+	return key, pka.PubKey().SerializeUncompressed(), nil
+}
+
+// redeemScript returns the redeem script of a P2SH output script paying to
+// the wallet, which is either a script it imported or the witness program of
+// a nested P2WPKH address, or nil if it is not one.
+func (w *Wallet) redeemScript(addrmgrNs walletdb.ReadBucket,
+	pkScript []byte) ([]byte, error) {
+	if !txscript.IsPayToScriptHash(pkScript) {
+		return nil, nil
+	}
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript,
+		w.chainParams)
+	if err != nil || len(addrs) != 1 {
+		return nil, err
+	}
+	ma, err := w.Manager.Address(addrmgrNs, addrs[0])
+	if err != nil {
+		if waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	switch ma := ma.(type) {
+	case waddrmgr.ManagedScriptAddress:
+		return ma.Script()
+	case waddrmgr.ManagedPubKeyAddress:
+		if ma.AddrType() != waddrmgr.NestedWitnessPubKey {
+			return nil, nil
+		}
+		witAddr, err := util.NewAddressWitnessPubKeyHash(
+			util.Hash160(ma.PubKey().SerializeCompressed()),
+			w.chainParams)
+		if err != nil {
+			return nil, err
+		}
+		return txscript.PayToAddrScript(witAddr)
+	}
+	return nil, nil
+}
+
+// psbtInputPkScript returns the output script an input of a PSBT spends, or
+// nil if the PSBT doesn't include it.
+func psbtInputPkScript(packet *psbt.Packet, i int) []byte {
+	in := &packet.Inputs[i]
+	if in.WitnessUtxo != nil {
+		return in.WitnessUtxo.PkScript
+	}
+	if in.NonWitnessUtxo != nil {
+		index := packet.UnsignedTx.TxIn[i].PreviousOutPoint.Index
+		if int(index) < len(in.NonWitnessUtxo.TxOut) {
+			return in.NonWitnessUtxo.TxOut[index].PkScript
+		}
+	}
+	return nil
+}
+
+// psbtInputValue returns the value of the output an input of a PSBT spends,
+// or zero if the PSBT doesn't include it.
+func psbtInputValue(packet *psbt.Packet, i int) int64 {
+	in := &packet.Inputs[i]
+	if in.WitnessUtxo != nil {
+		return in.WitnessUtxo.Value
+	}
+	if in.NonWitnessUtxo != nil {
+		index := packet.UnsignedTx.TxIn[i].PreviousOutPoint.Index
+		if int(index) < len(in.NonWitnessUtxo.TxOut) {
+			return in.NonWitnessUtxo.TxOut[index].Value
+		}
+	}
+	return 0
+}
+
+// estimatePSBTInputSize returns a worst case estimate of the serialize size
+// of an input spending an output with the given script, and of the weight of
+// its witness. Bare multisig inputs are sized from their scripts, P2SH
+// multisig inputs from their redeem scripts, and other P2SH inputs are taken
+// to be nested P2WPKH.
+func estimatePSBTInputSize(pkScript, redeemScript []byte) (int, int) {
+	switch {
+	case txscript.IsPayToWitnessPubKeyHash(pkScript):
+		return txsizes.RedeemP2WPKHInputSize,
+			txsizes.RedeemP2WPKHInputWitnessWeight
+	case txscript.GetScriptClass(pkScript) == txscript.MultiSigTy:
+		// OP_0 and a pushed signature for each required key.
+		_, required, _ := txscript.CalcMultiSigStats(pkScript)
+		scriptSize := 1 + required*(1+73)
+		return 32 + 4 + wire.VarIntSerializeSize(uint64(scriptSize)) +
+			scriptSize + 4, 0
+	case txscript.IsPayToScriptHash(pkScript):
+		if txscript.GetScriptClass(redeemScript) != txscript.MultiSigTy {
+			return txsizes.RedeemNestedP2WPKHInputSize,
+				txsizes.RedeemP2WPKHInputWitnessWeight
+		}
+		// OP_0, a pushed signature for each required key, and the pushed
+		// redeem script.
+		_, required, _ := txscript.CalcMultiSigStats(redeemScript)
+		scriptSize := 1 + required*(1+73) +
+			pushDataSize(len(redeemScript)) + len(redeemScript)
+		return 32 + 4 + wire.VarIntSerializeSize(uint64(scriptSize)) +
+			scriptSize + 4, 0
+	}
+	return txsizes.RedeemP2PKHInputSize, 0
+}
+
+// pushDataSize returns the size of the opcode that pushes n bytes.
+func pushDataSize(n int) int {
+	switch {
+	case n < txscript.OP_PUSHDATA1:
+		return 1
+	case n <= 0xff:
+		return 2
+	case n <= 0xffff:
+		return 3
+	}
+	return 5
+}
+
+// estimatePSBTVirtualSize returns a worst case estimate of the virtual size of
+// a transaction with inputs of the given total size and witness weight paying
+// txOuts, and a P2WPKH change output if addChangeOutput is true.
+func estimatePSBTVirtualSize(numInputs, inputSize, witnessWeight int,
+	txOuts []*wire.TxOut, addChangeOutput bool) int {
+	outputCount := len(txOuts)
+	changeSize := 0
+	if addChangeOutput {
+		outputCount++
+		changeSize = txsizes.P2WPKHOutputSize
+	}
+	size := 8 + wire.VarIntSerializeSize(uint64(numInputs)) +
+		wire.VarIntSerializeSize(uint64(outputCount)) + inputSize +
+		changeSize
+	for _, txOut := range txOuts {
+		size += txOut.SerializeSize()
+	}
+	if witnessWeight > 0 {
+		// The segwit marker and flag, and at most a byte for the empty
+		// witness of each input, rounded up to whole virtual bytes.
+		witnessWeight += 2 + numInputs
+		size += (witnessWeight + blockchain.WitnessScaleFactor - 1) /
+			blockchain.WitnessScaleFactor
+	}
+	return size
+}
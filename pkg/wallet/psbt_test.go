@@ -0,0 +1,361 @@
+package wallet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/p9c/pod/pkg/chain/config/netparams"
+	chainhash "github.com/p9c/pod/pkg/chain/hash"
+	wtxmgr "github.com/p9c/pod/pkg/chain/tx/mgr"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/chain/wire"
+	"github.com/p9c/pod/pkg/util"
+	"github.com/p9c/pod/pkg/util/psbt"
+	waddrmgr "github.com/p9c/pod/pkg/wallet/addrmgr"
+	"github.com/p9c/pod/pkg/wallet/chain"
+	walletdb "github.com/p9c/pod/pkg/wallet/db"
+)
+
+// testFeeRate is the fee rate the PSBTs of the tests pay
+const testFeeRate = util.Amount(10000)
+
+// testChainClient is a chain client that only knows of the best block, which
+// is all funding a PSBT from the outputs of the wallet asks it for.
+type testChainClient struct {
+	chain.Interface
+	height int32
+}
+
+func (c *testChainClient) BlockStamp() (*waddrmgr.BlockStamp, error) {
+	return &waddrmgr.BlockStamp{Height: c.height}, nil
+}
+
+func (c *testChainClient) Stop() {}
+
+func (c *testChainClient) WaitForShutdown() {}
+
+// testPSBTWallet returns a wallet like testWallet, with a chain client that
+// has the best block at height 10.
+func testPSBTWallet(t *testing.T, seed byte) (*Wallet, func()) {
+	t.Helper()
+	w, stop := testWallet(t, bytes.Repeat([]byte{seed}, 32))
+	w.chainClientLock.Lock()
+	w.chainClient = &testChainClient{height: 10}
+	w.chainClientLock.Unlock()
+	return w, stop
+}
+
+// newTestAddress returns a new address of the default account of the wallet
+// in a key scope.
+func newTestAddress(t *testing.T, w *Wallet,
+	scope waddrmgr.KeyScope) util.Address {
+	t.Helper()
+	addr, err := w.NewAddress(waddrmgr.DefaultAccountNum, scope, true)
+	if err != nil {
+		t.Fatalf("NewAddress: %v", err)
+	}
+	return addr
+}
+
+// payTo returns an output paying value to an address.
+func payTo(t *testing.T, addr util.Address, value int64) *wire.TxOut {
+	t.Helper()
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("unable to create script: %v", err)
+	}
+	return wire.NewTxOut(value, script)
+}
+
+// foreignAddress returns an address the wallets of the tests don't have.
+func foreignAddress(t *testing.T) util.Address {
+	t.Helper()
+	addr, err := util.NewAddressPubKeyHash(bytes.Repeat([]byte{0xee}, 20),
+		&netparams.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+// receive adds a transaction paying the outputs, mined at height 1, to the
+// wallet and returns it.
+func receive(t *testing.T, w *Wallet, outs ...*wire.TxOut) *wire.MsgTx {
+	t.Helper()
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{0x01}, 0), nil,
+		nil))
+	for _, out := range outs {
+		tx.AddTxOut(out)
+	}
+	rec, err := wtxmgr.NewTxRecordFromMsgTx(tx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := &wtxmgr.BlockMeta{
+		Block: wtxmgr.Block{Hash: chainhash.Hash{0x02}, Height: 1},
+		Time:  time.Now(),
+	}
+	err = walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) error {
+		return w.addRelevantTx(dbtx, rec, block)
+	})
+	if err != nil {
+		t.Fatalf("unable to add transaction: %v", err)
+	}
+	return tx
+}
+
+// checkSigned ensures a PSBT extracts to a transaction whose inputs spend
+// the outputs of prevTx.
+func checkSigned(t *testing.T, name string, packet *psbt.Packet,
+	prevTx *wire.MsgTx) {
+	t.Helper()
+	tx, err := psbt.Extract(packet)
+	if err != nil {
+		t.Fatalf("%s: Extract: %v", name, err)
+	}
+	var scripts [][]byte
+	var values []util.Amount
+	for _, txIn := range tx.TxIn {
+		out := prevTx.TxOut[txIn.PreviousOutPoint.Index]
+		scripts = append(scripts, out.PkScript)
+		values = append(values, util.Amount(out.Value))
+	}
+	if err = validateMsgTx(tx, scripts, values); err != nil {
+		t.Errorf("%s: %v", name, err)
+	}
+}
+
+// TestCreateFundedPSBT ensures a PSBT is funded with the largest eligible
+// outputs of the wallet, pays its change back to the wallet at the given
+// position, and that the outputs it locks are not selected again.
+func TestCreateFundedPSBT(t *testing.T) {
+	w, stop := testPSBTWallet(t, 0x03)
+	defer stop()
+	const coin = int64(util.SatoshiPerBitcoin)
+	prevTx := receive(t, w,
+		payTo(t, newTestAddress(t, w, waddrmgr.KeyScopeBIP0044), coin),
+		payTo(t, newTestAddress(t, w, waddrmgr.KeyScopeBIP0084), 2*coin),
+		payTo(t, newTestAddress(t, w, waddrmgr.KeyScopeBIP0049Plus), 3*coin))
+	tests := []struct {
+		name   string
+		amount int64
+		// spent holds the indexes of the outputs of prevTx the PSBT
+		// spends, or is nil if it can't be funded.
+		spent []uint32
+	}{
+		{"largest output", coin * 5 / 2, []uint32{2}},
+		{"locked output left out", coin * 7 / 2, nil},
+		{"two outputs", coin * 29 / 10, []uint32{1, 0}},
+	}
+	for _, test := range tests {
+		outputs := []*wire.TxOut{payTo(t, foreignAddress(t), test.amount)}
+		packet, fee, changeIndex, err := w.CreateFundedPSBT(nil, outputs, 0,
+			&PSBTFundingOptions{
+				ChangePosition: 1,
+				LockUnspents:   true,
+				FeeSatPerKb:    testFeeRate,
+			})
+		if test.spent == nil {
+			if err == nil || !strings.Contains(err.Error(),
+				"insufficient funds") {
+				t.Errorf("%s: got error %v, want insufficient funds",
+					test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: CreateFundedPSBT: %v", test.name, err)
+		}
+		tx := packet.UnsignedTx
+		if len(tx.TxIn) != len(test.spent) {
+			t.Fatalf("%s: got %d inputs, want %d", test.name, len(tx.TxIn),
+				len(test.spent))
+		}
+		var in int64
+		for i, txIn := range tx.TxIn {
+			op := wire.OutPoint{Hash: prevTx.TxHash(), Index: test.spent[i]}
+			if txIn.PreviousOutPoint != op {
+				t.Errorf("%s: got input %d spending %v, want %v", test.name,
+					i, txIn.PreviousOutPoint, op)
+			}
+			if !w.LockedOutpoint(txIn.PreviousOutPoint) {
+				t.Errorf("%s: input %d is not locked", test.name, i)
+			}
+			if psbtInputPkScript(packet, i) == nil {
+				t.Errorf("%s: input %d has no previous output", test.name, i)
+			}
+			in += psbtInputValue(packet, i)
+		}
+		if changeIndex != 1 || len(tx.TxOut) != 2 {
+			t.Fatalf("%s: got change at %d of %d outputs, want 1 of 2",
+				test.name, changeIndex, len(tx.TxOut))
+		}
+		var out int64
+		for _, txOut := range tx.TxOut {
+			out += txOut.Value
+		}
+		if fee <= 0 || util.Amount(in-out) != fee {
+			t.Errorf("%s: got fee %v, want %v", test.name, fee,
+				util.Amount(in-out))
+		}
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+			tx.TxOut[changeIndex].PkScript, w.chainParams)
+		if err != nil || len(addrs) != 1 {
+			t.Fatalf("%s: unable to read change script: %v", test.name, err)
+		}
+		if have, err := w.HaveAddress(addrs[0]); err != nil || !have {
+			t.Errorf("%s: change pays %v, which is not in the wallet",
+				test.name, addrs[0])
+		}
+	}
+	// The nested P2WPKH input of the first PSBT has its redeem script.
+	w.ResetLockedOutpoints()
+	packet, _, _, err := w.CreateFundedPSBT(nil,
+		[]*wire.TxOut{payTo(t, foreignAddress(t), coin*5/2)}, 0,
+		&PSBTFundingOptions{ChangePosition: -1, FeeSatPerKb: testFeeRate})
+	if err != nil {
+		t.Fatalf("CreateFundedPSBT: %v", err)
+	}
+	if in := packet.Inputs[0]; in.NonWitnessUtxo == nil ||
+		in.RedeemScript == nil {
+		t.Errorf("nested P2WPKH input has no previous transaction or " +
+			"redeem script")
+	}
+}
+
+// TestProcessPSBTSign ensures the wallet signs and finalizes the P2PKH, P2WPKH
+// and nested P2WPKH inputs of a PSBT, and refuses to sign an input whose
+// previous transaction is not the one it spends.
+func TestProcessPSBTSign(t *testing.T) {
+	w, stop := testPSBTWallet(t, 0x04)
+	defer stop()
+	const coin = int64(util.SatoshiPerBitcoin)
+	prevTx := receive(t, w,
+		payTo(t, newTestAddress(t, w, waddrmgr.KeyScopeBIP0044), coin),
+		payTo(t, newTestAddress(t, w, waddrmgr.KeyScopeBIP0084), coin),
+		payTo(t, newTestAddress(t, w, waddrmgr.KeyScopeBIP0049Plus), coin))
+	newPacket := func() *psbt.Packet {
+		t.Helper()
+		var inputs []*wire.OutPoint
+		prevHash := prevTx.TxHash()
+		for i := range prevTx.TxOut {
+			inputs = append(inputs, wire.NewOutPoint(&prevHash, uint32(i)))
+		}
+		packet, _, _, err := w.CreateFundedPSBT(inputs,
+			[]*wire.TxOut{payTo(t, foreignAddress(t), 2*coin)}, 0,
+			&PSBTFundingOptions{ChangePosition: -1,
+				FeeSatPerKb: testFeeRate})
+		if err != nil {
+			t.Fatalf("CreateFundedPSBT: %v", err)
+		}
+		return packet
+	}
+	packet := newPacket()
+	complete, err := w.ProcessPSBT(packet, false, txscript.SigHashAll)
+	if err != nil || complete {
+		t.Fatalf("ProcessPSBT without signing: got %v, %v, want false",
+			complete, err)
+	}
+	complete, err = w.ProcessPSBT(packet, true, txscript.SigHashAll)
+	if err != nil || !complete {
+		t.Fatalf("ProcessPSBT: got %v, %v, want true", complete, err)
+	}
+	checkSigned(t, "single key inputs", packet, prevTx)
+	// The previous transaction of the P2PKH input is swapped for another
+	// one with the same output, but a different hash.
+	packet = newPacket()
+	for i, in := range packet.Inputs {
+		if in.NonWitnessUtxo == nil || txscript.GetScriptClass(
+			psbtInputPkScript(packet, i)) != txscript.PubKeyHashTy {
+			continue
+		}
+		fake := in.NonWitnessUtxo.Copy()
+		fake.LockTime++
+		packet.Inputs[i].NonWitnessUtxo = fake
+	}
+	complete, err = w.ProcessPSBT(packet, true, txscript.SigHashAll)
+	if err == nil || !strings.Contains(err.Error(), "previous transaction") {
+		t.Errorf("ProcessPSBT with a wrong previous transaction: got %v, "+
+			"%v, want an error", complete, err)
+	}
+}
+
+// TestProcessPSBTMultisig ensures each co-signer of a P2SH multisig input adds
+// its own signature, and the one adding the last finalizes the PSBT.
+func TestProcessPSBTMultisig(t *testing.T) {
+	first, stopFirst := testPSBTWallet(t, 0x05)
+	defer stopFirst()
+	second, stopSecond := testPSBTWallet(t, 0x06)
+	defer stopSecond()
+	var pubKeys []*util.AddressPubKey
+	for _, w := range []*Wallet{first, second} {
+		addr := newTestAddress(t, w, waddrmgr.KeyScopeBIP0044)
+		pubKey, err := w.PubKeyForAddress(addr)
+		if err != nil {
+			t.Fatalf("PubKeyForAddress: %v", err)
+		}
+		pka, err := util.NewAddressPubKey(pubKey.SerializeCompressed(),
+			w.chainParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubKeys = append(pubKeys, pka)
+	}
+	script, err := txscript.MultiSigScript(pubKeys, 2)
+	if err != nil {
+		t.Fatalf("MultiSigScript: %v", err)
+	}
+	var p2sh util.Address
+	for _, w := range []*Wallet{first, second} {
+		if p2sh, err = w.ImportP2SHRedeemScript(script); err != nil {
+			t.Fatalf("ImportP2SHRedeemScript: %v", err)
+		}
+	}
+	// The multisig output is not a credit of either wallet, so its
+	// transaction is given to the wallet creating the PSBT.
+	prevTx := wire.NewMsgTx(wire.TxVersion)
+	prevTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{0x01}, 0),
+		nil, nil))
+	prevTx.AddTxOut(payTo(t, p2sh, int64(util.SatoshiPerBitcoin)))
+	prevHash := prevTx.TxHash()
+	packet, _, _, err := first.CreateFundedPSBT(
+		[]*wire.OutPoint{wire.NewOutPoint(&prevHash, 0)},
+		[]*wire.TxOut{payTo(t, foreignAddress(t),
+			int64(util.SatoshiPerBitcoin)/2)}, 0,
+		&PSBTFundingOptions{
+			ChangeAddress:  foreignAddress(t),
+			ChangePosition: -1,
+			FeeSatPerKb:    testFeeRate,
+			PrevTxs:        map[chainhash.Hash]*wire.MsgTx{prevHash: prevTx},
+		})
+	if err != nil {
+		t.Fatalf("CreateFundedPSBT: %v", err)
+	}
+	if !bytes.Equal(packet.Inputs[0].RedeemScript, script) {
+		t.Fatalf("multisig input has no redeem script")
+	}
+	complete, err := first.ProcessPSBT(packet, true, txscript.SigHashAll)
+	if err != nil || complete {
+		t.Fatalf("first ProcessPSBT: got %v, %v, want false", complete, err)
+	}
+	if n := len(packet.Inputs[0].PartialSigs); n != 1 {
+		t.Fatalf("first ProcessPSBT: got %d signatures, want 1", n)
+	}
+	// The PSBT is passed on to the other co-signer serialized.
+	encoded, err := packet.B64Encode()
+	if err != nil {
+		t.Fatalf("B64Encode: %v", err)
+	}
+	packet, err = psbt.NewFromRawBytes(strings.NewReader(encoded), true)
+	if err != nil {
+		t.Fatalf("NewFromRawBytes: %v", err)
+	}
+	complete, err = second.ProcessPSBT(packet, true, txscript.SigHashAll)
+	if err != nil || !complete {
+		t.Fatalf("second ProcessPSBT: got %v, %v, want true", complete, err)
+	}
+	checkSigned(t, "multisig input", packet, prevTx)
+}
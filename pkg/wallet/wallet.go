@@ -74,6 +74,8 @@ type Wallet struct {
 	chainClientSyncMtx sync.Mutex
 	lockedOutpoints    map[wire.OutPoint]struct{}
 	recoveryWindow     uint32
+	feeRate            util.Amount
+	feeRateMtx         sync.Mutex
 	// Channels for rescan processing.  Requests are added and merged with
 	// any waiting requests, before being sent to another goroutine to
 	// call the rescan RPC.
@@ -89,6 +91,7 @@ type Wallet struct {
 	lockRequests       chan struct{}
 	holdUnlockRequests chan chan heldUnlock
 	lockState          chan bool
+	unlockedUntil      chan time.Time
 	changePassphrase   chan changePassphraseRequest
 	changePassphrases  chan changePassphrasesRequest
 	// Information for reorganization handling.
@@ -1034,6 +1037,7 @@ type (
 		outputs     []*wire.TxOut
 		minconf     int32
 		feeSatPerKB util.Amount
+		strategy    CoinSelectionStrategy
 		resp        chan createTxResponse
 	}
 	createTxResponse struct {
@@ -1065,7 +1069,7 @@ out:
 				continue
 			}
 			tx, err := w.txToOutputs(txr.outputs, txr.account,
-				txr.minconf, txr.feeSatPerKB)
+				txr.minconf, txr.feeSatPerKB, txr.strategy)
 			heldUnlock.release()
 			txr.resp <- createTxResponse{tx, err}
 		case <-quit:
@@ -1078,16 +1082,19 @@ out:
 // CreateSimpleTx creates a new signed transaction spending unspent P2PKH
 // outputs with at laest minconf confirmations spending to any number of
 // address/amount pairs.  Change and an appropriate transaction fee are
-// automatically included, if necessary.  All transaction creation through this
-// function is serialized to prevent the creation of many transactions which
-// spend the same outputs.
+// automatically included, if necessary, and the outputs spent are chosen by the
+// coin selection strategy.  All transaction creation through this function is
+// serialized to prevent the creation of many transactions which spend the same
+// outputs.
 func (w *Wallet) CreateSimpleTx(account uint32, outputs []*wire.TxOut,
-	minconf int32, satPerKb util.Amount) (*txauthor.AuthoredTx, error) {
+	minconf int32, satPerKb util.Amount,
+	strategy CoinSelectionStrategy) (*txauthor.AuthoredTx, error) {
 	req := createTxRequest{
 		account:     account,
 		outputs:     outputs,
 		minconf:     minconf,
 		feeSatPerKB: satPerKb,
+		strategy:    strategy,
 		resp:        make(chan createTxResponse),
 	}
 	w.createTxRequests <- req
@@ -1099,6 +1106,7 @@ type (
 	unlockRequest struct {
 		passphrase []byte
 		lockAfter  <-chan time.Time // nil prevents the timeout.
+		lockTime   time.Time        // When lockAfter fires, if known.
 		err        chan error
 	}
 	changePassphraseRequest struct {
@@ -1122,6 +1130,7 @@ type (
 // walletLocker manages the locked/unlocked state of a wallet.
 func (w *Wallet) walletLocker() {
 	var timeout <-chan time.Time
+	var lockTime time.Time
 	holdChan := make(heldUnlock)
 	quit := w.quitChan()
 out:
@@ -1137,7 +1146,7 @@ out:
 				req.err <- err
 				continue
 			}
-			timeout = req.lockAfter
+			timeout, lockTime = req.lockAfter, req.lockTime
 			if timeout == nil {
 				Info("the wallet has been unlocked without a time limit")
 			} else {
@@ -1193,6 +1202,8 @@ out:
 			}
 		case w.lockState <- w.Manager.IsLocked():
 			continue
+		case w.unlockedUntil <- lockTime:
+			continue
 		case <-quit:
 			break out
 		case <-w.lockRequests:
@@ -1200,7 +1211,7 @@ out:
 		}
 		// Select statement fell through by an explicit lock or the
 		// timer expiring.  Lock the manager here.
-		timeout = nil
+		timeout, lockTime = nil, time.Time{}
 		err := w.Manager.Lock()
 		if err != nil && !waddrmgr.IsError(err, waddrmgr.ErrLocked) {
 			Error("could not lock wallet:", err)
@@ -1226,6 +1237,29 @@ func (w *Wallet) Unlock(passphrase []byte, lock <-chan time.Time) error {
 	return <-err
 }
 
+// UnlockTimeout unlocks the wallet's address manager like Unlock, and relocks it
+// after timeout has passed unless it is zero.  The time it relocks at is then
+// returned by UnlockedUntil.
+func (w *Wallet) UnlockTimeout(passphrase []byte, timeout time.Duration) error {
+	req := unlockRequest{
+		passphrase: passphrase,
+		err:        make(chan error, 1),
+	}
+	if timeout != 0 {
+		req.lockAfter = time.After(timeout)
+		req.lockTime = time.Now().Add(timeout)
+	}
+	w.unlockRequests <- req
+	return <-req.err
+}
+
+// UnlockedUntil returns the time the wallet relocks at after being unlocked by
+// UnlockTimeout.  The zero time is returned when the wallet is locked or was
+// unlocked without a known time limit.
+func (w *Wallet) UnlockedUntil() time.Time {
+	return <-w.unlockedUntil
+}
+
 // Lock locks the wallet's address manager.
 func (w *Wallet) Lock() {
 	w.lockRequests <- struct{}{}
@@ -1346,6 +1380,9 @@ func (w *Wallet) AccountAddresses(account uint32) (addrs []util.Address, err err
 // a UTXO must be in a block.  If confirmations is 1 or greater,
 // the balance will be calculated based on how many how many blocks
 // include a UTXO.
+//
+// UTXOs to watch-only addresses are not included, as the wallet cannot spend
+// them; see CalculateWatchOnlyBalance.
 func (w *Wallet) CalculateBalance(confirms int32) (util.Amount, error) {
 	var balance util.Amount
 	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
@@ -1353,17 +1390,25 @@ func (w *Wallet) CalculateBalance(confirms int32) (util.Amount, error) {
 		var err error
 		blk := w.Manager.SyncedTo()
 		balance, err = w.TxStore.Balance(txmgrNs, confirms, blk.Height)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		watchOnly, err := w.watchOnlyBalance(tx, confirms, blk.Height)
+		balance -= watchOnly
 		return err
 	})
 	return balance, err
 }
 
 // Balances records total, spendable (by policy), and immature coinbase
-// reward balance amounts.
+// reward balance amounts.  Outputs to watch-only addresses are not included in
+// these, but only in the WatchOnly amount, when spendable by policy.
 type Balances struct {
 	Total          util.Amount
 	Spendable      util.Amount
 	ImmatureReward util.Amount
+	WatchOnly      util.Amount
 }
 
 // CalculateAccountBalances sums the amounts of all unspent transaction
@@ -1387,13 +1432,23 @@ func (w *Wallet) CalculateAccountBalances(account uint32, confirms int32) (Balan
 		}
 		for i := range unspent {
 			output := &unspent[i]
-			var outputAcct uint32
+			var ma waddrmgr.ManagedAddress
 			_, addrs, _, err := txscript.ExtractPkScriptAddrs(
 				output.PkScript, w.chainParams)
 			if err == nil && len(addrs) > 0 {
-				_, outputAcct, err = w.Manager.AddrAccount(addrmgrNs, addrs[0])
+				ma, err = w.Manager.Address(addrmgrNs, addrs[0])
 			}
-			if err != nil || outputAcct != account {
+			if err != nil || ma == nil || ma.Account() != account {
+				continue
+			}
+			if ma.WatchOnly() {
+				if output.FromCoinBase && !confirmed(int32(w.chainParams.CoinbaseMaturity),
+					output.Height, syncBlock.Height) {
+					continue
+				}
+				if confirmed(confirms, output.Height, syncBlock.Height) {
+					bals.WatchOnly += output.Amount
+				}
 				continue
 			}
 			bals.Total += output.Amount
@@ -1715,11 +1770,14 @@ func RecvCategory(details *wtxmgr.TxDetails, syncHeight int32, net *netparams.Pa
 }
 
 // listTransactions creates a object that may be marshalled to a response result
-// for a listtransactions RPC.
+// for a listtransactions RPC.  Results involve watch-only addresses when they
+// credit one, or when they are sends and watchOnlyDebits is set because the
+// transaction spends outputs to watch-only addresses.
 //
 // TODO: This should be moved to the legacyrpc package.
 func listTransactions(tx walletdb.ReadTx, details *wtxmgr.TxDetails, addrMgr *waddrmgr.Manager,
-	syncHeight int32, net *netparams.Params) []btcjson.ListTransactionsResult {
+	syncHeight int32, net *netparams.Params,
+	watchOnlyDebits bool) []btcjson.ListTransactionsResult {
 	addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
 	var (
 		blockHashStr  string
@@ -1772,10 +1830,12 @@ outputs:
 		}
 		var address string
 		var accountName string
+		var watchOnly bool
 		_, addrs, _, _ := txscript.ExtractPkScriptAddrs(output.PkScript, net)
 		if len(addrs) == 1 {
 			addr := addrs[0]
 			address = addr.EncodeAddress()
+			watchOnly = isWatchOnlyAddress(addrmgrNs, addrMgr, addr)
 			mgr, account, err := addrMgr.AddrAccount(addrmgrNs, addrs[0])
 			if err == nil {
 				accountName, err = mgr.AccountName(addrmgrNs, account)
@@ -1788,7 +1848,6 @@ outputs:
 		amountF64 := util.Amount(output.Value).ToDUO()
 		result := btcjson.ListTransactionsResult{
 			// Fields left zeroed:
-			//   InvolvesWatchOnly
 			//   BlockIndex
 			//
 			// Fields set below:
@@ -1796,6 +1855,7 @@ outputs:
 			//   Category
 			//   Amount
 			//   Fee
+			//   InvolvesWatchOnly
 			Address:         address,
 			Vout:            uint32(i),
 			Confirmations:   confirmations,
@@ -1820,6 +1880,8 @@ outputs:
 			result.Category = "send"
 			result.Amount = -amountF64
 			result.Fee = &feeF64
+			result.InvolvesWatchOnly = watchOnlyDebits ||
+				(spentCredit && watchOnly)
 			results = append(results, result)
 		}
 		if isCredit {
@@ -1827,6 +1889,7 @@ outputs:
 			result.Category = recvCat
 			result.Amount = amountF64
 			result.Fee = nil
+			result.InvolvesWatchOnly = watchOnly
 			results = append(results, result)
 		}
 	}
@@ -1843,7 +1906,8 @@ func (w *Wallet) ListSinceBlock(start, end, syncHeight int32) (txList []btcjson.
 		rangeFn := func(details []wtxmgr.TxDetails) (bool, error) {
 			for _, detail := range details {
 				jsonResults := listTransactions(tx, &detail,
-					w.Manager, syncHeight, w.chainParams)
+					w.Manager, syncHeight, w.chainParams,
+					w.watchOnlyDebits(tx, &detail))
 				txList = append(txList, jsonResults...)
 			}
 			return false, nil
@@ -1884,7 +1948,8 @@ func (w *Wallet) ListTransactions(from, count int) (txList []btcjson.ListTransac
 					return true, nil
 				}
 				jsonResults := listTransactions(tx, &details[i],
-					w.Manager, syncBlock.Height, w.chainParams)
+					w.Manager, syncBlock.Height, w.chainParams,
+					w.watchOnlyDebits(tx, &details[i]))
 				txList = append(txList, jsonResults...)
 				if len(jsonResults) > 0 {
 					n++
@@ -1930,7 +1995,8 @@ func (w *Wallet) ListAddressTransactions(pkHashes map[string]struct{}) ([]btcjso
 						continue
 					}
 					jsonResults := listTransactions(tx, detail,
-						w.Manager, syncBlock.Height, w.chainParams)
+						w.Manager, syncBlock.Height, w.chainParams,
+						w.watchOnlyDebits(tx, detail))
 					// if err != nil {
 					// 	return false, err
 					// }
@@ -1962,7 +2028,8 @@ func (w *Wallet) ListAllTransactions() ([]btcjson.ListTransactionsResult, error)
 			// reverse order they were marked mined.
 			for i := len(details) - 1; i >= 0; i-- {
 				jsonResults := listTransactions(tx, &details[i], w.Manager,
-					syncBlock.Height, w.chainParams)
+					syncBlock.Height, w.chainParams,
+					w.watchOnlyDebits(tx, &details[i]))
 				txList = append(txList, jsonResults...)
 			}
 			return false, nil
@@ -2388,32 +2455,28 @@ func (w *Wallet) ListUnspent(minconf, maxconf int32,
 				continue
 			}
 		include:
-			// At the moment watch-only addresses are not supported, so all
-			// recorded outputs that are not multisig are "spendable".
-			// Multisig outputs are only "spendable" if all keys are
-			// controlled by this wallet.
+			// Recorded outputs that are not multisig are "spendable"
+			// unless their address is watch-only.  Multisig outputs are
+			// only "spendable" if all keys are controlled by this wallet.
 			//
-			// TODO: Each case will need updates when watch-only addrs
-			// is added.  For P2PK, P2PKH, and P2SH, the address must be
-			// looked up and not be watching-only.  For multisig, all
-			// pubkeys must belong to the manager with the associated
-			// private key (currently it only checks whether the pubkey
-			// exists, since the private key is required at the moment).
+			// TODO: For multisig, all pubkeys must belong to the manager
+			// with the associated private key (currently it only checks
+			// whether the pubkey exists and is not watch-only).
 			var spendable bool
 		scSwitch:
 			switch sc {
-			case txscript.PubKeyHashTy:
-				spendable = true
-			case txscript.PubKeyTy:
-				spendable = true
-			case txscript.WitnessV0ScriptHashTy:
-				spendable = true
-			case txscript.WitnessV0PubKeyHashTy:
-				spendable = true
+			case txscript.PubKeyHashTy, txscript.PubKeyTy,
+				txscript.WitnessV0ScriptHashTy,
+				txscript.WitnessV0PubKeyHashTy:
+				spendable = len(addrs) > 0 &&
+					!isWatchOnlyAddress(addrmgrNs, w.Manager, addrs[0])
 			case txscript.MultiSigTy:
 				for _, a := range addrs {
-					_, err := w.Manager.Address(addrmgrNs, a)
+					ma, err := w.Manager.Address(addrmgrNs, a)
 					if err == nil {
+						if ma.WatchOnly() {
+							break scSwitch
+						}
 						continue
 					}
 					if waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound) {
@@ -2517,20 +2580,7 @@ func (w *Wallet) ImportPrivateKey(scope waddrmgr.KeyScope, wif *util.WIF,
 	}
 	// The starting block for the key is the genesis block unless otherwise
 	// specified.
-	var newBirthday time.Time
-	if bs == nil {
-		bs = &waddrmgr.BlockStamp{
-			Hash:   *w.chainParams.GenesisHash,
-			Height: 0,
-		}
-	} else {
-		// Only update the new birthday time from default value if we
-		// actually have timestamp info in the header.
-		header, err := w.chainClient.GetBlockHeader(&bs.Hash)
-		if err == nil {
-			newBirthday = header.Timestamp
-		}
-	}
+	bs, newBirthday := w.importBirthday(bs)
 	// Attempt to import private key into wallet.
 	var addr util.Address
 	var props *waddrmgr.AccountProperties
@@ -2557,24 +2607,9 @@ func (w *Wallet) ImportPrivateKey(scope waddrmgr.KeyScope, wif *util.WIF,
 	}
 	// Rescan blockchain for transactions with txout scripts paying to the
 	// imported address.
-	if rescan {
-		job := &RescanJob{
-			Addrs:      []util.Address{addr},
-			OutPoints:  nil,
-			BlockStamp: *bs,
-		}
-		// Submit rescan job and log when the import has completed.
-		// Do not block on finishing the rescan.  The rescan success
-		// or failure is logged elsewhere, and the channel is not
-		// required to be read, so discard the return value.
-		_ = w.SubmitRescan(job)
-	} else {
-		err := w.chainClient.NotifyReceived([]util.Address{addr})
-		if err != nil {
-			Error(err)
-			return "", fmt.Errorf("Failed to subscribe for address ntfns for "+
-				"address %s: %s", addr.EncodeAddress(), err)
-		}
+	if err = w.watchAddresses([]util.Address{addr}, bs, rescan); err != nil {
+		Error(err)
+		return "", err
 	}
 	addrStr := addr.EncodeAddress()
 	Info("imported payment address", addrStr)
@@ -2583,6 +2618,25 @@ func (w *Wallet) ImportPrivateKey(scope waddrmgr.KeyScope, wif *util.WIF,
 	return addrStr, nil
 }
 
+// FeeRate returns the fee per kilobyte paid by the transactions the wallet
+// sends, which is the default relay fee unless it was changed with SetFeeRate.
+func (w *Wallet) FeeRate() util.Amount {
+	w.feeRateMtx.Lock()
+	defer w.feeRateMtx.Unlock()
+	return w.feeRate
+}
+
+// SetFeeRate sets the fee per kilobyte paid by the transactions the wallet
+// sends. A zero fee rate restores the default relay fee.
+func (w *Wallet) SetFeeRate(feeRate util.Amount) {
+	if feeRate == 0 {
+		feeRate = txrules.DefaultRelayFeePerKb
+	}
+	w.feeRateMtx.Lock()
+	w.feeRate = feeRate
+	w.feeRateMtx.Unlock()
+}
+
 // LockedOutpoint returns whether an outpoint has been marked as locked and
 // should not be used as an input for created transactions.
 func (w *Wallet) LockedOutpoint(op wire.OutPoint) bool {
@@ -2974,10 +3028,11 @@ func (w *Wallet) TotalReceivedForAddr(addr util.Address, minConf int32) (util.Am
 	return amount, err
 }
 
-// SendOutputs creates and sends payment transactions. It returns the
-// transaction hash upon success.
+// SendOutputs creates and sends payment transactions, spending outputs chosen
+// by the coin selection strategy. It returns the transaction hash upon success.
 func (w *Wallet) SendOutputs(outputs []*wire.TxOut, account uint32,
-	minconf int32, satPerKb util.Amount) (*chainhash.Hash, error) {
+	minconf int32, satPerKb util.Amount,
+	strategy CoinSelectionStrategy) (*chainhash.Hash, error) {
 	// Ensure the outputs to be created adhere to the network's consensus
 	// rules.
 	for _, output := range outputs {
@@ -2989,7 +3044,8 @@ func (w *Wallet) SendOutputs(outputs []*wire.TxOut, account uint32,
 	// transaction will be added to the database in order to ensure that we
 	// continue to re-broadcast the transaction upon restarts until it has
 	// been confirmed.
-	createdTx, err := w.CreateSimpleTx(account, outputs, minconf, satPerKb)
+	createdTx, err := w.CreateSimpleTx(account, outputs, minconf, satPerKb,
+		strategy)
 	if err != nil {
 		Error(err)
 		return nil, err
@@ -3215,6 +3271,16 @@ func (w *Wallet) Database() walletdb.DB {
 	return w.db
 }
 
+// SetBirthday stores the earliest time a key of the wallet could have been
+// used. A chain client set after this starts rescans from the first block
+// after the birthday.
+func (w *Wallet) SetBirthday(birthday time.Time) error {
+	return walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		return w.Manager.SetBirthday(addrmgrNs, birthday)
+	})
+}
+
 // Create creates an new wallet, writing it to an empty database.  If the passed
 // seed is non-nil, it is used.  Otherwise, a secure random seed of the
 // recommended length is generated.
@@ -3324,6 +3390,7 @@ func Open(db walletdb.DB, pubPass []byte, cbs *waddrmgr.OpenCallbacks,
 		TxStore:             txMgr,
 		lockedOutpoints:     map[wire.OutPoint]struct{}{},
 		recoveryWindow:      recoveryWindow,
+		feeRate:             txrules.DefaultRelayFeePerKb,
 		rescanAddJob:        make(chan *RescanJob),
 		rescanBatch:         make(chan *rescanBatch),
 		rescanNotifications: make(chan interface{}),
@@ -3334,6 +3401,7 @@ func Open(db walletdb.DB, pubPass []byte, cbs *waddrmgr.OpenCallbacks,
 		lockRequests:        make(chan struct{}),
 		holdUnlockRequests:  make(chan chan heldUnlock),
 		lockState:           make(chan bool),
+		unlockedUntil:       make(chan time.Time),
 		changePassphrase:    make(chan changePassphraseRequest),
 		changePassphrases:   make(chan changePassphrasesRequest),
 		chainParams:         params,
@@ -0,0 +1,403 @@
+package wallet
+
+import (
+	"fmt"
+	"time"
+
+	wtxmgr "github.com/p9c/pod/pkg/chain/tx/mgr"
+	txscript "github.com/p9c/pod/pkg/chain/tx/script"
+	"github.com/p9c/pod/pkg/util"
+	ec "github.com/p9c/pod/pkg/util/elliptic"
+	"github.com/p9c/pod/pkg/util/hdkeychain"
+	waddrmgr "github.com/p9c/pod/pkg/wallet/addrmgr"
+	walletdb "github.com/p9c/pod/pkg/wallet/db"
+)
+
+// watchOnlyLookahead is the number of addresses past the last used one that
+// are watched on each branch of a watch-only account. The wallet holding its
+// private keys hands out addresses without this wallet knowing, so this is the
+// gap limit of BIP0044 beyond which funds are not looked for.
+const watchOnlyLookahead = 20
+
+// ImportAccount imports the extended public key of a BIP0044-like account as a
+// new watch-only account named name in the key scope, and returns the account
+// number. The first watchOnlyLookahead addresses of each branch are watched,
+// and more as they are used. When rescan is set, the chain is rescanned from bs,
+// or the genesis block when it is nil, for payments to the account.
+func (w *Wallet) ImportAccount(scope waddrmgr.KeyScope, name string,
+	acctKeyPub *hdkeychain.ExtendedKey, bs *waddrmgr.BlockStamp,
+	rescan bool) (uint32, error) {
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		Error(err)
+		return 0, err
+	}
+	bs, newBirthday := w.importBirthday(bs)
+	var account uint32
+	var addrs []util.Address
+	var props *waddrmgr.AccountProperties
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		account, err = manager.ImportAccount(addrmgrNs, name, acctKeyPub)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		for _, branch := range []uint32{
+			waddrmgr.ExternalBranch, waddrmgr.InternalBranch,
+		} {
+			newAddrs, err := extendWatchOnlyAccount(
+				addrmgrNs, manager, account, branch,
+				watchOnlyLookahead-1,
+			)
+			if err != nil {
+				Error(err)
+				return err
+			}
+			addrs = append(addrs, newAddrs...)
+		}
+		props, err = manager.AccountProperties(addrmgrNs, account)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		return w.Manager.SetBirthday(addrmgrNs, newBirthday)
+	})
+	if err != nil {
+		Error(err)
+		return 0, err
+	}
+	if rescan {
+		go w.rescanWatchOnlyAccount(manager, account, addrs, *bs)
+	} else if err = w.watchAddresses(addrs, bs, false); err != nil {
+		Error(err)
+		return 0, err
+	}
+	Infof("imported watch-only account %q from extended public key", name)
+	w.NtfnServer.notifyAccountProperties(props)
+	return account, nil
+}
+
+// ImportPublicKey imports a public key into the imported account of the key
+// scope as a watch-only address, and returns the encoded address. When rescan
+// is set, the chain is rescanned from bs, or the genesis block when it is nil,
+// for payments to it.
+func (w *Wallet) ImportPublicKey(scope waddrmgr.KeyScope, pubKey *ec.PublicKey,
+	compressed bool, bs *waddrmgr.BlockStamp, rescan bool) (string, error) {
+	return w.importWatchOnly(scope, bs, rescan,
+		func(ns walletdb.ReadWriteBucket, manager *waddrmgr.ScopedKeyManager,
+			bs *waddrmgr.BlockStamp) (waddrmgr.ManagedAddress, error) {
+			return manager.ImportPublicKey(ns, pubKey, compressed, bs)
+		},
+	)
+}
+
+// ImportAddress imports an address into the imported account of the key scope
+// so that payments to it are watched, and returns the encoded address. Pay to
+// public key addresses are imported as their public key. When rescan is set,
+// the chain is rescanned from bs, or the genesis block when it is nil, for
+// payments to it.
+func (w *Wallet) ImportAddress(scope waddrmgr.KeyScope, addr util.Address,
+	bs *waddrmgr.BlockStamp, rescan bool) (string, error) {
+	if pkAddr, ok := addr.(*util.AddressPubKey); ok {
+		compressed := pkAddr.Format() != util.PKFUncompressed
+		return w.ImportPublicKey(scope, pkAddr.PubKey(), compressed, bs, rescan)
+	}
+	return w.importWatchOnly(scope, bs, rescan,
+		func(ns walletdb.ReadWriteBucket, manager *waddrmgr.ScopedKeyManager,
+			bs *waddrmgr.BlockStamp) (waddrmgr.ManagedAddress, error) {
+			return manager.ImportAddress(ns, addr, bs)
+		},
+	)
+}
+
+// importWatchOnly imports a watch-only address with the import function and
+// starts watching it.
+func (w *Wallet) importWatchOnly(scope waddrmgr.KeyScope,
+	bs *waddrmgr.BlockStamp, rescan bool,
+	importFn func(walletdb.ReadWriteBucket, *waddrmgr.ScopedKeyManager,
+		*waddrmgr.BlockStamp) (waddrmgr.ManagedAddress, error)) (string, error) {
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		Error(err)
+		return "", err
+	}
+	bs, newBirthday := w.importBirthday(bs)
+	var addr util.Address
+	var props *waddrmgr.AccountProperties
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) error {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		maddr, err := importFn(addrmgrNs, manager, bs)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		addr = maddr.Address()
+		props, err = manager.AccountProperties(
+			addrmgrNs, waddrmgr.ImportedAddrAccount,
+		)
+		if err != nil {
+			Error(err)
+			return err
+		}
+		return w.Manager.SetBirthday(addrmgrNs, newBirthday)
+	})
+	if err != nil {
+		Error(err)
+		return "", err
+	}
+	if err = w.watchAddresses([]util.Address{addr}, bs, rescan); err != nil {
+		Error(err)
+		return "", err
+	}
+	addrStr := addr.EncodeAddress()
+	Info("imported watch-only address", addrStr)
+	w.NtfnServer.notifyAccountProperties(props)
+	return addrStr, nil
+}
+
+// importBirthday returns the block stamp an import is rescanned from, which is
+// the genesis block unless bs is given, along with the birthday of the wallet
+// it implies.
+func (w *Wallet) importBirthday(bs *waddrmgr.BlockStamp) (*waddrmgr.BlockStamp,
+	time.Time) {
+	var newBirthday time.Time
+	if bs == nil {
+		bs = &waddrmgr.BlockStamp{
+			Hash:   *w.chainParams.GenesisHash,
+			Height: 0,
+		}
+	} else {
+		// Only update the new birthday time from default value if we
+		// actually have timestamp info in the header.
+		header, err := w.chainClient.GetBlockHeader(&bs.Hash)
+		if err == nil {
+			newBirthday = header.Timestamp
+		}
+	}
+	return bs, newBirthday
+}
+
+// watchAddresses makes the wallet watch imported addresses, rescanning the chain
+// for them from bs when rescan is set and otherwise only for new transactions.
+func (w *Wallet) watchAddresses(addrs []util.Address, bs *waddrmgr.BlockStamp,
+	rescan bool) error {
+	if rescan {
+		job := &RescanJob{
+			Addrs:      addrs,
+			OutPoints:  nil,
+			BlockStamp: *bs,
+		}
+		// Submit rescan job and log when the import has completed.
+		// Do not block on finishing the rescan.  The rescan success
+		// or failure is logged elsewhere, and the channel is not
+		// required to be read, so discard the return value.
+		_ = w.SubmitRescan(job)
+		return nil
+	}
+	if err := w.chainClient.NotifyReceived(addrs); err != nil {
+		Error(err)
+		return fmt.Errorf("Failed to subscribe for address ntfns for "+
+			"%d addresses: %s", len(addrs), err)
+	}
+	return nil
+}
+
+// rescanWatchOnlyAccount rescans the chain from bs for the addresses of a
+// watch-only account. Payments found during a rescan extend the addresses
+// watched on their branch, so those are rescanned in turn until no more are
+// derived.
+func (w *Wallet) rescanWatchOnlyAccount(manager *waddrmgr.ScopedKeyManager,
+	account uint32, addrs []util.Address, bs waddrmgr.BlockStamp) {
+	scanned := make(map[string]struct{})
+	for len(addrs) != 0 {
+		for _, addr := range addrs {
+			scanned[addr.EncodeAddress()] = struct{}{}
+		}
+		job := &RescanJob{
+			Addrs:      addrs,
+			BlockStamp: bs,
+		}
+		if err := <-w.SubmitRescan(job); err != nil {
+			Error(err)
+			return
+		}
+		addrs = nil
+		err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+			addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+			return manager.ForEachAccountAddress(addrmgrNs, account,
+				func(maddr waddrmgr.ManagedAddress) error {
+					addr := maddr.Address()
+					if _, ok := scanned[addr.EncodeAddress()]; !ok {
+						addrs = append(addrs, addr)
+					}
+					return nil
+				},
+			)
+		})
+		if err != nil {
+			Error(err)
+			return
+		}
+	}
+}
+
+// extendWatchOnlyAccount derives the addresses of a branch of a watch-only
+// account up to the index last, and returns the ones that are new.
+func extendWatchOnlyAccount(ns walletdb.ReadWriteBucket,
+	manager *waddrmgr.ScopedKeyManager, account, branch,
+	last uint32) ([]util.Address, error) {
+	props, err := manager.AccountProperties(ns, account)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	next, extend := props.ExternalKeyCount, manager.ExtendExternalAddresses
+	if branch == waddrmgr.InternalBranch {
+		next, extend = props.InternalKeyCount, manager.ExtendInternalAddresses
+	}
+	if last < next {
+		return nil, nil
+	}
+	if err = extend(ns, account, last); err != nil {
+		Error(err)
+		return nil, err
+	}
+	addrs := make([]util.Address, 0, last-next+1)
+	for index := next; index <= last; index++ {
+		maddr, err := manager.DeriveFromKeyPath(ns, waddrmgr.DerivationPath{
+			Account: account,
+			Branch:  branch,
+			Index:   index,
+		})
+		if err != nil {
+			Error(err)
+			return nil, err
+		}
+		addrs = append(addrs, maddr.Address())
+	}
+	return addrs, nil
+}
+
+// extendUsedWatchOnlyAccount keeps watchOnlyLookahead addresses watched past a
+// newly used address of a watch-only account, and returns the addresses that
+// need to be watched for that.
+func (w *Wallet) extendUsedWatchOnlyAccount(ns walletdb.ReadWriteBucket,
+	ma waddrmgr.ManagedAddress) ([]util.Address, error) {
+	pka, ok := ma.(waddrmgr.ManagedPubKeyAddress)
+	if !ok || !ma.WatchOnly() || ma.Imported() {
+		return nil, nil
+	}
+	scope, path, ok := pka.DerivationInfo()
+	if !ok {
+		return nil, nil
+	}
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		Error(err)
+		return nil, err
+	}
+	return extendWatchOnlyAccount(
+		ns, manager, path.Account, path.Branch,
+		path.Index+watchOnlyLookahead,
+	)
+}
+
+// CalculateWatchOnlyBalance sums the amounts of the unspent transaction outputs
+// to watch-only addresses of a wallet, which are not included in the balance
+// returned by CalculateBalance, with at least confirms confirmations.
+func (w *Wallet) CalculateWatchOnlyBalance(confirms int32) (util.Amount, error) {
+	var balance util.Amount
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		var err error
+		balance, err = w.watchOnlyBalance(tx, confirms, w.Manager.SyncedTo().Height)
+		return err
+	})
+	return balance, err
+}
+
+// watchOnlyBalance sums the amounts of the mature unspent transaction outputs
+// to watch-only addresses with at least confirms confirmations at syncHeight.
+func (w *Wallet) watchOnlyBalance(tx walletdb.ReadTx, confirms,
+	syncHeight int32) (util.Amount, error) {
+	addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+	txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+	unspent, err := w.TxStore.UnspentOutputs(txmgrNs)
+	if err != nil {
+		Error(err)
+		return 0, err
+	}
+	var balance util.Amount
+	for i := range unspent {
+		output := &unspent[i]
+		if !confirmed(confirms, output.Height, syncHeight) {
+			continue
+		}
+		if output.FromCoinBase && !confirmed(
+			int32(w.chainParams.CoinbaseMaturity), output.Height, syncHeight,
+		) {
+			continue
+		}
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+			output.PkScript, w.chainParams)
+		if err != nil || len(addrs) == 0 {
+			continue
+		}
+		if isWatchOnlyAddress(addrmgrNs, w.Manager, addrs[0]) {
+			balance += output.Amount
+		}
+	}
+	return balance, nil
+}
+
+// watchOnlyDebits returns whether a transaction spends outputs to watch-only
+// addresses of the wallet.
+func (w *Wallet) watchOnlyDebits(tx walletdb.ReadTx,
+	details *wtxmgr.TxDetails) bool {
+	if len(details.Debits) == 0 {
+		return false
+	}
+	addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+	txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+	var block *wtxmgr.Block
+	if details.Block.Height != -1 {
+		block = &details.Block.Block
+	}
+	pkScripts, err := w.TxStore.PreviousPkScripts(
+		txmgrNs, &details.TxRecord, block,
+	)
+	if err != nil {
+		Error(err)
+		return false
+	}
+	for _, pkScript := range pkScripts {
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+			pkScript, w.chainParams)
+		if err != nil || len(addrs) == 0 {
+			continue
+		}
+		if isWatchOnlyAddress(addrmgrNs, w.Manager, addrs[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWatchOnlyAddress returns whether addr is a watch-only address of the
+// wallet.
+func isWatchOnlyAddress(ns walletdb.ReadBucket, addrMgr *waddrmgr.Manager,
+	addr util.Address) bool {
+	ma, err := addrMgr.Address(ns, addr)
+	return err == nil && ma.WatchOnly()
+}
+
+// TxSpendsWatchOnly returns whether a transaction of the wallet spends outputs
+// to watch-only addresses of the wallet.
+func (w *Wallet) TxSpendsWatchOnly(details *wtxmgr.TxDetails) (spends bool,
+	err error) {
+	err = walletdb.View(w.db, func(tx walletdb.ReadTx) error {
+		spends = w.watchOnlyDebits(tx, details)
+		return nil
+	})
+	return
+}